@@ -0,0 +1,96 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// Assignment is a task the staff of a course hands out to its students. Attachments are the IDs of uploads
+// belonging to the course.
+type Assignment struct {
+	ID          bson.ObjectId `json:"id" bson:"_id"`
+	CourseID    bson.ObjectId `json:"courseID" bson:"courseID"`
+	Title       string        `json:"title" bson:"title"`
+	Description string        `json:"description" bson:"description"`
+	DueAt       time.Time     `json:"dueAt" bson:"dueAt"`
+	Attachments []string      `json:"attachments" bson:"attachments"`
+	// MaxPoints is the score of a perfect answer. Assignments without points are not graded.
+	MaxPoints float64 `json:"maxPoints" bson:"maxPoints"`
+	// Category is the name of the grade category the assignment counts towards.
+	Category  string    `json:"category" bson:"category"`
+	CreatedBy string    `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// IsGraded reports whether scores can be given for the assignment.
+func (a Assignment) IsGraded() bool {
+	return a.MaxPoints > 0
+}
+
+// AssignmentUpdate holds the changes to an assignment. Nil fields are left untouched.
+type AssignmentUpdate struct {
+	Title       *string
+	Description *string
+	DueAt       *time.Time
+	Attachments []string
+	MaxPoints   *float64
+	Category    *string
+}
+
+// Submission is the answer of a student to an assignment. Every student has at most one submission per assignment,
+// submitting again replaces it.
+type Submission struct {
+	ID           bson.ObjectId `json:"id" bson:"_id"`
+	AssignmentID bson.ObjectId `json:"assignmentID" bson:"assignmentID"`
+	CourseID     bson.ObjectId `json:"courseID" bson:"courseID"`
+	UserID       string        `json:"userID" bson:"userID"`
+	Text         string        `json:"text" bson:"text"`
+	Attachments  []string      `json:"attachments" bson:"attachments"`
+	SubmittedAt  time.Time     `json:"submittedAt" bson:"submittedAt"`
+	// Late is set if the submission was made after the assignment was due.
+	Late bool `json:"late" bson:"late"`
+}
+
+const (
+	// MaxSubmissionLength is the largest number of characters in the text of a submission.
+	MaxSubmissionLength = 50000
+	// MaxAttachments is the largest number of attachments of an assignment or a submission.
+	MaxAttachments = 10
+)
+
+type AssignmentRepository interface {
+	Insert(assignment *Assignment) error
+	FindOneByID(id string) (error, Assignment)
+	// FindByCourse returns the assignments of a course, earliest due first.
+	FindByCourse(courseID string) (error, []Assignment)
+	Update(id string, update bson.M) (error, Assignment)
+	Delete(id string) error
+	DeleteByCourse(courseID string) error
+}
+
+type SubmissionRepository interface {
+	// Upsert stores a submission, replacing an earlier one of the same user for the same assignment.
+	Upsert(submission *Submission) error
+	Find(assignmentID string, userID string) (error, Submission)
+	// FindByAssignment returns all submissions of an assignment, oldest first.
+	FindByAssignment(assignmentID string) (error, []Submission)
+	DeleteByAssignment(assignmentID string) error
+	DeleteByCourse(courseID string) error
+}
+
+// AssignmentDataDeleter deletes data belonging to an assignment when the assignment is deleted.
+type AssignmentDataDeleter interface {
+	DeleteByAssignment(assignmentID string) error
+}
+
+type AssignmentService interface {
+	GetAssignments(courseID string, userID string) (error, []Assignment)
+	GetAssignment(courseID string, assignmentID string, userID string) (error, Assignment)
+	CreateAssignment(courseID string, userID string, assignment Assignment) (error, Assignment)
+	UpdateAssignment(courseID string, assignmentID string, userID string, update AssignmentUpdate) (error, Assignment)
+	DeleteAssignment(courseID string, assignmentID string, userID string) error
+	Submit(courseID string, assignmentID string, userID string, submission Submission) (error, Submission)
+	GetSubmission(courseID string, assignmentID string, userID string) (error, Submission)
+	GetSubmissions(courseID string, assignmentID string, userID string) (error, []Submission)
+	DeleteByCourse(courseID string) error
+}
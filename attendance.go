@@ -0,0 +1,105 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// AttendanceStatus is whether a student attended a meeting.
+type AttendanceStatus string
+
+const (
+	AttendancePresent AttendanceStatus = "present"
+	AttendanceAbsent  AttendanceStatus = "absent"
+	// AttendanceExcused meetings do not count towards the attendance rate.
+	AttendanceExcused AttendanceStatus = "excused"
+)
+
+// IsValid reports whether s is a known status.
+func (s AttendanceStatus) IsValid() bool {
+	return s == AttendancePresent || s == AttendanceAbsent || s == AttendanceExcused
+}
+
+// AttendanceSession records who attended a single meeting of a course schedule. Students can check in with Code
+// until CodeExpiresAt. Students without a record count as absent.
+type AttendanceSession struct {
+	ID            bson.ObjectId               `json:"id" bson:"_id"`
+	CourseID      bson.ObjectId               `json:"courseID" bson:"courseID"`
+	ScheduleID    bson.ObjectId               `json:"scheduleID" bson:"scheduleID"`
+	Date          string                      `json:"date" bson:"date"`
+	Start         time.Time                   `json:"startsAt" bson:"startsAt"`
+	Code          string                      `json:"code,omitempty" bson:"code,omitempty"`
+	CodeExpiresAt *time.Time                  `json:"codeExpiresAt,omitempty" bson:"codeExpiresAt,omitempty"`
+	Records       map[string]AttendanceRecord `json:"records" bson:"records"`
+	CreatedBy     string                      `json:"createdBy" bson:"createdBy"`
+	CreatedAt     time.Time                   `json:"createdAt" bson:"createdAt"`
+}
+
+// AttendanceRecord is the attendance of one student. CheckedInAt is set if the student checked in with a code.
+type AttendanceRecord struct {
+	UserID      string           `json:"userID" bson:"userID"`
+	Status      AttendanceStatus `json:"status" bson:"status"`
+	CheckedInAt *time.Time       `json:"checkedInAt,omitempty" bson:"checkedInAt,omitempty"`
+	MarkedBy    string           `json:"markedBy,omitempty" bson:"markedBy,omitempty"`
+}
+
+// AttendanceReport sums up the attendance of a student. Rate is the percentage of attended meetings, leaving out
+// excused ones; it is nil if there is no such meeting.
+type AttendanceReport struct {
+	UserID   string              `json:"userID"`
+	Sessions int                 `json:"sessions"`
+	Present  int                 `json:"present"`
+	Absent   int                 `json:"absent"`
+	Excused  int                 `json:"excused"`
+	Rate     *float64            `json:"rate"`
+	Records  []AttendanceHistory `json:"records,omitempty"`
+}
+
+// AttendanceHistory is the status of a student in one session.
+type AttendanceHistory struct {
+	SessionID  bson.ObjectId    `json:"sessionID"`
+	ScheduleID bson.ObjectId    `json:"scheduleID"`
+	Date       string           `json:"date"`
+	Status     AttendanceStatus `json:"status"`
+}
+
+// CourseAttendanceReport sums up the attendance of all students of a course. Rate is the mean rate of the students.
+type CourseAttendanceReport struct {
+	CourseID bson.ObjectId      `json:"courseID"`
+	Sessions int                `json:"sessions"`
+	Rate     *float64           `json:"rate"`
+	Students []AttendanceReport `json:"students"`
+}
+
+type AttendanceRepository interface {
+	// Insert fails with ErrDuplicate if the meeting has a session already.
+	Insert(session *AttendanceSession) error
+	FindOneByID(id string) (error, AttendanceSession)
+	// Find fails with ErrNotFound if the meeting has no session.
+	Find(courseID string, scheduleID string, date string) (error, AttendanceSession)
+	// FindByCourse returns the sessions of a course, earliest first.
+	FindByCourse(courseID string) (error, []AttendanceSession)
+	// SetCode sets the check-in code of a session. An empty code closes the check-in.
+	SetCode(id string, code string, expiresAt time.Time) error
+	SetRecord(id string, record AttendanceRecord) error
+	// CheckIn records the student as present in the session of a course whose code is valid at now.
+	CheckIn(courseID string, code string, record AttendanceRecord, now time.Time) (error, AttendanceSession)
+	Delete(id string) error
+	DeleteByCourse(courseID string) error
+}
+
+type AttendanceService interface {
+	GetSessions(courseID string, userID string) (error, []AttendanceSession)
+	GetSession(courseID string, sessionID string, userID string) (error, AttendanceSession)
+	// OpenSession returns the session of a meeting, creating it if there is none yet.
+	OpenSession(courseID string, scheduleID string, date string, userID string, of OccurrenceFinder) (error, AttendanceSession)
+	DeleteSession(courseID string, sessionID string, userID string) error
+	OpenCheckIn(courseID string, sessionID string, userID string) (error, AttendanceSession)
+	CloseCheckIn(courseID string, sessionID string, userID string) (error, AttendanceSession)
+	CheckIn(courseID string, userID string, code string) (error, AttendanceSession)
+	MarkAttendance(courseID string, sessionID string, userID string, records []AttendanceRecord) (error, AttendanceSession)
+	GetStudentReport(courseID string, studentID string, userID string) (error, AttendanceReport)
+	GetCourseReport(courseID string, userID string) (error, CourseAttendanceReport)
+	DeleteCancelled(courseID string, of OccurrenceFinder) error
+	DeleteByCourse(courseID string) error
+}
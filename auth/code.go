@@ -0,0 +1,19 @@
+package auth
+
+import "crypto/rand"
+
+// CodeAlphabet leaves out characters that are easily confused, like 0 and O.
+const CodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// NewCode returns a random code of length characters from CodeAlphabet, meant to be typed in by people.
+func NewCode(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	// The alphabet has 32 characters, so every byte maps to one of them without bias.
+	for k := range b {
+		b[k] = CodeAlphabet[int(b[k])%len(CodeAlphabet)]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestNewCode(t *testing.T) {
+	t.Parallel()
+	for _, length := range []int{0, 6, 8} {
+		code, err := NewCode(length)
+		assert.Nil(t, err, "should not cause error")
+		assert.Len(t, code, length, "code length does not match")
+		assert.Empty(t, strings.Trim(code, CodeAlphabet), "code contains invalid characters")
+	}
+
+	a, _ := NewCode(8)
+	b, _ := NewCode(8)
+	assert.NotEqual(t, a, b, "codes repeat")
+}
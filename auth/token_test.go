@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHashToken(t *testing.T) {
+	t.Parallel()
+	var testCases = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"token", "token", "3c469e9d6c5875d37a43f353d4f88e61fcf812c66eee3457465a40b0da4153e0"},
+		{"empty", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			assert.Equal(t, v.expected, HashToken(v.input), "hash does not match")
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Package blob contains implementations of eduboard.BlobStore.
+package blob
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileSystem stores every blob as a file in Dir.
+type FileSystem struct {
+	Dir string
+}
+
+var _ eduboard.BlobStore = (*FileSystem)(nil)
+
+// Put writes r to a temporary file first and renames it afterwards, such that readers never see partial files.
+func (f *FileSystem) Put(key string, r io.Reader) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(f.Dir, 0700); err != nil {
+		return errors.Wrapf(err, "error creating directory %s", f.Dir)
+	}
+
+	tmp, err := ioutil.TempFile(f.Dir, ".upload")
+	if err != nil {
+		return errors.Wrap(err, "error creating temporary file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "error writing blob %s", key)
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrapf(err, "error writing blob %s", key)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *FileSystem) Get(key string) (io.ReadCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (f *FileSystem) Delete(key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path returns the file of key and makes sure it does not point outside of Dir.
+func (f *FileSystem) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, ".") || strings.ContainsAny(key, `/\`) {
+		return "", errors.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(f.Dir, key), nil
+}
@@ -0,0 +1,49 @@
+package blob
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileSystem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blob")
+	if err != nil {
+		t.Fatalf("error running test: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := FileSystem{Dir: dir}
+	err = f.Put("key", strings.NewReader("content"))
+	assert.Nil(t, err, "should not cause error")
+
+	r, err := f.Get("key")
+	assert.Nil(t, err, "should not cause error")
+	content, _ := ioutil.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "content", string(content), "content does not match")
+
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1, "temporary file was not removed")
+
+	err = f.Delete("key")
+	assert.Nil(t, err, "should not cause error")
+	_, err = f.Get("key")
+	assert.NotNil(t, err, "blob was not deleted")
+	assert.Nil(t, f.Delete("key"), "deleting a missing blob caused an error")
+}
+
+func TestFileSystem_InvalidKey(t *testing.T) {
+	t.Parallel()
+	f := FileSystem{Dir: os.TempDir()}
+	for _, key := range []string{"", "../key", "dir/key", `dir\key`, ".hidden"} {
+		t.Run(key, func(t *testing.T) {
+			assert.NotNil(t, f.Put(key, strings.NewReader("")), "invalid key was accepted")
+			_, err := f.Get(key)
+			assert.NotNil(t, err, "invalid key was accepted")
+			assert.NotNil(t, f.Delete(key), "invalid key was accepted")
+		})
+	}
+}
@@ -1,19 +1,42 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/blob"
 	"github.com/eduboard/backend/config"
 	"github.com/eduboard/backend/http"
+	"github.com/eduboard/backend/mail"
 	"github.com/eduboard/backend/mongodb"
+	"github.com/eduboard/backend/notify"
+	"github.com/eduboard/backend/service/assignmentService"
+	"github.com/eduboard/backend/service/attendanceService"
+	"github.com/eduboard/backend/service/commentService"
 	"github.com/eduboard/backend/service/courseEntryService"
 	"github.com/eduboard/backend/service/courseService"
+	"github.com/eduboard/backend/service/enrollmentService"
+	"github.com/eduboard/backend/service/gradebookService"
+	"github.com/eduboard/backend/service/inviteService"
+	"github.com/eduboard/backend/service/materialService"
+	"github.com/eduboard/backend/service/notificationService"
+	"github.com/eduboard/backend/service/pollService"
+	"github.com/eduboard/backend/service/roomService"
+	"github.com/eduboard/backend/service/scheduleService"
+	"github.com/eduboard/backend/service/uploadService"
 	"github.com/eduboard/backend/service/userService"
 )
 
+// shutdownTimeout is the time active requests get to finish when the server is stopped.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	c := config.GetConfig()
 	mongoConfig := mongodb.DBConfig{
@@ -37,18 +60,120 @@ func main() {
 		logDst = file
 	}
 
+	logger := log.New(logDst, "", log.LstdFlags)
+
+	var notifier eduboard.Notifier
+	var queue *mail.Queue
+	switch c.MailDriver {
+	case "log":
+		notifier = &notify.LogNotifier{Logger: logger}
+	case "maildir", "smtp":
+		var mailer mail.Mailer = &mail.Maildir{Dir: c.MailDir}
+		if c.MailDriver == "smtp" {
+			mailer = &mail.SMTP{Host: c.SMTPHost, Port: c.SMTPPort, Username: c.SMTPUser, Password: c.SMTPPass}
+		}
+		queue = mail.NewQueue(mailer, logger, 1000, 2)
+		notifier = &notify.MailNotifier{Mailer: queue, From: c.MailFrom, BaseURL: c.BaseURL}
+	default:
+		log.Fatalf("unknown mail driver %s", c.MailDriver)
+	}
+
+	var blobStore eduboard.BlobStore
+	switch c.UploadDriver {
+	case "fs":
+		blobStore = &blob.FileSystem{Dir: c.UploadDir}
+	case "gridfs":
+		blobStore = repository.BlobStore
+	default:
+		log.Fatalf("unknown upload driver %s", c.UploadDriver)
+	}
+
+	events := http.NewEventHub(http.DefaultEventHistory)
+	notifications := notificationService.New(repository.NotificationRepository, logger)
+	entryService := courseEntryService.New(repository.CourseEntryRepository, events, &notify.EntryPublishedNotifier{
+		Notifier: notifier,
+		Users:    repository.UserRepository,
+		Logger:   logger,
+	}, notifications)
+	scheduler := courseEntryService.Scheduler{
+		Service:  entryService,
+		Courses:  repository.CourseRepository,
+		Interval: time.Minute,
+		Logger:   logger,
+	}
+	stopScheduler := make(chan struct{})
+	schedulerDone := make(chan struct{})
+	go func() {
+		scheduler.Run(stopScheduler)
+		close(schedulerDone)
+	}()
+
+	uploads := uploadService.New(repository.UploadRepository, blobStore)
+	invites := inviteService.New(repository.InviteRepository, repository.CourseRepository, repository.UserRepository, notifier)
+	enrollments := enrollmentService.New(repository.EnrollmentRepository, repository.CourseRepository, notifications)
+	gradebook := gradebookService.New(repository.GradeRepository, repository.GradeCategoryRepository, repository.AssignmentRepository, repository.CourseRepository)
+	materials := materialService.New(repository.MaterialFolderRepository, repository.MaterialRepository, repository.UploadRepository, blobStore, repository.CourseRepository)
+	polls := pollService.New(repository.PollResponseRepository, repository.CourseEntryRepository, repository.CourseRepository, events)
+	attendance := attendanceService.New(repository.AttendanceRepository, repository.CourseRepository)
+	assignments := assignmentService.New(repository.AssignmentRepository, repository.SubmissionRepository, repository.UploadRepository, repository.CourseRepository, gradebook)
+	// The data of a course is deleted in this order. Uploads come last, as other data refers to them.
+	courses := courseService.New(repository.CourseRepository, events, notifications, notifications, invites, enrollments, polls, gradebook, attendance, assignments, materials, uploads)
+
 	server := http.AppServer{
-		Host:                  c.Host,
-		Static:                c.StaticDir,
-		Logger:                log.New(logDst, "", log.LstdFlags),
-		UserService:           userService.New(repository.UserRepository),
-		UserRepository:        repository.UserRepository,
-		CourseService:         courseService.New(repository.CourseRepository),
-		CourseEntryService:    courseEntryService.New(repository.CourseEntryRepository),
-		CourseRepository:      repository.CourseRepository,
-		CourseEntryRepository: repository.CourseEntryRepository,
+		Host:                   c.Host,
+		Static:                 c.StaticDir,
+		Logger:                 logger,
+		UserService:            userService.New(repository.UserRepository, repository.SessionRepository, repository.PasswordResetRepository, repository.VerificationRepository, notifier, logger),
+		UserRepository:         repository.UserRepository,
+		CourseService:          courses,
+		CourseEntryService:     entryService,
+		ScheduleService:        scheduleService.New(repository.CourseRepository, repository.RoomRepository, notifications, attendance),
+		CourseRepository:       repository.CourseRepository,
+		CourseEntryRepository:  repository.CourseEntryRepository,
+		CommentRepository:      repository.CommentRepository,
+		PollResponseRepository: repository.PollResponseRepository,
+		UploadService:          uploads,
+		RoomService:            roomService.New(repository.RoomRepository, repository.CourseRepository),
+		NotificationService:    notifications,
+		CommentService:         commentService.New(repository.CommentRepository),
+		InviteService:          invites,
+		EnrollmentService:      enrollments,
+		AssignmentService:      assignments,
+		GradebookService:       gradebook,
+		AttendanceService:      attendance,
+		PollService:            polls,
+		MaterialService:        materials,
+		Events:                 events,
 	}
 
+	shutdownDone := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		logger.Printf("Shutting down")
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Printf("error shutting down server: %v", err)
+		}
+		close(shutdownDone)
+	}()
+
 	server.Logger.Printf("Server listening on %s", c.Host)
-	server.Run()
+	err := server.Run()
+	if err == nil {
+		<-shutdownDone
+	}
+
+	// Nothing may send mails once the queue is closed, so the scheduler is stopped first.
+	close(stopScheduler)
+	<-schedulerDone
+	if queue != nil {
+		queue.Close()
+	}
+	if err != nil {
+		logger.Fatalf("error running server: %v", err)
+	}
 }
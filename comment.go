@@ -0,0 +1,80 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// Comment is a message of a member on a course entry. Comments either start a thread or reply to one,
+// replies can not be replied to.
+type Comment struct {
+	ID       bson.ObjectId `json:"id" bson:"_id"`
+	CourseID bson.ObjectId `json:"courseID" bson:"courseID"`
+	EntryID  bson.ObjectId `json:"entryID" bson:"entryID"`
+	// ParentID is the comment that starts the thread of a reply. Empty for comments that start a thread.
+	ParentID  bson.ObjectId `json:"parentID,omitempty" bson:"parentID,omitempty"`
+	AuthorID  string        `json:"authorID" bson:"authorID"`
+	Message   string        `json:"message" bson:"message"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+	// EditedAt is nil if the comment has not been edited.
+	EditedAt *time.Time `json:"editedAt,omitempty" bson:"editedAt,omitempty"`
+	// Replies holds the replies of a thread, oldest first. It is not stored.
+	Replies []Comment `json:"replies,omitempty" bson:"-"`
+}
+
+const (
+	// DefaultCommentLimit is the number of threads on a page if no limit is given.
+	DefaultCommentLimit = 20
+	// MaxCommentLimit is the largest number of threads on a page.
+	MaxCommentLimit = 100
+	// MaxCommentLength is the largest number of characters in a comment.
+	MaxCommentLength = 5000
+)
+
+// CommentPage is a page of the threads of an entry, oldest first. Next is the cursor of the following page,
+// or empty on the last page.
+type CommentPage struct {
+	Comments []Comment `json:"comments"`
+	Next     string    `json:"next,omitempty"`
+}
+
+type CommentRepository interface {
+	CommentInserter
+	CommentOneFinder
+	CommentManyFinder
+	CommentUpdater
+	CommentDeleter
+}
+
+type CommentInserter interface {
+	Insert(comment Comment) error
+}
+
+type CommentOneFinder interface {
+	FindOneByID(id string) (error, Comment)
+}
+
+type CommentManyFinder interface {
+	// FindMany returns up to limit comments matching query, oldest first. A limit of 0 returns all of them.
+	FindMany(query bson.M, limit int) (error, []Comment)
+}
+
+type CommentUpdater interface {
+	Update(id string, update bson.M) error
+}
+
+type CommentDeleter interface {
+	// Delete deletes a comment along with its replies.
+	Delete(id string) error
+	// DeleteByEntry deletes all comments of the entry with the given ID.
+	DeleteByEntry(entryID string) error
+	// DeleteByCourse deletes all comments of the course with the given ID.
+	DeleteByCourse(courseID string) error
+}
+
+type CommentService interface {
+	GetComments(courseID string, entryID string, userID string, cursor string, limit int, cf CourseOneFinder, ef CourseEntryOneFinder) (error, CommentPage)
+	CreateComment(courseID string, entryID string, userID string, comment Comment, cf CourseOneFinder, ef CourseEntryOneFinder) (error, Comment)
+	UpdateComment(courseID string, entryID string, commentID string, userID string, message string, cf CourseOneFinder) (error, Comment)
+	DeleteComment(courseID string, entryID string, commentID string, userID string, cf CourseOneFinder) error
+}
@@ -10,7 +10,17 @@ type config struct {
 	MongoUser,
 	MongoPass,
 	StaticDir,
-	LogFile string
+	LogFile,
+	BaseURL,
+	MailDriver,
+	MailFrom,
+	MailDir,
+	SMTPHost,
+	SMTPPort,
+	SMTPUser,
+	SMTPPass,
+	UploadDriver,
+	UploadDir string
 }
 
 func GetConfig() config {
@@ -54,14 +64,74 @@ func GetConfig() config {
 		logFile = ""
 	}
 
+	baseURL, ok := os.LookupEnv("BASE_URL")
+	if !ok {
+		baseURL = "http://localhost:8080"
+	}
+
+	mailDriver, ok := os.LookupEnv("MAIL_DRIVER")
+	if !ok {
+		mailDriver = "log"
+	}
+
+	mailFrom, ok := os.LookupEnv("MAIL_FROM")
+	if !ok {
+		mailFrom = "eduBoard <noreply@eduboard.io>"
+	}
+
+	mailDir, ok := os.LookupEnv("MAIL_DIR")
+	if !ok {
+		mailDir = "./maildir"
+	}
+
+	smtpHost, ok := os.LookupEnv("SMTP_HOST")
+	if !ok {
+		smtpHost = "localhost"
+	}
+
+	smtpPort, ok := os.LookupEnv("SMTP_PORT")
+	if !ok {
+		smtpPort = "25"
+	}
+
+	smtpUser, ok := os.LookupEnv("SMTP_USER")
+	if !ok {
+		smtpUser = ""
+	}
+
+	smtpPass, ok := os.LookupEnv("SMTP_PASS")
+	if !ok {
+		smtpPass = ""
+	}
+
+	uploadDriver, ok := os.LookupEnv("UPLOAD_DRIVER")
+	if !ok {
+		uploadDriver = "fs"
+	}
+
+	uploadDir, ok := os.LookupEnv("UPLOAD_DIR")
+	if !ok {
+		uploadDir = "./uploads"
+	}
+
 	return config{
-		Host:      host,
-		MongoHost: mongoHost,
-		MongoPort: mongoPort,
-		MongoDB:   mongoDB,
-		MongoUser: mongoUser,
-		MongoPass: mongPass,
-		StaticDir: staticDir,
-		LogFile:   logFile,
+		Host:         host,
+		MongoHost:    mongoHost,
+		MongoPort:    mongoPort,
+		MongoDB:      mongoDB,
+		MongoUser:    mongoUser,
+		MongoPass:    mongPass,
+		StaticDir:    staticDir,
+		LogFile:      logFile,
+		BaseURL:      baseURL,
+		MailDriver:   mailDriver,
+		MailFrom:     mailFrom,
+		MailDir:      mailDir,
+		SMTPHost:     smtpHost,
+		SMTPPort:     smtpPort,
+		SMTPUser:     smtpUser,
+		SMTPPass:     smtpPass,
+		UploadDriver: uploadDriver,
+		UploadDir:    uploadDir,
 	}
 }
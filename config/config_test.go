@@ -19,6 +19,16 @@ func TestGetConfig(t *testing.T) {
 		{"MONGO_PASS", "testpass"},
 		{"STATIC_DIR", "testdir"},
 		{"LOGFILE", "backend.log"},
+		{"BASE_URL", "https://eduboard.io"},
+		{"MAIL_DRIVER", "smtp"},
+		{"MAIL_FROM", "test@eduboard.io"},
+		{"MAIL_DIR", "testmaildir"},
+		{"SMTP_HOST", "testsmtp"},
+		{"SMTP_PORT", "587"},
+		{"SMTP_USER", "testsmtpuser"},
+		{"SMTP_PASS", "testsmtppass"},
+		{"UPLOAD_DRIVER", "gridfs"},
+		{"UPLOAD_DIR", "testuploaddir"},
 	}
 
 	var testCases = []struct {
@@ -35,7 +45,17 @@ func TestGetConfig(t *testing.T) {
 				"testuser",
 				"testpass",
 				"testdir",
-				"backend.log"}},
+				"backend.log",
+				"https://eduboard.io",
+				"smtp",
+				"test@eduboard.io",
+				"testmaildir",
+				"testsmtp",
+				"587",
+				"testsmtpuser",
+				"testsmtppass",
+				"gridfs",
+				"testuploaddir"}},
 		{"unset", true,
 			config{
 				":8080",
@@ -45,7 +65,17 @@ func TestGetConfig(t *testing.T) {
 				"",
 				"",
 				"./static",
-				""}},
+				"",
+				"http://localhost:8080",
+				"log",
+				"eduBoard <noreply@eduboard.io>",
+				"./maildir",
+				"localhost",
+				"25",
+				"",
+				"",
+				"fs",
+				"./uploads"}},
 	}
 
 	for _, v := range testCases {
@@ -9,20 +9,169 @@ type Course struct {
 	ID          bson.ObjectId   `json:"id,omitempty" bson:"_id"`
 	Title       string          `json:"title,omitempty" bson:"title,omitempty"`
 	Description string          `json:"description,omitempty" bson:"description,omitempty"`
-	Members     []string        `json:"members,omitempty" bson:"members,omitempty"`
+	Members     []Member        `json:"members,omitempty" bson:"members,omitempty"`
 	CreatedAt   time.Time       `json:"createdAt" bson:"createdAt"`
 	Labels      []string        `json:"labels" bson:"labels"`
 	EntryIDs    []bson.ObjectId `json:"entryIDs" bson:"entryIDs"`
 	Entries     []CourseEntry   `json:"entries" bson:"entries"`
 	Schedules   []Schedule      `json:"schedules" bson:"schedules"`
+	Archived    bool            `json:"archived" bson:"archived"`
+	// Enrollment is empty for courses created before enrollment policies, see EnrollmentPolicy.
+	Enrollment EnrollmentPolicy `json:"enrollment,omitempty" bson:"enrollment,omitempty"`
 }
 
-type Schedule struct {
-	Day      time.Weekday  `json:"day" bson:"day"`
-	Start    time.Time     `json:"startsAt" bson:"startsAt"`
-	Duration time.Duration `json:"duration,omitempty" bson:"duration"`
-	Room     string        `json:"room,omitempty" bson:"room"`
-	Title    string        `json:"title,omitempty" bson:"title"`
+// EnrollmentPolicy decides how students who are not invited can join a course.
+type EnrollmentPolicy string
+
+const (
+	// EnrollmentOpen lets everyone join right away.
+	EnrollmentOpen EnrollmentPolicy = "open"
+	// EnrollmentApproval lets students request to join. The staff approves or rejects each request.
+	EnrollmentApproval EnrollmentPolicy = "approval-required"
+	// EnrollmentInviteOnly only lets students join through the staff or an invite.
+	EnrollmentInviteOnly EnrollmentPolicy = "invite-only"
+)
+
+// IsValid reports whether p is one of the known policies.
+func (p EnrollmentPolicy) IsValid() bool {
+	return p == EnrollmentOpen || p == EnrollmentApproval || p == EnrollmentInviteOnly
+}
+
+// EnrollmentPolicy returns the enrollment policy of the course. Courses without a policy are invite-only.
+func (c Course) EnrollmentPolicy() EnrollmentPolicy {
+	if c.Enrollment == "" {
+		return EnrollmentInviteOnly
+	}
+	return c.Enrollment
+}
+
+// Role describes what a member is allowed to do within a course.
+type Role string
+
+const (
+	RoleOwner   Role = "owner"
+	RoleTeacher Role = "teacher"
+	RoleStudent Role = "student"
+)
+
+// IsStaff reports whether the role may manage members and entries of a course.
+func (r Role) IsStaff() bool {
+	return r == RoleOwner || r == RoleTeacher
+}
+
+// IsValid reports whether r is one of the known roles.
+func (r Role) IsValid() bool {
+	return r == RoleOwner || r == RoleTeacher || r == RoleStudent
+}
+
+type Member struct {
+	UserID string `json:"id" bson:"userID"`
+	Role   Role   `json:"role" bson:"role"`
+}
+
+// MemberIDs returns the user IDs of all members of the course.
+func (c Course) MemberIDs() []string {
+	ids := make([]string, len(c.Members))
+	for k, v := range c.Members {
+		ids[k] = v.UserID
+	}
+	return ids
+}
+
+// StaffIDs returns the user IDs of the owner and the teachers of the course.
+func (c Course) StaffIDs() []string {
+	ids := []string{}
+	for _, v := range c.Members {
+		if v.Role.IsStaff() {
+			ids = append(ids, v.UserID)
+		}
+	}
+	return ids
+}
+
+// StudentIDs returns the user IDs of the students of the course.
+func (c Course) StudentIDs() []string {
+	ids := []string{}
+	for _, v := range c.Members {
+		if v.Role == RoleStudent {
+			ids = append(ids, v.UserID)
+		}
+	}
+	return ids
+}
+
+// RoleOf returns the role userID holds in the course. ok is false if userID is not a member.
+func (c Course) RoleOf(userID string) (role Role, ok bool) {
+	for _, v := range c.Members {
+		if v.UserID == userID {
+			return v.Role, true
+		}
+	}
+	return "", false
+}
+
+// IsStaff reports whether userID is an owner or teacher of the course.
+func (c Course) IsStaff(userID string) bool {
+	role, ok := c.RoleOf(userID)
+	return ok && role.IsStaff()
+}
+
+// VisibleEntries returns the entries of the course userID may see. Drafts are only visible to staff.
+func (c Course) VisibleEntries(userID string) []CourseEntry {
+	if c.IsStaff(userID) {
+		return c.Entries
+	}
+
+	visible := []CourseEntry{}
+	for _, e := range c.Entries {
+		if e.Published {
+			visible = append(visible, e)
+		}
+	}
+	return visible
+}
+
+// CourseUpdate holds the changes to the details of a course. Nil fields are left untouched.
+type CourseUpdate struct {
+	Title       *string
+	Description *string
+	Labels      []string
+	Schedules   []Schedule
+	Enrollment  *EnrollmentPolicy
+}
+
+const (
+	// DefaultCourseLimit is the page size of course searches that do not set a limit.
+	DefaultCourseLimit = 20
+	// MaxCourseLimit is the largest page size of course searches.
+	MaxCourseLimit = 100
+)
+
+// CourseSortFields are the fields courses can be sorted by. Prefixing a field with "-" sorts in descending order,
+// except for relevance, which is always sorted best match first and requires a Text search.
+var CourseSortFields = map[string]bool{"title": true, "createdAt": true, "relevance": true}
+
+// CourseQuery filters, sorts and paginates courses. Empty fields do not filter.
+type CourseQuery struct {
+	// Text is searched in title, description and labels.
+	Text string
+	// Labels only matches courses with all of the labels.
+	Labels []string
+	// Member only matches courses the user with this ID is a member of.
+	Member string
+	// Archived matches archived instead of active courses.
+	Archived bool
+	Sort     string
+	Offset   int
+	Limit    int
+}
+
+// CoursePage is a page of a course search along with the number of all matching courses.
+type CoursePage struct {
+	Courses []Course
+	Total   int
+	Offset  int
+	Limit   int
 }
 
 type CourseInserter interface {
@@ -37,10 +186,30 @@ type CourseManyFinder interface {
 	FindMany(query bson.M) (error, []Course)
 }
 
+type CourseSearcher interface {
+	Search(query CourseQuery) (error, []Course, int)
+}
+
 type CourseUpdater interface {
 	Update(id string, update bson.M) (error, Course)
 }
 
+// CourseMemberAdder adds members to courses.
+type CourseMemberAdder interface {
+	// AddMember adds member to a course unless the user is a member already. The check is part of the update, so
+	// concurrent additions can not add a user twice. It returns the course and whether member was added.
+	AddMember(id string, member Member) (error, Course, bool)
+}
+
+type CourseDeleter interface {
+	Delete(id string) error
+}
+
+// CourseDataDeleter deletes data belonging to a course when the course is deleted.
+type CourseDataDeleter interface {
+	DeleteByCourse(courseID string) error
+}
+
 type CourseFindUpdater interface {
 	CourseOneFinder
 	CourseUpdater
@@ -50,15 +219,23 @@ type CourseRepository interface {
 	CourseInserter
 	CourseOneFinder
 	CourseManyFinder
+	CourseSearcher
 	CourseUpdater
+	CourseMemberAdder
+	CourseDeleter
 }
 
 type CourseService interface {
-	CreateCourse(c *Course) (*Course, error)
-	GetAllCourses() (err error, courses []Course)
-	GetCourse(id string, cef CourseEntryManyFinder) (err error, course Course)
+	CreateCourse(c *Course, ownerID string) (*Course, error)
+	SearchCourses(query CourseQuery) (error, CoursePage)
+	GetCourse(id string, userID string, cef CourseEntryManyFinder) (err error, course Course)
 	GetCoursesByMember(id string, cef CourseEntryManyFinder) (err error, courses []Course)
 	GetMembers(id string, uF UserFinder) (error, []User)
-	AddMembers(id string, members []string) (error, Course)
-	RemoveMembers(id string, members []string) (error, Course)
+	AddMembers(id string, userID string, members []Member) (error, Course)
+	JoinCourse(id string, userID string) (error, Course)
+	RemoveMembers(id string, userID string, members []string) (error, Course)
+	UpdateCourse(id string, userID string, update CourseUpdate, sc ScheduleChecker) (error, Course)
+	ArchiveCourse(id string, userID string) (error, Course)
+	RestoreCourse(id string, userID string, sc ScheduleChecker) (error, Course)
+	DeleteCourse(id string, userID string, ced CourseEntryDeleter, cd CommentDeleter, ucr UserCourseRemover) error
 }
@@ -14,12 +14,59 @@ type CourseEntry struct {
 	Message   string        `json:"message" bson:"message"`
 	Pictures  []url.URL     `json:"pictures" bson:"pictures"`
 	Published bool          `json:"published" bson:"published"`
+	// PublishAt schedules the publication of a draft. Zero if the entry is not scheduled.
+	PublishAt time.Time `json:"publishAt,omitempty" bson:"publishAt,omitempty"`
+	// Poll is nil if no poll is attached to the entry.
+	Poll *Poll `json:"poll,omitempty" bson:"poll,omitempty"`
+}
+
+// CourseEntryUpdate describes a partial update of a CourseEntry. Fields that are nil are left untouched,
+// a zero PublishAt cancels a scheduled publication.
+type CourseEntryUpdate struct {
+	Date      *time.Time
+	Message   *string
+	Pictures  []url.URL
+	Published *bool
+	PublishAt *time.Time
+}
+
+// EntryPublishedHook is informed whenever an entry becomes visible to all members of its course,
+// either when it is published directly or by the scheduler.
+type EntryPublishedHook interface {
+	EntryPublished(course Course, entry CourseEntry)
+}
+
+const (
+	// DefaultEntryLimit is the page size of entry feeds that do not set a limit.
+	DefaultEntryLimit = 20
+	// MaxEntryLimit is the largest page size of entry feeds.
+	MaxEntryLimit = 100
+)
+
+// CourseEntryFilter filters and paginates a feed of entries, which is always sorted newest first.
+// Zero values do not filter.
+type CourseEntryFilter struct {
+	// From and To limit the feed to entries dated From <= date < To.
+	From time.Time
+	To   time.Time
+	// Published only matches published entries if true and drafts if false.
+	Published *bool
+	// Cursor continues a feed after the last entry of a previous page.
+	Cursor string
+	Limit  int
+}
+
+// CourseEntryPage is a page of an entry feed. Next is the cursor of the following page, or empty on the last page.
+type CourseEntryPage struct {
+	Entries []CourseEntry
+	Next    string
 }
 
 type CourseEntryRepository interface {
 	CourseEntryInserter
 	CourseEntryOneFinder
 	CourseEntryManyFinder
+	CourseEntryFeedFinder
 	CourseEntryUpdater
 	CourseEntryDeleter
 }
@@ -36,16 +83,30 @@ type CourseEntryManyFinder interface {
 	FindMany(query bson.M) (error, []CourseEntry)
 }
 
+type CourseEntryFeedFinder interface {
+	// FindFeed returns up to limit entries matching query, newest first.
+	FindFeed(query bson.M, limit int) (error, []CourseEntry)
+}
+
 type CourseEntryUpdater interface {
 	Update(id string, update bson.M) error
+	// UpdateIf applies update to an entry only if the entry also matches query. It fails with ErrNotFound otherwise.
+	UpdateIf(id string, query bson.M, update bson.M) error
+	// PublishDue publishes all drafts scheduled at or before now and returns them.
+	PublishDue(now time.Time) (error, []CourseEntry)
 }
 
 type CourseEntryDeleter interface {
 	Delete(id string) error
+	// DeleteByCourse deletes all entries of the course with the given ID.
+	DeleteByCourse(courseID string) error
 }
 
 type CourseEntryService interface {
-	StoreCourseEntry(entry *CourseEntry, cfu CourseFindUpdater) (err error, courseEntry *CourseEntry)
-	UpdateCourseEntry(*CourseEntry) (*CourseEntry, error)
-	DeleteCourseEntry(entryID string, courseID string, updater CourseUpdater) error
+	StoreCourseEntry(entry *CourseEntry, userID string, cfu CourseFindUpdater) (err error, courseEntry *CourseEntry)
+	UpdateCourseEntry(entryID string, courseID string, userID string, update CourseEntryUpdate, cf CourseOneFinder) (*CourseEntry, error)
+	DeleteCourseEntry(entryID string, courseID string, userID string, cfu CourseFindUpdater, cd CommentDeleter, pd PollResponseDeleter) error
+	GetCourseEntries(courseID string, userID string, filter CourseEntryFilter, cf CourseOneFinder) (error, CourseEntryPage)
+	GetFeed(userID string, filter CourseEntryFilter, cmf CourseManyFinder) (error, CourseEntryPage)
+	PublishDue(now time.Time, cf CourseOneFinder) (error, []CourseEntry)
 }
@@ -0,0 +1,61 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// EnrollmentStatus is the state of an EnrollmentRequest.
+type EnrollmentStatus string
+
+const (
+	EnrollmentPending  EnrollmentStatus = "pending"
+	EnrollmentApproved EnrollmentStatus = "approved"
+	EnrollmentRejected EnrollmentStatus = "rejected"
+)
+
+// EnrollmentRequest is the request of a student to join a course. There is at most one request per user and course,
+// requesting again after a rejection replaces it.
+type EnrollmentRequest struct {
+	ID       bson.ObjectId    `json:"id" bson:"_id"`
+	CourseID bson.ObjectId    `json:"courseID" bson:"courseID"`
+	UserID   string           `json:"userID" bson:"userID"`
+	Status   EnrollmentStatus `json:"status" bson:"status"`
+	// Message is an optional note of the student to the staff.
+	Message   string    `json:"message,omitempty" bson:"message,omitempty"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	// DecidedBy and DecidedAt are set once the request was approved or rejected.
+	DecidedBy string     `json:"decidedBy,omitempty" bson:"decidedBy,omitempty"`
+	DecidedAt *time.Time `json:"decidedAt,omitempty" bson:"decidedAt,omitempty"`
+}
+
+// MaxEnrollmentMessageLength is the largest number of characters in the message of an enrollment request.
+const MaxEnrollmentMessageLength = 1000
+
+type EnrollmentRepository interface {
+	Insert(request *EnrollmentRequest) error
+	FindOneByID(id string) (error, EnrollmentRequest)
+	// Find returns the request of userID to join a course.
+	Find(courseID string, userID string) (error, EnrollmentRequest)
+	// FindByCourse returns the requests of a course with the given status, oldest first.
+	FindByCourse(courseID string, status EnrollmentStatus) (error, []EnrollmentRequest)
+	// Decide approves or rejects a pending request. It fails if the request is not pending anymore.
+	Decide(id string, status EnrollmentStatus, userID string, now time.Time) error
+	// DeletePending deletes the pending request of userID to join a course.
+	DeletePending(courseID string, userID string) error
+	Delete(id string) error
+	DeleteByCourse(courseID string) error
+}
+
+type EnrollmentService interface {
+	// RequestEnrollment asks to join a course. Students join open courses right away and get an approved request
+	// that is not stored.
+	RequestEnrollment(courseID string, userID string, message string, cj CourseJoiner) (error, EnrollmentRequest)
+	GetEnrollment(courseID string, userID string) (error, EnrollmentRequest)
+	CancelEnrollment(courseID string, userID string) error
+	GetPendingEnrollments(courseID string, userID string) (error, []EnrollmentRequest)
+	// ApproveEnrollment adds the requesting student to the course through CourseJoiner.AddMembers.
+	ApproveEnrollment(courseID string, requestID string, userID string, cj CourseJoiner) (error, EnrollmentRequest)
+	RejectEnrollment(courseID string, requestID string, userID string) (error, EnrollmentRequest)
+	DeleteByCourse(courseID string) error
+}
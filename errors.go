@@ -0,0 +1,21 @@
+package eduboard
+
+import "errors"
+
+// ErrForbidden is returned by services if the acting user lacks the permission for an operation.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrInvalidInput is returned by services if the input of an operation can not be processed.
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrArchived is returned by services if an operation would modify an archived course.
+var ErrArchived = errors.New("course is archived")
+
+// ErrExpired is returned by services if an invitation or token has expired, was revoked or is used up.
+var ErrExpired = errors.New("expired")
+
+// ErrNotFound is returned by services if an object does not exist or does not belong to the given course.
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicate is returned by repositories if an object clashes with an existing one on a unique field.
+var ErrDuplicate = errors.New("duplicate")
@@ -0,0 +1,36 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// EventType is the kind of change an Event reports.
+type EventType string
+
+const (
+	EventEntryCreated  EventType = "entry.created"
+	EventEntryUpdated  EventType = "entry.updated"
+	EventEntryDeleted  EventType = "entry.deleted"
+	EventPollUpdated   EventType = "poll.updated"
+	EventMemberAdded   EventType = "member.added"
+	EventMemberRemoved EventType = "member.removed"
+)
+
+// Event reports a change of a course to the users in Recipients. Events only carry IDs, clients fetch the changed
+// resources themselves, so that content is never sent to users who may not see it.
+type Event struct {
+	// ID is assigned by the EventPublisher.
+	ID         string        `json:"id"`
+	Type       EventType     `json:"type"`
+	CourseID   bson.ObjectId `json:"courseID"`
+	EntryID    bson.ObjectId `json:"entryID,omitempty"`
+	UserIDs    []string      `json:"userIDs,omitempty"`
+	Time       time.Time     `json:"time"`
+	Recipients []string      `json:"-"`
+}
+
+// EventPublisher delivers events to the connected clients of their recipients.
+type EventPublisher interface {
+	Publish(event Event)
+}
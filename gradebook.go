@@ -0,0 +1,75 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"time"
+)
+
+// Grade is the score and feedback a student received for an assignment.
+type Grade struct {
+	ID           bson.ObjectId `json:"id" bson:"_id"`
+	CourseID     bson.ObjectId `json:"courseID" bson:"courseID"`
+	AssignmentID bson.ObjectId `json:"assignmentID" bson:"assignmentID"`
+	UserID       string        `json:"userID" bson:"userID"`
+	Points       float64       `json:"points" bson:"points"`
+	Feedback     string        `json:"feedback" bson:"feedback"`
+	GradedBy     string        `json:"gradedBy" bson:"gradedBy"`
+	GradedAt     time.Time     `json:"gradedAt" bson:"gradedAt"`
+}
+
+// GradeCategory groups assignments whose scores count towards the course total with the same weight.
+type GradeCategory struct {
+	Name   string  `json:"name" bson:"name"`
+	Weight float64 `json:"weight" bson:"weight"`
+}
+
+// MaxFeedbackLength is the largest number of characters in the feedback of a grade.
+const MaxFeedbackLength = 5000
+
+// GradebookRow holds the grades of one student. Category scores and the total are percentages; they are nil while
+// nothing counting towards them was graded.
+type GradebookRow struct {
+	UserID     string              `json:"userID"`
+	Grades     []Grade             `json:"grades"`
+	Categories map[string]*float64 `json:"categories"`
+	Total      *float64            `json:"total"`
+}
+
+// Gradebook is the matrix of graded assignments and the students of a course.
+type Gradebook struct {
+	CourseID    bson.ObjectId   `json:"courseID"`
+	Categories  []GradeCategory `json:"categories"`
+	Assignments []Assignment    `json:"assignments"`
+	Rows        []GradebookRow  `json:"rows"`
+}
+
+type GradeRepository interface {
+	// Upsert stores a grade, replacing an earlier one of the same student for the same assignment.
+	Upsert(grade *Grade) error
+	FindByCourse(courseID string) (error, []Grade)
+	FindByUser(courseID string, userID string) (error, []Grade)
+	Delete(assignmentID string, userID string) error
+	DeleteByAssignment(assignmentID string) error
+	DeleteByCourse(courseID string) error
+}
+
+// GradeCategoryRepository stores the grade categories of courses.
+type GradeCategoryRepository interface {
+	Find(courseID string) (error, []GradeCategory)
+	Set(courseID string, categories []GradeCategory) error
+	Delete(courseID string) error
+}
+
+type GradebookService interface {
+	GetCategories(courseID string, userID string) (error, []GradeCategory)
+	SetCategories(courseID string, userID string, categories []GradeCategory) (error, []GradeCategory)
+	SetGrade(courseID string, assignmentID string, studentID string, userID string, points float64, feedback string) (error, Grade)
+	DeleteGrade(courseID string, assignmentID string, studentID string, userID string) error
+	// GetGradebook returns the whole gradebook to the staff and the own row to students.
+	GetGradebook(courseID string, userID string) (error, Gradebook)
+	// ExportGradebook writes the gradebook as CSV with one line per student. Only the staff may export it.
+	ExportGradebook(courseID string, userID string, uf UserFinder, w io.Writer) error
+	DeleteByAssignment(assignmentID string) error
+	DeleteByCourse(courseID string) error
+}
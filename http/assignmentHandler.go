@@ -0,0 +1,190 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+func (a *AppServer) GetAssignmentsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, assignments := a.AssignmentService.GetAssignments(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting assignments: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(assignments); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) GetAssignmentHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, assignment := a.AssignmentService.GetAssignment(p.ByName("courseID"), p.ByName("assignmentID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting assignment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(assignment); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) PostAssignmentHandler() httprouter.Handle {
+	type request struct {
+		Title       string    `json:"title"`
+		Description string    `json:"description"`
+		DueAt       time.Time `json:"dueAt"`
+		Attachments []string  `json:"attachments"`
+		MaxPoints   float64   `json:"maxPoints"`
+		Category    string    `json:"category"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		assignment := eduboard.Assignment{
+			Title:       req.Title,
+			Description: req.Description,
+			DueAt:       req.DueAt,
+			Attachments: req.Attachments,
+			MaxPoints:   req.MaxPoints,
+			Category:    req.Category,
+		}
+		err, assignment := a.AssignmentService.CreateAssignment(p.ByName("courseID"), r.Header.Get("userID"), assignment)
+		if err != nil {
+			a.Logger.Printf("error creating assignment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(assignment); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+// UpdateAssignmentHandler changes the fields present in the request body.
+func (a *AppServer) UpdateAssignmentHandler() httprouter.Handle {
+	type request struct {
+		Title       *string    `json:"title"`
+		Description *string    `json:"description"`
+		DueAt       *time.Time `json:"dueAt"`
+		Attachments []string   `json:"attachments"`
+		MaxPoints   *float64   `json:"maxPoints"`
+		Category    *string    `json:"category"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		update := eduboard.AssignmentUpdate{
+			Title:       req.Title,
+			Description: req.Description,
+			DueAt:       req.DueAt,
+			Attachments: req.Attachments,
+			MaxPoints:   req.MaxPoints,
+			Category:    req.Category,
+		}
+		err, assignment := a.AssignmentService.UpdateAssignment(p.ByName("courseID"), p.ByName("assignmentID"), r.Header.Get("userID"), update)
+		if err != nil {
+			a.Logger.Printf("error updating assignment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(assignment); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) DeleteAssignmentHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.AssignmentService.DeleteAssignment(p.ByName("courseID"), p.ByName("assignmentID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error deleting assignment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// PutSubmissionHandler stores the submission of the current user, replacing an earlier one.
+func (a *AppServer) PutSubmissionHandler() httprouter.Handle {
+	type request struct {
+		Text        string   `json:"text"`
+		Attachments []string `json:"attachments"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		submission := eduboard.Submission{Text: req.Text, Attachments: req.Attachments}
+		err, submission := a.AssignmentService.Submit(p.ByName("courseID"), p.ByName("assignmentID"), r.Header.Get("userID"), submission)
+		if err != nil {
+			a.Logger.Printf("error submitting assignment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(submission); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+// GetSubmissionHandler returns the submission of the current user.
+func (a *AppServer) GetSubmissionHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, submission := a.AssignmentService.GetSubmission(p.ByName("courseID"), p.ByName("assignmentID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting submission: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(submission); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) GetSubmissionsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, submissions := a.AssignmentService.GetSubmissions(p.ByName("courseID"), p.ByName("assignmentID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting submissions: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(submissions); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppServer_GetAssignmentsHandler(t *testing.T) {
+	service := mock.AssignmentService{}
+	service.GetAssignmentsFn = func(courseID string, userID string) (error, []eduboard.Assignment) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "no member"), []eduboard.Assignment{}
+		}
+		return nil, []eduboard.Assignment{{Title: "Sorting"}}
+	}
+	a := AppServer{AssignmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"member", "1", 200},
+		{"no member", "2", 403},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetAssignmentsHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"title":"Sorting"`, "title is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_PostAssignmentHandler(t *testing.T) {
+	service := mock.AssignmentService{}
+	service.CreateAssignmentFn = func(courseID string, userID string, assignment eduboard.Assignment) (error, eduboard.Assignment) {
+		if assignment.Title == "" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "empty title"), eduboard.Assignment{}
+		}
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Assignment{}
+		}
+		assert.Equal(t, time.Date(2018, 7, 8, 23, 59, 0, 0, time.UTC), assignment.DueAt.UTC(), "due date does not match")
+		assignment.CreatedBy = userID
+		return nil, assignment
+	}
+	a := AppServer{AssignmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		user    string
+		body    string
+		status  int
+		invoked bool
+	}{
+		{"success", "1", `{"title":"Sorting","dueAt":"2018-07-08T23:59:00Z","attachments":["a"]}`, 201, true},
+		{"not staff", "2", `{"title":"Sorting","dueAt":"2018-07-08T23:59:00Z"}`, 403, true},
+		{"empty title", "1", `{"dueAt":"2018-07-08T23:59:00Z"}`, 400, true},
+		{"malformed json", "1", `{"title":`, 400, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.CreateAssignmentFnInvoked = false
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.PostAssignmentHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.CreateAssignmentFnInvoked, "CreateAssignment was not invoked as expected")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"attachments":["a"]`, "attachments are missing")
+				assert.Contains(t, rr.Body.String(), `"createdBy":"1"`, "creator is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_UpdateAssignmentHandler(t *testing.T) {
+	service := mock.AssignmentService{}
+	service.UpdateAssignmentFn = func(courseID string, assignmentID string, userID string, update eduboard.AssignmentUpdate) (error, eduboard.Assignment) {
+		if assignmentID != "assignment" {
+			return errors.New("not found"), eduboard.Assignment{}
+		}
+		assert.Nil(t, update.Title, "title was set")
+		assert.Nil(t, update.Attachments, "attachments were set")
+		assert.NotNil(t, update.DueAt, "due date was not set")
+		return nil, eduboard.Assignment{DueAt: *update.DueAt}
+	}
+	a := AppServer{AssignmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name       string
+		assignment string
+		body       string
+		status     int
+	}{
+		{"success", "assignment", `{"dueAt":"2018-07-08T23:59:00Z"}`, 200},
+		{"unknown", "unknown", `{"dueAt":"2018-07-08T23:59:00Z"}`, 404},
+		{"malformed json", "assignment", `{"dueAt":`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.UpdateAssignmentHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "assignmentID", Value: v.assignment}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"dueAt":"2018-07-08T23:59:00Z"`, "due date is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_DeleteAssignmentHandler(t *testing.T) {
+	service := mock.AssignmentService{}
+	service.DeleteAssignmentFn = func(courseID string, assignmentID string, userID string) error {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff")
+		}
+		return nil
+	}
+	a := AppServer{AssignmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"1": 204, "2": 403} {
+		r := httptest.NewRequest("DELETE", "/", nil)
+		r.Header.Set("userID", user)
+		rr := httptest.NewRecorder()
+
+		a.DeleteAssignmentHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "assignmentID", Value: "assignment"}})
+		assert.Equal(t, status, rr.Code, "status code does not match")
+	}
+}
+
+func TestAppServer_PutSubmissionHandler(t *testing.T) {
+	service := mock.AssignmentService{}
+	service.SubmitFn = func(courseID string, assignmentID string, userID string, submission eduboard.Submission) (error, eduboard.Submission) {
+		switch userID {
+		case "teacher":
+			return errors.Wrap(eduboard.ErrForbidden, "no student"), eduboard.Submission{}
+		case "archived":
+			return errors.Wrap(eduboard.ErrArchived, "archived"), eduboard.Submission{}
+		}
+		submission.UserID = userID
+		submission.Late = true
+		return nil, submission
+	}
+	a := AppServer{AssignmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		user    string
+		body    string
+		status  int
+		invoked bool
+	}{
+		{"success", "student", `{"text":"Quicksort","attachments":["a"]}`, 200, true},
+		{"teacher", "teacher", `{"text":"Quicksort"}`, 403, true},
+		{"archived", "archived", `{"text":"Quicksort"}`, 409, true},
+		{"malformed json", "student", `{"text":`, 400, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.SubmitFnInvoked = false
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.PutSubmissionHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "assignmentID", Value: "assignment"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.SubmitFnInvoked, "Submit was not invoked as expected")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"text":"Quicksort"`, "text is missing")
+				assert.Contains(t, rr.Body.String(), `"late":true`, "late flag is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetSubmissionHandler(t *testing.T) {
+	service := mock.AssignmentService{}
+	service.GetSubmissionFn = func(courseID string, assignmentID string, userID string) (error, eduboard.Submission) {
+		if userID != "student" {
+			return errors.New("not found"), eduboard.Submission{}
+		}
+		return nil, eduboard.Submission{UserID: userID, Text: "Quicksort"}
+	}
+	a := AppServer{AssignmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"student": 200, "other": 404} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("userID", user)
+		rr := httptest.NewRecorder()
+
+		a.GetSubmissionHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "assignmentID", Value: "assignment"}})
+		assert.Equal(t, status, rr.Code, "status code does not match")
+	}
+}
+
+func TestAppServer_GetSubmissionsHandler(t *testing.T) {
+	service := mock.AssignmentService{}
+	service.GetSubmissionsFn = func(courseID string, assignmentID string, userID string) (error, []eduboard.Submission) {
+		if userID != "teacher" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), []eduboard.Submission{}
+		}
+		return nil, []eduboard.Submission{{UserID: "student", Late: true}}
+	}
+	a := AppServer{AssignmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"teacher", "teacher", 200},
+		{"student", "student", 403},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetSubmissionsHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "assignmentID", Value: "assignment"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"userID":"student"`, "submission is missing")
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+func (a *AppServer) GetAttendanceSessionsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, sessions := a.AttendanceService.GetSessions(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting attendance sessions: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(sessions); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// PostAttendanceSessionHandler returns the session of a meeting, creating it if there is none yet.
+func (a *AppServer) PostAttendanceSessionHandler() httprouter.Handle {
+	type request struct {
+		ScheduleID string `json:"scheduleID"`
+		Date       string `json:"date"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, session := a.AttendanceService.OpenSession(p.ByName("courseID"), req.ScheduleID, req.Date, r.Header.Get("userID"), a.ScheduleService)
+		if err != nil {
+			a.Logger.Printf("error opening attendance session: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(session); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) GetAttendanceSessionHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, session := a.AttendanceService.GetSession(p.ByName("courseID"), p.ByName("sessionID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting attendance session: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(session); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) DeleteAttendanceSessionHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.AttendanceService.DeleteSession(p.ByName("courseID"), p.ByName("sessionID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error deleting attendance session: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// OpenCheckInHandler sets a new check-in code for a session.
+func (a *AppServer) OpenCheckInHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, session := a.AttendanceService.OpenCheckIn(p.ByName("courseID"), p.ByName("sessionID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error opening check-in: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(session); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) CloseCheckInHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, session := a.AttendanceService.CloseCheckIn(p.ByName("courseID"), p.ByName("sessionID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error closing check-in: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(session); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// MarkAttendanceHandler sets the status of the students in the request body.
+func (a *AppServer) MarkAttendanceHandler() httprouter.Handle {
+	type request struct {
+		UserID string                    `json:"userID"`
+		Status eduboard.AttendanceStatus `json:"status"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		req := []request{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		records := make([]eduboard.AttendanceRecord, len(req))
+		for k, v := range req {
+			records[k] = eduboard.AttendanceRecord{UserID: v.UserID, Status: v.Status}
+		}
+		err, session := a.AttendanceService.MarkAttendance(p.ByName("courseID"), p.ByName("sessionID"), r.Header.Get("userID"), records)
+		if err != nil {
+			a.Logger.Printf("error marking attendance: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(session); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// CheckInHandler records the current user as present in the session whose check-in code was sent.
+func (a *AppServer) CheckInHandler() httprouter.Handle {
+	type request struct {
+		Code string `json:"code"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, session := a.AttendanceService.CheckIn(p.ByName("courseID"), r.Header.Get("userID"), req.Code)
+		if err != nil {
+			a.Logger.Printf("error checking in: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(session); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) GetAttendanceReportHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, report := a.AttendanceService.GetCourseReport(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting attendance report: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(report); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) GetStudentAttendanceReportHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, report := a.AttendanceService.GetStudentReport(p.ByName("courseID"), p.ByName("userID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting attendance report: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(report); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAppServer_PostAttendanceSessionHandler(t *testing.T) {
+	schedules := &mock.ScheduleService{}
+	service := mock.AttendanceService{}
+	service.OpenSessionFn = func(courseID string, scheduleID string, date string, userID string, of eduboard.OccurrenceFinder) (error, eduboard.AttendanceSession) {
+		assert.Equal(t, schedules, of, "schedule service was not passed")
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.AttendanceSession{}
+		}
+		if date != "2018-01-08" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "no meeting"), eduboard.AttendanceSession{}
+		}
+		return nil, eduboard.AttendanceSession{ID: bson.NewObjectId(), Date: date}
+	}
+	a := AppServer{AttendanceService: &service, ScheduleService: schedules, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		body   string
+		status int
+	}{
+		{"valid", "1", `{"scheduleID": "5a9d5e4a1c9d440000a0b1c2", "date": "2018-01-08"}`, 200},
+		{"no meeting", "1", `{"scheduleID": "5a9d5e4a1c9d440000a0b1c2", "date": "2018-01-09"}`, 400},
+		{"student", "2", `{"scheduleID": "5a9d5e4a1c9d440000a0b1c2", "date": "2018-01-08"}`, 403},
+		{"malformed", "1", `{"date":`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.PostAttendanceSessionHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
+
+func TestAppServer_MarkAttendanceHandler(t *testing.T) {
+	service := mock.AttendanceService{}
+	service.MarkAttendanceFn = func(courseID string, sessionID string, userID string, records []eduboard.AttendanceRecord) (error, eduboard.AttendanceSession) {
+		for _, v := range records {
+			if !v.Status.IsValid() {
+				return errors.Wrap(eduboard.ErrInvalidInput, "invalid status"), eduboard.AttendanceSession{}
+			}
+		}
+		session := eduboard.AttendanceSession{Records: map[string]eduboard.AttendanceRecord{}}
+		for _, v := range records {
+			session.Records[v.UserID] = v
+		}
+		return nil, session
+	}
+	a := AppServer{AttendanceService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", `[{"userID": "2", "status": "excused"}]`, 200},
+		{"invalid status", `[{"userID": "2", "status": "late"}]`, 400},
+		{"malformed", `{"userID": "2"}`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.MarkAttendanceHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "sessionID", Value: "session"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"status":"excused"`, "record is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_CheckInHandler(t *testing.T) {
+	service := mock.AttendanceService{}
+	service.CheckInFn = func(courseID string, userID string, code string) (error, eduboard.AttendanceSession) {
+		if code != "ABC234" {
+			return errors.Wrap(eduboard.ErrExpired, "no open check-in"), eduboard.AttendanceSession{}
+		}
+		return nil, eduboard.AttendanceSession{Records: map[string]eduboard.AttendanceRecord{userID: {UserID: userID, Status: eduboard.AttendancePresent}}}
+	}
+	a := AppServer{AttendanceService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", `{"code": "ABC234"}`, 200},
+		{"expired", `{"code": "XYZ789"}`, 410},
+		{"malformed", `{"code":`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "2")
+			rr := httptest.NewRecorder()
+
+			a.CheckInHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
+
+func TestAppServer_GetStudentAttendanceReportHandler(t *testing.T) {
+	service := mock.AttendanceService{}
+	service.GetStudentReportFn = func(courseID string, studentID string, userID string) (error, eduboard.AttendanceReport) {
+		if studentID != userID && userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.AttendanceReport{}
+		}
+		rate := 75.0
+		return nil, eduboard.AttendanceReport{UserID: studentID, Sessions: 4, Present: 3, Absent: 1, Rate: &rate}
+	}
+	a := AppServer{AttendanceService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"staff", "1", 200},
+		{"own report", "2", 200},
+		{"other student", "3", 403},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetStudentAttendanceReportHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "userID", Value: "2"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"rate":75`, "rate is missing")
+			}
+		})
+	}
+}
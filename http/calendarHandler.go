@@ -0,0 +1,116 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// calendarName is the name of calendars that hold all courses of a user.
+const calendarName = "eduboard"
+
+func (a *AppServer) GetCourseCalendarHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		a.writeCourseCalendar(w, p.ByName("courseID"), r.Header.Get("userID"))
+	}
+}
+
+func (a *AppServer) GetMyCalendarHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		a.writeUserCalendar(w, r.Header.Get("userID"))
+	}
+}
+
+// PostCalendarTokenHandler issues a new calendar token, revoking the previous one. The token is only shown once.
+func (a *AppServer) PostCalendarTokenHandler() httprouter.Handle {
+	type response struct {
+		Token string `json:"token"`
+		URL   string `json:"url"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, token := a.UserService.CreateCalendarToken(r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error creating calendar token: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(response{token, "/api/calendar/" + token}); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) DeleteCalendarTokenHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.UserService.RevokeCalendarToken(r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error revoking calendar token: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetTokenCalendarHandler serves the calendar of all courses of the owner of a calendar token, for clients that
+// subscribe to the feed without a session.
+func (a *AppServer) GetTokenCalendarHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, user := a.UserService.FindByCalendarToken(p.ByName("token"))
+		if err != nil {
+			a.Logger.Printf("error finding calendar token: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		a.writeUserCalendar(w, user.ID.Hex())
+	}
+}
+
+func (a *AppServer) GetTokenCourseCalendarHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, user := a.UserService.FindByCalendarToken(p.ByName("token"))
+		if err != nil {
+			a.Logger.Printf("error finding calendar token: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		a.writeCourseCalendar(w, p.ByName("courseID"), user.ID.Hex())
+	}
+}
+
+func (a *AppServer) writeCourseCalendar(w http.ResponseWriter, courseID string, userID string) {
+	err, course := a.CourseService.GetCourse(courseID, userID, a.CourseEntryRepository)
+	if err != nil {
+		a.Logger.Printf("error getting course: %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	a.writeCalendar(w, course.Title, []eduboard.Course{course})
+}
+
+func (a *AppServer) writeUserCalendar(w http.ResponseWriter, userID string) {
+	err, courses := a.UserService.GetMyCourses(userID, userID, a.CourseRepository, a.CourseEntryRepository)
+	if err != nil {
+		a.Logger.Printf("error getting courses: %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	a.writeCalendar(w, calendarName, courses)
+}
+
+func (a *AppServer) writeCalendar(w http.ResponseWriter, name string, courses []eduboard.Course) {
+	err, calendar := a.ScheduleService.ExportCalendar(name, courses)
+	if err != nil {
+		a.Logger.Printf("error exporting calendar: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if _, err = w.Write(calendar); err != nil {
+		a.Logger.Printf("error writing calendar: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// newCalendarServer returns a server whose services know the user "1" with the calendar token "secret",
+// who is a member of the course "1".
+func newCalendarServer() (*AppServer, *mock.UserService, *mock.ScheduleService) {
+	us := &mock.UserService{}
+	us.FindByCalendarTokenFn = func(token string) (error, eduboard.User) {
+		if token == "secret" {
+			return nil, eduboard.User{ID: "1"}
+		}
+		return errors.New("invalid calendar token"), eduboard.User{}
+	}
+	us.GetMyCoursesFn = func(id string, viewerID string, cBMF eduboard.CourseManyFinder, cEMF eduboard.CourseEntryManyFinder) (error, []eduboard.Course) {
+		// Token owners are passed by the hex form of their ID.
+		if id != "1" && id != "31" {
+			return errors.New("not found"), []eduboard.Course{}
+		}
+		return nil, []eduboard.Course{{ID: "1", Title: "Algebra"}}
+	}
+
+	cs := &mock.CourseService{}
+	cs.CourseFn = func(id string, userID string, cef eduboard.CourseEntryManyFinder) (error, eduboard.Course) {
+		if id == "1" {
+			return nil, eduboard.Course{ID: "1", Title: "Algebra"}
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
+
+	ss := &mock.ScheduleService{}
+	ss.ExportCalendarFn = func(name string, courses []eduboard.Course) (error, []byte) {
+		return nil, []byte("BEGIN:VCALENDAR\r\nX-WR-CALNAME:" + name + "\r\nEND:VCALENDAR\r\n")
+	}
+
+	a := &AppServer{UserService: us, CourseService: cs, ScheduleService: ss, Logger: log.New(os.Stdout, "", 0)}
+	return a, us, ss
+}
+
+func TestAppServer_GetCourseCalendarHandler(t *testing.T) {
+	a, _, ss := newCalendarServer()
+
+	rr := httptest.NewRecorder()
+	a.GetCourseCalendarHandler()(rr, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "courseID", Value: "1"}})
+	assert.Equal(t, 200, rr.Code, "status code does not match")
+	assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"), "content type does not match")
+	assert.Contains(t, rr.Body.String(), "X-WR-CALNAME:Algebra", "calendar is not named after the course")
+
+	ss.ExportCalendarFnInvoked = false
+	rr = httptest.NewRecorder()
+	a.GetCourseCalendarHandler()(rr, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "courseID", Value: "2"}})
+	assert.Equal(t, 404, rr.Code, "status code does not match")
+	assert.False(t, ss.ExportCalendarFnInvoked, "ExportCalendar was invoked")
+}
+
+func TestAppServer_GetMyCalendarHandler(t *testing.T) {
+	a, _, ss := newCalendarServer()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("userID", "1")
+	rr := httptest.NewRecorder()
+	a.GetMyCalendarHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 200, rr.Code, "status code does not match")
+	assert.Contains(t, rr.Body.String(), "X-WR-CALNAME:"+calendarName, "calendar name does not match")
+
+	ss.ExportCalendarFn = func(name string, courses []eduboard.Course) (error, []byte) {
+		return errors.New("invalid schedule"), []byte{}
+	}
+	rr = httptest.NewRecorder()
+	a.GetMyCalendarHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 500, rr.Code, "status code does not match")
+}
+
+func TestAppServer_PostCalendarTokenHandler(t *testing.T) {
+	a, us, _ := newCalendarServer()
+	us.CreateCalendarTokenFn = func(userID string) (error, string) {
+		if userID != "1" {
+			return errors.New("not found"), ""
+		}
+		return nil, "secret"
+	}
+
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("userID", "1")
+	rr := httptest.NewRecorder()
+	a.PostCalendarTokenHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 201, rr.Code, "status code does not match")
+	assert.JSONEq(t, `{"token":"secret","url":"/api/calendar/secret"}`, rr.Body.String(), "response does not match")
+
+	rr = httptest.NewRecorder()
+	a.PostCalendarTokenHandler()(rr, httptest.NewRequest("POST", "/", nil), httprouter.Params{})
+	assert.Equal(t, 500, rr.Code, "status code does not match")
+}
+
+func TestAppServer_DeleteCalendarTokenHandler(t *testing.T) {
+	a, us, _ := newCalendarServer()
+	us.RevokeCalendarTokenFn = func(userID string) error {
+		if userID != "1" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r.Header.Set("userID", "1")
+	rr := httptest.NewRecorder()
+	a.DeleteCalendarTokenHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 204, rr.Code, "status code does not match")
+	assert.True(t, us.RevokeCalendarTokenFnInvoked, "RevokeCalendarToken was not invoked")
+}
+
+func TestAppServer_GetTokenCalendarHandler(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"success", "secret", 200},
+		{"invalid token", "guess", 404},
+		{"empty token", "", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			a, _, ss := newCalendarServer()
+			rr := httptest.NewRecorder()
+			a.GetTokenCalendarHandler()(rr, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "token", Value: v.token}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.status == 200, ss.ExportCalendarFnInvoked, "ExportCalendar was not invoked as expected")
+		})
+	}
+}
+
+func TestAppServer_GetTokenCourseCalendarHandler(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		token  string
+		course string
+		status int
+	}{
+		{"success", "secret", "1", 200},
+		{"invalid token", "guess", "1", 404},
+		{"unknown course", "secret", "2", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			a, _, _ := newCalendarServer()
+			rr := httptest.NewRecorder()
+			params := httprouter.Params{{Key: "token", Value: v.token}, {Key: "courseID", Value: v.course}}
+			a.GetTokenCourseCalendarHandler()(rr, httptest.NewRequest("GET", "/", nil), params)
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
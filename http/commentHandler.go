@@ -0,0 +1,110 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"gopkg.in/mgo.v2/bson"
+	"net/http"
+)
+
+// GetCommentsHandler lists the threads of an entry with their replies. The parameters cursor and limit paginate the threads.
+func (a *AppServer) GetCommentsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		limit, err := intParam(r, "limit")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, page := a.CommentService.GetComments(p.ByName("courseID"), p.ByName("entryID"), r.Header.Get("userID"),
+			r.URL.Query().Get("cursor"), limit, a.CourseRepository, a.CourseEntryRepository)
+		if err != nil {
+			a.Logger.Printf("error getting comments: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(page); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) PostCommentHandler() httprouter.Handle {
+	type request struct {
+		Message  string `json:"message"`
+		ParentID string `json:"parentID"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		comment := eduboard.Comment{Message: req.Message}
+		if req.ParentID != "" {
+			if !bson.IsObjectIdHex(req.ParentID) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			comment.ParentID = bson.ObjectIdHex(req.ParentID)
+		}
+
+		err, comment := a.CommentService.CreateComment(p.ByName("courseID"), p.ByName("entryID"), r.Header.Get("userID"),
+			comment, a.CourseRepository, a.CourseEntryRepository)
+		if err != nil {
+			a.Logger.Printf("error creating comment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(comment); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) PutCommentHandler() httprouter.Handle {
+	type request struct {
+		Message string `json:"message"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, comment := a.CommentService.UpdateComment(p.ByName("courseID"), p.ByName("entryID"), p.ByName("commentID"),
+			r.Header.Get("userID"), req.Message, a.CourseRepository)
+		if err != nil {
+			a.Logger.Printf("error updating comment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(comment); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) DeleteCommentHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err := a.CommentService.DeleteComment(p.ByName("courseID"), p.ByName("entryID"), p.ByName("commentID"),
+			r.Header.Get("userID"), a.CourseRepository)
+		if err != nil {
+			a.Logger.Printf("error deleting comment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
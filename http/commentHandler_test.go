@@ -0,0 +1,169 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAppServer_GetCommentsHandler(t *testing.T) {
+	service := mock.CommentService{}
+	service.GetCommentsFn = func(courseID string, entryID string, userID string, cursor string, limit int, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.CommentPage) {
+		switch {
+		case userID != "1":
+			return errors.Wrap(eduboard.ErrForbidden, "not a member"), eduboard.CommentPage{}
+		case entryID != "1":
+			return errors.New("not found"), eduboard.CommentPage{}
+		}
+		return nil, eduboard.CommentPage{Comments: []eduboard.Comment{{Message: "Hello"}}, Next: cursor + "next"}
+	}
+	a := AppServer{CommentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		entry   string
+		user    string
+		query   string
+		status  int
+		invoked bool
+	}{
+		{"success", "1", "1", "?cursor=a&limit=5", 200, true},
+		{"invalid limit", "1", "1", "?limit=five", 400, false},
+		{"not a member", "1", "2", "", 403, true},
+		{"unknown entry", "2", "1", "", 404, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.GetCommentsFnInvoked = false
+			r := httptest.NewRequest("GET", "/"+v.query, nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetCommentsHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}, {Key: "entryID", Value: v.entry}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.GetCommentsFnInvoked, "GetComments was not invoked as expected")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"next":"anext"`, "cursor was not passed")
+				assert.Contains(t, rr.Body.String(), `"message":"Hello"`, "comments are missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_PostCommentHandler(t *testing.T) {
+	service := mock.CommentService{}
+	service.CreateCommentFn = func(courseID string, entryID string, userID string, comment eduboard.Comment, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.Comment) {
+		if comment.Message == "" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "empty"), eduboard.Comment{}
+		}
+		comment.AuthorID = userID
+		return nil, comment
+	}
+	a := AppServer{CommentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		body    string
+		status  int
+		invoked bool
+	}{
+		{"success", `{"message":"Hello"}`, 201, true},
+		{"reply", `{"message":"Hello","parentID":"5b23bbdc2bfa844c41a9f150"}`, 201, true},
+		{"invalid parent", `{"message":"Hello","parentID":"1"}`, 400, false},
+		{"invalid body", `{"message":`, 400, false},
+		{"empty", `{"message":""}`, 400, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.CreateCommentFnInvoked = false
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.PostCommentHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}, {Key: "entryID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.CreateCommentFnInvoked, "CreateComment was not invoked as expected")
+		})
+	}
+}
+
+func TestAppServer_PutCommentHandler(t *testing.T) {
+	service := mock.CommentService{}
+	service.UpdateCommentFn = func(courseID string, entryID string, commentID string, userID string, message string, cf eduboard.CourseOneFinder) (error, eduboard.Comment) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not the author"), eduboard.Comment{}
+		}
+		return nil, eduboard.Comment{Message: message}
+	}
+	a := AppServer{CommentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		body   string
+		status int
+	}{
+		{"success", "1", `{"message":"Edited"}`, 200},
+		{"forbidden", "2", `{"message":"Edited"}`, 403},
+		{"invalid body", "1", `{`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.PutCommentHandler()(rr, r, httprouter.Params{{Key: "commentID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"message":"Edited"`, "comment was not returned")
+			}
+		})
+	}
+}
+
+func TestAppServer_DeleteCommentHandler(t *testing.T) {
+	service := mock.CommentService{}
+	service.DeleteCommentFn = func(courseID string, entryID string, commentID string, userID string, cf eduboard.CourseOneFinder) error {
+		switch {
+		case commentID != "1":
+			return errors.New("not found")
+		case userID != "1":
+			return errors.Wrap(eduboard.ErrForbidden, "not the author")
+		}
+		return nil
+	}
+	a := AppServer{CommentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		comment string
+		user    string
+		status  int
+	}{
+		{"success", "1", "1", 204},
+		{"forbidden", "1", "2", 403},
+		{"not found", "2", "1", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("DELETE", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.DeleteCommentHandler()(rr, r, httprouter.Params{{Key: "commentID", Value: v.comment}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
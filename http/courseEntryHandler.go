@@ -7,6 +7,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"gopkg.in/mgo.v2/bson"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -16,13 +17,16 @@ func (a *AppServer) PostCourseEntryHandler() httprouter.Handle {
 		Message   string    `json:"message"`
 		Pictures  []string  `json:"pictures"`
 		Published bool      `json:"published"`
+		PublishAt time.Time `json:"publishAt"`
 	}
 	type response struct {
-		ID        string    `json:"id"`
-		Date      time.Time `json:"date"`
-		Message   string    `json:"message"`
-		Pictures  []string  `json:"pictures"`
-		Published bool      `json:"published"`
+		ID        string         `json:"id"`
+		Date      time.Time      `json:"date"`
+		Message   string         `json:"message"`
+		Pictures  []string       `json:"pictures"`
+		Published bool           `json:"published"`
+		PublishAt *time.Time     `json:"publishAt,omitempty"`
+		Poll      *eduboard.Poll `json:"poll,omitempty"`
 	}
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		var (
@@ -55,11 +59,12 @@ func (a *AppServer) PostCourseEntryHandler() httprouter.Handle {
 		entryModel.Message = request.Message
 		entryModel.Pictures = pURLs
 		entryModel.Published = request.Published
+		entryModel.PublishAt = request.PublishAt
 
-		err, entry := a.CourseEntryService.StoreCourseEntry(&entryModel, a.CourseRepository)
+		err, entry := a.CourseEntryService.StoreCourseEntry(&entryModel, r.Header.Get("userID"), a.CourseRepository)
 		if err != nil {
 			a.Logger.Printf("error storing courseEntry: %v", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 			return
 		}
 
@@ -69,6 +74,8 @@ func (a *AppServer) PostCourseEntryHandler() httprouter.Handle {
 			Message:   entry.Message,
 			Pictures:  url.StringifyURLs(entry.Pictures...),
 			Published: entry.Published,
+			PublishAt: publishAt(*entry),
+			Poll:      entryPoll(*entry),
 		}
 
 		if err = json.NewEncoder(w).Encode(res); err != nil {
@@ -79,20 +86,85 @@ func (a *AppServer) PostCourseEntryHandler() httprouter.Handle {
 
 func (a *AppServer) PutCourseEntryHandler() httprouter.Handle {
 	type request struct {
-		Date      time.Time `json:"date"`
-		Message   string    `json:"message"`
-		Pictures  []string  `json:"pictures"`
-		Published bool      `json:"published"`
+		Date      *time.Time `json:"date"`
+		Message   *string    `json:"message"`
+		Pictures  []string   `json:"pictures"`
+		Published *bool      `json:"published"`
+		// PublishAt is kept raw to tell a missing field apart from null, which cancels the schedule.
+		PublishAt json.RawMessage `json:"publishAt"`
 	}
 	type response struct {
-		ID        string    `json:"id"`
-		Date      time.Time `json:"date"`
-		Message   string    `json:"message"`
-		Pictures  []string  `json:"pictures"`
-		Published bool      `json:"published"`
+		ID        string         `json:"id"`
+		Date      time.Time      `json:"date"`
+		Message   string         `json:"message"`
+		Pictures  []string       `json:"pictures"`
+		Published bool           `json:"published"`
+		PublishAt *time.Time     `json:"publishAt,omitempty"`
+		Poll      *eduboard.Poll `json:"poll,omitempty"`
 	}
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		w.WriteHeader(http.StatusNotImplemented)
+		var (
+			update  eduboard.CourseEntryUpdate
+			request request
+		)
+		courseID := p.ByName("courseID")
+		entryID := p.ByName("entryID")
+		if !bson.IsObjectIdHex(courseID) || !bson.IsObjectIdHex(entryID) {
+			a.Logger.Printf("courseID %s or entryID %s is not a valid objectID", courseID, entryID)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// A missing pictures field leaves the pictures untouched, an empty list removes all of them.
+		if request.Pictures != nil {
+			pURLs, err := url.URLifyStrings(request.Pictures...)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			update.Pictures = pURLs
+		}
+
+		update.Date = request.Date
+		update.Message = request.Message
+		update.Published = request.Published
+		if len(request.PublishAt) > 0 {
+			update.PublishAt = &time.Time{}
+			if string(request.PublishAt) != "null" {
+				if err := json.Unmarshal(request.PublishAt, update.PublishAt); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+			}
+		}
+
+		entry, err := a.CourseEntryService.UpdateCourseEntry(entryID, courseID, r.Header.Get("userID"), update, a.CourseRepository)
+		if err != nil {
+			a.Logger.Printf("error updating courseEntry: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		res := response{
+			ID:        entry.ID.Hex(),
+			Date:      entry.Date,
+			Message:   entry.Message,
+			Pictures:  url.StringifyURLs(entry.Pictures...),
+			Published: entry.Published,
+			PublishAt: publishAt(*entry),
+			Poll:      entryPoll(*entry),
+		}
+
+		if err = json.NewEncoder(w).Encode(res); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 	}
 }
 
@@ -100,12 +172,139 @@ func (a *AppServer) DeleteCourseEntryHandler() httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		courseID := p.ByName("courseID")
 		entryID := p.ByName("entryID")
-		err := a.CourseEntryService.DeleteCourseEntry(entryID, courseID, a.CourseRepository)
+		err := a.CourseEntryService.DeleteCourseEntry(entryID, courseID, r.Header.Get("userID"), a.CourseRepository, a.CommentRepository, a.PollResponseRepository)
 		if err != nil {
 			a.Logger.Printf("error deleting courseEntry: %v", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+type feedResponse struct {
+	Entries []feedEntryResponse `json:"entries"`
+	Next    string              `json:"next,omitempty"`
+}
+
+type feedEntryResponse struct {
+	ID        string         `json:"id"`
+	CourseID  string         `json:"courseID"`
+	Date      time.Time      `json:"date"`
+	Message   string         `json:"message"`
+	Pictures  []string       `json:"pictures"`
+	Published bool           `json:"published"`
+	PublishAt *time.Time     `json:"publishAt,omitempty"`
+	Poll      *eduboard.Poll `json:"poll,omitempty"`
+}
+
+func (a *AppServer) GetCourseEntriesHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		id := p.ByName("courseID")
+		if ok := bson.IsObjectIdHex(id); !ok {
+			a.Logger.Printf("courseID %s is not a valid objectID", id)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		filter, err := entryFilter(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, page := a.CourseEntryService.GetCourseEntries(id, r.Header.Get("userID"), filter, a.CourseRepository)
+		if err != nil {
+			a.Logger.Printf("error getting courseEntries: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		a.writeEntryPage(w, page)
+	}
+}
+
+func (a *AppServer) GetFeedHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		filter, err := entryFilter(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, page := a.CourseEntryService.GetFeed(r.Header.Get("userID"), filter, a.CourseRepository)
+		if err != nil {
+			a.Logger.Printf("error getting feed: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+		a.writeEntryPage(w, page)
+	}
+}
+
+func (a *AppServer) writeEntryPage(w http.ResponseWriter, page eduboard.CourseEntryPage) {
+	res := feedResponse{Entries: make([]feedEntryResponse, len(page.Entries)), Next: page.Next}
+	for k, v := range page.Entries {
+		res.Entries[k] = feedEntryResponse{
+			ID:        v.ID.Hex(),
+			CourseID:  v.CourseID.Hex(),
+			Date:      v.Date,
+			Message:   v.Message,
+			Pictures:  url.StringifyURLs(v.Pictures...),
+			Published: v.Published,
+			PublishAt: publishAt(v),
+			Poll:      entryPoll(v),
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// entryFilter reads a eduboard.CourseEntryFilter from the query parameters from, to, published, cursor and limit.
+func entryFilter(r *http.Request) (eduboard.CourseEntryFilter, error) {
+	var (
+		filter eduboard.CourseEntryFilter
+		err    error
+	)
+	values := r.URL.Query()
+
+	if v := values.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, err
+		}
+	}
+	if v := values.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, err
+		}
+	}
+	if v := values.Get("published"); v != "" {
+		published, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Published = &published
+	}
+
+	filter.Cursor = values.Get("cursor")
+	filter.Limit, err = intParam(r, "limit")
+	return filter, err
+}
+
+// publishAt returns the scheduled publication time of a draft entry, or nil if there is none.
+func publishAt(entry eduboard.CourseEntry) *time.Time {
+	if entry.Published || entry.PublishAt.IsZero() {
+		return nil
+	}
+	return &entry.PublishAt
+}
+
+// entryPoll returns the poll of an entry without its correct answers, or nil if there is none.
+func entryPoll(entry eduboard.CourseEntry) *eduboard.Poll {
+	if entry.Poll == nil {
+		return nil
+	}
+	poll := entry.Poll.WithoutAnswers()
+	return &poll
+}
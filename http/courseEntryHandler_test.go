@@ -6,11 +6,13 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
 	"log"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAppServer_PostCourseEntryHandler(t *testing.T) {
@@ -26,12 +28,16 @@ func TestAppServer_PostCourseEntryHandler(t *testing.T) {
 		{"bad objectid", `{"message": "success"}"`, "5b23bbdc2bfa844c41a9f35", false, 400},
 		{"bad urls", `{"message": "success", "pictures": ["htttp\\:.orgcom"]}"`, "5b23bbdc2bfa844c41a9f135", false, 400},
 		{"error storing", `{"message": "success"}"`, "5b23bbdc2bfa844c41a9f136", true, 500},
+		{"forbidden", `{"message": "success"}"`, "5b23bbdc2bfa844c41a9f137", true, 403},
 	}
 
 	service := mock.CourseEntryService{}
-	service.StoreCourseEntryFn = func(entry *eduboard.CourseEntry, cfu eduboard.CourseFindUpdater) (err error, courseEntry *eduboard.CourseEntry) {
-		if entry.CourseID.Hex() == "5b23bbdc2bfa844c41a9f136" {
+	service.StoreCourseEntryFn = func(entry *eduboard.CourseEntry, userID string, cfu eduboard.CourseFindUpdater) (err error, courseEntry *eduboard.CourseEntry) {
+		switch entry.CourseID.Hex() {
+		case "5b23bbdc2bfa844c41a9f136":
 			return errors.New("could not store"), &eduboard.CourseEntry{}
+		case "5b23bbdc2bfa844c41a9f137":
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), &eduboard.CourseEntry{}
 		}
 		return nil, entry
 	}
@@ -50,3 +56,220 @@ func TestAppServer_PostCourseEntryHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestAppServer_PutCourseEntryHandler(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		input        string
+		courseID     string
+		entryID      string
+		invokeUpdate bool
+		status       int
+	}{
+		{"success", `{"message": "success"}`, "5b23bbdc2bfa844c41a9f135", "5b23bbdc2bfa844c41a9f140", true, 200},
+		{"success pictures", `{"pictures": ["https://example.com/picture.png"]}`, "5b23bbdc2bfa844c41a9f135", "5b23bbdc2bfa844c41a9f140", true, 200},
+		{"bad json", `{"message":`, "5b23bbdc2bfa844c41a9f135", "5b23bbdc2bfa844c41a9f140", false, 400},
+		{"bad course objectid", `{"message": "success"}`, "5b23bbdc2bfa844c41a9f35", "5b23bbdc2bfa844c41a9f140", false, 400},
+		{"bad entry objectid", `{"message": "success"}`, "5b23bbdc2bfa844c41a9f135", "5b23bbdc2bfa844c41a9f40", false, 400},
+		{"bad urls", `{"pictures": ["htttp\\:.orgcom"]}`, "5b23bbdc2bfa844c41a9f135", "5b23bbdc2bfa844c41a9f140", false, 400},
+		{"error updating", `{"message": "success"}`, "5b23bbdc2bfa844c41a9f136", "5b23bbdc2bfa844c41a9f140", true, 500},
+		{"forbidden", `{"message": "success"}`, "5b23bbdc2bfa844c41a9f137", "5b23bbdc2bfa844c41a9f140", true, 403},
+		{"not found", `{"message": "success"}`, "5b23bbdc2bfa844c41a9f139", "5b23bbdc2bfa844c41a9f140", true, 404},
+		{"schedule", `{"publishAt": "2030-07-01T15:04:05Z"}`, "5b23bbdc2bfa844c41a9f135", "5b23bbdc2bfa844c41a9f140", true, 200},
+		{"cancel schedule", `{"publishAt": null}`, "5b23bbdc2bfa844c41a9f138", "5b23bbdc2bfa844c41a9f140", true, 200},
+		{"bad publishAt", `{"publishAt": "tomorrow"}`, "5b23bbdc2bfa844c41a9f135", "5b23bbdc2bfa844c41a9f140", false, 400},
+	}
+
+	service := mock.CourseEntryService{}
+	service.UpdateCourseEntryFn = func(entryID string, courseID string, userID string, update eduboard.CourseEntryUpdate, cf eduboard.CourseOneFinder) (*eduboard.CourseEntry, error) {
+		switch courseID {
+		case "5b23bbdc2bfa844c41a9f136":
+			return &eduboard.CourseEntry{}, errors.New("could not update")
+		case "5b23bbdc2bfa844c41a9f137":
+			return &eduboard.CourseEntry{}, errors.Wrap(eduboard.ErrForbidden, "not staff")
+		case "5b23bbdc2bfa844c41a9f139":
+			return &eduboard.CourseEntry{}, errors.Wrap(eduboard.ErrNotFound, "no such entry")
+		case "5b23bbdc2bfa844c41a9f138":
+			if update.PublishAt == nil || !update.PublishAt.IsZero() {
+				return &eduboard.CourseEntry{}, errors.New("schedule was not cancelled")
+			}
+		}
+		entry := eduboard.CourseEntry{ID: bson.ObjectIdHex(entryID), CourseID: bson.ObjectIdHex(courseID)}
+		if update.PublishAt != nil {
+			entry.PublishAt = *update.PublishAt
+		}
+		if update.Message != nil {
+			entry.Message = *update.Message
+		}
+		entry.Pictures = update.Pictures
+		return &entry, nil
+	}
+	a := AppServer{CourseEntryService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.UpdateCourseEntryFnInvoked = false
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.input))
+			rr := httptest.NewRecorder()
+			p := httprouter.Params{
+				httprouter.Param{Key: "courseID", Value: v.courseID},
+				httprouter.Param{Key: "entryID", Value: v.entryID},
+			}
+
+			a.PutCourseEntryHandler()(rr, r, p)
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invokeUpdate, service.UpdateCourseEntryFnInvoked, "Update was not invoked as expected")
+			if v.name == "schedule" {
+				assert.Contains(t, rr.Body.String(), `"publishAt":"2030-07-01T15:04:05Z"`, "response does not contain publishAt")
+			}
+		})
+	}
+}
+
+func TestAppServer_DeleteCourseEntryHandler(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		courseID string
+		status   int
+	}{
+		{"success", "1", 204},
+		{"error deleting", "2", 500},
+		{"forbidden", "3", 403},
+		{"not found", "4", 404},
+	}
+
+	service := mock.CourseEntryService{}
+	service.DeleteCourseEntryFn = func(entryID string, courseID string, userID string, cfu eduboard.CourseFindUpdater, cd eduboard.CommentDeleter, pd eduboard.PollResponseDeleter) error {
+		switch courseID {
+		case "1":
+			return nil
+		case "3":
+			return errors.Wrap(eduboard.ErrForbidden, "not staff")
+		case "4":
+			return errors.Wrap(eduboard.ErrNotFound, "no such entry")
+		default:
+			return errors.New("could not delete")
+		}
+	}
+	a := AppServer{CourseEntryService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.DeleteCourseEntryFnInvoked = false
+			r := httptest.NewRequest("DELETE", "/", nil)
+			rr := httptest.NewRecorder()
+			p := httprouter.Params{
+				httprouter.Param{Key: "courseID", Value: v.courseID},
+				httprouter.Param{Key: "entryID", Value: "1"},
+			}
+
+			a.DeleteCourseEntryHandler()(rr, r, p)
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.True(t, service.DeleteCourseEntryFnInvoked, "Delete was not invoked")
+		})
+	}
+}
+
+func TestAppServer_GetCourseEntriesHandler(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		courseID string
+		query    string
+		invoked  bool
+		status   int
+	}{
+		{"success", "5b23bbdc2bfa844c41a9f135", "", true, 200},
+		{"filtered", "5b23bbdc2bfa844c41a9f135", "?from=2018-06-01T00:00:00Z&to=2018-07-01T00:00:00Z&published=true&limit=5&cursor=abc", true, 200},
+		{"bad objectid", "5b23bbdc2bfa844c41a9f35", "", false, 400},
+		{"bad date", "5b23bbdc2bfa844c41a9f135", "?from=yesterday", false, 400},
+		{"bad published", "5b23bbdc2bfa844c41a9f135", "?published=maybe", false, 400},
+		{"bad limit", "5b23bbdc2bfa844c41a9f135", "?limit=many", false, 400},
+		{"forbidden", "5b23bbdc2bfa844c41a9f137", "", true, 403},
+		{"not found", "5b23bbdc2bfa844c41a9f136", "", true, 404},
+	}
+
+	var filter eduboard.CourseEntryFilter
+	service := mock.CourseEntryService{}
+	service.GetCourseEntriesFn = func(courseID string, userID string, f eduboard.CourseEntryFilter, cf eduboard.CourseOneFinder) (error, eduboard.CourseEntryPage) {
+		filter = f
+		switch courseID {
+		case "5b23bbdc2bfa844c41a9f136":
+			return errors.New("not found"), eduboard.CourseEntryPage{}
+		case "5b23bbdc2bfa844c41a9f137":
+			return errors.Wrap(eduboard.ErrForbidden, "no member"), eduboard.CourseEntryPage{}
+		}
+		entry := eduboard.CourseEntry{ID: bson.ObjectIdHex("5b23bbdc2bfa844c41a9f140"), CourseID: bson.ObjectIdHex(courseID), Published: true}
+		return nil, eduboard.CourseEntryPage{Entries: []eduboard.CourseEntry{entry}, Next: "next"}
+	}
+	a := AppServer{CourseEntryService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.GetCourseEntriesFnInvoked = false
+			r := httptest.NewRequest("GET", "/"+v.query, nil)
+			rr := httptest.NewRecorder()
+
+			a.GetCourseEntriesHandler()(rr, r, httprouter.Params{httprouter.Param{Key: "courseID", Value: v.courseID}})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			assert.Equal(t, v.invoked, service.GetCourseEntriesFnInvoked, "GetCourseEntries was not invoked as expected")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"next":"next"`, "next cursor missing")
+				assert.Contains(t, rr.Body.String(), `"courseID":"`+v.courseID+`"`, "course missing")
+			}
+			if v.name == "filtered" {
+				published := true
+				expected := eduboard.CourseEntryFilter{
+					From:      time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC),
+					To:        time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC),
+					Published: &published,
+					Cursor:    "abc",
+					Limit:     5,
+				}
+				assert.Equal(t, expected, filter, "filter does not match")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetFeedHandler(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		userID  string
+		query   string
+		invoked bool
+		status  int
+	}{
+		{"success", "1", "", true, 200},
+		{"bad date", "1", "?to=tomorrow", false, 400},
+		{"invalid cursor", "1", "?cursor=invalid", true, 400},
+		{"error", "2", "", true, 500},
+	}
+
+	service := mock.CourseEntryService{}
+	service.GetFeedFn = func(userID string, f eduboard.CourseEntryFilter, cmf eduboard.CourseManyFinder) (error, eduboard.CourseEntryPage) {
+		if f.Cursor == "invalid" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "invalid cursor"), eduboard.CourseEntryPage{}
+		}
+		if userID != "1" {
+			return errors.New("error"), eduboard.CourseEntryPage{}
+		}
+		return nil, eduboard.CourseEntryPage{Entries: []eduboard.CourseEntry{}}
+	}
+	a := AppServer{CourseEntryService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.GetFeedFnInvoked = false
+			r := httptest.NewRequest("GET", "/"+v.query, nil)
+			r.Header.Set("userID", v.userID)
+			rr := httptest.NewRecorder()
+
+			a.GetFeedHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			assert.Equal(t, v.invoked, service.GetFeedFnInvoked, "GetFeed was not invoked as expected")
+			if v.status == 200 {
+				assert.Equal(t, "{\"entries\":[]}\n", rr.Body.String(), "body does not match")
+			}
+		})
+	}
+}
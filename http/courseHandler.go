@@ -6,27 +6,69 @@ import (
 	"github.com/eduboard/backend/url"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 func (a *AppServer) GetAllCoursesHandler() httprouter.Handle {
+	type courseResponse struct {
+		ID          string                    `json:"id"`
+		Title       string                    `json:"title"`
+		Description string                    `json:"description"`
+		Labels      []string                  `json:"labels,omitempty"`
+		Enrollment  eduboard.EnrollmentPolicy `json:"enrollment"`
+	}
 	type response struct {
-		ID          string `json:"id"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
+		Courses []courseResponse `json:"courses"`
+		Total   int              `json:"total"`
+		Offset  int              `json:"offset"`
+		Limit   int              `json:"limit"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		err, courses := a.CourseService.GetAllCourses()
+		values := r.URL.Query()
+		query := eduboard.CourseQuery{
+			Text:   values.Get("q"),
+			Labels: values["label"],
+			Member: values.Get("member"),
+			Sort:   values.Get("sort"),
+		}
+		if query.Member == "me" {
+			query.Member = r.Header.Get("userID")
+		}
+
+		var err error
+		if archived := values.Get("archived"); archived != "" {
+			if query.Archived, err = strconv.ParseBool(archived); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		if query.Offset, err = intParam(r, "offset"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if query.Limit, err = intParam(r, "limit"); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, page := a.CourseService.SearchCourses(query)
 		if err != nil {
 			a.Logger.Printf("error getting courses: %v", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 			return
 		}
 
-		var res = make([]response, len(courses))
-		for k, v := range courses {
-			res[k] = response{ID: v.ID.Hex(), Title: v.Title, Description: v.Description}
+		res := response{
+			Courses: make([]courseResponse, len(page.Courses)),
+			Total:   page.Total,
+			Offset:  page.Offset,
+			Limit:   page.Limit,
+		}
+		for k, v := range page.Courses {
+			res.Courses[k] = courseResponse{ID: v.ID.Hex(), Title: v.Title, Description: v.Description, Labels: v.Labels,
+				Enrollment: v.EnrollmentPolicy()}
 		}
 
 		if err = json.NewEncoder(w).Encode(&res); err != nil {
@@ -37,11 +79,13 @@ func (a *AppServer) GetAllCoursesHandler() httprouter.Handle {
 
 func (a *AppServer) GetCourseHandler() httprouter.Handle {
 	type entryResponse struct {
-		ID        string    `json:"id"`
-		Date      time.Time `json:"date"`
-		Message   string    `json:"message"`
-		Pictures  []string  `json:"pictures"`
-		Published bool      `json:"published"`
+		ID        string         `json:"id"`
+		Date      time.Time      `json:"date"`
+		Message   string         `json:"message"`
+		Pictures  []string       `json:"pictures"`
+		Published bool           `json:"published"`
+		PublishAt *time.Time     `json:"publishAt,omitempty"`
+		Poll      *eduboard.Poll `json:"poll,omitempty"`
 	}
 
 	type scheduleResponse struct {
@@ -52,19 +96,26 @@ func (a *AppServer) GetCourseHandler() httprouter.Handle {
 		Title    string        `json:"title,omitempty"`
 	}
 
+	type memberResponse struct {
+		ID   string        `json:"id"`
+		Role eduboard.Role `json:"role"`
+	}
+
 	type courseResponse struct {
-		ID          string             `json:"id"`
-		Title       string             `json:"title"`
-		Description string             `json:"description"`
-		Members     []string           `json:"members,omitempty"`
-		Labels      []string           `json:"labels,omitempty"`
-		Entries     []entryResponse    `json:"entries,omitempty"`
-		Schedules   []scheduleResponse `json:"schedules,omitempty"`
+		ID          string                    `json:"id"`
+		Title       string                    `json:"title"`
+		Description string                    `json:"description"`
+		Members     []memberResponse          `json:"members,omitempty"`
+		Labels      []string                  `json:"labels,omitempty"`
+		Entries     []entryResponse           `json:"entries,omitempty"`
+		Schedules   []scheduleResponse        `json:"schedules,omitempty"`
+		Archived    bool                      `json:"archived"`
+		Enrollment  eduboard.EnrollmentPolicy `json:"enrollment"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		id := p.ByName("courseID")
-		err, course := a.CourseService.GetCourse(id, a.CourseEntryRepository)
+		err, course := a.CourseService.GetCourse(id, r.Header.Get("userID"), a.CourseEntryRepository)
 		if err != nil {
 			a.Logger.Printf("error getting course: %v", err)
 			w.WriteHeader(http.StatusNotFound)
@@ -75,10 +126,16 @@ func (a *AppServer) GetCourseHandler() httprouter.Handle {
 			ID:          course.ID.Hex(),
 			Title:       course.Title,
 			Description: course.Description,
-			Members:     course.Members,
+			Members:     make([]memberResponse, len(course.Members)),
 			Labels:      course.Labels,
 			Entries:     make([]entryResponse, len(course.Entries)),
 			Schedules:   make([]scheduleResponse, len(course.Schedules)),
+			Archived:    course.Archived,
+			Enrollment:  course.EnrollmentPolicy(),
+		}
+
+		for k, v := range course.Members {
+			res.Members[k] = memberResponse{ID: v.UserID, Role: v.Role}
 		}
 
 		for k, v := range course.Entries {
@@ -88,6 +145,8 @@ func (a *AppServer) GetCourseHandler() httprouter.Handle {
 				Message:   v.Message,
 				Pictures:  url.StringifyURLs(v.Pictures...),
 				Published: v.Published,
+				PublishAt: publishAt(v),
+				Poll:      entryPoll(v),
 			}
 		}
 
@@ -142,7 +201,8 @@ func (a *AppServer) GetMembersHandler() httprouter.Handle {
 
 func (a *AppServer) AddMembersHandler() httprouter.Handle {
 	type request struct {
-		ID string `json:"id"`
+		ID   string        `json:"id"`
+		Role eduboard.Role `json:"role"`
 	}
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		request := []request{}
@@ -153,15 +213,15 @@ func (a *AppServer) AddMembersHandler() httprouter.Handle {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		members := []string{}
+		members := []eduboard.Member{}
 		for _, req := range request {
-			members = append(members, req.ID)
+			members = append(members, eduboard.Member{UserID: req.ID, Role: req.Role})
 		}
-		err, _ = a.CourseService.AddMembers(id, members)
+		err, _ = a.CourseService.AddMembers(id, r.Header.Get("userID"), members)
 
 		if err != nil {
 			a.Logger.Printf("Error while subscribing user to course %v", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 			return
 		}
 
@@ -185,10 +245,10 @@ func (a *AppServer) RemoveMembersHandler() httprouter.Handle {
 		for _, req := range request {
 			members = append(members, req.ID)
 		}
-		err, _ = a.CourseService.RemoveMembers(id, members)
+		err, _ = a.CourseService.RemoveMembers(id, r.Header.Get("userID"), members)
 		if err != nil {
 			a.Logger.Printf("Error while unsubscribing user from course %v", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 			return
 		}
 	}
@@ -196,18 +256,24 @@ func (a *AppServer) RemoveMembersHandler() httprouter.Handle {
 
 func (a *AppServer) CreateCourseHandler() httprouter.Handle {
 	type request struct {
-		Title       string   `json:"title,omitempty"`
-		Description string   `json:"description,omitempty"`
-		Members     []string `json:"members,omitempty"`
-		Labels      []string `json:"labels"`
+		Title       string                    `json:"title,omitempty"`
+		Description string                    `json:"description,omitempty"`
+		Members     []string                  `json:"members,omitempty"`
+		Labels      []string                  `json:"labels"`
+		Enrollment  eduboard.EnrollmentPolicy `json:"enrollment"`
+	}
+	type memberResponse struct {
+		ID   string        `json:"id"`
+		Role eduboard.Role `json:"role"`
 	}
 	type response struct {
-		ID          string    `json:"id,omitempty"`
-		Title       string    `json:"title,omitempty"`
-		Description string    `json:"description,omitempty"`
-		Members     []string  `json:"members,omitempty"`
-		Labels      []string  `json:"labels"`
-		CreatedAt   time.Time `json:"createdAt"`
+		ID          string                    `json:"id,omitempty"`
+		Title       string                    `json:"title,omitempty"`
+		Description string                    `json:"description,omitempty"`
+		Members     []memberResponse          `json:"members,omitempty"`
+		Labels      []string                  `json:"labels"`
+		CreatedAt   time.Time                 `json:"createdAt"`
+		Enrollment  eduboard.EnrollmentPolicy `json:"enrollment"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
@@ -222,13 +288,16 @@ func (a *AppServer) CreateCourseHandler() httprouter.Handle {
 
 		course.Title = request.Title
 		course.Description = request.Description
-		course.Members = request.Members
 		course.Labels = request.Labels
+		course.Enrollment = request.Enrollment
+		for _, m := range request.Members {
+			course.Members = append(course.Members, eduboard.Member{UserID: m})
+		}
 
-		newCourse, err := a.CourseService.CreateCourse(&course)
+		newCourse, err := a.CourseService.CreateCourse(&course, r.Header.Get("userID"))
 		if err != nil {
 			a.Logger.Printf("error creating course: %v", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
 			return
 		}
 
@@ -236,12 +305,116 @@ func (a *AppServer) CreateCourseHandler() httprouter.Handle {
 			ID:          newCourse.ID.Hex(),
 			Title:       newCourse.Title,
 			Description: newCourse.Description,
-			Members:     newCourse.Members,
+			Members:     make([]memberResponse, len(newCourse.Members)),
 			Labels:      newCourse.Labels,
 			CreatedAt:   newCourse.CreatedAt,
+			Enrollment:  newCourse.EnrollmentPolicy(),
+		}
+		for k, v := range newCourse.Members {
+			response.Members[k] = memberResponse{ID: v.UserID, Role: v.Role}
 		}
 		if err = json.NewEncoder(w).Encode(response); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 		}
 	}
 }
+
+func (a *AppServer) UpdateCourseHandler() httprouter.Handle {
+	type request struct {
+		Title       *string                    `json:"title"`
+		Description *string                    `json:"description"`
+		Labels      []string                   `json:"labels"`
+		Schedules   []eduboard.Schedule        `json:"schedules"`
+		Enrollment  *eduboard.EnrollmentPolicy `json:"enrollment"`
+	}
+	type response struct {
+		ID          string                    `json:"id"`
+		Title       string                    `json:"title"`
+		Description string                    `json:"description"`
+		Labels      []string                  `json:"labels"`
+		Schedules   []eduboard.Schedule       `json:"schedules"`
+		Archived    bool                      `json:"archived"`
+		Enrollment  eduboard.EnrollmentPolicy `json:"enrollment"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var request request
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		update := eduboard.CourseUpdate{
+			Title:       request.Title,
+			Description: request.Description,
+			Labels:      request.Labels,
+			Schedules:   request.Schedules,
+			Enrollment:  request.Enrollment,
+		}
+		err, course := a.CourseService.UpdateCourse(p.ByName("courseID"), r.Header.Get("userID"), update, a.ScheduleService)
+		if err != nil {
+			a.Logger.Printf("error updating course: %v", err)
+			writeError(w, err, http.StatusNotFound)
+			return
+		}
+
+		res := response{
+			ID:          course.ID.Hex(),
+			Title:       course.Title,
+			Description: course.Description,
+			Labels:      course.Labels,
+			Schedules:   course.Schedules,
+			Archived:    course.Archived,
+			Enrollment:  course.EnrollmentPolicy(),
+		}
+		if err = json.NewEncoder(w).Encode(res); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) ArchiveCourseHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, _ := a.CourseService.ArchiveCourse(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error archiving course: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *AppServer) RestoreCourseHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, _ := a.CourseService.RestoreCourse(p.ByName("courseID"), r.Header.Get("userID"), a.ScheduleService)
+		if err != nil {
+			a.Logger.Printf("error restoring course: %v", err)
+			writeError(w, err, http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *AppServer) DeleteCourseHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err := a.CourseService.DeleteCourse(p.ByName("courseID"), r.Header.Get("userID"), a.CourseEntryRepository, a.CommentRepository, a.UserRepository)
+		if err != nil {
+			a.Logger.Printf("error deleting course: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// intParam parses the query parameter key as integer. Missing parameters are 0.
+func intParam(r *http.Request, key string) (int, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
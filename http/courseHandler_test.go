@@ -35,33 +35,52 @@ func TestAppServer_GetAllCoursesHandler(t *testing.T) {
 		Logger:           log.New(os.Stdout, "", 0),
 	}
 
+	var query eduboard.CourseQuery
+	mockService.SearchCoursesFn = func(q eduboard.CourseQuery) (error, eduboard.CoursePage) {
+		query = q
+		switch q.Text {
+		case "error":
+			return errors.New("error"), eduboard.CoursePage{}
+		case "invalid":
+			return errors.Wrap(eduboard.ErrInvalidInput, "bad sort"), eduboard.CoursePage{}
+		}
+		return nil, eduboard.CoursePage{Courses: coursesList, Total: 42, Offset: q.Offset, Limit: 2}
+	}
+
 	var testCases = []struct {
-		name   string
-		error  bool
-		status int
+		name     string
+		url      string
+		invoked  bool
+		status   int
+		expected eduboard.CourseQuery
 	}{
-		{"success", false, 200},
-		{"error", true, 500},
+		{"all", "/", true, 200, eduboard.CourseQuery{}},
+		{"filtered", "/?q=math&label=a&label=b&member=me&sort=-createdAt&offset=2&limit=2", true, 200,
+			eduboard.CourseQuery{Text: "math", Labels: []string{"a", "b"}, Member: "1", Sort: "-createdAt", Offset: 2, Limit: 2}},
+		{"other member", "/?member=2", true, 200, eduboard.CourseQuery{Member: "2"}},
+		{"archived", "/?archived=true", true, 200, eduboard.CourseQuery{Archived: true}},
+		{"bad archived", "/?archived=maybe", false, 400, eduboard.CourseQuery{}},
+		{"bad offset", "/?offset=two", false, 400, eduboard.CourseQuery{}},
+		{"bad limit", "/?limit=-", false, 400, eduboard.CourseQuery{}},
+		{"invalid query", "/?q=invalid", true, 400, eduboard.CourseQuery{}},
+		{"error", "/?q=error", true, 500, eduboard.CourseQuery{}},
 	}
 
 	for _, v := range testCases {
-
-		mockService.CoursesFn = func() (err error, courses []eduboard.Course) {
-			if v.error {
-				return errors.New("error"), []eduboard.Course{}
-			}
-			return nil, coursesList
-		}
-
 		t.Run(v.name, func(t *testing.T) {
-			mockService.CoursesFnInvoked = false
-			r := httptest.NewRequest("GET", "/", nil)
+			mockService.SearchCoursesFnInvoked = false
+			r := httptest.NewRequest("GET", v.url, nil)
+			r.Header.Set("userID", "1")
 			rr := httptest.NewRecorder()
 
 			appServer.GetAllCoursesHandler()(rr, r, httprouter.Params{})
-			assert.True(t, mockService.CoursesFnInvoked, "GetAllCourses was not invoked")
+			assert.Equal(t, v.invoked, mockService.SearchCoursesFnInvoked, "SearchCourses was not invoked as expected")
 			assert.Equal(t, v.status, rr.Code, "bad response code")
-			assert.True(t, v.error || len(rr.Body.String()) > 2, "bad body length")
+			if v.status == 200 {
+				assert.Equal(t, v.expected, query, "query does not match")
+				assert.Contains(t, rr.Body.String(), `"total":42`, "total missing")
+				assert.Contains(t, rr.Body.String(), `"labels":["label 1"]`, "labels missing")
+			}
 		})
 	}
 }
@@ -81,15 +100,15 @@ func TestAppServer_GetCourseHandler(t *testing.T) {
 		bodylength int
 		status     int
 	}{
-		{"no entries", "1", 40, 200},
-		{"success", "2", 300, 200},
+		{"no entries", "1", 84, 200},
+		{"success", "2", 344, 200},
 		{"bad id", "3", 0, 404},
 	}
 
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
 			mockService.CourseFnInvoked = false
-			mockService.CourseFn = func(id string, cef eduboard.CourseEntryManyFinder) (err error, course eduboard.Course) {
+			mockService.CourseFn = func(id string, userID string, cef eduboard.CourseEntryManyFinder) (err error, course eduboard.Course) {
 				switch id {
 				case "1":
 					return nil, eduboard.Course{ID: "1"}
@@ -171,15 +190,18 @@ func TestAppServer_AddMembersHandler(t *testing.T) {
 		{"success", "1", `[{"id": "1"},{"id": "2"}]`, 200},
 		{"invalid body", "2", `lala <> ""`, 400},
 		{"error subscribing user", "3", `[{"id": "1"},{"id": "2"}]`, 500},
+		{"forbidden", "4", `[{"id": "1"},{"id": "2", "role": "teacher"}]`, 403},
 	}
 
-	mockService.AddMembersFn = func(id string, members []string) (error, eduboard.Course) {
+	mockService.AddMembersFn = func(id string, userID string, members []eduboard.Member) (error, eduboard.Course) {
 		switch id {
 		case "1":
 			if len(members) != 2 {
 				return errors.New("Members are not correct"), eduboard.Course{}
 			}
 			return nil, eduboard.Course{ID: "1"}
+		case "4":
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Course{}
 		default:
 			return errors.New("Error fetching members"), eduboard.Course{}
 		}
@@ -214,12 +236,15 @@ func TestAppServer_RemoveMembersHandler(t *testing.T) {
 		{"success", "1", `[{"id": "1"},{"id": "2"}]`, 200},
 		{"invalid body", "2", `lala <> ""`, 400},
 		{"error subscribing user", "3", `[{"id": "1"},{"id": "2"}]`, 500},
+		{"forbidden", "4", `[{"id": "1"},{"id": "2"}]`, 403},
 	}
 
-	mockService.RemoveMembersFn = func(id string, members []string) (error, eduboard.Course) {
+	mockService.RemoveMembersFn = func(id string, userID string, members []string) (error, eduboard.Course) {
 		switch id {
 		case "1":
 			return nil, eduboard.Course{ID: "1"}
+		case "4":
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Course{}
 		default:
 			return errors.New("Error fetching members"), eduboard.Course{}
 		}
@@ -264,7 +289,7 @@ func TestAppServer_CreateCourseHandler(t *testing.T) {
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
 			mockService.CreateCourseFnInvoked = false
-			mockService.CreateCourseFn = func(c *eduboard.Course) (*eduboard.Course, error) {
+			mockService.CreateCourseFn = func(c *eduboard.Course, ownerID string) (*eduboard.Course, error) {
 				newCourse := eduboard.Course{}
 				newCourse.ID = bson.NewObjectId()
 				newCourse.Title = c.Title
@@ -287,3 +312,158 @@ func TestAppServer_CreateCourseHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestAppServer_UpdateCourseHandler(t *testing.T) {
+	mockService := mock.CourseService{}
+	appServer := AppServer{CourseService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{"success", "5b23bbdc2bfa844c41a9f134", `{"title": "New title", "schedules": [{"day": 1, "room": "EN 154"}]}`, 200},
+		{"invalid body", "5b23bbdc2bfa844c41a9f134", `{"title":`, 400},
+		{"not found", "5b23bbdc2bfa844c41a9f135", `{"title": "New title"}`, 404},
+		{"forbidden", "5b23bbdc2bfa844c41a9f136", `{"title": "New title"}`, 403},
+		{"archived", "5b23bbdc2bfa844c41a9f137", `{"title": "New title"}`, 409},
+		{"enrollment", "5b23bbdc2bfa844c41a9f134", `{"title": "New title", "enrollment": "open"}`, 200},
+	}
+
+	mockService.UpdateCourseFn = func(id string, userID string, update eduboard.CourseUpdate, sc eduboard.ScheduleChecker) (error, eduboard.Course) {
+		switch id {
+		case "5b23bbdc2bfa844c41a9f135":
+			return errors.New("not found"), eduboard.Course{}
+		case "5b23bbdc2bfa844c41a9f136":
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Course{}
+		case "5b23bbdc2bfa844c41a9f137":
+			return errors.Wrap(eduboard.ErrArchived, "archived"), eduboard.Course{}
+		}
+		course := eduboard.Course{ID: bson.ObjectIdHex(id), Title: *update.Title, Schedules: update.Schedules}
+		if update.Enrollment != nil {
+			course.Enrollment = *update.Enrollment
+		}
+		return nil, course
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.UpdateCourseFnInvoked = false
+
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			rr := httptest.NewRecorder()
+			appServer.UpdateCourseHandler()(rr, r, httprouter.Params{httprouter.Param{Key: "courseID", Value: v.id}})
+
+			assert.Equal(t, v.status != 400, mockService.UpdateCourseFnInvoked, "UpdateCourse was not invoked as expected")
+			assert.Equal(t, v.status, rr.Code, "unexpected status code")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"title":"New title"`, "title missing")
+			}
+			switch v.name {
+			case "success":
+				assert.Contains(t, rr.Body.String(), `"room":"EN 154"`, "schedules missing")
+				assert.Contains(t, rr.Body.String(), `"enrollment":"invite-only"`, "default enrollment missing")
+			case "enrollment":
+				assert.Contains(t, rr.Body.String(), `"enrollment":"open"`, "enrollment missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_ArchiveCourseHandler(t *testing.T) {
+	mockService := mock.CourseService{}
+	appServer := AppServer{CourseService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		id      string
+		restore bool
+		status  int
+	}{
+		{"archive", "1", false, 204},
+		{"restore", "1", true, 204},
+		{"archive forbidden", "2", false, 403},
+		{"restore not found", "3", true, 404},
+		{"restore room conflict", "4", true, 409},
+	}
+
+	setArchived := func(id string, userID string) (error, eduboard.Course) {
+		switch id {
+		case "2":
+			return errors.Wrap(eduboard.ErrForbidden, "not owner"), eduboard.Course{}
+		case "3":
+			return errors.New("not found"), eduboard.Course{}
+		case "4":
+			return &eduboard.RoomConflictError{Conflicts: []eduboard.RoomConflict{{Room: "EN 154", CourseID: "1"}}}, eduboard.Course{}
+		}
+		return nil, eduboard.Course{}
+	}
+	mockService.ArchiveCourseFn = setArchived
+	mockService.RestoreCourseFn = func(id string, userID string, sc eduboard.ScheduleChecker) (error, eduboard.Course) {
+		return setArchived(id, userID)
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.ArchiveCourseFnInvoked = false
+			mockService.RestoreCourseFnInvoked = false
+
+			r := httptest.NewRequest("POST", "/", nil)
+			rr := httptest.NewRecorder()
+			p := httprouter.Params{httprouter.Param{Key: "courseID", Value: v.id}}
+			if v.restore {
+				appServer.RestoreCourseHandler()(rr, r, p)
+			} else {
+				appServer.ArchiveCourseHandler()(rr, r, p)
+			}
+
+			assert.Equal(t, !v.restore, mockService.ArchiveCourseFnInvoked, "ArchiveCourse was not invoked as expected")
+			assert.Equal(t, v.restore, mockService.RestoreCourseFnInvoked, "RestoreCourse was not invoked as expected")
+			assert.Equal(t, v.status, rr.Code, "unexpected status code")
+		})
+	}
+}
+
+func TestAppServer_DeleteCourseHandler(t *testing.T) {
+	mockService := mock.CourseService{}
+	appServer := AppServer{
+		CourseService:         &mockService,
+		CourseEntryRepository: &mock.CourseEntryRepository{},
+		UserRepository:        &mock.UserRepository{},
+		Logger:                log.New(os.Stdout, "", 0),
+	}
+
+	var testCases = []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"success", "1", 204},
+		{"forbidden", "2", 403},
+		{"error", "3", 500},
+	}
+
+	mockService.DeleteCourseFn = func(id string, userID string, ced eduboard.CourseEntryDeleter, cd eduboard.CommentDeleter, ucr eduboard.UserCourseRemover) error {
+		switch id {
+		case "2":
+			return errors.Wrap(eduboard.ErrForbidden, "not owner")
+		case "3":
+			return errors.New("error")
+		}
+		return nil
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.DeleteCourseFnInvoked = false
+
+			r := httptest.NewRequest("DELETE", "/", nil)
+			rr := httptest.NewRecorder()
+			appServer.DeleteCourseHandler()(rr, r, httprouter.Params{httprouter.Param{Key: "courseID", Value: v.id}})
+
+			assert.True(t, mockService.DeleteCourseFnInvoked, "DeleteCourse was not invoked")
+			assert.Equal(t, v.status, rr.Code, "unexpected status code")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// PostEnrollmentHandler asks to join a course. Open courses are joined right away with 200 OK,
+// courses requiring approval answer 201 Created with the pending request.
+func (a *AppServer) PostEnrollmentHandler() httprouter.Handle {
+	type request struct {
+		Message string `json:"message"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, enrollment := a.EnrollmentService.RequestEnrollment(p.ByName("courseID"), r.Header.Get("userID"), req.Message, a.CourseService)
+		if err != nil {
+			a.Logger.Printf("error requesting enrollment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if enrollment.Status == eduboard.EnrollmentPending {
+			w.WriteHeader(http.StatusCreated)
+		}
+		if err = json.NewEncoder(w).Encode(enrollment); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+// GetEnrollmentHandler returns the enrollment request of the current user.
+func (a *AppServer) GetEnrollmentHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, enrollment := a.EnrollmentService.GetEnrollment(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting enrollment: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(enrollment); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) DeleteEnrollmentHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.EnrollmentService.CancelEnrollment(p.ByName("courseID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error cancelling enrollment: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *AppServer) GetEnrollmentRequestsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, requests := a.EnrollmentService.GetPendingEnrollments(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting enrollment requests: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(requests); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) ApproveEnrollmentHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, enrollment := a.EnrollmentService.ApproveEnrollment(p.ByName("courseID"), p.ByName("requestID"), r.Header.Get("userID"), a.CourseService)
+		if err != nil {
+			a.Logger.Printf("error approving enrollment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(enrollment); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) RejectEnrollmentHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, enrollment := a.EnrollmentService.RejectEnrollment(p.ByName("courseID"), p.ByName("requestID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error rejecting enrollment: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(enrollment); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAppServer_PostEnrollmentHandler(t *testing.T) {
+	courseService := mock.CourseService{}
+	service := mock.EnrollmentService{}
+	service.RequestEnrollmentFn = func(courseID string, userID string, message string, cj eduboard.CourseJoiner) (error, eduboard.EnrollmentRequest) {
+		assert.Equal(t, &courseService, cj, "course service was not passed")
+		switch courseID {
+		case "open":
+			return nil, eduboard.EnrollmentRequest{UserID: userID, Status: eduboard.EnrollmentApproved}
+		case "approval":
+			return nil, eduboard.EnrollmentRequest{UserID: userID, Status: eduboard.EnrollmentPending, Message: message}
+		case "invite-only":
+			return errors.Wrap(eduboard.ErrForbidden, "invite only"), eduboard.EnrollmentRequest{}
+		}
+		return errors.New("not found"), eduboard.EnrollmentRequest{}
+	}
+	a := AppServer{EnrollmentService: &service, CourseService: &courseService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		course  string
+		body    string
+		status  int
+		invoked bool
+	}{
+		{"open", "open", `{}`, 200, true},
+		{"approval", "approval", `{"message":"Please"}`, 201, true},
+		{"invite-only", "invite-only", `{}`, 403, true},
+		{"unknown", "unknown", `{}`, 404, true},
+		{"malformed json", "open", `{"message":`, 400, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.RequestEnrollmentFnInvoked = false
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.PostEnrollmentHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: v.course}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.RequestEnrollmentFnInvoked, "RequestEnrollment was not invoked as expected")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"status":"pending"`, "status is missing")
+				assert.Contains(t, rr.Body.String(), `"message":"Please"`, "message is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetEnrollmentHandler(t *testing.T) {
+	service := mock.EnrollmentService{}
+	service.GetEnrollmentFn = func(courseID string, userID string) (error, eduboard.EnrollmentRequest) {
+		if userID != "1" {
+			return errors.New("not found"), eduboard.EnrollmentRequest{}
+		}
+		return nil, eduboard.EnrollmentRequest{UserID: userID, Status: eduboard.EnrollmentRejected}
+	}
+	a := AppServer{EnrollmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"success", "1", 200},
+		{"no request", "2", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetEnrollmentHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"status":"rejected"`, "status is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_DeleteEnrollmentHandler(t *testing.T) {
+	service := mock.EnrollmentService{}
+	service.CancelEnrollmentFn = func(courseID string, userID string) error {
+		if userID != "1" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+	a := AppServer{EnrollmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"success", "1", 204},
+		{"no pending request", "2", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("DELETE", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.DeleteEnrollmentHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
+
+func TestAppServer_GetEnrollmentRequestsHandler(t *testing.T) {
+	service := mock.EnrollmentService{}
+	service.GetPendingEnrollmentsFn = func(courseID string, userID string) (error, []eduboard.EnrollmentRequest) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), []eduboard.EnrollmentRequest{}
+		}
+		return nil, []eduboard.EnrollmentRequest{{UserID: "2", Status: eduboard.EnrollmentPending}}
+	}
+	a := AppServer{EnrollmentService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"success", "1", 200},
+		{"not staff", "2", 403},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetEnrollmentRequestsHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"userID":"2"`, "requests are missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_DecideEnrollmentHandler(t *testing.T) {
+	courseService := mock.CourseService{}
+	service := mock.EnrollmentService{}
+	decide := func(requestID string, userID string, status eduboard.EnrollmentStatus) (error, eduboard.EnrollmentRequest) {
+		switch {
+		case userID != "1":
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.EnrollmentRequest{}
+		case requestID == "decided":
+			return errors.Wrap(eduboard.ErrInvalidInput, "already decided"), eduboard.EnrollmentRequest{}
+		case requestID != "1":
+			return errors.New("not found"), eduboard.EnrollmentRequest{}
+		}
+		return nil, eduboard.EnrollmentRequest{Status: status, DecidedBy: userID}
+	}
+	service.ApproveEnrollmentFn = func(courseID string, requestID string, userID string, cj eduboard.CourseJoiner) (error, eduboard.EnrollmentRequest) {
+		assert.Equal(t, &courseService, cj, "course service was not passed")
+		return decide(requestID, userID, eduboard.EnrollmentApproved)
+	}
+	service.RejectEnrollmentFn = func(courseID string, requestID string, userID string) (error, eduboard.EnrollmentRequest) {
+		return decide(requestID, userID, eduboard.EnrollmentRejected)
+	}
+	a := AppServer{EnrollmentService: &service, CourseService: &courseService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		approve bool
+		request string
+		user    string
+		status  int
+		body    string
+	}{
+		{"approve", true, "1", "1", 200, `"status":"approved"`},
+		{"reject", false, "1", "1", 200, `"status":"rejected"`},
+		{"not staff", true, "1", "2", 403, ""},
+		{"already decided", false, "decided", "1", 400, ""},
+		{"unknown", true, "2", "1", 404, ""},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			handler := a.RejectEnrollmentHandler()
+			if v.approve {
+				handler = a.ApproveEnrollmentHandler()
+			}
+			handler(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}, {Key: "requestID", Value: v.request}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Contains(t, rr.Body.String(), v.body, "body does not match")
+		})
+	}
+}
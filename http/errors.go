@@ -0,0 +1,43 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+// errorStatus returns the status code matching a service error, falling back to fallback for unknown errors.
+func errorStatus(err error, fallback int) int {
+	if _, ok := errors.Cause(err).(*eduboard.RoomConflictError); ok {
+		return http.StatusConflict
+	}
+
+	switch errors.Cause(err) {
+	case eduboard.ErrForbidden:
+		return http.StatusForbidden
+	case eduboard.ErrInvalidInput:
+		return http.StatusBadRequest
+	case eduboard.ErrArchived:
+		return http.StatusConflict
+	case eduboard.ErrExpired:
+		return http.StatusGone
+	case eduboard.ErrNotFound:
+		return http.StatusNotFound
+	}
+	return fallback
+}
+
+// writeError writes the status code matching a service error. Room conflicts also list the clashing courses,
+// so clients can tell users where to look for a free slot.
+func writeError(w http.ResponseWriter, err error, fallback int) {
+	type response struct {
+		Error     string                  `json:"error"`
+		Conflicts []eduboard.RoomConflict `json:"conflicts"`
+	}
+
+	w.WriteHeader(errorStatus(err, fallback))
+	if conflict, ok := errors.Cause(err).(*eduboard.RoomConflictError); ok {
+		json.NewEncoder(w).Encode(response{"room conflict", conflict.Conflicts})
+	}
+}
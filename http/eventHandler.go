@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+const (
+	// eventStreamDuration ends event streams before the write timeout of the server does. Clients reconnect on their
+	// own and resume the stream with the last event ID.
+	eventStreamDuration = writeTimeout - 5*time.Second
+	// eventHeartbeat keeps idle streams from being closed by proxies.
+	eventHeartbeat = 15 * time.Second
+	// eventRetry is the reconnection delay suggested to clients in milliseconds.
+	eventRetry = 1000
+)
+
+// GetEventsHandler streams the events of the user's courses as server-sent events. Streams are resumed by sending the
+// ID of the last received event in the Last-Event-ID header or the lastEventId parameter. If events may have been
+// missed in between, a reset event tells the client to reload its data.
+func (a *AppServer) GetEventsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			a.Logger.Printf("error streaming events: response can not be flushed")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		lastID := r.Header.Get("Last-Event-ID")
+		if lastID == "" {
+			lastID = r.URL.Query().Get("lastEventId")
+		}
+
+		s, missed, complete := a.Events.subscribe(r.Header.Get("userID"), lastID)
+		defer a.Events.unsubscribe(s)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, "retry: %d\n\n", eventRetry)
+		if !complete {
+			fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+		}
+		for _, e := range missed {
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(eventHeartbeat)
+		defer heartbeat.Stop()
+		end := time.NewTimer(eventStreamDuration)
+		defer end.Stop()
+
+		for {
+			select {
+			case e, open := <-s.events:
+				if !open {
+					// The stream fell behind and was dropped by the hub.
+					return
+				}
+				if err := writeEvent(w, e); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			case <-end.C:
+				return
+			case <-r.Context().Done():
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e eduboard.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
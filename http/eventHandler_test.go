@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestAppServer_GetEventsHandler(t *testing.T) {
+	h := NewEventHub(10)
+	h.Publish(eduboard.Event{Type: eduboard.EventEntryCreated, CourseID: "1", Recipients: []string{"1"}})
+	h.Publish(eduboard.Event{Type: eduboard.EventEntryDeleted, CourseID: "1", Recipients: []string{"2"}})
+	h.Publish(eduboard.Event{Type: eduboard.EventMemberAdded, CourseID: "1", UserIDs: []string{"3"}, Recipients: []string{"1"}})
+	a := &AppServer{Events: h, Logger: log.New(os.Stdout, "", 0)}
+
+	testCases := []struct {
+		name     string
+		lastID   string
+		query    string
+		contains []string
+		excludes []string
+	}{
+		{"new stream", "", "", []string{"retry: 1000\n"}, []string{"event: reset", "id: "}},
+		{"resume", h.prefix + "-1", "", []string{"id: " + h.prefix + "-3\nevent: member.added\ndata: {"},
+			[]string{"event: reset", "entry.deleted"}},
+		{"resume by parameter", "", "?lastEventId=" + h.prefix + "-1", []string{"id: " + h.prefix + "-3\n"}, []string{"event: reset"}},
+		{"unknown event", "unknown", "", []string{"event: reset\n"}, []string{"id: "}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			// The request is cancelled up front, so that the handler returns after writing the missed events.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			r := httptest.NewRequest("GET", "/"+v.query, nil).WithContext(ctx)
+			r.Header.Set("userID", "1")
+			if v.lastID != "" {
+				r.Header.Set("Last-Event-ID", v.lastID)
+			}
+
+			rr := httptest.NewRecorder()
+			a.GetEventsHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, 200, rr.Code, "status code does not match")
+			assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"), "content type does not match")
+			for _, c := range v.contains {
+				assert.Contains(t, rr.Body.String(), c, "stream is missing content")
+			}
+			for _, e := range v.excludes {
+				assert.NotContains(t, rr.Body.String(), e, "stream contains unexpected content")
+			}
+			assert.Empty(t, h.subscribers, "stream was not unsubscribed")
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"fmt"
+	"github.com/eduboard/backend"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultEventHistory is the number of events kept for clients that reconnect.
+	DefaultEventHistory = 1000
+	// subscriberBuffer is the number of events a subscriber may fall behind before it is disconnected.
+	subscriberBuffer = 64
+)
+
+// EventHub distributes events to the streams of connected users. It keeps the latest events, so that clients can
+// resume a stream with the ID of the last event they received.
+//
+// Event IDs are prefixed with the start time of the hub, as IDs of a previous process would refer to other events.
+type EventHub struct {
+	mu          sync.Mutex
+	prefix      string
+	next        uint64
+	size        int
+	history     []eduboard.Event
+	subscribers map[*subscriber]bool
+}
+
+var _ eduboard.EventPublisher = (*EventHub)(nil)
+
+type subscriber struct {
+	userID string
+	events chan eduboard.Event
+}
+
+// NewEventHub returns a hub that keeps the last size events.
+func NewEventHub(size int) *EventHub {
+	return &EventHub{
+		prefix:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		size:        size,
+		history:     []eduboard.Event{},
+		subscribers: map[*subscriber]bool{},
+	}
+}
+
+// Publish assigns an ID to event and sends it to all connected recipients. Subscribers that fell too far behind
+// are disconnected and have to resume their stream.
+func (h *EventHub) Publish(event eduboard.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.next++
+	event.ID = fmt.Sprintf("%s-%d", h.prefix, h.next)
+	h.history = append(h.history, event)
+	if len(h.history) > h.size {
+		h.history = h.history[len(h.history)-h.size:]
+	}
+
+	for s := range h.subscribers {
+		if !isRecipient(event, s.userID) {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			close(s.events)
+			delete(h.subscribers, s)
+		}
+	}
+}
+
+// subscribe connects a stream of userID. If lastID is set, the events of userID published after it are returned
+// as well. complete is false if lastID is unknown or has already left the history, so that events may have been missed.
+func (h *EventHub) subscribe(userID string, lastID string) (s *subscriber, missed []eduboard.Event, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s = &subscriber{userID: userID, events: make(chan eduboard.Event, subscriberBuffer)}
+	h.subscribers[s] = true
+
+	missed = []eduboard.Event{}
+	if lastID == "" {
+		return s, missed, true
+	}
+
+	n, ok := h.sequence(lastID)
+	first := h.next - uint64(len(h.history)) + 1
+	if !ok || n > h.next || n+1 < first {
+		return s, missed, false
+	}
+	for _, e := range h.history[n+1-first:] {
+		if isRecipient(e, userID) {
+			missed = append(missed, e)
+		}
+	}
+	return s, missed, true
+}
+
+func (h *EventHub) unsubscribe(s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[s] {
+		close(s.events)
+		delete(h.subscribers, s)
+	}
+}
+
+// sequence returns the sequence number of an event ID issued by this hub.
+func (h *EventHub) sequence(id string) (uint64, bool) {
+	if !strings.HasPrefix(id, h.prefix+"-") {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(id, h.prefix+"-"), 10, 64)
+	return n, err == nil
+}
+
+func isRecipient(event eduboard.Event, userID string) bool {
+	for _, r := range event.Recipients {
+		if r == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEventHub_Publish(t *testing.T) {
+	h := NewEventHub(10)
+	s1, _, _ := h.subscribe("1", "")
+	s2, _, _ := h.subscribe("2", "")
+
+	h.Publish(eduboard.Event{Type: eduboard.EventEntryCreated, Recipients: []string{"1"}})
+	h.Publish(eduboard.Event{Type: eduboard.EventEntryUpdated, Recipients: []string{"1", "2"}})
+
+	assert.Len(t, s1.events, 2, "events of subscriber 1 do not match")
+	assert.Len(t, s2.events, 1, "events of subscriber 2 do not match")
+	e := <-s2.events
+	assert.Equal(t, eduboard.EventEntryUpdated, e.Type, "event type does not match")
+	assert.Equal(t, h.prefix+"-2", e.ID, "event ID does not match")
+
+	h.unsubscribe(s1)
+	h.unsubscribe(s2)
+	assert.Empty(t, h.subscribers, "subscribers were not removed")
+}
+
+func TestEventHub_Publish_Lagging(t *testing.T) {
+	h := NewEventHub(10)
+	s, _, _ := h.subscribe("1", "")
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		h.Publish(eduboard.Event{Recipients: []string{"1"}})
+	}
+	assert.Empty(t, h.subscribers, "lagging subscriber was not dropped")
+
+	n := 0
+	for range s.events {
+		n++
+	}
+	assert.Equal(t, subscriberBuffer, n, "buffered events do not match")
+
+	// Unsubscribing a dropped subscriber must not close its channel again.
+	h.unsubscribe(s)
+}
+
+func TestEventHub_subscribe(t *testing.T) {
+	h := NewEventHub(3)
+	for i := 0; i < 5; i++ {
+		recipients := []string{"1"}
+		if i == 3 {
+			recipients = []string{"2"}
+		}
+		h.Publish(eduboard.Event{Recipients: recipients})
+	}
+
+	testCases := []struct {
+		name     string
+		lastID   string
+		complete bool
+		missed   []string
+	}{
+		{"new stream", "", true, []string{}},
+		{"up to date", h.prefix + "-5", true, []string{}},
+		{"resume", h.prefix + "-2", true, []string{h.prefix + "-3", h.prefix + "-5"}},
+		{"oldest kept event", h.prefix + "-3", true, []string{h.prefix + "-5"}},
+		{"left history", h.prefix + "-1", false, []string{}},
+		{"future event", h.prefix + "-6", false, []string{}},
+		{"previous process", "abc-4", false, []string{}},
+		{"malformed", "garbage", false, []string{}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			s, missed, complete := h.subscribe("1", v.lastID)
+			defer h.unsubscribe(s)
+
+			assert.Equal(t, v.complete, complete, "completeness does not match")
+			ids := []string{}
+			for _, e := range missed {
+				ids = append(ids, e.ID)
+			}
+			assert.Equal(t, v.missed, ids, "missed events do not match")
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// GetGradebookHandler returns the whole gradebook to the staff and the own grades to students.
+func (a *AppServer) GetGradebookHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, gradebook := a.GradebookService.GetGradebook(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting gradebook: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(gradebook); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) ExportGradebookHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		// The export is buffered so that errors can still be answered with a status code.
+		var buf bytes.Buffer
+		if err := a.GradebookService.ExportGradebook(p.ByName("courseID"), r.Header.Get("userID"), a.UserRepository, &buf); err != nil {
+			a.Logger.Printf("error exporting gradebook: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="gradebook.csv"`)
+		if _, err := buf.WriteTo(w); err != nil {
+			a.Logger.Printf("error writing gradebook: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) GetGradeCategoriesHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, categories := a.GradebookService.GetCategories(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting grade categories: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(categories); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// PutGradeCategoriesHandler replaces all grade categories of a course.
+func (a *AppServer) PutGradeCategoriesHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		categories := []eduboard.GradeCategory{}
+		if err := json.NewDecoder(r.Body).Decode(&categories); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, categories := a.GradebookService.SetCategories(p.ByName("courseID"), r.Header.Get("userID"), categories)
+		if err != nil {
+			a.Logger.Printf("error setting grade categories: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(categories); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// PutGradeHandler records the grade of a student for an assignment.
+func (a *AppServer) PutGradeHandler() httprouter.Handle {
+	type request struct {
+		Points   *float64 `json:"points"`
+		Feedback string   `json:"feedback"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Points == nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, grade := a.GradebookService.SetGrade(p.ByName("courseID"), p.ByName("assignmentID"), p.ByName("userID"), r.Header.Get("userID"), *req.Points, req.Feedback)
+		if err != nil {
+			a.Logger.Printf("error setting grade: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(grade); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) DeleteGradeHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.GradebookService.DeleteGrade(p.ByName("courseID"), p.ByName("assignmentID"), p.ByName("userID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error deleting grade: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
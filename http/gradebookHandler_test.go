@@ -0,0 +1,182 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAppServer_GetGradebookHandler(t *testing.T) {
+	service := mock.GradebookService{}
+	service.GetGradebookFn = func(courseID string, userID string) (error, eduboard.Gradebook) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "no member"), eduboard.Gradebook{}
+		}
+		total := 80.0
+		return nil, eduboard.Gradebook{Rows: []eduboard.GradebookRow{{UserID: userID, Total: &total}}}
+	}
+	a := AppServer{GradebookService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"1": 200, "2": 403} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("userID", user)
+		rr := httptest.NewRecorder()
+
+		a.GetGradebookHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+		assert.Equal(t, status, rr.Code, "status code does not match")
+		if status == 200 {
+			assert.Contains(t, rr.Body.String(), `"total":80`, "total is missing")
+		}
+	}
+}
+
+func TestAppServer_ExportGradebookHandler(t *testing.T) {
+	users := &mock.UserRepository{}
+	service := mock.GradebookService{}
+	service.ExportGradebookFn = func(courseID string, userID string, uf eduboard.UserFinder, w io.Writer) error {
+		assert.Equal(t, users, uf, "user repository was not passed")
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff")
+		}
+		_, err := io.WriteString(w, "User ID,Total\n")
+		return err
+	}
+	a := AppServer{GradebookService: &service, UserRepository: users, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"staff", "1", 200},
+		{"student", "2", 403},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.ExportGradebookHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"), "content type does not match")
+				assert.Equal(t, "User ID,Total\n", rr.Body.String(), "body does not match")
+			} else {
+				assert.Empty(t, rr.Body.String(), "body was written")
+			}
+		})
+	}
+}
+
+func TestAppServer_PutGradeCategoriesHandler(t *testing.T) {
+	service := mock.GradebookService{}
+	service.SetCategoriesFn = func(courseID string, userID string, categories []eduboard.GradeCategory) (error, []eduboard.GradeCategory) {
+		for _, v := range categories {
+			if v.Weight <= 0 {
+				return errors.Wrap(eduboard.ErrInvalidInput, "no weight"), []eduboard.GradeCategory{}
+			}
+		}
+		return nil, categories
+	}
+	a := AppServer{GradebookService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		body    string
+		status  int
+		invoked bool
+	}{
+		{"success", `[{"name":"Exams","weight":3}]`, 200, true},
+		{"no weight", `[{"name":"Exams"}]`, 400, true},
+		{"malformed json", `[{"name":`, 400, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.SetCategoriesFnInvoked = false
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.PutGradeCategoriesHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.SetCategoriesFnInvoked, "SetCategories was not invoked as expected")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"name":"Exams"`, "category is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_PutGradeHandler(t *testing.T) {
+	service := mock.GradebookService{}
+	service.SetGradeFn = func(courseID string, assignmentID string, studentID string, userID string, points float64, feedback string) (error, eduboard.Grade) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Grade{}
+		}
+		return nil, eduboard.Grade{UserID: studentID, Points: points, Feedback: feedback, GradedBy: userID}
+	}
+	a := AppServer{GradebookService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		user    string
+		body    string
+		status  int
+		invoked bool
+	}{
+		{"success", "1", `{"points":7.5,"feedback":"Well done"}`, 200, true},
+		{"zero points", "1", `{"points":0}`, 200, true},
+		{"not staff", "2", `{"points":7.5}`, 403, true},
+		{"no points", "1", `{"feedback":"Well done"}`, 400, false},
+		{"malformed json", "1", `{"points":`, 400, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.SetGradeFnInvoked = false
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			params := httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "assignmentID", Value: "assignment"}, {Key: "userID", Value: "student"}}
+			a.PutGradeHandler()(rr, r, params)
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.SetGradeFnInvoked, "SetGrade was not invoked as expected")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"userID":"student"`, "student is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_DeleteGradeHandler(t *testing.T) {
+	service := mock.GradebookService{}
+	service.DeleteGradeFn = func(courseID string, assignmentID string, studentID string, userID string) error {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff")
+		}
+		return nil
+	}
+	a := AppServer{GradebookService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"1": 204, "2": 403} {
+		r := httptest.NewRequest("DELETE", "/", nil)
+		r.Header.Set("userID", user)
+		rr := httptest.NewRecorder()
+
+		params := httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "assignmentID", Value: "assignment"}, {Key: "userID", Value: "student"}}
+		a.DeleteGradeHandler()(rr, r, params)
+		assert.Equal(t, status, rr.Code, "status code does not match")
+	}
+}
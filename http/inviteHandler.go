@@ -0,0 +1,128 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+func (a *AppServer) GetInviteCodesHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, codes := a.InviteService.GetInviteCodes(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting invite codes: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(codes); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// PostInviteCodeHandler creates an invite code. Both expiresAt and maxUses are optional.
+func (a *AppServer) PostInviteCodeHandler() httprouter.Handle {
+	type request struct {
+		ExpiresAt *time.Time `json:"expiresAt"`
+		MaxUses   int        `json:"maxUses"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, code := a.InviteService.CreateInviteCode(p.ByName("courseID"), r.Header.Get("userID"), req.ExpiresAt, req.MaxUses)
+		if err != nil {
+			a.Logger.Printf("error creating invite code: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(code); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) DeleteInviteCodeHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err := a.InviteService.RevokeInviteCode(p.ByName("courseID"), p.ByName("inviteID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error revoking invite code: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// JoinCourseHandler adds the current user to the course of an invite code.
+func (a *AppServer) JoinCourseHandler() httprouter.Handle {
+	type response struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, course := a.InviteService.Join(p.ByName("code"), r.Header.Get("userID"), a.CourseService)
+		if err != nil {
+			a.Logger.Printf("error joining course: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(response{ID: course.ID.Hex(), Title: course.Title}); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) GetEmailInvitesHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, invites := a.InviteService.GetEmailInvites(p.ByName("courseID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting email invites: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(invites); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// PostEmailInviteHandler invites an email address to a course. Existing users are added right away.
+func (a *AppServer) PostEmailInviteHandler() httprouter.Handle {
+	type request struct {
+		Email string `json:"email"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, invite := a.InviteService.InviteEmail(p.ByName("courseID"), r.Header.Get("userID"), req.Email, a.CourseService)
+		if err != nil {
+			a.Logger.Printf("error inviting email: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(invite); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
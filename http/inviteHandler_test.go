@@ -0,0 +1,233 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppServer_GetInviteCodesHandler(t *testing.T) {
+	service := mock.InviteService{}
+	service.GetInviteCodesFn = func(courseID string, userID string) (error, []eduboard.InviteCode) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), []eduboard.InviteCode{}
+		}
+		return nil, []eduboard.InviteCode{{Code: "ABCDEFGH"}}
+	}
+	a := AppServer{InviteService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"success", "1", 200},
+		{"not staff", "2", 403},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetInviteCodesHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"code":"ABCDEFGH"`, "codes are missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_PostInviteCodeHandler(t *testing.T) {
+	service := mock.InviteService{}
+	service.CreateInviteCodeFn = func(courseID string, userID string, expiresAt *time.Time, maxUses int) (error, eduboard.InviteCode) {
+		if maxUses < 0 {
+			return errors.Wrap(eduboard.ErrInvalidInput, "negative"), eduboard.InviteCode{}
+		}
+		return nil, eduboard.InviteCode{Code: "ABCDEFGH", ExpiresAt: expiresAt, MaxUses: maxUses}
+	}
+	a := AppServer{InviteService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		body    string
+		status  int
+		invoked bool
+	}{
+		{"success", `{}`, 201, true},
+		{"limited", `{"expiresAt":"2030-01-01T00:00:00Z","maxUses":5}`, 201, true},
+		{"invalid uses", `{"maxUses":-1}`, 400, true},
+		{"invalid expiry", `{"expiresAt":"tomorrow"}`, 400, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.CreateInviteCodeFnInvoked = false
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			rr := httptest.NewRecorder()
+
+			a.PostInviteCodeHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.CreateInviteCodeFnInvoked, "CreateInviteCode was not invoked as expected")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"code":"ABCDEFGH"`, "code is missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_DeleteInviteCodeHandler(t *testing.T) {
+	service := mock.InviteService{}
+	service.RevokeInviteCodeFn = func(courseID string, codeID string, userID string) error {
+		if codeID != "1" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+	a := AppServer{InviteService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		code   string
+		status int
+	}{
+		{"success", "1", 204},
+		{"unknown", "2", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("DELETE", "/", nil)
+			rr := httptest.NewRecorder()
+
+			a.DeleteInviteCodeHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}, {Key: "inviteID", Value: v.code}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
+
+func TestAppServer_JoinCourseHandler(t *testing.T) {
+	courseService := mock.CourseService{}
+	service := mock.InviteService{}
+	service.JoinFn = func(code string, userID string, cj eduboard.CourseJoiner) (error, eduboard.Course) {
+		assert.Equal(t, &courseService, cj, "course service was not passed")
+		switch code {
+		case "EXPIRED2":
+			return errors.Wrap(eduboard.ErrExpired, "expired"), eduboard.Course{}
+		case "ARCHIVED":
+			return errors.Wrap(eduboard.ErrArchived, "archived"), eduboard.Course{}
+		case "VALID234":
+			return nil, eduboard.Course{ID: bson.ObjectIdHex("5b23bbdc2bfa844c41a9f134"), Title: "Algorithms"}
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
+	a := AppServer{InviteService: &service, CourseService: &courseService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		code   string
+		status int
+	}{
+		{"success", "VALID234", 200},
+		{"expired", "EXPIRED2", 410},
+		{"archived", "ARCHIVED", 409},
+		{"unknown", "UNKNOWN2", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", nil)
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.JoinCourseHandler()(rr, r, httprouter.Params{{Key: "code", Value: v.code}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.JSONEq(t, `{"id":"5b23bbdc2bfa844c41a9f134","title":"Algorithms"}`, rr.Body.String(), "body does not match")
+			}
+		})
+	}
+}
+
+func TestAppServer_PostEmailInviteHandler(t *testing.T) {
+	service := mock.InviteService{}
+	service.InviteEmailFn = func(courseID string, userID string, email string, cj eduboard.CourseJoiner) (error, eduboard.EmailInvite) {
+		if email == "" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "invalid email"), eduboard.EmailInvite{}
+		}
+		return nil, eduboard.EmailInvite{Email: email, TokenHash: "hash"}
+	}
+	a := AppServer{InviteService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		body    string
+		status  int
+		invoked bool
+	}{
+		{"success", `{"email":"e@mail.com"}`, 201, true},
+		{"invalid email", `{}`, 400, true},
+		{"malformed json", `{"email":`, 400, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.InviteEmailFnInvoked = false
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			rr := httptest.NewRecorder()
+
+			a.PostEmailInviteHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.InviteEmailFnInvoked, "InviteEmail was not invoked as expected")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"email":"e@mail.com"`, "invite is missing")
+				assert.NotContains(t, rr.Body.String(), "hash", "token hash was exposed")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetEmailInvitesHandler(t *testing.T) {
+	service := mock.InviteService{}
+	service.GetEmailInvitesFn = func(courseID string, userID string) (error, []eduboard.EmailInvite) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), []eduboard.EmailInvite{}
+		}
+		return nil, []eduboard.EmailInvite{{Email: "e@mail.com"}}
+	}
+	a := AppServer{InviteService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		status int
+	}{
+		{"success", "1", 200},
+		{"not staff", "2", 403},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetEmailInvitesHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"email":"e@mail.com"`, "invites are missing")
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetMaterialsHandler lists the folder given by the folderID query parameter, or the top of the library.
+func (a *AppServer) GetMaterialsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, listing := a.MaterialService.GetListing(p.ByName("courseID"), r.URL.Query().Get("folderID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting materials: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(listing); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) GetMaterialHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, material := a.MaterialService.GetMaterial(p.ByName("courseID"), p.ByName("materialID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting material: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(material); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// PostMaterialHandler adds an upload of the course to the library.
+func (a *AppServer) PostMaterialHandler() httprouter.Handle {
+	type request struct {
+		FolderID    string `json:"folderID"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		UploadID    string `json:"uploadID"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.FolderID != "" && !bson.IsObjectIdHex(req.FolderID) {
+			a.Logger.Printf("folderID %s is not a valid objectID", req.FolderID)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		material := eduboard.Material{
+			Name:        req.Name,
+			Description: req.Description,
+		}
+		if req.FolderID != "" {
+			material.FolderID = bson.ObjectIdHex(req.FolderID)
+		}
+		err, material := a.MaterialService.CreateMaterial(p.ByName("courseID"), r.Header.Get("userID"), material, req.UploadID)
+		if err != nil {
+			a.Logger.Printf("error creating material: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(material); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+// UpdateMaterialHandler changes the fields present in the request body. An empty folderID moves the material
+// to the top of the library.
+func (a *AppServer) UpdateMaterialHandler() httprouter.Handle {
+	type request struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		FolderID    *string `json:"folderID"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		update := eduboard.MaterialUpdate{
+			Name:        req.Name,
+			Description: req.Description,
+			FolderID:    req.FolderID,
+		}
+		err, material := a.MaterialService.UpdateMaterial(p.ByName("courseID"), p.ByName("materialID"), r.Header.Get("userID"), update)
+		if err != nil {
+			a.Logger.Printf("error updating material: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(material); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) DeleteMaterialHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.MaterialService.DeleteMaterial(p.ByName("courseID"), p.ByName("materialID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error deleting material: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// PostMaterialVersionHandler makes an upload of the course the latest version of a material.
+func (a *AppServer) PostMaterialVersionHandler() httprouter.Handle {
+	type request struct {
+		UploadID string `json:"uploadID"`
+		Comment  string `json:"comment"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, material := a.MaterialService.AddVersion(p.ByName("courseID"), p.ByName("materialID"), r.Header.Get("userID"), req.UploadID, req.Comment)
+		if err != nil {
+			a.Logger.Printf("error adding material version: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(material); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+// GetMaterialDownloadHandler sends the version given by the version query parameter, or the latest version.
+func (a *AppServer) GetMaterialDownloadHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		version := 0
+		if v := r.URL.Query().Get("version"); v != "" {
+			var err error
+			if version, err = strconv.Atoi(v); err != nil || version < 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		err, v, content := a.MaterialService.OpenMaterial(p.ByName("courseID"), p.ByName("materialID"), r.Header.Get("userID"), version)
+		if err != nil {
+			a.Logger.Printf("error opening material: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		defer content.Close()
+
+		// Materials are served like uploads, see serveUpload.
+		disposition := "attachment"
+		if strings.HasPrefix(v.ContentType, "image/") {
+			disposition = "inline"
+		}
+
+		w.Header().Set("Content-Type", v.ContentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": v.Filename}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "private, max-age=86400")
+		if _, err = io.Copy(w, content); err != nil {
+			a.Logger.Printf("error sending material %s: %v", p.ByName("materialID"), err)
+		}
+	}
+}
+
+// GetMaterialsArchiveHandler sends the whole library as a zip archive.
+func (a *AppServer) GetMaterialsArchiveHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		a.exportMaterials(w, p.ByName("courseID"), "", r.Header.Get("userID"), "materials.zip")
+	}
+}
+
+// GetMaterialFolderArchiveHandler sends a folder and its subfolders as a zip archive named after the folder.
+func (a *AppServer) GetMaterialFolderArchiveHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, folder := a.MaterialService.GetFolder(p.ByName("courseID"), p.ByName("folderID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting material folder: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		a.exportMaterials(w, p.ByName("courseID"), p.ByName("folderID"), r.Header.Get("userID"), folder.Name+".zip")
+	}
+}
+
+func (a *AppServer) exportMaterials(w http.ResponseWriter, courseID string, folderID string, userID string, filename string) {
+	archive := &archiveWriter{w: w, filename: filename}
+	if err := a.MaterialService.ExportFolder(courseID, folderID, userID, archive); err != nil {
+		a.Logger.Printf("error exporting materials: %v", err)
+		// Once the archive has been started the status can not be changed anymore.
+		if archive.started {
+			return
+		}
+		if errors.Cause(err) == eduboard.ErrTooLarge {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(errorStatus(err, http.StatusNotFound))
+	}
+}
+
+// archiveWriter sets the headers of a zip download before the first byte of the archive is written,
+// so that errors occurring before can still be reported with their status code.
+type archiveWriter struct {
+	w        http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (aw *archiveWriter) Write(b []byte) (int, error) {
+	if !aw.started {
+		aw.started = true
+		aw.w.Header().Set("Content-Type", "application/zip")
+		aw.w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": aw.filename}))
+		aw.w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	return aw.w.Write(b)
+}
+
+// PostMaterialFolderHandler creates a folder in the folder given by parentID, or at the top of the library.
+func (a *AppServer) PostMaterialFolderHandler() httprouter.Handle {
+	type request struct {
+		ParentID string `json:"parentID"`
+		Name     string `json:"name"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.ParentID != "" && !bson.IsObjectIdHex(req.ParentID) {
+			a.Logger.Printf("parentID %s is not a valid objectID", req.ParentID)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		folder := eduboard.MaterialFolder{Name: req.Name}
+		if req.ParentID != "" {
+			folder.ParentID = bson.ObjectIdHex(req.ParentID)
+		}
+		err, folder := a.MaterialService.CreateFolder(p.ByName("courseID"), r.Header.Get("userID"), folder)
+		if err != nil {
+			a.Logger.Printf("error creating material folder: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(folder); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+// UpdateMaterialFolderHandler renames or moves a folder. An empty parentID moves it to the top of the library.
+func (a *AppServer) UpdateMaterialFolderHandler() httprouter.Handle {
+	type request struct {
+		Name     *string `json:"name"`
+		ParentID *string `json:"parentID"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		update := eduboard.MaterialFolderUpdate{
+			Name:     req.Name,
+			ParentID: req.ParentID,
+		}
+		err, folder := a.MaterialService.UpdateFolder(p.ByName("courseID"), p.ByName("folderID"), r.Header.Get("userID"), update)
+		if err != nil {
+			a.Logger.Printf("error updating material folder: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(folder); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// DeleteMaterialFolderHandler deletes a folder along with everything in it.
+func (a *AppServer) DeleteMaterialFolderHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.MaterialService.DeleteFolder(p.ByName("courseID"), p.ByName("folderID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error deleting material folder: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
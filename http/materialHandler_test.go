@@ -0,0 +1,240 @@
+package http
+
+import (
+	"archive/zip"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAppServer_PostMaterialHandler(t *testing.T) {
+	service := mock.MaterialService{}
+	service.CreateMaterialFn = func(courseID string, userID string, material eduboard.Material, uploadID string) (error, eduboard.Material) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Material{}
+		}
+		if uploadID != "upload" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "unknown upload"), eduboard.Material{}
+		}
+		material.Versions = []eduboard.MaterialVersion{{Version: 1, Filename: "notes.pdf"}}
+		return nil, material
+	}
+	a := AppServer{MaterialService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		body   string
+		status int
+	}{
+		{"valid", "1", `{"folderID": "5b23bbdc2bfa844c41a9f140", "name": "Notes", "uploadID": "upload"}`, 201},
+		{"top", "1", `{"uploadID": "upload"}`, 201},
+		{"unknown upload", "1", `{"uploadID": "other"}`, 400},
+		{"invalid folder", "1", `{"folderID": "slides", "uploadID": "upload"}`, 400},
+		{"student", "2", `{"uploadID": "upload"}`, 403},
+		{"malformed", "1", `{"uploadID":`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.PostMaterialHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"filename":"notes.pdf"`, "versions are missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetMaterialsHandler(t *testing.T) {
+	service := mock.MaterialService{}
+	service.GetListingFn = func(courseID string, folderID string, userID string) (error, eduboard.MaterialListing) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "no member"), eduboard.MaterialListing{}
+		}
+		if folderID != "" {
+			return errors.New("not found"), eduboard.MaterialListing{}
+		}
+		return nil, eduboard.MaterialListing{Path: []eduboard.MaterialFolder{}, Folders: []eduboard.MaterialFolder{{Name: "Slides"}}, Materials: []eduboard.Material{}}
+	}
+	a := AppServer{MaterialService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		url    string
+		status int
+	}{
+		{"top", "1", "/", 200},
+		{"unknown folder", "1", "/?folderID=5b23bbdc2bfa844c41a9f140", 404},
+		{"no member", "2", "/", 403},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", v.url, nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetMaterialsHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"name":"Slides"`, "folders are missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetMaterialDownloadHandler(t *testing.T) {
+	service := mock.MaterialService{}
+	service.OpenMaterialFn = func(courseID string, materialID string, userID string, version int) (error, eduboard.MaterialVersion, io.ReadCloser) {
+		if version > 2 {
+			return errors.New("no such version"), eduboard.MaterialVersion{}, nil
+		}
+		if version == 0 {
+			version = 2
+		}
+		content := ioutil.NopCloser(strings.NewReader("version " + string(rune('0'+version))))
+		return nil, eduboard.MaterialVersion{Version: version, Filename: "notes.pdf", ContentType: "application/pdf"}, content
+	}
+	a := AppServer{MaterialService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		url     string
+		status  int
+		content string
+	}{
+		{"latest", "/", 200, "version 2"},
+		{"first", "/?version=1", 200, "version 1"},
+		{"missing", "/?version=3", 404, ""},
+		{"invalid", "/?version=first", 400, ""},
+		{"zero", "/?version=0", 400, ""},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", v.url, nil)
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.GetMaterialDownloadHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "materialID", Value: "material"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Equal(t, v.content, rr.Body.String(), "content does not match")
+				assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"), "content type does not match")
+				assert.Equal(t, `attachment; filename=notes.pdf`, rr.Header().Get("Content-Disposition"), "disposition does not match")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetMaterialFolderArchiveHandler(t *testing.T) {
+	service := mock.MaterialService{}
+	service.GetFolderFn = func(courseID string, folderID string, userID string) (error, eduboard.MaterialFolder) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "no member"), eduboard.MaterialFolder{}
+		}
+		return nil, eduboard.MaterialFolder{ID: bson.ObjectIdHex(folderID), Name: "Week 1"}
+	}
+	service.ExportFolderFn = func(courseID string, folderID string, userID string, w io.Writer) error {
+		zw := zip.NewWriter(w)
+		f, _ := zw.Create("intro.pdf")
+		f.Write([]byte("slides"))
+		return zw.Close()
+	}
+	a := AppServer{MaterialService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"1": 200, "2": 403} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("userID", user)
+		rr := httptest.NewRecorder()
+
+		a.GetMaterialFolderArchiveHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "folderID", Value: "5b23bbdc2bfa844c41a9f140"}})
+		assert.Equal(t, status, rr.Code, "status code does not match")
+		if status != 200 {
+			assert.Empty(t, rr.Header().Get("Content-Disposition"), "error response is a download")
+			continue
+		}
+		assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"), "content type does not match")
+		assert.Equal(t, `attachment; filename="Week 1.zip"`, rr.Header().Get("Content-Disposition"), "disposition does not match")
+		zr, err := zip.NewReader(strings.NewReader(rr.Body.String()), int64(rr.Body.Len()))
+		assert.Nil(t, err, "archive can not be read")
+		assert.Len(t, zr.File, 1, "unexpected number of files")
+	}
+}
+
+func TestAppServer_GetMaterialsArchiveHandler(t *testing.T) {
+	service := mock.MaterialService{}
+	service.ExportFolderFn = func(courseID string, folderID string, userID string, w io.Writer) error {
+		assert.Empty(t, folderID, "did not export the whole library")
+		switch userID {
+		case "1":
+			return zip.NewWriter(w).Close()
+		case "3":
+			return errors.Wrap(eduboard.ErrTooLarge, "library is too large")
+		}
+		return errors.Wrap(eduboard.ErrForbidden, "no member")
+	}
+	a := AppServer{MaterialService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"1": 200, "2": 403, "3": 413} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("userID", user)
+		rr := httptest.NewRecorder()
+
+		a.GetMaterialsArchiveHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}})
+		assert.Equal(t, status, rr.Code, "status code does not match")
+		assert.Equal(t, status == 200, rr.Header().Get("Content-Type") == "application/zip", "content type does not match")
+	}
+}
+
+func TestAppServer_UpdateMaterialFolderHandler(t *testing.T) {
+	service := mock.MaterialService{}
+	service.UpdateFolderFn = func(courseID string, folderID string, userID string, update eduboard.MaterialFolderUpdate) (error, eduboard.MaterialFolder) {
+		if update.ParentID != nil && *update.ParentID == folderID {
+			return errors.Wrap(eduboard.ErrInvalidInput, "moved into itself"), eduboard.MaterialFolder{}
+		}
+		if update.Name == nil {
+			return nil, eduboard.MaterialFolder{Name: "Slides"}
+		}
+		return nil, eduboard.MaterialFolder{Name: *update.Name}
+	}
+	a := AppServer{MaterialService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"rename", `{"name": "Lectures"}`, 200},
+		{"move to top", `{"parentID": ""}`, 200},
+		{"into itself", `{"parentID": "folder"}`, 400},
+		{"malformed", `{"name":`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.UpdateMaterialFolderHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "folderID", Value: "folder"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
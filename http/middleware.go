@@ -2,6 +2,7 @@ package http
 
 import (
 	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
 	"log"
 	"net/http"
 )
@@ -15,6 +16,8 @@ func Chain(final http.Handler, m ...func(handler http.Handler) http.Handler) htt
 	return final
 }
 
+// NewAuthMiddleware only lets requests with a valid session cookie pass. The ID of the authenticated user
+// and of the session are passed on in the userID and sessionID headers.
 func NewAuthMiddleware(provider eduboard.UserAuthenticationProvider) func(handler http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -24,18 +27,38 @@ func NewAuthMiddleware(provider eduboard.UserAuthenticationProvider) func(handle
 				return
 			}
 
-			sessionID := cookie.Value
-			err, userID := provider.CheckAuthentication(sessionID)
+			err, session := provider.CheckAuthentication(cookie.Value)
 			if err != nil {
 				w.WriteHeader(http.StatusForbidden)
 				return
 			}
-			r.Header.Set("userID", userID)
+
+			// The session might have been renewed, so the cookie has to follow its new expiry.
+			renewed := createCookie(cookie.Value, session.ExpiresAt)
+			http.SetCookie(w, &renewed)
+
+			r.Header.Set("userID", session.UserID)
+			r.Header.Set("sessionID", session.ID.Hex())
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// NewVerifiedMiddleware only lets users pass who confirmed their email. It relies on the userID header
+// set by the auth middleware and is applied to single routes, as unverified users may still read.
+func NewVerifiedMiddleware(uS eduboard.UserService) func(next httprouter.Handle) httprouter.Handle {
+	return func(next httprouter.Handle) httprouter.Handle {
+		return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+			err, user := uS.GetUser(r.Header.Get("userID"))
+			if err != nil || !user.Verified {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next(w, r, p)
+		}
+	}
+}
+
 func Logger(l *log.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -49,7 +72,7 @@ func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:8080")
 		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Last-Event-ID")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if r.Method == "OPTIONS" {
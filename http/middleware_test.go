@@ -3,13 +3,16 @@ package http
 import (
 	"bytes"
 	"errors"
+	"github.com/eduboard/backend"
 	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestChain(t *testing.T) {
@@ -40,14 +43,14 @@ func TestChain(t *testing.T) {
 
 func TestAppServer_NewAuthMiddleware(t *testing.T) {
 	var as = &mock.UserAuthenticationProvider{
-		CheckAuthenticationFn: func(sessionID string) (err error, id string) {
+		CheckAuthenticationFn: func(sessionID string) (err error, session eduboard.Session) {
 			if sessionID == "" {
-				return errors.New("empty sessionID"), ""
+				return errors.New("empty sessionID"), eduboard.Session{}
 			}
 			if sessionID == "invalid" {
-				return errors.New("not found"), ""
+				return errors.New("not found"), eduboard.Session{}
 			}
-			return nil, "1"
+			return nil, eduboard.Session{ID: "1", UserID: "1", ExpiresAt: time.Now().Add(time.Hour)}
 		},
 	}
 
@@ -69,6 +72,8 @@ func TestAppServer_NewAuthMiddleware(t *testing.T) {
 
 		var testHandler http.HandlerFunc = func(writer http.ResponseWriter, request *http.Request) {
 			assert.True(t, v.enter, "handler should not have been entered")
+			assert.Equal(t, "1", request.Header.Get("userID"), "userID was not passed on")
+			assert.Equal(t, "31", request.Header.Get("sessionID"), "sessionID was not passed on")
 			handlerEntered = true
 		}
 
@@ -85,6 +90,49 @@ func TestAppServer_NewAuthMiddleware(t *testing.T) {
 			assert.Equal(t, v.status, rr.Code, "status code does not match")
 			assert.True(t, as.CheckAuthenticationFnInvoked, "authentication was not actually checked")
 			assert.Equal(t, v.enter, handlerEntered, "handler was not called as expected")
+			if v.enter {
+				assert.NotEmpty(t, rr.HeaderMap["Set-Cookie"], "session cookie was not renewed")
+			}
+		})
+	}
+}
+
+func TestNewVerifiedMiddleware(t *testing.T) {
+	mockService := &mock.UserService{}
+	mockService.GetUserFn = func(id string) (error, eduboard.User) {
+		switch id {
+		case "verified":
+			return nil, eduboard.User{Verified: true}
+		case "unverified":
+			return nil, eduboard.User{}
+		}
+		return errors.New("not found"), eduboard.User{}
+	}
+
+	var testCases = []struct {
+		name   string
+		userID string
+		enter  bool
+		status int
+	}{
+		{"unknown user", "unknown", false, 403},
+		{"unverified", "unverified", false, 403},
+		{"verified", "verified", true, 200},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			handlerEntered := false
+			handler := NewVerifiedMiddleware(mockService)(func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+				handlerEntered = true
+			})
+
+			req := httptest.NewRequest("", "/", nil)
+			req.Header.Set("userID", v.userID)
+			rr := httptest.NewRecorder()
+			handler(rr, req, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.enter, handlerEntered, "handler was not called as expected")
 		})
 	}
 }
@@ -104,7 +152,7 @@ func TestCORS(t *testing.T) {
 	var expectedHeader = map[string]string{
 		"Access-Control-Allow-Origin":      "http://localhost:8080",
 		"Access-Control-Allow-Methods":     "POST, GET, OPTIONS, PUT, DELETE",
-		"Access-Control-Allow-Headers":     "Accept, Content-Type, Content-Length, Accept-Encoding",
+		"Access-Control-Allow-Headers":     "Accept, Content-Type, Content-Length, Accept-Encoding, Last-Event-ID",
 		"Access-Control-Allow-Credentials": "true",
 	}
 
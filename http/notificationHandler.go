@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+// GetNotificationsHandler lists the latest notifications of the user. The parameter unread=true leaves out
+// notifications that have been read, limit sets the number of notifications.
+func (a *AppServer) GetNotificationsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		limit, err := intParam(r, "limit")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		unread := false
+		if v := r.URL.Query().Get("unread"); v != "" {
+			if unread, err = strconv.ParseBool(v); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		err, notifications := a.NotificationService.GetNotifications(r.Header.Get("userID"), unread, limit)
+		if err != nil {
+			a.Logger.Printf("error getting notifications: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(notifications); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) GetUnreadNotificationsHandler() httprouter.Handle {
+	type response struct {
+		Count int `json:"count"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, count := a.NotificationService.CountUnread(r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error counting notifications: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(response{count}); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) ReadNotificationHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.NotificationService.MarkRead(p.ByName("notificationID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error marking notification as read: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *AppServer) ReadAllNotificationsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.NotificationService.MarkAllRead(r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error marking notifications as read: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestAppServer_GetNotificationsHandler(t *testing.T) {
+	service := mock.NotificationService{}
+	service.GetNotificationsFn = func(userID string, unread bool, limit int) (error, []eduboard.Notification) {
+		if limit > eduboard.MaxNotificationLimit {
+			return errors.Wrap(eduboard.ErrInvalidInput, "limit"), []eduboard.Notification{}
+		}
+		if userID != "1" {
+			return errors.New("error"), []eduboard.Notification{}
+		}
+		notifications := []eduboard.Notification{{ID: "1", Read: false}}
+		if !unread {
+			notifications = append(notifications, eduboard.Notification{ID: "2", Read: true})
+		}
+		return nil, notifications
+	}
+	a := AppServer{NotificationService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		user    string
+		query   string
+		status  int
+		invoked bool
+		length  int
+	}{
+		{"all", "1", "", 200, true, 2},
+		{"unread", "1", "?unread=true&limit=5", 200, true, 1},
+		{"invalid unread", "1", "?unread=maybe", 400, false, 0},
+		{"invalid limit", "1", "?limit=ten", 400, false, 0},
+		{"limit too large", "1", "?limit=1000", 400, true, 0},
+		{"error", "2", "", 500, true, 0},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.GetNotificationsFnInvoked = false
+			r := httptest.NewRequest("GET", "/"+v.query, nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.GetNotificationsHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			assert.Equal(t, v.invoked, service.GetNotificationsFnInvoked, "GetNotifications was not invoked as expected")
+			if v.status != 200 {
+				return
+			}
+			var notifications []map[string]interface{}
+			assert.Nil(t, json.NewDecoder(rr.Body).Decode(&notifications), "error decoding body")
+			assert.Len(t, notifications, v.length, "notifications do not match")
+		})
+	}
+}
+
+func TestAppServer_GetUnreadNotificationsHandler(t *testing.T) {
+	service := mock.NotificationService{}
+	service.CountUnreadFn = func(userID string) (error, int) {
+		if userID != "1" {
+			return errors.New("error"), 0
+		}
+		return nil, 3
+	}
+	a := AppServer{NotificationService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("userID", "1")
+	rr := httptest.NewRecorder()
+	a.GetUnreadNotificationsHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 200, rr.Code, "status code does not match")
+	assert.JSONEq(t, `{"count":3}`, rr.Body.String(), "body does not match")
+
+	r.Header.Set("userID", "2")
+	rr = httptest.NewRecorder()
+	a.GetUnreadNotificationsHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 500, rr.Code, "status code does not match")
+}
+
+func TestAppServer_ReadNotificationHandler(t *testing.T) {
+	service := mock.NotificationService{}
+	service.MarkReadFn = func(id string, userID string) error {
+		if id == "1" && userID == "1" {
+			return nil
+		}
+		return errors.New("not found")
+	}
+	a := AppServer{NotificationService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		id     string
+		user   string
+		status int
+	}{
+		{"success", "1", "1", 204},
+		{"other user", "1", "2", 404},
+		{"unknown", "2", "1", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", nil)
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.ReadNotificationHandler()(rr, r, httprouter.Params{{Key: "notificationID", Value: v.id}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
+
+func TestAppServer_ReadAllNotificationsHandler(t *testing.T) {
+	service := mock.NotificationService{}
+	service.MarkAllReadFn = func(userID string) error {
+		if userID != "1" {
+			return errors.New("error")
+		}
+		return nil
+	}
+	a := AppServer{NotificationService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("userID", "1")
+	rr := httptest.NewRecorder()
+	a.ReadAllNotificationsHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 204, rr.Code, "status code does not match")
+
+	r.Header.Set("userID", "2")
+	rr = httptest.NewRecorder()
+	a.ReadAllNotificationsHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 500, rr.Code, "status code does not match")
+}
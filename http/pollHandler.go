@@ -0,0 +1,115 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// PutPollHandler attaches a poll or quiz to an entry.
+func (a *AppServer) PutPollHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var poll eduboard.Poll
+		if err := json.NewDecoder(r.Body).Decode(&poll); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, poll := a.PollService.SetPoll(p.ByName("courseID"), p.ByName("entryID"), r.Header.Get("userID"), poll)
+		if err != nil {
+			a.Logger.Printf("error setting poll: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(poll); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) DeletePollHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.PollService.DeletePoll(p.ByName("courseID"), p.ByName("entryID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error deleting poll: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *AppServer) ClosePollHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, poll := a.PollService.ClosePoll(p.ByName("courseID"), p.ByName("entryID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error closing poll: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(poll); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) GetPollResponseHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, response := a.PollService.GetResponse(p.ByName("courseID"), p.ByName("entryID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting poll response: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(response); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// PostPollResponseHandler stores the options the current user chose for every question of a poll.
+func (a *AppServer) PostPollResponseHandler() httprouter.Handle {
+	type request struct {
+		Answers [][]int `json:"answers"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, response := a.PollService.Respond(p.ByName("courseID"), p.ByName("entryID"), r.Header.Get("userID"), req.Answers)
+		if err != nil {
+			a.Logger.Printf("error responding to poll: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(response); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) GetPollResultsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, results := a.PollService.GetResults(p.ByName("courseID"), p.ByName("entryID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting poll results: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(results); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
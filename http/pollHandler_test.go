@@ -0,0 +1,123 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAppServer_PutPollHandler(t *testing.T) {
+	service := mock.PollService{}
+	service.SetPollFn = func(courseID string, entryID string, userID string, poll eduboard.Poll) (error, eduboard.Poll) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Poll{}
+		}
+		if poll.Kind != eduboard.PollKindQuiz || len(poll.Questions) != 1 {
+			return errors.Wrap(eduboard.ErrInvalidInput, "invalid poll"), eduboard.Poll{}
+		}
+		return nil, poll
+	}
+	a := AppServer{PollService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	quiz := `{"kind": "quiz", "questions": [{"text": "1 + 1", "options": ["1", "2"], "correct": [1]}]}`
+	var testCases = []struct {
+		name   string
+		user   string
+		body   string
+		status int
+	}{
+		{"valid", "1", quiz, 200},
+		{"invalid", "1", `{"kind": "quiz", "questions": []}`, 400},
+		{"student", "2", quiz, 403},
+		{"malformed", "1", `{"kind":`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.PutPollHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "entryID", Value: "entry"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"correct":[1]`, "staff does not see the correct answers")
+			}
+		})
+	}
+}
+
+func TestAppServer_PostPollResponseHandler(t *testing.T) {
+	service := mock.PollService{}
+	service.RespondFn = func(courseID string, entryID string, userID string, answers [][]int) (error, eduboard.PollResponse) {
+		if userID == "2" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "already responded"), eduboard.PollResponse{}
+		}
+		return nil, eduboard.PollResponse{UserID: userID, Answers: answers}
+	}
+	a := AppServer{PollService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		user   string
+		body   string
+		status int
+	}{
+		{"valid", "1", `{"answers": [[0, 2]]}`, 201},
+		{"twice", "2", `{"answers": [[0]]}`, 400},
+		{"malformed", "1", `{"answers": [0]}`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.user)
+			rr := httptest.NewRecorder()
+
+			a.PostPollResponseHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "entryID", Value: "entry"}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"answers":[[0,2]]`, "answers are missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetPollResultsHandler(t *testing.T) {
+	service := mock.PollService{}
+	service.GetResultsFn = func(courseID string, entryID string, userID string) (error, eduboard.PollResults) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "not responded"), eduboard.PollResults{}
+		}
+		return nil, eduboard.PollResults{Kind: eduboard.PollKindPoll, Responses: 3, Questions: []eduboard.PollQuestionResult{{Counts: []int{1, 2}}}}
+	}
+	a := AppServer{PollService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"1": 200, "2": 403} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("userID", user)
+		rr := httptest.NewRecorder()
+
+		a.GetPollResultsHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "course"}, {Key: "entryID", Value: "entry"}})
+		assert.Equal(t, status, rr.Code, "status code does not match")
+		if status == 200 {
+			assert.Contains(t, rr.Body.String(), `"counts":[1,2]`, "counts are missing")
+		}
+	}
+}
+
+func TestEntryPoll(t *testing.T) {
+	assert.Nil(t, entryPoll(eduboard.CourseEntry{}), "entry without poll has a poll")
+
+	poll := eduboard.Poll{Kind: eduboard.PollKindQuiz, Questions: []eduboard.PollQuestion{{Text: "1 + 1", Options: []string{"1", "2"}, Correct: []int{1}}}}
+	public := entryPoll(eduboard.CourseEntry{Poll: &poll})
+	assert.Nil(t, public.Questions[0].Correct, "correct answers were not removed")
+	assert.Equal(t, []int{1}, poll.Questions[0].Correct, "correct answers of the entry were changed")
+}
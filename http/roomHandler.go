@@ -0,0 +1,133 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+func (a *AppServer) GetRoomsHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, rooms := a.RoomService.GetRooms()
+		if err != nil {
+			a.Logger.Printf("error getting rooms: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(rooms); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) PostRoomHandler() httprouter.Handle {
+	type request struct {
+		Name     string `json:"name"`
+		Building string `json:"building"`
+		Capacity int    `json:"capacity"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, room := a.RoomService.CreateRoom(&eduboard.Room{Name: req.Name, Building: req.Building, Capacity: req.Capacity}, r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error creating room: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(room); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) GetRoomHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, room := a.RoomService.GetRoom(p.ByName("roomID"))
+		if err != nil {
+			a.Logger.Printf("error getting room: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(room); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) PutRoomHandler() httprouter.Handle {
+	type request struct {
+		Name     *string `json:"name"`
+		Building *string `json:"building"`
+		Capacity *int    `json:"capacity"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		update := eduboard.RoomUpdate{
+			Name:     req.Name,
+			Building: req.Building,
+			Capacity: req.Capacity,
+		}
+		err, room := a.RoomService.UpdateRoom(p.ByName("roomID"), r.Header.Get("userID"), update)
+		if err != nil {
+			a.Logger.Printf("error updating room: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(room); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) DeleteRoomHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := a.RoomService.DeleteRoom(p.ByName("roomID"), r.Header.Get("userID")); err != nil {
+			a.Logger.Printf("error deleting room: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetRoomOccupancyHandler lists the meetings of all courses in a room, taking the same range parameters as GetOccurrencesHandler.
+func (a *AppServer) GetRoomOccupancyHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		from, to, err := occurrenceRange(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, occurrences := a.ScheduleService.GetRoomOccupancy(p.ByName("roomID"), from, to)
+		if err != nil {
+			a.Logger.Printf("error getting room occupancy: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(occurrences); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppServer_GetRoomsHandler(t *testing.T) {
+	service := mock.RoomService{GetRoomsFn: func() (error, []eduboard.Room) {
+		return nil, []eduboard.Room{{ID: "1", Name: "EN 154", Capacity: 80}}
+	}}
+	a := AppServer{RoomService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	rr := httptest.NewRecorder()
+	a.GetRoomsHandler()(rr, httptest.NewRequest("GET", "/", nil), httprouter.Params{})
+	assert.Equal(t, 200, rr.Code, "status code does not match")
+	assert.Contains(t, rr.Body.String(), `"name":"EN 154"`, "room missing")
+}
+
+func TestAppServer_PostRoomHandler(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		body    string
+		invoked bool
+		status  int
+	}{
+		{"success", `{"name": "MA 001", "building": "MA", "capacity": 120}`, true, 201},
+		{"bad json", `{"name":`, false, 400},
+		{"existing", `{"name": "EN 154"}`, true, 400},
+	}
+
+	service := mock.RoomService{CreateRoomFn: func(room *eduboard.Room, userID string) (error, eduboard.Room) {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "no staff"), eduboard.Room{}
+		}
+		if room.Name == "EN 154" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "room exists"), eduboard.Room{}
+		}
+		room.ID = "1"
+		return nil, *room
+	}}
+	a := AppServer{RoomService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.CreateRoomFnInvoked = false
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "1")
+			a.PostRoomHandler()(rr, r, httprouter.Params{})
+
+			assert.Equal(t, v.invoked, service.CreateRoomFnInvoked, "CreateRoom was not invoked as expected")
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"capacity":120`, "capacity missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_PostRoomHandler_Forbidden(t *testing.T) {
+	service := mock.RoomService{CreateRoomFn: func(room *eduboard.Room, userID string) (error, eduboard.Room) {
+		return errors.Wrap(eduboard.ErrForbidden, "no staff"), eduboard.Room{}
+	}}
+	a := AppServer{RoomService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "MA 001"}`))
+	r.Header.Set("userID", "2")
+	a.PostRoomHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 403, rr.Code, "status code does not match")
+}
+
+func TestAppServer_PutRoomHandler(t *testing.T) {
+	service := mock.RoomService{UpdateRoomFn: func(id string, userID string, update eduboard.RoomUpdate) (error, eduboard.Room) {
+		if id != "1" {
+			return errors.Wrap(eduboard.ErrNotFound, "unknown room"), eduboard.Room{}
+		}
+		if update.Name != nil && *update.Name == "MA 001" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "room is used"), eduboard.Room{}
+		}
+		return nil, eduboard.Room{ID: "1", Name: "EN 154", Capacity: *update.Capacity}
+	}}
+	a := AppServer{RoomService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{"success", "1", `{"capacity": 60}`, 200},
+		{"used", "1", `{"name": "MA 001"}`, 400},
+		{"unknown", "2", `{"capacity": 60}`, 404},
+		{"bad json", "1", `{"capacity":`, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", "1")
+			a.PutRoomHandler()(rr, r, httprouter.Params{{Key: "roomID", Value: v.id}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"capacity":60`, "capacity missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_DeleteRoomHandler(t *testing.T) {
+	service := mock.RoomService{DeleteRoomFn: func(id string, userID string) error {
+		if userID != "1" {
+			return errors.Wrap(eduboard.ErrForbidden, "no staff")
+		}
+		return nil
+	}}
+	a := AppServer{RoomService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"1": 204, "2": 403} {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", "/", nil)
+		r.Header.Set("userID", user)
+		a.DeleteRoomHandler()(rr, r, httprouter.Params{{Key: "roomID", Value: "1"}})
+		assert.Equal(t, status, rr.Code, "status code does not match")
+	}
+}
+
+func TestAppServer_GetRoomHandler(t *testing.T) {
+	service := mock.RoomService{GetRoomFn: func(id string) (error, eduboard.Room) {
+		if id == "1" {
+			return nil, eduboard.Room{ID: "1", Name: "EN 154"}
+		}
+		return errors.New("not found"), eduboard.Room{}
+	}}
+	a := AppServer{RoomService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	rr := httptest.NewRecorder()
+	a.GetRoomHandler()(rr, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "roomID", Value: "1"}})
+	assert.Equal(t, 200, rr.Code, "status code does not match")
+
+	rr = httptest.NewRecorder()
+	a.GetRoomHandler()(rr, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "roomID", Value: "2"}})
+	assert.Equal(t, 404, rr.Code, "status code does not match")
+}
+
+func TestAppServer_GetRoomOccupancyHandler(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		room   string
+		query  string
+		status int
+	}{
+		{"success", "1", "?from=2018-10-01T00:00:00Z&to=2018-10-08T00:00:00Z", 200},
+		{"bad range", "1", "?from=yesterday", 400},
+		{"invalid range", "1", "?from=2018-10-08T00:00:00Z&to=2018-10-01T00:00:00Z", 400},
+		{"unknown room", "2", "", 404},
+	}
+
+	service := mock.ScheduleService{}
+	service.GetRoomOccupancyFn = func(roomID string, from time.Time, to time.Time) (error, []eduboard.Occurrence) {
+		if roomID != "1" {
+			return errors.New("not found"), []eduboard.Occurrence{}
+		}
+		if !to.After(from) {
+			return errors.Wrap(eduboard.ErrInvalidInput, "invalid range"), []eduboard.Occurrence{}
+		}
+		return nil, []eduboard.Occurrence{{CourseID: "1", Date: "2018-10-01", Room: "EN 154"}}
+	}
+	a := AppServer{ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			a.GetRoomOccupancyHandler()(rr, httptest.NewRequest("GET", "/"+v.query, nil), httprouter.Params{{Key: "roomID", Value: v.room}})
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"date":"2018-10-01"`, "occurrence missing")
+			}
+		})
+	}
+}
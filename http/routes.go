@@ -4,27 +4,146 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
+// authenticatedRoutes returns the routes that need a session. Every request that changes data other users can see
+// additionally needs a verified email address. Reading and managing the own account under /me does not.
 func (a *AppServer) authenticatedRoutes() *httprouter.Router {
 	router := httprouter.New()
+	verified := NewVerifiedMiddleware(a.UserService)
 
 	// User
 	router.GET("/api/v1/users", a.GetAllUsersHandler())
 	router.GET("/api/v1/users/:id", a.GetUserHandler())
 	router.GET("/api/v1/users/:id/courses", a.GetMyCoursesHandler())
 	router.GET("/api/v1/me", a.GetMeHandler())
+	router.GET("/api/v1/feed", a.GetFeedHandler())
+	router.GET("/api/v1/events", a.GetEventsHandler())
+	router.GET("/api/v1/me/sessions", a.GetSessionsHandler())
+	router.DELETE("/api/v1/me/sessions/:sessionID", a.RevokeSessionHandler())
+	router.POST("/api/v1/me/verification", a.RequestVerificationHandler())
+	router.PUT("/api/v1/me/picture", verified(a.PutProfilePictureHandler()))
+	router.GET("/api/v1/me/timetable", a.GetTimetableHandler())
+	router.GET("/api/v1/me/calendar", a.GetMyCalendarHandler())
+	router.POST("/api/v1/me/calendar/token", a.PostCalendarTokenHandler())
+	router.DELETE("/api/v1/me/calendar/token", a.DeleteCalendarTokenHandler())
+	router.GET("/api/v1/me/notifications", a.GetNotificationsHandler())
+	router.GET("/api/v1/me/notifications/unread", a.GetUnreadNotificationsHandler())
+	router.POST("/api/v1/me/notifications/read", a.ReadAllNotificationsHandler())
+	router.POST("/api/v1/notifications/:notificationID/read", a.ReadNotificationHandler())
 
 	// Courses
 	router.GET("/api/v1/courses/:courseID", a.GetCourseHandler())
+	router.PUT("/api/v1/courses/:courseID", verified(a.UpdateCourseHandler()))
+	router.DELETE("/api/v1/courses/:courseID", verified(a.DeleteCourseHandler()))
+	router.POST("/api/v1/courses/:courseID/archive", verified(a.ArchiveCourseHandler()))
+	router.POST("/api/v1/courses/:courseID/restore", verified(a.RestoreCourseHandler()))
 	router.GET("/api/v1/courses/:courseID/users", a.GetMembersHandler())
-	router.POST("/api/v1/courses/:courseID/users/subscribe", a.AddMembersHandler())
-	router.POST("/api/v1/courses/:courseID/users/unsubscribe", a.RemoveMembersHandler())
+	router.POST("/api/v1/courses/:courseID/users/subscribe", verified(a.AddMembersHandler()))
+	router.POST("/api/v1/courses/:courseID/users/unsubscribe", verified(a.RemoveMembersHandler()))
 	router.GET("/api/v1/courses", a.GetAllCoursesHandler())
 
+	// Invites
+	router.GET("/api/v1/courses/:courseID/invites", a.GetInviteCodesHandler())
+	router.POST("/api/v1/courses/:courseID/invites", verified(a.PostInviteCodeHandler()))
+	router.DELETE("/api/v1/courses/:courseID/invites/:inviteID", verified(a.DeleteInviteCodeHandler()))
+	router.GET("/api/v1/courses/:courseID/email-invites", a.GetEmailInvitesHandler())
+	router.POST("/api/v1/courses/:courseID/email-invites", verified(a.PostEmailInviteHandler()))
+	router.POST("/api/v1/join/:code", verified(a.JoinCourseHandler()))
+
+	// Enrollment
+	router.GET("/api/v1/courses/:courseID/enrollment", a.GetEnrollmentHandler())
+	router.POST("/api/v1/courses/:courseID/enrollment", verified(a.PostEnrollmentHandler()))
+	router.DELETE("/api/v1/courses/:courseID/enrollment", verified(a.DeleteEnrollmentHandler()))
+	router.GET("/api/v1/courses/:courseID/enrollment-requests", a.GetEnrollmentRequestsHandler())
+	router.POST("/api/v1/courses/:courseID/enrollment-requests/:requestID/approve", verified(a.ApproveEnrollmentHandler()))
+	router.POST("/api/v1/courses/:courseID/enrollment-requests/:requestID/reject", verified(a.RejectEnrollmentHandler()))
+
+	// Assignments
+	router.GET("/api/v1/courses/:courseID/assignments", a.GetAssignmentsHandler())
+	router.POST("/api/v1/courses/:courseID/assignments", verified(a.PostAssignmentHandler()))
+	router.GET("/api/v1/courses/:courseID/assignments/:assignmentID", a.GetAssignmentHandler())
+	router.PUT("/api/v1/courses/:courseID/assignments/:assignmentID", verified(a.UpdateAssignmentHandler()))
+	router.DELETE("/api/v1/courses/:courseID/assignments/:assignmentID", verified(a.DeleteAssignmentHandler()))
+	router.GET("/api/v1/courses/:courseID/assignments/:assignmentID/submission", a.GetSubmissionHandler())
+	router.PUT("/api/v1/courses/:courseID/assignments/:assignmentID/submission", verified(a.PutSubmissionHandler()))
+	router.GET("/api/v1/courses/:courseID/assignments/:assignmentID/submissions", a.GetSubmissionsHandler())
+
+	// Gradebook
+	router.GET("/api/v1/courses/:courseID/gradebook", a.GetGradebookHandler())
+	router.GET("/api/v1/courses/:courseID/gradebook.csv", a.ExportGradebookHandler())
+	router.GET("/api/v1/courses/:courseID/grade-categories", a.GetGradeCategoriesHandler())
+	router.PUT("/api/v1/courses/:courseID/grade-categories", verified(a.PutGradeCategoriesHandler()))
+	router.PUT("/api/v1/courses/:courseID/assignments/:assignmentID/grades/:userID", verified(a.PutGradeHandler()))
+	router.DELETE("/api/v1/courses/:courseID/assignments/:assignmentID/grades/:userID", verified(a.DeleteGradeHandler()))
+
+	// Attendance
+	router.GET("/api/v1/courses/:courseID/attendance", a.GetAttendanceSessionsHandler())
+	router.POST("/api/v1/courses/:courseID/attendance", verified(a.PostAttendanceSessionHandler()))
+	router.GET("/api/v1/courses/:courseID/attendance/:sessionID", a.GetAttendanceSessionHandler())
+	router.DELETE("/api/v1/courses/:courseID/attendance/:sessionID", verified(a.DeleteAttendanceSessionHandler()))
+	router.POST("/api/v1/courses/:courseID/attendance/:sessionID/code", verified(a.OpenCheckInHandler()))
+	router.DELETE("/api/v1/courses/:courseID/attendance/:sessionID/code", verified(a.CloseCheckInHandler()))
+	router.PUT("/api/v1/courses/:courseID/attendance/:sessionID/records", verified(a.MarkAttendanceHandler()))
+	router.POST("/api/v1/courses/:courseID/check-in", verified(a.CheckInHandler()))
+	router.GET("/api/v1/courses/:courseID/attendance-report", a.GetAttendanceReportHandler())
+	router.GET("/api/v1/courses/:courseID/attendance-report/:userID", a.GetStudentAttendanceReportHandler())
+
+	// Schedules
+	router.GET("/api/v1/courses/:courseID/schedules", a.GetSchedulesHandler())
+	router.POST("/api/v1/courses/:courseID/schedules", verified(a.PostScheduleHandler()))
+	router.PUT("/api/v1/courses/:courseID/schedules/:scheduleID", verified(a.PutScheduleHandler()))
+	router.DELETE("/api/v1/courses/:courseID/schedules/:scheduleID", verified(a.DeleteScheduleHandler()))
+	router.PUT("/api/v1/courses/:courseID/schedules/:scheduleID/exceptions/:date", verified(a.PutScheduleExceptionHandler()))
+	router.DELETE("/api/v1/courses/:courseID/schedules/:scheduleID/exceptions/:date", verified(a.DeleteScheduleExceptionHandler()))
+	router.GET("/api/v1/courses/:courseID/occurrences", a.GetOccurrencesHandler())
+	router.GET("/api/v1/courses/:courseID/calendar", a.GetCourseCalendarHandler())
+
+	// Rooms
+	router.GET("/api/v1/rooms", a.GetRoomsHandler())
+	router.POST("/api/v1/rooms", verified(a.PostRoomHandler()))
+	router.GET("/api/v1/rooms/:roomID", a.GetRoomHandler())
+	router.PUT("/api/v1/rooms/:roomID", verified(a.PutRoomHandler()))
+	router.DELETE("/api/v1/rooms/:roomID", verified(a.DeleteRoomHandler()))
+	router.GET("/api/v1/rooms/:roomID/occupancy", a.GetRoomOccupancyHandler())
+
 	// CourseEntries
-	router.POST("/api/v1/courses", a.CreateCourseHandler())
-	router.POST("/api/v1/courses/:courseID/entries", a.PostCourseEntryHandler())
-	router.PUT("/api/v1/courses/:courseID/entries/:entryID", a.PutCourseEntryHandler())
-	router.DELETE("/api/v1/courses/:courseID/entries/:entryID", a.DeleteCourseEntryHandler())
+	router.POST("/api/v1/courses", verified(a.CreateCourseHandler()))
+	router.GET("/api/v1/courses/:courseID/entries", a.GetCourseEntriesHandler())
+	router.POST("/api/v1/courses/:courseID/entries", verified(a.PostCourseEntryHandler()))
+	router.PUT("/api/v1/courses/:courseID/entries/:entryID", verified(a.PutCourseEntryHandler()))
+	router.DELETE("/api/v1/courses/:courseID/entries/:entryID", verified(a.DeleteCourseEntryHandler()))
+
+	// Comments
+	router.GET("/api/v1/courses/:courseID/entries/:entryID/comments", a.GetCommentsHandler())
+	router.POST("/api/v1/courses/:courseID/entries/:entryID/comments", verified(a.PostCommentHandler()))
+	router.PUT("/api/v1/courses/:courseID/entries/:entryID/comments/:commentID", verified(a.PutCommentHandler()))
+	router.DELETE("/api/v1/courses/:courseID/entries/:entryID/comments/:commentID", verified(a.DeleteCommentHandler()))
+
+	// Polls
+	router.PUT("/api/v1/courses/:courseID/entries/:entryID/poll", verified(a.PutPollHandler()))
+	router.DELETE("/api/v1/courses/:courseID/entries/:entryID/poll", verified(a.DeletePollHandler()))
+	router.POST("/api/v1/courses/:courseID/entries/:entryID/poll/close", verified(a.ClosePollHandler()))
+	router.GET("/api/v1/courses/:courseID/entries/:entryID/poll/response", a.GetPollResponseHandler())
+	router.POST("/api/v1/courses/:courseID/entries/:entryID/poll/response", verified(a.PostPollResponseHandler()))
+	router.GET("/api/v1/courses/:courseID/entries/:entryID/poll/results", a.GetPollResultsHandler())
+
+	// Materials
+	router.GET("/api/v1/courses/:courseID/materials", a.GetMaterialsHandler())
+	router.POST("/api/v1/courses/:courseID/materials", verified(a.PostMaterialHandler()))
+	router.GET("/api/v1/courses/:courseID/materials.zip", a.GetMaterialsArchiveHandler())
+	router.GET("/api/v1/courses/:courseID/materials/:materialID", a.GetMaterialHandler())
+	router.PUT("/api/v1/courses/:courseID/materials/:materialID", verified(a.UpdateMaterialHandler()))
+	router.DELETE("/api/v1/courses/:courseID/materials/:materialID", verified(a.DeleteMaterialHandler()))
+	router.POST("/api/v1/courses/:courseID/materials/:materialID/versions", verified(a.PostMaterialVersionHandler()))
+	router.GET("/api/v1/courses/:courseID/materials/:materialID/download", a.GetMaterialDownloadHandler())
+	router.POST("/api/v1/courses/:courseID/material-folders", verified(a.PostMaterialFolderHandler()))
+	router.PUT("/api/v1/courses/:courseID/material-folders/:folderID", verified(a.UpdateMaterialFolderHandler()))
+	router.DELETE("/api/v1/courses/:courseID/material-folders/:folderID", verified(a.DeleteMaterialFolderHandler()))
+	router.GET("/api/v1/courses/:courseID/material-folders/:folderID/zip", a.GetMaterialFolderArchiveHandler())
+
+	// Uploads
+	router.POST("/api/v1/uploads", verified(a.PostUploadHandler()))
+	router.GET("/api/v1/uploads/:uploadID", a.GetUploadHandler())
+	router.GET("/api/v1/uploads/:uploadID/thumbnail", a.GetUploadThumbnailHandler())
 
 	return router
 }
@@ -36,5 +155,16 @@ func (a *AppServer) publicRoutes() *httprouter.Router {
 	router.POST("/api/register", a.RegisterUserHandler())
 	router.POST("/api/login", a.LoginUserHandler())
 	router.POST("/api/logout", a.LogoutUserHandler())
+
+	// Password reset
+	router.POST("/api/password/forgot", a.ForgotPasswordHandler())
+	router.POST("/api/password/reset", a.ResetPasswordHandler())
+
+	// Email verification
+	router.POST("/api/verify", a.VerifyEmailHandler())
+
+	// Calendar feeds
+	router.GET("/api/calendar/:token", a.GetTokenCalendarHandler())
+	router.GET("/api/calendar/:token/courses/:courseID", a.GetTokenCourseCalendarHandler())
 	return router
 }
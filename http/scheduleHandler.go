@@ -0,0 +1,214 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+// DefaultOccurrenceRange is the range occurrences are expanded for if the request does not set an end.
+const DefaultOccurrenceRange = 7 * 24 * time.Hour
+
+func (a *AppServer) GetSchedulesHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, schedules := a.ScheduleService.GetSchedules(p.ByName("courseID"))
+		if err != nil {
+			a.Logger.Printf("error getting schedules: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(schedules); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) PostScheduleHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var request eduboard.Schedule
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, schedule := a.ScheduleService.AddSchedule(p.ByName("courseID"), r.Header.Get("userID"), request)
+		if err != nil {
+			a.Logger.Printf("error adding schedule: %v", err)
+			writeError(w, err, http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(schedule); err != nil {
+			a.Logger.Printf("error encoding response: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) PutScheduleHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var request eduboard.Schedule
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, schedule := a.ScheduleService.UpdateSchedule(p.ByName("courseID"), p.ByName("scheduleID"), r.Header.Get("userID"), request)
+		a.writeSchedule(w, err, schedule)
+	}
+}
+
+func (a *AppServer) DeleteScheduleHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err := a.ScheduleService.DeleteSchedule(p.ByName("courseID"), p.ByName("scheduleID"), r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error deleting schedule: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *AppServer) PutScheduleExceptionHandler() httprouter.Handle {
+	type request struct {
+		Cancelled bool          `json:"cancelled"`
+		Start     *time.Time    `json:"startsAt"`
+		Duration  time.Duration `json:"duration"`
+		Room      string        `json:"room"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		date := p.ByName("date")
+		if _, err := time.Parse(eduboard.DateFormat, date); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var request request
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			a.Logger.Printf("error decoding request body: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		exception := eduboard.ScheduleException{
+			Date:      date,
+			Cancelled: request.Cancelled,
+			Start:     request.Start,
+			Duration:  request.Duration,
+			Room:      request.Room,
+		}
+		err, schedule := a.ScheduleService.SetException(p.ByName("courseID"), p.ByName("scheduleID"), r.Header.Get("userID"), exception)
+		a.writeSchedule(w, err, schedule)
+	}
+}
+
+func (a *AppServer) DeleteScheduleExceptionHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, schedule := a.ScheduleService.DeleteException(p.ByName("courseID"), p.ByName("scheduleID"), r.Header.Get("userID"), p.ByName("date"))
+		a.writeSchedule(w, err, schedule)
+	}
+}
+
+func (a *AppServer) GetOccurrencesHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		from, to, err := occurrenceRange(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, occurrences := a.ScheduleService.GetOccurrences(p.ByName("courseID"), from, to)
+		if err != nil {
+			a.Logger.Printf("error getting occurrences: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(occurrences); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetTimetableHandler returns the meetings of all courses of the user. Times are given in the time zone of the
+// tz parameter if set, otherwise in the time zone of their schedule.
+func (a *AppServer) GetTimetableHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		from, to, err := occurrenceRange(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var loc *time.Location
+		if tz := r.URL.Query().Get("tz"); tz != "" {
+			if loc, err = time.LoadLocation(tz); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		userID := r.Header.Get("userID")
+		err, courses := a.UserService.GetMyCourses(userID, userID, a.CourseRepository, a.CourseEntryRepository)
+		if err != nil {
+			a.Logger.Printf("error getting courses: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		err, timetable := a.ScheduleService.GetTimetable(courses, from, to)
+		if err != nil {
+			a.Logger.Printf("error getting timetable: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		if loc != nil {
+			for k := range timetable {
+				timetable[k].Start = timetable[k].Start.In(loc)
+				timetable[k].End = timetable[k].End.In(loc)
+			}
+		}
+
+		if err = json.NewEncoder(w).Encode(timetable); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) writeSchedule(w http.ResponseWriter, err error, schedule eduboard.Schedule) {
+	if err != nil {
+		a.Logger.Printf("error updating schedule: %v", err)
+		writeError(w, err, http.StatusNotFound)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(schedule); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// occurrenceRange reads the RFC 3339 query parameters from and to. from defaults to now and to to DefaultOccurrenceRange after from.
+func occurrenceRange(r *http.Request) (time.Time, time.Time, error) {
+	var err error
+	from, to := time.Now(), time.Time{}
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	to = from.Add(DefaultOccurrenceRange)
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	return from, to, nil
+}
@@ -0,0 +1,299 @@
+package http
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppServer_GetSchedulesHandler(t *testing.T) {
+	service := mock.ScheduleService{}
+	service.GetSchedulesFn = func(courseID string) (error, []eduboard.Schedule) {
+		if courseID == "1" {
+			return nil, []eduboard.Schedule{{ID: "1", Day: time.Monday, Room: "EN 154"}}
+		}
+		return errors.New("not found"), []eduboard.Schedule{}
+	}
+	a := AppServer{ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	rr := httptest.NewRecorder()
+	a.GetSchedulesHandler()(rr, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "courseID", Value: "1"}})
+	assert.Equal(t, 200, rr.Code, "status code does not match")
+	assert.Contains(t, rr.Body.String(), `"room":"EN 154"`, "room missing")
+
+	rr = httptest.NewRecorder()
+	a.GetSchedulesHandler()(rr, httptest.NewRequest("GET", "/", nil), httprouter.Params{{Key: "courseID", Value: "2"}})
+	assert.Equal(t, 404, rr.Code, "status code does not match")
+}
+
+func TestAppServer_PostScheduleHandler(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		course  string
+		body    string
+		invoked bool
+		status  int
+	}{
+		{"success", "1", `{"day": 1, "startsAt": "2018-10-01T10:00:00Z", "duration": 3600000000000, "interval": 2, "termEnd": "2019-02-01"}`, true, 201},
+		{"bad json", "1", `{"day":`, false, 400},
+		{"invalid", "2", `{"day": 9}`, true, 400},
+		{"forbidden", "3", `{"day": 1}`, true, 403},
+		{"archived", "4", `{"day": 1}`, true, 409},
+		{"room conflict", "5", `{"day": 1, "room": "EN 154"}`, true, 409},
+	}
+
+	service := mock.ScheduleService{}
+	service.AddScheduleFn = func(courseID string, userID string, schedule eduboard.Schedule) (error, eduboard.Schedule) {
+		switch courseID {
+		case "2":
+			return errors.Wrap(eduboard.ErrInvalidInput, "bad day"), eduboard.Schedule{}
+		case "3":
+			return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Schedule{}
+		case "4":
+			return errors.Wrap(eduboard.ErrArchived, "archived"), eduboard.Schedule{}
+		case "5":
+			return &eduboard.RoomConflictError{Conflicts: []eduboard.RoomConflict{{Room: "EN 154", CourseID: "1", CourseTitle: "Analysis"}}}, eduboard.Schedule{}
+		}
+		schedule.ID = "1"
+		return nil, schedule
+	}
+	a := AppServer{ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.AddScheduleFnInvoked = false
+			rr := httptest.NewRecorder()
+			a.PostScheduleHandler()(rr, httptest.NewRequest("POST", "/", strings.NewReader(v.body)), httprouter.Params{{Key: "courseID", Value: v.course}})
+
+			assert.Equal(t, v.invoked, service.AddScheduleFnInvoked, "AddSchedule was not invoked as expected")
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"interval":2`, "interval missing")
+				assert.Contains(t, rr.Body.String(), `"termEnd":"2019-02-01"`, "term end missing")
+			}
+			if v.name == "room conflict" {
+				assert.Contains(t, rr.Body.String(), `"courseTitle":"Analysis"`, "clashing course missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_PutScheduleHandler(t *testing.T) {
+	service := mock.ScheduleService{}
+	service.UpdateScheduleFn = func(courseID string, scheduleID string, userID string, schedule eduboard.Schedule) (error, eduboard.Schedule) {
+		if scheduleID != "1" {
+			return errors.New("no such schedule"), eduboard.Schedule{}
+		}
+		schedule.ID = "1"
+		return nil, schedule
+	}
+	a := AppServer{ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name     string
+		schedule string
+		body     string
+		status   int
+	}{
+		{"success", "1", `{"day": 2, "room": "EN 154"}`, 200},
+		{"bad json", "1", `{`, 400},
+		{"not found", "2", `{"day": 2}`, 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			p := httprouter.Params{{Key: "courseID", Value: "1"}, {Key: "scheduleID", Value: v.schedule}}
+			a.PutScheduleHandler()(rr, httptest.NewRequest("PUT", "/", strings.NewReader(v.body)), p)
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+		})
+	}
+}
+
+func TestAppServer_DeleteScheduleHandler(t *testing.T) {
+	service := mock.ScheduleService{}
+	service.DeleteScheduleFn = func(courseID string, scheduleID string, userID string) error {
+		if userID != "teacher" {
+			return errors.Wrap(eduboard.ErrForbidden, "not staff")
+		}
+		return nil
+	}
+	a := AppServer{ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for user, status := range map[string]int{"teacher": 204, "student": 403} {
+		r := httptest.NewRequest("DELETE", "/", nil)
+		r.Header.Set("userID", user)
+		rr := httptest.NewRecorder()
+		a.DeleteScheduleHandler()(rr, r, httprouter.Params{{Key: "courseID", Value: "1"}, {Key: "scheduleID", Value: "1"}})
+		assert.Equal(t, status, rr.Code, "status code does not match")
+	}
+}
+
+func TestAppServer_PutScheduleExceptionHandler(t *testing.T) {
+	var exception eduboard.ScheduleException
+	service := mock.ScheduleService{}
+	service.SetExceptionFn = func(courseID string, scheduleID string, userID string, e eduboard.ScheduleException) (error, eduboard.Schedule) {
+		exception = e
+		if e.Date == "2018-10-09" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "no meeting"), eduboard.Schedule{}
+		}
+		return nil, eduboard.Schedule{ID: "1", Exceptions: []eduboard.ScheduleException{e}}
+	}
+	a := AppServer{ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		date    string
+		body    string
+		invoked bool
+		status  int
+	}{
+		{"cancel", "2018-10-08", `{"cancelled": true}`, true, 200},
+		{"move", "2018-10-08", `{"startsAt": "2018-10-10T12:00:00Z", "room": "MA 001"}`, true, 200},
+		{"bad date", "08.10.2018", `{"cancelled": true}`, false, 400},
+		{"bad json", "2018-10-08", `{`, false, 400},
+		{"no meeting", "2018-10-09", `{"cancelled": true}`, true, 400},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.SetExceptionFnInvoked = false
+			exception = eduboard.ScheduleException{}
+			rr := httptest.NewRecorder()
+			p := httprouter.Params{{Key: "courseID", Value: "1"}, {Key: "scheduleID", Value: "1"}, {Key: "date", Value: v.date}}
+			a.PutScheduleExceptionHandler()(rr, httptest.NewRequest("PUT", "/", strings.NewReader(v.body)), p)
+
+			assert.Equal(t, v.invoked, service.SetExceptionFnInvoked, "SetException was not invoked as expected")
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.invoked {
+				assert.Equal(t, v.date, exception.Date, "date does not match")
+			}
+			if v.name == "move" {
+				assert.Equal(t, "MA 001", exception.Room, "room does not match")
+				assert.NotNil(t, exception.Start, "start missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_DeleteScheduleExceptionHandler(t *testing.T) {
+	service := mock.ScheduleService{}
+	service.DeleteExceptionFn = func(courseID string, scheduleID string, userID string, date string) (error, eduboard.Schedule) {
+		if date != "2018-10-08" {
+			return errors.New("no exception"), eduboard.Schedule{}
+		}
+		return nil, eduboard.Schedule{ID: "1"}
+	}
+	a := AppServer{ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for date, status := range map[string]int{"2018-10-08": 200, "2018-10-15": 404} {
+		rr := httptest.NewRecorder()
+		p := httprouter.Params{{Key: "courseID", Value: "1"}, {Key: "scheduleID", Value: "1"}, {Key: "date", Value: date}}
+		a.DeleteScheduleExceptionHandler()(rr, httptest.NewRequest("DELETE", "/", nil), p)
+		assert.Equal(t, status, rr.Code, "status code does not match")
+	}
+}
+
+func TestAppServer_GetOccurrencesHandler(t *testing.T) {
+	var from, to time.Time
+	service := mock.ScheduleService{}
+	service.GetOccurrencesFn = func(courseID string, f time.Time, t time.Time) (error, []eduboard.Occurrence) {
+		from, to = f, t
+		if courseID == "2" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "range too long"), []eduboard.Occurrence{}
+		}
+		return nil, []eduboard.Occurrence{{Date: "2018-10-08", Cancelled: true}}
+	}
+	a := AppServer{ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		course  string
+		url     string
+		invoked bool
+		status  int
+		days    int
+	}{
+		{"range", "1", "/?from=2018-10-01T00:00:00Z&to=2018-10-15T00:00:00Z", true, 200, 14},
+		{"default range", "1", "/?from=2018-10-01T00:00:00Z", true, 200, 7},
+		{"bad from", "1", "/?from=yesterday", false, 400, 0},
+		{"bad to", "1", "/?to=tomorrow", false, 400, 0},
+		{"invalid range", "2", "/", true, 400, 0},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.GetOccurrencesFnInvoked = false
+			rr := httptest.NewRecorder()
+			a.GetOccurrencesHandler()(rr, httptest.NewRequest("GET", v.url, nil), httprouter.Params{{Key: "courseID", Value: v.course}})
+
+			assert.Equal(t, v.invoked, service.GetOccurrencesFnInvoked, "GetOccurrences was not invoked as expected")
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Equal(t, time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC), from.UTC(), "from does not match")
+				assert.Equal(t, time.Duration(v.days)*24*time.Hour, to.Sub(from), "range does not match")
+				assert.Contains(t, rr.Body.String(), `"cancelled":true`, "cancelled missing")
+			}
+		})
+	}
+}
+
+func TestAppServer_GetTimetableHandler(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		query  string
+		status int
+		start  string
+	}{
+		{"success", "?from=2018-10-01T00:00:00Z&to=2018-10-08T00:00:00Z", 200, `"startsAt":"2018-10-01T10:00:00Z"`},
+		{"time zone", "?from=2018-10-01T00:00:00Z&tz=Europe/Berlin", 200, `"startsAt":"2018-10-01T12:00:00+02:00"`},
+		{"unknown time zone", "?tz=Mars/Olympus_Mons", 400, ""},
+		{"bad range", "?to=tomorrow", 400, ""},
+		{"invalid range", "?from=2018-10-08T00:00:00Z&to=2018-10-01T00:00:00Z", 400, ""},
+	}
+
+	users := mock.UserService{}
+	users.GetMyCoursesFn = func(id string, viewerID string, cBMF eduboard.CourseManyFinder, cEMF eduboard.CourseEntryManyFinder) (error, []eduboard.Course) {
+		return nil, []eduboard.Course{{ID: "1", Title: "Algebra"}}
+	}
+	service := mock.ScheduleService{}
+	service.GetTimetableFn = func(courses []eduboard.Course, from time.Time, to time.Time) (error, []eduboard.TimetableEntry) {
+		if !to.After(from) {
+			return errors.Wrap(eduboard.ErrInvalidInput, "invalid range"), []eduboard.TimetableEntry{}
+		}
+		start := time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC)
+		o := eduboard.Occurrence{CourseID: courses[0].ID, Date: "2018-10-01", Start: start, End: start.Add(time.Hour)}
+		return nil, []eduboard.TimetableEntry{{Occurrence: o, CourseTitle: courses[0].Title, Overlapping: true}}
+	}
+	a := AppServer{UserService: &users, ScheduleService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			if v.name == "time zone" {
+				if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+					t.Skip("time zone database not available")
+				}
+			}
+
+			r := httptest.NewRequest("GET", "/"+v.query, nil)
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+			a.GetTimetableHandler()(rr, r, httprouter.Params{})
+
+			assert.Equal(t, v.status, rr.Code, "status code does not match")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), v.start, "start does not match")
+				assert.Contains(t, rr.Body.String(), `"courseTitle":"Algebra"`, "course title missing")
+				assert.Contains(t, rr.Body.String(), `"overlapping":true`, "overlap flag missing")
+			}
+		})
+	}
+}
@@ -1,23 +1,42 @@
 package http
 
 import (
+	"context"
 	"github.com/eduboard/backend"
 	"log"
 	"net/http"
 	"time"
 )
 
+// writeTimeout is the time after which the server gives up writing a response.
+const writeTimeout = 30 * time.Second
+
 type AppServer struct {
-	Host                  string
-	Static                string
-	Logger                *log.Logger
-	UserService           eduboard.UserService
-	UserRepository        eduboard.UserRepository
-	CourseService         eduboard.CourseService
-	CourseEntryService    eduboard.CourseEntryService
-	CourseRepository      eduboard.CourseRepository
-	CourseEntryRepository eduboard.CourseEntryRepository
-	httpServer            *http.Server
+	Host                   string
+	Static                 string
+	Logger                 *log.Logger
+	UserService            eduboard.UserService
+	UserRepository         eduboard.UserRepository
+	CourseService          eduboard.CourseService
+	CourseEntryService     eduboard.CourseEntryService
+	ScheduleService        eduboard.ScheduleService
+	CourseRepository       eduboard.CourseRepository
+	CourseEntryRepository  eduboard.CourseEntryRepository
+	CommentRepository      eduboard.CommentRepository
+	PollResponseRepository eduboard.PollResponseRepository
+	UploadService          eduboard.UploadService
+	RoomService            eduboard.RoomService
+	NotificationService    eduboard.NotificationService
+	CommentService         eduboard.CommentService
+	InviteService          eduboard.InviteService
+	EnrollmentService      eduboard.EnrollmentService
+	AssignmentService      eduboard.AssignmentService
+	GradebookService       eduboard.GradebookService
+	AttendanceService      eduboard.AttendanceService
+	PollService            eduboard.PollService
+	MaterialService        eduboard.MaterialService
+	Events                 *EventHub
+	httpServer             *http.Server
 }
 
 func (a *AppServer) initialize() {
@@ -35,14 +54,23 @@ func (a *AppServer) initialize() {
 
 	a.httpServer = &http.Server{
 		Addr:           a.Host,
-		ReadTimeout:    1 * time.Second,
-		WriteTimeout:   1 * time.Second,
+		ReadTimeout:    30 * time.Second,
+		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: 1 << 20,
 		Handler:        mux,
 	}
 }
 
-func (a *AppServer) Run() {
+// Run serves requests until the server fails or Shutdown is called, in which case it returns nil.
+func (a *AppServer) Run() error {
 	a.initialize()
-	a.Logger.Fatal(a.httpServer.ListenAndServe())
+	if err := a.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for active requests to finish until ctx is done.
+func (a *AppServer) Shutdown(ctx context.Context) error {
+	return a.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/eduboard/backend"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxFormOverhead is the space granted to multipart headers and form fields besides the file.
+const maxFormOverhead = 1 << 20
+
+func (a *AppServer) PostUploadHandler() httprouter.Handle {
+	type response struct {
+		ID           string    `json:"id"`
+		URL          string    `json:"url"`
+		ThumbnailURL string    `json:"thumbnailUrl,omitempty"`
+		Filename     string    `json:"filename"`
+		ContentType  string    `json:"contentType"`
+		Size         int64     `json:"size"`
+		CreatedAt    time.Time `json:"createdAt"`
+		Private      bool      `json:"private"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		r.Body = http.MaxBytesReader(w, r.Body, eduboard.MaxUploadSize+maxFormOverhead)
+		if err := r.ParseMultipartForm(maxFormOverhead); err != nil {
+			a.Logger.Printf("error parsing upload: %v", err)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		courseID := r.FormValue("courseID")
+		if courseID != "" && !bson.IsObjectIdHex(courseID) {
+			a.Logger.Printf("courseID %s is not a valid objectID", courseID)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		uploadModel := eduboard.Upload{
+			OwnerID:  r.Header.Get("userID"),
+			CourseID: courseID,
+			Filename: filepath.Base(header.Filename),
+		}
+		err, upload := a.UploadService.StoreUpload(&uploadModel, file, a.CourseRepository)
+		if err != nil {
+			a.Logger.Printf("error storing upload: %v", err)
+			w.WriteHeader(uploadErrorStatus(err))
+			return
+		}
+
+		res := response{
+			ID:           upload.ID.Hex(),
+			URL:          upload.URL(),
+			ThumbnailURL: upload.ThumbnailURL(),
+			Filename:     upload.Filename,
+			ContentType:  upload.ContentType,
+			Size:         upload.Size,
+			CreatedAt:    upload.CreatedAt,
+			Private:      upload.Private,
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(res); err != nil {
+			a.Logger.Printf("error encoding upload: %v", err)
+		}
+	}
+}
+
+func (a *AppServer) GetUploadHandler() httprouter.Handle {
+	return a.serveUpload(false)
+}
+
+func (a *AppServer) GetUploadThumbnailHandler() httprouter.Handle {
+	return a.serveUpload(true)
+}
+
+func (a *AppServer) serveUpload(thumbnail bool) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		id := p.ByName("uploadID")
+		if !bson.IsObjectIdHex(id) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, upload, content := a.UploadService.OpenUpload(id, r.Header.Get("userID"), thumbnail, a.CourseRepository)
+		if err != nil {
+			a.Logger.Printf("error opening upload: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusNotFound))
+			return
+		}
+		defer content.Close()
+
+		// Only images are shown inline, everything else is downloaded to keep browsers from rendering it on our origin.
+		disposition := "attachment"
+		if strings.HasPrefix(upload.ContentType, "image/") {
+			disposition = "inline"
+		}
+
+		w.Header().Set("Content-Type", upload.ContentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": upload.Filename}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "private, max-age=86400")
+		if _, err = io.Copy(w, content); err != nil {
+			a.Logger.Printf("error sending upload %s: %v", id, err)
+		}
+	}
+}
+
+func uploadErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case eduboard.ErrTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case eduboard.ErrUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	}
+	return errorStatus(err, http.StatusInternalServerError)
+}
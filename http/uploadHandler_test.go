@@ -0,0 +1,157 @@
+package http
+
+import (
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func multipartBody(t *testing.T, file string, fields map[string]string) (*bytes.Buffer, string) {
+	b := &bytes.Buffer{}
+	mw := multipart.NewWriter(b)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	if file != "" {
+		fw, err := mw.CreateFormFile("file", "../picture.png")
+		if err != nil {
+			t.Fatalf("error running test: %v", err)
+		}
+		fw.Write([]byte(file))
+	}
+	mw.Close()
+	return b, mw.FormDataContentType()
+}
+
+func TestAppServer_PostUploadHandler(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		file     string
+		courseID string
+		invoked  bool
+		status   int
+	}{
+		{"success", "content", "", true, 201},
+		{"course", "content", "5b23bbdc2bfa844c41a9f135", true, 201},
+		{"no file", "", "", false, 400},
+		{"bad objectid", "content", "5b23bbdc2bfa844c41a9f35", false, 400},
+		{"forbidden", "forbidden", "", true, 403},
+		{"unsupported", "unsupported", "", true, 415},
+		{"too large", "large", "", true, 413},
+		{"error storing", "error", "", true, 500},
+	}
+
+	service := mock.UploadService{}
+	service.StoreUploadFn = func(upload *eduboard.Upload, content io.Reader, cf eduboard.CourseOneFinder) (error, eduboard.Upload) {
+		data, _ := ioutil.ReadAll(content)
+		switch string(data) {
+		case "forbidden":
+			return errors.Wrap(eduboard.ErrForbidden, "no member"), eduboard.Upload{}
+		case "unsupported":
+			return errors.Wrap(eduboard.ErrUnsupportedMediaType, "text/html"), eduboard.Upload{}
+		case "large":
+			return eduboard.ErrTooLarge, eduboard.Upload{}
+		case "error":
+			return errors.New("could not store"), eduboard.Upload{}
+		}
+		upload.ID = bson.ObjectIdHex("5b23bbdc2bfa844c41a9f140")
+		upload.ContentType = "image/png"
+		upload.ThumbnailType = "image/png"
+		return nil, *upload
+	}
+	a := AppServer{UploadService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			service.StoreUploadFnInvoked = false
+			body, contentType := multipartBody(t, v.file, map[string]string{"courseID": v.courseID})
+			r := httptest.NewRequest("POST", "/", body)
+			r.Header.Set("Content-Type", contentType)
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			a.PostUploadHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			assert.Equal(t, v.invoked, service.StoreUploadFnInvoked, "StoreUpload was not invoked as expected")
+			if v.status == 201 {
+				assert.Contains(t, rr.Body.String(), `"url":"/api/v1/uploads/5b23bbdc2bfa844c41a9f140"`, "url missing")
+				assert.Contains(t, rr.Body.String(), `"thumbnailUrl":"/api/v1/uploads/5b23bbdc2bfa844c41a9f140/thumbnail"`, "thumbnail url missing")
+				assert.Contains(t, rr.Body.String(), `"filename":"picture.png"`, "filename was not cleaned")
+			}
+		})
+	}
+}
+
+func TestAppServer_PostUploadHandler_NoMultipart(t *testing.T) {
+	service := mock.UploadService{}
+	a := AppServer{UploadService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"file":"content"}`))
+	rr := httptest.NewRecorder()
+	a.PostUploadHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 413, rr.Code, "bad response code")
+	assert.False(t, service.StoreUploadFnInvoked, "StoreUpload was invoked")
+}
+
+func TestAppServer_GetUploadHandler(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		uploadID    string
+		thumbnail   bool
+		status      int
+		disposition string
+	}{
+		{"image", "5b23bbdc2bfa844c41a9f140", false, 200, "inline"},
+		{"thumbnail", "5b23bbdc2bfa844c41a9f140", true, 200, "inline"},
+		{"pdf", "5b23bbdc2bfa844c41a9f141", false, 200, "attachment"},
+		{"bad objectid", "5b23bbdc2bfa844c41a9f40", false, 400, ""},
+		{"forbidden", "5b23bbdc2bfa844c41a9f142", false, 403, ""},
+		{"not found", "5b23bbdc2bfa844c41a9f143", false, 404, ""},
+	}
+
+	service := mock.UploadService{}
+	service.OpenUploadFn = func(id string, userID string, thumbnail bool, cf eduboard.CourseOneFinder) (error, eduboard.Upload, io.ReadCloser) {
+		content := ioutil.NopCloser(strings.NewReader("content"))
+		switch id {
+		case "5b23bbdc2bfa844c41a9f140":
+			return nil, eduboard.Upload{Filename: "picture.png", ContentType: "image/png"}, content
+		case "5b23bbdc2bfa844c41a9f141":
+			return nil, eduboard.Upload{Filename: "script.pdf", ContentType: "application/pdf"}, content
+		case "5b23bbdc2bfa844c41a9f142":
+			return errors.Wrap(eduboard.ErrForbidden, "no member"), eduboard.Upload{}, nil
+		}
+		return errors.New("not found"), eduboard.Upload{}, nil
+	}
+	a := AppServer{UploadService: &service, Logger: log.New(os.Stdout, "", 0)}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			rr := httptest.NewRecorder()
+			handler := a.GetUploadHandler()
+			if v.thumbnail {
+				handler = a.GetUploadThumbnailHandler()
+			}
+
+			handler(rr, r, httprouter.Params{httprouter.Param{Key: "uploadID", Value: v.uploadID}})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			if v.status == 200 {
+				assert.Equal(t, "content", rr.Body.String(), "content does not match")
+				assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Disposition"), v.disposition), "disposition does not match")
+				assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"), "content sniffing not disabled")
+			}
+		})
+	}
+}
@@ -49,6 +49,8 @@ func (a *AppServer) RegisterUserHandler() httprouter.Handle {
 		Surname  string `json:"surname"`
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		// Invite is the token of an email invite to redeem, if any.
+		Invite string `json:"invite"`
 	}
 	type response struct {
 		ID      string `json:"id"`
@@ -76,10 +78,25 @@ func (a *AppServer) RegisterUserHandler() httprouter.Handle {
 		err, user := a.UserService.CreateUser(&userModel, request.Password)
 		if err != nil {
 			a.Logger.Printf("error creating user: %v", err)
+			w.WriteHeader(errorStatus(err, http.StatusInternalServerError))
+			return
+		}
+
+		err, session := a.UserService.CreateSession(user.ID.Hex(), r.UserAgent())
+		if err != nil {
+			a.Logger.Printf("error creating session: %v", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
+		// A broken invite must not fail the registration, the user can still be invited again. New users
+		// are not verified yet, so they only join the course once they verified their email.
+		if request.Invite != "" {
+			if err, _ = a.InviteService.RedeemEmailInvite(request.Invite, user.ID.Hex(), a.CourseService); err != nil {
+				a.Logger.Printf("error redeeming invite: %v", err)
+			}
+		}
+
 		response := response{
 			ID:      user.ID.Hex(),
 			Name:    user.Name,
@@ -87,7 +104,7 @@ func (a *AppServer) RegisterUserHandler() httprouter.Handle {
 			Email:   user.Email,
 		}
 
-		cookie := createCookie(user.SessionID, user.SessionExpires)
+		cookie := createCookie(session.Token, session.ExpiresAt)
 		http.SetCookie(w, &cookie)
 		if err = json.NewEncoder(w).Encode(response); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -114,14 +131,14 @@ func (a *AppServer) LoginUserHandler() httprouter.Handle {
 			return
 		}
 
-		err, user := a.UserService.Login(request.Email, request.Password)
+		err, user, session := a.UserService.Login(request.Email, request.Password, r.UserAgent())
 		if err != nil {
 			a.Logger.Printf("error logging in: %v", err)
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
-		cookie := createCookie(user.SessionID, user.SessionExpires)
+		cookie := createCookie(session.Token, session.ExpiresAt)
 		response := response{user.Name, user.Surname, user.Email}
 		http.SetCookie(w, &cookie)
 		if err = json.NewEncoder(w).Encode(response); err != nil {
@@ -151,6 +168,91 @@ func (a *AppServer) LogoutUserHandler() httprouter.Handle {
 	}
 }
 
+func (a *AppServer) ForgotPasswordHandler() httprouter.Handle {
+	type request struct {
+		Email string `json:"email"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var request request
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil || request.Email == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Failures are only logged, the response must not reveal whether the email is registered.
+		if err = a.UserService.RequestPasswordReset(request.Email); err != nil {
+			a.Logger.Printf("error requesting password reset: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (a *AppServer) ResetPasswordHandler() httprouter.Handle {
+	type request struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var request request
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil || request.Token == "" || request.Password == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = a.UserService.ResetPassword(request.Token, request.Password)
+		if err != nil {
+			a.Logger.Printf("error resetting password: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *AppServer) VerifyEmailHandler() httprouter.Handle {
+	type request struct {
+		Token string `json:"token"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var request request
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil || request.Token == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err, userID := a.UserService.VerifyEmail(request.Token)
+		if err != nil {
+			a.Logger.Printf("error verifying email: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// The email is verified either way, courses that can not be joined are only logged.
+		if err = a.InviteService.JoinInvitedCourses(userID, a.CourseService); err != nil {
+			a.Logger.Printf("error joining invited courses: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *AppServer) RequestVerificationHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err := a.UserService.RequestVerification(r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error requesting verification: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
 func (a *AppServer) GetUserHandler() httprouter.Handle {
 	type response struct {
 		ID      string `json:"id"`
@@ -187,11 +289,14 @@ func (a *AppServer) GetUserHandler() httprouter.Handle {
 
 func (a *AppServer) GetMeHandler() httprouter.Handle {
 	type response struct {
-		ID      string `json:"id"`
-		Name    string `json:"name"`
-		Surname string `json:"surname"`
-		Email   string `json:"email"`
-		Picture string `json:"profilePicture,omitempty"`
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Surname  string `json:"surname"`
+		Email    string `json:"email"`
+		Picture  string `json:"profilePicture,omitempty"`
+		Verified bool   `json:"verified"`
+		// Unread is the number of unread notifications.
+		Unread int `json:"unreadNotifications"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
@@ -208,12 +313,20 @@ func (a *AppServer) GetMeHandler() httprouter.Handle {
 			return
 		}
 
+		// The profile is still useful without the count, so errors are only logged.
+		err, unread := a.NotificationService.CountUnread(id)
+		if err != nil {
+			a.Logger.Printf("error counting notifications: %v", err)
+		}
+
 		response := response{
-			ID:      user.ID.Hex(),
-			Name:    user.Name,
-			Surname: user.Surname,
-			Email:   user.Email,
-			Picture: url.StringifyURLs(user.Picture)[0],
+			ID:       user.ID.Hex(),
+			Name:     user.Name,
+			Surname:  user.Surname,
+			Email:    user.Email,
+			Picture:  url.StringifyURLs(user.Picture)[0],
+			Verified: user.Verified,
+			Unread:   unread,
 		}
 
 		if err = json.NewEncoder(w).Encode(response); err != nil {
@@ -222,6 +335,35 @@ func (a *AppServer) GetMeHandler() httprouter.Handle {
 	}
 }
 
+func (a *AppServer) PutProfilePictureHandler() httprouter.Handle {
+	type request struct {
+		Picture string `json:"profilePicture"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		var request request
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		pURLs, err := url.URLifyStrings(request.Picture)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		err = a.UserService.UpdatePicture(r.Header.Get("userID"), pURLs[0])
+		if err != nil {
+			a.Logger.Printf("error updating profile picture: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 func (a *AppServer) GetMyCoursesHandler() httprouter.Handle {
 	type entryResponse struct {
 		ID        string    `json:"id"`
@@ -239,18 +381,23 @@ func (a *AppServer) GetMyCoursesHandler() httprouter.Handle {
 		Title    string        `json:"title,omitempty"`
 	}
 
+	type memberResponse struct {
+		ID   string        `json:"id"`
+		Role eduboard.Role `json:"role"`
+	}
+
 	type courseResponse struct {
 		ID          string             `json:"id"`
 		Title       string             `json:"title"`
 		Description string             `json:"description"`
-		Members     []string           `json:"members,omitempty"`
+		Members     []memberResponse   `json:"members,omitempty"`
 		Labels      []string           `json:"labels,omitempty"`
 		Entries     []entryResponse    `json:"entries,omitempty"`
 		Schedules   []scheduleResponse `json:"schedules,omitempty"`
 	}
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		id := p.ByName("id")
-		err, courses := a.UserService.GetMyCourses(id, a.CourseRepository, a.CourseEntryRepository)
+		err, courses := a.UserService.GetMyCourses(id, r.Header.Get("userID"), a.CourseRepository, a.CourseEntryRepository)
 		if err != nil {
 			a.Logger.Printf("error getting courses: %v", err)
 			w.WriteHeader(http.StatusNotFound)
@@ -263,12 +410,16 @@ func (a *AppServer) GetMyCoursesHandler() httprouter.Handle {
 				ID:          v.ID.Hex(),
 				Title:       v.Title,
 				Description: v.Description,
-				Members:     v.Members,
+				Members:     make([]memberResponse, len(v.Members)),
 				Labels:      v.Labels,
 				Entries:     make([]entryResponse, len(v.Entries)),
 				Schedules:   make([]scheduleResponse, len(v.Schedules)),
 			}
 
+			for mK, mV := range v.Members {
+				res[k].Members[mK] = memberResponse{ID: mV.UserID, Role: mV.Role}
+			}
+
 			for eK, eV := range v.Entries {
 				res[k].Entries[eK] = entryResponse{
 					ID:        eV.ID.Hex(),
@@ -296,6 +447,60 @@ func (a *AppServer) GetMyCoursesHandler() httprouter.Handle {
 	}
 }
 
+func (a *AppServer) GetSessionsHandler() httprouter.Handle {
+	type response struct {
+		ID           string    `json:"id"`
+		UserAgent    string    `json:"userAgent"`
+		CreatedAt    time.Time `json:"createdAt"`
+		LastSeen     time.Time `json:"lastSeen"`
+		ExpiresAt    time.Time `json:"expiresAt"`
+		MaxExpiresAt time.Time `json:"maxExpiresAt"`
+		Current      bool      `json:"current"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err, sessions := a.UserService.GetSessions(r.Header.Get("userID"))
+		if err != nil {
+			a.Logger.Printf("error getting sessions: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var res = make([]response, len(sessions))
+		for k, v := range sessions {
+			res[k] = response{
+				ID:           v.ID.Hex(),
+				UserAgent:    v.UserAgent,
+				CreatedAt:    v.CreatedAt,
+				LastSeen:     v.LastSeen,
+				ExpiresAt:    v.ExpiresAt,
+				MaxExpiresAt: v.MaxExpiresAt,
+				Current:      v.ID.Hex() == r.Header.Get("sessionID"),
+			}
+		}
+
+		if err = json.NewEncoder(w).Encode(&res); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func (a *AppServer) RevokeSessionHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		err := a.UserService.RevokeSession(r.Header.Get("userID"), p.ByName("sessionID"))
+		if err != nil {
+			a.Logger.Printf("error revoking session: %v", err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 func createCookie(value string, expires time.Time) http.Cookie {
-	return http.Cookie{Name: "sessionID", Value: value, Path: "/", Expires: expires, MaxAge: 86400}
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge <= 0 {
+		maxAge = -1
+	}
+	return http.Cookie{Name: "sessionID", Value: value, Path: "/", Expires: expires, MaxAge: maxAge, HttpOnly: true}
 }
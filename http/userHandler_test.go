@@ -14,6 +14,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAppServer_GetAllUsersHandler(t *testing.T) {
@@ -76,23 +77,37 @@ func TestAppServer_RegisterUserHandler(t *testing.T) {
 		u.ID = bson.ObjectIdHex("5b1d24e72c5b292fe0d6ee55")
 		return nil, *u
 	}
-	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+	mockService.CreateSessionFn = func(userID string, userAgent string) (error, eduboard.Session) {
+		return nil, eduboard.Session{Token: "session", UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	inviteService := mock.InviteService{}
+	inviteService.RedeemEmailInviteFn = func(token string, userID string, cj eduboard.CourseJoiner) (error, eduboard.Course) {
+		if token != "invite" {
+			return errors.New("invalid token"), eduboard.Course{}
+		}
+		return nil, eduboard.Course{}
+	}
+	appServer := AppServer{UserService: &mockService, InviteService: &inviteService, Logger: log.New(os.Stdout, "", 0)}
 
 	var testCases = []struct {
 		name   string
 		body   string
 		status int
+		invite bool
 	}{
-		{"no email", `{"password":"password"}`, 400},
-		{"no password", `{"email":"e@mail.com"}`, 400},
-		{"password too short", `{"email":"e@mail.com","password":"pass"}`, 500},
-		{"malformed json", `{"email":"e@mail.com","password":"pass"`, 400},
-		{"success", `{"email":"e@mail.com","password":"password"}`, 200},
+		{"no email", `{"password":"password"}`, 400, false},
+		{"no password", `{"email":"e@mail.com"}`, 400, false},
+		{"password too short", `{"email":"e@mail.com","password":"pass"}`, 500, false},
+		{"malformed json", `{"email":"e@mail.com","password":"pass"`, 400, false},
+		{"success", `{"email":"e@mail.com","password":"password"}`, 200, false},
+		{"invite", `{"email":"e@mail.com","password":"password","invite":"invite"}`, 200, true},
+		{"invalid invite", `{"email":"e@mail.com","password":"password","invite":"other"}`, 200, true},
 	}
 
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
 			mockService.CreateUserFnInvoked = false
+			inviteService.RedeemEmailInviteFnInvoked = false
 			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
 			rr := httptest.NewRecorder()
 
@@ -109,21 +124,38 @@ func TestAppServer_RegisterUserHandler(t *testing.T) {
 			assert.Equal(t, v.status, rr.Code, "bad response code")
 
 			if v.status == 200 {
+				assert.True(t, mockService.CreateSessionFnInvoked, "CreateSession was not invoked")
 				assert.NotEmptyf(t, rr.HeaderMap["Set-Cookie"], "cookie was not set on successful registration")
+				assert.Equal(t, v.invite, inviteService.RedeemEmailInviteFnInvoked, "RedeemEmailInvite was not invoked as expected")
 			}
 		})
 	}
 }
 
+func TestAppServer_RegisterUserHandler_InvalidEmail(t *testing.T) {
+	mockService := mock.UserService{}
+	mockService.CreateUserFn = func(u *eduboard.User, password string) (error, eduboard.User) {
+		return eduboard.ErrInvalidInput, eduboard.User{}
+	}
+	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"email":"e.mail.com","password":"password"}`))
+	rr := httptest.NewRecorder()
+
+	appServer.RegisterUserHandler()(rr, r, httprouter.Params{})
+	assert.Equal(t, 400, rr.Code, "bad response code")
+	assert.False(t, mockService.CreateSessionFnInvoked, "CreateSession was invoked")
+}
+
 func TestAppServer_LoginUserHandler(t *testing.T) {
 	mockService := mock.UserService{}
-	mockService.LoginFn = func(email string, password string) (error, eduboard.User) {
+	mockService.LoginFn = func(email string, password string, userAgent string) (error, eduboard.User, eduboard.Session) {
 		if password != "password" {
-			return errors.New("bad login"), eduboard.User{}
+			return errors.New("bad login"), eduboard.User{}, eduboard.Session{}
 		}
 
 		user := eduboard.User{ID: bson.ObjectIdHex("5b1d24e72c5b292fe0d6ee55")}
-		return nil, user
+		return nil, user, eduboard.Session{Token: "session", UserID: user.ID.Hex(), ExpiresAt: time.Now().Add(time.Hour)}
 	}
 	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
 
@@ -265,7 +297,11 @@ func TestAppServer_GetMeHandler(t *testing.T) {
 		}
 		return nil, eduboard.User{Name: "name", Picture: *u}
 	}
-	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+	notifications := mock.NotificationService{}
+	notifications.CountUnreadFn = func(userID string) (error, int) {
+		return nil, 4
+	}
+	appServer := AppServer{UserService: &mockService, NotificationService: &notifications, Logger: log.New(os.Stdout, "", 0)}
 
 	var testCases = []struct {
 		name   string
@@ -297,6 +333,7 @@ func TestAppServer_GetMeHandler(t *testing.T) {
 			if v.status == 200 {
 				assert.NotEmptyf(t, rr.Body, "body should not be empty")
 				assert.Contains(t, rr.Body.String(), "profilePicture", "Response Body does not contain profilePicture")
+				assert.Contains(t, rr.Body.String(), `"unreadNotifications":4`, "Response Body does not contain unread notifications")
 			}
 		})
 	}
@@ -304,7 +341,7 @@ func TestAppServer_GetMeHandler(t *testing.T) {
 
 func TestAppServer_GetMyCoursesHandler(t *testing.T) {
 	mockService := mock.UserService{}
-	mockService.GetMyCoursesFn = func(id string, cF eduboard.CourseManyFinder, cEF eduboard.CourseEntryManyFinder) (error, []eduboard.Course) {
+	mockService.GetMyCoursesFn = func(id string, viewerID string, cF eduboard.CourseManyFinder, cEF eduboard.CourseEntryManyFinder) (error, []eduboard.Course) {
 		switch id {
 		case "userid":
 			return nil, []eduboard.Course{
@@ -350,3 +387,259 @@ func TestAppServer_GetMyCoursesHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestAppServer_GetSessionsHandler(t *testing.T) {
+	mockService := mock.UserService{}
+	mockService.GetSessionsFn = func(userID string) (error, []eduboard.Session) {
+		if userID != "1" {
+			return errors.New("error"), []eduboard.Session{}
+		}
+		return nil, []eduboard.Session{{ID: "1", UserID: "1"}, {ID: "2", UserID: "1"}}
+	}
+	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		userID string
+		status int
+	}{
+		{"success", "1", 200},
+		{"error", "2", 500},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.GetSessionsFnInvoked = false
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("userID", v.userID)
+			r.Header.Set("sessionID", "31")
+			rr := httptest.NewRecorder()
+
+			appServer.GetSessionsHandler()(rr, r, httprouter.Params{})
+			assert.True(t, mockService.GetSessionsFnInvoked, "GetSessions was not invoked")
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			if v.status == 200 {
+				assert.Contains(t, rr.Body.String(), `"current":true`, "current session was not marked")
+			}
+		})
+	}
+}
+
+func TestAppServer_RevokeSessionHandler(t *testing.T) {
+	mockService := mock.UserService{}
+	mockService.RevokeSessionFn = func(userID string, sessionID string) error {
+		if userID != "1" || sessionID != "31" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name      string
+		sessionID string
+		status    int
+	}{
+		{"success", "31", 204},
+		{"not found", "32", 404},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.RevokeSessionFnInvoked = false
+			r := httptest.NewRequest("DELETE", "/", nil)
+			r.Header.Set("userID", "1")
+			rr := httptest.NewRecorder()
+
+			appServer.RevokeSessionHandler()(rr, r, httprouter.Params{httprouter.Param{Key: "sessionID", Value: v.sessionID}})
+			assert.True(t, mockService.RevokeSessionFnInvoked, "RevokeSession was not invoked")
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+		})
+	}
+}
+
+func TestAppServer_ForgotPasswordHandler(t *testing.T) {
+	mockService := mock.UserService{}
+	mockService.RequestPasswordResetFn = func(email string) error {
+		if email == "fail@mail.com" {
+			return errors.New("error")
+		}
+		return nil
+	}
+	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"no email", `{}`, 400},
+		{"malformed json", `{"email":"e@mail.com"`, 400},
+		{"error", `{"email":"fail@mail.com"}`, 202},
+		{"success", `{"email":"e@mail.com"}`, 202},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.RequestPasswordResetFnInvoked = false
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			rr := httptest.NewRecorder()
+
+			appServer.ForgotPasswordHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			assert.Equal(t, v.status != 400, mockService.RequestPasswordResetFnInvoked, "RequestPasswordReset was not invoked as expected")
+		})
+	}
+}
+
+func TestAppServer_ResetPasswordHandler(t *testing.T) {
+	mockService := mock.UserService{}
+	mockService.ResetPasswordFn = func(token string, password string) error {
+		if token != "token" {
+			return errors.New("invalid token")
+		}
+		return nil
+	}
+	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		body    string
+		invoked bool
+		status  int
+	}{
+		{"no token", `{"password":"password"}`, false, 400},
+		{"no password", `{"token":"token"}`, false, 400},
+		{"malformed json", `{"token":"token"`, false, 400},
+		{"invalid token", `{"token":"other","password":"password"}`, true, 400},
+		{"success", `{"token":"token","password":"password"}`, true, 204},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.ResetPasswordFnInvoked = false
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			rr := httptest.NewRecorder()
+
+			appServer.ResetPasswordHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			assert.Equal(t, v.invoked, mockService.ResetPasswordFnInvoked, "ResetPassword was not invoked as expected")
+		})
+	}
+}
+
+func TestAppServer_VerifyEmailHandler(t *testing.T) {
+	mockService := mock.UserService{}
+	mockService.VerifyEmailFn = func(token string) (error, string) {
+		switch token {
+		case "token":
+			return nil, "1"
+		case "invited":
+			return nil, "2"
+		}
+		return errors.New("invalid token"), ""
+	}
+	inviteService := mock.InviteService{}
+	inviteService.JoinInvitedCoursesFn = func(userID string, cj eduboard.CourseJoiner) error {
+		if userID == "2" {
+			return errors.New("course is archived")
+		}
+		return nil
+	}
+	appServer := AppServer{UserService: &mockService, InviteService: &inviteService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		body    string
+		invoked bool
+		status  int
+	}{
+		{"no token", `{}`, false, 400},
+		{"malformed json", `{"token":"token"`, false, 400},
+		{"invalid token", `{"token":"other"}`, true, 400},
+		{"success", `{"token":"token"}`, true, 204},
+		{"joining fails", `{"token":"invited"}`, true, 204},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.VerifyEmailFnInvoked = false
+			inviteService.JoinInvitedCoursesFnInvoked = false
+			r := httptest.NewRequest("POST", "/", strings.NewReader(v.body))
+			rr := httptest.NewRecorder()
+
+			appServer.VerifyEmailHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			assert.Equal(t, v.invoked, mockService.VerifyEmailFnInvoked, "VerifyEmail was not invoked as expected")
+			assert.Equal(t, v.status == 204, inviteService.JoinInvitedCoursesFnInvoked, "JoinInvitedCourses was not invoked as expected")
+		})
+	}
+}
+
+func TestAppServer_RequestVerificationHandler(t *testing.T) {
+	mockService := mock.UserService{}
+	mockService.RequestVerificationFn = func(userID string) error {
+		if userID != "1" {
+			return errors.New("already verified")
+		}
+		return nil
+	}
+	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name   string
+		userID string
+		status int
+	}{
+		{"already verified", "2", 400},
+		{"success", "1", 202},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", nil)
+			r.Header.Set("userID", v.userID)
+			rr := httptest.NewRecorder()
+
+			appServer.RequestVerificationHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+		})
+	}
+}
+
+func TestAppServer_PutProfilePictureHandler(t *testing.T) {
+	mockService := mock.UserService{}
+	mockService.UpdatePictureFn = func(id string, picture url.URL) error {
+		if id != "1" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+	appServer := AppServer{UserService: &mockService, Logger: log.New(os.Stdout, "", 0)}
+
+	var testCases = []struct {
+		name    string
+		userID  string
+		body    string
+		invoked bool
+		status  int
+	}{
+		{"malformed json", "1", `{"profilePicture":`, false, 400},
+		{"bad url", "1", `{"profilePicture":"htttp\\:.orgcom"}`, false, 400},
+		{"unknown user", "2", `{"profilePicture":"/api/v1/uploads/5b23bbdc2bfa844c41a9f140"}`, true, 500},
+		{"success", "1", `{"profilePicture":"/api/v1/uploads/5b23bbdc2bfa844c41a9f140"}`, true, 204},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockService.UpdatePictureFnInvoked = false
+			r := httptest.NewRequest("PUT", "/", strings.NewReader(v.body))
+			r.Header.Set("userID", v.userID)
+			rr := httptest.NewRecorder()
+
+			appServer.PutProfilePictureHandler()(rr, r, httprouter.Params{})
+			assert.Equal(t, v.status, rr.Code, "bad response code")
+			assert.Equal(t, v.invoked, mockService.UpdatePictureFnInvoked, "UpdatePicture was not invoked as expected")
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package ical writes iCalendar documents as defined by RFC 5545.
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxLineLength is the maximum length of a content line in octets, excluding the line break.
+	maxLineLength = 75
+
+	dateTimeFormat = "20060102T150405"
+)
+
+// Param is a property parameter such as TZID.
+type Param struct {
+	Name  string
+	Value string
+}
+
+// Writer writes content lines, folding lines longer than 75 octets. The first error stops all further writes
+// and is returned by Err.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Begin(component string) {
+	w.Property("BEGIN", component)
+}
+
+func (w *Writer) End(component string) {
+	w.Property("END", component)
+}
+
+// Property writes a property with a value that is already formatted.
+func (w *Writer) Property(name string, value string, params ...Param) {
+	line := name
+	for _, p := range params {
+		line += ";" + p.Name + "=" + p.Value
+	}
+	w.line(line + ":" + value)
+}
+
+// Text writes a property with a text value, escaping it as required.
+func (w *Writer) Text(name string, value string, params ...Param) {
+	w.Property(name, EscapeText(value), params...)
+}
+
+// DateTime writes a property with a date-time value. Times in UTC are written as such, all others in local time
+// along with the TZID of their location, which must be described by a VTIMEZONE of the same document.
+func (w *Writer) DateTime(name string, t time.Time) {
+	if t.Location() == time.UTC {
+		w.Property(name, t.Format(dateTimeFormat)+"Z")
+		return
+	}
+	w.Property(name, t.Format(dateTimeFormat), Param{"TZID", t.Location().String()})
+}
+
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func (w *Writer) line(line string) {
+	if w.err != nil {
+		return
+	}
+
+	// Continuation lines start with a space, which counts towards their length.
+	var b strings.Builder
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	_, w.err = io.WriteString(w.w, b.String())
+}
+
+// EscapeText escapes backslashes, semicolons, commas and line breaks in a text value.
+func EscapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// Timezone writes a VTIMEZONE component describing loc between from and to. Every change of the UTC offset is written
+// as an observance of its own, which spares deriving recurrence rules from the time zone database.
+func (w *Writer) Timezone(loc *time.Location, from time.Time, to time.Time) {
+	w.Begin("VTIMEZONE")
+	w.Property("TZID", loc.String())
+
+	type transition struct {
+		at         time.Time
+		name       string
+		fromOffset int
+		toOffset   int
+	}
+
+	name, offset := from.In(loc).Zone()
+	transitions := []transition{{from, name, offset, offset}}
+	for t := from; t.Before(to); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		if _, o := next.In(loc).Zone(); o == offset {
+			continue
+		}
+
+		// The offset changed within the day, search the second it did.
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.In(loc).Zone(); o == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+
+		name, o := hi.In(loc).Zone()
+		transitions = append(transitions, transition{hi, name, offset, o})
+		offset = o
+	}
+
+	for k, t := range transitions {
+		// Observances without a change are daylight saving time if the next change turns the clocks back.
+		daylight := t.toOffset > t.fromOffset
+		if t.toOffset == t.fromOffset && k+1 < len(transitions) {
+			daylight = transitions[k+1].toOffset < t.toOffset
+		}
+
+		kind := "STANDARD"
+		if daylight {
+			kind = "DAYLIGHT"
+		}
+		w.Begin(kind)
+		// The start of an observance is given in the local time before it.
+		w.Property("DTSTART", t.at.UTC().Add(time.Duration(t.fromOffset)*time.Second).Format(dateTimeFormat))
+		w.Property("TZOFFSETFROM", formatOffset(t.fromOffset))
+		w.Property("TZOFFSETTO", formatOffset(t.toOffset))
+		w.Text("TZNAME", t.name)
+		w.End(kind)
+	}
+
+	w.End("VTIMEZONE")
+}
+
+// formatOffset formats an offset in seconds east of UTC as +HHMM, or +HHMMSS if it is not a whole minute.
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	if offset%60 != 0 {
+		return fmt.Sprintf("%s%02d%02d%02d", sign, offset/3600, offset/60%60, offset%60)
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+}
@@ -0,0 +1,89 @@
+package ical
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter_Property(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	w.Begin("VEVENT")
+	w.Property("DTSTART", "20181015T100000", Param{"TZID", "Europe/Berlin"})
+	w.Text("SUMMARY", "Algebra, Lecture; Room\\1\nsecond line")
+	w.End("VEVENT")
+
+	assert.Nil(t, w.Err(), "should not cause error")
+	assert.Equal(t, "BEGIN:VEVENT\r\n"+
+		"DTSTART;TZID=Europe/Berlin:20181015T100000\r\n"+
+		"SUMMARY:Algebra\\, Lecture\\; Room\\\\1\\nsecond line\r\n"+
+		"END:VEVENT\r\n", b.String(), "output does not match")
+}
+
+func TestWriter_line(t *testing.T) {
+	var testCases = []struct {
+		name  string
+		value string
+	}{
+		{"short", "short"},
+		{"exactly one line", strings.Repeat("a", 67)},
+		{"long", strings.Repeat("abcdefghij", 20)},
+		{"multi-byte", strings.Repeat("äöü€", 30)},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			w := NewWriter(b)
+			w.Text("SUMMARY", v.value)
+			assert.Nil(t, w.Err(), "should not cause error")
+
+			lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+			unfolded := lines[0]
+			for _, l := range lines {
+				assert.True(t, len(l) <= 75, "line is longer than 75 octets")
+			}
+			for _, l := range lines[1:] {
+				assert.True(t, strings.HasPrefix(l, " "), "continuation line does not start with a space")
+				unfolded += l[1:]
+			}
+			assert.Equal(t, "SUMMARY:"+v.value, unfolded, "unfolded line does not match")
+		})
+	}
+}
+
+func TestWriter_DateTime(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	w.DateTime("DTSTAMP", time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC))
+	w.DateTime("DTSTART", time.Date(2018, 10, 15, 10, 0, 0, 0, time.FixedZone("Custom/Zone", 3600)))
+	assert.Equal(t, "DTSTAMP:20181015T100000Z\r\nDTSTART;TZID=Custom/Zone:20181015T100000\r\n", b.String(), "output does not match")
+}
+
+func TestWriter_Timezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	b := &bytes.Buffer{}
+	w := NewWriter(b)
+	w.Timezone(berlin, time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, w.Err(), "should not cause error")
+
+	assert.Equal(t, "BEGIN:VTIMEZONE\r\n"+
+		"TZID:Europe/Berlin\r\n"+
+		"BEGIN:DAYLIGHT\r\nDTSTART:20181001T020000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT\r\n"+
+		"BEGIN:STANDARD\r\nDTSTART:20181028T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nEND:STANDARD\r\n"+
+		"BEGIN:DAYLIGHT\r\nDTSTART:20190331T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT\r\n"+
+		"END:VTIMEZONE\r\n", b.String(), "output does not match")
+}
+
+func TestFormatOffset(t *testing.T) {
+	assert.Equal(t, "+0100", formatOffset(3600), "offset does not match")
+	assert.Equal(t, "-0930", formatOffset(-34200), "offset does not match")
+	assert.Equal(t, "+005328", formatOffset(3208), "offset does not match")
+}
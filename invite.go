@@ -0,0 +1,91 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// InviteCode lets users join a course as students on their own. Codes are meant to be shared, so unlike tokens
+// they are stored as they are and shown to the staff of the course.
+type InviteCode struct {
+	ID        bson.ObjectId `json:"id" bson:"_id"`
+	CourseID  bson.ObjectId `json:"courseID" bson:"courseID"`
+	Code      string        `json:"code" bson:"code"`
+	CreatedBy string        `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+	// ExpiresAt is nil for codes that do not expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	// MaxUses limits how often a code can be used. 0 is unlimited.
+	MaxUses int  `json:"maxUses" bson:"maxUses"`
+	Uses    int  `json:"uses" bson:"uses"`
+	Revoked bool `json:"revoked" bson:"revoked"`
+}
+
+// Valid reports whether the code can still be used at now.
+func (c InviteCode) Valid(now time.Time) bool {
+	if c.Revoked || c.ExpiresAt != nil && !c.ExpiresAt.After(now) {
+		return false
+	}
+	return c.MaxUses == 0 || c.Uses < c.MaxUses
+}
+
+// EmailInvite invites someone without an account to a course. The invite is redeemed with the token sent
+// to Email when registering. Like VerificationToken only the hash of the token is stored.
+type EmailInvite struct {
+	ID        bson.ObjectId `json:"id" bson:"_id"`
+	CourseID  bson.ObjectId `json:"courseID" bson:"courseID"`
+	Email     string        `json:"email" bson:"email"`
+	TokenHash string        `json:"-" bson:"tokenHash"`
+	InvitedBy string        `json:"invitedBy" bson:"invitedBy"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+	// ExpiresAt is removed once the invite is redeemed, as the user may only join after verifying their email.
+	ExpiresAt time.Time `json:"expiresAt" bson:"expiresAt"`
+	// UserID is the user who redeemed the invite, empty while it is pending.
+	UserID string `json:"userID,omitempty" bson:"userID,omitempty"`
+}
+
+type InviteRepository interface {
+	InsertCode(code *InviteCode) error
+	FindCode(code string) (error, InviteCode)
+	// FindCodes returns all codes of a course, newest first.
+	FindCodes(courseID string) (error, []InviteCode)
+	// UseCode counts a use of code if it is still valid at now. Concurrent uses can not exceed MaxUses.
+	UseCode(code InviteCode, now time.Time) error
+	// ReleaseCode gives back a use counted by UseCode that did not lead to joining the course.
+	ReleaseCode(id string) error
+	RevokeCode(id string, courseID string) error
+	InsertEmailInvite(invite *EmailInvite) error
+	// FindEmailInvites returns the pending and unexpired invites of a course, newest first.
+	FindEmailInvites(courseID string, now time.Time) (error, []EmailInvite)
+	// RedeemEmailInvite marks the pending and unexpired invite with the given token hash as redeemed by userID and returns it.
+	// Redeemed invites do not expire, they are kept until DeleteEmailInvite is called.
+	RedeemEmailInvite(tokenHash string, userID string, now time.Time) (error, EmailInvite)
+	// FindRedeemedEmailInvites returns the invites redeemed by userID.
+	FindRedeemedEmailInvites(userID string) (error, []EmailInvite)
+	DeleteEmailInvite(id string) error
+	// DeleteByCourse deletes all codes and email invites of a course.
+	DeleteByCourse(courseID string) error
+}
+
+// CourseJoiner adds users to courses on behalf of invites.
+type CourseJoiner interface {
+	AddMembers(id string, userID string, members []Member) (error, Course)
+	// JoinCourse adds userID to a course as a student. Callers have to make sure the user was invited.
+	JoinCourse(id string, userID string) (error, Course)
+}
+
+type InviteService interface {
+	CreateInviteCode(courseID string, userID string, expiresAt *time.Time, maxUses int) (error, InviteCode)
+	GetInviteCodes(courseID string, userID string) (error, []InviteCode)
+	RevokeInviteCode(courseID string, codeID string, userID string) error
+	Join(code string, userID string, cj CourseJoiner) (error, Course)
+	// InviteEmail adds the user with the given email to a course, or sends an invite if there is no such user.
+	InviteEmail(courseID string, userID string, email string, cj CourseJoiner) (error, EmailInvite)
+	GetEmailInvites(courseID string, userID string) (error, []EmailInvite)
+	// RedeemEmailInvite adds userID to the course of an email invite. Users who did not verify their email yet
+	// join the course in JoinInvitedCourses once they did, until then an empty course is returned.
+	RedeemEmailInvite(token string, userID string, cj CourseJoiner) (error, Course)
+	// JoinInvitedCourses adds a user who just verified their email to the courses of the invites they redeemed.
+	JoinInvitedCourses(userID string, cj CourseJoiner) error
+	DeleteByCourse(courseID string) error
+}
@@ -0,0 +1,107 @@
+// Package mail sends emails. Messages are rendered from templates and handed to a Mailer,
+// which either delivers them through SMTP or stores them in a local maildir for development.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"mime"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"strings"
+	"time"
+)
+
+// ErrInvalidAddress is returned if the sender or a recipient of a message is not a valid email address.
+var ErrInvalidAddress = errors.New("invalid email address")
+
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers a single message.
+type Mailer interface {
+	Send(m Message) error
+}
+
+// Validate returns ErrInvalidAddress unless the sender and all recipients are valid addresses.
+func (m Message) Validate() error {
+	_, _, err := m.addresses()
+	return err
+}
+
+// addresses parses the sender and the recipients. Addresses end up in headers, so anything that is not
+// a single well-formed address, such as a string containing a line break, is rejected.
+func (m Message) addresses() (*netmail.Address, []*netmail.Address, error) {
+	from, err := netmail.ParseAddress(m.From)
+	if err != nil {
+		return nil, nil, errors.Wrapf(ErrInvalidAddress, "sender %q: %v", m.From, err)
+	}
+	if len(m.To) == 0 {
+		return nil, nil, errors.Wrap(ErrInvalidAddress, "no recipients")
+	}
+
+	to := make([]*netmail.Address, len(m.To))
+	for k, v := range m.To {
+		if to[k], err = netmail.ParseAddress(v); err != nil {
+			return nil, nil, errors.Wrapf(ErrInvalidAddress, "recipient %q: %v", v, err)
+		}
+	}
+	return from, to, nil
+}
+
+// Bytes returns the message in RFC 5322 format. Messages with both a text and a HTML body are sent as
+// multipart/alternative, so clients can pick the representation they prefer.
+func (m Message) Bytes() ([]byte, error) {
+	from, to, err := m.addresses()
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := make([]string, len(to))
+	for k, v := range to {
+		recipients[k] = v.String()
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\n")
+
+	if m.HTML == "" {
+		writePart(&b, "text/plain", m.Text)
+		return b.Bytes(), nil
+	}
+
+	boundary := randomString()
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", boundary)
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	writePart(&b, "text/plain", m.Text)
+	fmt.Fprintf(&b, "\r\n--%s\r\n", boundary)
+	writePart(&b, "text/html", m.HTML)
+	fmt.Fprintf(&b, "\r\n--%s--\r\n", boundary)
+	return b.Bytes(), nil
+}
+
+func writePart(b *bytes.Buffer, contentType string, body string) {
+	fmt.Fprintf(b, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	fmt.Fprintf(b, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	w := quotedprintable.NewWriter(b)
+	w.Write([]byte(body))
+	w.Close()
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMessage_Bytes(t *testing.T) {
+	t.Parallel()
+	var testCases = []struct {
+		name      string
+		message   Message
+		multipart bool
+	}{
+		{"text", Message{From: "a@mail.com", To: []string{"b@mail.com"}, Subject: "Hello", Text: "Hello World"}, false},
+		{"html", Message{From: "a@mail.com", To: []string{"b@mail.com", "c@mail.com"}, Subject: "Hello", Text: "Hello World", HTML: "<p>Hello World</p>"}, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			data, err := v.message.Bytes()
+			assert.Nil(t, err, "should not cause error")
+			b := string(data)
+			assert.Contains(t, b, "From: <a@mail.com>\r\n", "sender missing")
+			assert.Contains(t, b, "To: <"+strings.Join(v.message.To, ">, <")+">\r\n", "recipients missing")
+			assert.Contains(t, b, "Subject: Hello\r\n", "subject missing")
+			assert.Contains(t, b, "Hello World", "text body missing")
+			assert.Equal(t, v.multipart, strings.Contains(b, "multipart/alternative"), "multipart does not match")
+			if v.multipart {
+				assert.Contains(t, b, "<p>Hello World</p>", "html body missing")
+			}
+		})
+	}
+}
+
+func TestMessage_Validate(t *testing.T) {
+	t.Parallel()
+	var testCases = []struct {
+		name    string
+		message Message
+		valid   bool
+	}{
+		{"valid", Message{From: "Eduboard <a@mail.com>", To: []string{"b@mail.com"}}, true},
+		{"injected sender", Message{From: "a@mail.com\r\nBcc: c@mail.com", To: []string{"b@mail.com"}}, false},
+		{"injected recipient", Message{From: "a@mail.com", To: []string{"b@mail.com\r\nBcc: c@mail.com"}}, false},
+		{"no recipients", Message{From: "a@mail.com"}, false},
+		{"malformed recipient", Message{From: "a@mail.com", To: []string{"b"}}, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err := v.message.Validate()
+			if v.valid {
+				assert.Nil(t, err, "valid message was rejected")
+				return
+			}
+			assert.Equal(t, ErrInvalidAddress, errors.Cause(err), "invalid message was accepted")
+			_, err = v.message.Bytes()
+			assert.NotNil(t, err, "invalid message was formatted")
+		})
+	}
+}
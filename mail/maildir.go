@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Maildir stores messages as files in a maildir instead of delivering them. Any mail client supporting
+// maildirs can be used to read them during development, tests can simply inspect the files.
+type Maildir struct {
+	Dir string
+}
+
+var _ Mailer = (*Maildir)(nil)
+
+func (m *Maildir) Send(msg Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(m.Dir, sub), 0700); err != nil {
+			return err
+		}
+	}
+
+	// Messages are written to tmp first and moved to new afterwards, so readers never see partial files.
+	name := fmt.Sprintf("%d.%s.eduboard", time.Now().UnixNano(), randomString())
+	tmp := filepath.Join(m.Dir, "tmp", name)
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(m.Dir, "new", name))
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaildir_Send(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maildir")
+	if err != nil {
+		t.Fatalf("error running test: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := Maildir{Dir: dir}
+	err = m.Send(Message{From: "a@mail.com", To: []string{"b@mail.com"}, Subject: "Hello", Text: "Hello World"})
+	assert.Nil(t, err, "should not cause error")
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "new"))
+	assert.Nil(t, err, "new directory was not created")
+	assert.Len(t, files, 1, "message was not stored")
+
+	tmp, err := ioutil.ReadDir(filepath.Join(dir, "tmp"))
+	assert.Nil(t, err, "tmp directory was not created")
+	assert.Empty(t, tmp, "message was not moved from tmp")
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "new", files[0].Name()))
+	assert.Nil(t, err, "message can not be read")
+	assert.Contains(t, string(content), "Hello World", "message content was not stored")
+}
@@ -0,0 +1,89 @@
+package mail
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned if a message can not be queued because too many messages are waiting for delivery.
+var ErrQueueFull = errors.New("mail queue is full")
+
+// Queue sends messages asynchronously through another Mailer. Failed deliveries are retried with exponential backoff
+// until Attempts is reached, after which the message is dropped and the error is logged.
+type Queue struct {
+	Mailer   Mailer
+	Logger   *log.Logger
+	Attempts int
+	Backoff  time.Duration
+
+	messages chan Message
+	wg       sync.WaitGroup
+}
+
+var _ Mailer = (*Queue)(nil)
+
+// NewQueue creates a queue holding up to size messages and starts workers goroutines delivering them.
+func NewQueue(mailer Mailer, logger *log.Logger, size int, workers int) *Queue {
+	q := &Queue{
+		Mailer:   mailer,
+		Logger:   logger,
+		Attempts: 5,
+		Backoff:  time.Second,
+		messages: make(chan Message, size),
+	}
+
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Send queues m for delivery and returns immediately. Messages with invalid addresses are rejected right away,
+// as retrying them would not help.
+func (q *Queue) Send(m Message) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	select {
+	case q.messages <- m:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting messages and waits until all queued messages have been handled.
+func (q *Queue) Close() {
+	close(q.messages)
+	q.wg.Wait()
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for m := range q.messages {
+		q.deliver(m)
+	}
+}
+
+func (q *Queue) deliver(m Message) {
+	backoff := q.Backoff
+	for attempt := 1; ; attempt++ {
+		err := q.Mailer.Send(m)
+		if err == nil {
+			return
+		}
+
+		if attempt >= q.Attempts {
+			q.Logger.Printf("giving up sending mail %q to %v after %d attempts: %v", m.Subject, m.To, attempt, err)
+			return
+		}
+
+		q.Logger.Printf("error sending mail %q to %v, retrying in %s: %v", m.Subject, m.To, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
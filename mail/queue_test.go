@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"sync"
+	"testing"
+	"time"
+)
+
+type failingMailer struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	sent     []Message
+}
+
+func (f *failingMailer) Send(m Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.attempts <= f.failures {
+		return errors.New("temporary failure")
+	}
+	f.sent = append(f.sent, m)
+	return nil
+}
+
+func TestQueue_Send(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		failures int
+		attempts int
+		sent     int
+	}{
+		{"success", 0, 1, 1},
+		{"retried", 2, 3, 1},
+		{"given up", 10, 3, 0},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			mailer := &failingMailer{failures: v.failures}
+			q := NewQueue(mailer, log.New(b, "", 0), 1, 1)
+			q.Attempts = 3
+			q.Backoff = time.Millisecond
+
+			err := q.Send(Message{From: "a@mail.com", To: []string{"b@mail.com"}, Subject: "Hello"})
+			assert.Nil(t, err, "should not cause error")
+			q.Close()
+
+			assert.Equal(t, v.attempts, mailer.attempts, "attempts do not match")
+			assert.Len(t, mailer.sent, v.sent, "sent messages do not match")
+			if v.sent == 0 {
+				assert.Contains(t, b.String(), "giving up", "failure was not logged")
+			}
+		})
+	}
+}
+
+func TestQueue_SendFull(t *testing.T) {
+	block := make(chan struct{})
+	mailer := &blockingMailer{block: block}
+	q := NewQueue(mailer, log.New(&bytes.Buffer{}, "", 0), 1, 1)
+
+	// The first message is taken by the worker, the second one fills the queue.
+	m := Message{From: "a@mail.com", To: []string{"b@mail.com"}}
+	assert.Nil(t, q.Send(m), "should not cause error")
+	<-mailer.started()
+	assert.Nil(t, q.Send(m), "should not cause error")
+	assert.Equal(t, ErrQueueFull, q.Send(m), "full queue accepted message")
+
+	close(block)
+	q.Close()
+}
+
+type blockingMailer struct {
+	block chan struct{}
+	once  sync.Once
+	start chan struct{}
+}
+
+func (b *blockingMailer) started() chan struct{} {
+	b.once.Do(func() { b.start = make(chan struct{}) })
+	return b.start
+}
+
+func (b *blockingMailer) Send(m Message) error {
+	select {
+	case <-b.started():
+	default:
+		close(b.started())
+	}
+	<-b.block
+	return nil
+}
@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"net"
+	"net/smtp"
+)
+
+// SMTP delivers messages to an SMTP server. Authentication is only used if a username is set,
+// which allows pointing it to a local test server.
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+}
+
+var _ Mailer = (*SMTP)(nil)
+
+func (s *SMTP) Send(m Message) error {
+	data, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	from, to, _ := m.addresses()
+
+	recipients := make([]string, len(to))
+	for k, v := range to {
+		recipients[k] = v.Address
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, from.Address, recipients, data)
+}
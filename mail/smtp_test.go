@@ -0,0 +1,68 @@
+package mail
+
+import (
+	"bufio"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"testing"
+)
+
+// serveSMTP accepts a single connection on l and speaks just enough SMTP to receive one message.
+func serveSMTP(l net.Listener, received chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	write := func(s string) { conn.Write([]byte(s + "\r\n")) }
+	write("220 localhost test server")
+
+	var data []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			write("250 localhost")
+		case strings.HasPrefix(cmd, "DATA"):
+			write("354 go ahead")
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				data = append(data, line)
+			}
+			received <- strings.Join(data, "")
+			write("250 ok")
+		case strings.HasPrefix(cmd, "QUIT"):
+			write("221 bye")
+			return
+		default:
+			write("250 ok")
+		}
+	}
+}
+
+func TestSMTP_Send(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error running test: %v", err)
+	}
+	defer l.Close()
+
+	received := make(chan string, 1)
+	go serveSMTP(l, received)
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	s := SMTP{Host: host, Port: port}
+	err = s.Send(Message{From: "a@mail.com", To: []string{"b@mail.com"}, Subject: "Hello", Text: "Hello World"})
+	assert.Nil(t, err, "should not cause error")
+	assert.Contains(t, <-received, "Hello World", "message was not delivered")
+}
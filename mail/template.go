@@ -0,0 +1,75 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	"strings"
+	textTemplate "text/template"
+)
+
+// Template renders a message with a subject, a plain text and an optional HTML body.
+// The HTML body is escaped automatically, the other parts are used as they are.
+type Template struct {
+	subject *textTemplate.Template
+	text    *textTemplate.Template
+	html    *htmlTemplate.Template
+}
+
+// NewTemplate parses the templates of a message. html may be empty for plain text messages.
+func NewTemplate(name string, subject string, text string, html string) (*Template, error) {
+	var err error
+	t := &Template{}
+
+	if t.subject, err = textTemplate.New(name + ".subject").Parse(subject); err != nil {
+		return nil, err
+	}
+	if t.text, err = textTemplate.New(name + ".text").Parse(text); err != nil {
+		return nil, err
+	}
+	if html != "" {
+		if t.html, err = htmlTemplate.New(name + ".html").Parse(html); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// MustTemplate is like NewTemplate but panics if a template can not be parsed.
+// It is meant for templates defined at compile time.
+func MustTemplate(name string, subject string, text string, html string) *Template {
+	t, err := NewTemplate(name, subject, text, html)
+	if err != nil {
+		panic(fmt.Sprintf("mail: error parsing template %s: %v", name, err))
+	}
+	return t
+}
+
+// Render creates a message to the recipients in to, filling the templates with data.
+func (t *Template) Render(from string, to []string, data interface{}) (Message, error) {
+	m := Message{From: from, To: to}
+
+	var b bytes.Buffer
+	if err := t.subject.Execute(&b, data); err != nil {
+		return Message{}, err
+	}
+	// Line breaks in the subject would allow injecting headers.
+	m.Subject = strings.Join(strings.Fields(b.String()), " ")
+
+	b.Reset()
+	if err := t.text.Execute(&b, data); err != nil {
+		return Message{}, err
+	}
+	m.Text = b.String()
+
+	if t.html == nil {
+		return m, nil
+	}
+
+	b.Reset()
+	if err := t.html.Execute(&b, data); err != nil {
+		return Message{}, err
+	}
+	m.HTML = b.String()
+	return m, nil
+}
@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	t.Parallel()
+	var testCases = []struct {
+		name     string
+		html     string
+		expected Message
+	}{
+		{"text", "", Message{From: "a@mail.com", To: []string{"b@mail.com"}, Subject: "Hello <World>", Text: "Hi <World>"}},
+		{"html", "<p>Hi {{.}}</p>", Message{From: "a@mail.com", To: []string{"b@mail.com"}, Subject: "Hello <World>", Text: "Hi <World>", HTML: "<p>Hi &lt;World&gt;</p>"}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			tmpl, err := NewTemplate(v.name, "Hello\n{{.}}", "Hi {{.}}", v.html)
+			assert.Nil(t, err, "should not cause error")
+
+			m, err := tmpl.Render("a@mail.com", []string{"b@mail.com"}, "<World>")
+			assert.Nil(t, err, "should not cause error")
+			assert.Equal(t, v.expected, m, "message does not match")
+		})
+	}
+}
+
+func TestNewTemplate(t *testing.T) {
+	t.Parallel()
+	_, err := NewTemplate("broken", "{{.", "", "")
+	assert.Error(t, err, "broken template was parsed")
+
+	assert.Panics(t, func() { MustTemplate("broken", "", "{{.", "") }, "broken template did not panic")
+}
@@ -0,0 +1,136 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"time"
+)
+
+const (
+	// MaxMaterialNameLength is the largest number of characters in the name of a folder or material.
+	MaxMaterialNameLength = 255
+	// MaxMaterialDescriptionLength is the largest number of characters in the description of a material.
+	MaxMaterialDescriptionLength = 5000
+	// MaxMaterialFolderDepth is the largest number of nested folders.
+	MaxMaterialFolderDepth = 10
+	// MaxMaterialExportSize is the largest total size in bytes of the files in an exported archive. The archive is
+	// built while the request is answered, so larger folders have to be downloaded in parts.
+	MaxMaterialExportSize = 100 << 20
+)
+
+// MaterialFolder groups the materials of a course. Folders without ParentID are at the top of the library.
+type MaterialFolder struct {
+	ID        bson.ObjectId `json:"id" bson:"_id"`
+	CourseID  bson.ObjectId `json:"courseID" bson:"courseID"`
+	ParentID  bson.ObjectId `json:"parentID,omitempty" bson:"parentID,omitempty"`
+	Name      string        `json:"name" bson:"name"`
+	CreatedBy string        `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+}
+
+// MaterialFolderUpdate describes a partial update of a MaterialFolder. Fields that are nil are left untouched,
+// an empty ParentID moves the folder to the top of the library.
+type MaterialFolderUpdate struct {
+	Name     *string
+	ParentID *string
+}
+
+// Material is a file in the library of a course. Uploading a new version keeps the previous ones.
+// Materials without FolderID are at the top of the library.
+type Material struct {
+	ID          bson.ObjectId `json:"id" bson:"_id"`
+	CourseID    bson.ObjectId `json:"courseID" bson:"courseID"`
+	FolderID    bson.ObjectId `json:"folderID,omitempty" bson:"folderID,omitempty"`
+	Name        string        `json:"name" bson:"name"`
+	Description string        `json:"description" bson:"description"`
+	// Versions holds all versions of the file, oldest first.
+	Versions  []MaterialVersion `json:"versions" bson:"versions"`
+	CreatedBy string            `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time         `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Latest returns the current version of the material.
+func (m Material) Latest() MaterialVersion {
+	if len(m.Versions) == 0 {
+		return MaterialVersion{}
+	}
+	return m.Versions[len(m.Versions)-1]
+}
+
+// MaterialVersion is one uploaded revision of a material. Versions are numbered from 1.
+type MaterialVersion struct {
+	Version     int           `json:"version" bson:"version"`
+	UploadID    bson.ObjectId `json:"uploadID" bson:"uploadID"`
+	Filename    string        `json:"filename" bson:"filename"`
+	ContentType string        `json:"contentType" bson:"contentType"`
+	Size        int64         `json:"size" bson:"size"`
+	Comment     string        `json:"comment,omitempty" bson:"comment,omitempty"`
+	UploadedBy  string        `json:"uploadedBy" bson:"uploadedBy"`
+	UploadedAt  time.Time     `json:"uploadedAt" bson:"uploadedAt"`
+}
+
+// MaterialUpdate describes a partial update of a Material. Fields that are nil are left untouched,
+// an empty FolderID moves the material to the top of the library.
+type MaterialUpdate struct {
+	Name        *string
+	Description *string
+	FolderID    *string
+}
+
+// MaterialListing is the content of a folder, or of the top of the library if Folder is nil.
+// Path holds the folders above Folder, outermost first.
+type MaterialListing struct {
+	Folder    *MaterialFolder  `json:"folder"`
+	Path      []MaterialFolder `json:"path"`
+	Folders   []MaterialFolder `json:"folders"`
+	Materials []Material       `json:"materials"`
+}
+
+// MaterialFolderRepository fails with ErrDuplicate if a folder would have the same name as another one in its folder.
+type MaterialFolderRepository interface {
+	Insert(folder MaterialFolder) error
+	FindOneByID(id string) (error, MaterialFolder)
+	FindByCourse(courseID string) (error, []MaterialFolder)
+	Update(id string, update bson.M) (error, MaterialFolder)
+	Delete(ids []bson.ObjectId) error
+	DeleteByCourse(courseID string) error
+}
+
+// MaterialRepository fails with ErrDuplicate if a material would have the same name as another one in its folder.
+type MaterialRepository interface {
+	Insert(material Material) error
+	FindOneByID(id string) (error, Material)
+	FindByCourse(courseID string) (error, []Material)
+	Update(id string, update bson.M) (error, Material)
+	// AddVersion appends version to the versions of a material and returns the updated material.
+	AddVersion(id string, version MaterialVersion) (error, Material)
+	Delete(id string) error
+	// DeleteByFolders deletes all materials in the folders with the given IDs.
+	DeleteByFolders(folderIDs []bson.ObjectId) error
+	DeleteByCourse(courseID string) error
+}
+
+type MaterialService interface {
+	// GetListing returns the content of a folder, or of the top of the library if folderID is empty.
+	GetListing(courseID string, folderID string, userID string) (error, MaterialListing)
+	GetFolder(courseID string, folderID string, userID string) (error, MaterialFolder)
+	CreateFolder(courseID string, userID string, folder MaterialFolder) (error, MaterialFolder)
+	UpdateFolder(courseID string, folderID string, userID string, update MaterialFolderUpdate) (error, MaterialFolder)
+	// DeleteFolder deletes a folder along with all folders and materials in it.
+	DeleteFolder(courseID string, folderID string, userID string) error
+	GetMaterial(courseID string, materialID string, userID string) (error, Material)
+	// CreateMaterial adds a material whose first version is the upload with the given ID.
+	CreateMaterial(courseID string, userID string, material Material, uploadID string) (error, Material)
+	UpdateMaterial(courseID string, materialID string, userID string, update MaterialUpdate) (error, Material)
+	AddVersion(courseID string, materialID string, userID string, uploadID string, comment string) (error, Material)
+	DeleteMaterial(courseID string, materialID string, userID string) error
+	// OpenMaterial returns a version of a material and its content, the latest one if version is 0.
+	// The caller has to close the content.
+	OpenMaterial(courseID string, materialID string, userID string, version int) (error, MaterialVersion, io.ReadCloser)
+	// ExportFolder writes the latest versions of all materials in a folder and its subfolders to w as a zip archive.
+	// The whole library is exported if folderID is empty. It fails with ErrTooLarge if the files exceed
+	// MaxMaterialExportSize.
+	ExportFolder(courseID string, folderID string, userID string, w io.Writer) error
+	DeleteByCourse(courseID string) error
+}
@@ -3,6 +3,9 @@ package mock
 import (
 	"github.com/eduboard/backend"
 	"gopkg.in/mgo.v2/bson"
+	"io"
+	"net/url"
+	"time"
 )
 
 type Repository struct {
@@ -24,18 +27,47 @@ type CourseRepository struct {
 	UpdateFn        func(id string, update bson.M) (error, eduboard.Course)
 	UpdateFnInvoked bool
 
+	AddMemberFn        func(id string, member eduboard.Member) (error, eduboard.Course, bool)
+	AddMemberFnInvoked bool
+
 	FindByMemberFn        func(member string) (error, []eduboard.Course)
 	FindByMemberFnInvoked bool
+
+	SearchFn        func(query eduboard.CourseQuery) (error, []eduboard.Course, int)
+	SearchFnInvoked bool
+
+	DeleteFn        func(id string) error
+	DeleteFnInvoked bool
 }
 
 var (
-	_ eduboard.CourseRepository = (*CourseRepository)(nil)
-	_ eduboard.CourseInserter   = (*CourseRepository)(nil)
-	_ eduboard.CourseOneFinder  = (*CourseRepository)(nil)
-	_ eduboard.CourseManyFinder = (*CourseRepository)(nil)
-	_ eduboard.CourseUpdater    = (*CourseRepository)(nil)
+	_ eduboard.CourseRepository  = (*CourseRepository)(nil)
+	_ eduboard.CourseInserter    = (*CourseRepository)(nil)
+	_ eduboard.CourseOneFinder   = (*CourseRepository)(nil)
+	_ eduboard.CourseManyFinder  = (*CourseRepository)(nil)
+	_ eduboard.CourseUpdater     = (*CourseRepository)(nil)
+	_ eduboard.CourseMemberAdder = (*CourseRepository)(nil)
+	_ eduboard.CourseDeleter     = (*CourseRepository)(nil)
 )
 
+// CourseFinder returns a CourseRepository that finds a course for each of ids and for archivedID, all with the given
+// members. The course archivedID is archived, other IDs are not found.
+func CourseFinder(members []eduboard.Member, archivedID string, ids ...string) *CourseRepository {
+	cr := &CourseRepository{}
+	cr.FindFn = func(id string) (error, eduboard.Course) {
+		if id == archivedID {
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members, Archived: true}
+		}
+		for _, v := range ids {
+			if v == id {
+				return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members}
+			}
+		}
+		return eduboard.ErrNotFound, eduboard.Course{}
+	}
+	return cr
+}
+
 func (cRM *CourseRepository) Insert(course *eduboard.Course) error {
 	cRM.StoreFnInvoked = true
 	return cRM.StoreFn(course)
@@ -56,11 +88,39 @@ func (cRM *CourseRepository) Update(id string, update bson.M) (error, eduboard.C
 	return cRM.UpdateFn(id, update)
 }
 
+func (cRM *CourseRepository) AddMember(id string, member eduboard.Member) (error, eduboard.Course, bool) {
+	cRM.AddMemberFnInvoked = true
+	return cRM.AddMemberFn(id, member)
+}
+
 func (cRM *CourseRepository) FindByMember(member string) (error, []eduboard.Course) {
 	cRM.FindByMemberFnInvoked = true
 	return cRM.FindByMemberFn(member)
 }
 
+func (cRM *CourseRepository) Search(query eduboard.CourseQuery) (error, []eduboard.Course, int) {
+	cRM.SearchFnInvoked = true
+	return cRM.SearchFn(query)
+}
+
+func (cRM *CourseRepository) Delete(id string) error {
+	cRM.DeleteFnInvoked = true
+	return cRM.DeleteFn(id)
+}
+
+// CourseDataDeleter implements the eduboard.CourseDataDeleter interface to mock functions and record successful invocations.
+type CourseDataDeleter struct {
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.CourseDataDeleter = (*CourseDataDeleter)(nil)
+
+func (cDDM *CourseDataDeleter) DeleteByCourse(courseID string) error {
+	cDDM.DeleteByCourseFnInvoked = true
+	return cDDM.DeleteByCourseFn(courseID)
+}
+
 // Course implements the eduboard.CourseRepository interface to mock functions and record successful invocations.
 type UserRepository struct {
 	StoreFn        func(user *eduboard.User) error
@@ -75,12 +135,6 @@ type UserRepository struct {
 	FindByEmailFn        func(email string) (error, eduboard.User)
 	FindByEmailFnInvoked bool
 
-	FindBySessionIDFn        func(sessionID string) (error, eduboard.User)
-	FindBySessionIDFnInvoked bool
-
-	UpdateSessionIDFn        func(user eduboard.User) (error, eduboard.User)
-	UpdateSessionIDFnInvoked bool
-
 	IsIDValidFn        func(id string) bool
 	IsIDValidFnInvoked bool
 
@@ -89,6 +143,24 @@ type UserRepository struct {
 
 	FindMembersFn        func(members []string) (error, []eduboard.User)
 	FindMembersFnInvoked bool
+
+	UpdatePasswordFn        func(id string, passwordHash string) error
+	UpdatePasswordFnInvoked bool
+
+	SetVerifiedFn        func(id string) error
+	SetVerifiedFnInvoked bool
+
+	SetPictureFn        func(id string, picture url.URL) error
+	SetPictureFnInvoked bool
+
+	RemoveCourseFn        func(courseID string) error
+	RemoveCourseFnInvoked bool
+
+	SetCalendarTokenFn        func(id string, tokenHash string) error
+	SetCalendarTokenFnInvoked bool
+
+	FindByCalendarTokenFn        func(tokenHash string) (error, eduboard.User)
+	FindByCalendarTokenFnInvoked bool
 }
 
 var _ eduboard.UserRepository = (*UserRepository)(nil)
@@ -113,16 +185,6 @@ func (uRM *UserRepository) FindByEmail(email string) (error, eduboard.User) {
 	return uRM.FindByEmailFn(email)
 }
 
-func (uRM *UserRepository) FindBySessionID(sessionID string) (error, eduboard.User) {
-	uRM.FindBySessionIDFnInvoked = true
-	return uRM.FindBySessionIDFn(sessionID)
-}
-
-func (uRM *UserRepository) UpdateSessionID(user eduboard.User) (error, eduboard.User) {
-	uRM.UpdateSessionIDFnInvoked = true
-	return uRM.UpdateSessionIDFn(user)
-}
-
 func (uRM *UserRepository) IsIDValid(id string) bool {
 	uRM.IsIDValidFnInvoked = true
 	return uRM.IsIDValidFn(id)
@@ -138,6 +200,36 @@ func (uRM *UserRepository) FindMembers(members []string) (error, []eduboard.User
 	return uRM.FindMembersFn(members)
 }
 
+func (uRM *UserRepository) UpdatePassword(id string, passwordHash string) error {
+	uRM.UpdatePasswordFnInvoked = true
+	return uRM.UpdatePasswordFn(id, passwordHash)
+}
+
+func (uRM *UserRepository) SetVerified(id string) error {
+	uRM.SetVerifiedFnInvoked = true
+	return uRM.SetVerifiedFn(id)
+}
+
+func (uRM *UserRepository) SetPicture(id string, picture url.URL) error {
+	uRM.SetPictureFnInvoked = true
+	return uRM.SetPictureFn(id, picture)
+}
+
+func (uRM *UserRepository) RemoveCourse(courseID string) error {
+	uRM.RemoveCourseFnInvoked = true
+	return uRM.RemoveCourseFn(courseID)
+}
+
+func (uRM *UserRepository) SetCalendarToken(id string, tokenHash string) error {
+	uRM.SetCalendarTokenFnInvoked = true
+	return uRM.SetCalendarTokenFn(id, tokenHash)
+}
+
+func (uRM *UserRepository) FindByCalendarToken(tokenHash string) (error, eduboard.User) {
+	uRM.FindByCalendarTokenFnInvoked = true
+	return uRM.FindByCalendarTokenFn(tokenHash)
+}
+
 // CourseEntryRepository implements the eduboard.CourseEntryRepository interface to mock functions and record successful invocations.
 type CourseEntryRepository struct {
 	InsertFn        func(course eduboard.CourseEntry) error
@@ -149,11 +241,23 @@ type CourseEntryRepository struct {
 	FindManyFn        func(query bson.M) (error, []eduboard.CourseEntry)
 	FindManyFnInvoked bool
 
+	FindFeedFn        func(query bson.M, limit int) (error, []eduboard.CourseEntry)
+	FindFeedFnInvoked bool
+
 	UpdateFn        func(id string, update bson.M) error
 	UpdateFnInvoked bool
 
+	PublishDueFn        func(now time.Time) (error, []eduboard.CourseEntry)
+	PublishDueFnInvoked bool
+
 	DeleteFn        func(id string) error
 	DeleteFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+
+	UpdateIfFn        func(id string, query bson.M, update bson.M) error
+	UpdateIfFnInvoked bool
 }
 
 var _ eduboard.CourseEntryRepository = (*CourseEntryRepository)(nil)
@@ -173,12 +277,978 @@ func (cRM *CourseEntryRepository) FindMany(query bson.M) (error, []eduboard.Cour
 	return cRM.FindManyFn(query)
 }
 
+func (cRM *CourseEntryRepository) FindFeed(query bson.M, limit int) (error, []eduboard.CourseEntry) {
+	cRM.FindFeedFnInvoked = true
+	return cRM.FindFeedFn(query, limit)
+}
+
 func (cRM *CourseEntryRepository) Update(id string, update bson.M) error {
 	cRM.UpdateFnInvoked = true
 	return cRM.UpdateFn(id, update)
 }
 
+func (cRM *CourseEntryRepository) PublishDue(now time.Time) (error, []eduboard.CourseEntry) {
+	cRM.PublishDueFnInvoked = true
+	return cRM.PublishDueFn(now)
+}
+
 func (cRM *CourseEntryRepository) Delete(id string) error {
 	cRM.DeleteFnInvoked = true
 	return cRM.DeleteFn(id)
 }
+
+func (cRM *CourseEntryRepository) DeleteByCourse(courseID string) error {
+	cRM.DeleteByCourseFnInvoked = true
+	return cRM.DeleteByCourseFn(courseID)
+}
+
+func (cRM *CourseEntryRepository) UpdateIf(id string, query bson.M, update bson.M) error {
+	cRM.UpdateIfFnInvoked = true
+	return cRM.UpdateIfFn(id, query, update)
+}
+
+// SessionRepository implements the eduboard.SessionRepository interface to mock functions and record successful invocations.
+type SessionRepository struct {
+	InsertFn        func(session *eduboard.Session) error
+	InsertFnInvoked bool
+
+	FindByTokenFn        func(tokenHash string) (error, eduboard.Session)
+	FindByTokenFnInvoked bool
+
+	FindByUserFn        func(userID string) (error, []eduboard.Session)
+	FindByUserFnInvoked bool
+
+	TouchFn        func(id string, lastSeen time.Time, expiresAt time.Time) error
+	TouchFnInvoked bool
+
+	DeleteFn        func(id string) error
+	DeleteFnInvoked bool
+
+	DeleteByUserFn        func(userID string) error
+	DeleteByUserFnInvoked bool
+}
+
+var _ eduboard.SessionRepository = (*SessionRepository)(nil)
+
+func (sRM *SessionRepository) Insert(session *eduboard.Session) error {
+	sRM.InsertFnInvoked = true
+	return sRM.InsertFn(session)
+}
+
+func (sRM *SessionRepository) FindByToken(tokenHash string) (error, eduboard.Session) {
+	sRM.FindByTokenFnInvoked = true
+	return sRM.FindByTokenFn(tokenHash)
+}
+
+func (sRM *SessionRepository) FindByUser(userID string) (error, []eduboard.Session) {
+	sRM.FindByUserFnInvoked = true
+	return sRM.FindByUserFn(userID)
+}
+
+func (sRM *SessionRepository) Touch(id string, lastSeen time.Time, expiresAt time.Time) error {
+	sRM.TouchFnInvoked = true
+	return sRM.TouchFn(id, lastSeen, expiresAt)
+}
+
+func (sRM *SessionRepository) Delete(id string) error {
+	sRM.DeleteFnInvoked = true
+	return sRM.DeleteFn(id)
+}
+
+func (sRM *SessionRepository) DeleteByUser(userID string) error {
+	sRM.DeleteByUserFnInvoked = true
+	return sRM.DeleteByUserFn(userID)
+}
+
+// PasswordResetRepository implements the eduboard.PasswordResetRepository interface to mock functions and record successful invocations.
+type PasswordResetRepository struct {
+	InsertFn        func(token *eduboard.PasswordResetToken) error
+	InsertFnInvoked bool
+
+	ConsumeFn        func(tokenHash string, now time.Time) (error, eduboard.PasswordResetToken)
+	ConsumeFnInvoked bool
+
+	ReleaseFn        func(id string) error
+	ReleaseFnInvoked bool
+
+	DeleteByUserFn        func(userID string) error
+	DeleteByUserFnInvoked bool
+}
+
+var _ eduboard.PasswordResetRepository = (*PasswordResetRepository)(nil)
+
+func (pRM *PasswordResetRepository) Insert(token *eduboard.PasswordResetToken) error {
+	pRM.InsertFnInvoked = true
+	return pRM.InsertFn(token)
+}
+
+func (pRM *PasswordResetRepository) Consume(tokenHash string, now time.Time) (error, eduboard.PasswordResetToken) {
+	pRM.ConsumeFnInvoked = true
+	return pRM.ConsumeFn(tokenHash, now)
+}
+
+func (pRM *PasswordResetRepository) Release(id string) error {
+	pRM.ReleaseFnInvoked = true
+	return pRM.ReleaseFn(id)
+}
+
+func (pRM *PasswordResetRepository) DeleteByUser(userID string) error {
+	pRM.DeleteByUserFnInvoked = true
+	return pRM.DeleteByUserFn(userID)
+}
+
+// VerificationRepository implements the eduboard.VerificationRepository interface to mock functions and record successful invocations.
+type VerificationRepository struct {
+	InsertFn        func(token *eduboard.VerificationToken) error
+	InsertFnInvoked bool
+
+	ConsumeFn        func(tokenHash string, now time.Time) (error, eduboard.VerificationToken)
+	ConsumeFnInvoked bool
+}
+
+var _ eduboard.VerificationRepository = (*VerificationRepository)(nil)
+
+func (vRM *VerificationRepository) Insert(token *eduboard.VerificationToken) error {
+	vRM.InsertFnInvoked = true
+	return vRM.InsertFn(token)
+}
+
+func (vRM *VerificationRepository) Consume(tokenHash string, now time.Time) (error, eduboard.VerificationToken) {
+	vRM.ConsumeFnInvoked = true
+	return vRM.ConsumeFn(tokenHash, now)
+}
+
+// UploadRepository implements the eduboard.UploadRepository interface to mock functions and record successful invocations.
+type UploadRepository struct {
+	InsertFn        func(upload *eduboard.Upload) error
+	InsertFnInvoked bool
+
+	FindFn        func(id string) (error, eduboard.Upload)
+	FindFnInvoked bool
+
+	FindByCourseFn        func(courseID string) (error, []eduboard.Upload)
+	FindByCourseFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.UploadRepository = (*UploadRepository)(nil)
+
+func (uRM *UploadRepository) Insert(upload *eduboard.Upload) error {
+	uRM.InsertFnInvoked = true
+	return uRM.InsertFn(upload)
+}
+
+func (uRM *UploadRepository) Find(id string) (error, eduboard.Upload) {
+	uRM.FindFnInvoked = true
+	return uRM.FindFn(id)
+}
+
+func (uRM *UploadRepository) FindByCourse(courseID string) (error, []eduboard.Upload) {
+	uRM.FindByCourseFnInvoked = true
+	return uRM.FindByCourseFn(courseID)
+}
+
+func (uRM *UploadRepository) DeleteByCourse(courseID string) error {
+	uRM.DeleteByCourseFnInvoked = true
+	return uRM.DeleteByCourseFn(courseID)
+}
+
+// RoomRepository implements the eduboard.RoomRepository interface to mock functions and record successful invocations.
+type RoomRepository struct {
+	InsertFn        func(room *eduboard.Room) error
+	InsertFnInvoked bool
+
+	FindAllFn        func() (error, []eduboard.Room)
+	FindAllFnInvoked bool
+
+	FindOneByIDFn        func(id string) (error, eduboard.Room)
+	FindOneByIDFnInvoked bool
+
+	FindByNameFn        func(name string) (error, eduboard.Room)
+	FindByNameFnInvoked bool
+
+	UpdateFn        func(id string, update bson.M) (error, eduboard.Room)
+	UpdateFnInvoked bool
+
+	DeleteFn        func(id string) error
+	DeleteFnInvoked bool
+}
+
+var _ eduboard.RoomRepository = (*RoomRepository)(nil)
+
+func (rRM *RoomRepository) Insert(room *eduboard.Room) error {
+	rRM.InsertFnInvoked = true
+	return rRM.InsertFn(room)
+}
+
+func (rRM *RoomRepository) FindAll() (error, []eduboard.Room) {
+	rRM.FindAllFnInvoked = true
+	return rRM.FindAllFn()
+}
+
+func (rRM *RoomRepository) FindOneByID(id string) (error, eduboard.Room) {
+	rRM.FindOneByIDFnInvoked = true
+	return rRM.FindOneByIDFn(id)
+}
+
+func (rRM *RoomRepository) FindByName(name string) (error, eduboard.Room) {
+	rRM.FindByNameFnInvoked = true
+	return rRM.FindByNameFn(name)
+}
+
+func (rRM *RoomRepository) Update(id string, update bson.M) (error, eduboard.Room) {
+	rRM.UpdateFnInvoked = true
+	return rRM.UpdateFn(id, update)
+}
+
+func (rRM *RoomRepository) Delete(id string) error {
+	rRM.DeleteFnInvoked = true
+	return rRM.DeleteFn(id)
+}
+
+// CommentRepository implements the eduboard.CommentRepository interface to mock functions and record successful invocations.
+type CommentRepository struct {
+	InsertFn        func(comment eduboard.Comment) error
+	InsertFnInvoked bool
+
+	FindOneByIDFn        func(id string) (error, eduboard.Comment)
+	FindOneByIDFnInvoked bool
+
+	FindManyFn        func(query bson.M, limit int) (error, []eduboard.Comment)
+	FindManyFnInvoked bool
+
+	UpdateFn        func(id string, update bson.M) error
+	UpdateFnInvoked bool
+
+	DeleteFn        func(id string) error
+	DeleteFnInvoked bool
+
+	DeleteByEntryFn        func(entryID string) error
+	DeleteByEntryFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.CommentRepository = (*CommentRepository)(nil)
+
+func (cRM *CommentRepository) Insert(comment eduboard.Comment) error {
+	cRM.InsertFnInvoked = true
+	return cRM.InsertFn(comment)
+}
+
+func (cRM *CommentRepository) FindOneByID(id string) (error, eduboard.Comment) {
+	cRM.FindOneByIDFnInvoked = true
+	return cRM.FindOneByIDFn(id)
+}
+
+func (cRM *CommentRepository) FindMany(query bson.M, limit int) (error, []eduboard.Comment) {
+	cRM.FindManyFnInvoked = true
+	return cRM.FindManyFn(query, limit)
+}
+
+func (cRM *CommentRepository) Update(id string, update bson.M) error {
+	cRM.UpdateFnInvoked = true
+	return cRM.UpdateFn(id, update)
+}
+
+func (cRM *CommentRepository) Delete(id string) error {
+	cRM.DeleteFnInvoked = true
+	return cRM.DeleteFn(id)
+}
+
+func (cRM *CommentRepository) DeleteByEntry(entryID string) error {
+	cRM.DeleteByEntryFnInvoked = true
+	return cRM.DeleteByEntryFn(entryID)
+}
+
+func (cRM *CommentRepository) DeleteByCourse(courseID string) error {
+	cRM.DeleteByCourseFnInvoked = true
+	return cRM.DeleteByCourseFn(courseID)
+}
+
+// NotificationRepository implements the eduboard.NotificationRepository interface to mock functions and record successful invocations.
+type NotificationRepository struct {
+	InsertFn        func(notifications []eduboard.Notification) error
+	InsertFnInvoked bool
+
+	FindByUserFn        func(userID string, unread bool, limit int) (error, []eduboard.Notification)
+	FindByUserFnInvoked bool
+
+	MarkReadFn        func(id string, userID string) error
+	MarkReadFnInvoked bool
+
+	MarkAllReadFn        func(userID string) error
+	MarkAllReadFnInvoked bool
+
+	CountUnreadFn        func(userID string) (error, int)
+	CountUnreadFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.NotificationRepository = (*NotificationRepository)(nil)
+
+func (nRM *NotificationRepository) Insert(notifications []eduboard.Notification) error {
+	nRM.InsertFnInvoked = true
+	return nRM.InsertFn(notifications)
+}
+
+func (nRM *NotificationRepository) FindByUser(userID string, unread bool, limit int) (error, []eduboard.Notification) {
+	nRM.FindByUserFnInvoked = true
+	return nRM.FindByUserFn(userID, unread, limit)
+}
+
+func (nRM *NotificationRepository) MarkRead(id string, userID string) error {
+	nRM.MarkReadFnInvoked = true
+	return nRM.MarkReadFn(id, userID)
+}
+
+func (nRM *NotificationRepository) MarkAllRead(userID string) error {
+	nRM.MarkAllReadFnInvoked = true
+	return nRM.MarkAllReadFn(userID)
+}
+
+func (nRM *NotificationRepository) CountUnread(userID string) (error, int) {
+	nRM.CountUnreadFnInvoked = true
+	return nRM.CountUnreadFn(userID)
+}
+
+func (nRM *NotificationRepository) DeleteByCourse(courseID string) error {
+	nRM.DeleteByCourseFnInvoked = true
+	return nRM.DeleteByCourseFn(courseID)
+}
+
+// BlobStore implements the eduboard.BlobStore interface to mock functions and record successful invocations.
+type BlobStore struct {
+	PutFn        func(key string, r io.Reader) error
+	PutFnInvoked bool
+
+	GetFn        func(key string) (io.ReadCloser, error)
+	GetFnInvoked bool
+
+	DeleteFn        func(key string) error
+	DeleteFnInvoked bool
+}
+
+var _ eduboard.BlobStore = (*BlobStore)(nil)
+
+func (bSM *BlobStore) Put(key string, r io.Reader) error {
+	bSM.PutFnInvoked = true
+	return bSM.PutFn(key, r)
+}
+
+func (bSM *BlobStore) Get(key string) (io.ReadCloser, error) {
+	bSM.GetFnInvoked = true
+	return bSM.GetFn(key)
+}
+
+func (bSM *BlobStore) Delete(key string) error {
+	bSM.DeleteFnInvoked = true
+	return bSM.DeleteFn(key)
+}
+
+// InviteRepository implements the eduboard.InviteRepository interface to mock functions and record successful invocations.
+type InviteRepository struct {
+	InsertCodeFn        func(code *eduboard.InviteCode) error
+	InsertCodeFnInvoked bool
+
+	FindCodeFn        func(code string) (error, eduboard.InviteCode)
+	FindCodeFnInvoked bool
+
+	FindCodesFn        func(courseID string) (error, []eduboard.InviteCode)
+	FindCodesFnInvoked bool
+
+	UseCodeFn        func(code eduboard.InviteCode, now time.Time) error
+	UseCodeFnInvoked bool
+
+	ReleaseCodeFn        func(id string) error
+	ReleaseCodeFnInvoked bool
+
+	RevokeCodeFn        func(id string, courseID string) error
+	RevokeCodeFnInvoked bool
+
+	InsertEmailInviteFn        func(invite *eduboard.EmailInvite) error
+	InsertEmailInviteFnInvoked bool
+
+	FindEmailInvitesFn        func(courseID string, now time.Time) (error, []eduboard.EmailInvite)
+	FindEmailInvitesFnInvoked bool
+
+	RedeemEmailInviteFn        func(tokenHash string, userID string, now time.Time) (error, eduboard.EmailInvite)
+	RedeemEmailInviteFnInvoked bool
+
+	FindRedeemedEmailInvitesFn        func(userID string) (error, []eduboard.EmailInvite)
+	FindRedeemedEmailInvitesFnInvoked bool
+
+	DeleteEmailInviteFn        func(id string) error
+	DeleteEmailInviteFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.InviteRepository = (*InviteRepository)(nil)
+
+func (iRM *InviteRepository) InsertCode(code *eduboard.InviteCode) error {
+	iRM.InsertCodeFnInvoked = true
+	return iRM.InsertCodeFn(code)
+}
+
+func (iRM *InviteRepository) FindCode(code string) (error, eduboard.InviteCode) {
+	iRM.FindCodeFnInvoked = true
+	return iRM.FindCodeFn(code)
+}
+
+func (iRM *InviteRepository) FindCodes(courseID string) (error, []eduboard.InviteCode) {
+	iRM.FindCodesFnInvoked = true
+	return iRM.FindCodesFn(courseID)
+}
+
+func (iRM *InviteRepository) UseCode(code eduboard.InviteCode, now time.Time) error {
+	iRM.UseCodeFnInvoked = true
+	return iRM.UseCodeFn(code, now)
+}
+
+func (iRM *InviteRepository) ReleaseCode(id string) error {
+	iRM.ReleaseCodeFnInvoked = true
+	return iRM.ReleaseCodeFn(id)
+}
+
+func (iRM *InviteRepository) RevokeCode(id string, courseID string) error {
+	iRM.RevokeCodeFnInvoked = true
+	return iRM.RevokeCodeFn(id, courseID)
+}
+
+func (iRM *InviteRepository) InsertEmailInvite(invite *eduboard.EmailInvite) error {
+	iRM.InsertEmailInviteFnInvoked = true
+	return iRM.InsertEmailInviteFn(invite)
+}
+
+func (iRM *InviteRepository) FindEmailInvites(courseID string, now time.Time) (error, []eduboard.EmailInvite) {
+	iRM.FindEmailInvitesFnInvoked = true
+	return iRM.FindEmailInvitesFn(courseID, now)
+}
+
+func (iRM *InviteRepository) RedeemEmailInvite(tokenHash string, userID string, now time.Time) (error, eduboard.EmailInvite) {
+	iRM.RedeemEmailInviteFnInvoked = true
+	return iRM.RedeemEmailInviteFn(tokenHash, userID, now)
+}
+
+func (iRM *InviteRepository) FindRedeemedEmailInvites(userID string) (error, []eduboard.EmailInvite) {
+	iRM.FindRedeemedEmailInvitesFnInvoked = true
+	return iRM.FindRedeemedEmailInvitesFn(userID)
+}
+
+func (iRM *InviteRepository) DeleteEmailInvite(id string) error {
+	iRM.DeleteEmailInviteFnInvoked = true
+	return iRM.DeleteEmailInviteFn(id)
+}
+
+func (iRM *InviteRepository) DeleteByCourse(courseID string) error {
+	iRM.DeleteByCourseFnInvoked = true
+	return iRM.DeleteByCourseFn(courseID)
+}
+
+// EnrollmentRepository implements the eduboard.EnrollmentRepository interface to mock functions and record successful invocations.
+type EnrollmentRepository struct {
+	InsertFn        func(request *eduboard.EnrollmentRequest) error
+	InsertFnInvoked bool
+
+	FindOneByIDFn        func(id string) (error, eduboard.EnrollmentRequest)
+	FindOneByIDFnInvoked bool
+
+	FindFn        func(courseID string, userID string) (error, eduboard.EnrollmentRequest)
+	FindFnInvoked bool
+
+	FindByCourseFn        func(courseID string, status eduboard.EnrollmentStatus) (error, []eduboard.EnrollmentRequest)
+	FindByCourseFnInvoked bool
+
+	DecideFn        func(id string, status eduboard.EnrollmentStatus, userID string, now time.Time) error
+	DecideFnInvoked bool
+
+	DeletePendingFn        func(courseID string, userID string) error
+	DeletePendingFnInvoked bool
+
+	DeleteFn        func(id string) error
+	DeleteFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.EnrollmentRepository = (*EnrollmentRepository)(nil)
+
+func (eRM *EnrollmentRepository) Insert(request *eduboard.EnrollmentRequest) error {
+	eRM.InsertFnInvoked = true
+	return eRM.InsertFn(request)
+}
+
+func (eRM *EnrollmentRepository) FindOneByID(id string) (error, eduboard.EnrollmentRequest) {
+	eRM.FindOneByIDFnInvoked = true
+	return eRM.FindOneByIDFn(id)
+}
+
+func (eRM *EnrollmentRepository) Find(courseID string, userID string) (error, eduboard.EnrollmentRequest) {
+	eRM.FindFnInvoked = true
+	return eRM.FindFn(courseID, userID)
+}
+
+func (eRM *EnrollmentRepository) FindByCourse(courseID string, status eduboard.EnrollmentStatus) (error, []eduboard.EnrollmentRequest) {
+	eRM.FindByCourseFnInvoked = true
+	return eRM.FindByCourseFn(courseID, status)
+}
+
+func (eRM *EnrollmentRepository) Decide(id string, status eduboard.EnrollmentStatus, userID string, now time.Time) error {
+	eRM.DecideFnInvoked = true
+	return eRM.DecideFn(id, status, userID, now)
+}
+
+func (eRM *EnrollmentRepository) DeletePending(courseID string, userID string) error {
+	eRM.DeletePendingFnInvoked = true
+	return eRM.DeletePendingFn(courseID, userID)
+}
+
+func (eRM *EnrollmentRepository) Delete(id string) error {
+	eRM.DeleteFnInvoked = true
+	return eRM.DeleteFn(id)
+}
+
+func (eRM *EnrollmentRepository) DeleteByCourse(courseID string) error {
+	eRM.DeleteByCourseFnInvoked = true
+	return eRM.DeleteByCourseFn(courseID)
+}
+
+// AssignmentRepository implements the eduboard.AssignmentRepository interface to mock functions and record successful invocations.
+type AssignmentRepository struct {
+	InsertFn        func(assignment *eduboard.Assignment) error
+	InsertFnInvoked bool
+
+	FindOneByIDFn        func(id string) (error, eduboard.Assignment)
+	FindOneByIDFnInvoked bool
+
+	FindByCourseFn        func(courseID string) (error, []eduboard.Assignment)
+	FindByCourseFnInvoked bool
+
+	UpdateFn        func(id string, update bson.M) (error, eduboard.Assignment)
+	UpdateFnInvoked bool
+
+	DeleteFn        func(id string) error
+	DeleteFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.AssignmentRepository = (*AssignmentRepository)(nil)
+
+func (aRM *AssignmentRepository) Insert(assignment *eduboard.Assignment) error {
+	aRM.InsertFnInvoked = true
+	return aRM.InsertFn(assignment)
+}
+
+func (aRM *AssignmentRepository) FindOneByID(id string) (error, eduboard.Assignment) {
+	aRM.FindOneByIDFnInvoked = true
+	return aRM.FindOneByIDFn(id)
+}
+
+func (aRM *AssignmentRepository) FindByCourse(courseID string) (error, []eduboard.Assignment) {
+	aRM.FindByCourseFnInvoked = true
+	return aRM.FindByCourseFn(courseID)
+}
+
+func (aRM *AssignmentRepository) Update(id string, update bson.M) (error, eduboard.Assignment) {
+	aRM.UpdateFnInvoked = true
+	return aRM.UpdateFn(id, update)
+}
+
+func (aRM *AssignmentRepository) Delete(id string) error {
+	aRM.DeleteFnInvoked = true
+	return aRM.DeleteFn(id)
+}
+
+func (aRM *AssignmentRepository) DeleteByCourse(courseID string) error {
+	aRM.DeleteByCourseFnInvoked = true
+	return aRM.DeleteByCourseFn(courseID)
+}
+
+// SubmissionRepository implements the eduboard.SubmissionRepository interface to mock functions and record successful invocations.
+type SubmissionRepository struct {
+	UpsertFn        func(submission *eduboard.Submission) error
+	UpsertFnInvoked bool
+
+	FindFn        func(assignmentID string, userID string) (error, eduboard.Submission)
+	FindFnInvoked bool
+
+	FindByAssignmentFn        func(assignmentID string) (error, []eduboard.Submission)
+	FindByAssignmentFnInvoked bool
+
+	DeleteByAssignmentFn        func(assignmentID string) error
+	DeleteByAssignmentFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.SubmissionRepository = (*SubmissionRepository)(nil)
+
+func (sRM *SubmissionRepository) Upsert(submission *eduboard.Submission) error {
+	sRM.UpsertFnInvoked = true
+	return sRM.UpsertFn(submission)
+}
+
+func (sRM *SubmissionRepository) Find(assignmentID string, userID string) (error, eduboard.Submission) {
+	sRM.FindFnInvoked = true
+	return sRM.FindFn(assignmentID, userID)
+}
+
+func (sRM *SubmissionRepository) FindByAssignment(assignmentID string) (error, []eduboard.Submission) {
+	sRM.FindByAssignmentFnInvoked = true
+	return sRM.FindByAssignmentFn(assignmentID)
+}
+
+func (sRM *SubmissionRepository) DeleteByAssignment(assignmentID string) error {
+	sRM.DeleteByAssignmentFnInvoked = true
+	return sRM.DeleteByAssignmentFn(assignmentID)
+}
+
+func (sRM *SubmissionRepository) DeleteByCourse(courseID string) error {
+	sRM.DeleteByCourseFnInvoked = true
+	return sRM.DeleteByCourseFn(courseID)
+}
+
+// GradeRepository implements the eduboard.GradeRepository interface to mock functions and record successful invocations.
+type GradeRepository struct {
+	UpsertFn        func(grade *eduboard.Grade) error
+	UpsertFnInvoked bool
+
+	FindByCourseFn        func(courseID string) (error, []eduboard.Grade)
+	FindByCourseFnInvoked bool
+
+	FindByUserFn        func(courseID string, userID string) (error, []eduboard.Grade)
+	FindByUserFnInvoked bool
+
+	DeleteFn        func(assignmentID string, userID string) error
+	DeleteFnInvoked bool
+
+	DeleteByAssignmentFn        func(assignmentID string) error
+	DeleteByAssignmentFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.GradeRepository = (*GradeRepository)(nil)
+
+func (gRM *GradeRepository) Upsert(grade *eduboard.Grade) error {
+	gRM.UpsertFnInvoked = true
+	return gRM.UpsertFn(grade)
+}
+
+func (gRM *GradeRepository) FindByCourse(courseID string) (error, []eduboard.Grade) {
+	gRM.FindByCourseFnInvoked = true
+	return gRM.FindByCourseFn(courseID)
+}
+
+func (gRM *GradeRepository) FindByUser(courseID string, userID string) (error, []eduboard.Grade) {
+	gRM.FindByUserFnInvoked = true
+	return gRM.FindByUserFn(courseID, userID)
+}
+
+func (gRM *GradeRepository) Delete(assignmentID string, userID string) error {
+	gRM.DeleteFnInvoked = true
+	return gRM.DeleteFn(assignmentID, userID)
+}
+
+func (gRM *GradeRepository) DeleteByAssignment(assignmentID string) error {
+	gRM.DeleteByAssignmentFnInvoked = true
+	return gRM.DeleteByAssignmentFn(assignmentID)
+}
+
+func (gRM *GradeRepository) DeleteByCourse(courseID string) error {
+	gRM.DeleteByCourseFnInvoked = true
+	return gRM.DeleteByCourseFn(courseID)
+}
+
+// GradeCategoryRepository implements the eduboard.GradeCategoryRepository interface to mock functions and record successful invocations.
+type GradeCategoryRepository struct {
+	FindFn        func(courseID string) (error, []eduboard.GradeCategory)
+	FindFnInvoked bool
+
+	SetFn        func(courseID string, categories []eduboard.GradeCategory) error
+	SetFnInvoked bool
+
+	DeleteFn        func(courseID string) error
+	DeleteFnInvoked bool
+}
+
+var _ eduboard.GradeCategoryRepository = (*GradeCategoryRepository)(nil)
+
+func (gCRM *GradeCategoryRepository) Find(courseID string) (error, []eduboard.GradeCategory) {
+	gCRM.FindFnInvoked = true
+	return gCRM.FindFn(courseID)
+}
+
+func (gCRM *GradeCategoryRepository) Set(courseID string, categories []eduboard.GradeCategory) error {
+	gCRM.SetFnInvoked = true
+	return gCRM.SetFn(courseID, categories)
+}
+
+func (gCRM *GradeCategoryRepository) Delete(courseID string) error {
+	gCRM.DeleteFnInvoked = true
+	return gCRM.DeleteFn(courseID)
+}
+
+// AttendanceRepository implements the eduboard.AttendanceRepository interface to mock functions and record successful invocations.
+type AttendanceRepository struct {
+	InsertFn        func(session *eduboard.AttendanceSession) error
+	InsertFnInvoked bool
+
+	FindOneByIDFn        func(id string) (error, eduboard.AttendanceSession)
+	FindOneByIDFnInvoked bool
+
+	FindFn        func(courseID string, scheduleID string, date string) (error, eduboard.AttendanceSession)
+	FindFnInvoked bool
+
+	FindByCourseFn        func(courseID string) (error, []eduboard.AttendanceSession)
+	FindByCourseFnInvoked bool
+
+	SetCodeFn        func(id string, code string, expiresAt time.Time) error
+	SetCodeFnInvoked bool
+
+	SetRecordFn        func(id string, record eduboard.AttendanceRecord) error
+	SetRecordFnInvoked bool
+
+	CheckInFn        func(courseID string, code string, record eduboard.AttendanceRecord, now time.Time) (error, eduboard.AttendanceSession)
+	CheckInFnInvoked bool
+
+	DeleteFn        func(id string) error
+	DeleteFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.AttendanceRepository = (*AttendanceRepository)(nil)
+
+func (aRM *AttendanceRepository) Insert(session *eduboard.AttendanceSession) error {
+	aRM.InsertFnInvoked = true
+	return aRM.InsertFn(session)
+}
+
+func (aRM *AttendanceRepository) FindOneByID(id string) (error, eduboard.AttendanceSession) {
+	aRM.FindOneByIDFnInvoked = true
+	return aRM.FindOneByIDFn(id)
+}
+
+func (aRM *AttendanceRepository) Find(courseID string, scheduleID string, date string) (error, eduboard.AttendanceSession) {
+	aRM.FindFnInvoked = true
+	return aRM.FindFn(courseID, scheduleID, date)
+}
+
+func (aRM *AttendanceRepository) FindByCourse(courseID string) (error, []eduboard.AttendanceSession) {
+	aRM.FindByCourseFnInvoked = true
+	return aRM.FindByCourseFn(courseID)
+}
+
+func (aRM *AttendanceRepository) SetCode(id string, code string, expiresAt time.Time) error {
+	aRM.SetCodeFnInvoked = true
+	return aRM.SetCodeFn(id, code, expiresAt)
+}
+
+func (aRM *AttendanceRepository) SetRecord(id string, record eduboard.AttendanceRecord) error {
+	aRM.SetRecordFnInvoked = true
+	return aRM.SetRecordFn(id, record)
+}
+
+func (aRM *AttendanceRepository) CheckIn(courseID string, code string, record eduboard.AttendanceRecord, now time.Time) (error, eduboard.AttendanceSession) {
+	aRM.CheckInFnInvoked = true
+	return aRM.CheckInFn(courseID, code, record, now)
+}
+
+func (aRM *AttendanceRepository) Delete(id string) error {
+	aRM.DeleteFnInvoked = true
+	return aRM.DeleteFn(id)
+}
+
+func (aRM *AttendanceRepository) DeleteByCourse(courseID string) error {
+	aRM.DeleteByCourseFnInvoked = true
+	return aRM.DeleteByCourseFn(courseID)
+}
+
+// PollResponseRepository implements the eduboard.PollResponseRepository interface to mock functions and record successful invocations.
+type PollResponseRepository struct {
+	InsertFn        func(response eduboard.PollResponse) error
+	InsertFnInvoked bool
+
+	FindFn        func(entryID string, userID string) (error, eduboard.PollResponse)
+	FindFnInvoked bool
+
+	FindByEntryFn        func(entryID string) (error, []eduboard.PollResponse)
+	FindByEntryFnInvoked bool
+
+	CountByEntryFn        func(entryID string) (error, int)
+	CountByEntryFnInvoked bool
+
+	DeleteByEntryFn        func(entryID string) error
+	DeleteByEntryFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.PollResponseRepository = (*PollResponseRepository)(nil)
+
+func (pRM *PollResponseRepository) Insert(response eduboard.PollResponse) error {
+	pRM.InsertFnInvoked = true
+	return pRM.InsertFn(response)
+}
+
+func (pRM *PollResponseRepository) Find(entryID string, userID string) (error, eduboard.PollResponse) {
+	pRM.FindFnInvoked = true
+	return pRM.FindFn(entryID, userID)
+}
+
+func (pRM *PollResponseRepository) FindByEntry(entryID string) (error, []eduboard.PollResponse) {
+	pRM.FindByEntryFnInvoked = true
+	return pRM.FindByEntryFn(entryID)
+}
+
+func (pRM *PollResponseRepository) CountByEntry(entryID string) (error, int) {
+	pRM.CountByEntryFnInvoked = true
+	return pRM.CountByEntryFn(entryID)
+}
+
+func (pRM *PollResponseRepository) DeleteByEntry(entryID string) error {
+	pRM.DeleteByEntryFnInvoked = true
+	return pRM.DeleteByEntryFn(entryID)
+}
+
+func (pRM *PollResponseRepository) DeleteByCourse(courseID string) error {
+	pRM.DeleteByCourseFnInvoked = true
+	return pRM.DeleteByCourseFn(courseID)
+}
+
+// MaterialFolderRepository implements the eduboard.MaterialFolderRepository interface to mock functions and record successful invocations.
+type MaterialFolderRepository struct {
+	InsertFn        func(folder eduboard.MaterialFolder) error
+	InsertFnInvoked bool
+
+	FindOneByIDFn        func(id string) (error, eduboard.MaterialFolder)
+	FindOneByIDFnInvoked bool
+
+	FindByCourseFn        func(courseID string) (error, []eduboard.MaterialFolder)
+	FindByCourseFnInvoked bool
+
+	UpdateFn        func(id string, update bson.M) (error, eduboard.MaterialFolder)
+	UpdateFnInvoked bool
+
+	DeleteFn        func(ids []bson.ObjectId) error
+	DeleteFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.MaterialFolderRepository = (*MaterialFolderRepository)(nil)
+
+func (mRM *MaterialFolderRepository) Insert(folder eduboard.MaterialFolder) error {
+	mRM.InsertFnInvoked = true
+	return mRM.InsertFn(folder)
+}
+
+func (mRM *MaterialFolderRepository) FindOneByID(id string) (error, eduboard.MaterialFolder) {
+	mRM.FindOneByIDFnInvoked = true
+	return mRM.FindOneByIDFn(id)
+}
+
+func (mRM *MaterialFolderRepository) FindByCourse(courseID string) (error, []eduboard.MaterialFolder) {
+	mRM.FindByCourseFnInvoked = true
+	return mRM.FindByCourseFn(courseID)
+}
+
+func (mRM *MaterialFolderRepository) Update(id string, update bson.M) (error, eduboard.MaterialFolder) {
+	mRM.UpdateFnInvoked = true
+	return mRM.UpdateFn(id, update)
+}
+
+func (mRM *MaterialFolderRepository) Delete(ids []bson.ObjectId) error {
+	mRM.DeleteFnInvoked = true
+	return mRM.DeleteFn(ids)
+}
+
+func (mRM *MaterialFolderRepository) DeleteByCourse(courseID string) error {
+	mRM.DeleteByCourseFnInvoked = true
+	return mRM.DeleteByCourseFn(courseID)
+}
+
+// MaterialRepository implements the eduboard.MaterialRepository interface to mock functions and record successful invocations.
+type MaterialRepository struct {
+	InsertFn        func(material eduboard.Material) error
+	InsertFnInvoked bool
+
+	FindOneByIDFn        func(id string) (error, eduboard.Material)
+	FindOneByIDFnInvoked bool
+
+	FindByCourseFn        func(courseID string) (error, []eduboard.Material)
+	FindByCourseFnInvoked bool
+
+	UpdateFn        func(id string, update bson.M) (error, eduboard.Material)
+	UpdateFnInvoked bool
+
+	AddVersionFn        func(id string, version eduboard.MaterialVersion) (error, eduboard.Material)
+	AddVersionFnInvoked bool
+
+	DeleteFn        func(id string) error
+	DeleteFnInvoked bool
+
+	DeleteByFoldersFn        func(folderIDs []bson.ObjectId) error
+	DeleteByFoldersFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.MaterialRepository = (*MaterialRepository)(nil)
+
+func (mRM *MaterialRepository) Insert(material eduboard.Material) error {
+	mRM.InsertFnInvoked = true
+	return mRM.InsertFn(material)
+}
+
+func (mRM *MaterialRepository) FindOneByID(id string) (error, eduboard.Material) {
+	mRM.FindOneByIDFnInvoked = true
+	return mRM.FindOneByIDFn(id)
+}
+
+func (mRM *MaterialRepository) FindByCourse(courseID string) (error, []eduboard.Material) {
+	mRM.FindByCourseFnInvoked = true
+	return mRM.FindByCourseFn(courseID)
+}
+
+func (mRM *MaterialRepository) Update(id string, update bson.M) (error, eduboard.Material) {
+	mRM.UpdateFnInvoked = true
+	return mRM.UpdateFn(id, update)
+}
+
+func (mRM *MaterialRepository) AddVersion(id string, version eduboard.MaterialVersion) (error, eduboard.Material) {
+	mRM.AddVersionFnInvoked = true
+	return mRM.AddVersionFn(id, version)
+}
+
+func (mRM *MaterialRepository) Delete(id string) error {
+	mRM.DeleteFnInvoked = true
+	return mRM.DeleteFn(id)
+}
+
+func (mRM *MaterialRepository) DeleteByFolders(folderIDs []bson.ObjectId) error {
+	mRM.DeleteByFoldersFnInvoked = true
+	return mRM.DeleteByFoldersFn(folderIDs)
+}
+
+func (mRM *MaterialRepository) DeleteByCourse(courseID string) error {
+	mRM.DeleteByCourseFnInvoked = true
+	return mRM.DeleteByCourseFn(courseID)
+}
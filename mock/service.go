@@ -2,14 +2,17 @@ package mock
 
 import (
 	"github.com/eduboard/backend"
+	"io"
+	"net/url"
+	"time"
 )
 
 type CourseService struct {
-	CourseFn        func(id string, cef eduboard.CourseEntryManyFinder) (err error, course eduboard.Course)
+	CourseFn        func(id string, userID string, cef eduboard.CourseEntryManyFinder) (err error, course eduboard.Course)
 	CourseFnInvoked bool
 
-	CoursesFn        func() (err error, courses []eduboard.Course)
-	CoursesFnInvoked bool
+	SearchCoursesFn        func(query eduboard.CourseQuery) (error, eduboard.CoursePage)
+	SearchCoursesFnInvoked bool
 
 	GetCoursesByMemberFn        func(id string, cef eduboard.CourseEntryManyFinder) (error, []eduboard.Course)
 	GetCoursesByMemberFnInvoked bool
@@ -17,31 +20,46 @@ type CourseService struct {
 	GetMembersFn        func(course string, uF eduboard.UserFinder) (error, []eduboard.User)
 	GetMembersFnInvoked bool
 
-	AddMembersFn        func(course string, members []string) (error, eduboard.Course)
+	AddMembersFn        func(course string, userID string, members []eduboard.Member) (error, eduboard.Course)
 	AddMembersFnInvoked bool
 
-	RemoveMembersFn        func(course string, members []string) (error, eduboard.Course)
+	JoinCourseFn        func(course string, userID string) (error, eduboard.Course)
+	JoinCourseFnInvoked bool
+
+	RemoveMembersFn        func(course string, userID string, members []string) (error, eduboard.Course)
 	RemoveMembersFnInvoked bool
 
-	CreateCourseFn        func(c *eduboard.Course) (*eduboard.Course, error)
+	CreateCourseFn        func(c *eduboard.Course, ownerID string) (*eduboard.Course, error)
 	CreateCourseFnInvoked bool
+
+	UpdateCourseFn        func(id string, userID string, update eduboard.CourseUpdate, sc eduboard.ScheduleChecker) (error, eduboard.Course)
+	UpdateCourseFnInvoked bool
+
+	ArchiveCourseFn        func(id string, userID string) (error, eduboard.Course)
+	ArchiveCourseFnInvoked bool
+
+	RestoreCourseFn        func(id string, userID string, sc eduboard.ScheduleChecker) (error, eduboard.Course)
+	RestoreCourseFnInvoked bool
+
+	DeleteCourseFn        func(id string, userID string, ced eduboard.CourseEntryDeleter, cd eduboard.CommentDeleter, ucr eduboard.UserCourseRemover) error
+	DeleteCourseFnInvoked bool
 }
 
 var _ eduboard.CourseService = (*CourseService)(nil)
 
-func (cSM *CourseService) GetCourse(id string, cef eduboard.CourseEntryManyFinder) (err error, course eduboard.Course) {
+func (cSM *CourseService) GetCourse(id string, userID string, cef eduboard.CourseEntryManyFinder) (err error, course eduboard.Course) {
 	cSM.CourseFnInvoked = true
-	return cSM.CourseFn(id, cef)
+	return cSM.CourseFn(id, userID, cef)
 }
 
-func (cSM *CourseService) GetAllCourses() (err error, courses []eduboard.Course) {
-	cSM.CoursesFnInvoked = true
-	return cSM.CoursesFn()
+func (cSM *CourseService) SearchCourses(query eduboard.CourseQuery) (error, eduboard.CoursePage) {
+	cSM.SearchCoursesFnInvoked = true
+	return cSM.SearchCoursesFn(query)
 }
 
-func (cSM *CourseService) CreateCourse(c *eduboard.Course) (*eduboard.Course, error) {
+func (cSM *CourseService) CreateCourse(c *eduboard.Course, ownerID string) (*eduboard.Course, error) {
 	cSM.CreateCourseFnInvoked = true
-	return cSM.CreateCourseFn(c)
+	return cSM.CreateCourseFn(c, ownerID)
 }
 
 func (cSM *CourseService) GetCoursesByMember(id string, cef eduboard.CourseEntryManyFinder) (error, []eduboard.Course) {
@@ -54,40 +72,243 @@ func (cSM *CourseService) GetMembers(course string, uF eduboard.UserFinder) (err
 	return cSM.GetMembersFn(course, uF)
 }
 
-func (cSM *CourseService) AddMembers(course string, members []string) (error, eduboard.Course) {
+func (cSM *CourseService) AddMembers(course string, userID string, members []eduboard.Member) (error, eduboard.Course) {
 	cSM.AddMembersFnInvoked = true
-	return cSM.AddMembersFn(course, members)
+	return cSM.AddMembersFn(course, userID, members)
+}
+
+func (cSM *CourseService) JoinCourse(course string, userID string) (error, eduboard.Course) {
+	cSM.JoinCourseFnInvoked = true
+	return cSM.JoinCourseFn(course, userID)
 }
 
-func (cSM *CourseService) RemoveMembers(course string, members []string) (error, eduboard.Course) {
+func (cSM *CourseService) RemoveMembers(course string, userID string, members []string) (error, eduboard.Course) {
 	cSM.RemoveMembersFnInvoked = true
-	return cSM.RemoveMembersFn(course, members)
+	return cSM.RemoveMembersFn(course, userID, members)
+}
+
+func (cSM *CourseService) UpdateCourse(id string, userID string, update eduboard.CourseUpdate, sc eduboard.ScheduleChecker) (error, eduboard.Course) {
+	cSM.UpdateCourseFnInvoked = true
+	return cSM.UpdateCourseFn(id, userID, update, sc)
+}
+
+func (cSM *CourseService) ArchiveCourse(id string, userID string) (error, eduboard.Course) {
+	cSM.ArchiveCourseFnInvoked = true
+	return cSM.ArchiveCourseFn(id, userID)
+}
+
+func (cSM *CourseService) RestoreCourse(id string, userID string, sc eduboard.ScheduleChecker) (error, eduboard.Course) {
+	cSM.RestoreCourseFnInvoked = true
+	return cSM.RestoreCourseFn(id, userID, sc)
+}
+
+func (cSM *CourseService) DeleteCourse(id string, userID string, ced eduboard.CourseEntryDeleter, cd eduboard.CommentDeleter, ucr eduboard.UserCourseRemover) error {
+	cSM.DeleteCourseFnInvoked = true
+	return cSM.DeleteCourseFn(id, userID, ced, cd, ucr)
+}
+
+type ScheduleService struct {
+	GetSchedulesFn        func(courseID string) (error, []eduboard.Schedule)
+	GetSchedulesFnInvoked bool
+
+	AddScheduleFn        func(courseID string, userID string, schedule eduboard.Schedule) (error, eduboard.Schedule)
+	AddScheduleFnInvoked bool
+
+	UpdateScheduleFn        func(courseID string, scheduleID string, userID string, schedule eduboard.Schedule) (error, eduboard.Schedule)
+	UpdateScheduleFnInvoked bool
+
+	DeleteScheduleFn        func(courseID string, scheduleID string, userID string) error
+	DeleteScheduleFnInvoked bool
+
+	SetExceptionFn        func(courseID string, scheduleID string, userID string, exception eduboard.ScheduleException) (error, eduboard.Schedule)
+	SetExceptionFnInvoked bool
+
+	DeleteExceptionFn        func(courseID string, scheduleID string, userID string, date string) (error, eduboard.Schedule)
+	DeleteExceptionFnInvoked bool
+
+	GetOccurrencesFn        func(courseID string, from time.Time, to time.Time) (error, []eduboard.Occurrence)
+	GetOccurrencesFnInvoked bool
+
+	GetOccurrenceFn        func(courseID string, scheduleID string, date string) (error, eduboard.Occurrence)
+	GetOccurrenceFnInvoked bool
+
+	ExportCalendarFn        func(name string, courses []eduboard.Course) (error, []byte)
+	ExportCalendarFnInvoked bool
+
+	GetTimetableFn        func(courses []eduboard.Course, from time.Time, to time.Time) (error, []eduboard.TimetableEntry)
+	GetTimetableFnInvoked bool
+
+	GetRoomOccupancyFn        func(roomID string, from time.Time, to time.Time) (error, []eduboard.Occurrence)
+	GetRoomOccupancyFnInvoked bool
+
+	CheckSchedulesFn        func(course eduboard.Course, schedules []eduboard.Schedule, store func() error) error
+	CheckSchedulesFnInvoked bool
+
+	CheckRestoreFn        func(course eduboard.Course, store func() error) error
+	CheckRestoreFnInvoked bool
+}
+
+var _ eduboard.ScheduleService = (*ScheduleService)(nil)
+
+func (sSM *ScheduleService) GetSchedules(courseID string) (error, []eduboard.Schedule) {
+	sSM.GetSchedulesFnInvoked = true
+	return sSM.GetSchedulesFn(courseID)
+}
+
+func (sSM *ScheduleService) AddSchedule(courseID string, userID string, schedule eduboard.Schedule) (error, eduboard.Schedule) {
+	sSM.AddScheduleFnInvoked = true
+	return sSM.AddScheduleFn(courseID, userID, schedule)
+}
+
+func (sSM *ScheduleService) UpdateSchedule(courseID string, scheduleID string, userID string, schedule eduboard.Schedule) (error, eduboard.Schedule) {
+	sSM.UpdateScheduleFnInvoked = true
+	return sSM.UpdateScheduleFn(courseID, scheduleID, userID, schedule)
+}
+
+func (sSM *ScheduleService) DeleteSchedule(courseID string, scheduleID string, userID string) error {
+	sSM.DeleteScheduleFnInvoked = true
+	return sSM.DeleteScheduleFn(courseID, scheduleID, userID)
+}
+
+func (sSM *ScheduleService) SetException(courseID string, scheduleID string, userID string, exception eduboard.ScheduleException) (error, eduboard.Schedule) {
+	sSM.SetExceptionFnInvoked = true
+	return sSM.SetExceptionFn(courseID, scheduleID, userID, exception)
+}
+
+func (sSM *ScheduleService) DeleteException(courseID string, scheduleID string, userID string, date string) (error, eduboard.Schedule) {
+	sSM.DeleteExceptionFnInvoked = true
+	return sSM.DeleteExceptionFn(courseID, scheduleID, userID, date)
+}
+
+func (sSM *ScheduleService) GetOccurrences(courseID string, from time.Time, to time.Time) (error, []eduboard.Occurrence) {
+	sSM.GetOccurrencesFnInvoked = true
+	return sSM.GetOccurrencesFn(courseID, from, to)
+}
+
+func (sSM *ScheduleService) GetOccurrence(courseID string, scheduleID string, date string) (error, eduboard.Occurrence) {
+	sSM.GetOccurrenceFnInvoked = true
+	return sSM.GetOccurrenceFn(courseID, scheduleID, date)
+}
+
+func (sSM *ScheduleService) ExportCalendar(name string, courses []eduboard.Course) (error, []byte) {
+	sSM.ExportCalendarFnInvoked = true
+	return sSM.ExportCalendarFn(name, courses)
+}
+
+func (sSM *ScheduleService) GetTimetable(courses []eduboard.Course, from time.Time, to time.Time) (error, []eduboard.TimetableEntry) {
+	sSM.GetTimetableFnInvoked = true
+	return sSM.GetTimetableFn(courses, from, to)
+}
+
+func (sSM *ScheduleService) GetRoomOccupancy(roomID string, from time.Time, to time.Time) (error, []eduboard.Occurrence) {
+	sSM.GetRoomOccupancyFnInvoked = true
+	return sSM.GetRoomOccupancyFn(roomID, from, to)
+}
+
+func (sSM *ScheduleService) CheckSchedules(course eduboard.Course, schedules []eduboard.Schedule, store func() error) error {
+	sSM.CheckSchedulesFnInvoked = true
+	return sSM.CheckSchedulesFn(course, schedules, store)
+}
+
+func (sSM *ScheduleService) CheckRestore(course eduboard.Course, store func() error) error {
+	sSM.CheckRestoreFnInvoked = true
+	return sSM.CheckRestoreFn(course, store)
 }
 
 type CourseEntryService struct {
-	StoreCourseEntryFn        func(entry *eduboard.CourseEntry, cfu eduboard.CourseFindUpdater) (err error, courseEntry *eduboard.CourseEntry)
+	StoreCourseEntryFn        func(entry *eduboard.CourseEntry, userID string, cfu eduboard.CourseFindUpdater) (err error, courseEntry *eduboard.CourseEntry)
 	StoreCourseEntryFnInvoked bool
 
-	UpdateCourseEntryFn        func(*eduboard.CourseEntry) (*eduboard.CourseEntry, error)
+	UpdateCourseEntryFn        func(entryID string, courseID string, userID string, update eduboard.CourseEntryUpdate, cf eduboard.CourseOneFinder) (*eduboard.CourseEntry, error)
 	UpdateCourseEntryFnInvoked bool
 
-	DeleteCourseEntryFn        func(entryID string, courseID string, updater eduboard.CourseUpdater) error
+	DeleteCourseEntryFn        func(entryID string, courseID string, userID string, cfu eduboard.CourseFindUpdater, cd eduboard.CommentDeleter, pd eduboard.PollResponseDeleter) error
 	DeleteCourseEntryFnInvoked bool
+
+	GetCourseEntriesFn        func(courseID string, userID string, filter eduboard.CourseEntryFilter, cf eduboard.CourseOneFinder) (error, eduboard.CourseEntryPage)
+	GetCourseEntriesFnInvoked bool
+
+	GetFeedFn        func(userID string, filter eduboard.CourseEntryFilter, cmf eduboard.CourseManyFinder) (error, eduboard.CourseEntryPage)
+	GetFeedFnInvoked bool
+
+	PublishDueFn        func(now time.Time, cf eduboard.CourseOneFinder) (error, []eduboard.CourseEntry)
+	PublishDueFnInvoked bool
 }
 
-func (cSM *CourseEntryService) StoreCourseEntry(entry *eduboard.CourseEntry, cfu eduboard.CourseFindUpdater) (err error, courseEntry *eduboard.CourseEntry) {
+var _ eduboard.CourseEntryService = (*CourseEntryService)(nil)
+
+func (cSM *CourseEntryService) StoreCourseEntry(entry *eduboard.CourseEntry, userID string, cfu eduboard.CourseFindUpdater) (err error, courseEntry *eduboard.CourseEntry) {
 	cSM.StoreCourseEntryFnInvoked = true
-	return cSM.StoreCourseEntryFn(entry, cfu)
+	return cSM.StoreCourseEntryFn(entry, userID, cfu)
 }
 
-func (cSM *CourseEntryService) UpdateCourseEntry(e *eduboard.CourseEntry) (*eduboard.CourseEntry, error) {
+func (cSM *CourseEntryService) UpdateCourseEntry(entryID string, courseID string, userID string, update eduboard.CourseEntryUpdate, cf eduboard.CourseOneFinder) (*eduboard.CourseEntry, error) {
 	cSM.UpdateCourseEntryFnInvoked = true
-	return cSM.UpdateCourseEntryFn(e)
+	return cSM.UpdateCourseEntryFn(entryID, courseID, userID, update, cf)
 }
 
-func (cSM *CourseEntryService) DeleteCourseEntry(entryID string, courseID string, updater eduboard.CourseUpdater) error {
+func (cSM *CourseEntryService) DeleteCourseEntry(entryID string, courseID string, userID string, cfu eduboard.CourseFindUpdater, cd eduboard.CommentDeleter, pd eduboard.PollResponseDeleter) error {
 	cSM.DeleteCourseEntryFnInvoked = true
-	return cSM.DeleteCourseEntryFn(entryID, courseID, updater)
+	return cSM.DeleteCourseEntryFn(entryID, courseID, userID, cfu, cd, pd)
+}
+
+func (cSM *CourseEntryService) GetCourseEntries(courseID string, userID string, filter eduboard.CourseEntryFilter, cf eduboard.CourseOneFinder) (error, eduboard.CourseEntryPage) {
+	cSM.GetCourseEntriesFnInvoked = true
+	return cSM.GetCourseEntriesFn(courseID, userID, filter, cf)
+}
+
+func (cSM *CourseEntryService) GetFeed(userID string, filter eduboard.CourseEntryFilter, cmf eduboard.CourseManyFinder) (error, eduboard.CourseEntryPage) {
+	cSM.GetFeedFnInvoked = true
+	return cSM.GetFeedFn(userID, filter, cmf)
+}
+
+func (cSM *CourseEntryService) PublishDue(now time.Time, cf eduboard.CourseOneFinder) (error, []eduboard.CourseEntry) {
+	cSM.PublishDueFnInvoked = true
+	return cSM.PublishDueFn(now, cf)
+}
+
+type RoomService struct {
+	CreateRoomFn        func(room *eduboard.Room, userID string) (error, eduboard.Room)
+	CreateRoomFnInvoked bool
+
+	GetRoomsFn        func() (error, []eduboard.Room)
+	GetRoomsFnInvoked bool
+
+	GetRoomFn        func(id string) (error, eduboard.Room)
+	GetRoomFnInvoked bool
+
+	UpdateRoomFn        func(id string, userID string, update eduboard.RoomUpdate) (error, eduboard.Room)
+	UpdateRoomFnInvoked bool
+
+	DeleteRoomFn        func(id string, userID string) error
+	DeleteRoomFnInvoked bool
+}
+
+var _ eduboard.RoomService = (*RoomService)(nil)
+
+func (rSM *RoomService) CreateRoom(room *eduboard.Room, userID string) (error, eduboard.Room) {
+	rSM.CreateRoomFnInvoked = true
+	return rSM.CreateRoomFn(room, userID)
+}
+
+func (rSM *RoomService) GetRooms() (error, []eduboard.Room) {
+	rSM.GetRoomsFnInvoked = true
+	return rSM.GetRoomsFn()
+}
+
+func (rSM *RoomService) GetRoom(id string) (error, eduboard.Room) {
+	rSM.GetRoomFnInvoked = true
+	return rSM.GetRoomFn(id)
+}
+
+func (rSM *RoomService) UpdateRoom(id string, userID string, update eduboard.RoomUpdate) (error, eduboard.Room) {
+	rSM.UpdateRoomFnInvoked = true
+	return rSM.UpdateRoomFn(id, userID, update)
+}
+
+func (rSM *RoomService) DeleteRoom(id string, userID string) error {
+	rSM.DeleteRoomFnInvoked = true
+	return rSM.DeleteRoomFn(id, userID)
 }
 
 type UserService struct {
@@ -100,10 +321,16 @@ type UserService struct {
 	GetAllUsersFn        func() ([]eduboard.User, error)
 	GetAllUsersFnInvoked bool
 
-	GetMyCoursesFn        func(id string, cBMF eduboard.CourseManyFinder, cEMF eduboard.CourseEntryManyFinder) (error, []eduboard.Course)
+	GetMyCoursesFn        func(id string, viewerID string, cBMF eduboard.CourseManyFinder, cEMF eduboard.CourseEntryManyFinder) (error, []eduboard.Course)
 	GetMyCoursesFnInvoked bool
 
+	UpdatePictureFn        func(id string, picture url.URL) error
+	UpdatePictureFnInvoked bool
+
 	UserAuthenticationProvider
+	PasswordResetter
+	EmailVerifier
+	CalendarTokenProvider
 }
 
 var _ eduboard.UserService = (*UserService)(nil)
@@ -123,36 +350,282 @@ func (uSM *UserService) GetAllUsers() ([]eduboard.User, error) {
 	return uSM.GetAllUsersFn()
 }
 
-func (uSM *UserService) GetMyCourses(id string, cBMF eduboard.CourseManyFinder, cEMF eduboard.CourseEntryManyFinder) (error, []eduboard.Course) {
+func (uSM *UserService) GetMyCourses(id string, viewerID string, cBMF eduboard.CourseManyFinder, cEMF eduboard.CourseEntryManyFinder) (error, []eduboard.Course) {
 	uSM.GetMyCoursesFnInvoked = true
-	return uSM.GetMyCoursesFn(id, cBMF, cEMF)
+	return uSM.GetMyCoursesFn(id, viewerID, cBMF, cEMF)
+}
+
+func (uSM *UserService) UpdatePicture(id string, picture url.URL) error {
+	uSM.UpdatePictureFnInvoked = true
+	return uSM.UpdatePictureFn(id, picture)
 }
 
 type UserAuthenticationProvider struct {
-	LoginFn        func(email string, password string) (error, eduboard.User)
+	LoginFn        func(email string, password string, userAgent string) (error, eduboard.User, eduboard.Session)
 	LoginFnInvoked bool
 
-	LogoutFn        func(sessionID string) error
+	LogoutFn        func(token string) error
 	LogoutFnInvoked bool
 
-	CheckAuthenticationFn        func(sessionID string) (err error, userID string)
+	CreateSessionFn        func(userID string, userAgent string) (error, eduboard.Session)
+	CreateSessionFnInvoked bool
+
+	CheckAuthenticationFn        func(token string) (err error, session eduboard.Session)
 	CheckAuthenticationFnInvoked bool
+
+	GetSessionsFn        func(userID string) (error, []eduboard.Session)
+	GetSessionsFnInvoked bool
+
+	RevokeSessionFn        func(userID string, sessionID string) error
+	RevokeSessionFnInvoked bool
 }
 
 var _ eduboard.UserAuthenticationProvider = (*UserAuthenticationProvider)(nil)
 
-func (uAM *UserAuthenticationProvider) Login(email string, password string) (error, eduboard.User) {
+func (uAM *UserAuthenticationProvider) Login(email string, password string, userAgent string) (error, eduboard.User, eduboard.Session) {
 	uAM.LoginFnInvoked = true
-	return uAM.LoginFn(email, password)
+	return uAM.LoginFn(email, password, userAgent)
 }
 
-func (uAM *UserAuthenticationProvider) Logout(sessionID string) error {
+func (uAM *UserAuthenticationProvider) Logout(token string) error {
 	uAM.LogoutFnInvoked = true
-	return uAM.LogoutFn(sessionID)
+	return uAM.LogoutFn(token)
 }
-func (uAM *UserAuthenticationProvider) CheckAuthentication(sessionID string) (err error, userID string) {
+
+func (uAM *UserAuthenticationProvider) CreateSession(userID string, userAgent string) (error, eduboard.Session) {
+	uAM.CreateSessionFnInvoked = true
+	return uAM.CreateSessionFn(userID, userAgent)
+}
+
+func (uAM *UserAuthenticationProvider) CheckAuthentication(token string) (err error, session eduboard.Session) {
 	uAM.CheckAuthenticationFnInvoked = true
-	return uAM.CheckAuthenticationFn(sessionID)
+	return uAM.CheckAuthenticationFn(token)
+}
+
+func (uAM *UserAuthenticationProvider) GetSessions(userID string) (error, []eduboard.Session) {
+	uAM.GetSessionsFnInvoked = true
+	return uAM.GetSessionsFn(userID)
+}
+
+func (uAM *UserAuthenticationProvider) RevokeSession(userID string, sessionID string) error {
+	uAM.RevokeSessionFnInvoked = true
+	return uAM.RevokeSessionFn(userID, sessionID)
+}
+
+type PasswordResetter struct {
+	RequestPasswordResetFn        func(email string) error
+	RequestPasswordResetFnInvoked bool
+
+	ResetPasswordFn        func(token string, password string) error
+	ResetPasswordFnInvoked bool
+}
+
+var _ eduboard.PasswordResetter = (*PasswordResetter)(nil)
+
+func (pRM *PasswordResetter) RequestPasswordReset(email string) error {
+	pRM.RequestPasswordResetFnInvoked = true
+	return pRM.RequestPasswordResetFn(email)
+}
+
+func (pRM *PasswordResetter) ResetPassword(token string, password string) error {
+	pRM.ResetPasswordFnInvoked = true
+	return pRM.ResetPasswordFn(token, password)
+}
+
+type EmailVerifier struct {
+	RequestVerificationFn        func(userID string) error
+	RequestVerificationFnInvoked bool
+
+	VerifyEmailFn        func(token string) (error, string)
+	VerifyEmailFnInvoked bool
+}
+
+var _ eduboard.EmailVerifier = (*EmailVerifier)(nil)
+
+func (eVM *EmailVerifier) RequestVerification(userID string) error {
+	eVM.RequestVerificationFnInvoked = true
+	return eVM.RequestVerificationFn(userID)
+}
+
+func (eVM *EmailVerifier) VerifyEmail(token string) (error, string) {
+	eVM.VerifyEmailFnInvoked = true
+	return eVM.VerifyEmailFn(token)
+}
+
+type CalendarTokenProvider struct {
+	CreateCalendarTokenFn        func(userID string) (error, string)
+	CreateCalendarTokenFnInvoked bool
+
+	RevokeCalendarTokenFn        func(userID string) error
+	RevokeCalendarTokenFnInvoked bool
+
+	FindByCalendarTokenFn        func(token string) (error, eduboard.User)
+	FindByCalendarTokenFnInvoked bool
+}
+
+var _ eduboard.CalendarTokenProvider = (*CalendarTokenProvider)(nil)
+
+func (cTM *CalendarTokenProvider) CreateCalendarToken(userID string) (error, string) {
+	cTM.CreateCalendarTokenFnInvoked = true
+	return cTM.CreateCalendarTokenFn(userID)
+}
+
+func (cTM *CalendarTokenProvider) RevokeCalendarToken(userID string) error {
+	cTM.RevokeCalendarTokenFnInvoked = true
+	return cTM.RevokeCalendarTokenFn(userID)
+}
+
+func (cTM *CalendarTokenProvider) FindByCalendarToken(token string) (error, eduboard.User) {
+	cTM.FindByCalendarTokenFnInvoked = true
+	return cTM.FindByCalendarTokenFn(token)
+}
+
+type UploadService struct {
+	StoreUploadFn        func(upload *eduboard.Upload, content io.Reader, cf eduboard.CourseOneFinder) (error, eduboard.Upload)
+	StoreUploadFnInvoked bool
+
+	OpenUploadFn        func(id string, userID string, thumbnail bool, cf eduboard.CourseOneFinder) (error, eduboard.Upload, io.ReadCloser)
+	OpenUploadFnInvoked bool
+}
+
+var _ eduboard.UploadService = (*UploadService)(nil)
+
+func (uSM *UploadService) StoreUpload(upload *eduboard.Upload, content io.Reader, cf eduboard.CourseOneFinder) (error, eduboard.Upload) {
+	uSM.StoreUploadFnInvoked = true
+	return uSM.StoreUploadFn(upload, content, cf)
+}
+
+func (uSM *UploadService) OpenUpload(id string, userID string, thumbnail bool, cf eduboard.CourseOneFinder) (error, eduboard.Upload, io.ReadCloser) {
+	uSM.OpenUploadFnInvoked = true
+	return uSM.OpenUploadFn(id, userID, thumbnail, cf)
+}
+
+type Notifier struct {
+	NotifyPasswordResetFn        func(user eduboard.User, token string, expires time.Time) error
+	NotifyPasswordResetFnInvoked bool
+
+	NotifyVerificationFn        func(user eduboard.User, token string, expires time.Time) error
+	NotifyVerificationFnInvoked bool
+
+	NotifyEntryPublishedFn        func(user eduboard.User, course eduboard.Course, entry eduboard.CourseEntry) error
+	NotifyEntryPublishedFnInvoked bool
+
+	NotifyInviteFn        func(email string, course eduboard.Course, token string, expires time.Time) error
+	NotifyInviteFnInvoked bool
+}
+
+var _ eduboard.Notifier = (*Notifier)(nil)
+
+func (nM *Notifier) NotifyPasswordReset(user eduboard.User, token string, expires time.Time) error {
+	nM.NotifyPasswordResetFnInvoked = true
+	return nM.NotifyPasswordResetFn(user, token, expires)
+}
+
+func (nM *Notifier) NotifyVerification(user eduboard.User, token string, expires time.Time) error {
+	nM.NotifyVerificationFnInvoked = true
+	return nM.NotifyVerificationFn(user, token, expires)
+}
+
+func (nM *Notifier) NotifyEntryPublished(user eduboard.User, course eduboard.Course, entry eduboard.CourseEntry) error {
+	nM.NotifyEntryPublishedFnInvoked = true
+	return nM.NotifyEntryPublishedFn(user, course, entry)
+}
+
+func (nM *Notifier) NotifyInvite(email string, course eduboard.Course, token string, expires time.Time) error {
+	nM.NotifyInviteFnInvoked = true
+	return nM.NotifyInviteFn(email, course, token, expires)
+}
+
+type CommentService struct {
+	GetCommentsFn        func(courseID string, entryID string, userID string, cursor string, limit int, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.CommentPage)
+	GetCommentsFnInvoked bool
+
+	CreateCommentFn        func(courseID string, entryID string, userID string, comment eduboard.Comment, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.Comment)
+	CreateCommentFnInvoked bool
+
+	UpdateCommentFn        func(courseID string, entryID string, commentID string, userID string, message string, cf eduboard.CourseOneFinder) (error, eduboard.Comment)
+	UpdateCommentFnInvoked bool
+
+	DeleteCommentFn        func(courseID string, entryID string, commentID string, userID string, cf eduboard.CourseOneFinder) error
+	DeleteCommentFnInvoked bool
+}
+
+var _ eduboard.CommentService = (*CommentService)(nil)
+
+func (cSM *CommentService) GetComments(courseID string, entryID string, userID string, cursor string, limit int, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.CommentPage) {
+	cSM.GetCommentsFnInvoked = true
+	return cSM.GetCommentsFn(courseID, entryID, userID, cursor, limit, cf, ef)
+}
+
+func (cSM *CommentService) CreateComment(courseID string, entryID string, userID string, comment eduboard.Comment, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.Comment) {
+	cSM.CreateCommentFnInvoked = true
+	return cSM.CreateCommentFn(courseID, entryID, userID, comment, cf, ef)
+}
+
+func (cSM *CommentService) UpdateComment(courseID string, entryID string, commentID string, userID string, message string, cf eduboard.CourseOneFinder) (error, eduboard.Comment) {
+	cSM.UpdateCommentFnInvoked = true
+	return cSM.UpdateCommentFn(courseID, entryID, commentID, userID, message, cf)
+}
+
+func (cSM *CommentService) DeleteComment(courseID string, entryID string, commentID string, userID string, cf eduboard.CourseOneFinder) error {
+	cSM.DeleteCommentFnInvoked = true
+	return cSM.DeleteCommentFn(courseID, entryID, commentID, userID, cf)
+}
+
+type NotificationService struct {
+	CreateNotificationsFn        func(userIDs []string, notification eduboard.Notification)
+	CreateNotificationsFnInvoked bool
+
+	GetNotificationsFn        func(userID string, unread bool, limit int) (error, []eduboard.Notification)
+	GetNotificationsFnInvoked bool
+
+	MarkReadFn        func(id string, userID string) error
+	MarkReadFnInvoked bool
+
+	MarkAllReadFn        func(userID string) error
+	MarkAllReadFnInvoked bool
+
+	CountUnreadFn        func(userID string) (error, int)
+	CountUnreadFnInvoked bool
+}
+
+var _ eduboard.NotificationService = (*NotificationService)(nil)
+
+func (nSM *NotificationService) CreateNotifications(userIDs []string, notification eduboard.Notification) {
+	nSM.CreateNotificationsFnInvoked = true
+	nSM.CreateNotificationsFn(userIDs, notification)
+}
+
+func (nSM *NotificationService) GetNotifications(userID string, unread bool, limit int) (error, []eduboard.Notification) {
+	nSM.GetNotificationsFnInvoked = true
+	return nSM.GetNotificationsFn(userID, unread, limit)
+}
+
+func (nSM *NotificationService) MarkRead(id string, userID string) error {
+	nSM.MarkReadFnInvoked = true
+	return nSM.MarkReadFn(id, userID)
+}
+
+func (nSM *NotificationService) MarkAllRead(userID string) error {
+	nSM.MarkAllReadFnInvoked = true
+	return nSM.MarkAllReadFn(userID)
+}
+
+func (nSM *NotificationService) CountUnread(userID string) (error, int) {
+	nSM.CountUnreadFnInvoked = true
+	return nSM.CountUnreadFn(userID)
+}
+
+type EventPublisher struct {
+	PublishFn        func(event eduboard.Event)
+	PublishFnInvoked bool
+}
+
+var _ eduboard.EventPublisher = (*EventPublisher)(nil)
+
+func (eM *EventPublisher) Publish(event eduboard.Event) {
+	eM.PublishFnInvoked = true
+	eM.PublishFn(event)
 }
 
 type Authenticator interface {
@@ -187,3 +660,551 @@ func (uAM *AuthenticatorMock) SessionID() string {
 	uAM.SessionIDFnInvoked = true
 	return uAM.SessionIDFn()
 }
+
+type InviteService struct {
+	CreateInviteCodeFn        func(courseID string, userID string, expiresAt *time.Time, maxUses int) (error, eduboard.InviteCode)
+	CreateInviteCodeFnInvoked bool
+
+	GetInviteCodesFn        func(courseID string, userID string) (error, []eduboard.InviteCode)
+	GetInviteCodesFnInvoked bool
+
+	RevokeInviteCodeFn        func(courseID string, codeID string, userID string) error
+	RevokeInviteCodeFnInvoked bool
+
+	JoinFn        func(code string, userID string, cj eduboard.CourseJoiner) (error, eduboard.Course)
+	JoinFnInvoked bool
+
+	InviteEmailFn        func(courseID string, userID string, email string, cj eduboard.CourseJoiner) (error, eduboard.EmailInvite)
+	InviteEmailFnInvoked bool
+
+	GetEmailInvitesFn        func(courseID string, userID string) (error, []eduboard.EmailInvite)
+	GetEmailInvitesFnInvoked bool
+
+	RedeemEmailInviteFn        func(token string, userID string, cj eduboard.CourseJoiner) (error, eduboard.Course)
+	RedeemEmailInviteFnInvoked bool
+
+	JoinInvitedCoursesFn        func(userID string, cj eduboard.CourseJoiner) error
+	JoinInvitedCoursesFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.InviteService = (*InviteService)(nil)
+
+func (iSM *InviteService) CreateInviteCode(courseID string, userID string, expiresAt *time.Time, maxUses int) (error, eduboard.InviteCode) {
+	iSM.CreateInviteCodeFnInvoked = true
+	return iSM.CreateInviteCodeFn(courseID, userID, expiresAt, maxUses)
+}
+
+func (iSM *InviteService) GetInviteCodes(courseID string, userID string) (error, []eduboard.InviteCode) {
+	iSM.GetInviteCodesFnInvoked = true
+	return iSM.GetInviteCodesFn(courseID, userID)
+}
+
+func (iSM *InviteService) RevokeInviteCode(courseID string, codeID string, userID string) error {
+	iSM.RevokeInviteCodeFnInvoked = true
+	return iSM.RevokeInviteCodeFn(courseID, codeID, userID)
+}
+
+func (iSM *InviteService) Join(code string, userID string, cj eduboard.CourseJoiner) (error, eduboard.Course) {
+	iSM.JoinFnInvoked = true
+	return iSM.JoinFn(code, userID, cj)
+}
+
+func (iSM *InviteService) InviteEmail(courseID string, userID string, email string, cj eduboard.CourseJoiner) (error, eduboard.EmailInvite) {
+	iSM.InviteEmailFnInvoked = true
+	return iSM.InviteEmailFn(courseID, userID, email, cj)
+}
+
+func (iSM *InviteService) GetEmailInvites(courseID string, userID string) (error, []eduboard.EmailInvite) {
+	iSM.GetEmailInvitesFnInvoked = true
+	return iSM.GetEmailInvitesFn(courseID, userID)
+}
+
+func (iSM *InviteService) RedeemEmailInvite(token string, userID string, cj eduboard.CourseJoiner) (error, eduboard.Course) {
+	iSM.RedeemEmailInviteFnInvoked = true
+	return iSM.RedeemEmailInviteFn(token, userID, cj)
+}
+
+func (iSM *InviteService) JoinInvitedCourses(userID string, cj eduboard.CourseJoiner) error {
+	iSM.JoinInvitedCoursesFnInvoked = true
+	return iSM.JoinInvitedCoursesFn(userID, cj)
+}
+
+func (iSM *InviteService) DeleteByCourse(courseID string) error {
+	iSM.DeleteByCourseFnInvoked = true
+	return iSM.DeleteByCourseFn(courseID)
+}
+
+type EnrollmentService struct {
+	RequestEnrollmentFn        func(courseID string, userID string, message string, cj eduboard.CourseJoiner) (error, eduboard.EnrollmentRequest)
+	RequestEnrollmentFnInvoked bool
+
+	GetEnrollmentFn        func(courseID string, userID string) (error, eduboard.EnrollmentRequest)
+	GetEnrollmentFnInvoked bool
+
+	CancelEnrollmentFn        func(courseID string, userID string) error
+	CancelEnrollmentFnInvoked bool
+
+	GetPendingEnrollmentsFn        func(courseID string, userID string) (error, []eduboard.EnrollmentRequest)
+	GetPendingEnrollmentsFnInvoked bool
+
+	ApproveEnrollmentFn        func(courseID string, requestID string, userID string, cj eduboard.CourseJoiner) (error, eduboard.EnrollmentRequest)
+	ApproveEnrollmentFnInvoked bool
+
+	RejectEnrollmentFn        func(courseID string, requestID string, userID string) (error, eduboard.EnrollmentRequest)
+	RejectEnrollmentFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.EnrollmentService = (*EnrollmentService)(nil)
+
+func (eSM *EnrollmentService) RequestEnrollment(courseID string, userID string, message string, cj eduboard.CourseJoiner) (error, eduboard.EnrollmentRequest) {
+	eSM.RequestEnrollmentFnInvoked = true
+	return eSM.RequestEnrollmentFn(courseID, userID, message, cj)
+}
+
+func (eSM *EnrollmentService) GetEnrollment(courseID string, userID string) (error, eduboard.EnrollmentRequest) {
+	eSM.GetEnrollmentFnInvoked = true
+	return eSM.GetEnrollmentFn(courseID, userID)
+}
+
+func (eSM *EnrollmentService) CancelEnrollment(courseID string, userID string) error {
+	eSM.CancelEnrollmentFnInvoked = true
+	return eSM.CancelEnrollmentFn(courseID, userID)
+}
+
+func (eSM *EnrollmentService) GetPendingEnrollments(courseID string, userID string) (error, []eduboard.EnrollmentRequest) {
+	eSM.GetPendingEnrollmentsFnInvoked = true
+	return eSM.GetPendingEnrollmentsFn(courseID, userID)
+}
+
+func (eSM *EnrollmentService) ApproveEnrollment(courseID string, requestID string, userID string, cj eduboard.CourseJoiner) (error, eduboard.EnrollmentRequest) {
+	eSM.ApproveEnrollmentFnInvoked = true
+	return eSM.ApproveEnrollmentFn(courseID, requestID, userID, cj)
+}
+
+func (eSM *EnrollmentService) RejectEnrollment(courseID string, requestID string, userID string) (error, eduboard.EnrollmentRequest) {
+	eSM.RejectEnrollmentFnInvoked = true
+	return eSM.RejectEnrollmentFn(courseID, requestID, userID)
+}
+
+func (eSM *EnrollmentService) DeleteByCourse(courseID string) error {
+	eSM.DeleteByCourseFnInvoked = true
+	return eSM.DeleteByCourseFn(courseID)
+}
+
+type AssignmentService struct {
+	GetAssignmentsFn        func(courseID string, userID string) (error, []eduboard.Assignment)
+	GetAssignmentsFnInvoked bool
+
+	GetAssignmentFn        func(courseID string, assignmentID string, userID string) (error, eduboard.Assignment)
+	GetAssignmentFnInvoked bool
+
+	CreateAssignmentFn        func(courseID string, userID string, assignment eduboard.Assignment) (error, eduboard.Assignment)
+	CreateAssignmentFnInvoked bool
+
+	UpdateAssignmentFn        func(courseID string, assignmentID string, userID string, update eduboard.AssignmentUpdate) (error, eduboard.Assignment)
+	UpdateAssignmentFnInvoked bool
+
+	DeleteAssignmentFn        func(courseID string, assignmentID string, userID string) error
+	DeleteAssignmentFnInvoked bool
+
+	SubmitFn        func(courseID string, assignmentID string, userID string, submission eduboard.Submission) (error, eduboard.Submission)
+	SubmitFnInvoked bool
+
+	GetSubmissionFn        func(courseID string, assignmentID string, userID string) (error, eduboard.Submission)
+	GetSubmissionFnInvoked bool
+
+	GetSubmissionsFn        func(courseID string, assignmentID string, userID string) (error, []eduboard.Submission)
+	GetSubmissionsFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.AssignmentService = (*AssignmentService)(nil)
+
+func (aSM *AssignmentService) GetAssignments(courseID string, userID string) (error, []eduboard.Assignment) {
+	aSM.GetAssignmentsFnInvoked = true
+	return aSM.GetAssignmentsFn(courseID, userID)
+}
+
+func (aSM *AssignmentService) GetAssignment(courseID string, assignmentID string, userID string) (error, eduboard.Assignment) {
+	aSM.GetAssignmentFnInvoked = true
+	return aSM.GetAssignmentFn(courseID, assignmentID, userID)
+}
+
+func (aSM *AssignmentService) CreateAssignment(courseID string, userID string, assignment eduboard.Assignment) (error, eduboard.Assignment) {
+	aSM.CreateAssignmentFnInvoked = true
+	return aSM.CreateAssignmentFn(courseID, userID, assignment)
+}
+
+func (aSM *AssignmentService) UpdateAssignment(courseID string, assignmentID string, userID string, update eduboard.AssignmentUpdate) (error, eduboard.Assignment) {
+	aSM.UpdateAssignmentFnInvoked = true
+	return aSM.UpdateAssignmentFn(courseID, assignmentID, userID, update)
+}
+
+func (aSM *AssignmentService) DeleteAssignment(courseID string, assignmentID string, userID string) error {
+	aSM.DeleteAssignmentFnInvoked = true
+	return aSM.DeleteAssignmentFn(courseID, assignmentID, userID)
+}
+
+func (aSM *AssignmentService) Submit(courseID string, assignmentID string, userID string, submission eduboard.Submission) (error, eduboard.Submission) {
+	aSM.SubmitFnInvoked = true
+	return aSM.SubmitFn(courseID, assignmentID, userID, submission)
+}
+
+func (aSM *AssignmentService) GetSubmission(courseID string, assignmentID string, userID string) (error, eduboard.Submission) {
+	aSM.GetSubmissionFnInvoked = true
+	return aSM.GetSubmissionFn(courseID, assignmentID, userID)
+}
+
+func (aSM *AssignmentService) GetSubmissions(courseID string, assignmentID string, userID string) (error, []eduboard.Submission) {
+	aSM.GetSubmissionsFnInvoked = true
+	return aSM.GetSubmissionsFn(courseID, assignmentID, userID)
+}
+
+func (aSM *AssignmentService) DeleteByCourse(courseID string) error {
+	aSM.DeleteByCourseFnInvoked = true
+	return aSM.DeleteByCourseFn(courseID)
+}
+
+type GradebookService struct {
+	GetCategoriesFn        func(courseID string, userID string) (error, []eduboard.GradeCategory)
+	GetCategoriesFnInvoked bool
+
+	SetCategoriesFn        func(courseID string, userID string, categories []eduboard.GradeCategory) (error, []eduboard.GradeCategory)
+	SetCategoriesFnInvoked bool
+
+	SetGradeFn        func(courseID string, assignmentID string, studentID string, userID string, points float64, feedback string) (error, eduboard.Grade)
+	SetGradeFnInvoked bool
+
+	DeleteGradeFn        func(courseID string, assignmentID string, studentID string, userID string) error
+	DeleteGradeFnInvoked bool
+
+	GetGradebookFn        func(courseID string, userID string) (error, eduboard.Gradebook)
+	GetGradebookFnInvoked bool
+
+	ExportGradebookFn        func(courseID string, userID string, uf eduboard.UserFinder, w io.Writer) error
+	ExportGradebookFnInvoked bool
+
+	DeleteByAssignmentFn        func(assignmentID string) error
+	DeleteByAssignmentFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.GradebookService = (*GradebookService)(nil)
+
+func (gSM *GradebookService) GetCategories(courseID string, userID string) (error, []eduboard.GradeCategory) {
+	gSM.GetCategoriesFnInvoked = true
+	return gSM.GetCategoriesFn(courseID, userID)
+}
+
+func (gSM *GradebookService) SetCategories(courseID string, userID string, categories []eduboard.GradeCategory) (error, []eduboard.GradeCategory) {
+	gSM.SetCategoriesFnInvoked = true
+	return gSM.SetCategoriesFn(courseID, userID, categories)
+}
+
+func (gSM *GradebookService) SetGrade(courseID string, assignmentID string, studentID string, userID string, points float64, feedback string) (error, eduboard.Grade) {
+	gSM.SetGradeFnInvoked = true
+	return gSM.SetGradeFn(courseID, assignmentID, studentID, userID, points, feedback)
+}
+
+func (gSM *GradebookService) DeleteGrade(courseID string, assignmentID string, studentID string, userID string) error {
+	gSM.DeleteGradeFnInvoked = true
+	return gSM.DeleteGradeFn(courseID, assignmentID, studentID, userID)
+}
+
+func (gSM *GradebookService) GetGradebook(courseID string, userID string) (error, eduboard.Gradebook) {
+	gSM.GetGradebookFnInvoked = true
+	return gSM.GetGradebookFn(courseID, userID)
+}
+
+func (gSM *GradebookService) ExportGradebook(courseID string, userID string, uf eduboard.UserFinder, w io.Writer) error {
+	gSM.ExportGradebookFnInvoked = true
+	return gSM.ExportGradebookFn(courseID, userID, uf, w)
+}
+
+func (gSM *GradebookService) DeleteByAssignment(assignmentID string) error {
+	gSM.DeleteByAssignmentFnInvoked = true
+	return gSM.DeleteByAssignmentFn(assignmentID)
+}
+
+func (gSM *GradebookService) DeleteByCourse(courseID string) error {
+	gSM.DeleteByCourseFnInvoked = true
+	return gSM.DeleteByCourseFn(courseID)
+}
+
+type AttendanceService struct {
+	GetSessionsFn        func(courseID string, userID string) (error, []eduboard.AttendanceSession)
+	GetSessionsFnInvoked bool
+
+	GetSessionFn        func(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession)
+	GetSessionFnInvoked bool
+
+	OpenSessionFn        func(courseID string, scheduleID string, date string, userID string, of eduboard.OccurrenceFinder) (error, eduboard.AttendanceSession)
+	OpenSessionFnInvoked bool
+
+	DeleteSessionFn        func(courseID string, sessionID string, userID string) error
+	DeleteSessionFnInvoked bool
+
+	OpenCheckInFn        func(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession)
+	OpenCheckInFnInvoked bool
+
+	CloseCheckInFn        func(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession)
+	CloseCheckInFnInvoked bool
+
+	CheckInFn        func(courseID string, userID string, code string) (error, eduboard.AttendanceSession)
+	CheckInFnInvoked bool
+
+	MarkAttendanceFn        func(courseID string, sessionID string, userID string, records []eduboard.AttendanceRecord) (error, eduboard.AttendanceSession)
+	MarkAttendanceFnInvoked bool
+
+	GetStudentReportFn        func(courseID string, studentID string, userID string) (error, eduboard.AttendanceReport)
+	GetStudentReportFnInvoked bool
+
+	GetCourseReportFn        func(courseID string, userID string) (error, eduboard.CourseAttendanceReport)
+	GetCourseReportFnInvoked bool
+
+	DeleteCancelledFn        func(courseID string, of eduboard.OccurrenceFinder) error
+	DeleteCancelledFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.AttendanceService = (*AttendanceService)(nil)
+
+func (aSM *AttendanceService) GetSessions(courseID string, userID string) (error, []eduboard.AttendanceSession) {
+	aSM.GetSessionsFnInvoked = true
+	return aSM.GetSessionsFn(courseID, userID)
+}
+
+func (aSM *AttendanceService) GetSession(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession) {
+	aSM.GetSessionFnInvoked = true
+	return aSM.GetSessionFn(courseID, sessionID, userID)
+}
+
+func (aSM *AttendanceService) OpenSession(courseID string, scheduleID string, date string, userID string, of eduboard.OccurrenceFinder) (error, eduboard.AttendanceSession) {
+	aSM.OpenSessionFnInvoked = true
+	return aSM.OpenSessionFn(courseID, scheduleID, date, userID, of)
+}
+
+func (aSM *AttendanceService) DeleteSession(courseID string, sessionID string, userID string) error {
+	aSM.DeleteSessionFnInvoked = true
+	return aSM.DeleteSessionFn(courseID, sessionID, userID)
+}
+
+func (aSM *AttendanceService) OpenCheckIn(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession) {
+	aSM.OpenCheckInFnInvoked = true
+	return aSM.OpenCheckInFn(courseID, sessionID, userID)
+}
+
+func (aSM *AttendanceService) CloseCheckIn(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession) {
+	aSM.CloseCheckInFnInvoked = true
+	return aSM.CloseCheckInFn(courseID, sessionID, userID)
+}
+
+func (aSM *AttendanceService) CheckIn(courseID string, userID string, code string) (error, eduboard.AttendanceSession) {
+	aSM.CheckInFnInvoked = true
+	return aSM.CheckInFn(courseID, userID, code)
+}
+
+func (aSM *AttendanceService) MarkAttendance(courseID string, sessionID string, userID string, records []eduboard.AttendanceRecord) (error, eduboard.AttendanceSession) {
+	aSM.MarkAttendanceFnInvoked = true
+	return aSM.MarkAttendanceFn(courseID, sessionID, userID, records)
+}
+
+func (aSM *AttendanceService) GetStudentReport(courseID string, studentID string, userID string) (error, eduboard.AttendanceReport) {
+	aSM.GetStudentReportFnInvoked = true
+	return aSM.GetStudentReportFn(courseID, studentID, userID)
+}
+
+func (aSM *AttendanceService) GetCourseReport(courseID string, userID string) (error, eduboard.CourseAttendanceReport) {
+	aSM.GetCourseReportFnInvoked = true
+	return aSM.GetCourseReportFn(courseID, userID)
+}
+
+func (aSM *AttendanceService) DeleteCancelled(courseID string, of eduboard.OccurrenceFinder) error {
+	aSM.DeleteCancelledFnInvoked = true
+	return aSM.DeleteCancelledFn(courseID, of)
+}
+
+func (aSM *AttendanceService) DeleteByCourse(courseID string) error {
+	aSM.DeleteByCourseFnInvoked = true
+	return aSM.DeleteByCourseFn(courseID)
+}
+
+type PollService struct {
+	SetPollFn        func(courseID string, entryID string, userID string, poll eduboard.Poll) (error, eduboard.Poll)
+	SetPollFnInvoked bool
+
+	DeletePollFn        func(courseID string, entryID string, userID string) error
+	DeletePollFnInvoked bool
+
+	ClosePollFn        func(courseID string, entryID string, userID string) (error, eduboard.Poll)
+	ClosePollFnInvoked bool
+
+	RespondFn        func(courseID string, entryID string, userID string, answers [][]int) (error, eduboard.PollResponse)
+	RespondFnInvoked bool
+
+	GetResponseFn        func(courseID string, entryID string, userID string) (error, eduboard.PollResponse)
+	GetResponseFnInvoked bool
+
+	GetResultsFn        func(courseID string, entryID string, userID string) (error, eduboard.PollResults)
+	GetResultsFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.PollService = (*PollService)(nil)
+
+func (pSM *PollService) SetPoll(courseID string, entryID string, userID string, poll eduboard.Poll) (error, eduboard.Poll) {
+	pSM.SetPollFnInvoked = true
+	return pSM.SetPollFn(courseID, entryID, userID, poll)
+}
+
+func (pSM *PollService) DeletePoll(courseID string, entryID string, userID string) error {
+	pSM.DeletePollFnInvoked = true
+	return pSM.DeletePollFn(courseID, entryID, userID)
+}
+
+func (pSM *PollService) ClosePoll(courseID string, entryID string, userID string) (error, eduboard.Poll) {
+	pSM.ClosePollFnInvoked = true
+	return pSM.ClosePollFn(courseID, entryID, userID)
+}
+
+func (pSM *PollService) Respond(courseID string, entryID string, userID string, answers [][]int) (error, eduboard.PollResponse) {
+	pSM.RespondFnInvoked = true
+	return pSM.RespondFn(courseID, entryID, userID, answers)
+}
+
+func (pSM *PollService) GetResponse(courseID string, entryID string, userID string) (error, eduboard.PollResponse) {
+	pSM.GetResponseFnInvoked = true
+	return pSM.GetResponseFn(courseID, entryID, userID)
+}
+
+func (pSM *PollService) GetResults(courseID string, entryID string, userID string) (error, eduboard.PollResults) {
+	pSM.GetResultsFnInvoked = true
+	return pSM.GetResultsFn(courseID, entryID, userID)
+}
+
+func (pSM *PollService) DeleteByCourse(courseID string) error {
+	pSM.DeleteByCourseFnInvoked = true
+	return pSM.DeleteByCourseFn(courseID)
+}
+
+type MaterialService struct {
+	GetListingFn        func(courseID string, folderID string, userID string) (error, eduboard.MaterialListing)
+	GetListingFnInvoked bool
+
+	GetFolderFn        func(courseID string, folderID string, userID string) (error, eduboard.MaterialFolder)
+	GetFolderFnInvoked bool
+
+	CreateFolderFn        func(courseID string, userID string, folder eduboard.MaterialFolder) (error, eduboard.MaterialFolder)
+	CreateFolderFnInvoked bool
+
+	UpdateFolderFn        func(courseID string, folderID string, userID string, update eduboard.MaterialFolderUpdate) (error, eduboard.MaterialFolder)
+	UpdateFolderFnInvoked bool
+
+	DeleteFolderFn        func(courseID string, folderID string, userID string) error
+	DeleteFolderFnInvoked bool
+
+	GetMaterialFn        func(courseID string, materialID string, userID string) (error, eduboard.Material)
+	GetMaterialFnInvoked bool
+
+	CreateMaterialFn        func(courseID string, userID string, material eduboard.Material, uploadID string) (error, eduboard.Material)
+	CreateMaterialFnInvoked bool
+
+	UpdateMaterialFn        func(courseID string, materialID string, userID string, update eduboard.MaterialUpdate) (error, eduboard.Material)
+	UpdateMaterialFnInvoked bool
+
+	AddVersionFn        func(courseID string, materialID string, userID string, uploadID string, comment string) (error, eduboard.Material)
+	AddVersionFnInvoked bool
+
+	DeleteMaterialFn        func(courseID string, materialID string, userID string) error
+	DeleteMaterialFnInvoked bool
+
+	OpenMaterialFn        func(courseID string, materialID string, userID string, version int) (error, eduboard.MaterialVersion, io.ReadCloser)
+	OpenMaterialFnInvoked bool
+
+	ExportFolderFn        func(courseID string, folderID string, userID string, w io.Writer) error
+	ExportFolderFnInvoked bool
+
+	DeleteByCourseFn        func(courseID string) error
+	DeleteByCourseFnInvoked bool
+}
+
+var _ eduboard.MaterialService = (*MaterialService)(nil)
+
+func (mSM *MaterialService) GetListing(courseID string, folderID string, userID string) (error, eduboard.MaterialListing) {
+	mSM.GetListingFnInvoked = true
+	return mSM.GetListingFn(courseID, folderID, userID)
+}
+
+func (mSM *MaterialService) GetFolder(courseID string, folderID string, userID string) (error, eduboard.MaterialFolder) {
+	mSM.GetFolderFnInvoked = true
+	return mSM.GetFolderFn(courseID, folderID, userID)
+}
+
+func (mSM *MaterialService) CreateFolder(courseID string, userID string, folder eduboard.MaterialFolder) (error, eduboard.MaterialFolder) {
+	mSM.CreateFolderFnInvoked = true
+	return mSM.CreateFolderFn(courseID, userID, folder)
+}
+
+func (mSM *MaterialService) UpdateFolder(courseID string, folderID string, userID string, update eduboard.MaterialFolderUpdate) (error, eduboard.MaterialFolder) {
+	mSM.UpdateFolderFnInvoked = true
+	return mSM.UpdateFolderFn(courseID, folderID, userID, update)
+}
+
+func (mSM *MaterialService) DeleteFolder(courseID string, folderID string, userID string) error {
+	mSM.DeleteFolderFnInvoked = true
+	return mSM.DeleteFolderFn(courseID, folderID, userID)
+}
+
+func (mSM *MaterialService) GetMaterial(courseID string, materialID string, userID string) (error, eduboard.Material) {
+	mSM.GetMaterialFnInvoked = true
+	return mSM.GetMaterialFn(courseID, materialID, userID)
+}
+
+func (mSM *MaterialService) CreateMaterial(courseID string, userID string, material eduboard.Material, uploadID string) (error, eduboard.Material) {
+	mSM.CreateMaterialFnInvoked = true
+	return mSM.CreateMaterialFn(courseID, userID, material, uploadID)
+}
+
+func (mSM *MaterialService) UpdateMaterial(courseID string, materialID string, userID string, update eduboard.MaterialUpdate) (error, eduboard.Material) {
+	mSM.UpdateMaterialFnInvoked = true
+	return mSM.UpdateMaterialFn(courseID, materialID, userID, update)
+}
+
+func (mSM *MaterialService) AddVersion(courseID string, materialID string, userID string, uploadID string, comment string) (error, eduboard.Material) {
+	mSM.AddVersionFnInvoked = true
+	return mSM.AddVersionFn(courseID, materialID, userID, uploadID, comment)
+}
+
+func (mSM *MaterialService) DeleteMaterial(courseID string, materialID string, userID string) error {
+	mSM.DeleteMaterialFnInvoked = true
+	return mSM.DeleteMaterialFn(courseID, materialID, userID)
+}
+
+func (mSM *MaterialService) OpenMaterial(courseID string, materialID string, userID string, version int) (error, eduboard.MaterialVersion, io.ReadCloser) {
+	mSM.OpenMaterialFnInvoked = true
+	return mSM.OpenMaterialFn(courseID, materialID, userID, version)
+}
+
+func (mSM *MaterialService) ExportFolder(courseID string, folderID string, userID string, w io.Writer) error {
+	mSM.ExportFolderFnInvoked = true
+	return mSM.ExportFolderFn(courseID, folderID, userID, w)
+}
+
+func (mSM *MaterialService) DeleteByCourse(courseID string) error {
+	mSM.DeleteByCourseFnInvoked = true
+	return mSM.DeleteByCourseFn(courseID)
+}
@@ -0,0 +1,175 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+)
+
+type AssignmentRepository struct {
+	c *mgo.Collection
+}
+
+func newAssignmentRepository(database *mgo.Database) *AssignmentRepository {
+	collection := database.C("assignment")
+
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID", "dueAt"}}); err != nil {
+		log.Printf("error creating index on assignments: %v", err)
+	}
+
+	return &AssignmentRepository{
+		c: collection,
+	}
+}
+
+func (a *AssignmentRepository) Insert(assignment *eduboard.Assignment) error {
+	if assignment.ID == "" {
+		assignment.ID = bson.NewObjectId()
+	}
+	return a.c.Insert(assignment)
+}
+
+func (a *AssignmentRepository) FindOneByID(id string) (error, eduboard.Assignment) {
+	result := eduboard.Assignment{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.Assignment{}
+	}
+	if err := a.c.FindId(bson.ObjectIdHex(id)).One(&result); err != nil {
+		return err, eduboard.Assignment{}
+	}
+	return nil, result
+}
+
+func (a *AssignmentRepository) FindByCourse(courseID string) (error, []eduboard.Assignment) {
+	result := []eduboard.Assignment{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.Assignment{}
+	}
+	if err := a.c.Find(bson.M{"courseID": bson.ObjectIdHex(courseID)}).Sort("dueAt").All(&result); err != nil {
+		return err, []eduboard.Assignment{}
+	}
+	return nil, result
+}
+
+func (a *AssignmentRepository) Update(id string, update bson.M) (error, eduboard.Assignment) {
+	result := eduboard.Assignment{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.Assignment{}
+	}
+	change := mgo.Change{
+		Update:    update,
+		ReturnNew: true,
+	}
+	if _, err := a.c.FindId(bson.ObjectIdHex(id)).Apply(change, &result); err != nil {
+		return err, eduboard.Assignment{}
+	}
+	return nil, result
+}
+
+func (a *AssignmentRepository) Delete(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return a.c.RemoveId(bson.ObjectIdHex(id))
+}
+
+func (a *AssignmentRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := a.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
+
+type SubmissionRepository struct {
+	c *mgo.Collection
+}
+
+func newSubmissionRepository(database *mgo.Database) *SubmissionRepository {
+	collection := database.C("submission")
+
+	// Every student has at most one submission per assignment.
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"assignmentID", "userID"}, Unique: true}); err != nil {
+		log.Printf("error creating index on submissions: %v", err)
+	}
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID"}}); err != nil {
+		log.Printf("error creating index on submissions: %v", err)
+	}
+
+	return &SubmissionRepository{
+		c: collection,
+	}
+}
+
+func (s *SubmissionRepository) Upsert(submission *eduboard.Submission) error {
+	query := bson.M{"assignmentID": submission.AssignmentID, "userID": submission.UserID}
+	update := bson.M{
+		"$set": bson.M{
+			"courseID":    submission.CourseID,
+			"text":        submission.Text,
+			"attachments": submission.Attachments,
+			"submittedAt": submission.SubmittedAt,
+			"late":        submission.Late,
+		},
+		"$setOnInsert": bson.M{"_id": bson.NewObjectId()},
+	}
+	if _, err := s.c.Upsert(query, update); err != nil {
+		return err
+	}
+
+	// The ID of a replaced submission stays the same.
+	stored := eduboard.Submission{}
+	if err := s.c.Find(query).Select(bson.M{"_id": 1}).One(&stored); err != nil {
+		return err
+	}
+	submission.ID = stored.ID
+	return nil
+}
+
+func (s *SubmissionRepository) Find(assignmentID string, userID string) (error, eduboard.Submission) {
+	result := eduboard.Submission{}
+
+	if !bson.IsObjectIdHex(assignmentID) {
+		return errors.New("invalid id"), eduboard.Submission{}
+	}
+	if err := s.c.Find(bson.M{"assignmentID": bson.ObjectIdHex(assignmentID), "userID": userID}).One(&result); err != nil {
+		return err, eduboard.Submission{}
+	}
+	return nil, result
+}
+
+func (s *SubmissionRepository) FindByAssignment(assignmentID string) (error, []eduboard.Submission) {
+	result := []eduboard.Submission{}
+
+	if !bson.IsObjectIdHex(assignmentID) {
+		return errors.New("invalid id"), []eduboard.Submission{}
+	}
+	if err := s.c.Find(bson.M{"assignmentID": bson.ObjectIdHex(assignmentID)}).Sort("submittedAt").All(&result); err != nil {
+		return err, []eduboard.Submission{}
+	}
+	return nil, result
+}
+
+func (s *SubmissionRepository) DeleteByAssignment(assignmentID string) error {
+	if !bson.IsObjectIdHex(assignmentID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := s.c.RemoveAll(bson.M{"assignmentID": bson.ObjectIdHex(assignmentID)})
+	return err
+}
+
+func (s *SubmissionRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := s.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
@@ -0,0 +1,138 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"time"
+)
+
+type AttendanceRepository struct {
+	c *mgo.Collection
+}
+
+func newAttendanceRepository(database *mgo.Database) *AttendanceRepository {
+	collection := database.C("attendance")
+
+	// There is at most one session per meeting.
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID", "scheduleID", "date"}, Unique: true}); err != nil {
+		log.Printf("error creating index on attendance: %v", err)
+	}
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID", "code"}, Sparse: true}); err != nil {
+		log.Printf("error creating index on attendance: %v", err)
+	}
+
+	return &AttendanceRepository{
+		c: collection,
+	}
+}
+
+func (a *AttendanceRepository) Insert(session *eduboard.AttendanceSession) error {
+	if session.ID == "" {
+		session.ID = bson.NewObjectId()
+	}
+	err := a.c.Insert(session)
+	if mgo.IsDup(err) {
+		return eduboard.ErrDuplicate
+	}
+	return err
+}
+
+func (a *AttendanceRepository) FindOneByID(id string) (error, eduboard.AttendanceSession) {
+	result := eduboard.AttendanceSession{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.AttendanceSession{}
+	}
+	if err := a.c.FindId(bson.ObjectIdHex(id)).One(&result); err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+	return nil, result
+}
+
+func (a *AttendanceRepository) Find(courseID string, scheduleID string, date string) (error, eduboard.AttendanceSession) {
+	result := eduboard.AttendanceSession{}
+
+	if !bson.IsObjectIdHex(courseID) || !bson.IsObjectIdHex(scheduleID) {
+		return errors.New("invalid id"), eduboard.AttendanceSession{}
+	}
+	query := bson.M{"courseID": bson.ObjectIdHex(courseID), "scheduleID": bson.ObjectIdHex(scheduleID), "date": date}
+	err := a.c.Find(query).One(&result)
+	if err == mgo.ErrNotFound {
+		return eduboard.ErrNotFound, eduboard.AttendanceSession{}
+	}
+	if err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+	return nil, result
+}
+
+func (a *AttendanceRepository) FindByCourse(courseID string) (error, []eduboard.AttendanceSession) {
+	result := []eduboard.AttendanceSession{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.AttendanceSession{}
+	}
+	if err := a.c.Find(bson.M{"courseID": bson.ObjectIdHex(courseID)}).Sort("startsAt").All(&result); err != nil {
+		return err, []eduboard.AttendanceSession{}
+	}
+	return nil, result
+}
+
+func (a *AttendanceRepository) SetCode(id string, code string, expiresAt time.Time) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+
+	update := bson.M{"$set": bson.M{"code": code, "codeExpiresAt": expiresAt}}
+	if code == "" {
+		update = bson.M{"$unset": bson.M{"code": "", "codeExpiresAt": ""}}
+	}
+	return a.c.UpdateId(bson.ObjectIdHex(id), update)
+}
+
+func (a *AttendanceRepository) SetRecord(id string, record eduboard.AttendanceRecord) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return a.c.UpdateId(bson.ObjectIdHex(id), bson.M{"$set": bson.M{"records." + record.UserID: record}})
+}
+
+func (a *AttendanceRepository) CheckIn(courseID string, code string, record eduboard.AttendanceRecord, now time.Time) (error, eduboard.AttendanceSession) {
+	result := eduboard.AttendanceSession{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), eduboard.AttendanceSession{}
+	}
+	query := bson.M{
+		"courseID":      bson.ObjectIdHex(courseID),
+		"code":          code,
+		"codeExpiresAt": bson.M{"$gt": now},
+	}
+	change := mgo.Change{
+		Update:    bson.M{"$set": bson.M{"records." + record.UserID: record}},
+		ReturnNew: true,
+	}
+	if _, err := a.c.Find(query).Apply(change, &result); err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+	return nil, result
+}
+
+func (a *AttendanceRepository) Delete(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return a.c.RemoveId(bson.ObjectIdHex(id))
+}
+
+func (a *AttendanceRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := a.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
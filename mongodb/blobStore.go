@@ -0,0 +1,42 @@
+package mongodb
+
+import (
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"io"
+)
+
+// GridFSBlobStore stores blobs in MongoDB using GridFS, with the key as file name.
+type GridFSBlobStore struct {
+	fs *mgo.GridFS
+}
+
+var _ eduboard.BlobStore = (*GridFSBlobStore)(nil)
+
+func newGridFSBlobStore(database *mgo.Database) *GridFSBlobStore {
+	return &GridFSBlobStore{
+		fs: database.GridFS("uploads"),
+	}
+}
+
+func (g *GridFSBlobStore) Put(key string, r io.Reader) error {
+	file, err := g.fs.Create(key)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(file, r); err != nil {
+		file.Abort()
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (g *GridFSBlobStore) Get(key string) (io.ReadCloser, error) {
+	return g.fs.Open(key)
+}
+
+func (g *GridFSBlobStore) Delete(key string) error {
+	return g.fs.Remove(key)
+}
@@ -0,0 +1,93 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+)
+
+type CommentRepository struct {
+	c *mgo.Collection
+}
+
+func newCommentRepository(database *mgo.Database) *CommentRepository {
+	collection := database.C("comment")
+
+	// Threads are listed by entry, their replies by parent.
+	indexes := []mgo.Index{
+		{Key: []string{"entryID", "parentID", "_id"}},
+		{Key: []string{"parentID"}},
+		{Key: []string{"courseID"}},
+	}
+	for _, index := range indexes {
+		if err := collection.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on comments: %v", index.Key, err)
+		}
+	}
+
+	return &CommentRepository{
+		c: collection,
+	}
+}
+
+func (c *CommentRepository) Insert(comment eduboard.Comment) error {
+	return c.c.Insert(comment)
+}
+
+func (c *CommentRepository) FindOneByID(id string) (error, eduboard.Comment) {
+	result := eduboard.Comment{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.Comment{}
+	}
+	if err := c.c.FindId(bson.ObjectIdHex(id)).One(&result); err != nil {
+		return err, eduboard.Comment{}
+	}
+	return nil, result
+}
+
+func (c *CommentRepository) FindMany(query bson.M, limit int) (error, []eduboard.Comment) {
+	result := []eduboard.Comment{}
+
+	if err := c.c.Find(query).Sort("_id").Limit(limit).All(&result); err != nil {
+		return err, []eduboard.Comment{}
+	}
+	return nil, result
+}
+
+func (c *CommentRepository) Update(id string, update bson.M) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return c.c.UpdateId(bson.ObjectIdHex(id), update)
+}
+
+func (c *CommentRepository) Delete(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+
+	commentID := bson.ObjectIdHex(id)
+	_, err := c.c.RemoveAll(bson.M{"$or": []bson.M{{"_id": commentID}, {"parentID": commentID}}})
+	return err
+}
+
+func (c *CommentRepository) DeleteByEntry(entryID string) error {
+	if !bson.IsObjectIdHex(entryID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := c.c.RemoveAll(bson.M{"entryID": bson.ObjectIdHex(entryID)})
+	return err
+}
+
+func (c *CommentRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := c.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/eduboard/backend"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
+	"log"
+	"time"
 )
 
 type CourseEntryRepository struct {
@@ -13,6 +15,19 @@ type CourseEntryRepository struct {
 
 func newCourseEntryRepository(database *mgo.Database) *CourseEntryRepository {
 	collection := database.C("courseEntry")
+
+	// Feeds are filtered by course and sorted by date, optionally also filtered by publication.
+	indexes := []mgo.Index{
+		{Key: []string{"courseID", "-date", "-_id"}},
+		{Key: []string{"courseID", "published", "-date", "-_id"}},
+		{Key: []string{"published", "publishAt"}},
+	}
+	for _, index := range indexes {
+		if err := collection.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on course entries: %v", index.Key, err)
+		}
+	}
+
 	return &CourseEntryRepository{
 		c: collection,
 	}
@@ -44,6 +59,15 @@ func (c *CourseEntryRepository) FindMany(query bson.M) (error, []eduboard.Course
 	return nil, result
 }
 
+func (c *CourseEntryRepository) FindFeed(query bson.M, limit int) (error, []eduboard.CourseEntry) {
+	result := []eduboard.CourseEntry{}
+
+	if err := c.c.Find(query).Sort("-date", "-_id").Limit(limit).All(&result); err != nil {
+		return err, []eduboard.CourseEntry{}
+	}
+	return nil, result
+}
+
 func (c *CourseEntryRepository) Update(id string, update bson.M) error {
 	if !bson.IsObjectIdHex(id) {
 		return errors.New("invalid id")
@@ -58,6 +82,45 @@ func (c *CourseEntryRepository) Update(id string, update bson.M) error {
 	return nil
 }
 
+func (c *CourseEntryRepository) UpdateIf(id string, query bson.M, update bson.M) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+
+	condition := bson.M{"_id": bson.ObjectIdHex(id)}
+	for k, v := range query {
+		condition[k] = v
+	}
+	err := c.c.Update(condition, update)
+	if err == mgo.ErrNotFound {
+		return eduboard.ErrNotFound
+	}
+	return err
+}
+
+func (c *CourseEntryRepository) PublishDue(now time.Time) (error, []eduboard.CourseEntry) {
+	due := []eduboard.CourseEntry{}
+	if err := c.c.Find(bson.M{"published": false, "publishAt": bson.M{"$lte": now}}).All(&due); err != nil {
+		return err, []eduboard.CourseEntry{}
+	}
+
+	// The update only matches drafts, such that every entry is published and returned exactly once,
+	// even if several schedulers run at the same time.
+	published := []eduboard.CourseEntry{}
+	for _, entry := range due {
+		err := c.c.Update(bson.M{"_id": entry.ID, "published": false}, bson.M{"$set": bson.M{"published": true}})
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err, published
+		}
+		entry.Published = true
+		published = append(published, entry)
+	}
+	return nil, published
+}
+
 func (c *CourseEntryRepository) Delete(id string) error {
 	if !bson.IsObjectIdHex(id) {
 		return errors.New("invalid id")
@@ -65,3 +128,12 @@ func (c *CourseEntryRepository) Delete(id string) error {
 
 	return c.c.Remove(bson.M{"_id": bson.ObjectIdHex(id)})
 }
+
+func (c *CourseEntryRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := c.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
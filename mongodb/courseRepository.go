@@ -5,6 +5,7 @@ import (
 	"github.com/eduboard/backend"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
+	"log"
 	"time"
 )
 
@@ -14,11 +15,78 @@ type CourseRepository struct {
 
 func newCourseRepository(database *mgo.Database) *CourseRepository {
 	collection := database.C("course")
+
+	// Courses created before roles existed store their members as plain user IDs.
+	if err := migrateMembers(collection); err != nil {
+		log.Printf("error migrating course members: %v", err)
+	}
+
+	text := mgo.Index{
+		Key:     []string{"$text:title", "$text:description", "$text:labels"},
+		Weights: map[string]int{"title": 10, "labels": 5, "description": 1},
+		Name:    "course_text",
+	}
+	if err := collection.EnsureIndex(text); err != nil {
+		log.Printf("error creating text index on courses: %v", err)
+	}
+
 	return &CourseRepository{
 		c: collection,
 	}
 }
 
+// legacyCourse is a course whose members may still be plain user IDs.
+type legacyCourse struct {
+	ID      bson.ObjectId `bson:"_id"`
+	Members []interface{} `bson:"members"`
+}
+
+func migrateMembers(collection *mgo.Collection) error {
+	iter := collection.Find(bson.M{"members": bson.M{"$type": 2}}).Select(bson.M{"members": 1}).Iter()
+	course := legacyCourse{}
+	for iter.Next(&course) {
+		if err := collection.UpdateId(course.ID, bson.M{"$set": bson.M{"members": convertMembers(course.Members)}}); err != nil {
+			iter.Close()
+			return err
+		}
+		course = legacyCourse{}
+	}
+	return iter.Close()
+}
+
+// convertMembers turns plain user IDs into members. The first of them created the course and becomes its owner
+// unless the course already has one, everybody else becomes a student. Members that already have a role are kept.
+func convertMembers(old []interface{}) []eduboard.Member {
+	owned := false
+	for _, v := range old {
+		if m, ok := v.(bson.M); ok && m["role"] == string(eduboard.RoleOwner) {
+			owned = true
+		}
+	}
+
+	course := eduboard.Course{Members: []eduboard.Member{}}
+	for _, v := range old {
+		member := eduboard.Member{}
+		switch v := v.(type) {
+		case string:
+			member.UserID, member.Role = v, eduboard.RoleStudent
+			if !owned {
+				member.Role, owned = eduboard.RoleOwner, true
+			}
+		case bson.M:
+			userID, _ := v["userID"].(string)
+			role, _ := v["role"].(string)
+			member.UserID, member.Role = userID, eduboard.Role(role)
+		default:
+			continue
+		}
+		if _, ok := course.RoleOf(member.UserID); !ok {
+			course.Members = append(course.Members, member)
+		}
+	}
+	return course.Members
+}
+
 func (c *CourseRepository) Insert(course *eduboard.Course) error {
 	if course.ID == "" {
 		course.ID = bson.NewObjectId()
@@ -46,13 +114,55 @@ func (c *CourseRepository) FindOneByID(id string) (error, eduboard.Course) {
 func (c *CourseRepository) FindMany(query bson.M) (error, []eduboard.Course) {
 	result := []eduboard.Course{}
 
-	if err := c.c.Find(bson.M{}).All(&result); err != nil {
+	if err := c.c.Find(query).All(&result); err != nil {
 		return err, []eduboard.Course{}
 	}
 
 	return nil, result
 }
 
+// Search returns a page of the courses matching query along with the number of all matching courses.
+func (c *CourseRepository) Search(query eduboard.CourseQuery) (error, []eduboard.Course, int) {
+	filter := bson.M{}
+	if query.Text != "" {
+		filter["$text"] = bson.M{"$search": query.Text}
+	}
+	if len(query.Labels) > 0 {
+		filter["labels"] = bson.M{"$all": query.Labels}
+	}
+	if query.Member != "" {
+		filter["members.userID"] = query.Member
+	}
+	if query.Archived {
+		filter["archived"] = true
+	} else {
+		// Courses stored before archiving existed lack the field.
+		filter["archived"] = bson.M{"$ne": true}
+	}
+
+	total, err := c.c.Find(filter).Count()
+	if err != nil {
+		return err, []eduboard.Course{}, 0
+	}
+
+	q := c.c.Find(filter)
+	switch query.Sort {
+	case "relevance":
+		q = q.Select(bson.M{"score": bson.M{"$meta": "textScore"}}).Sort("$textScore:score", "_id")
+	case "":
+		q = q.Sort("_id")
+	default:
+		// The ID breaks ties, such that pages do not overlap.
+		q = q.Sort(query.Sort, "_id")
+	}
+
+	result := []eduboard.Course{}
+	if err = q.Skip(query.Offset).Limit(query.Limit).All(&result); err != nil {
+		return err, []eduboard.Course{}, 0
+	}
+	return nil, result, total
+}
+
 func (c *CourseRepository) Update(id string, update bson.M) (error, eduboard.Course) {
 	if !bson.IsObjectIdHex(id) {
 		return errors.New("invalid id"), eduboard.Course{}
@@ -64,3 +174,35 @@ func (c *CourseRepository) Update(id string, update bson.M) (error, eduboard.Cou
 
 	return nil, eduboard.Course{}
 }
+
+func (c *CourseRepository) AddMember(id string, member eduboard.Member) (error, eduboard.Course, bool) {
+	result := eduboard.Course{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.Course{}, false
+	}
+
+	query := bson.M{"_id": bson.ObjectIdHex(id), "members.userID": bson.M{"$ne": member.UserID}}
+	change := mgo.Change{
+		Update:    bson.M{"$push": bson.M{"members": member}},
+		ReturnNew: true,
+	}
+	_, err := c.c.Find(query).Apply(change, &result)
+	if err == mgo.ErrNotFound {
+		// Either the course does not exist or the user is a member already.
+		err, result = c.FindOneByID(id)
+		return err, result, false
+	}
+	if err != nil {
+		return err, eduboard.Course{}, false
+	}
+	return nil, result, true
+}
+
+func (c *CourseRepository) Delete(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+
+	return c.c.RemoveId(bson.ObjectIdHex(id))
+}
@@ -0,0 +1,42 @@
+package mongodb
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+func TestConvertMembers(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		old      []interface{}
+		expected []eduboard.Member
+	}{
+		{"empty", []interface{}{}, []eduboard.Member{}},
+		{"ids", []interface{}{"creator", "a", "b"}, []eduboard.Member{
+			{UserID: "creator", Role: eduboard.RoleOwner},
+			{UserID: "a", Role: eduboard.RoleStudent},
+			{UserID: "b", Role: eduboard.RoleStudent},
+		}},
+		{"duplicate ids", []interface{}{"creator", "a", "a"}, []eduboard.Member{
+			{UserID: "creator", Role: eduboard.RoleOwner},
+			{UserID: "a", Role: eduboard.RoleStudent},
+		}},
+		{"mixed with owner", []interface{}{bson.M{"userID": "owner", "role": "owner"}, "a"}, []eduboard.Member{
+			{UserID: "owner", Role: eduboard.RoleOwner},
+			{UserID: "a", Role: eduboard.RoleStudent},
+		}},
+		{"mixed without owner", []interface{}{bson.M{"userID": "teacher", "role": "teacher"}, "a", "b"}, []eduboard.Member{
+			{UserID: "teacher", Role: eduboard.RoleTeacher},
+			{UserID: "a", Role: eduboard.RoleOwner},
+			{UserID: "b", Role: eduboard.RoleStudent},
+		}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			assert.Equal(t, v.expected, convertMembers(v.old), "members do not match")
+		})
+	}
+}
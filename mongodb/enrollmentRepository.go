@@ -0,0 +1,110 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"time"
+)
+
+type EnrollmentRepository struct {
+	c *mgo.Collection
+}
+
+func newEnrollmentRepository(database *mgo.Database) *EnrollmentRepository {
+	collection := database.C("enrollment")
+
+	// Every user has at most one request per course.
+	indexes := []mgo.Index{
+		{Key: []string{"courseID", "userID"}, Unique: true},
+		{Key: []string{"courseID", "status", "_id"}},
+	}
+	for _, index := range indexes {
+		if err := collection.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on enrollments: %v", index.Key, err)
+		}
+	}
+
+	return &EnrollmentRepository{
+		c: collection,
+	}
+}
+
+func (e *EnrollmentRepository) Insert(request *eduboard.EnrollmentRequest) error {
+	if request.ID == "" {
+		request.ID = bson.NewObjectId()
+	}
+	return e.c.Insert(request)
+}
+
+func (e *EnrollmentRepository) FindOneByID(id string) (error, eduboard.EnrollmentRequest) {
+	result := eduboard.EnrollmentRequest{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.EnrollmentRequest{}
+	}
+	if err := e.c.FindId(bson.ObjectIdHex(id)).One(&result); err != nil {
+		return err, eduboard.EnrollmentRequest{}
+	}
+	return nil, result
+}
+
+func (e *EnrollmentRepository) Find(courseID string, userID string) (error, eduboard.EnrollmentRequest) {
+	result := eduboard.EnrollmentRequest{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), eduboard.EnrollmentRequest{}
+	}
+	if err := e.c.Find(bson.M{"courseID": bson.ObjectIdHex(courseID), "userID": userID}).One(&result); err != nil {
+		return err, eduboard.EnrollmentRequest{}
+	}
+	return nil, result
+}
+
+func (e *EnrollmentRepository) FindByCourse(courseID string, status eduboard.EnrollmentStatus) (error, []eduboard.EnrollmentRequest) {
+	result := []eduboard.EnrollmentRequest{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.EnrollmentRequest{}
+	}
+	query := bson.M{"courseID": bson.ObjectIdHex(courseID), "status": status}
+	if err := e.c.Find(query).Sort("_id").All(&result); err != nil {
+		return err, []eduboard.EnrollmentRequest{}
+	}
+	return nil, result
+}
+
+func (e *EnrollmentRepository) Decide(id string, status eduboard.EnrollmentStatus, userID string, now time.Time) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+
+	// Only pending requests match, so a request can not be decided twice.
+	query := bson.M{"_id": bson.ObjectIdHex(id), "status": eduboard.EnrollmentPending}
+	return e.c.Update(query, bson.M{"$set": bson.M{"status": status, "decidedBy": userID, "decidedAt": now}})
+}
+
+func (e *EnrollmentRepository) DeletePending(courseID string, userID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+	return e.c.Remove(bson.M{"courseID": bson.ObjectIdHex(courseID), "userID": userID, "status": eduboard.EnrollmentPending})
+}
+
+func (e *EnrollmentRepository) Delete(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return e.c.RemoveId(bson.ObjectIdHex(id))
+}
+
+func (e *EnrollmentRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := e.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
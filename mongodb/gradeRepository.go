@@ -0,0 +1,156 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+)
+
+type GradeRepository struct {
+	c *mgo.Collection
+}
+
+func newGradeRepository(database *mgo.Database) *GradeRepository {
+	collection := database.C("grade")
+
+	// Every student has at most one grade per assignment.
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"assignmentID", "userID"}, Unique: true}); err != nil {
+		log.Printf("error creating index on grades: %v", err)
+	}
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID", "userID"}}); err != nil {
+		log.Printf("error creating index on grades: %v", err)
+	}
+
+	return &GradeRepository{
+		c: collection,
+	}
+}
+
+func (g *GradeRepository) Upsert(grade *eduboard.Grade) error {
+	query := bson.M{"assignmentID": grade.AssignmentID, "userID": grade.UserID}
+	update := bson.M{
+		"$set": bson.M{
+			"courseID": grade.CourseID,
+			"points":   grade.Points,
+			"feedback": grade.Feedback,
+			"gradedBy": grade.GradedBy,
+			"gradedAt": grade.GradedAt,
+		},
+		"$setOnInsert": bson.M{"_id": bson.NewObjectId()},
+	}
+	if _, err := g.c.Upsert(query, update); err != nil {
+		return err
+	}
+
+	stored := eduboard.Grade{}
+	if err := g.c.Find(query).Select(bson.M{"_id": 1}).One(&stored); err != nil {
+		return err
+	}
+	grade.ID = stored.ID
+	return nil
+}
+
+func (g *GradeRepository) FindByCourse(courseID string) (error, []eduboard.Grade) {
+	result := []eduboard.Grade{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.Grade{}
+	}
+	if err := g.c.Find(bson.M{"courseID": bson.ObjectIdHex(courseID)}).All(&result); err != nil {
+		return err, []eduboard.Grade{}
+	}
+	return nil, result
+}
+
+func (g *GradeRepository) FindByUser(courseID string, userID string) (error, []eduboard.Grade) {
+	result := []eduboard.Grade{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.Grade{}
+	}
+	if err := g.c.Find(bson.M{"courseID": bson.ObjectIdHex(courseID), "userID": userID}).All(&result); err != nil {
+		return err, []eduboard.Grade{}
+	}
+	return nil, result
+}
+
+func (g *GradeRepository) Delete(assignmentID string, userID string) error {
+	if !bson.IsObjectIdHex(assignmentID) {
+		return errors.New("invalid id")
+	}
+	return g.c.Remove(bson.M{"assignmentID": bson.ObjectIdHex(assignmentID), "userID": userID})
+}
+
+func (g *GradeRepository) DeleteByAssignment(assignmentID string) error {
+	if !bson.IsObjectIdHex(assignmentID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := g.c.RemoveAll(bson.M{"assignmentID": bson.ObjectIdHex(assignmentID)})
+	return err
+}
+
+func (g *GradeRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := g.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
+
+type GradeCategoryRepository struct {
+	c *mgo.Collection
+}
+
+type gradeCategories struct {
+	CourseID   bson.ObjectId            `bson:"_id"`
+	Categories []eduboard.GradeCategory `bson:"categories"`
+}
+
+func newGradeCategoryRepository(database *mgo.Database) *GradeCategoryRepository {
+	return &GradeCategoryRepository{
+		c: database.C("gradeCategory"),
+	}
+}
+
+// Find returns the categories of a course. Courses without categories have none.
+func (g *GradeCategoryRepository) Find(courseID string) (error, []eduboard.GradeCategory) {
+	result := gradeCategories{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.GradeCategory{}
+	}
+	err := g.c.FindId(bson.ObjectIdHex(courseID)).One(&result)
+	if err == mgo.ErrNotFound {
+		return nil, []eduboard.GradeCategory{}
+	}
+	if err != nil {
+		return err, []eduboard.GradeCategory{}
+	}
+	return nil, result.Categories
+}
+
+func (g *GradeCategoryRepository) Set(courseID string, categories []eduboard.GradeCategory) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := g.c.UpsertId(bson.ObjectIdHex(courseID), bson.M{"$set": bson.M{"categories": categories}})
+	return err
+}
+
+// Delete removes the categories of a course. Courses without categories are left as they are.
+func (g *GradeCategoryRepository) Delete(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	err := g.c.RemoveId(bson.ObjectIdHex(courseID))
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"time"
+)
+
+type InviteRepository struct {
+	codes   *mgo.Collection
+	invites *mgo.Collection
+}
+
+func newInviteRepository(database *mgo.Database) *InviteRepository {
+	codes := database.C("inviteCode")
+	invites := database.C("emailInvite")
+
+	codeIndexes := []mgo.Index{
+		{Key: []string{"code"}, Unique: true},
+		{Key: []string{"courseID"}},
+	}
+	for _, index := range codeIndexes {
+		if err := codes.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on invite codes: %v", index.Key, err)
+		}
+	}
+
+	inviteIndexes := []mgo.Index{
+		{Key: []string{"tokenHash"}, Unique: true},
+		{Key: []string{"courseID"}},
+		{Key: []string{"userID"}, Sparse: true},
+		{Key: []string{"expiresAt"}, ExpireAfter: time.Second},
+	}
+	for _, index := range inviteIndexes {
+		if err := invites.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on email invites: %v", index.Key, err)
+		}
+	}
+
+	return &InviteRepository{
+		codes:   codes,
+		invites: invites,
+	}
+}
+
+func (i *InviteRepository) InsertCode(code *eduboard.InviteCode) error {
+	if code.ID == "" {
+		code.ID = bson.NewObjectId()
+	}
+	return i.codes.Insert(code)
+}
+
+func (i *InviteRepository) FindCode(code string) (error, eduboard.InviteCode) {
+	result := eduboard.InviteCode{}
+
+	if err := i.codes.Find(bson.M{"code": code}).One(&result); err != nil {
+		return err, eduboard.InviteCode{}
+	}
+	return nil, result
+}
+
+func (i *InviteRepository) FindCodes(courseID string) (error, []eduboard.InviteCode) {
+	result := []eduboard.InviteCode{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.InviteCode{}
+	}
+	if err := i.codes.Find(bson.M{"courseID": bson.ObjectIdHex(courseID)}).Sort("-_id").All(&result); err != nil {
+		return err, []eduboard.InviteCode{}
+	}
+	return nil, result
+}
+
+func (i *InviteRepository) UseCode(code eduboard.InviteCode, now time.Time) error {
+	// The conditions are checked by the update itself, so concurrent uses can not exceed MaxUses.
+	query := bson.M{
+		"_id":     code.ID,
+		"revoked": false,
+		"$or":     []bson.M{{"expiresAt": nil}, {"expiresAt": bson.M{"$gt": now}}},
+	}
+	if code.MaxUses > 0 {
+		query["uses"] = bson.M{"$lt": code.MaxUses}
+	}
+	return i.codes.Update(query, bson.M{"$inc": bson.M{"uses": 1}})
+}
+
+func (i *InviteRepository) ReleaseCode(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	query := bson.M{"_id": bson.ObjectIdHex(id), "uses": bson.M{"$gt": 0}}
+	return i.codes.Update(query, bson.M{"$inc": bson.M{"uses": -1}})
+}
+
+func (i *InviteRepository) RevokeCode(id string, courseID string) error {
+	if !bson.IsObjectIdHex(id) || !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+	query := bson.M{"_id": bson.ObjectIdHex(id), "courseID": bson.ObjectIdHex(courseID)}
+	return i.codes.Update(query, bson.M{"$set": bson.M{"revoked": true}})
+}
+
+func (i *InviteRepository) InsertEmailInvite(invite *eduboard.EmailInvite) error {
+	if invite.ID == "" {
+		invite.ID = bson.NewObjectId()
+	}
+	return i.invites.Insert(invite)
+}
+
+func (i *InviteRepository) FindEmailInvites(courseID string, now time.Time) (error, []eduboard.EmailInvite) {
+	result := []eduboard.EmailInvite{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.EmailInvite{}
+	}
+	query := bson.M{
+		"courseID":  bson.ObjectIdHex(courseID),
+		"userID":    bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": now},
+	}
+	if err := i.invites.Find(query).Sort("-_id").All(&result); err != nil {
+		return err, []eduboard.EmailInvite{}
+	}
+	return nil, result
+}
+
+func (i *InviteRepository) RedeemEmailInvite(tokenHash string, userID string, now time.Time) (error, eduboard.EmailInvite) {
+	result := eduboard.EmailInvite{}
+
+	// Finding and marking the invite in one operation makes sure it can only be redeemed once. Without
+	// expiresAt the invite is not removed before the user verified their email and joined the course.
+	change := mgo.Change{
+		Update:    bson.M{"$set": bson.M{"userID": userID}, "$unset": bson.M{"expiresAt": ""}},
+		ReturnNew: true,
+	}
+	query := bson.M{"tokenHash": tokenHash, "userID": bson.M{"$exists": false}, "expiresAt": bson.M{"$gt": now}}
+	if _, err := i.invites.Find(query).Apply(change, &result); err != nil {
+		return err, eduboard.EmailInvite{}
+	}
+	return nil, result
+}
+
+func (i *InviteRepository) FindRedeemedEmailInvites(userID string) (error, []eduboard.EmailInvite) {
+	result := []eduboard.EmailInvite{}
+
+	if err := i.invites.Find(bson.M{"userID": userID}).All(&result); err != nil {
+		return err, []eduboard.EmailInvite{}
+	}
+	return nil, result
+}
+
+func (i *InviteRepository) DeleteEmailInvite(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return i.invites.RemoveId(bson.ObjectIdHex(id))
+}
+
+func (i *InviteRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	query := bson.M{"courseID": bson.ObjectIdHex(courseID)}
+	if _, err := i.codes.RemoveAll(query); err != nil {
+		return err
+	}
+	_, err := i.invites.RemoveAll(query)
+	return err
+}
@@ -0,0 +1,196 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"time"
+)
+
+type MaterialFolderRepository struct {
+	c *mgo.Collection
+}
+
+func newMaterialFolderRepository(database *mgo.Database) *MaterialFolderRepository {
+	collection := database.C("materialFolder")
+
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID"}}); err != nil {
+		log.Printf("error creating index on material folders: %v", err)
+	}
+	// Names are unique within their folder. The service also compares them case-insensitively.
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID", "parentID", "name"}, Unique: true}); err != nil {
+		log.Printf("error creating index on material folders: %v", err)
+	}
+
+	return &MaterialFolderRepository{
+		c: collection,
+	}
+}
+
+func (m *MaterialFolderRepository) Insert(folder eduboard.MaterialFolder) error {
+	err := m.c.Insert(folder)
+	if mgo.IsDup(err) {
+		return eduboard.ErrDuplicate
+	}
+	return err
+}
+
+func (m *MaterialFolderRepository) FindOneByID(id string) (error, eduboard.MaterialFolder) {
+	result := eduboard.MaterialFolder{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.MaterialFolder{}
+	}
+	if err := m.c.FindId(bson.ObjectIdHex(id)).One(&result); err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+	return nil, result
+}
+
+func (m *MaterialFolderRepository) FindByCourse(courseID string) (error, []eduboard.MaterialFolder) {
+	result := []eduboard.MaterialFolder{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.MaterialFolder{}
+	}
+	if err := m.c.Find(bson.M{"courseID": bson.ObjectIdHex(courseID)}).Sort("name").All(&result); err != nil {
+		return err, []eduboard.MaterialFolder{}
+	}
+	return nil, result
+}
+
+func (m *MaterialFolderRepository) Update(id string, update bson.M) (error, eduboard.MaterialFolder) {
+	result := eduboard.MaterialFolder{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.MaterialFolder{}
+	}
+	change := mgo.Change{
+		Update:    update,
+		ReturnNew: true,
+	}
+	_, err := m.c.FindId(bson.ObjectIdHex(id)).Apply(change, &result)
+	if mgo.IsDup(err) {
+		return eduboard.ErrDuplicate, eduboard.MaterialFolder{}
+	}
+	if err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+	return nil, result
+}
+
+func (m *MaterialFolderRepository) Delete(ids []bson.ObjectId) error {
+	_, err := m.c.RemoveAll(bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+func (m *MaterialFolderRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := m.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
+
+type MaterialRepository struct {
+	c *mgo.Collection
+}
+
+func newMaterialRepository(database *mgo.Database) *MaterialRepository {
+	collection := database.C("material")
+
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID", "folderID"}}); err != nil {
+		log.Printf("error creating index on materials: %v", err)
+	}
+	// Names are unique within their folder. The service also compares them case-insensitively.
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID", "folderID", "name"}, Unique: true}); err != nil {
+		log.Printf("error creating index on materials: %v", err)
+	}
+
+	return &MaterialRepository{
+		c: collection,
+	}
+}
+
+func (m *MaterialRepository) Insert(material eduboard.Material) error {
+	err := m.c.Insert(material)
+	if mgo.IsDup(err) {
+		return eduboard.ErrDuplicate
+	}
+	return err
+}
+
+func (m *MaterialRepository) FindOneByID(id string) (error, eduboard.Material) {
+	result := eduboard.Material{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.Material{}
+	}
+	if err := m.c.FindId(bson.ObjectIdHex(id)).One(&result); err != nil {
+		return err, eduboard.Material{}
+	}
+	return nil, result
+}
+
+func (m *MaterialRepository) FindByCourse(courseID string) (error, []eduboard.Material) {
+	result := []eduboard.Material{}
+
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id"), []eduboard.Material{}
+	}
+	if err := m.c.Find(bson.M{"courseID": bson.ObjectIdHex(courseID)}).Sort("name").All(&result); err != nil {
+		return err, []eduboard.Material{}
+	}
+	return nil, result
+}
+
+func (m *MaterialRepository) Update(id string, update bson.M) (error, eduboard.Material) {
+	result := eduboard.Material{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.Material{}
+	}
+	change := mgo.Change{
+		Update:    update,
+		ReturnNew: true,
+	}
+	_, err := m.c.FindId(bson.ObjectIdHex(id)).Apply(change, &result)
+	if mgo.IsDup(err) {
+		return eduboard.ErrDuplicate, eduboard.Material{}
+	}
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+	return nil, result
+}
+
+func (m *MaterialRepository) AddVersion(id string, version eduboard.MaterialVersion) (error, eduboard.Material) {
+	return m.Update(id, bson.M{
+		"$push": bson.M{"versions": version},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	})
+}
+
+func (m *MaterialRepository) Delete(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return m.c.RemoveId(bson.ObjectIdHex(id))
+}
+
+func (m *MaterialRepository) DeleteByFolders(folderIDs []bson.ObjectId) error {
+	_, err := m.c.RemoveAll(bson.M{"folderID": bson.M{"$in": folderIDs}})
+	return err
+}
+
+func (m *MaterialRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := m.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
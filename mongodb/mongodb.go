@@ -13,10 +13,28 @@ type DBConfig struct {
 }
 
 type Repository struct {
-	session               *mgo.Session
-	UserRepository        *UserRepository
-	CourseRepository      *CourseRepository
-	CourseEntryRepository *CourseEntryRepository
+	session                  *mgo.Session
+	UserRepository           *UserRepository
+	CourseRepository         *CourseRepository
+	CourseEntryRepository    *CourseEntryRepository
+	SessionRepository        *SessionRepository
+	PasswordResetRepository  *PasswordResetRepository
+	VerificationRepository   *VerificationRepository
+	UploadRepository         *UploadRepository
+	RoomRepository           *RoomRepository
+	NotificationRepository   *NotificationRepository
+	CommentRepository        *CommentRepository
+	PollResponseRepository   *PollResponseRepository
+	InviteRepository         *InviteRepository
+	EnrollmentRepository     *EnrollmentRepository
+	AssignmentRepository     *AssignmentRepository
+	SubmissionRepository     *SubmissionRepository
+	GradeRepository          *GradeRepository
+	GradeCategoryRepository  *GradeCategoryRepository
+	AttendanceRepository     *AttendanceRepository
+	MaterialFolderRepository *MaterialFolderRepository
+	MaterialRepository       *MaterialRepository
+	BlobStore                *GridFSBlobStore
 }
 
 func Initialize(c DBConfig) *Repository {
@@ -34,9 +52,27 @@ func Initialize(c DBConfig) *Repository {
 
 	db := session.DB(config.Database)
 	return &Repository{
-		session:               session,
-		UserRepository:        newUserRepository(db),
-		CourseRepository:      newCourseRepository(db),
-		CourseEntryRepository: newCourseEntryRepository(db),
+		session:                  session,
+		UserRepository:           newUserRepository(db),
+		CourseRepository:         newCourseRepository(db),
+		CourseEntryRepository:    newCourseEntryRepository(db),
+		SessionRepository:        newSessionRepository(db),
+		PasswordResetRepository:  newPasswordResetRepository(db),
+		VerificationRepository:   newVerificationRepository(db),
+		UploadRepository:         newUploadRepository(db),
+		RoomRepository:           newRoomRepository(db),
+		NotificationRepository:   newNotificationRepository(db),
+		CommentRepository:        newCommentRepository(db),
+		PollResponseRepository:   newPollResponseRepository(db),
+		InviteRepository:         newInviteRepository(db),
+		EnrollmentRepository:     newEnrollmentRepository(db),
+		AssignmentRepository:     newAssignmentRepository(db),
+		SubmissionRepository:     newSubmissionRepository(db),
+		GradeRepository:          newGradeRepository(db),
+		GradeCategoryRepository:  newGradeCategoryRepository(db),
+		AttendanceRepository:     newAttendanceRepository(db),
+		MaterialFolderRepository: newMaterialFolderRepository(db),
+		MaterialRepository:       newMaterialRepository(db),
+		BlobStore:                newGridFSBlobStore(db),
 	}
 }
@@ -0,0 +1,89 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+)
+
+type NotificationRepository struct {
+	c *mgo.Collection
+}
+
+func newNotificationRepository(database *mgo.Database) *NotificationRepository {
+	collection := database.C("notification")
+
+	indexes := []mgo.Index{
+		{Key: []string{"userID", "-createdAt"}},
+		// MongoDB removes old notifications on its own.
+		{Key: []string{"createdAt"}, ExpireAfter: eduboard.NotificationRetention},
+	}
+	for _, index := range indexes {
+		if err := collection.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on notifications: %v", index.Key, err)
+		}
+	}
+
+	return &NotificationRepository{
+		c: collection,
+	}
+}
+
+func (n *NotificationRepository) Insert(notifications []eduboard.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(notifications))
+	for k := range notifications {
+		if notifications[k].ID == "" {
+			notifications[k].ID = bson.NewObjectId()
+		}
+		docs[k] = notifications[k]
+	}
+	return n.c.Insert(docs...)
+}
+
+func (n *NotificationRepository) FindByUser(userID string, unread bool, limit int) (error, []eduboard.Notification) {
+	query := bson.M{"userID": userID}
+	if unread {
+		query["read"] = false
+	}
+
+	result := []eduboard.Notification{}
+	if err := n.c.Find(query).Sort("-createdAt").Limit(limit).All(&result); err != nil {
+		return err, []eduboard.Notification{}
+	}
+	return nil, result
+}
+
+func (n *NotificationRepository) MarkRead(id string, userID string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return n.c.Update(bson.M{"_id": bson.ObjectIdHex(id), "userID": userID}, bson.M{"$set": bson.M{"read": true}})
+}
+
+func (n *NotificationRepository) MarkAllRead(userID string) error {
+	_, err := n.c.UpdateAll(bson.M{"userID": userID, "read": false}, bson.M{"$set": bson.M{"read": true}})
+	return err
+}
+
+func (n *NotificationRepository) CountUnread(userID string) (error, int) {
+	count, err := n.c.Find(bson.M{"userID": userID, "read": false}).Count()
+	if err != nil {
+		return err, 0
+	}
+	return nil, count
+}
+
+func (n *NotificationRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := n.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
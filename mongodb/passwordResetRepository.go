@@ -0,0 +1,66 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"time"
+)
+
+type PasswordResetRepository struct {
+	c *mgo.Collection
+}
+
+func newPasswordResetRepository(database *mgo.Database) *PasswordResetRepository {
+	collection := database.C("passwordReset")
+
+	indexes := []mgo.Index{
+		{Key: []string{"tokenHash"}, Unique: true},
+		{Key: []string{"expiresAt"}, ExpireAfter: time.Second},
+	}
+	for _, index := range indexes {
+		if err := collection.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on password resets: %v", index.Key, err)
+		}
+	}
+
+	return &PasswordResetRepository{
+		c: collection,
+	}
+}
+
+func (p *PasswordResetRepository) Insert(token *eduboard.PasswordResetToken) error {
+	if token.ID == "" {
+		token.ID = bson.NewObjectId()
+	}
+	return p.c.Insert(token)
+}
+
+func (p *PasswordResetRepository) Consume(tokenHash string, now time.Time) (error, eduboard.PasswordResetToken) {
+	result := eduboard.PasswordResetToken{}
+
+	// Finding and marking the token in one operation makes sure it can only be used once.
+	change := mgo.Change{
+		Update:    bson.M{"$set": bson.M{"used": true}},
+		ReturnNew: true,
+	}
+	query := bson.M{"tokenHash": tokenHash, "used": false, "expiresAt": bson.M{"$gt": now}}
+	if _, err := p.c.Find(query).Apply(change, &result); err != nil {
+		return err, eduboard.PasswordResetToken{}
+	}
+	return nil, result
+}
+
+func (p *PasswordResetRepository) Release(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return p.c.UpdateId(bson.ObjectIdHex(id), bson.M{"$set": bson.M{"used": false}})
+}
+
+func (p *PasswordResetRepository) DeleteByUser(userID string) error {
+	_, err := p.c.RemoveAll(bson.M{"userID": userID})
+	return err
+}
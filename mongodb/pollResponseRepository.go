@@ -0,0 +1,86 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+)
+
+type PollResponseRepository struct {
+	c *mgo.Collection
+}
+
+func newPollResponseRepository(database *mgo.Database) *PollResponseRepository {
+	collection := database.C("pollResponse")
+
+	// Every member may respond to a poll only once.
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"entryID", "userID"}, Unique: true}); err != nil {
+		log.Printf("error creating index on poll responses: %v", err)
+	}
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID"}}); err != nil {
+		log.Printf("error creating index on poll responses: %v", err)
+	}
+
+	return &PollResponseRepository{
+		c: collection,
+	}
+}
+
+func (p *PollResponseRepository) Insert(response eduboard.PollResponse) error {
+	err := p.c.Insert(response)
+	if mgo.IsDup(err) {
+		return eduboard.ErrDuplicate
+	}
+	return err
+}
+
+func (p *PollResponseRepository) Find(entryID string, userID string) (error, eduboard.PollResponse) {
+	result := eduboard.PollResponse{}
+
+	if !bson.IsObjectIdHex(entryID) {
+		return errors.New("invalid id"), eduboard.PollResponse{}
+	}
+	if err := p.c.Find(bson.M{"entryID": bson.ObjectIdHex(entryID), "userID": userID}).One(&result); err != nil {
+		return err, eduboard.PollResponse{}
+	}
+	return nil, result
+}
+
+func (p *PollResponseRepository) FindByEntry(entryID string) (error, []eduboard.PollResponse) {
+	result := []eduboard.PollResponse{}
+
+	if !bson.IsObjectIdHex(entryID) {
+		return errors.New("invalid id"), []eduboard.PollResponse{}
+	}
+	if err := p.c.Find(bson.M{"entryID": bson.ObjectIdHex(entryID)}).Sort("createdAt").All(&result); err != nil {
+		return err, []eduboard.PollResponse{}
+	}
+	return nil, result
+}
+
+func (p *PollResponseRepository) CountByEntry(entryID string) (error, int) {
+	if !bson.IsObjectIdHex(entryID) {
+		return errors.New("invalid id"), 0
+	}
+	n, err := p.c.Find(bson.M{"entryID": bson.ObjectIdHex(entryID)}).Count()
+	return err, n
+}
+
+func (p *PollResponseRepository) DeleteByEntry(entryID string) error {
+	if !bson.IsObjectIdHex(entryID) {
+		return errors.New("invalid id")
+	}
+	_, err := p.c.RemoveAll(bson.M{"entryID": bson.ObjectIdHex(entryID)})
+	return err
+}
+
+func (p *PollResponseRepository) DeleteByCourse(courseID string) error {
+	if !bson.IsObjectIdHex(courseID) {
+		return errors.New("invalid id")
+	}
+
+	_, err := p.c.RemoveAll(bson.M{"courseID": bson.ObjectIdHex(courseID)})
+	return err
+}
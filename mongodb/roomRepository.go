@@ -0,0 +1,76 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+)
+
+type RoomRepository struct {
+	c *mgo.Collection
+}
+
+func newRoomRepository(database *mgo.Database) *RoomRepository {
+	collection := database.C("room")
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"name"}, Unique: true}); err != nil {
+		log.Printf("error creating index on rooms: %v", err)
+	}
+
+	return &RoomRepository{
+		c: collection,
+	}
+}
+
+func (r *RoomRepository) Insert(room *eduboard.Room) error {
+	if room.ID == "" {
+		room.ID = bson.NewObjectId()
+	}
+	return r.c.Insert(room)
+}
+
+func (r *RoomRepository) FindAll() (error, []eduboard.Room) {
+	result := []eduboard.Room{}
+	if err := r.c.Find(nil).Sort("name").All(&result); err != nil {
+		return err, []eduboard.Room{}
+	}
+	return nil, result
+}
+
+func (r *RoomRepository) FindOneByID(id string) (error, eduboard.Room) {
+	result := eduboard.Room{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), result
+	}
+	if err := r.c.FindId(bson.ObjectIdHex(id)).One(&result); err != nil {
+		return err, eduboard.Room{}
+	}
+	return nil, result
+}
+
+func (r *RoomRepository) Update(id string, update bson.M) (error, eduboard.Room) {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), eduboard.Room{}
+	}
+	if err := r.c.UpdateId(bson.ObjectIdHex(id), update); err != nil {
+		return err, eduboard.Room{}
+	}
+	return r.FindOneByID(id)
+}
+
+func (r *RoomRepository) Delete(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+	return r.c.RemoveId(bson.ObjectIdHex(id))
+}
+
+func (r *RoomRepository) FindByName(name string) (error, eduboard.Room) {
+	result := eduboard.Room{}
+	if err := r.c.Find(bson.M{"name": name}).One(&result); err != nil {
+		return err, eduboard.Room{}
+	}
+	return nil, result
+}
@@ -0,0 +1,90 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"time"
+)
+
+type SessionRepository struct {
+	c *mgo.Collection
+}
+
+func newSessionRepository(database *mgo.Database) *SessionRepository {
+	collection := database.C("session")
+
+	// Sessions used to store their token in plain text. They are ended, their tokens are never looked up again.
+	if _, err := collection.RemoveAll(bson.M{"tokenHash": bson.M{"$exists": false}}); err != nil {
+		log.Printf("error removing sessions with plain tokens: %v", err)
+	}
+	collection.DropIndex("token")
+
+	indexes := []mgo.Index{
+		{Key: []string{"tokenHash"}, Unique: true},
+		{Key: []string{"userID"}},
+		// MongoDB removes sessions on its own once expiresAt has passed.
+		{Key: []string{"expiresAt"}, ExpireAfter: time.Second},
+	}
+	for _, index := range indexes {
+		if err := collection.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on sessions: %v", index.Key, err)
+		}
+	}
+
+	return &SessionRepository{
+		c: collection,
+	}
+}
+
+func (s *SessionRepository) Insert(session *eduboard.Session) error {
+	if session.ID == "" {
+		session.ID = bson.NewObjectId()
+	}
+	return s.c.Insert(session)
+}
+
+func (s *SessionRepository) FindByToken(tokenHash string) (error, eduboard.Session) {
+	result := eduboard.Session{}
+
+	if tokenHash == "" {
+		return errors.New("not found"), result
+	}
+
+	if err := s.c.Find(bson.M{"tokenHash": tokenHash}).One(&result); err != nil {
+		return err, eduboard.Session{}
+	}
+	return nil, result
+}
+
+func (s *SessionRepository) FindByUser(userID string) (error, []eduboard.Session) {
+	result := []eduboard.Session{}
+
+	if err := s.c.Find(bson.M{"userID": userID}).Sort("-lastSeen").All(&result); err != nil {
+		return err, []eduboard.Session{}
+	}
+	return nil, result
+}
+
+func (s *SessionRepository) Touch(id string, lastSeen time.Time, expiresAt time.Time) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+
+	return s.c.UpdateId(bson.ObjectIdHex(id), bson.M{"$set": bson.M{"lastSeen": lastSeen, "expiresAt": expiresAt}})
+}
+
+func (s *SessionRepository) Delete(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id")
+	}
+
+	return s.c.RemoveId(bson.ObjectIdHex(id))
+}
+
+func (s *SessionRepository) DeleteByUser(userID string) error {
+	_, err := s.c.RemoveAll(bson.M{"userID": userID})
+	return err
+}
@@ -0,0 +1,57 @@
+package mongodb
+
+import (
+	"errors"
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+)
+
+type UploadRepository struct {
+	c *mgo.Collection
+}
+
+func newUploadRepository(database *mgo.Database) *UploadRepository {
+	collection := database.C("upload")
+
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"courseID"}, Sparse: true}); err != nil {
+		log.Printf("error creating index on uploads: %v", err)
+	}
+
+	return &UploadRepository{
+		c: collection,
+	}
+}
+
+func (u *UploadRepository) Insert(upload *eduboard.Upload) error {
+	if upload.ID == "" {
+		upload.ID = bson.NewObjectId()
+	}
+	return u.c.Insert(upload)
+}
+
+func (u *UploadRepository) FindByCourse(courseID string) (error, []eduboard.Upload) {
+	result := []eduboard.Upload{}
+	if err := u.c.Find(bson.M{"courseID": courseID}).All(&result); err != nil {
+		return err, []eduboard.Upload{}
+	}
+	return nil, result
+}
+
+func (u *UploadRepository) DeleteByCourse(courseID string) error {
+	_, err := u.c.RemoveAll(bson.M{"courseID": courseID})
+	return err
+}
+
+func (u *UploadRepository) Find(id string) (error, eduboard.Upload) {
+	result := eduboard.Upload{}
+
+	if !bson.IsObjectIdHex(id) {
+		return errors.New("invalid id"), result
+	}
+	if err := u.c.FindId(bson.ObjectIdHex(id)).One(&result); err != nil {
+		return err, eduboard.Upload{}
+	}
+	return nil, result
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/eduboard/backend"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
+	"log"
+	"net/url"
 )
 
 type UserRepository struct {
@@ -13,6 +15,15 @@ type UserRepository struct {
 
 func newUserRepository(database *mgo.Database) *UserRepository {
 	collection := database.C("user")
+
+	// Accounts created before email verification existed have no verified field and count as verified.
+	if _, err := collection.UpdateAll(bson.M{"verified": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"verified": true}}); err != nil {
+		log.Printf("error marking existing users as verified: %v", err)
+	}
+	if err := collection.EnsureIndex(mgo.Index{Key: []string{"calendarToken"}, Unique: true, Sparse: true}); err != nil {
+		log.Printf("error creating index on users: %v", err)
+	}
+
 	return &UserRepository{
 		c: collection,
 	}
@@ -48,18 +59,18 @@ func (u *UserRepository) FindMany(query bson.M) ([]eduboard.User, error) {
 	return result, nil
 }
 
-func (u *UserRepository) FindBySessionID(sessionID string) (error, eduboard.User) {
-	if sessionID == "" {
+func (u *UserRepository) FindByEmail(email string) (error, eduboard.User) {
+	if email == "" {
 		return errors.New("not found"), eduboard.User{}
 	}
-	return u.findBy("sessionID", sessionID)
+	return u.findBy("email", email)
 }
 
-func (u *UserRepository) FindByEmail(email string) (error, eduboard.User) {
-	if email == "" {
+func (u *UserRepository) FindByCalendarToken(tokenHash string) (error, eduboard.User) {
+	if tokenHash == "" {
 		return errors.New("not found"), eduboard.User{}
 	}
-	return u.findBy("email", email)
+	return u.findBy("calendarToken", tokenHash)
 }
 
 func (u *UserRepository) findBy(key string, value string) (error, eduboard.User) {
@@ -91,8 +102,33 @@ func (u *UserRepository) FindMembers(members []string) (error, []eduboard.User)
 	return nil, result
 }
 
-func (u *UserRepository) UpdateSessionID(user eduboard.User) (error, eduboard.User) {
-	return u.updateValue(user.ID.Hex(), bson.M{"$set": bson.M{"sessionID": user.SessionID}})
+func (u *UserRepository) UpdatePassword(id string, passwordHash string) error {
+	err, _ := u.updateValue(id, bson.M{"$set": bson.M{"password": passwordHash}})
+	return err
+}
+
+func (u *UserRepository) SetVerified(id string) error {
+	err, _ := u.updateValue(id, bson.M{"$set": bson.M{"verified": true}})
+	return err
+}
+
+func (u *UserRepository) SetPicture(id string, picture url.URL) error {
+	err, _ := u.updateValue(id, bson.M{"$set": bson.M{"profilePicture": picture}})
+	return err
+}
+
+func (u *UserRepository) SetCalendarToken(id string, tokenHash string) error {
+	change := bson.M{"$set": bson.M{"calendarToken": tokenHash}}
+	if tokenHash == "" {
+		change = bson.M{"$unset": bson.M{"calendarToken": ""}}
+	}
+	err, _ := u.updateValue(id, change)
+	return err
+}
+
+func (u *UserRepository) RemoveCourse(courseID string) error {
+	_, err := u.c.UpdateAll(bson.M{"courses": courseID}, bson.M{"$pull": bson.M{"courses": courseID}})
+	return err
 }
 
 func (u *UserRepository) updateValue(id string, change bson.M) (error, eduboard.User) {
@@ -0,0 +1,53 @@
+package mongodb
+
+import (
+	"github.com/eduboard/backend"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"log"
+	"time"
+)
+
+type VerificationRepository struct {
+	c *mgo.Collection
+}
+
+func newVerificationRepository(database *mgo.Database) *VerificationRepository {
+	collection := database.C("verification")
+
+	indexes := []mgo.Index{
+		{Key: []string{"tokenHash"}, Unique: true},
+		{Key: []string{"expiresAt"}, ExpireAfter: time.Second},
+	}
+	for _, index := range indexes {
+		if err := collection.EnsureIndex(index); err != nil {
+			log.Printf("error creating index %v on verifications: %v", index.Key, err)
+		}
+	}
+
+	return &VerificationRepository{
+		c: collection,
+	}
+}
+
+func (v *VerificationRepository) Insert(token *eduboard.VerificationToken) error {
+	if token.ID == "" {
+		token.ID = bson.NewObjectId()
+	}
+	return v.c.Insert(token)
+}
+
+func (v *VerificationRepository) Consume(tokenHash string, now time.Time) (error, eduboard.VerificationToken) {
+	result := eduboard.VerificationToken{}
+
+	// Finding and marking the token in one operation makes sure it can only be used once.
+	change := mgo.Change{
+		Update:    bson.M{"$set": bson.M{"used": true}},
+		ReturnNew: true,
+	}
+	query := bson.M{"tokenHash": tokenHash, "used": false, "expiresAt": bson.M{"$gt": now}}
+	if _, err := v.c.Find(query).Apply(change, &result); err != nil {
+		return err, eduboard.VerificationToken{}
+	}
+	return nil, result
+}
@@ -0,0 +1,66 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// NotificationType is the kind of change a Notification tells a user about.
+type NotificationType string
+
+const (
+	NotificationEntryPublished      NotificationType = "entry.published"
+	NotificationMemberAdded         NotificationType = "member.added"
+	NotificationScheduleChanged     NotificationType = "schedule.changed"
+	NotificationEnrollmentRequested NotificationType = "enrollment.requested"
+	NotificationEnrollmentRejected  NotificationType = "enrollment.rejected"
+)
+
+const (
+	// DefaultNotificationLimit is the number of notifications listed if no limit is given.
+	DefaultNotificationLimit = 20
+	// MaxNotificationLimit is the largest number of notifications listed at once.
+	MaxNotificationLimit = 100
+	// NotificationRetention is the time after which notifications are removed, read or not.
+	NotificationRetention = 90 * 24 * time.Hour
+)
+
+// Notification tells a user about something that happened in one of their courses while they were away.
+// Unlike an Event it is stored until the user has seen it.
+type Notification struct {
+	ID          bson.ObjectId    `json:"id" bson:"_id"`
+	UserID      string           `json:"-" bson:"userID"`
+	Type        NotificationType `json:"type" bson:"type"`
+	CourseID    bson.ObjectId    `json:"courseID" bson:"courseID"`
+	CourseTitle string           `json:"courseTitle" bson:"courseTitle"`
+	EntryID     bson.ObjectId    `json:"entryID,omitempty" bson:"entryID,omitempty"`
+	ScheduleID  bson.ObjectId    `json:"scheduleID,omitempty" bson:"scheduleID,omitempty"`
+	// ActorID is the user who caused the notification, if any.
+	ActorID   string    `json:"actorID,omitempty" bson:"actorID,omitempty"`
+	Read      bool      `json:"read" bson:"read"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+type NotificationRepository interface {
+	Insert(notifications []Notification) error
+	// FindByUser returns the latest notifications of a user, newest first.
+	FindByUser(userID string, unread bool, limit int) (error, []Notification)
+	MarkRead(id string, userID string) error
+	MarkAllRead(userID string) error
+	CountUnread(userID string) (error, int)
+	DeleteByCourse(courseID string) error
+}
+
+// NotificationCreator stores a copy of a notification for each of the given users.
+// Notifications are best effort, failures do not undo the change they report.
+type NotificationCreator interface {
+	CreateNotifications(userIDs []string, notification Notification)
+}
+
+type NotificationService interface {
+	NotificationCreator
+	GetNotifications(userID string, unread bool, limit int) (error, []Notification)
+	MarkRead(id string, userID string) error
+	MarkAllRead(userID string) error
+	CountUnread(userID string) (error, int)
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"github.com/eduboard/backend"
+	"log"
+)
+
+// EntryPublishedNotifier informs all students of a course through a Notifier when one of its entries is published.
+// Staff members are left out, as they published the entry themselves.
+type EntryPublishedNotifier struct {
+	Notifier eduboard.Notifier
+	Users    eduboard.UserFinder
+	Logger   *log.Logger
+}
+
+var _ eduboard.EntryPublishedHook = (*EntryPublishedNotifier)(nil)
+
+func (e *EntryPublishedNotifier) EntryPublished(course eduboard.Course, entry eduboard.CourseEntry) {
+	recipients := []string{}
+	for _, m := range course.Members {
+		if !m.Role.IsStaff() {
+			recipients = append(recipients, m.UserID)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	err, users := e.Users.FindMembers(recipients)
+	if err != nil {
+		e.Logger.Printf("error finding members of course %s: %v", course.ID.Hex(), err)
+		return
+	}
+
+	for _, u := range users {
+		if err := e.Notifier.NotifyEntryPublished(u, course, entry); err != nil {
+			e.Logger.Printf("error notifying %s about entry %s: %v", u.ID.Hex(), entry.ID.Hex(), err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+)
+
+func TestEntryPublishedNotifier_EntryPublished(t *testing.T) {
+	course := eduboard.Course{ID: "1", Title: "Course", Members: []eduboard.Member{
+		{UserID: "owner", Role: eduboard.RoleOwner},
+		{UserID: "teacher", Role: eduboard.RoleTeacher},
+		{UserID: "student1", Role: eduboard.RoleStudent},
+		{UserID: "student2", Role: eduboard.RoleStudent},
+	}}
+
+	var requested []string
+	users := mock.UserRepository{}
+	users.FindMembersFn = func(members []string) (error, []eduboard.User) {
+		requested = members
+		return nil, []eduboard.User{{ID: "student1"}, {ID: "student2"}}
+	}
+
+	var notified []string
+	notifier := mock.Notifier{}
+	notifier.NotifyEntryPublishedFn = func(user eduboard.User, c eduboard.Course, entry eduboard.CourseEntry) error {
+		notified = append(notified, string(user.ID))
+		if user.ID == "student2" {
+			return errors.New("mailbox full")
+		}
+		return nil
+	}
+
+	b := &bytes.Buffer{}
+	n := EntryPublishedNotifier{Notifier: &notifier, Users: &users, Logger: log.New(b, "", 0)}
+	n.EntryPublished(course, eduboard.CourseEntry{ID: "2"})
+
+	assert.Equal(t, []string{"student1", "student2"}, requested, "staff should not be notified")
+	assert.Equal(t, []string{"student1", "student2"}, notified, "not all students were notified")
+	assert.Contains(t, b.String(), "mailbox full", "log does not contain error")
+}
+
+func TestEntryPublishedNotifier_EntryPublished_NoStudents(t *testing.T) {
+	users := mock.UserRepository{}
+	n := EntryPublishedNotifier{Notifier: &mock.Notifier{}, Users: &users, Logger: log.New(&bytes.Buffer{}, "", 0)}
+	n.EntryPublished(eduboard.Course{Members: []eduboard.Member{{UserID: "owner", Role: eduboard.RoleOwner}}}, eduboard.CourseEntry{})
+	assert.False(t, users.FindMembersFnInvoked, "FindMembers was invoked")
+}
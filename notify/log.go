@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"github.com/eduboard/backend"
+	"log"
+	"time"
+)
+
+// LogNotifier writes notifications to a log instead of delivering them. It is meant for local development,
+// where the logger can be pointed to stdout or a file.
+type LogNotifier struct {
+	Logger *log.Logger
+}
+
+var _ eduboard.Notifier = (*LogNotifier)(nil)
+
+func (l *LogNotifier) NotifyPasswordReset(user eduboard.User, token string, expires time.Time) error {
+	l.Logger.Printf("password reset for %s (%s): token %s, valid until %s", user.Email, user.ID.Hex(), token, expires.Format(time.RFC3339))
+	return nil
+}
+
+func (l *LogNotifier) NotifyVerification(user eduboard.User, token string, expires time.Time) error {
+	l.Logger.Printf("email verification for %s (%s): token %s, valid until %s", user.Email, user.ID.Hex(), token, expires.Format(time.RFC3339))
+	return nil
+}
+
+func (l *LogNotifier) NotifyEntryPublished(user eduboard.User, course eduboard.Course, entry eduboard.CourseEntry) error {
+	l.Logger.Printf("entry %s published in course %s (%s) for %s (%s)", entry.ID.Hex(), course.Title, course.ID.Hex(), user.Email, user.ID.Hex())
+	return nil
+}
+
+func (l *LogNotifier) NotifyInvite(email string, course eduboard.Course, token string, expires time.Time) error {
+	l.Logger.Printf("invite to course %s (%s) for %s: token %s, valid until %s", course.Title, course.ID.Hex(), email, token, expires.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestLogNotifier_NotifyPasswordReset(t *testing.T) {
+	b := &bytes.Buffer{}
+	n := LogNotifier{Logger: log.New(b, "", 0)}
+
+	err := n.NotifyPasswordReset(eduboard.User{ID: "1", Email: "e@mail.com"}, "secret-token", time.Now())
+	assert.Nil(t, err, "should not cause error")
+	assert.Contains(t, b.String(), "e@mail.com", "log does not contain email")
+	assert.Contains(t, b.String(), "secret-token", "log does not contain token")
+}
+
+func TestLogNotifier_NotifyVerification(t *testing.T) {
+	b := &bytes.Buffer{}
+	n := LogNotifier{Logger: log.New(b, "", 0)}
+
+	err := n.NotifyVerification(eduboard.User{ID: "1", Email: "e@mail.com"}, "secret-token", time.Now())
+	assert.Nil(t, err, "should not cause error")
+	assert.Contains(t, b.String(), "e@mail.com", "log does not contain email")
+	assert.Contains(t, b.String(), "secret-token", "log does not contain token")
+}
+
+func TestLogNotifier_NotifyEntryPublished(t *testing.T) {
+	b := &bytes.Buffer{}
+	n := LogNotifier{Logger: log.New(b, "", 0)}
+
+	err := n.NotifyEntryPublished(eduboard.User{ID: "1", Email: "e@mail.com"}, eduboard.Course{ID: "2", Title: "Course"}, eduboard.CourseEntry{ID: "3"})
+	assert.Nil(t, err, "should not cause error")
+	assert.Contains(t, b.String(), "e@mail.com", "log does not contain email")
+	assert.Contains(t, b.String(), "Course", "log does not contain course")
+}
+
+func TestLogNotifier_NotifyInvite(t *testing.T) {
+	b := &bytes.Buffer{}
+	n := LogNotifier{Logger: log.New(b, "", 0)}
+
+	err := n.NotifyInvite("e@mail.com", eduboard.Course{ID: "2", Title: "Course"}, "secret-token", time.Now())
+	assert.Nil(t, err, "should not cause error")
+	assert.Contains(t, b.String(), "e@mail.com", "log does not contain email")
+	assert.Contains(t, b.String(), "secret-token", "log does not contain token")
+}
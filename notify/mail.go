@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mail"
+	"net/url"
+	"time"
+)
+
+var passwordResetTemplate = mail.MustTemplate("passwordReset",
+	`Reset your eduBoard password`,
+	`Hello {{.Name}},
+
+someone requested to reset the password of your eduBoard account. If this was you, open the following link
+to choose a new password:
+
+{{.Link}}
+
+The link is valid until {{.Expires.Format "02.01.2006 15:04 MST"}}. If you did not request a new password, you can ignore this email.
+`,
+	`<p>Hello {{.Name}},</p>
+<p>someone requested to reset the password of your eduBoard account. If this was you, click the following link to choose a new password:</p>
+<p><a href="{{.Link}}">Reset password</a></p>
+<p>The link is valid until {{.Expires.Format "02.01.2006 15:04 MST"}}. If you did not request a new password, you can ignore this email.</p>
+`)
+
+var verificationTemplate = mail.MustTemplate("verification",
+	`Confirm your eduBoard email address`,
+	`Hello {{.Name}},
+
+welcome to eduBoard! Please open the following link to confirm your email address:
+
+{{.Link}}
+
+The link is valid until {{.Expires.Format "02.01.2006 15:04 MST"}}. If you did not create an account, you can ignore this email.
+`,
+	`<p>Hello {{.Name}},</p>
+<p>welcome to eduBoard! Please click the following link to confirm your email address:</p>
+<p><a href="{{.Link}}">Confirm email address</a></p>
+<p>The link is valid until {{.Expires.Format "02.01.2006 15:04 MST"}}. If you did not create an account, you can ignore this email.</p>
+`)
+
+var entryPublishedTemplate = mail.MustTemplate("entryPublished",
+	`New entry in {{.Course}}`,
+	`Hello {{.Name}},
+
+a new entry has been published in {{.Course}}:
+
+{{.Message}}
+
+{{.Link}}
+`,
+	`<p>Hello {{.Name}},</p>
+<p>a new entry has been published in {{.Course}}:</p>
+<blockquote>{{.Message}}</blockquote>
+<p><a href="{{.Link}}">Open course</a></p>
+`)
+
+var inviteTemplate = mail.MustTemplate("invite",
+	`You have been invited to {{.Course}}`,
+	`Hello,
+
+you have been invited to join {{.Course}} on eduBoard. Open the following link to create an account
+and join the course:
+
+{{.Link}}
+
+The link is valid until {{.Expires.Format "02.01.2006 15:04 MST"}}. If you do not want to join, you can ignore this email.
+`,
+	`<p>Hello,</p>
+<p>you have been invited to join {{.Course}} on eduBoard. Click the following link to create an account and join the course:</p>
+<p><a href="{{.Link}}">Join course</a></p>
+<p>The link is valid until {{.Expires.Format "02.01.2006 15:04 MST"}}. If you do not want to join, you can ignore this email.</p>
+`)
+
+// MailNotifier sends notifications as emails through a mail.Mailer.
+// Links in emails point to the frontend served at BaseURL.
+type MailNotifier struct {
+	Mailer  mail.Mailer
+	From    string
+	BaseURL string
+}
+
+var _ eduboard.Notifier = (*MailNotifier)(nil)
+
+func (m *MailNotifier) NotifyPasswordReset(user eduboard.User, token string, expires time.Time) error {
+	data := struct {
+		Name    string
+		Link    string
+		Expires time.Time
+	}{user.Name, m.link("/reset-password", url.Values{"token": {token}}), expires}
+
+	return m.send(passwordResetTemplate, user, data)
+}
+
+func (m *MailNotifier) NotifyVerification(user eduboard.User, token string, expires time.Time) error {
+	data := struct {
+		Name    string
+		Link    string
+		Expires time.Time
+	}{user.Name, m.link("/verify-email", url.Values{"token": {token}}), expires}
+
+	return m.send(verificationTemplate, user, data)
+}
+
+func (m *MailNotifier) NotifyEntryPublished(user eduboard.User, course eduboard.Course, entry eduboard.CourseEntry) error {
+	data := struct {
+		Name    string
+		Course  string
+		Message string
+		Link    string
+	}{user.Name, course.Title, entry.Message, m.BaseURL + "/courses/" + course.ID.Hex()}
+
+	return m.send(entryPublishedTemplate, user, data)
+}
+
+func (m *MailNotifier) NotifyInvite(email string, course eduboard.Course, token string, expires time.Time) error {
+	data := struct {
+		Course  string
+		Link    string
+		Expires time.Time
+	}{course.Title, m.link("/register", url.Values{"invite": {token}}), expires}
+
+	return m.send(inviteTemplate, eduboard.User{Email: email}, data)
+}
+
+func (m *MailNotifier) send(t *mail.Template, user eduboard.User, data interface{}) error {
+	msg, err := t.Render(m.From, []string{user.Email}, data)
+	if err != nil {
+		return err
+	}
+	return m.Mailer.Send(msg)
+}
+
+func (m *MailNotifier) link(path string, query url.Values) string {
+	return m.BaseURL + path + "?" + query.Encode()
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mail"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+type recordingMailer struct {
+	sent []mail.Message
+}
+
+func (r *recordingMailer) Send(m mail.Message) error {
+	r.sent = append(r.sent, m)
+	return nil
+}
+
+func TestMailNotifier_NotifyPasswordReset(t *testing.T) {
+	mailer := &recordingMailer{}
+	n := MailNotifier{Mailer: mailer, From: "noreply@eduboard.io", BaseURL: "https://eduboard.io"}
+
+	err := n.NotifyPasswordReset(eduboard.User{ID: "1", Name: "Mathias", Email: "e@mail.com"}, "secret token", time.Now())
+	assert.Nil(t, err, "should not cause error")
+	assert.Len(t, mailer.sent, 1, "no mail was sent")
+	assert.Equal(t, []string{"e@mail.com"}, mailer.sent[0].To, "recipient does not match")
+	assert.Contains(t, mailer.sent[0].Text, "https://eduboard.io/reset-password?token=secret+token", "text does not contain link")
+	assert.Contains(t, mailer.sent[0].HTML, "https://eduboard.io/reset-password?token=secret&#43;token", "html does not contain link")
+}
+
+func TestMailNotifier_NotifyVerification(t *testing.T) {
+	mailer := &recordingMailer{}
+	n := MailNotifier{Mailer: mailer, From: "noreply@eduboard.io", BaseURL: "https://eduboard.io"}
+
+	err := n.NotifyVerification(eduboard.User{ID: "1", Name: "Mathias", Email: "e@mail.com"}, "token", time.Now())
+	assert.Nil(t, err, "should not cause error")
+	assert.Len(t, mailer.sent, 1, "no mail was sent")
+	assert.Equal(t, []string{"e@mail.com"}, mailer.sent[0].To, "recipient does not match")
+	assert.Contains(t, mailer.sent[0].Text, "https://eduboard.io/verify-email?token=token", "text does not contain link")
+}
+
+func TestMailNotifier_NotifyEntryPublished(t *testing.T) {
+	mailer := &recordingMailer{}
+	n := MailNotifier{Mailer: mailer, From: "noreply@eduboard.io", BaseURL: "https://eduboard.io"}
+
+	course := eduboard.Course{ID: bson.ObjectIdHex("5b23c8d5382d33000150681a"), Title: "Algorithms"}
+	err := n.NotifyEntryPublished(eduboard.User{ID: "1", Name: "Mathias", Email: "e@mail.com"}, course, eduboard.CourseEntry{Message: "Exam on Friday"})
+	assert.Nil(t, err, "should not cause error")
+	assert.Len(t, mailer.sent, 1, "no mail was sent")
+	assert.Equal(t, "New entry in Algorithms", mailer.sent[0].Subject, "subject does not match")
+	assert.Contains(t, mailer.sent[0].Text, "Exam on Friday", "text does not contain message")
+	assert.Contains(t, mailer.sent[0].Text, "https://eduboard.io/courses/5b23c8d5382d33000150681a", "text does not contain link")
+}
+
+func TestMailNotifier_NotifyInvite(t *testing.T) {
+	mailer := &recordingMailer{}
+	n := MailNotifier{Mailer: mailer, From: "noreply@eduboard.io", BaseURL: "https://eduboard.io"}
+
+	err := n.NotifyInvite("e@mail.com", eduboard.Course{Title: "Algorithms"}, "token", time.Now())
+	assert.Nil(t, err, "should not cause error")
+	assert.Len(t, mailer.sent, 1, "no mail was sent")
+	assert.Equal(t, []string{"e@mail.com"}, mailer.sent[0].To, "recipient does not match")
+	assert.Equal(t, "You have been invited to Algorithms", mailer.sent[0].Subject, "subject does not match")
+	assert.Contains(t, mailer.sent[0].Text, "https://eduboard.io/register?invite=token", "text does not contain link")
+}
@@ -0,0 +1,36 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// PasswordResetToken allows a user to set a new password without knowing the old one.
+// Only the hash of the token is stored, the token itself is handed to the user through a Notifier.
+type PasswordResetToken struct {
+	ID        bson.ObjectId `json:"id" bson:"_id"`
+	UserID    string        `json:"userID" bson:"userID"`
+	TokenHash string        `json:"-" bson:"tokenHash"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+	ExpiresAt time.Time     `json:"expiresAt" bson:"expiresAt"`
+	Used      bool          `json:"used" bson:"used"`
+}
+
+type PasswordResetRepository interface {
+	Insert(token *PasswordResetToken) error
+	// Consume marks the unused and unexpired token with the given hash as used and returns it.
+	Consume(tokenHash string, now time.Time) (error, PasswordResetToken)
+	// Release marks a consumed token as unused again.
+	Release(id string) error
+	// DeleteByUser deletes all tokens of the user with the given ID.
+	DeleteByUser(userID string) error
+}
+
+// Notifier delivers messages to users outside of the application.
+type Notifier interface {
+	NotifyPasswordReset(user User, token string, expires time.Time) error
+	NotifyVerification(user User, token string, expires time.Time) error
+	NotifyEntryPublished(user User, course Course, entry CourseEntry) error
+	// NotifyInvite invites someone without an account to a course.
+	NotifyInvite(email string, course Course, token string, expires time.Time) error
+}
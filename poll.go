@@ -0,0 +1,122 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// PollKind tells polls, which only collect opinions, apart from quizzes, which have correct answers.
+type PollKind string
+
+const (
+	PollKindPoll PollKind = "poll"
+	PollKindQuiz PollKind = "quiz"
+)
+
+const (
+	// MaxPollQuestions is the largest number of questions of a poll.
+	MaxPollQuestions = 20
+	// MaxPollOptions is the largest number of options of a question.
+	MaxPollOptions = 10
+	// MaxPollTextLength is the largest number of characters of a question or option.
+	MaxPollTextLength = 500
+)
+
+// Poll is attached to a course entry. Every member may respond to it once, as long as it is not closed.
+type Poll struct {
+	Kind PollKind `json:"kind" bson:"kind"`
+	// Anonymous polls never reveal who chose which option, not even to staff. Quizzes can not be anonymous.
+	Anonymous bool           `json:"anonymous" bson:"anonymous"`
+	Questions []PollQuestion `json:"questions" bson:"questions"`
+	Closed    bool           `json:"closed" bson:"closed"`
+	CreatedAt time.Time      `json:"createdAt" bson:"createdAt"`
+	// Answered is set before the first response is stored. Answered polls can not be replaced.
+	Answered bool `json:"-" bson:"answered,omitempty"`
+}
+
+type PollQuestion struct {
+	Text    string   `json:"text" bson:"text"`
+	Options []string `json:"options" bson:"options"`
+	// Multiple questions allow choosing more than one option.
+	Multiple bool `json:"multiple" bson:"multiple"`
+	// Correct holds the indices of the correct options of quiz questions.
+	Correct []int `json:"correct,omitempty" bson:"correct,omitempty"`
+}
+
+// WithoutAnswers returns a copy of the poll without the correct answers of its questions.
+func (p Poll) WithoutAnswers() Poll {
+	questions := make([]PollQuestion, len(p.Questions))
+	for k, q := range p.Questions {
+		q.Correct = nil
+		questions[k] = q
+	}
+	p.Questions = questions
+	return p
+}
+
+// PollResponse holds the options a member chose for every question of a poll. Responses to quizzes are graded
+// right away: Correct tells which questions were answered right and Score counts them.
+type PollResponse struct {
+	ID        bson.ObjectId `json:"id" bson:"_id"`
+	CourseID  bson.ObjectId `json:"courseID" bson:"courseID"`
+	EntryID   bson.ObjectId `json:"entryID" bson:"entryID"`
+	UserID    string        `json:"userID" bson:"userID"`
+	Answers   [][]int       `json:"answers" bson:"answers"`
+	Correct   []bool        `json:"correct,omitempty" bson:"correct,omitempty"`
+	Score     *int          `json:"score,omitempty" bson:"score,omitempty"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+}
+
+// PollResults aggregates all responses to a poll.
+type PollResults struct {
+	EntryID   bson.ObjectId        `json:"entryID"`
+	Kind      PollKind             `json:"kind"`
+	Closed    bool                 `json:"closed"`
+	Responses int                  `json:"responses"`
+	Questions []PollQuestionResult `json:"questions"`
+	// MaxScore and MeanScore are only set for quizzes. MeanScore is nil until the first response.
+	MaxScore  int      `json:"maxScore,omitempty"`
+	MeanScore *float64 `json:"meanScore,omitempty"`
+	// Scores lists the score of every member who answered a quiz. Only staff gets to see them.
+	Scores []PollScore `json:"scores,omitempty"`
+}
+
+// PollQuestionResult counts how often each option of a question was chosen.
+type PollQuestionResult struct {
+	Counts  []int `json:"counts"`
+	Correct []int `json:"correct,omitempty"`
+	// Voters lists the users who chose each option. Only staff gets to see the voters of polls that are not anonymous.
+	Voters [][]string `json:"voters,omitempty"`
+}
+
+type PollScore struct {
+	UserID string `json:"userID"`
+	Score  int    `json:"score"`
+}
+
+type PollResponseRepository interface {
+	PollResponseDeleter
+	// Insert fails with ErrDuplicate if the member responded to the poll already.
+	Insert(response PollResponse) error
+	Find(entryID string, userID string) (error, PollResponse)
+	// FindByEntry returns all responses to the poll of an entry, oldest first.
+	FindByEntry(entryID string) (error, []PollResponse)
+	CountByEntry(entryID string) (error, int)
+	DeleteByCourse(courseID string) error
+}
+
+type PollResponseDeleter interface {
+	// DeleteByEntry deletes all responses to the poll of the entry with the given ID.
+	DeleteByEntry(entryID string) error
+}
+
+type PollService interface {
+	// SetPoll attaches a poll to an entry, replacing the previous one as long as nobody responded to it.
+	SetPoll(courseID string, entryID string, userID string, poll Poll) (error, Poll)
+	DeletePoll(courseID string, entryID string, userID string) error
+	ClosePoll(courseID string, entryID string, userID string) (error, Poll)
+	Respond(courseID string, entryID string, userID string, answers [][]int) (error, PollResponse)
+	GetResponse(courseID string, entryID string, userID string) (error, PollResponse)
+	GetResults(courseID string, entryID string, userID string) (error, PollResults)
+	DeleteByCourse(courseID string) error
+}
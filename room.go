@@ -0,0 +1,66 @@
+package eduboard
+
+import (
+	"fmt"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"time"
+)
+
+// Room is a registered room schedules can take place in. Schedules refer to rooms by Name.
+type Room struct {
+	ID       bson.ObjectId `json:"id" bson:"_id"`
+	Name     string        `json:"name" bson:"name"`
+	Building string        `json:"building,omitempty" bson:"building,omitempty"`
+	Capacity int           `json:"capacity,omitempty" bson:"capacity,omitempty"`
+	// CreatedBy is the user who registered the room. Only they may change or delete it.
+	CreatedBy string    `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// RoomUpdate holds the fields of a room to change. Nil fields are left as they are.
+type RoomUpdate struct {
+	Name     *string
+	Building *string
+	Capacity *int
+}
+
+// RoomConflict is a meeting of another schedule that takes place in the same room at the same time.
+type RoomConflict struct {
+	Room        string        `json:"room"`
+	CourseID    bson.ObjectId `json:"courseID"`
+	CourseTitle string        `json:"courseTitle"`
+	ScheduleID  bson.ObjectId `json:"scheduleID"`
+	Start       time.Time     `json:"startsAt"`
+	End         time.Time     `json:"endsAt"`
+}
+
+// RoomConflictError is returned by services if schedules would occupy a room that is already in use.
+type RoomConflictError struct {
+	Conflicts []RoomConflict
+}
+
+func (e *RoomConflictError) Error() string {
+	courses := make([]string, len(e.Conflicts))
+	for k, c := range e.Conflicts {
+		courses[k] = fmt.Sprintf("%s in %s on %s", c.CourseID.Hex(), c.Room, c.Start.Format(time.RFC3339))
+	}
+	return "room conflicts with " + strings.Join(courses, ", ")
+}
+
+type RoomRepository interface {
+	Insert(room *Room) error
+	FindAll() (error, []Room)
+	FindOneByID(id string) (error, Room)
+	FindByName(name string) (error, Room)
+	Update(id string, update bson.M) (error, Room)
+	Delete(id string) error
+}
+
+type RoomService interface {
+	CreateRoom(room *Room, userID string) (error, Room)
+	GetRooms() (error, []Room)
+	GetRoom(id string) (error, Room)
+	UpdateRoom(id string, userID string, update RoomUpdate) (error, Room)
+	DeleteRoom(id string, userID string) error
+}
@@ -0,0 +1,96 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// DateFormat is the format of calendar dates such as term boundaries and exception dates.
+const DateFormat = "2006-01-02"
+
+// MaxOccurrenceRange is the longest time range occurrences can be expanded for at once.
+const MaxOccurrenceRange = 366 * 24 * time.Hour
+
+// Schedule is a recurring meeting of a course. It takes place every Interval weeks on Day at the time of day of Start,
+// from the first Day on or after TermStart until TermEnd. Without a term, meetings start on the date of Start and never end.
+type Schedule struct {
+	ID       bson.ObjectId `json:"id,omitempty" bson:"id,omitempty"`
+	Day      time.Weekday  `json:"day" bson:"day"`
+	Start    time.Time     `json:"startsAt" bson:"startsAt"`
+	Duration time.Duration `json:"duration,omitempty" bson:"duration"`
+	Room     string        `json:"room,omitempty" bson:"room"`
+	Title    string        `json:"title,omitempty" bson:"title"`
+	// TimeZone is the IANA name of the time zone meetings are held in. It defaults to UTC, as the database does not keep
+	// the location of Start.
+	TimeZone string `json:"timeZone,omitempty" bson:"timeZone,omitempty"`
+	// Interval is the number of weeks between two meetings, 0 and 1 both meaning weekly.
+	Interval   int                 `json:"interval,omitempty" bson:"interval,omitempty"`
+	TermStart  string              `json:"termStart,omitempty" bson:"termStart,omitempty"`
+	TermEnd    string              `json:"termEnd,omitempty" bson:"termEnd,omitempty"`
+	Exceptions []ScheduleException `json:"exceptions,omitempty" bson:"exceptions,omitempty"`
+}
+
+// ScheduleException cancels or moves the single meeting of a schedule on Date.
+// Unset fields of a moved meeting keep their regular values.
+type ScheduleException struct {
+	Date      string        `json:"date" bson:"date"`
+	Cancelled bool          `json:"cancelled,omitempty" bson:"cancelled,omitempty"`
+	Start     *time.Time    `json:"startsAt,omitempty" bson:"startsAt,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty" bson:"duration,omitempty"`
+	Room      string        `json:"room,omitempty" bson:"room,omitempty"`
+}
+
+// Occurrence is a single meeting of a schedule. Date is the regular date of the meeting, even if it has been moved.
+type Occurrence struct {
+	CourseID   bson.ObjectId `json:"courseID"`
+	ScheduleID bson.ObjectId `json:"scheduleID"`
+	Date       string        `json:"date"`
+	Start      time.Time     `json:"startsAt"`
+	End        time.Time     `json:"endsAt"`
+	Title      string        `json:"title,omitempty"`
+	Room       string        `json:"room,omitempty"`
+	Cancelled  bool          `json:"cancelled,omitempty"`
+	Moved      bool          `json:"moved,omitempty"`
+}
+
+// TimetableEntry is a meeting in the timetable of a user. OverlapsWith lists the courses of other meetings of the
+// timetable that take place at the same time.
+type TimetableEntry struct {
+	Occurrence
+	CourseTitle  string          `json:"courseTitle"`
+	Overlapping  bool            `json:"overlapping"`
+	OverlapsWith []bson.ObjectId `json:"overlapsWith,omitempty"`
+}
+
+type ScheduleService interface {
+	GetSchedules(courseID string) (error, []Schedule)
+	AddSchedule(courseID string, userID string, schedule Schedule) (error, Schedule)
+	UpdateSchedule(courseID string, scheduleID string, userID string, schedule Schedule) (error, Schedule)
+	DeleteSchedule(courseID string, scheduleID string, userID string) error
+	SetException(courseID string, scheduleID string, userID string, exception ScheduleException) (error, Schedule)
+	DeleteException(courseID string, scheduleID string, userID string, date string) (error, Schedule)
+	GetOccurrences(courseID string, from time.Time, to time.Time) (error, []Occurrence)
+	OccurrenceFinder
+	GetTimetable(courses []Course, from time.Time, to time.Time) (error, []TimetableEntry)
+	// ExportCalendar returns the schedules of courses as an iCalendar document.
+	ExportCalendar(name string, courses []Course) (error, []byte)
+	GetRoomOccupancy(roomID string, from time.Time, to time.Time) (error, []Occurrence)
+	ScheduleChecker
+}
+
+// OccurrenceFinder finds single meetings of a course.
+type OccurrenceFinder interface {
+	GetOccurrence(courseID string, scheduleID string, date string) (error, Occurrence)
+}
+
+// MeetingDataDeleter deletes the data of meetings that no longer take place after the schedules of a course changed.
+type MeetingDataDeleter interface {
+	DeleteCancelled(courseID string, of OccurrenceFinder) error
+}
+
+// ScheduleChecker validates schedules and calls store to replace all schedules of a course with them, or to restore
+// an archived course with its schedules. No other schedules are booked until store returns.
+type ScheduleChecker interface {
+	CheckSchedules(course Course, schedules []Schedule, store func() error) error
+	CheckRestore(course Course, store func() error) error
+}
@@ -0,0 +1,56 @@
+// Package access checks what users may do with the data of a course. The services use it to find the course
+// of a request and the data they are asked about.
+package access
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+)
+
+// Member returns the course if userID is one of its members.
+func Member(cf eduboard.CourseOneFinder, courseID string, userID string) (error, eduboard.Course) {
+	err, course := cf.FindOneByID(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", courseID), eduboard.Course{}
+	}
+	if _, ok := course.RoleOf(userID); !ok {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s is no member of course %s", userID, courseID), eduboard.Course{}
+	}
+	return nil, course
+}
+
+// Staff returns the course if userID belongs to its staff.
+func Staff(cf eduboard.CourseOneFinder, courseID string, userID string) (error, eduboard.Course) {
+	err, course := cf.FindOneByID(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", courseID), eduboard.Course{}
+	}
+	if !course.IsStaff(userID) {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s is no staff of course %s", userID, courseID), eduboard.Course{}
+	}
+	return nil, course
+}
+
+// Manager returns the course if userID belongs to its staff and may change its data. Archived courses are read-only.
+func Manager(cf eduboard.CourseOneFinder, courseID string, userID string) (error, eduboard.Course) {
+	err, course := Staff(cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Course{}
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "course %s is archived", courseID), eduboard.Course{}
+	}
+	return nil, course
+}
+
+// Assignment returns an assignment of a course.
+func Assignment(ar eduboard.AssignmentRepository, courseID string, assignmentID string) (error, eduboard.Assignment) {
+	err, assignment := ar.FindOneByID(assignmentID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding assignment %s", assignmentID), eduboard.Assignment{}
+	}
+	if assignment.CourseID.Hex() != courseID {
+		return errors.Wrapf(eduboard.ErrNotFound, "assignment %s does not belong to course %s", assignmentID, courseID), eduboard.Assignment{}
+	}
+	return nil, assignment
+}
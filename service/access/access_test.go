@@ -0,0 +1,116 @@
+package access
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+const (
+	courseID     = "5b23bbdc2bfa844c41a9f134"
+	archivedID   = "5b23bbdc2bfa844c41a9f135"
+	otherID      = "5b23bbdc2bfa844c41a9f136"
+	assignmentID = "5b23bbdc2bfa844c41a9f140"
+)
+
+var members = []eduboard.Member{
+	{UserID: "teacher", Role: eduboard.RoleTeacher},
+	{UserID: "student", Role: eduboard.RoleStudent},
+}
+
+func TestMember(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		course string
+		user   string
+		err    error
+	}{
+		{"student", courseID, "student", nil},
+		{"teacher", courseID, "teacher", nil},
+		{"archived", archivedID, "student", nil},
+		{"stranger", courseID, "stranger", eduboard.ErrForbidden},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err, course := Member(mock.CourseFinder(members, archivedID, courseID), v.course, v.user)
+			assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+			if v.err == nil {
+				assert.Equal(t, v.course, course.ID.Hex(), "course does not match")
+			}
+		})
+	}
+
+	err, _ := Member(mock.CourseFinder(members, archivedID, courseID), otherID, "student")
+	assert.Error(t, err, "did not return error when expected")
+}
+
+func TestStaff(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		course string
+		user   string
+		err    error
+	}{
+		{"teacher", courseID, "teacher", nil},
+		{"archived", archivedID, "teacher", nil},
+		{"student", courseID, "student", eduboard.ErrForbidden},
+		{"stranger", courseID, "stranger", eduboard.ErrForbidden},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err, course := Staff(mock.CourseFinder(members, archivedID, courseID), v.course, v.user)
+			assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+			if v.err == nil {
+				assert.Equal(t, v.course, course.ID.Hex(), "course does not match")
+			}
+		})
+	}
+}
+
+func TestManager(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		course string
+		user   string
+		err    error
+	}{
+		{"teacher", courseID, "teacher", nil},
+		{"archived", archivedID, "teacher", eduboard.ErrArchived},
+		{"student", courseID, "student", eduboard.ErrForbidden},
+		{"student of archived", archivedID, "student", eduboard.ErrForbidden},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err, course := Manager(mock.CourseFinder(members, archivedID, courseID), v.course, v.user)
+			assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+			if v.err == nil {
+				assert.Equal(t, v.course, course.ID.Hex(), "course does not match")
+			}
+		})
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	ar := &mock.AssignmentRepository{}
+	ar.FindOneByIDFn = func(id string) (error, eduboard.Assignment) {
+		if id != assignmentID {
+			return errors.New("not found"), eduboard.Assignment{}
+		}
+		return nil, eduboard.Assignment{ID: bson.ObjectIdHex(id), CourseID: bson.ObjectIdHex(courseID)}
+	}
+
+	err, assignment := Assignment(ar, courseID, assignmentID)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Equal(t, assignmentID, assignment.ID.Hex(), "assignment does not match")
+
+	err, _ = Assignment(ar, otherID, assignmentID)
+	assert.Equal(t, eduboard.ErrNotFound, errors.Cause(err), "assignment of other course is not reported as not found")
+	err, _ = Assignment(ar, courseID, otherID)
+	assert.Error(t, err, "did not return error when expected")
+}
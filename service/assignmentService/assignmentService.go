@@ -0,0 +1,272 @@
+package assignmentService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type AssignmentService struct {
+	ar eduboard.AssignmentRepository
+	sr eduboard.SubmissionRepository
+	ur eduboard.UploadRepository
+	cf eduboard.CourseOneFinder
+	// deleters delete further data of an assignment, like its grades, before the assignment itself is deleted.
+	deleters []eduboard.AssignmentDataDeleter
+}
+
+func New(assignments eduboard.AssignmentRepository, submissions eduboard.SubmissionRepository, uploads eduboard.UploadRepository, courseFinder eduboard.CourseOneFinder, deleters ...eduboard.AssignmentDataDeleter) *AssignmentService {
+	return &AssignmentService{
+		ar:       assignments,
+		sr:       submissions,
+		ur:       uploads,
+		cf:       courseFinder,
+		deleters: deleters,
+	}
+}
+
+// GetAssignments returns the assignments of a course, earliest due first. Only members may see them.
+func (aS *AssignmentService) GetAssignments(courseID string, userID string) (error, []eduboard.Assignment) {
+	if err, _ := access.Member(aS.cf, courseID, userID); err != nil {
+		return err, []eduboard.Assignment{}
+	}
+
+	err, assignments := aS.ar.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding assignments of course %s", courseID), []eduboard.Assignment{}
+	}
+	return nil, assignments
+}
+
+func (aS *AssignmentService) GetAssignment(courseID string, assignmentID string, userID string) (error, eduboard.Assignment) {
+	if err, _ := access.Member(aS.cf, courseID, userID); err != nil {
+		return err, eduboard.Assignment{}
+	}
+	return access.Assignment(aS.ar, courseID, assignmentID)
+}
+
+// CreateAssignment hands out a new assignment. Only the staff of a course that is not archived may create assignments.
+func (aS *AssignmentService) CreateAssignment(courseID string, userID string, assignment eduboard.Assignment) (error, eduboard.Assignment) {
+	err, course := access.Manager(aS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Assignment{}
+	}
+
+	assignment.Title = strings.TrimSpace(assignment.Title)
+	if assignment.Title == "" {
+		return errors.Wrap(eduboard.ErrInvalidInput, "assignment title is empty"), eduboard.Assignment{}
+	}
+	if assignment.DueAt.IsZero() {
+		return errors.Wrap(eduboard.ErrInvalidInput, "assignment has no due date"), eduboard.Assignment{}
+	}
+	if assignment.MaxPoints < 0 {
+		return errors.Wrap(eduboard.ErrInvalidInput, "assignment has negative points"), eduboard.Assignment{}
+	}
+	assignment.Category = strings.TrimSpace(assignment.Category)
+	if assignment.Attachments == nil {
+		assignment.Attachments = []string{}
+	}
+	if err = aS.checkAttachments(courseID, "", assignment.Attachments); err != nil {
+		return err, eduboard.Assignment{}
+	}
+
+	assignment.ID = ""
+	assignment.CourseID = course.ID
+	assignment.CreatedBy = userID
+	assignment.CreatedAt = time.Now()
+	if err = aS.ar.Insert(&assignment); err != nil {
+		return errors.Wrapf(err, "error storing assignment for course %s", courseID), eduboard.Assignment{}
+	}
+	return nil, assignment
+}
+
+// UpdateAssignment changes an assignment. Submissions made before a change of the due date keep their late flag.
+func (aS *AssignmentService) UpdateAssignment(courseID string, assignmentID string, userID string, update eduboard.AssignmentUpdate) (error, eduboard.Assignment) {
+	if err, _ := access.Manager(aS.cf, courseID, userID); err != nil {
+		return err, eduboard.Assignment{}
+	}
+	if err, _ := access.Assignment(aS.ar, courseID, assignmentID); err != nil {
+		return err, eduboard.Assignment{}
+	}
+
+	set := bson.M{}
+	if update.Title != nil {
+		title := strings.TrimSpace(*update.Title)
+		if title == "" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "assignment title is empty"), eduboard.Assignment{}
+		}
+		set["title"] = title
+	}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.DueAt != nil {
+		if update.DueAt.IsZero() {
+			return errors.Wrap(eduboard.ErrInvalidInput, "assignment has no due date"), eduboard.Assignment{}
+		}
+		set["dueAt"] = *update.DueAt
+	}
+	if update.Attachments != nil {
+		if err := aS.checkAttachments(courseID, "", update.Attachments); err != nil {
+			return err, eduboard.Assignment{}
+		}
+		set["attachments"] = update.Attachments
+	}
+	if update.MaxPoints != nil {
+		if *update.MaxPoints < 0 {
+			return errors.Wrap(eduboard.ErrInvalidInput, "assignment has negative points"), eduboard.Assignment{}
+		}
+		set["maxPoints"] = *update.MaxPoints
+	}
+	if update.Category != nil {
+		set["category"] = strings.TrimSpace(*update.Category)
+	}
+	if len(set) == 0 {
+		return errors.Wrap(eduboard.ErrInvalidInput, "nothing to update"), eduboard.Assignment{}
+	}
+
+	err, assignment := aS.ar.Update(assignmentID, bson.M{"$set": set})
+	if err != nil {
+		return errors.Wrapf(err, "error updating assignment %s", assignmentID), eduboard.Assignment{}
+	}
+	return nil, assignment
+}
+
+// DeleteAssignment removes an assignment together with its submissions and the data of the deleters.
+func (aS *AssignmentService) DeleteAssignment(courseID string, assignmentID string, userID string) error {
+	if err, _ := access.Manager(aS.cf, courseID, userID); err != nil {
+		return err
+	}
+	if err, _ := access.Assignment(aS.ar, courseID, assignmentID); err != nil {
+		return err
+	}
+
+	// Once the assignment is gone it can not be deleted again, so its submissions and grades have to go first.
+	for _, d := range aS.deleters {
+		if err := d.DeleteByAssignment(assignmentID); err != nil {
+			return errors.Wrapf(err, "error deleting data of assignment %s", assignmentID)
+		}
+	}
+	if err := aS.sr.DeleteByAssignment(assignmentID); err != nil {
+		return errors.Wrapf(err, "error deleting submissions of assignment %s", assignmentID)
+	}
+	if err := aS.ar.Delete(assignmentID); err != nil {
+		return errors.Wrapf(err, "error deleting assignment %s", assignmentID)
+	}
+	return nil
+}
+
+// DeleteByCourse deletes all assignments of a course along with their submissions.
+func (aS *AssignmentService) DeleteByCourse(courseID string) error {
+	if err := aS.sr.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting submissions of course %s", courseID)
+	}
+	if err := aS.ar.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting assignments of course %s", courseID)
+	}
+	return nil
+}
+
+// Submit stores the submission of a student, replacing an earlier one. Submissions after the due date are accepted
+// but flagged as late.
+func (aS *AssignmentService) Submit(courseID string, assignmentID string, userID string, submission eduboard.Submission) (error, eduboard.Submission) {
+	err, course := access.Member(aS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Submission{}
+	}
+	if role, _ := course.RoleOf(userID); role != eduboard.RoleStudent {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s is no student of course %s", userID, courseID), eduboard.Submission{}
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not submit to course %s", courseID), eduboard.Submission{}
+	}
+
+	err, assignment := access.Assignment(aS.ar, courseID, assignmentID)
+	if err != nil {
+		return err, eduboard.Submission{}
+	}
+
+	submission.Text = strings.TrimSpace(submission.Text)
+	if submission.Attachments == nil {
+		submission.Attachments = []string{}
+	}
+	if submission.Text == "" && len(submission.Attachments) == 0 {
+		return errors.Wrap(eduboard.ErrInvalidInput, "submission is empty"), eduboard.Submission{}
+	}
+	if utf8.RuneCountInString(submission.Text) > eduboard.MaxSubmissionLength {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "submission is longer than %d characters", eduboard.MaxSubmissionLength), eduboard.Submission{}
+	}
+	if err = aS.checkAttachments(courseID, userID, submission.Attachments); err != nil {
+		return err, eduboard.Submission{}
+	}
+
+	now := time.Now()
+	submission.ID = ""
+	submission.AssignmentID = assignment.ID
+	submission.CourseID = course.ID
+	submission.UserID = userID
+	submission.SubmittedAt = now
+	submission.Late = now.After(assignment.DueAt)
+	if err = aS.sr.Upsert(&submission); err != nil {
+		return errors.Wrapf(err, "error storing submission for assignment %s", assignmentID), eduboard.Submission{}
+	}
+	return nil, submission
+}
+
+// GetSubmission returns the submission of userID to an assignment.
+func (aS *AssignmentService) GetSubmission(courseID string, assignmentID string, userID string) (error, eduboard.Submission) {
+	if err, _ := access.Assignment(aS.ar, courseID, assignmentID); err != nil {
+		return err, eduboard.Submission{}
+	}
+
+	err, submission := aS.sr.Find(assignmentID, userID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding submission of user %s for assignment %s", userID, assignmentID), eduboard.Submission{}
+	}
+	return nil, submission
+}
+
+// GetSubmissions returns all submissions to an assignment, oldest first. Only the staff may see them.
+func (aS *AssignmentService) GetSubmissions(courseID string, assignmentID string, userID string) (error, []eduboard.Submission) {
+	if err, _ := access.Staff(aS.cf, courseID, userID); err != nil {
+		return err, []eduboard.Submission{}
+	}
+	if err, _ := access.Assignment(aS.ar, courseID, assignmentID); err != nil {
+		return err, []eduboard.Submission{}
+	}
+
+	err, submissions := aS.sr.FindByAssignment(assignmentID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding submissions of assignment %s", assignmentID), []eduboard.Submission{}
+	}
+	return nil, submissions
+}
+
+// checkAttachments makes sure every attachment was uploaded to the course. Unless ownerID is empty, the attachments
+// must also have been uploaded by ownerID, otherwise they must not be private as all members may read them.
+func (aS *AssignmentService) checkAttachments(courseID string, ownerID string, attachments []string) error {
+	if len(attachments) > eduboard.MaxAttachments {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "more than %d attachments", eduboard.MaxAttachments)
+	}
+
+	for _, id := range attachments {
+		err, upload := aS.ur.Find(id)
+		if err != nil {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "unknown attachment %s", id)
+		}
+		if upload.CourseID != courseID {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "attachment %s does not belong to course %s", id, courseID)
+		}
+		if ownerID != "" && upload.OwnerID != ownerID {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "attachment %s was not uploaded by user %s", id, ownerID)
+		}
+		if ownerID == "" && upload.Private {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "attachment %s is private", id)
+		}
+	}
+	return nil
+}
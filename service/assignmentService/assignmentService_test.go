@@ -0,0 +1,347 @@
+package assignmentService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	courseID     = "5b23bbdc2bfa844c41a9f134"
+	otherID      = "5b23bbdc2bfa844c41a9f135"
+	archivedID   = "5b23bbdc2bfa844c41a9f136"
+	openID       = "5b23bbdc2bfa844c41a9f140"
+	overdueID    = "5b23bbdc2bfa844c41a9f141"
+	elsewhereID  = "5b23bbdc2bfa844c41a9f142"
+	archivedTask = "5b23bbdc2bfa844c41a9f143"
+)
+
+var members = []eduboard.Member{
+	{UserID: "owner", Role: eduboard.RoleOwner},
+	{UserID: "teacher", Role: eduboard.RoleTeacher},
+	{UserID: "student", Role: eduboard.RoleStudent},
+}
+
+// newAssignments returns a repository holding an open and an overdue assignment of the course, one of another
+// course and one of the archived course.
+func newAssignments() *mock.AssignmentRepository {
+	assignments := map[string]eduboard.Assignment{
+		openID:       {CourseID: bson.ObjectIdHex(courseID), DueAt: time.Now().Add(time.Hour)},
+		overdueID:    {CourseID: bson.ObjectIdHex(courseID), DueAt: time.Now().Add(-time.Hour)},
+		elsewhereID:  {CourseID: bson.ObjectIdHex(otherID), DueAt: time.Now().Add(time.Hour)},
+		archivedTask: {CourseID: bson.ObjectIdHex(archivedID), DueAt: time.Now().Add(time.Hour)},
+	}
+
+	ar := &mock.AssignmentRepository{}
+	ar.InsertFn = func(assignment *eduboard.Assignment) error { return nil }
+	ar.FindOneByIDFn = func(id string) (error, eduboard.Assignment) {
+		if a, ok := assignments[id]; ok {
+			a.ID = bson.ObjectIdHex(id)
+			return nil, a
+		}
+		return errors.New("not found"), eduboard.Assignment{}
+	}
+	ar.UpdateFn = func(id string, update bson.M) (error, eduboard.Assignment) {
+		return nil, eduboard.Assignment{ID: bson.ObjectIdHex(id)}
+	}
+	ar.DeleteFn = func(id string) error { return nil }
+	return ar
+}
+
+// newUploads returns a repository holding uploads of "student" and "teacher" to the course and one of "student"
+// to another course. The uploads of "student" are private.
+func newUploads() *mock.UploadRepository {
+	uploads := map[string]eduboard.Upload{
+		"mine":      {OwnerID: "student", CourseID: courseID, Private: true},
+		"teachers":  {OwnerID: "teacher", CourseID: courseID},
+		"elsewhere": {OwnerID: "student", CourseID: otherID, Private: true},
+	}
+
+	ur := &mock.UploadRepository{}
+	ur.FindFn = func(id string) (error, eduboard.Upload) {
+		if u, ok := uploads[id]; ok {
+			return nil, u
+		}
+		return errors.New("not found"), eduboard.Upload{}
+	}
+	return ur
+}
+
+func TestNew(t *testing.T) {
+	ar := newAssignments()
+	sr := &mock.SubmissionRepository{}
+	ur := newUploads()
+	cf := mock.CourseFinder(members, archivedID, courseID, otherID)
+	s := New(ar, sr, ur, cf)
+	assert.Equal(t, ar, s.ar, "assignment repository does not match")
+	assert.Equal(t, sr, s.sr, "submission repository does not match")
+	assert.Equal(t, ur, s.ur, "upload repository does not match")
+	assert.Equal(t, cf, s.cf, "course finder does not match")
+}
+
+func TestAssignmentService_GetAssignments(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		course string
+		user   string
+		err    error
+	}{
+		{"student", courseID, "student", nil},
+		{"archived", archivedID, "teacher", nil},
+		{"no member", courseID, "stranger", eduboard.ErrForbidden},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			ar := newAssignments()
+			ar.FindByCourseFn = func(course string) (error, []eduboard.Assignment) {
+				assert.Equal(t, v.course, course, "course does not match")
+				return nil, []eduboard.Assignment{{Title: "Sorting"}}
+			}
+
+			err, assignments := New(ar, &mock.SubmissionRepository{}, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID)).GetAssignments(v.course, v.user)
+			assert.Equal(t, v.err == nil, ar.FindByCourseFnInvoked, "FindByCourse was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Len(t, assignments, 1, "unexpected number of assignments")
+		})
+	}
+}
+
+func TestAssignmentService_CreateAssignment(t *testing.T) {
+	due := time.Now().Add(24 * time.Hour)
+	var testCases = []struct {
+		name       string
+		course     string
+		user       string
+		assignment eduboard.Assignment
+		err        error
+	}{
+		{"success", courseID, "teacher", eduboard.Assignment{Title: " Sorting ", DueAt: due, Attachments: []string{"teachers"}}, nil},
+		{"student", courseID, "student", eduboard.Assignment{Title: "Sorting", DueAt: due}, eduboard.ErrForbidden},
+		{"archived", archivedID, "teacher", eduboard.Assignment{Title: "Sorting", DueAt: due}, eduboard.ErrArchived},
+		{"empty title", courseID, "teacher", eduboard.Assignment{Title: " ", DueAt: due}, eduboard.ErrInvalidInput},
+		{"no due date", courseID, "teacher", eduboard.Assignment{Title: "Sorting"}, eduboard.ErrInvalidInput},
+		{"unknown attachment", courseID, "teacher", eduboard.Assignment{Title: "Sorting", DueAt: due, Attachments: []string{"unknown"}}, eduboard.ErrInvalidInput},
+		{"attachment of other course", courseID, "teacher", eduboard.Assignment{Title: "Sorting", DueAt: due, Attachments: []string{"elsewhere"}}, eduboard.ErrInvalidInput},
+		{"private attachment", courseID, "teacher", eduboard.Assignment{Title: "Sorting", DueAt: due, Attachments: []string{"mine"}}, eduboard.ErrInvalidInput},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			ar := newAssignments()
+
+			err, assignment := New(ar, &mock.SubmissionRepository{}, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID)).CreateAssignment(v.course, v.user, v.assignment)
+			assert.Equal(t, v.err == nil, ar.InsertFnInvoked, "Insert was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, "Sorting", assignment.Title, "title does not match")
+			assert.Equal(t, courseID, assignment.CourseID.Hex(), "course does not match")
+			assert.Equal(t, v.user, assignment.CreatedBy, "creator does not match")
+			assert.False(t, assignment.CreatedAt.IsZero(), "creation time not set")
+		})
+	}
+}
+
+func TestAssignmentService_UpdateAssignment(t *testing.T) {
+	title := "Searching"
+	empty := ""
+	var testCases = []struct {
+		name       string
+		assignment string
+		user       string
+		update     eduboard.AssignmentUpdate
+		err        error
+		notFound   bool
+	}{
+		{"success", openID, "teacher", eduboard.AssignmentUpdate{Title: &title, Attachments: []string{}}, nil, false},
+		{"student", openID, "student", eduboard.AssignmentUpdate{Title: &title}, eduboard.ErrForbidden, false},
+		{"empty title", openID, "teacher", eduboard.AssignmentUpdate{Title: &empty}, eduboard.ErrInvalidInput, false},
+		{"nothing", openID, "teacher", eduboard.AssignmentUpdate{}, eduboard.ErrInvalidInput, false},
+		{"other course", elsewhereID, "teacher", eduboard.AssignmentUpdate{Title: &title}, nil, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			ar := newAssignments()
+			ar.UpdateFn = func(id string, update bson.M) (error, eduboard.Assignment) {
+				assert.Equal(t, bson.M{"$set": bson.M{"title": title, "attachments": []string{}}}, update, "update does not match")
+				return nil, eduboard.Assignment{}
+			}
+
+			err, _ := New(ar, &mock.SubmissionRepository{}, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID)).UpdateAssignment(courseID, v.assignment, v.user, v.update)
+			assert.Equal(t, v.err == nil && !v.notFound, ar.UpdateFnInvoked, "Update was not invoked as expected")
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+		})
+	}
+}
+
+func TestAssignmentService_DeleteAssignment(t *testing.T) {
+	deleted := []string{}
+	ar := newAssignments()
+	ar.DeleteFn = func(id string) error {
+		deleted = append(deleted, "assignment")
+		return nil
+	}
+	sr := &mock.SubmissionRepository{DeleteByAssignmentFn: func(assignmentID string) error {
+		assert.Equal(t, openID, assignmentID, "assignment does not match")
+		deleted = append(deleted, "submissions")
+		return nil
+	}}
+	grades := &mock.GradebookService{DeleteByAssignmentFn: func(assignmentID string) error {
+		assert.Equal(t, openID, assignmentID, "assignment does not match")
+		deleted = append(deleted, "grades")
+		return nil
+	}}
+	s := New(ar, sr, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID), grades)
+
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(s.DeleteAssignment(courseID, openID, "student")), "error does not match")
+	assert.Empty(t, deleted, "deleted data without permission")
+	assert.Nil(t, s.DeleteAssignment(courseID, openID, "owner"), "returned error when it shouldn't")
+	assert.Equal(t, []string{"grades", "submissions", "assignment"}, deleted, "data was not deleted in order")
+}
+
+func TestAssignmentService_DeleteAssignment_Fails(t *testing.T) {
+	ar := newAssignments()
+	sr := &mock.SubmissionRepository{DeleteByAssignmentFn: func(assignmentID string) error {
+		return errors.New("error deleting submissions")
+	}}
+	s := New(ar, sr, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID))
+
+	assert.Error(t, s.DeleteAssignment(courseID, openID, "owner"), "did not return error when expected")
+	assert.False(t, ar.DeleteFnInvoked, "assignment was deleted before its submissions")
+}
+
+func TestAssignmentService_DeleteByCourse(t *testing.T) {
+	deleted := []string{}
+	ar := newAssignments()
+	ar.DeleteByCourseFn = func(course string) error {
+		assert.Equal(t, courseID, course, "course does not match")
+		deleted = append(deleted, "assignments")
+		return nil
+	}
+	sr := &mock.SubmissionRepository{DeleteByCourseFn: func(course string) error {
+		assert.Equal(t, courseID, course, "course does not match")
+		deleted = append(deleted, "submissions")
+		return nil
+	}}
+
+	err := New(ar, sr, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID)).DeleteByCourse(courseID)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Equal(t, []string{"submissions", "assignments"}, deleted, "data was not deleted in order")
+}
+
+func TestAssignmentService_Submit(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		course     string
+		assignment string
+		user       string
+		submission eduboard.Submission
+		err        error
+		notFound   bool
+		late       bool
+	}{
+		{"success", courseID, openID, "student", eduboard.Submission{Text: " Quicksort "}, nil, false, false},
+		{"files", courseID, openID, "student", eduboard.Submission{Attachments: []string{"mine"}}, nil, false, false},
+		{"late", courseID, overdueID, "student", eduboard.Submission{Text: "Quicksort"}, nil, false, true},
+		{"teacher", courseID, openID, "teacher", eduboard.Submission{Text: "Quicksort"}, eduboard.ErrForbidden, false, false},
+		{"no member", courseID, openID, "stranger", eduboard.Submission{Text: "Quicksort"}, eduboard.ErrForbidden, false, false},
+		{"archived", archivedID, archivedTask, "student", eduboard.Submission{Text: "Quicksort"}, eduboard.ErrArchived, false, false},
+		{"empty", courseID, openID, "student", eduboard.Submission{Text: " "}, eduboard.ErrInvalidInput, false, false},
+		{"too long", courseID, openID, "student", eduboard.Submission{Text: strings.Repeat("a", eduboard.MaxSubmissionLength+1)}, eduboard.ErrInvalidInput, false, false},
+		{"foreign file", courseID, openID, "student", eduboard.Submission{Attachments: []string{"teachers"}}, eduboard.ErrInvalidInput, false, false},
+		{"file of other course", courseID, openID, "student", eduboard.Submission{Attachments: []string{"elsewhere"}}, eduboard.ErrInvalidInput, false, false},
+		{"assignment of other course", courseID, elsewhereID, "student", eduboard.Submission{Text: "Quicksort"}, nil, true, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			sr := &mock.SubmissionRepository{UpsertFn: func(submission *eduboard.Submission) error {
+				submission.ID = bson.NewObjectId()
+				return nil
+			}}
+
+			err, submission := New(newAssignments(), sr, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID)).Submit(v.course, v.assignment, v.user, v.submission)
+			assert.Equal(t, v.err == nil && !v.notFound, sr.UpsertFnInvoked, "Upsert was not invoked as expected")
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, strings.TrimSpace(v.submission.Text), submission.Text, "text does not match")
+			assert.Equal(t, v.assignment, submission.AssignmentID.Hex(), "assignment does not match")
+			assert.Equal(t, v.user, submission.UserID, "user does not match")
+			assert.Equal(t, v.late, submission.Late, "late flag does not match")
+			assert.NotEmpty(t, submission.ID, "id not set")
+		})
+	}
+}
+
+func TestAssignmentService_GetSubmissions(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		course string
+		task   string
+		user   string
+		err    error
+	}{
+		{"teacher", courseID, openID, "teacher", nil},
+		{"archived", archivedID, archivedTask, "owner", nil},
+		{"student", courseID, openID, "student", eduboard.ErrForbidden},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			sr := &mock.SubmissionRepository{FindByAssignmentFn: func(assignmentID string) (error, []eduboard.Submission) {
+				return nil, []eduboard.Submission{{UserID: "student"}}
+			}}
+
+			err, submissions := New(newAssignments(), sr, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID)).GetSubmissions(v.course, v.task, v.user)
+			assert.Equal(t, v.err == nil, sr.FindByAssignmentFnInvoked, "FindByAssignment was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Len(t, submissions, 1, "unexpected number of submissions")
+		})
+	}
+}
+
+func TestAssignmentService_GetSubmission(t *testing.T) {
+	sr := &mock.SubmissionRepository{FindFn: func(assignmentID string, userID string) (error, eduboard.Submission) {
+		if userID != "student" {
+			return errors.New("not found"), eduboard.Submission{}
+		}
+		return nil, eduboard.Submission{UserID: userID}
+	}}
+	s := New(newAssignments(), sr, newUploads(), mock.CourseFinder(members, archivedID, courseID, otherID))
+
+	err, submission := s.GetSubmission(courseID, openID, "student")
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Equal(t, "student", submission.UserID, "user does not match")
+
+	err, _ = s.GetSubmission(courseID, openID, "teacher")
+	assert.Error(t, err, "did not return error when expected")
+
+	err, _ = s.GetSubmission(courseID, elsewhereID, "student")
+	assert.Error(t, err, "did not return error when expected")
+}
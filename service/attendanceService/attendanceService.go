@@ -0,0 +1,345 @@
+package attendanceService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/auth"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const (
+	// CheckInTimeout is the time students have to check in with a code.
+	CheckInTimeout = 10 * time.Minute
+	// codeLength is the number of characters of a check-in code.
+	codeLength = 6
+)
+
+type AttendanceService struct {
+	r  eduboard.AttendanceRepository
+	cf eduboard.CourseOneFinder
+}
+
+func New(repository eduboard.AttendanceRepository, courseFinder eduboard.CourseOneFinder) *AttendanceService {
+	return &AttendanceService{
+		r:  repository,
+		cf: courseFinder,
+	}
+}
+
+// GetSessions returns the sessions of a course, earliest first. Only the staff may see them.
+func (aS *AttendanceService) GetSessions(courseID string, userID string) (error, []eduboard.AttendanceSession) {
+	if err, _ := access.Staff(aS.cf, courseID, userID); err != nil {
+		return err, []eduboard.AttendanceSession{}
+	}
+
+	err, sessions := aS.r.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding attendance sessions of course %s", courseID), []eduboard.AttendanceSession{}
+	}
+	return nil, sessions
+}
+
+func (aS *AttendanceService) GetSession(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession) {
+	if err, _ := access.Staff(aS.cf, courseID, userID); err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+	return aS.session(courseID, sessionID)
+}
+
+// OpenSession returns the session of the meeting of a schedule on date, creating it if needed.
+// Cancelled meetings have no session.
+func (aS *AttendanceService) OpenSession(courseID string, scheduleID string, date string, userID string, of eduboard.OccurrenceFinder) (error, eduboard.AttendanceSession) {
+	err, course := access.Manager(aS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+
+	err, session := aS.r.Find(courseID, scheduleID, date)
+	if err == nil {
+		return nil, session
+	}
+	if err != eduboard.ErrNotFound {
+		return errors.Wrapf(err, "error finding attendance session of schedule %s on %s", scheduleID, date), eduboard.AttendanceSession{}
+	}
+
+	err, occurrence := of.GetOccurrence(courseID, scheduleID, date)
+	if err != nil {
+		return errors.Wrapf(err, "error finding meeting of schedule %s on %s", scheduleID, date), eduboard.AttendanceSession{}
+	}
+	if occurrence.Cancelled {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "meeting of schedule %s on %s is cancelled", scheduleID, date), eduboard.AttendanceSession{}
+	}
+
+	session = eduboard.AttendanceSession{
+		CourseID:   course.ID,
+		ScheduleID: occurrence.ScheduleID,
+		Date:       occurrence.Date,
+		Start:      occurrence.Start,
+		Records:    map[string]eduboard.AttendanceRecord{},
+		CreatedBy:  userID,
+		CreatedAt:  time.Now(),
+	}
+	err = aS.r.Insert(&session)
+	if err == eduboard.ErrDuplicate {
+		// Another request opened the session in the meantime.
+		err, session = aS.r.Find(courseID, scheduleID, date)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error storing attendance session of schedule %s on %s", scheduleID, date), eduboard.AttendanceSession{}
+	}
+	return nil, session
+}
+
+func (aS *AttendanceService) DeleteSession(courseID string, sessionID string, userID string) error {
+	if err, _ := access.Manager(aS.cf, courseID, userID); err != nil {
+		return err
+	}
+	if err, _ := aS.session(courseID, sessionID); err != nil {
+		return err
+	}
+
+	if err := aS.r.Delete(sessionID); err != nil {
+		return errors.Wrapf(err, "error deleting attendance session %s", sessionID)
+	}
+	return nil
+}
+
+// OpenCheckIn sets a new code students can check in with until CheckInTimeout has passed.
+func (aS *AttendanceService) OpenCheckIn(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession) {
+	if err, _ := access.Manager(aS.cf, courseID, userID); err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+	err, session := aS.session(courseID, sessionID)
+	if err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+
+	code, err := auth.NewCode(codeLength)
+	if err != nil {
+		return errors.Wrap(err, "error creating check-in code"), eduboard.AttendanceSession{}
+	}
+	expires := time.Now().Add(CheckInTimeout)
+	if err = aS.r.SetCode(sessionID, code, expires); err != nil {
+		return errors.Wrapf(err, "error opening check-in of attendance session %s", sessionID), eduboard.AttendanceSession{}
+	}
+
+	session.Code = code
+	session.CodeExpiresAt = &expires
+	return nil, session
+}
+
+func (aS *AttendanceService) CloseCheckIn(courseID string, sessionID string, userID string) (error, eduboard.AttendanceSession) {
+	if err, _ := access.Manager(aS.cf, courseID, userID); err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+	err, session := aS.session(courseID, sessionID)
+	if err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+
+	if err = aS.r.SetCode(sessionID, "", time.Time{}); err != nil {
+		return errors.Wrapf(err, "error closing check-in of attendance session %s", sessionID), eduboard.AttendanceSession{}
+	}
+
+	session.Code = ""
+	session.CodeExpiresAt = nil
+	return nil, session
+}
+
+// CheckIn records a student as present in the session whose code is open. The returned session only contains the
+// record of the student.
+func (aS *AttendanceService) CheckIn(courseID string, userID string, code string) (error, eduboard.AttendanceSession) {
+	err, course := aS.cf.FindOneByID(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", courseID), eduboard.AttendanceSession{}
+	}
+	if role, _ := course.RoleOf(userID); role != eduboard.RoleStudent {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s is no student of course %s", userID, courseID), eduboard.AttendanceSession{}
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not check in to course %s", courseID), eduboard.AttendanceSession{}
+	}
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return errors.Wrap(eduboard.ErrInvalidInput, "check-in code is empty"), eduboard.AttendanceSession{}
+	}
+
+	now := time.Now()
+	record := eduboard.AttendanceRecord{UserID: userID, Status: eduboard.AttendancePresent, CheckedInAt: &now}
+	err, session := aS.r.CheckIn(courseID, code, record, now)
+	if err != nil {
+		return errors.Wrapf(eduboard.ErrExpired, "check-in code of course %s is invalid or expired: %v", courseID, err), eduboard.AttendanceSession{}
+	}
+
+	session.Code = ""
+	session.CodeExpiresAt = nil
+	session.Records = map[string]eduboard.AttendanceRecord{userID: record}
+	return nil, session
+}
+
+// MarkAttendance sets the status of students in a session by hand.
+func (aS *AttendanceService) MarkAttendance(courseID string, sessionID string, userID string, records []eduboard.AttendanceRecord) (error, eduboard.AttendanceSession) {
+	err, course := access.Manager(aS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+	if err, _ = aS.session(courseID, sessionID); err != nil {
+		return err, eduboard.AttendanceSession{}
+	}
+
+	for _, v := range records {
+		if role, _ := course.RoleOf(v.UserID); role != eduboard.RoleStudent {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "user %s is no student of course %s", v.UserID, courseID), eduboard.AttendanceSession{}
+		}
+		if !v.Status.IsValid() {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "invalid attendance status %s", v.Status), eduboard.AttendanceSession{}
+		}
+	}
+
+	for _, v := range records {
+		record := eduboard.AttendanceRecord{UserID: v.UserID, Status: v.Status, MarkedBy: userID}
+		if err = aS.r.SetRecord(sessionID, record); err != nil {
+			return errors.Wrapf(err, "error marking attendance of user %s in session %s", v.UserID, sessionID), eduboard.AttendanceSession{}
+		}
+	}
+	return aS.session(courseID, sessionID)
+}
+
+// GetStudentReport returns the attendance of a student in every session of a course. Students may only see their own.
+func (aS *AttendanceService) GetStudentReport(courseID string, studentID string, userID string) (error, eduboard.AttendanceReport) {
+	err, course := aS.cf.FindOneByID(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", courseID), eduboard.AttendanceReport{}
+	}
+	if studentID != userID && !course.IsStaff(userID) {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s may not see the attendance of user %s", userID, studentID), eduboard.AttendanceReport{}
+	}
+	if role, _ := course.RoleOf(studentID); role != eduboard.RoleStudent {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "user %s is no student of course %s", studentID, courseID), eduboard.AttendanceReport{}
+	}
+
+	err, sessions := aS.r.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding attendance sessions of course %s", courseID), eduboard.AttendanceReport{}
+	}
+
+	result := report(studentID, sessions)
+	result.Records = make([]eduboard.AttendanceHistory, len(sessions))
+	for k, v := range sessions {
+		result.Records[k] = eduboard.AttendanceHistory{
+			SessionID:  v.ID,
+			ScheduleID: v.ScheduleID,
+			Date:       v.Date,
+			Status:     status(v, studentID),
+		}
+	}
+	return nil, result
+}
+
+// GetCourseReport returns the attendance of all students of a course. Only the staff may see it.
+func (aS *AttendanceService) GetCourseReport(courseID string, userID string) (error, eduboard.CourseAttendanceReport) {
+	err, course := access.Staff(aS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.CourseAttendanceReport{}
+	}
+
+	err, sessions := aS.r.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding attendance sessions of course %s", courseID), eduboard.CourseAttendanceReport{}
+	}
+
+	result := eduboard.CourseAttendanceReport{
+		CourseID: course.ID,
+		Sessions: len(sessions),
+		Students: []eduboard.AttendanceReport{},
+	}
+	var sum float64
+	var rated int
+	for _, v := range course.StudentIDs() {
+		r := report(v, sessions)
+		if r.Rate != nil {
+			sum += *r.Rate
+			rated++
+		}
+		result.Students = append(result.Students, r)
+	}
+	if rated > 0 {
+		rate := sum / float64(rated)
+		result.Rate = &rate
+	}
+	return nil, result
+}
+
+// DeleteCancelled deletes the sessions of meetings that no longer take place, as their schedule was deleted or
+// changed, such that they no longer count in reports.
+func (aS *AttendanceService) DeleteCancelled(courseID string, of eduboard.OccurrenceFinder) error {
+	err, sessions := aS.r.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding attendance sessions of course %s", courseID)
+	}
+
+	for _, v := range sessions {
+		err, occurrence := of.GetOccurrence(courseID, v.ScheduleID.Hex(), v.Date)
+		if cause := errors.Cause(err); cause != nil && cause != eduboard.ErrNotFound && cause != eduboard.ErrInvalidInput {
+			return errors.Wrapf(err, "error finding meeting of attendance session %s", v.ID.Hex())
+		}
+		if err == nil && !occurrence.Cancelled {
+			continue
+		}
+		if err = aS.r.Delete(v.ID.Hex()); err != nil {
+			return errors.Wrapf(err, "error deleting attendance session %s", v.ID.Hex())
+		}
+	}
+	return nil
+}
+
+// DeleteByCourse deletes all attendance sessions of a course.
+func (aS *AttendanceService) DeleteByCourse(courseID string) error {
+	if err := aS.r.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting attendance sessions of course %s", courseID)
+	}
+	return nil
+}
+
+// report counts the attendance of a student. Sessions without a record of the student count as absent.
+func report(studentID string, sessions []eduboard.AttendanceSession) eduboard.AttendanceReport {
+	result := eduboard.AttendanceReport{UserID: studentID, Sessions: len(sessions)}
+	for _, v := range sessions {
+		switch status(v, studentID) {
+		case eduboard.AttendancePresent:
+			result.Present++
+		case eduboard.AttendanceExcused:
+			result.Excused++
+		default:
+			result.Absent++
+		}
+	}
+
+	if counted := result.Present + result.Absent; counted > 0 {
+		rate := float64(result.Present) / float64(counted) * 100
+		result.Rate = &rate
+	}
+	return result
+}
+
+func status(session eduboard.AttendanceSession, studentID string) eduboard.AttendanceStatus {
+	if record, ok := session.Records[studentID]; ok {
+		return record.Status
+	}
+	return eduboard.AttendanceAbsent
+}
+
+// session returns an attendance session of a course.
+func (aS *AttendanceService) session(courseID string, sessionID string) (error, eduboard.AttendanceSession) {
+	err, session := aS.r.FindOneByID(sessionID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding attendance session %s", sessionID), eduboard.AttendanceSession{}
+	}
+	if session.CourseID.Hex() != courseID {
+		return errors.Wrapf(eduboard.ErrNotFound, "attendance session %s does not belong to course %s", sessionID, courseID), eduboard.AttendanceSession{}
+	}
+	return nil, session
+}
@@ -0,0 +1,357 @@
+package attendanceService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+const (
+	courseID    = "5b23bbdc2bfa844c41a9f134"
+	archivedID  = "5b23bbdc2bfa844c41a9f135"
+	scheduleID  = "5b23bbdc2bfa844c41a9f140"
+	mondayID    = "5b23bbdc2bfa844c41a9f150"
+	tuesdayID   = "5b23bbdc2bfa844c41a9f151"
+	wednesdayID = "5b23bbdc2bfa844c41a9f152"
+	elsewhereID = "5b23bbdc2bfa844c41a9f153"
+)
+
+var members = []eduboard.Member{
+	{UserID: "teacher", Role: eduboard.RoleTeacher},
+	{UserID: "student", Role: eduboard.RoleStudent},
+	{UserID: "classmate", Role: eduboard.RoleStudent},
+}
+
+// newRepository returns a repository holding three sessions of the course and one of the archived course.
+// "student" was present, excused and absent, "classmate" was present once and has no other records.
+func newRepository() *mock.AttendanceRepository {
+	sessions := []eduboard.AttendanceSession{
+		{ID: bson.ObjectIdHex(mondayID), CourseID: bson.ObjectIdHex(courseID), Date: "2018-10-01", Records: map[string]eduboard.AttendanceRecord{
+			"student":   {UserID: "student", Status: eduboard.AttendancePresent},
+			"classmate": {UserID: "classmate", Status: eduboard.AttendancePresent},
+		}},
+		{ID: bson.ObjectIdHex(tuesdayID), CourseID: bson.ObjectIdHex(courseID), Date: "2018-10-02", Records: map[string]eduboard.AttendanceRecord{
+			"student": {UserID: "student", Status: eduboard.AttendanceExcused},
+		}},
+		{ID: bson.ObjectIdHex(wednesdayID), CourseID: bson.ObjectIdHex(courseID), Date: "2018-10-03", Records: map[string]eduboard.AttendanceRecord{
+			"student": {UserID: "student", Status: eduboard.AttendanceAbsent},
+		}},
+		{ID: bson.ObjectIdHex(elsewhereID), CourseID: bson.ObjectIdHex(archivedID), Date: "2018-10-01"},
+	}
+
+	r := &mock.AttendanceRepository{}
+	r.InsertFn = func(session *eduboard.AttendanceSession) error { return nil }
+	r.FindOneByIDFn = func(id string) (error, eduboard.AttendanceSession) {
+		for _, v := range sessions {
+			if v.ID.Hex() == id {
+				return nil, v
+			}
+		}
+		return errors.New("not found"), eduboard.AttendanceSession{}
+	}
+	r.FindFn = func(course string, schedule string, date string) (error, eduboard.AttendanceSession) {
+		if date == "2018-10-01" {
+			return nil, sessions[0]
+		}
+		return eduboard.ErrNotFound, eduboard.AttendanceSession{}
+	}
+	r.FindByCourseFn = func(course string) (error, []eduboard.AttendanceSession) { return nil, sessions[:3] }
+	r.SetCodeFn = func(id string, code string, expiresAt time.Time) error { return nil }
+	r.SetRecordFn = func(id string, record eduboard.AttendanceRecord) error { return nil }
+	r.DeleteFn = func(id string) error { return nil }
+	return r
+}
+
+func TestNew(t *testing.T) {
+	r := newRepository()
+	cf := mock.CourseFinder(members, archivedID, courseID)
+	s := New(r, cf)
+	assert.Equal(t, r, s.r, "repository does not match")
+	assert.Equal(t, cf, s.cf, "course finder does not match")
+}
+
+func TestAttendanceService_OpenSession(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		course   string
+		date     string
+		user     string
+		err      error
+		notFound bool
+		created  bool
+	}{
+		{"new", courseID, "2018-10-08", "teacher", nil, false, true},
+		{"existing", courseID, "2018-10-01", "teacher", nil, false, false},
+		{"cancelled", courseID, "2018-10-15", "teacher", eduboard.ErrInvalidInput, false, false},
+		{"no meeting", courseID, "2018-10-09", "teacher", nil, true, false},
+		{"student", courseID, "2018-10-08", "student", eduboard.ErrForbidden, false, false},
+		{"archived", archivedID, "2018-10-08", "teacher", eduboard.ErrArchived, false, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			of := &mock.ScheduleService{GetOccurrenceFn: func(course string, schedule string, date string) (error, eduboard.Occurrence) {
+				if date == "2018-10-09" {
+					return errors.New("no meeting"), eduboard.Occurrence{}
+				}
+				start, _ := time.Parse(eduboard.DateFormat, date)
+				return nil, eduboard.Occurrence{ScheduleID: bson.ObjectIdHex(schedule), Date: date, Start: start, Cancelled: date == "2018-10-15"}
+			}}
+
+			err, session := New(r, mock.CourseFinder(members, archivedID, courseID)).OpenSession(v.course, scheduleID, v.date, v.user, of)
+			assert.Equal(t, v.created, r.InsertFnInvoked, "Insert was not invoked as expected")
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.date, session.Date, "date does not match")
+			if v.created {
+				assert.Equal(t, scheduleID, session.ScheduleID.Hex(), "schedule does not match")
+				assert.Equal(t, v.user, session.CreatedBy, "creator does not match")
+			}
+		})
+	}
+}
+
+func TestAttendanceService_OpenSession_Concurrent(t *testing.T) {
+	of := &mock.ScheduleService{GetOccurrenceFn: func(course string, schedule string, date string) (error, eduboard.Occurrence) {
+		return nil, eduboard.Occurrence{ScheduleID: bson.ObjectIdHex(schedule), Date: date}
+	}}
+	opened := eduboard.AttendanceSession{ID: bson.ObjectIdHex(mondayID), CourseID: bson.ObjectIdHex(courseID), Date: "2018-10-08", CreatedBy: "assistant"}
+
+	r := newRepository()
+	r.FindFn = func(course string, schedule string, date string) (error, eduboard.AttendanceSession) {
+		if r.InsertFnInvoked {
+			return nil, opened
+		}
+		return eduboard.ErrNotFound, eduboard.AttendanceSession{}
+	}
+	r.InsertFn = func(session *eduboard.AttendanceSession) error { return eduboard.ErrDuplicate }
+
+	err, session := New(r, mock.CourseFinder(members, archivedID, courseID)).OpenSession(courseID, scheduleID, "2018-10-08", "teacher", of)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Equal(t, opened, session, "session was not read again")
+
+	r = newRepository()
+	r.FindFn = func(course string, schedule string, date string) (error, eduboard.AttendanceSession) {
+		return errors.New("connection lost"), eduboard.AttendanceSession{}
+	}
+	err, _ = New(r, mock.CourseFinder(members, archivedID, courseID)).OpenSession(courseID, scheduleID, "2018-10-08", "teacher", of)
+	assert.Error(t, err, "did not return error when expected")
+	assert.False(t, r.InsertFnInvoked, "Insert was invoked")
+}
+
+func TestAttendanceService_OpenCheckIn(t *testing.T) {
+	r := newRepository()
+	var stored string
+	r.SetCodeFn = func(id string, code string, expiresAt time.Time) error {
+		stored = code
+		if code != "" {
+			assert.WithinDuration(t, time.Now().Add(CheckInTimeout), expiresAt, time.Second, "expiry does not match")
+		}
+		return nil
+	}
+	s := New(r, mock.CourseFinder(members, archivedID, courseID))
+
+	err, _ := s.OpenCheckIn(courseID, mondayID, "student")
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(err), "error does not match")
+	assert.False(t, r.SetCodeFnInvoked, "SetCode was invoked")
+
+	err, session := s.OpenCheckIn(courseID, mondayID, "teacher")
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Len(t, session.Code, codeLength, "code length does not match")
+	assert.Equal(t, stored, session.Code, "stored code does not match")
+	assert.NotNil(t, session.CodeExpiresAt, "expiry not set")
+
+	err, _ = s.OpenCheckIn(courseID, elsewhereID, "teacher")
+	assert.Error(t, err, "did not return error when expected")
+
+	err, session = s.CloseCheckIn(courseID, mondayID, "teacher")
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Equal(t, "", stored, "code was not removed")
+	assert.Nil(t, session.CodeExpiresAt, "expiry was not removed")
+}
+
+func TestAttendanceService_CheckIn(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		course  string
+		user    string
+		code    string
+		err     error
+		invoked bool
+	}{
+		{"success", courseID, "student", " abc234 ", nil, true},
+		{"wrong code", courseID, "student", "ZZZZZZ", eduboard.ErrExpired, true},
+		{"empty code", courseID, "student", " ", eduboard.ErrInvalidInput, false},
+		{"teacher", courseID, "teacher", "ABC234", eduboard.ErrForbidden, false},
+		{"archived", archivedID, "student", "ABC234", eduboard.ErrArchived, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			r.CheckInFn = func(course string, code string, record eduboard.AttendanceRecord, now time.Time) (error, eduboard.AttendanceSession) {
+				if code != "ABC234" {
+					return errors.New("not found"), eduboard.AttendanceSession{}
+				}
+				assert.Equal(t, eduboard.AttendancePresent, record.Status, "status does not match")
+				assert.NotNil(t, record.CheckedInAt, "check-in time not set")
+				return nil, eduboard.AttendanceSession{Code: code, Records: map[string]eduboard.AttendanceRecord{
+					record.UserID: record,
+					"classmate":   {UserID: "classmate", Status: eduboard.AttendanceAbsent},
+				}}
+			}
+
+			err, session := New(r, mock.CourseFinder(members, archivedID, courseID)).CheckIn(v.course, v.user, v.code)
+			assert.Equal(t, v.invoked, r.CheckInFnInvoked, "CheckIn was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Empty(t, session.Code, "code was returned")
+			assert.Len(t, session.Records, 1, "records of others were returned")
+			assert.Equal(t, eduboard.AttendancePresent, session.Records[v.user].Status, "status does not match")
+		})
+	}
+}
+
+func TestAttendanceService_MarkAttendance(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		user    string
+		records []eduboard.AttendanceRecord
+		err     error
+		marked  bool
+	}{
+		{"success", "teacher", []eduboard.AttendanceRecord{{UserID: "student", Status: eduboard.AttendanceExcused}, {UserID: "classmate", Status: eduboard.AttendancePresent}}, nil, true},
+		{"student", "student", []eduboard.AttendanceRecord{{UserID: "student", Status: eduboard.AttendancePresent}}, eduboard.ErrForbidden, false},
+		{"no student", "teacher", []eduboard.AttendanceRecord{{UserID: "teacher", Status: eduboard.AttendancePresent}}, eduboard.ErrInvalidInput, false},
+		{"invalid status", "teacher", []eduboard.AttendanceRecord{{UserID: "student", Status: "late"}}, eduboard.ErrInvalidInput, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			var marked []string
+			r.SetRecordFn = func(id string, record eduboard.AttendanceRecord) error {
+				assert.Equal(t, v.user, record.MarkedBy, "marker does not match")
+				marked = append(marked, record.UserID)
+				return nil
+			}
+
+			err, _ := New(r, mock.CourseFinder(members, archivedID, courseID)).MarkAttendance(courseID, mondayID, v.user, v.records)
+			assert.Equal(t, v.marked, r.SetRecordFnInvoked, "SetRecord was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, []string{"student", "classmate"}, marked, "marked students do not match")
+		})
+	}
+}
+
+func TestAttendanceService_GetStudentReport(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		student string
+		user    string
+		err     error
+		present int
+		absent  int
+		excused int
+		rate    float64
+	}{
+		{"own", "student", "student", nil, 1, 1, 1, 50},
+		{"staff", "classmate", "teacher", nil, 1, 2, 0, 100.0 / 3},
+		{"other student", "classmate", "student", eduboard.ErrForbidden, 0, 0, 0, 0},
+		{"no student", "teacher", "teacher", eduboard.ErrInvalidInput, 0, 0, 0, 0},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err, report := New(newRepository(), mock.CourseFinder(members, archivedID, courseID)).GetStudentReport(courseID, v.student, v.user)
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, 3, report.Sessions, "sessions do not match")
+			assert.Equal(t, v.present, report.Present, "present does not match")
+			assert.Equal(t, v.absent, report.Absent, "absent does not match")
+			assert.Equal(t, v.excused, report.Excused, "excused does not match")
+			if assert.NotNil(t, report.Rate, "rate not computed") {
+				assert.InDelta(t, v.rate, *report.Rate, 0.001, "rate does not match")
+			}
+			assert.Len(t, report.Records, 3, "unexpected number of records")
+			assert.Equal(t, eduboard.AttendancePresent, report.Records[0].Status, "status does not match")
+		})
+	}
+}
+
+func TestAttendanceService_GetCourseReport(t *testing.T) {
+	s := New(newRepository(), mock.CourseFinder(members, archivedID, courseID))
+
+	err, _ := s.GetCourseReport(courseID, "student")
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(err), "error does not match")
+
+	err, report := s.GetCourseReport(courseID, "teacher")
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Equal(t, 3, report.Sessions, "sessions do not match")
+	assert.Len(t, report.Students, 2, "unexpected number of students")
+	assert.Nil(t, report.Students[0].Records, "records were included")
+	if assert.NotNil(t, report.Rate, "rate not computed") {
+		assert.InDelta(t, (50+100.0/3)/2, *report.Rate, 0.001, "rate does not match")
+	}
+}
+
+func TestAttendanceService_DeleteCancelled(t *testing.T) {
+	r := newRepository()
+	deleted := []string{}
+	r.DeleteFn = func(id string) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+	of := &mock.ScheduleService{GetOccurrenceFn: func(course string, schedule string, date string) (error, eduboard.Occurrence) {
+		switch date {
+		case "2018-10-01":
+			return nil, eduboard.Occurrence{Date: date}
+		case "2018-10-02":
+			return nil, eduboard.Occurrence{Date: date, Cancelled: true}
+		}
+		return errors.Wrap(eduboard.ErrNotFound, "schedule was deleted"), eduboard.Occurrence{}
+	}}
+
+	assert.Nil(t, New(r, mock.CourseFinder(members, archivedID, courseID)).DeleteCancelled(courseID, of), "returned error when it shouldn't")
+	assert.Equal(t, []string{tuesdayID, wednesdayID}, deleted, "deleted sessions do not match")
+
+	deleted = []string{}
+	of.GetOccurrenceFn = func(course string, schedule string, date string) (error, eduboard.Occurrence) {
+		return errors.New("connection lost"), eduboard.Occurrence{}
+	}
+	assert.Error(t, New(r, mock.CourseFinder(members, archivedID, courseID)).DeleteCancelled(courseID, of), "did not return error when expected")
+	assert.Empty(t, deleted, "sessions were deleted")
+}
+
+func TestAttendanceService_DeleteByCourse(t *testing.T) {
+	r := newRepository()
+	r.DeleteByCourseFn = func(id string) error {
+		if id != courseID {
+			return errors.New("error deleting sessions")
+		}
+		return nil
+	}
+	s := New(r, mock.CourseFinder(members, archivedID, courseID))
+
+	assert.Nil(t, s.DeleteByCourse(courseID), "returned error when it shouldn't")
+	assert.Error(t, s.DeleteByCourse(archivedID), "did not return error when expected")
+}
@@ -0,0 +1,214 @@
+package commentService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type CommentService struct {
+	r eduboard.CommentRepository
+}
+
+func New(repository eduboard.CommentRepository) *CommentService {
+	return &CommentService{
+		r: repository,
+	}
+}
+
+// GetComments returns a page of the threads of an entry, oldest first, each with all of its replies.
+// Comments are visible to everyone who can see the entry.
+func (cS *CommentService) GetComments(courseID string, entryID string, userID string, cursor string, limit int, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.CommentPage) {
+	if limit < 0 {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "negative limit %d", limit), eduboard.CommentPage{}
+	}
+	if limit == 0 {
+		limit = eduboard.DefaultCommentLimit
+	}
+	if limit > eduboard.MaxCommentLimit {
+		limit = eduboard.MaxCommentLimit
+	}
+
+	err, _, entry := visibleEntry(courseID, entryID, userID, cf, ef)
+	if err != nil {
+		return err, eduboard.CommentPage{}
+	}
+
+	query := bson.M{"entryID": entry.ID, "parentID": bson.M{"$exists": false}}
+	if cursor != "" {
+		if !bson.IsObjectIdHex(cursor) {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "invalid cursor %s", cursor), eduboard.CommentPage{}
+		}
+		query["_id"] = bson.M{"$gt": bson.ObjectIdHex(cursor)}
+	}
+
+	// One more thread than requested tells whether there is a next page.
+	err, threads := cS.r.FindMany(query, limit+1)
+	if err != nil {
+		return errors.Wrapf(err, "error finding comments of entry %s", entryID), eduboard.CommentPage{}
+	}
+
+	page := eduboard.CommentPage{Comments: threads}
+	if len(threads) > limit {
+		page.Comments = threads[:limit]
+		page.Next = page.Comments[limit-1].ID.Hex()
+	}
+	if len(page.Comments) == 0 {
+		return nil, page
+	}
+
+	ids := make([]bson.ObjectId, len(page.Comments))
+	for k, c := range page.Comments {
+		ids[k] = c.ID
+	}
+	err, replies := cS.r.FindMany(bson.M{"parentID": bson.M{"$in": ids}}, 0)
+	if err != nil {
+		return errors.Wrapf(err, "error finding replies of entry %s", entryID), eduboard.CommentPage{}
+	}
+
+	threadOf := map[bson.ObjectId]int{}
+	for k, id := range ids {
+		threadOf[id] = k
+	}
+	for _, r := range replies {
+		k := threadOf[r.ParentID]
+		page.Comments[k].Replies = append(page.Comments[k].Replies, r)
+	}
+	return nil, page
+}
+
+// CreateComment adds a comment to an entry, or a reply to a thread if comment.ParentID is set.
+// Every member who can see the entry may comment on it.
+func (cS *CommentService) CreateComment(courseID string, entryID string, userID string, comment eduboard.Comment, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.Comment) {
+	message, err := validMessage(comment.Message)
+	if err != nil {
+		return err, eduboard.Comment{}
+	}
+
+	err, course, entry := visibleEntry(courseID, entryID, userID, cf, ef)
+	if err != nil {
+		return err, eduboard.Comment{}
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not comment in course %s", courseID), eduboard.Comment{}
+	}
+
+	if comment.ParentID != "" {
+		err, parent := cS.r.FindOneByID(comment.ParentID.Hex())
+		if err != nil {
+			return errors.Wrapf(err, "error finding comment %s", comment.ParentID.Hex()), eduboard.Comment{}
+		}
+		if parent.EntryID != entry.ID {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "comment %s belongs to another entry", parent.ID.Hex()), eduboard.Comment{}
+		}
+		if parent.ParentID != "" {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "comment %s is a reply itself", parent.ID.Hex()), eduboard.Comment{}
+		}
+	}
+
+	comment = eduboard.Comment{
+		ID:        bson.NewObjectId(),
+		CourseID:  course.ID,
+		EntryID:   entry.ID,
+		ParentID:  comment.ParentID,
+		AuthorID:  userID,
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+	if err = cS.r.Insert(comment); err != nil {
+		return errors.Wrapf(err, "error storing comment on entry %s", entryID), eduboard.Comment{}
+	}
+	return nil, comment
+}
+
+// UpdateComment changes the message of a comment. Only its author and the staff of the course may edit it.
+func (cS *CommentService) UpdateComment(courseID string, entryID string, commentID string, userID string, message string, cf eduboard.CourseOneFinder) (error, eduboard.Comment) {
+	message, err := validMessage(message)
+	if err != nil {
+		return err, eduboard.Comment{}
+	}
+
+	err, comment := cS.managed(courseID, entryID, commentID, userID, cf)
+	if err != nil {
+		return err, eduboard.Comment{}
+	}
+
+	now := time.Now()
+	comment.Message = message
+	comment.EditedAt = &now
+	if err = cS.r.Update(commentID, bson.M{"$set": bson.M{"message": comment.Message, "editedAt": now}}); err != nil {
+		return errors.Wrapf(err, "error updating comment %s", commentID), eduboard.Comment{}
+	}
+	return nil, comment
+}
+
+// DeleteComment deletes a comment along with its replies. Only its author and the staff of the course may delete it.
+func (cS *CommentService) DeleteComment(courseID string, entryID string, commentID string, userID string, cf eduboard.CourseOneFinder) error {
+	err, _ := cS.managed(courseID, entryID, commentID, userID, cf)
+	if err != nil {
+		return err
+	}
+
+	if err = cS.r.Delete(commentID); err != nil {
+		return errors.Wrapf(err, "error deleting comment %s", commentID)
+	}
+	return nil
+}
+
+// managed returns a comment of an entry if userID may change it.
+func (cS *CommentService) managed(courseID string, entryID string, commentID string, userID string, cf eduboard.CourseOneFinder) (error, eduboard.Comment) {
+	err, course := access.Member(cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Comment{}
+	}
+
+	err, comment := cS.r.FindOneByID(commentID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding comment %s", commentID), eduboard.Comment{}
+	}
+	if comment.CourseID != course.ID || comment.EntryID.Hex() != entryID {
+		return errors.Wrapf(eduboard.ErrNotFound, "comment %s does not belong to entry %s", commentID, entryID), eduboard.Comment{}
+	}
+
+	if comment.AuthorID != userID && !course.IsStaff(userID) {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s may not change comment %s", userID, commentID), eduboard.Comment{}
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not change comments in course %s", courseID), eduboard.Comment{}
+	}
+	return nil, comment
+}
+
+// visibleEntry returns an entry of a course along with the course if userID may see it.
+// Members see published entries, staff also sees drafts.
+func visibleEntry(courseID string, entryID string, userID string, cf eduboard.CourseOneFinder, ef eduboard.CourseEntryOneFinder) (error, eduboard.Course, eduboard.CourseEntry) {
+	err, course := access.Member(cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Course{}, eduboard.CourseEntry{}
+	}
+
+	err, entry := ef.FindOneByID(entryID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding entry %s", entryID), eduboard.Course{}, eduboard.CourseEntry{}
+	}
+	// Drafts are hidden from students as if they did not exist.
+	if entry.CourseID != course.ID || !entry.Published && !course.IsStaff(userID) {
+		return errors.Wrapf(eduboard.ErrNotFound, "course %s has no entry %s", courseID, entryID), eduboard.Course{}, eduboard.CourseEntry{}
+	}
+	return nil, course, entry
+}
+
+func validMessage(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return "", errors.Wrap(eduboard.ErrInvalidInput, "comment must not be empty")
+	}
+	if utf8.RuneCountInString(message) > eduboard.MaxCommentLength {
+		return "", errors.Wrapf(eduboard.ErrInvalidInput, "comment is longer than %d characters", eduboard.MaxCommentLength)
+	}
+	return message, nil
+}
@@ -0,0 +1,267 @@
+package commentService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"testing"
+)
+
+const (
+	courseID   = "5b23bbdc2bfa844c41a9f134"
+	archivedID = "5b23bbdc2bfa844c41a9f135"
+	entryID    = "5b23bbdc2bfa844c41a9f140"
+	draftID    = "5b23bbdc2bfa844c41a9f141"
+	threadID   = "5b23bbdc2bfa844c41a9f150"
+	replyID    = "5b23bbdc2bfa844c41a9f151"
+	otherID    = "5b23bbdc2bfa844c41a9f152"
+)
+
+var members = []eduboard.Member{
+	{UserID: "teacher", Role: eduboard.RoleTeacher},
+	{UserID: "student", Role: eduboard.RoleStudent},
+	{UserID: "author", Role: eduboard.RoleStudent},
+}
+
+// newFinders returns finders for a course with a published entry and a draft, and an archived course.
+func newFinders() (*mock.CourseRepository, *mock.CourseEntryRepository) {
+	cr := &mock.CourseRepository{}
+	cr.FindFn = func(id string) (error, eduboard.Course) {
+		switch id {
+		case courseID:
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members}
+		case archivedID:
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members, Archived: true}
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
+
+	er := &mock.CourseEntryRepository{}
+	er.FindOneFn = func(id string) (error, eduboard.CourseEntry) {
+		switch id {
+		case entryID:
+			return nil, eduboard.CourseEntry{ID: bson.ObjectIdHex(id), CourseID: bson.ObjectIdHex(courseID), Published: true}
+		case draftID:
+			return nil, eduboard.CourseEntry{ID: bson.ObjectIdHex(id), CourseID: bson.ObjectIdHex(courseID)}
+		}
+		return errors.New("not found"), eduboard.CourseEntry{}
+	}
+	return cr, er
+}
+
+// newRepository returns a repository holding a thread by "author" with one reply, and a comment on another entry.
+func newRepository() *mock.CommentRepository {
+	comments := map[string]eduboard.Comment{
+		threadID: {ID: bson.ObjectIdHex(threadID), CourseID: bson.ObjectIdHex(courseID), EntryID: bson.ObjectIdHex(entryID), AuthorID: "author"},
+		replyID: {ID: bson.ObjectIdHex(replyID), CourseID: bson.ObjectIdHex(courseID), EntryID: bson.ObjectIdHex(entryID),
+			ParentID: bson.ObjectIdHex(threadID), AuthorID: "student"},
+		otherID: {ID: bson.ObjectIdHex(otherID), CourseID: bson.ObjectIdHex(courseID), EntryID: bson.ObjectIdHex(draftID), AuthorID: "teacher"},
+	}
+
+	r := &mock.CommentRepository{}
+	r.InsertFn = func(comment eduboard.Comment) error { return nil }
+	r.FindOneByIDFn = func(id string) (error, eduboard.Comment) {
+		if c, ok := comments[id]; ok {
+			return nil, c
+		}
+		return errors.New("not found"), eduboard.Comment{}
+	}
+	r.UpdateFn = func(id string, update bson.M) error { return nil }
+	r.DeleteFn = func(id string) error { return nil }
+	return r
+}
+
+func TestNew(t *testing.T) {
+	r := newRepository()
+	s := New(r)
+	assert.Equal(t, r, s.r, "repository does not match")
+}
+
+func TestCommentService_GetComments(t *testing.T) {
+	threads := []eduboard.Comment{
+		{ID: bson.ObjectIdHex(threadID)},
+		{ID: bson.ObjectIdHex(otherID)},
+		{ID: bson.ObjectIdHex("5b23bbdc2bfa844c41a9f153")},
+	}
+	reply := eduboard.Comment{ID: bson.ObjectIdHex(replyID), ParentID: bson.ObjectIdHex(threadID)}
+
+	var testCases = []struct {
+		name     string
+		course   string
+		entry    string
+		user     string
+		cursor   string
+		limit    int
+		err      error
+		notFound bool
+		length   int
+		next     string
+	}{
+		{"success", courseID, entryID, "student", "", 0, nil, false, 3, ""},
+		{"paginated", courseID, entryID, "student", "", 2, nil, false, 2, otherID},
+		{"cursor", courseID, entryID, "student", threadID, 0, nil, false, 3, ""},
+		{"staff sees drafts", courseID, draftID, "teacher", "", 0, nil, false, 3, ""},
+		{"student does not see drafts", courseID, draftID, "student", "", 0, nil, true, 0, ""},
+		{"not a member", courseID, entryID, "stranger", "", 0, eduboard.ErrForbidden, false, 0, ""},
+		{"unknown entry", courseID, otherID, "student", "", 0, nil, true, 0, ""},
+		{"entry of other course", archivedID, entryID, "student", "", 0, nil, true, 0, ""},
+		{"invalid cursor", courseID, entryID, "student", "garbage", 0, eduboard.ErrInvalidInput, false, 0, ""},
+		{"negative limit", courseID, entryID, "student", "", -1, eduboard.ErrInvalidInput, false, 0, ""},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			cr, er := newFinders()
+			r := newRepository()
+			var queries []bson.M
+			r.FindManyFn = func(query bson.M, limit int) (error, []eduboard.Comment) {
+				queries = append(queries, query)
+				if _, ok := query["parentID"].(bson.M)["$in"]; ok {
+					assert.Equal(t, 0, limit, "replies are limited")
+					return nil, []eduboard.Comment{reply}
+				}
+				found := make([]eduboard.Comment, len(threads))
+				copy(found, threads)
+				if limit < len(found) {
+					return nil, found[:limit]
+				}
+				return nil, found
+			}
+
+			err, page := New(r).GetComments(v.course, v.entry, v.user, v.cursor, v.limit, cr, er)
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				assert.False(t, r.FindManyFnInvoked, "FindMany was invoked")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Len(t, page.Comments, v.length, "unexpected number of threads")
+			assert.Equal(t, v.next, page.Next, "next cursor does not match")
+			assert.Equal(t, []eduboard.Comment{reply}, page.Comments[0].Replies, "replies do not match")
+			assert.Empty(t, page.Comments[1].Replies, "thread has replies of another thread")
+			if v.cursor != "" {
+				assert.Equal(t, bson.M{"$gt": bson.ObjectIdHex(v.cursor)}, queries[0]["_id"], "cursor was not applied")
+			}
+		})
+	}
+}
+
+func TestCommentService_CreateComment(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		course   string
+		entry    string
+		user     string
+		comment  eduboard.Comment
+		err      error
+		notFound bool
+	}{
+		{"success", courseID, entryID, "student", eduboard.Comment{Message: " Hello "}, nil, false},
+		{"reply", courseID, entryID, "student", eduboard.Comment{Message: "Hello", ParentID: bson.ObjectIdHex(threadID)}, nil, false},
+		{"reply to reply", courseID, entryID, "student", eduboard.Comment{Message: "Hello", ParentID: bson.ObjectIdHex(replyID)}, eduboard.ErrInvalidInput, false},
+		{"reply to other entry", courseID, entryID, "student", eduboard.Comment{Message: "Hello", ParentID: bson.ObjectIdHex(otherID)}, eduboard.ErrInvalidInput, false},
+		{"unknown parent", courseID, entryID, "student", eduboard.Comment{Message: "Hello", ParentID: bson.ObjectIdHex(entryID)}, nil, true},
+		{"empty", courseID, entryID, "student", eduboard.Comment{Message: "  "}, eduboard.ErrInvalidInput, false},
+		{"too long", courseID, entryID, "student", eduboard.Comment{Message: strings.Repeat("ä", eduboard.MaxCommentLength+1)}, eduboard.ErrInvalidInput, false},
+		{"draft", courseID, draftID, "student", eduboard.Comment{Message: "Hello"}, nil, true},
+		{"not a member", courseID, entryID, "stranger", eduboard.Comment{Message: "Hello"}, eduboard.ErrForbidden, false},
+		{"archived", archivedID, entryID, "student", eduboard.Comment{Message: "Hello"}, nil, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			cr, er := newFinders()
+			r := newRepository()
+
+			err, comment := New(r).CreateComment(v.course, v.entry, v.user, v.comment, cr, er)
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				assert.False(t, r.InsertFnInvoked, "Insert was invoked")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.True(t, r.InsertFnInvoked, "Insert was not invoked")
+			assert.True(t, comment.ID.Valid(), "comment did not get an ID")
+			assert.Equal(t, "Hello", comment.Message, "message was not trimmed")
+			assert.Equal(t, v.user, comment.AuthorID, "author does not match")
+			assert.Equal(t, v.comment.ParentID, comment.ParentID, "parent does not match")
+			assert.Equal(t, entryID, comment.EntryID.Hex(), "entry does not match")
+		})
+	}
+}
+
+func TestCommentService_UpdateComment(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		entry    string
+		comment  string
+		user     string
+		message  string
+		err      error
+		notFound bool
+	}{
+		{"author", entryID, threadID, "author", "Edited", nil, false},
+		{"staff", entryID, threadID, "teacher", "Edited", nil, false},
+		{"other student", entryID, threadID, "student", "Edited", eduboard.ErrForbidden, false},
+		{"empty", entryID, threadID, "author", "", eduboard.ErrInvalidInput, false},
+		{"wrong entry", draftID, threadID, "author", "Edited", nil, true},
+		{"unknown", entryID, entryID, "author", "Edited", nil, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			cr, _ := newFinders()
+			r := newRepository()
+
+			err, comment := New(r).UpdateComment(courseID, v.entry, v.comment, v.user, v.message, cr)
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				assert.False(t, r.UpdateFnInvoked, "Update was invoked")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.True(t, r.UpdateFnInvoked, "Update was not invoked")
+			assert.Equal(t, v.message, comment.Message, "message does not match")
+			assert.NotNil(t, comment.EditedAt, "edit time not set")
+			assert.Equal(t, "author", comment.AuthorID, "author changed")
+		})
+	}
+}
+
+func TestCommentService_DeleteComment(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		course  string
+		comment string
+		user    string
+		err     error
+		invoked bool
+	}{
+		{"author", courseID, replyID, "student", nil, true},
+		{"staff", courseID, threadID, "teacher", nil, true},
+		{"other student", courseID, threadID, "student", eduboard.ErrForbidden, false},
+		{"other course", archivedID, threadID, "teacher", nil, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			cr, _ := newFinders()
+			r := newRepository()
+
+			err := New(r).DeleteComment(v.course, entryID, v.comment, v.user, cr)
+			assert.Equal(t, v.invoked, r.DeleteFnInvoked, "Delete was not invoked as expected")
+			if !v.invoked {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+		})
+	}
+}
@@ -2,25 +2,42 @@ package courseEntryService
 
 import (
 	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
 	"github.com/pkg/errors"
 	"gopkg.in/mgo.v2/bson"
+	"time"
 )
 
-func New(repository eduboard.CourseEntryRepository) CourseEntryService {
+func New(repository eduboard.CourseEntryRepository, events eduboard.EventPublisher, hooks ...eduboard.EntryPublishedHook) CourseEntryService {
 	return CourseEntryService{
-		ER: repository,
+		ER:     repository,
+		Events: events,
+		Hooks:  hooks,
 	}
 }
 
 type CourseEntryService struct {
 	ER eduboard.CourseEntryRepository
+	// Events is informed about all changes of entries. It may be nil.
+	Events eduboard.EventPublisher
+	Hooks  []eduboard.EntryPublishedHook
 }
 
-func (cES CourseEntryService) StoreCourseEntry(entry *eduboard.CourseEntry, cfu eduboard.CourseFindUpdater) (error, *eduboard.CourseEntry) {
+func (cES CourseEntryService) StoreCourseEntry(entry *eduboard.CourseEntry, userID string, cfu eduboard.CourseFindUpdater) (error, *eduboard.CourseEntry) {
 	courseID := entry.CourseID.Hex()
-	err, course := cfu.FindOneByID(courseID)
+	err, course := access.Manager(cfu, courseID, userID)
 	if err != nil {
-		return errors.Wrapf(err, "error finding course with ID %d", courseID), &eduboard.CourseEntry{}
+		return err, &eduboard.CourseEntry{}
+	}
+
+	// Entries posted without a date are dated by their creation.
+	if entry.Date.IsZero() {
+		entry.Date = entry.CreatedAt
+	}
+
+	// Scheduled entries stay drafts until their time has come, entries scheduled in the past are published right away.
+	if !entry.PublishAt.IsZero() {
+		entry.Published = !entry.PublishAt.After(time.Now())
 	}
 
 	entryID := bson.NewObjectId()
@@ -36,23 +53,100 @@ func (cES CourseEntryService) StoreCourseEntry(entry *eduboard.CourseEntry, cfu
 		return errors.Wrapf(err, "error updating course with ID %s", courseID), &eduboard.CourseEntry{}
 	}
 
+	cES.publish(eduboard.EventEntryCreated, course, *entry, entry.Published)
+	if entry.Published {
+		cES.published(course, *entry)
+	}
 	return nil, entry
 }
 
-func (cES CourseEntryService) UpdateCourseEntry(*eduboard.CourseEntry) (*eduboard.CourseEntry, error) {
-	return &eduboard.CourseEntry{}, nil
+func (cES CourseEntryService) UpdateCourseEntry(entryID string, courseID string, userID string, update eduboard.CourseEntryUpdate, cf eduboard.CourseOneFinder) (*eduboard.CourseEntry, error) {
+	err, course := access.Manager(cf, courseID, userID)
+	if err != nil {
+		return &eduboard.CourseEntry{}, err
+	}
+
+	err, entry := cES.ER.FindOneByID(entryID)
+	if err != nil {
+		return &eduboard.CourseEntry{}, errors.Wrapf(eduboard.ErrNotFound, "could not find entry with id %s: %v", entryID, err)
+	}
+
+	if entry.CourseID.Hex() != courseID {
+		return &eduboard.CourseEntry{}, errors.Wrapf(eduboard.ErrNotFound, "entry with ID %s does not belong to course with ID %s", entryID, courseID)
+	}
+
+	set := bson.M{}
+	if update.Date != nil {
+		set["date"] = *update.Date
+	}
+	if update.Message != nil {
+		set["message"] = *update.Message
+	}
+	if update.Pictures != nil {
+		set["pictures"] = update.Pictures
+	}
+	if update.Published != nil {
+		set["published"] = *update.Published
+	}
+
+	change := bson.M{}
+	if update.PublishAt != nil && !update.PublishAt.IsZero() {
+		set["publishAt"] = *update.PublishAt
+		if update.Published == nil {
+			set["published"] = !update.PublishAt.After(time.Now())
+		}
+	} else if update.PublishAt != nil || (update.Published != nil && !*update.Published) {
+		// Neither cancelled schedules nor withdrawn entries may be published by the scheduler later on.
+		change["$unset"] = bson.M{"publishAt": ""}
+	}
+
+	if len(set) > 0 {
+		change["$set"] = set
+	}
+	if len(change) == 0 {
+		return &entry, nil
+	}
+
+	wasPublished := entry.Published
+	if err = cES.ER.Update(entryID, change); err != nil {
+		return &eduboard.CourseEntry{}, errors.Wrapf(err, "error updating courseEntry with ID %s", entryID)
+	}
+
+	err, entry = cES.ER.FindOneByID(entryID)
+	if err != nil {
+		return &eduboard.CourseEntry{}, errors.Wrapf(err, "error finding updated courseEntry with ID %s", entryID)
+	}
+
+	cES.publish(eduboard.EventEntryUpdated, course, entry, entry.Published || wasPublished)
+	if entry.Published && !wasPublished {
+		cES.published(course, entry)
+	}
+	return &entry, nil
 }
 
-func (cES CourseEntryService) DeleteCourseEntry(entryID string, courseID string, cfu eduboard.CourseUpdater) error {
+// DeleteCourseEntry deletes an entry along with its comments and poll responses.
+func (cES CourseEntryService) DeleteCourseEntry(entryID string, courseID string, userID string, cfu eduboard.CourseFindUpdater, cd eduboard.CommentDeleter, pd eduboard.PollResponseDeleter) error {
+	err, course := access.Manager(cfu, courseID, userID)
+	if err != nil {
+		return err
+	}
+
 	err, entry := cES.ER.FindOneByID(entryID)
 	if err != nil {
-		return errors.Errorf("could not find entry with id %s", entryID)
+		return errors.Wrapf(eduboard.ErrNotFound, "could not find entry with id %s: %v", entryID, err)
 	}
 
 	if entry.CourseID.Hex() != courseID {
-		return errors.Errorf("entry with ID %s does not belong to course with ID %s", entryID, courseID)
+		return errors.Wrapf(eduboard.ErrNotFound, "entry with ID %s does not belong to course with ID %s", entryID, courseID)
 	}
 
+	// Comments and poll responses are only reachable through the entry, so they have to go first.
+	if err := cd.DeleteByEntry(entryID); err != nil {
+		return errors.Wrapf(err, "error deleting comments of courseEntry with ID %s", entryID)
+	}
+	if err := pd.DeleteByEntry(entryID); err != nil {
+		return errors.Wrapf(err, "error deleting poll responses of courseEntry with ID %s", entryID)
+	}
 	if err := cES.ER.Delete(entryID); err != nil {
 		return errors.Wrapf(err, "error deleting courseEntry with ID %s", entryID)
 	}
@@ -61,5 +155,54 @@ func (cES CourseEntryService) DeleteCourseEntry(entryID string, courseID string,
 		return err
 	}
 
+	cES.publish(eduboard.EventEntryDeleted, course, entry, entry.Published)
 	return nil
 }
+
+// PublishDue publishes all entries whose scheduled publication time is at or before now and returns them.
+// Entries whose course can not be found are published without notifying anyone; the last such error is returned.
+func (cES CourseEntryService) PublishDue(now time.Time, cf eduboard.CourseOneFinder) (error, []eduboard.CourseEntry) {
+	err, entries := cES.ER.PublishDue(now)
+	if err != nil {
+		return errors.Wrap(err, "error publishing scheduled entries"), []eduboard.CourseEntry{}
+	}
+
+	var failed error
+	for _, entry := range entries {
+		courseID := entry.CourseID.Hex()
+		err, course := cf.FindOneByID(courseID)
+		if err != nil {
+			failed = errors.Wrapf(err, "error finding course with ID %s", courseID)
+			continue
+		}
+		cES.publish(eduboard.EventEntryUpdated, course, entry, true)
+		cES.published(course, entry)
+	}
+	return failed, entries
+}
+
+// publish reports a change of entry to the staff of course, and to all members if the change is visible to them.
+func (cES CourseEntryService) publish(t eduboard.EventType, course eduboard.Course, entry eduboard.CourseEntry, visible bool) {
+	if cES.Events == nil {
+		return
+	}
+
+	recipients := course.StaffIDs()
+	if visible {
+		recipients = course.MemberIDs()
+	}
+	cES.Events.Publish(eduboard.Event{
+		Type:       t,
+		CourseID:   course.ID,
+		EntryID:    entry.ID,
+		Time:       time.Now(),
+		Recipients: recipients,
+	})
+}
+
+// published informs all hooks that entry has been published.
+func (cES CourseEntryService) published(course eduboard.Course, entry eduboard.CourseEntry) {
+	for _, h := range cES.Hooks {
+		h.EntryPublished(course, entry)
+	}
+}
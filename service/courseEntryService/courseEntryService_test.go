@@ -7,28 +7,35 @@ import (
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/mgo.v2/bson"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
 	t.Parallel()
 	r := mock.CourseEntryRepository{}
-	ces := New(&r)
+	ces := New(&r, nil)
 	assert.Equal(t, &r, ces.ER, "repository does not match")
 }
 
 func TestCourseEntryService_StoreCourseEntry(t *testing.T) {
 	successEntry := eduboard.CourseEntry{CourseID: bson.ObjectIdHex("5b23c8d5382d33000150681e")}
 	failureEntry := eduboard.CourseEntry{CourseID: bson.ObjectIdHex("5b23c8d5382d33000150681f")}
+	archivedEntry := eduboard.CourseEntry{CourseID: bson.ObjectIdHex("5b23c8d5382d33000150681d")}
 
 	var testCases = []struct {
 		name  string
 		error bool
 		entry eduboard.CourseEntry
+		user  string
 	}{
-		{"success", false, successEntry},
-		{"no course", true, failureEntry},
+		{"success", false, successEntry, "teacher"},
+		{"no course", true, failureEntry, "teacher"},
+		{"student", true, successEntry, "student"},
+		{"archived", true, archivedEntry, "teacher"},
 	}
 
+	members := []eduboard.Member{{UserID: "teacher", Role: eduboard.RoleTeacher}, {UserID: "student", Role: eduboard.RoleStudent}}
+
 	mockEntryRepo := mock.CourseEntryRepository{}
 	service := CourseEntryService{ER: &mockEntryRepo}
 
@@ -38,8 +45,11 @@ func TestCourseEntryService_StoreCourseEntry(t *testing.T) {
 
 	mockCourseRepo := mock.CourseRepository{}
 	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
-		if id == "5b23c8d5382d33000150681e" {
-			return nil, eduboard.Course{ID: bson.ObjectIdHex("5b23c8d5382d33000150681e")}
+		switch id {
+		case "5b23c8d5382d33000150681e":
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members}
+		case "5b23c8d5382d33000150681d":
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members, Archived: true}
 		}
 		return errors.New("not found"), eduboard.Course{}
 	}
@@ -53,7 +63,7 @@ func TestCourseEntryService_StoreCourseEntry(t *testing.T) {
 			mockCourseRepo.FindFnInvoked = false
 			mockCourseRepo.UpdateFnInvoked = false
 
-			err, e := service.StoreCourseEntry(&v.entry, &mockCourseRepo)
+			err, e := service.StoreCourseEntry(&v.entry, v.user, &mockCourseRepo)
 			if v.error {
 				assert.Equal(t, &eduboard.CourseEntry{}, e, "courseEntry unexpected")
 				assert.NotNil(t, err, "error nil")
@@ -77,16 +87,21 @@ func TestCourseEntryService_DeleteCourseEntry(t *testing.T) {
 	failureEntry := "5b23c8d5382d33000150681f"
 	successCourse := "5b23c8d5382d33000150681a"
 	failureCourse := "5b23c8d5382d33000150681b"
+	members := []eduboard.Member{{UserID: "teacher", Role: eduboard.RoleTeacher}, {UserID: "student", Role: eduboard.RoleStudent}}
 
 	var testCases = []struct {
-		name   string
-		error  bool
-		entry  string
-		course string
+		name        string
+		error       bool
+		invokeEntry bool
+		entry       string
+		course      string
+		user        string
 	}{
-		{"success", false, successEntry, successCourse},
-		{"no entry", true, failureEntry, successCourse},
-		{"no course", true, successEntry, failureCourse},
+		{"success", false, true, successEntry, successCourse, "teacher"},
+		{"no entry", true, true, failureEntry, successCourse, "teacher"},
+		{"wrong course", true, true, successEntry, failureCourse, "teacher"},
+		{"no course", true, false, successEntry, "5b23c8d5382d33000150681c", "teacher"},
+		{"student", true, false, successEntry, successCourse, "student"},
 	}
 
 	mockEntryRepo := mock.CourseEntryRepository{}
@@ -98,25 +113,44 @@ func TestCourseEntryService_DeleteCourseEntry(t *testing.T) {
 		return errors.New("not found"), eduboard.CourseEntry{}
 	}
 	mockEntryRepo.DeleteFn = func(id string) error { return nil }
+	mockCommentRepo := mock.CommentRepository{}
+	mockCommentRepo.DeleteByEntryFn = func(entryID string) error { return nil }
+	mockPollRepo := mock.PollResponseRepository{}
+	mockPollRepo.DeleteByEntryFn = func(entryID string) error { return nil }
 
 	mockCourseRepo := mock.CourseRepository{}
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		if id == successCourse || id == failureCourse {
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members}
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
 	mockCourseRepo.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) { return nil, eduboard.Course{} }
 
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
 			mockEntryRepo.FindOneFnInvoked = false
 			mockEntryRepo.DeleteFnInvoked = false
+			mockCommentRepo.DeleteByEntryFnInvoked = false
+			mockPollRepo.DeleteByEntryFnInvoked = false
 			mockCourseRepo.UpdateFnInvoked = false
 
-			err := service.DeleteCourseEntry(v.entry, v.course, &mockCourseRepo)
-			assert.True(t, mockEntryRepo.FindOneFnInvoked, "FindeOne was not invoked")
+			err := service.DeleteCourseEntry(v.entry, v.course, v.user, &mockCourseRepo, &mockCommentRepo, &mockPollRepo)
+			assert.Equal(t, v.invokeEntry, mockEntryRepo.FindOneFnInvoked, "FindOne was not invoked as expected")
 			if v.error {
 				assert.Errorf(t, err, "error is nil")
+				if v.name == "no entry" || v.name == "wrong course" {
+					assert.Equal(t, eduboard.ErrNotFound, errors.Cause(err), "missing entry is not reported as not found")
+				}
 				assert.False(t, mockCourseRepo.UpdateFnInvoked, "Update was invoked")
 				assert.False(t, mockEntryRepo.DeleteFnInvoked, "Delete was invoked")
+				assert.False(t, mockCommentRepo.DeleteByEntryFnInvoked, "comments were deleted")
+				assert.False(t, mockPollRepo.DeleteByEntryFnInvoked, "poll responses were deleted")
 				return
 			}
 			assert.Nil(t, err, "error not nil")
+			assert.True(t, mockCommentRepo.DeleteByEntryFnInvoked, "comments were not deleted")
+			assert.True(t, mockPollRepo.DeleteByEntryFnInvoked, "poll responses were not deleted")
 			assert.True(t, mockEntryRepo.DeleteFnInvoked, "Delete was not invoked")
 			assert.True(t, mockCourseRepo.UpdateFnInvoked, "Update was not invoked")
 		})
@@ -124,5 +158,331 @@ func TestCourseEntryService_DeleteCourseEntry(t *testing.T) {
 }
 
 func TestCourseEntryService_UpdateCourseEntry(t *testing.T) {
+	successEntry := "5b23c8d5382d33000150681e"
+	failureEntry := "5b23c8d5382d33000150681f"
+	brokenEntry := "5b23c8d5382d33000150681d"
+	successCourse := "5b23c8d5382d33000150681a"
+	failureCourse := "5b23c8d5382d33000150681b"
+
+	message := "updated"
+	published := true
+
+	members := []eduboard.Member{{UserID: "teacher", Role: eduboard.RoleTeacher}, {UserID: "student", Role: eduboard.RoleStudent}}
+
+	var testCases = []struct {
+		name         string
+		error        bool
+		invokeEntry  bool
+		invokeUpdate bool
+		entry        string
+		course       string
+		user         string
+		update       eduboard.CourseEntryUpdate
+	}{
+		{"success", false, true, true, successEntry, successCourse, "teacher", eduboard.CourseEntryUpdate{Message: &message, Published: &published}},
+		{"empty update", false, true, false, successEntry, successCourse, "teacher", eduboard.CourseEntryUpdate{}},
+		{"no entry", true, true, false, failureEntry, successCourse, "teacher", eduboard.CourseEntryUpdate{Message: &message}},
+		{"wrong course", true, true, false, successEntry, failureCourse, "teacher", eduboard.CourseEntryUpdate{Message: &message}},
+		{"update failed", true, true, true, brokenEntry, successCourse, "teacher", eduboard.CourseEntryUpdate{Message: &message}},
+		{"student", true, false, false, successEntry, successCourse, "student", eduboard.CourseEntryUpdate{Message: &message}},
+	}
+
+	mockCourseRepo := mock.CourseRepository{}
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members}
+	}
+
+	mockEntryRepo := mock.CourseEntryRepository{}
+	service := CourseEntryService{ER: &mockEntryRepo}
+
+	var stored bson.M
+	mockEntryRepo.FindOneFn = func(id string) (error, eduboard.CourseEntry) {
+		if id == successEntry || id == brokenEntry {
+			entry := eduboard.CourseEntry{ID: bson.ObjectIdHex(id), CourseID: bson.ObjectIdHex(successCourse)}
+			if set, ok := stored["$set"].(bson.M); ok {
+				entry.Message, _ = set["message"].(string)
+				entry.Published, _ = set["published"].(bool)
+			}
+			return nil, entry
+		}
+		return errors.New("not found"), eduboard.CourseEntry{}
+	}
+	mockEntryRepo.UpdateFn = func(id string, update bson.M) error {
+		if id == brokenEntry {
+			return errors.New("error updating")
+		}
+		stored = update
+		return nil
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			stored = nil
+			mockEntryRepo.FindOneFnInvoked = false
+			mockEntryRepo.UpdateFnInvoked = false
+
+			e, err := service.UpdateCourseEntry(v.entry, v.course, v.user, v.update, &mockCourseRepo)
+			assert.Equal(t, v.invokeEntry, mockEntryRepo.FindOneFnInvoked, "FindOne was not invoked as expected")
+			assert.Equal(t, v.invokeUpdate, mockEntryRepo.UpdateFnInvoked, "Update was not invoked as expected")
+			if v.error {
+				assert.Error(t, err, "error is nil")
+				assert.Equal(t, &eduboard.CourseEntry{}, e, "courseEntry unexpected")
+				if v.name == "no entry" || v.name == "wrong course" {
+					assert.Equal(t, eduboard.ErrNotFound, errors.Cause(err), "missing entry is not reported as not found")
+				}
+				return
+			}
+			assert.Nil(t, err, "error not nil")
+			assert.Equal(t, v.entry, e.ID.Hex(), "entry ID does not match")
+			if v.update.Message != nil {
+				assert.Equal(t, bson.M{"$set": bson.M{"message": message, "published": published}}, stored, "update does not match")
+				assert.Equal(t, message, e.Message, "stored entry was not returned")
+				assert.True(t, e.Published, "stored entry was not returned")
+			}
+		})
+	}
+}
+
+type recordingHook struct {
+	published []eduboard.CourseEntry
+}
+
+func (r *recordingHook) EntryPublished(course eduboard.Course, entry eduboard.CourseEntry) {
+	r.published = append(r.published, entry)
+}
+
+func TestCourseEntryService_StoreCourseEntry_Date(t *testing.T) {
+	courseID := bson.ObjectIdHex("5b23c8d5382d33000150681e")
+	created := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	date := time.Date(2018, 6, 2, 8, 0, 0, 0, time.UTC)
+
+	var testCases = []struct {
+		name     string
+		entry    eduboard.CourseEntry
+		expected time.Time
+	}{
+		{"date", eduboard.CourseEntry{CourseID: courseID, Date: date, CreatedAt: created}, date},
+		{"no date", eduboard.CourseEntry{CourseID: courseID, CreatedAt: created}, created},
+	}
+
+	mockEntryRepo := mock.CourseEntryRepository{}
+	mockCourseRepo := mock.CourseRepository{}
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		return nil, eduboard.Course{ID: courseID, Members: []eduboard.Member{{UserID: "teacher", Role: eduboard.RoleTeacher}}}
+	}
+	mockCourseRepo.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) { return nil, eduboard.Course{} }
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			var inserted eduboard.CourseEntry
+			mockEntryRepo.InsertFn = func(entry eduboard.CourseEntry) error {
+				inserted = entry
+				return nil
+			}
+			service := CourseEntryService{ER: &mockEntryRepo}
+
+			err, _ := service.StoreCourseEntry(&v.entry, "teacher", &mockCourseRepo)
+			assert.Nil(t, err, "error not nil")
+			assert.Equal(t, v.expected, inserted.Date, "date does not match")
+		})
+	}
+}
+
+func TestCourseEntryService_StoreCourseEntry_PublishAt(t *testing.T) {
+	courseID := bson.ObjectIdHex("5b23c8d5382d33000150681e")
+	members := []eduboard.Member{{UserID: "teacher", Role: eduboard.RoleTeacher}}
+
+	var testCases = []struct {
+		name      string
+		entry     eduboard.CourseEntry
+		published bool
+	}{
+		{"draft", eduboard.CourseEntry{CourseID: courseID}, false},
+		{"published", eduboard.CourseEntry{CourseID: courseID, Published: true}, true},
+		{"scheduled", eduboard.CourseEntry{CourseID: courseID, Published: true, PublishAt: time.Now().Add(time.Hour)}, false},
+		{"scheduled in past", eduboard.CourseEntry{CourseID: courseID, PublishAt: time.Now().Add(-time.Hour)}, true},
+	}
+
+	mockEntryRepo := mock.CourseEntryRepository{}
+	mockEntryRepo.InsertFn = func(course eduboard.CourseEntry) error { return nil }
+	mockCourseRepo := mock.CourseRepository{}
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		return nil, eduboard.Course{ID: courseID, Members: members}
+	}
+	mockCourseRepo.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) { return nil, eduboard.Course{} }
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			hook := &recordingHook{}
+			service := New(&mockEntryRepo, nil, hook)
+
+			err, e := service.StoreCourseEntry(&v.entry, "teacher", &mockCourseRepo)
+			assert.Nil(t, err, "error not nil")
+			assert.Equal(t, v.published, e.Published, "published does not match")
+			assert.Equal(t, v.published, len(hook.published) == 1, "hook was not invoked as expected")
+		})
+	}
+}
+
+func TestCourseEntryService_UpdateCourseEntry_PublishAt(t *testing.T) {
+	entryID := "5b23c8d5382d33000150681e"
+	courseID := "5b23c8d5382d33000150681a"
+	members := []eduboard.Member{{UserID: "teacher", Role: eduboard.RoleTeacher}}
+
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	published := true
+	unpublished := false
+
+	var testCases = []struct {
+		name    string
+		update  eduboard.CourseEntryUpdate
+		change  bson.M
+		invoked bool
+	}{
+		{"schedule", eduboard.CourseEntryUpdate{PublishAt: &future}, bson.M{"$set": bson.M{"publishAt": future, "published": false}}, false},
+		{"schedule in past", eduboard.CourseEntryUpdate{PublishAt: &past}, bson.M{"$set": bson.M{"publishAt": past, "published": true}}, true},
+		{"cancel schedule", eduboard.CourseEntryUpdate{PublishAt: &time.Time{}}, bson.M{"$unset": bson.M{"publishAt": ""}}, false},
+		{"publish", eduboard.CourseEntryUpdate{Published: &published}, bson.M{"$set": bson.M{"published": true}}, true},
+		{"unpublish", eduboard.CourseEntryUpdate{Published: &unpublished}, bson.M{"$set": bson.M{"published": false}, "$unset": bson.M{"publishAt": ""}}, false},
+	}
+
+	mockCourseRepo := mock.CourseRepository{}
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members}
+	}
+
+	mockEntryRepo := mock.CourseEntryRepository{}
+	var stored bson.M
+	mockEntryRepo.FindOneFn = func(id string) (error, eduboard.CourseEntry) {
+		entry := eduboard.CourseEntry{ID: bson.ObjectIdHex(id), CourseID: bson.ObjectIdHex(courseID)}
+		if set, ok := stored["$set"].(bson.M); ok {
+			entry.Published, _ = set["published"].(bool)
+		}
+		return nil, entry
+	}
+	mockEntryRepo.UpdateFn = func(id string, update bson.M) error {
+		stored = update
+		return nil
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			stored = nil
+			hook := &recordingHook{}
+			service := New(&mockEntryRepo, nil, hook)
+
+			_, err := service.UpdateCourseEntry(entryID, courseID, "teacher", v.update, &mockCourseRepo)
+			assert.Nil(t, err, "error not nil")
+			assert.Equal(t, v.change, stored, "update does not match")
+			assert.Equal(t, v.invoked, len(hook.published) == 1, "hook was not invoked as expected")
+		})
+	}
+}
 
+func TestCourseEntryService_PublishDue(t *testing.T) {
+	courseID := bson.ObjectIdHex("5b23c8d5382d33000150681a")
+	deletedID := bson.ObjectIdHex("5b23c8d5382d33000150681b")
+	due := []eduboard.CourseEntry{
+		{ID: "1", CourseID: courseID, Published: true},
+		{ID: "2", CourseID: courseID, Published: true},
+	}
+
+	var testCases = []struct {
+		name        string
+		repoError   bool
+		courseError bool
+		hooked      int
+	}{
+		{"success", false, false, 2},
+		{"repository error", true, false, 0},
+		{"course error", false, true, 2},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockEntryRepo := mock.CourseEntryRepository{}
+			mockEntryRepo.PublishDueFn = func(now time.Time) (error, []eduboard.CourseEntry) {
+				if v.repoError {
+					return errors.New("error"), nil
+				}
+				if v.courseError {
+					return nil, append([]eduboard.CourseEntry{{ID: "0", CourseID: deletedID, Published: true}}, due...)
+				}
+				return nil, due
+			}
+			mockCourseRepo := mock.CourseRepository{}
+			mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+				if id == deletedID.Hex() {
+					return errors.New("not found"), eduboard.Course{}
+				}
+				return nil, eduboard.Course{ID: courseID}
+			}
+			hook := &recordingHook{}
+			service := New(&mockEntryRepo, nil, hook)
+
+			err, entries := service.PublishDue(time.Now(), &mockCourseRepo)
+			assert.True(t, mockEntryRepo.PublishDueFnInvoked, "PublishDue was not invoked")
+			assert.Len(t, hook.published, v.hooked, "hook was not invoked as expected")
+			if v.repoError {
+				assert.Error(t, err, "error is nil")
+				return
+			}
+			if v.courseError {
+				assert.Error(t, err, "error is nil")
+				assert.Len(t, entries, 3, "entries do not match")
+				return
+			}
+			assert.Nil(t, err, "error not nil")
+			assert.Equal(t, due, entries, "entries do not match")
+		})
+	}
+}
+
+func TestCourseEntryService_Events(t *testing.T) {
+	courseID := bson.ObjectIdHex("5b23c8d5382d33000150681a")
+	members := []eduboard.Member{
+		{UserID: "owner", Role: eduboard.RoleOwner},
+		{UserID: "teacher", Role: eduboard.RoleTeacher},
+		{UserID: "student", Role: eduboard.RoleStudent},
+	}
+
+	var testCases = []struct {
+		name       string
+		published  bool
+		recipients []string
+	}{
+		{"published", true, []string{"owner", "teacher", "student"}},
+		{"draft", false, []string{"owner", "teacher"}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockEntryRepo := mock.CourseEntryRepository{}
+			mockEntryRepo.InsertFn = func(entry eduboard.CourseEntry) error { return nil }
+			mockCourseRepo := mock.CourseRepository{}
+			mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+				return nil, eduboard.Course{ID: courseID, Members: members}
+			}
+			mockCourseRepo.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) { return nil, eduboard.Course{} }
+
+			events := []eduboard.Event{}
+			publisher := mock.EventPublisher{}
+			publisher.PublishFn = func(event eduboard.Event) {
+				events = append(events, event)
+			}
+			service := New(&mockEntryRepo, &publisher)
+
+			entry := eduboard.CourseEntry{CourseID: courseID, Published: v.published}
+			err, stored := service.StoreCourseEntry(&entry, "teacher", &mockCourseRepo)
+			assert.Nil(t, err, "error not nil")
+			if !assert.Len(t, events, 1, "unexpected number of events") {
+				return
+			}
+			assert.Equal(t, eduboard.EventEntryCreated, events[0].Type, "event type does not match")
+			assert.Equal(t, courseID, events[0].CourseID, "course does not match")
+			assert.Equal(t, stored.ID, events[0].EntryID, "entry does not match")
+			assert.Equal(t, v.recipients, events[0].Recipients, "recipients do not match")
+		})
+	}
 }
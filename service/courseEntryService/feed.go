@@ -0,0 +1,130 @@
+package courseEntryService
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetCourseEntries returns a page of the entries of a course. Members see published entries, staff also sees drafts.
+func (cES CourseEntryService) GetCourseEntries(courseID string, userID string, filter eduboard.CourseEntryFilter, cf eduboard.CourseOneFinder) (error, eduboard.CourseEntryPage) {
+	err, course := access.Member(cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.CourseEntryPage{}
+	}
+	return cES.feed([]eduboard.Course{course}, userID, filter)
+}
+
+// GetFeed returns a page of the entries of all courses of a user, with the same visibility as GetCourseEntries.
+func (cES CourseEntryService) GetFeed(userID string, filter eduboard.CourseEntryFilter, cmf eduboard.CourseManyFinder) (error, eduboard.CourseEntryPage) {
+	err, courses := cmf.FindMany(bson.M{"members.userID": userID})
+	if err != nil {
+		return errors.Wrapf(err, "error finding courses of user %s", userID), eduboard.CourseEntryPage{}
+	}
+	return cES.feed(courses, userID, filter)
+}
+
+func (cES CourseEntryService) feed(courses []eduboard.Course, userID string, filter eduboard.CourseEntryFilter) (error, eduboard.CourseEntryPage) {
+	if filter.Limit < 0 {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "negative limit %d", filter.Limit), eduboard.CourseEntryPage{}
+	}
+	if filter.Limit == 0 {
+		filter.Limit = eduboard.DefaultEntryLimit
+	}
+	if filter.Limit > eduboard.MaxEntryLimit {
+		filter.Limit = eduboard.MaxEntryLimit
+	}
+
+	var staff, members []bson.ObjectId
+	for _, c := range courses {
+		if c.IsStaff(userID) {
+			staff = append(staff, c.ID)
+		} else {
+			members = append(members, c.ID)
+		}
+	}
+
+	var visible []bson.M
+	if len(staff) > 0 {
+		visible = append(visible, bson.M{"courseID": bson.M{"$in": staff}})
+	}
+	if len(members) > 0 {
+		visible = append(visible, bson.M{"courseID": bson.M{"$in": members}, "published": true})
+	}
+	if len(visible) == 0 {
+		return nil, eduboard.CourseEntryPage{Entries: []eduboard.CourseEntry{}}
+	}
+
+	query := []bson.M{{"$or": visible}}
+	if filter.Published != nil {
+		query = append(query, bson.M{"published": *filter.Published})
+	}
+
+	date := bson.M{}
+	if !filter.From.IsZero() {
+		date["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		date["$lt"] = filter.To
+	}
+	if len(date) > 0 {
+		query = append(query, bson.M{"date": date})
+	}
+
+	if filter.Cursor != "" {
+		cursorDate, cursorID, err := parseCursor(filter.Cursor)
+		if err != nil {
+			return err, eduboard.CourseEntryPage{}
+		}
+		query = append(query, bson.M{"$or": []bson.M{
+			{"date": bson.M{"$lt": cursorDate}},
+			{"date": cursorDate, "_id": bson.M{"$lt": cursorID}},
+		}})
+	}
+
+	// One more entry than requested tells whether there is a next page.
+	err, entries := cES.ER.FindFeed(bson.M{"$and": query}, filter.Limit+1)
+	if err != nil {
+		return errors.Wrap(err, "error finding course entries"), eduboard.CourseEntryPage{}
+	}
+
+	page := eduboard.CourseEntryPage{Entries: entries}
+	if len(entries) > filter.Limit {
+		page.Entries = entries[:filter.Limit]
+		page.Next = cursor(page.Entries[filter.Limit-1])
+	}
+	return nil, page
+}
+
+// cursor encodes the position of entry in a feed sorted by date and ID. Dates are stored with millisecond
+// precision, counting milliseconds also covers dates outside the range of UnixNano.
+func cursor(entry eduboard.CourseEntry) string {
+	millis := entry.Date.Unix()*1000 + int64(entry.Date.Nanosecond()/int(time.Millisecond))
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", millis, entry.ID.Hex())))
+}
+
+func parseCursor(c string) (time.Time, bson.ObjectId, error) {
+	invalid := errors.Wrapf(eduboard.ErrInvalidInput, "invalid cursor %s", c)
+
+	b, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return time.Time{}, "", invalid
+	}
+
+	parts := strings.SplitN(string(b), ":", 2)
+	if len(parts) != 2 || !bson.IsObjectIdHex(parts[1]) {
+		return time.Time{}, "", invalid
+	}
+
+	millis, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", invalid
+	}
+	return time.Unix(millis/1000, millis%1000*int64(time.Millisecond)), bson.ObjectIdHex(parts[1]), nil
+}
@@ -0,0 +1,170 @@
+package courseEntryService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+var (
+	staffCourse   = bson.ObjectIdHex("5b23c8d5382d33000150681e")
+	studentCourse = bson.ObjectIdHex("5b23c8d5382d33000150681f")
+)
+
+func feedCourses() map[string]eduboard.Course {
+	return map[string]eduboard.Course{
+		staffCourse.Hex():   {ID: staffCourse, Members: []eduboard.Member{{UserID: "user", Role: eduboard.RoleTeacher}}},
+		studentCourse.Hex(): {ID: studentCourse, Members: []eduboard.Member{{UserID: "user", Role: eduboard.RoleStudent}}},
+	}
+}
+
+func feedEntries(n int) []eduboard.CourseEntry {
+	entries := make([]eduboard.CourseEntry, n)
+	date := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := range entries {
+		entries[i] = eduboard.CourseEntry{ID: bson.NewObjectId(), CourseID: staffCourse, Date: date.Add(-time.Duration(i) * time.Hour)}
+	}
+	return entries
+}
+
+func TestCourseEntryService_GetCourseEntries(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		courseID string
+		userID   string
+		error    error
+		invoked  bool
+		query    bson.M
+	}{
+		{"staff", staffCourse.Hex(), "user", nil, true, bson.M{"$and": []bson.M{{"$or": []bson.M{{"courseID": bson.M{"$in": []bson.ObjectId{staffCourse}}}}}}}},
+		{"student", studentCourse.Hex(), "user", nil, true, bson.M{"$and": []bson.M{{"$or": []bson.M{{"courseID": bson.M{"$in": []bson.ObjectId{studentCourse}}, "published": true}}}}}},
+		{"no member", staffCourse.Hex(), "stranger", eduboard.ErrForbidden, false, nil},
+	}
+
+	mockCourseRepo := mock.CourseRepository{}
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		if c, ok := feedCourses()[id]; ok {
+			return nil, c
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			var query bson.M
+			mockEntryRepo := mock.CourseEntryRepository{}
+			mockEntryRepo.FindFeedFn = func(q bson.M, limit int) (error, []eduboard.CourseEntry) {
+				query = q
+				return nil, []eduboard.CourseEntry{}
+			}
+			service := CourseEntryService{ER: &mockEntryRepo}
+
+			err, _ := service.GetCourseEntries(v.courseID, v.userID, eduboard.CourseEntryFilter{}, &mockCourseRepo)
+			assert.Equal(t, v.error, errors.Cause(err), "error does not match")
+			assert.Equal(t, v.invoked, mockEntryRepo.FindFeedFnInvoked, "FindFeed was not invoked as expected")
+			if v.invoked {
+				assert.Equal(t, v.query, query, "query does not match")
+			}
+		})
+	}
+}
+
+func TestCourseEntryService_GetFeed(t *testing.T) {
+	published := false
+	from := time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)
+	entries := feedEntries(5)
+
+	var testCases = []struct {
+		name    string
+		filter  eduboard.CourseEntryFilter
+		stored  []eduboard.CourseEntry
+		limit   int
+		entries int
+		next    bool
+		error   bool
+	}{
+		{"default limit", eduboard.CourseEntryFilter{}, entries, eduboard.DefaultEntryLimit + 1, 5, false, false},
+		{"limit capped", eduboard.CourseEntryFilter{Limit: 1000}, entries, eduboard.MaxEntryLimit + 1, 5, false, false},
+		{"paginated", eduboard.CourseEntryFilter{Limit: 2}, entries, 3, 2, true, false},
+		{"filtered", eduboard.CourseEntryFilter{From: from, To: to, Published: &published}, entries, eduboard.DefaultEntryLimit + 1, 5, false, false},
+		{"cursor", eduboard.CourseEntryFilter{Cursor: cursor(entries[1])}, entries[2:], eduboard.DefaultEntryLimit + 1, 3, false, false},
+		{"invalid cursor", eduboard.CourseEntryFilter{Cursor: "invalid"}, entries, 0, 0, false, true},
+		{"negative limit", eduboard.CourseEntryFilter{Limit: -1}, entries, 0, 0, false, true},
+	}
+
+	mockCourseRepo := mock.CourseRepository{}
+	mockCourseRepo.FindManyFn = func(query bson.M) (error, []eduboard.Course) {
+		courses := feedCourses()
+		return nil, []eduboard.Course{courses[staffCourse.Hex()], courses[studentCourse.Hex()]}
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			var (
+				query bson.M
+				limit int
+			)
+			mockEntryRepo := mock.CourseEntryRepository{}
+			mockEntryRepo.FindFeedFn = func(q bson.M, l int) (error, []eduboard.CourseEntry) {
+				query, limit = q, l
+				if l < len(v.stored) {
+					return nil, v.stored[:l]
+				}
+				return nil, v.stored
+			}
+			service := CourseEntryService{ER: &mockEntryRepo}
+
+			err, page := service.GetFeed("user", v.filter, &mockCourseRepo)
+			if v.error {
+				assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "error does not match")
+				assert.False(t, mockEntryRepo.FindFeedFnInvoked, "FindFeed was invoked")
+				return
+			}
+
+			assert.Nil(t, err, "should not cause error")
+			assert.Equal(t, v.limit, limit, "limit does not match")
+			assert.Len(t, page.Entries, v.entries, "number of entries does not match")
+			assert.Equal(t, v.next, page.Next != "", "next cursor does not match")
+			if v.next {
+				assert.Equal(t, cursor(page.Entries[len(page.Entries)-1]), page.Next, "next cursor does not point to last entry")
+			}
+
+			conditions := query["$and"].([]bson.M)
+			visible := conditions[0]["$or"].([]bson.M)
+			assert.Len(t, visible, 2, "staff and student courses are not both visible")
+			if v.filter.Published != nil {
+				assert.Contains(t, conditions, bson.M{"published": false}, "published filter missing")
+			}
+			if !v.filter.From.IsZero() {
+				assert.Contains(t, conditions, bson.M{"date": bson.M{"$gte": from, "$lt": to}}, "date filter missing")
+			}
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+	for _, d := range []time.Time{
+		time.Date(2018, 6, 1, 12, 30, 0, 123000000, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 999000000, time.UTC),
+		time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC),
+		{},
+	} {
+		entry := eduboard.CourseEntry{ID: bson.NewObjectId(), Date: d}
+		date, id, err := parseCursor(cursor(entry))
+		assert.Nil(t, err, "should not cause error")
+		assert.True(t, entry.Date.Equal(date), "date %v does not match %v", date, entry.Date)
+		assert.Equal(t, entry.ID, id, "id does not match")
+	}
+
+	var err error
+	for _, c := range []string{"invalid", "MTIzNDU", "YWJjOjViMjNjOGQ1MzgyZDMzMDAwMTUwNjgxZQ"} {
+		_, _, err = parseCursor(c)
+		assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "invalid cursor %s was accepted", c)
+	}
+}
@@ -0,0 +1,40 @@
+package courseEntryService
+
+import (
+	"github.com/eduboard/backend"
+	"log"
+	"time"
+)
+
+// Scheduler periodically publishes entries whose PublishAt time has passed.
+type Scheduler struct {
+	Service  eduboard.CourseEntryService
+	Courses  eduboard.CourseOneFinder
+	Interval time.Duration
+	Logger   *log.Logger
+}
+
+// Run publishes due entries every Interval until stop is closed. A nil stop channel runs forever.
+func (s *Scheduler) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(time.Now())
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(now time.Time) {
+	err, entries := s.Service.PublishDue(now, s.Courses)
+	if err != nil {
+		s.Logger.Printf("error publishing scheduled entries: %v", err)
+	}
+	if len(entries) > 0 {
+		s.Logger.Printf("published %d scheduled entries", len(entries))
+	}
+}
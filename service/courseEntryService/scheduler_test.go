@@ -0,0 +1,60 @@
+package courseEntryService
+
+import (
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestScheduler_Run(t *testing.T) {
+	calls := make(chan time.Time, 10)
+	mockService := mock.CourseEntryService{}
+	mockService.PublishDueFn = func(now time.Time, cf eduboard.CourseOneFinder) (error, []eduboard.CourseEntry) {
+		calls <- now
+		return nil, []eduboard.CourseEntry{{ID: "1"}}
+	}
+
+	b := &bytes.Buffer{}
+	s := Scheduler{Service: &mockService, Courses: &mock.CourseRepository{}, Interval: time.Millisecond, Logger: log.New(b, "", 0)}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.Run(stop)
+		close(done)
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatal("PublishDue was not invoked")
+		}
+	}
+	close(stop)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	assert.Contains(t, b.String(), "published 1 scheduled entries", "log does not contain published entries")
+}
+
+func TestScheduler_tick(t *testing.T) {
+	mockService := mock.CourseEntryService{}
+	mockService.PublishDueFn = func(now time.Time, cf eduboard.CourseOneFinder) (error, []eduboard.CourseEntry) {
+		return errors.New("database down"), []eduboard.CourseEntry{}
+	}
+
+	b := &bytes.Buffer{}
+	s := Scheduler{Service: &mockService, Logger: log.New(b, "", 0)}
+	s.tick(time.Now())
+	assert.True(t, mockService.PublishDueFnInvoked, "PublishDue was not invoked")
+	assert.Contains(t, b.String(), "database down", "log does not contain error")
+}
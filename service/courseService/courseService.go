@@ -2,25 +2,65 @@ package courseService
 
 import (
 	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
 	"github.com/pkg/errors"
 	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"time"
 )
 
 type CourseService struct {
 	CR eduboard.CourseRepository
+	// Events is informed about membership changes. It may be nil.
+	Events eduboard.EventPublisher
+	// Notifications is informed about new members and schedule changes. It may be nil.
+	Notifications eduboard.NotificationCreator
+	// Deleters delete further data of a course, in order, before the course itself is deleted.
+	Deleters []eduboard.CourseDataDeleter
 }
 
-func New(repository eduboard.CourseRepository) CourseService {
+func New(repository eduboard.CourseRepository, events eduboard.EventPublisher, notifications eduboard.NotificationCreator, deleters ...eduboard.CourseDataDeleter) CourseService {
 	return CourseService{
-		CR: repository,
+		CR:            repository,
+		Events:        events,
+		Notifications: notifications,
+		Deleters:      deleters,
 	}
 }
 
-func (cS CourseService) GetAllCourses() (error, []eduboard.Course) {
-	return cS.CR.FindMany(bson.M{})
+// SearchCourses returns a page of the courses matching query. Missing pagination and sorting is filled in with defaults,
+// text searches are sorted by relevance and all other searches by title.
+func (cS CourseService) SearchCourses(query eduboard.CourseQuery) (error, eduboard.CoursePage) {
+	if query.Offset < 0 {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "negative offset %d", query.Offset), eduboard.CoursePage{}
+	}
+	if query.Limit <= 0 {
+		query.Limit = eduboard.DefaultCourseLimit
+	}
+	if query.Limit > eduboard.MaxCourseLimit {
+		query.Limit = eduboard.MaxCourseLimit
+	}
+
+	if query.Sort == "" {
+		query.Sort = "title"
+		if query.Text != "" {
+			query.Sort = "relevance"
+		}
+	}
+	field := strings.TrimPrefix(query.Sort, "-")
+	if !eduboard.CourseSortFields[field] || (field == "relevance" && (query.Text == "" || field != query.Sort)) {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "can not sort by %s", query.Sort), eduboard.CoursePage{}
+	}
+
+	err, courses, total := cS.CR.Search(query)
+	if err != nil {
+		return errors.Wrap(err, "error searching courses"), eduboard.CoursePage{}
+	}
+	return nil, eduboard.CoursePage{Courses: courses, Total: total, Offset: query.Offset, Limit: query.Limit}
 }
 
-func (cS CourseService) GetCourse(id string, cef eduboard.CourseEntryManyFinder) (error, eduboard.Course) {
+// GetCourse returns the course with the entries userID may see.
+func (cS CourseService) GetCourse(id string, userID string, cef eduboard.CourseEntryManyFinder) (error, eduboard.Course) {
 	err, course := cS.CR.FindOneByID(id)
 	if err != nil {
 		return errors.Wrapf(err, "error finding course %s", id), eduboard.Course{}
@@ -32,26 +72,28 @@ func (cS CourseService) GetCourse(id string, cef eduboard.CourseEntryManyFinder)
 
 	err, e := cef.FindMany(bson.M{"courseID": course.ID})
 	if err != nil {
-		return errors.Wrapf(err, "error finding courseEntries from %d", course.ID), eduboard.Course{}
+		return errors.Wrapf(err, "error finding courseEntries from %s", course.ID.Hex()), eduboard.Course{}
 	}
 
 	course.Entries = e
+	course.Entries = course.VisibleEntries(userID)
 	return nil, course
 }
 
 func (cS CourseService) GetCoursesByMember(id string, cef eduboard.CourseEntryManyFinder) (error, []eduboard.Course) {
-	err, courses := cS.CR.FindMany(bson.M{"members": id})
+	err, courses := cS.CR.FindMany(bson.M{"members.userID": id})
 	if err != nil {
 		return errors.Wrapf(err, "error finding courses %s", id), []eduboard.Course{}
 	}
 
-	for _, course := range courses {
+	for k, course := range courses {
 		if len(course.EntryIDs) > 0 {
 			err, e := cef.FindMany(bson.M{"courseID": course.ID})
 			if err != nil {
 				return errors.Wrapf(err, "error finding courseEntries from %s", course.ID), []eduboard.Course{}
 			}
 			course.Entries = e
+			courses[k].Entries = course.VisibleEntries(id)
 		}
 	}
 
@@ -64,7 +106,7 @@ func (cS CourseService) GetMembers(id string, uF eduboard.UserFinder) (error, []
 		return errors.Wrapf(err, "error finding course %s", id), []eduboard.User{}
 	}
 
-	err, users := uF.FindMembers(course.Members)
+	err, users := uF.FindMembers(course.MemberIDs())
 	if err != nil {
 		return errors.Wrapf(err, "error finding members from course %s", id), []eduboard.User{}
 	}
@@ -72,18 +114,341 @@ func (cS CourseService) GetMembers(id string, uF eduboard.UserFinder) (error, []
 	return nil, users
 }
 
-func (cS CourseService) AddMembers(course string, members []string) (error, eduboard.Course) {
-	return cS.CR.Update(course, bson.M{"$push": bson.M{"members": bson.M{"$each": members}}})
+func (cS CourseService) AddMembers(id string, userID string, members []eduboard.Member) (error, eduboard.Course) {
+	err, course := cS.CR.FindOneByID(id)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", id), eduboard.Course{}
+	}
+
+	role, _ := course.RoleOf(userID)
+	if !role.IsStaff() {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s may not add members to course %s", userID, id), eduboard.Course{}
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not add members to course %s", id), eduboard.Course{}
+	}
+
+	checked := []eduboard.Member{}
+	for _, m := range members {
+		if m.Role == "" {
+			m.Role = eduboard.RoleStudent
+		}
+
+		switch m.Role {
+		case eduboard.RoleStudent:
+		case eduboard.RoleTeacher:
+			if role != eduboard.RoleOwner {
+				return errors.Wrapf(eduboard.ErrForbidden, "only the owner may add teachers to course %s", id), eduboard.Course{}
+			}
+		default:
+			return errors.Wrapf(eduboard.ErrInvalidInput, "role %s can not be assigned", m.Role), eduboard.Course{}
+		}
+
+		if _, ok := course.RoleOf(m.UserID); !ok {
+			checked = append(checked, m)
+		}
+	}
+
+	// Users added twice or concurrently are skipped by AddMember.
+	updated := course
+	newMembers := []eduboard.Member{}
+	for _, m := range checked {
+		err, c, ok := cS.CR.AddMember(id, m)
+		if err != nil {
+			return errors.Wrapf(err, "error adding user %s to course %s", m.UserID, id), eduboard.Course{}
+		}
+		updated = c
+		if ok {
+			newMembers = append(newMembers, m)
+		}
+	}
+
+	if len(newMembers) > 0 {
+		added := eduboard.Course{Members: newMembers}.MemberIDs()
+		cS.publish(eduboard.EventMemberAdded, course.ID, added, updated.MemberIDs())
+		cS.notify(added, eduboard.Notification{
+			Type:        eduboard.NotificationMemberAdded,
+			CourseID:    course.ID,
+			CourseTitle: course.Title,
+			ActorID:     userID,
+		})
+	}
+	return nil, updated
+}
+
+// JoinCourse adds userID to a course as a student. It does not check permissions, callers have to make sure
+// the user was invited.
+func (cS CourseService) JoinCourse(id string, userID string) (error, eduboard.Course) {
+	err, course := cS.CR.FindOneByID(id)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", id), eduboard.Course{}
+	}
+
+	if _, ok := course.RoleOf(userID); ok {
+		return nil, course
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not join course %s", id), eduboard.Course{}
+	}
+
+	member := eduboard.Member{UserID: userID, Role: eduboard.RoleStudent}
+	err, updated, ok := cS.CR.AddMember(id, member)
+	if err != nil {
+		return errors.Wrapf(err, "error adding user %s to course %s", userID, id), eduboard.Course{}
+	}
+	if !ok {
+		// The user joined concurrently.
+		return nil, updated
+	}
+
+	cS.publish(eduboard.EventMemberAdded, course.ID, []string{userID}, updated.MemberIDs())
+	return nil, updated
+}
+
+func (cS CourseService) RemoveMembers(id string, userID string, members []string) (error, eduboard.Course) {
+	err, course := cS.CR.FindOneByID(id)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", id), eduboard.Course{}
+	}
+
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not remove members from course %s", id), eduboard.Course{}
+	}
+
+	role, _ := course.RoleOf(userID)
+	for _, m := range members {
+		// Everyone may leave a course on their own, removing others requires staff permissions.
+		if m != userID && !role.IsStaff() {
+			return errors.Wrapf(eduboard.ErrForbidden, "user %s may not remove members from course %s", userID, id), eduboard.Course{}
+		}
+
+		if r, _ := course.RoleOf(m); r == eduboard.RoleOwner {
+			return errors.Wrapf(eduboard.ErrForbidden, "the owner can not be removed from course %s", id), eduboard.Course{}
+		}
+
+		// Teachers may only be removed by the owner or by themselves.
+		if r, _ := course.RoleOf(m); r == eduboard.RoleTeacher && m != userID && role != eduboard.RoleOwner {
+			return errors.Wrapf(eduboard.ErrForbidden, "only the owner may remove teachers from course %s", id), eduboard.Course{}
+		}
+	}
+
+	err, updated := cS.CR.Update(id, bson.M{"$pull": bson.M{"members": bson.M{"userID": bson.M{"$in": members}}}})
+	if err != nil {
+		return err, eduboard.Course{}
+	}
+
+	removed := []string{}
+	for _, m := range members {
+		if _, ok := course.RoleOf(m); ok {
+			removed = append(removed, m)
+		}
+	}
+	if len(removed) > 0 {
+		// Removed members are informed as well, using the members from before the update.
+		cS.publish(eduboard.EventMemberRemoved, course.ID, removed, course.MemberIDs())
+	}
+	return nil, updated
+}
+
+// publish reports a membership change to recipients.
+func (cS CourseService) publish(t eduboard.EventType, courseID bson.ObjectId, userIDs []string, recipients []string) {
+	if cS.Events == nil {
+		return
+	}
+	cS.Events.Publish(eduboard.Event{
+		Type:       t,
+		CourseID:   courseID,
+		UserIDs:    userIDs,
+		Time:       time.Now(),
+		Recipients: recipients,
+	})
 }
 
-func (cS CourseService) RemoveMembers(course string, members []string) (error, eduboard.Course) {
-	return cS.CR.Update(course, bson.M{"$pull": bson.M{"members": bson.M{"$in": members}}})
+// notify creates a notification for each of userIDs.
+func (cS CourseService) notify(userIDs []string, notification eduboard.Notification) {
+	if cS.Notifications == nil {
+		return
+	}
+	cS.Notifications.CreateNotifications(userIDs, notification)
 }
 
-func (cS CourseService) CreateCourse(c *eduboard.Course) (*eduboard.Course, error) {
+func (cS CourseService) CreateCourse(c *eduboard.Course, ownerID string) (*eduboard.Course, error) {
+	if ownerID == "" {
+		return &eduboard.Course{}, errors.New("course needs an owner")
+	}
+	if c.Enrollment == "" {
+		c.Enrollment = eduboard.EnrollmentInviteOnly
+	}
+	if !c.Enrollment.IsValid() {
+		return &eduboard.Course{}, errors.Wrapf(eduboard.ErrInvalidInput, "unknown enrollment policy %s", c.Enrollment)
+	}
+
+	members := []eduboard.Member{{UserID: ownerID, Role: eduboard.RoleOwner}}
+	for _, m := range c.Members {
+		if _, ok := (eduboard.Course{Members: members}).RoleOf(m.UserID); ok {
+			continue
+		}
+		members = append(members, eduboard.Member{UserID: m.UserID, Role: eduboard.RoleStudent})
+	}
+	c.Members = members
+
 	err := cS.CR.Insert(c)
 	if err != nil {
 		return &eduboard.Course{}, errors.Wrap(err, "error storing course")
 	}
 	return c, nil
 }
+
+// UpdateCourse changes the details of a course. Only staff may update courses, archived courses can not be updated.
+// New schedules are checked and stored by sc.
+func (cS CourseService) UpdateCourse(id string, userID string, update eduboard.CourseUpdate, sc eduboard.ScheduleChecker) (error, eduboard.Course) {
+	err, course := access.Manager(cS.CR, id, userID)
+	if err != nil {
+		return err, eduboard.Course{}
+	}
+
+	set := bson.M{}
+	if update.Title != nil {
+		if strings.TrimSpace(*update.Title) == "" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "title must not be empty"), eduboard.Course{}
+		}
+		set["title"] = *update.Title
+	}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.Labels != nil {
+		set["labels"] = update.Labels
+	}
+	if update.Enrollment != nil {
+		if !update.Enrollment.IsValid() {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "unknown enrollment policy %s", *update.Enrollment), eduboard.Course{}
+		}
+		set["enrollment"] = *update.Enrollment
+	}
+	if update.Schedules == nil {
+		if len(set) == 0 {
+			return nil, course
+		}
+		return cS.update(id, bson.M{"$set": set})
+	}
+
+	// Schedules need an ID to be managed one by one later on.
+	schedules := make([]eduboard.Schedule, len(update.Schedules))
+	for k, v := range update.Schedules {
+		if v.ID == "" {
+			v.ID = bson.NewObjectId()
+		}
+		schedules[k] = v
+	}
+	set["schedules"] = schedules
+
+	var updated eduboard.Course
+	err = sc.CheckSchedules(course, schedules, func() error {
+		err, updated = cS.update(id, bson.M{"$set": set})
+		return err
+	})
+	if err != nil {
+		return err, eduboard.Course{}
+	}
+	cS.notify(updated.MemberIDs(), eduboard.Notification{
+		Type:        eduboard.NotificationScheduleChanged,
+		CourseID:    updated.ID,
+		CourseTitle: updated.Title,
+		ActorID:     userID,
+	})
+	return nil, updated
+}
+
+// ArchiveCourse makes a course read-only and hides it from course listings. Only the owner may archive a course.
+func (cS CourseService) ArchiveCourse(id string, userID string) (error, eduboard.Course) {
+	err, course := cS.archivable(id, userID)
+	if err != nil {
+		return err, eduboard.Course{}
+	}
+	if course.Archived {
+		return nil, course
+	}
+	return cS.update(id, bson.M{"$set": bson.M{"archived": true}})
+}
+
+// RestoreCourse reverts ArchiveCourse. Only the owner may restore a course. The rooms of its schedules may have been
+// booked by other courses in the meantime, so sc checks them again before it is restored.
+func (cS CourseService) RestoreCourse(id string, userID string, sc eduboard.ScheduleChecker) (error, eduboard.Course) {
+	err, course := cS.archivable(id, userID)
+	if err != nil {
+		return err, eduboard.Course{}
+	}
+	if !course.Archived {
+		return nil, course
+	}
+
+	var restored eduboard.Course
+	err = sc.CheckRestore(course, func() error {
+		err, restored = cS.update(id, bson.M{"$set": bson.M{"archived": false}})
+		return err
+	})
+	if err != nil {
+		return err, eduboard.Course{}
+	}
+	return nil, restored
+}
+
+// archivable returns the course if userID owns it.
+func (cS CourseService) archivable(id string, userID string) (error, eduboard.Course) {
+	err, course := cS.CR.FindOneByID(id)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", id), eduboard.Course{}
+	}
+
+	if role, _ := course.RoleOf(userID); role != eduboard.RoleOwner {
+		return errors.Wrapf(eduboard.ErrForbidden, "only the owner may archive or restore course %s", id), eduboard.Course{}
+	}
+	return nil, course
+}
+
+// DeleteCourse deletes a course along with its entries, comments and the data of all Deleters and removes it from
+// the courses of all users. Only the owner may delete a course.
+func (cS CourseService) DeleteCourse(id string, userID string, ced eduboard.CourseEntryDeleter, cd eduboard.CommentDeleter, ucr eduboard.UserCourseRemover) error {
+	err, course := cS.CR.FindOneByID(id)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", id)
+	}
+
+	if role, _ := course.RoleOf(userID); role != eduboard.RoleOwner {
+		return errors.Wrapf(eduboard.ErrForbidden, "only the owner may delete course %s", id)
+	}
+
+	// The course itself is deleted last, such that a failed deletion can be retried.
+	for _, d := range cS.Deleters {
+		if err = d.DeleteByCourse(id); err != nil {
+			return errors.Wrapf(err, "error deleting data of course %s", id)
+		}
+	}
+	if err = cd.DeleteByCourse(id); err != nil {
+		return errors.Wrapf(err, "error deleting comments of course %s", id)
+	}
+	if err = ced.DeleteByCourse(id); err != nil {
+		return errors.Wrapf(err, "error deleting courseEntries of course %s", id)
+	}
+	if err = ucr.RemoveCourse(id); err != nil {
+		return errors.Wrapf(err, "error removing course %s from users", id)
+	}
+	if err = cS.CR.Delete(id); err != nil {
+		return errors.Wrapf(err, "error deleting course %s", id)
+	}
+	return nil
+}
+
+// update applies change to the course and returns the updated course.
+func (cS CourseService) update(id string, change bson.M) (error, eduboard.Course) {
+	if err, _ := cS.CR.Update(id, change); err != nil {
+		return errors.Wrapf(err, "error updating course %s", id), eduboard.Course{}
+	}
+
+	err, course := cS.CR.FindOneByID(id)
+	if err != nil {
+		return errors.Wrapf(err, "error finding updated course %s", id), eduboard.Course{}
+	}
+	return nil, course
+}
@@ -1,22 +1,23 @@
 package courseService
 
 import (
-	"errors"
 	"github.com/eduboard/backend"
 	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/mgo.v2/bson"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
 	t.Parallel()
 	r := mock.CourseRepository{}
-	cs := New(&r)
+	cs := New(&r, nil, nil)
 	assert.Equal(t, &r, cs.CR, "repository does not match")
 }
 
-func TestCourseService_GetAllCourses(t *testing.T) {
+func TestCourseService_SearchCourses(t *testing.T) {
 	t.Parallel()
 	var mockRepo mock.CourseRepository
 
@@ -27,34 +28,45 @@ func TestCourseService_GetAllCourses(t *testing.T) {
 
 	var testCases = []struct {
 		name     string
-		input    []eduboard.Course
+		query    eduboard.CourseQuery
 		error    bool
-		expected []eduboard.Course
+		invoked  bool
+		expected eduboard.CourseQuery
 	}{
-		{"success", []eduboard.Course{course1, course2}, false, []eduboard.Course{course1, course2}},
-		{"empty", []eduboard.Course{}, false, []eduboard.Course{}},
-		{"error", []eduboard.Course{}, true, []eduboard.Course{}},
+		{"defaults", eduboard.CourseQuery{}, false, true, eduboard.CourseQuery{Sort: "title", Limit: eduboard.DefaultCourseLimit}},
+		{"text defaults to relevance", eduboard.CourseQuery{Text: "math"}, false, true, eduboard.CourseQuery{Text: "math", Sort: "relevance", Limit: eduboard.DefaultCourseLimit}},
+		{"limit capped", eduboard.CourseQuery{Limit: 1000, Offset: 40}, false, true, eduboard.CourseQuery{Sort: "title", Limit: eduboard.MaxCourseLimit, Offset: 40}},
+		{"descending", eduboard.CourseQuery{Sort: "-createdAt", Labels: []string{"a"}, Member: "1"}, false, true, eduboard.CourseQuery{Sort: "-createdAt", Labels: []string{"a"}, Member: "1", Limit: eduboard.DefaultCourseLimit}},
+		{"negative offset", eduboard.CourseQuery{Offset: -1}, true, false, eduboard.CourseQuery{}},
+		{"unknown sort", eduboard.CourseQuery{Sort: "members"}, true, false, eduboard.CourseQuery{}},
+		{"relevance without text", eduboard.CourseQuery{Sort: "relevance"}, true, false, eduboard.CourseQuery{}},
+		{"descending relevance", eduboard.CourseQuery{Text: "math", Sort: "-relevance"}, true, false, eduboard.CourseQuery{}},
+		{"error", eduboard.CourseQuery{Member: "failing"}, true, true, eduboard.CourseQuery{}},
 	}
 
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
-			mockRepo.FindManyFnInvoked = false
-			mockRepo.FindManyFn = func(query bson.M) (error, []eduboard.Course) {
-				var err error
-				if v.error {
-					err = errors.New("error")
+			var query eduboard.CourseQuery
+			mockRepo.SearchFnInvoked = false
+			mockRepo.SearchFn = func(q eduboard.CourseQuery) (error, []eduboard.Course, int) {
+				query = q
+				if q.Member == "failing" {
+					return errors.New("error"), []eduboard.Course{}, 0
 				}
-				return err, v.input
+				return nil, []eduboard.Course{course1, course2}, 42
 			}
 
-			err, courses := service.GetAllCourses()
-			if !v.error {
-				assert.Nil(t, err, "failed when it should not")
-			} else {
+			err, page := service.SearchCourses(v.query)
+			assert.Equal(t, v.invoked, mockRepo.SearchFnInvoked, "repository call was not invoked as expected")
+			if v.error {
 				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, eduboard.CoursePage{}, page, "page is not empty")
+				return
 			}
-			assert.True(t, mockRepo.FindManyFnInvoked, "repository call was not invoked")
-			assert.Equal(t, v.expected, courses, "courses do not equal expected values")
+
+			assert.Nil(t, err, "failed when it should not")
+			assert.Equal(t, v.expected, query, "query was not completed as expected")
+			assert.Equal(t, eduboard.CoursePage{Courses: []eduboard.Course{course1, course2}, Total: 42, Offset: v.expected.Offset, Limit: v.expected.Limit}, page, "page does not match")
 		})
 	}
 }
@@ -116,7 +128,7 @@ func TestCourseService_GetCourse(t *testing.T) {
 			mockCourseRepo.FindFnInvoked = false
 			mockEntryRepo.FindManyFnInvoked = false
 
-			err, course := service.GetCourse(v.input, &mockEntryRepo)
+			err, course := service.GetCourse(v.input, "", &mockEntryRepo)
 			assert.True(t, mockCourseRepo.FindFnInvoked, "courseRepository call was not invoked")
 			assert.Equal(t, v.invokeEntryRepo, mockEntryRepo.FindManyFnInvoked, "entryRepository call was not invoked as expected")
 			assert.Equal(t, v.expected, course, "courses do not equal expected values")
@@ -137,11 +149,14 @@ func TestCourseService_GetCoursesByMember(t *testing.T) {
 	var mockEntryRepo mock.CourseEntryRepository
 	service := CourseService{CR: &mockCourseRepo}
 
-	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []string{"1"}}
+	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}}}
 	courseWithEntries := eduboard.Course{ID: "2", Title: "Course 2", EntryIDs: []bson.ObjectId{"1", "2"}}
 	brokenCourseWithEntries := eduboard.Course{ID: "4", Title: "Course 4", EntryIDs: []bson.ObjectId{"1"}}
 	courseEntry1 := eduboard.CourseEntry{ID: "1", CourseID: "2", Message: "First Entry", Published: true}
 	courseEntry2 := eduboard.CourseEntry{ID: "2", CourseID: "2", Message: "Second Entry", Published: true}
+	draft := eduboard.CourseEntry{ID: "3", CourseID: "2", Message: "Draft"}
+	expectedCourse := courseWithEntries
+	expectedCourse.Entries = []eduboard.CourseEntry{courseEntry1, courseEntry2}
 
 	testCases := []struct {
 		name     string
@@ -151,12 +166,12 @@ func TestCourseService_GetCoursesByMember(t *testing.T) {
 	}{
 		{"success", "1", false, []eduboard.Course{course1}},
 		{"error", "", true, []eduboard.Course{}},
-		{"success with entries", "2", false, []eduboard.Course{courseWithEntries}},
+		{"success with entries", "2", false, []eduboard.Course{expectedCourse}},
 		{"broken on fetching entries", "4", true, []eduboard.Course{}},
 	}
 
 	mockCourseRepo.FindManyFn = func(update bson.M) (error, []eduboard.Course) {
-		switch update["members"] {
+		switch update["members.userID"] {
 		case "1":
 			return nil, []eduboard.Course{course1}
 		case "2":
@@ -171,7 +186,7 @@ func TestCourseService_GetCoursesByMember(t *testing.T) {
 		if string(query["courseID"].(bson.ObjectId)) == "4" {
 			return errors.New("error"), nil
 		}
-		return nil, []eduboard.CourseEntry{courseEntry1, courseEntry2}
+		return nil, []eduboard.CourseEntry{courseEntry1, courseEntry2, draft}
 	}
 
 	for _, v := range testCases {
@@ -201,8 +216,8 @@ func TestCourseService_GetMembers(t *testing.T) {
 
 	service := CourseService{CR: &mockCourseRepo}
 
-	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []string{"1"}}
-	course2 := eduboard.Course{ID: "2", Title: "Course 1", Members: []string{"1", "2"}}
+	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}}}
+	course2 := eduboard.Course{ID: "2", Title: "Course 1", Members: []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}, {UserID: "2", Role: eduboard.RoleStudent}}}
 
 	user1 := eduboard.User{ID: "1", Name: "User 1"}
 
@@ -257,38 +272,135 @@ func TestCourseService_GetMembers(t *testing.T) {
 func TestCourseService_AddMembers(t *testing.T) {
 	t.Parallel()
 
+	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []eduboard.Member{
+		{UserID: "1", Role: eduboard.RoleOwner},
+		{UserID: "2", Role: eduboard.RoleTeacher},
+		{UserID: "3", Role: eduboard.RoleStudent},
+	}}
+
+	testCases := []struct {
+		name         string
+		courseInput  string
+		userInput    string
+		membersInput []eduboard.Member
+		error        bool
+		cause        error
+		added        []string
+	}{
+		{"success", "1", "1", []eduboard.Member{{UserID: "4"}, {UserID: "5", Role: eduboard.RoleTeacher}}, false, nil, []string{"4", "5"}},
+		{"teacher adds student", "1", "2", []eduboard.Member{{UserID: "4"}, {UserID: "5"}}, false, nil, []string{"4", "5"}},
+		{"skips existing members", "1", "1", []eduboard.Member{{UserID: "3"}, {UserID: "4"}, {UserID: "4"}, {UserID: "5"}}, false, nil, []string{"4", "5"}},
+		{"added concurrently", "1", "1", []eduboard.Member{{UserID: "6"}}, false, nil, []string{}},
+		{"update fails", "1", "1", []eduboard.Member{{UserID: "broken"}}, true, nil, []string{}},
+		{"teacher adds teacher", "1", "2", []eduboard.Member{{UserID: "4", Role: eduboard.RoleTeacher}}, true, eduboard.ErrForbidden, []string{}},
+		{"student adds student", "1", "3", []eduboard.Member{{UserID: "4"}}, true, eduboard.ErrForbidden, []string{}},
+		{"owner role", "1", "1", []eduboard.Member{{UserID: "4", Role: eduboard.RoleOwner}}, true, eduboard.ErrInvalidInput, []string{}},
+		{"course not found", "", "1", []eduboard.Member{{UserID: "4"}}, true, nil, []string{}},
+		{"archived", "2", "1", []eduboard.Member{{UserID: "4"}}, true, eduboard.ErrArchived, []string{}},
+	}
+
+	archived := course1
+	archived.Archived = true
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			var mockCourseRepo mock.CourseRepository
+			mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+				switch id {
+				case "1":
+					return nil, course1
+				case "2":
+					return nil, archived
+				}
+				return errors.New("not found"), eduboard.Course{}
+			}
+			updated := course1
+			added := []string{}
+			mockCourseRepo.AddMemberFn = func(id string, member eduboard.Member) (error, eduboard.Course, bool) {
+				if member.UserID == "broken" {
+					return errors.New("error updating course"), eduboard.Course{}, false
+				}
+				// User 6 joins before being added.
+				if _, ok := updated.RoleOf(member.UserID); ok || member.UserID == "6" {
+					return nil, updated, false
+				}
+				updated.Members = append(append([]eduboard.Member{}, updated.Members...), member)
+				added = append(added, member.UserID)
+				return nil, updated, true
+			}
+			service := CourseService{CR: &mockCourseRepo}
+
+			err, course := service.AddMembers(v.courseInput, v.userInput, v.membersInput)
+			assert.Equal(t, v.added, added, "added members do not match")
+			if v.error {
+				assert.Error(t, err, "did not return error when expected")
+				if v.cause != nil {
+					assert.Equal(t, v.cause, errors.Cause(err), "error does not match")
+				}
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, updated, course, "course does not match")
+		})
+	}
+}
+
+func TestCoursesService_RemoveMembers(t *testing.T) {
+	t.Parallel()
+
 	var mockCourseRepo mock.CourseRepository
 	service := CourseService{CR: &mockCourseRepo}
 
-	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []string{"1"}}
+	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []eduboard.Member{
+		{UserID: "1", Role: eduboard.RoleOwner},
+		{UserID: "2", Role: eduboard.RoleTeacher},
+		{UserID: "3", Role: eduboard.RoleStudent},
+		{UserID: "4", Role: eduboard.RoleStudent},
+	}}
 
 	testCases := []struct {
 		name         string
 		courseInput  string
+		userInput    string
 		membersInput []string
 		error        bool
+		invokeUpdate bool
 		expected     eduboard.Course
 	}{
-		{"success", "1", []string{"1", "2"}, false, course1},
-		{"error", "", []string{""}, true, eduboard.Course{}},
+		{"success", "1", "1", []string{"2", "3"}, false, true, course1},
+		{"teacher removes students", "1", "2", []string{"3", "4"}, false, true, course1},
+		{"student leaves", "1", "3", []string{"3"}, false, true, course1},
+		{"student removes student", "1", "3", []string{"4"}, true, false, eduboard.Course{}},
+		{"teacher removes teacher", "1", "2", []string{"2", "3"}, false, true, course1},
+		{"remove owner", "1", "2", []string{"1"}, true, false, eduboard.Course{}},
+		{"course not found", "", "1", []string{"3"}, true, false, eduboard.Course{}},
+		{"archived", "2", "3", []string{"3"}, true, false, eduboard.Course{}},
 	}
 
+	archived := course1
+	archived.Archived = true
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		switch id {
+		case "1":
+			return nil, course1
+		case "2":
+			return nil, archived
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
 	mockCourseRepo.UpdateFn = func(course string, query bson.M) (error, eduboard.Course) {
-		switch len(query["$push"].(bson.M)["members"].(bson.M)["$each"].([]string)) {
-		case 2:
+		if _, ok := query["$pull"].(bson.M)["members"].(bson.M)["userID"].(bson.M)["$in"].([]string); ok {
 			return nil, course1
-		default:
-
-			return errors.New("Error fetching courses"), eduboard.Course{}
 		}
+		return errors.New("Error fetching courses"), eduboard.Course{}
 	}
 
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
 			mockCourseRepo.UpdateFnInvoked = false
 
-			err, courses := service.AddMembers(v.courseInput, v.membersInput)
-			assert.True(t, mockCourseRepo.UpdateFnInvoked, "courseRepository call was not invoked")
+			err, courses := service.RemoveMembers(v.courseInput, v.userInput, v.membersInput)
+			assert.Equal(t, v.invokeUpdate, mockCourseRepo.UpdateFnInvoked, "courseRepository call was not invoked as expected")
 
 			assert.Equal(t, v.expected, courses, "courses do not equal expected values")
 
@@ -301,48 +413,425 @@ func TestCourseService_AddMembers(t *testing.T) {
 	}
 }
 
-func TestCoursesService_RemoveMembers(t *testing.T) {
+func TestCourseService_CreateCourse(t *testing.T) {
+	t.Parallel()
+
+	var mockCourseRepo mock.CourseRepository
+	service := CourseService{CR: &mockCourseRepo}
+
+	testCases := []struct {
+		name       string
+		owner      string
+		members    []eduboard.Member
+		enrollment eduboard.EnrollmentPolicy
+		error      bool
+		expected   []eduboard.Member
+	}{
+		{"success", "1", nil, "", false, []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}}},
+		{"with members", "1", []eduboard.Member{{UserID: "1"}, {UserID: "2", Role: eduboard.RoleOwner}, {UserID: "2"}},
+			"", false, []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}, {UserID: "2", Role: eduboard.RoleStudent}}},
+		{"open", "1", nil, eduboard.EnrollmentOpen, false, []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}}},
+		{"unknown enrollment", "1", nil, "closed", true, nil},
+		{"no owner", "", nil, "", true, nil},
+	}
+
+	mockCourseRepo.StoreFn = func(course *eduboard.Course) error {
+		return nil
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			course, err := service.CreateCourse(&eduboard.Course{Title: "Course", Members: v.members, Enrollment: v.enrollment}, v.owner)
+			if v.error {
+				assert.Error(t, err, "did not return error when expected")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.expected, course.Members, "members do not equal expected values")
+			if v.enrollment == "" {
+				assert.Equal(t, eduboard.EnrollmentInviteOnly, course.Enrollment, "courses are not invite-only by default")
+			}
+		})
+	}
+}
+
+func TestCourseService_UpdateCourse(t *testing.T) {
 	t.Parallel()
 
 	var mockCourseRepo mock.CourseRepository
 	service := CourseService{CR: &mockCourseRepo}
+	checker := mock.ScheduleService{CheckSchedulesFn: func(course eduboard.Course, schedules []eduboard.Schedule, store func() error) error {
+		for _, s := range schedules {
+			if s.Room == "taken" {
+				return &eduboard.RoomConflictError{Conflicts: []eduboard.RoomConflict{{Room: s.Room}}}
+			}
+		}
+		return store()
+	}}
 
-	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []string{"1"}}
+	members := []eduboard.Member{
+		{UserID: "1", Role: eduboard.RoleOwner},
+		{UserID: "2", Role: eduboard.RoleTeacher},
+		{UserID: "3", Role: eduboard.RoleStudent},
+	}
+	course := eduboard.Course{ID: "1", Title: "Course 1", Members: members}
+	archived := eduboard.Course{ID: "2", Title: "Course 2", Members: members, Archived: true}
+
+	title := "Updated"
+	empty := " "
+	schedules := []eduboard.Schedule{{ID: "1", Day: time.Monday, Room: "EN 154"}}
+	approval := eduboard.EnrollmentApproval
+	unknown := eduboard.EnrollmentPolicy("closed")
 
 	testCases := []struct {
 		name         string
-		courseInput  string
-		membersInput []string
-		error        bool
-		expected     eduboard.Course
+		course       string
+		user         string
+		update       eduboard.CourseUpdate
+		err          error
+		invokeUpdate bool
+		expected     bson.M
 	}{
-		{"success", "1", []string{"1", "2"}, false, course1},
-		{"error", "", []string{""}, true, eduboard.Course{}},
+		{"success", "1", "1", eduboard.CourseUpdate{Title: &title, Labels: []string{}, Schedules: schedules}, nil, true,
+			bson.M{"$set": bson.M{"title": title, "labels": []string{}, "schedules": schedules}}},
+		{"teacher", "1", "2", eduboard.CourseUpdate{Description: &title}, nil, true, bson.M{"$set": bson.M{"description": title}}},
+		{"enrollment", "1", "1", eduboard.CourseUpdate{Enrollment: &approval}, nil, true, bson.M{"$set": bson.M{"enrollment": approval}}},
+		{"unknown enrollment", "1", "1", eduboard.CourseUpdate{Enrollment: &unknown}, eduboard.ErrInvalidInput, false, nil},
+		{"empty update", "1", "1", eduboard.CourseUpdate{}, nil, false, nil},
+		{"empty title", "1", "1", eduboard.CourseUpdate{Title: &empty}, eduboard.ErrInvalidInput, false, nil},
+		{"student", "1", "3", eduboard.CourseUpdate{Title: &title}, eduboard.ErrForbidden, false, nil},
+		{"archived", "2", "1", eduboard.CourseUpdate{Title: &title}, eduboard.ErrArchived, false, nil},
+		{"room conflict", "1", "1", eduboard.CourseUpdate{Schedules: []eduboard.Schedule{{Room: "taken"}}}, nil, false, nil},
 	}
 
-	mockCourseRepo.UpdateFn = func(course string, query bson.M) (error, eduboard.Course) {
-		switch len(query["$pull"].(bson.M)["members"].(bson.M)["$in"].([]string)) {
-		case 2:
-			return nil, course1
-		default:
-			return errors.New("Error fetching courses"), eduboard.Course{}
+	var change bson.M
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		if id == "2" {
+			return nil, archived
+		}
+		return nil, course
+	}
+	mockCourseRepo.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) {
+		change = update
+		return nil, eduboard.Course{}
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			change = nil
+			mockCourseRepo.UpdateFnInvoked = false
+
+			err, c := service.UpdateCourse(v.course, v.user, v.update, &checker)
+			assert.Equal(t, v.invokeUpdate, mockCourseRepo.UpdateFnInvoked, "courseRepository call was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			if _, ok := err.(*eduboard.RoomConflictError); ok {
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.expected, change, "update does not match")
+			assert.Equal(t, course, c, "course does not match")
+		})
+	}
+
+	t.Run("schedule ids", func(t *testing.T) {
+		err, _ := service.UpdateCourse("1", "1", eduboard.CourseUpdate{Schedules: []eduboard.Schedule{{Day: time.Monday}}}, &checker)
+		assert.Nil(t, err, "returned error when it shouldn't")
+		stored := change["$set"].(bson.M)["schedules"].([]eduboard.Schedule)
+		assert.True(t, stored[0].ID.Valid(), "schedule did not get an ID")
+	})
+}
+
+func TestCourseService_ArchiveCourse(t *testing.T) {
+	t.Parallel()
+
+	var mockCourseRepo mock.CourseRepository
+	service := CourseService{CR: &mockCourseRepo}
+
+	members := []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}, {UserID: "2", Role: eduboard.RoleTeacher}}
+	courses := map[string]eduboard.Course{
+		"1": {ID: "1", Members: members},
+		"2": {ID: "2", Members: members, Archived: true},
+		"3": {ID: "3", Members: members, Archived: true},
+	}
+
+	// The rooms of course 3 were booked by another course while it was archived.
+	conflict := &eduboard.RoomConflictError{Conflicts: []eduboard.RoomConflict{{Room: "EN 154", CourseID: "4"}}}
+	checker := &mock.ScheduleService{}
+	checker.CheckRestoreFn = func(course eduboard.Course, store func() error) error {
+		if course.ID == "3" {
+			return conflict
 		}
+		return store()
+	}
+
+	testCases := []struct {
+		name         string
+		course       string
+		user         string
+		archive      bool
+		err          error
+		invokeUpdate bool
+	}{
+		{"archive", "1", "1", true, nil, true},
+		{"archive archived", "2", "1", true, nil, false},
+		{"restore", "2", "1", false, nil, true},
+		{"restore active", "1", "1", false, nil, false},
+		{"restore into booked room", "3", "1", false, conflict, false},
+		{"teacher archives", "1", "2", true, eduboard.ErrForbidden, false},
+		{"teacher restores", "2", "2", false, eduboard.ErrForbidden, false},
+	}
+
+	var change bson.M
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+		return nil, courses[id]
+	}
+	mockCourseRepo.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) {
+		change = update
+		return nil, eduboard.Course{}
 	}
 
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
+			change = nil
 			mockCourseRepo.UpdateFnInvoked = false
 
-			err, courses := service.RemoveMembers(v.courseInput, v.membersInput)
-			assert.True(t, mockCourseRepo.UpdateFnInvoked, "courseRepository call was not invoked")
+			var err error
+			if v.archive {
+				err, _ = service.ArchiveCourse(v.course, v.user)
+			} else {
+				err, _ = service.RestoreCourse(v.course, v.user, checker)
+			}
+			assert.Equal(t, v.invokeUpdate, mockCourseRepo.UpdateFnInvoked, "courseRepository call was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			if v.invokeUpdate {
+				assert.Equal(t, bson.M{"$set": bson.M{"archived": v.archive}}, change, "update does not match")
+			}
+		})
+	}
+}
 
-			assert.Equal(t, v.expected, courses, "courses do not equal expected values")
+func TestCourseService_DeleteCourse(t *testing.T) {
+	t.Parallel()
+
+	members := []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}, {UserID: "2", Role: eduboard.RoleTeacher}}
 
+	testCases := []struct {
+		name           string
+		course         string
+		user           string
+		entriesError   bool
+		dataError      bool
+		error          bool
+		invokeEntries  bool
+		invokeComments bool
+		invokeUsers    bool
+		invokeDelete   bool
+	}{
+		{"success", "5b23bbdc2bfa844c41a9f134", "1", false, false, false, true, true, true, true},
+		{"teacher", "5b23bbdc2bfa844c41a9f134", "2", false, false, true, false, false, false, false},
+		{"not found", "", "1", false, false, true, false, false, false, false},
+		{"entries error", "5b23bbdc2bfa844c41a9f134", "1", true, false, true, true, true, false, false},
+		{"data error", "5b23bbdc2bfa844c41a9f134", "1", false, true, true, false, false, false, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mockCourseRepo := mock.CourseRepository{}
+			mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+				if id == "" {
+					return errors.New("not found"), eduboard.Course{}
+				}
+				return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members}
+			}
+			mockCourseRepo.DeleteFn = func(id string) error { return nil }
+			mockEntryRepo := mock.CourseEntryRepository{}
+			mockEntryRepo.DeleteByCourseFn = func(courseID string) error {
+				if v.entriesError {
+					return errors.New("error")
+				}
+				return nil
+			}
+			mockCommentRepo := mock.CommentRepository{}
+			mockCommentRepo.DeleteByCourseFn = func(courseID string) error { return nil }
+			mockUserRepo := mock.UserRepository{}
+			mockUserRepo.RemoveCourseFn = func(courseID string) error { return nil }
+			deleted := []string{}
+			deleter := func(name string, fail bool) *mock.CourseDataDeleter {
+				return &mock.CourseDataDeleter{DeleteByCourseFn: func(courseID string) error {
+					if fail {
+						return errors.New("error")
+					}
+					deleted = append(deleted, name)
+					return nil
+				}}
+			}
+			service := New(&mockCourseRepo, nil, nil, deleter("first", false), deleter("second", v.dataError), deleter("third", false))
+
+			err := service.DeleteCourse(v.course, v.user, &mockEntryRepo, &mockCommentRepo, &mockUserRepo)
+			assert.Equal(t, v.invokeComments, mockCommentRepo.DeleteByCourseFnInvoked, "comments DeleteByCourse was not invoked as expected")
+			assert.Equal(t, v.invokeEntries, mockEntryRepo.DeleteByCourseFnInvoked, "DeleteByCourse was not invoked as expected")
+			assert.Equal(t, v.invokeUsers, mockUserRepo.RemoveCourseFnInvoked, "RemoveCourse was not invoked as expected")
+			assert.Equal(t, v.invokeDelete, mockCourseRepo.DeleteFnInvoked, "Delete was not invoked as expected")
+			if v.dataError {
+				assert.Equal(t, []string{"first"}, deleted, "deleters were not invoked as expected")
+			}
 			if v.error {
 				assert.Error(t, err, "did not return error when expected")
 				return
 			}
 			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, []string{"first", "second", "third"}, deleted, "deleters were not invoked in order")
+		})
+	}
+}
+
+func TestCourseService_MemberEvents(t *testing.T) {
+	t.Parallel()
+
+	course := eduboard.Course{ID: "1", Members: []eduboard.Member{
+		{UserID: "1", Role: eduboard.RoleOwner},
+		{UserID: "2", Role: eduboard.RoleStudent},
+	}}
+	added := course
+	added.Members = append(added.Members, eduboard.Member{UserID: "3", Role: eduboard.RoleStudent})
+	removed := course
+	removed.Members = course.Members[:1]
+
+	var mockCourseRepo mock.CourseRepository
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) { return nil, course }
+
+	events := []eduboard.Event{}
+	publisher := mock.EventPublisher{}
+	publisher.PublishFn = func(event eduboard.Event) {
+		events = append(events, event)
+	}
+	notified := [][]string{}
+	notifications := mock.NotificationService{}
+	notifications.CreateNotificationsFn = func(userIDs []string, notification eduboard.Notification) {
+		assert.Equal(t, eduboard.NotificationMemberAdded, notification.Type, "notification type does not match")
+		assert.Equal(t, "1", notification.ActorID, "actor does not match")
+		notified = append(notified, userIDs)
+	}
+	service := New(&mockCourseRepo, &publisher, &notifications)
+
+	mockCourseRepo.AddMemberFn = func(id string, member eduboard.Member) (error, eduboard.Course, bool) { return nil, added, true }
+	err, _ := service.AddMembers("1", "1", []eduboard.Member{{UserID: "2"}, {UserID: "3"}})
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	mockCourseRepo.UpdateFn = func(id string, query bson.M) (error, eduboard.Course) { return nil, removed }
+	err, _ = service.RemoveMembers("1", "2", []string{"2"})
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	publisher.PublishFnInvoked = false
+	err, _ = service.AddMembers("1", "1", []eduboard.Member{{UserID: "2"}})
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.False(t, publisher.PublishFnInvoked, "event published without new members")
+
+	if !assert.Len(t, events, 2, "unexpected number of events") {
+		return
+	}
+	assert.Equal(t, eduboard.EventMemberAdded, events[0].Type, "event type does not match")
+	assert.Equal(t, []string{"3"}, events[0].UserIDs, "added members do not match")
+	assert.Equal(t, []string{"1", "2", "3"}, events[0].Recipients, "recipients do not match")
+	assert.Equal(t, eduboard.EventMemberRemoved, events[1].Type, "event type does not match")
+	assert.Equal(t, []string{"2"}, events[1].UserIDs, "removed members do not match")
+	assert.Equal(t, []string{"1", "2"}, events[1].Recipients, "recipients do not match")
+	assert.Equal(t, [][]string{{"3"}}, notified, "notified members do not match")
+}
+
+func TestCourseService_UpdateCourse_Notifications(t *testing.T) {
+	t.Parallel()
+
+	course := eduboard.Course{ID: "1", Title: "Course 1", Members: []eduboard.Member{
+		{UserID: "1", Role: eduboard.RoleOwner},
+		{UserID: "2", Role: eduboard.RoleStudent},
+	}}
+	var mockCourseRepo mock.CourseRepository
+	mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) { return nil, course }
+	mockCourseRepo.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) { return nil, course }
+	checker := mock.ScheduleService{CheckSchedulesFn: func(course eduboard.Course, schedules []eduboard.Schedule, store func() error) error { return store() }}
+
+	var notification eduboard.Notification
+	notifications := mock.NotificationService{}
+	notifications.CreateNotificationsFn = func(userIDs []string, n eduboard.Notification) {
+		assert.Equal(t, []string{"1", "2"}, userIDs, "recipients do not match")
+		notification = n
+	}
+	service := New(&mockCourseRepo, nil, &notifications)
+
+	title := "Updated"
+	err, _ := service.UpdateCourse("1", "1", eduboard.CourseUpdate{Title: &title}, &checker)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.False(t, notifications.CreateNotificationsFnInvoked, "notified about a change without schedules")
+
+	err, _ = service.UpdateCourse("1", "1", eduboard.CourseUpdate{Schedules: []eduboard.Schedule{}}, &checker)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.True(t, notifications.CreateNotificationsFnInvoked, "not notified about schedule change")
+	assert.Equal(t, eduboard.NotificationScheduleChanged, notification.Type, "notification type does not match")
+	assert.Equal(t, "1", notification.ActorID, "actor does not match")
+}
+
+func TestCourseService_JoinCourse(t *testing.T) {
+	t.Parallel()
+
+	members := []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}, {UserID: "2", Role: eduboard.RoleStudent}}
+
+	testCases := []struct {
+		name      string
+		course    string
+		user      string
+		err       error
+		notFound  bool
+		invokeAdd bool
+		added     bool
+	}{
+		{"success", "1", "3", nil, false, true, true},
+		{"already member", "1", "2", nil, false, false, false},
+		{"joined concurrently", "1", "4", nil, false, true, false},
+		{"archived", "2", "3", eduboard.ErrArchived, false, false, false},
+		{"not found", "", "3", nil, true, false, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			var mockCourseRepo mock.CourseRepository
+			mockCourseRepo.FindFn = func(id string) (error, eduboard.Course) {
+				switch id {
+				case "1":
+					return nil, eduboard.Course{ID: "1", Members: members}
+				case "2":
+					return nil, eduboard.Course{ID: "2", Members: members, Archived: true}
+				}
+				return errors.New("not found"), eduboard.Course{}
+			}
+			mockCourseRepo.AddMemberFn = func(id string, member eduboard.Member) (error, eduboard.Course, bool) {
+				assert.Equal(t, eduboard.Member{UserID: v.user, Role: eduboard.RoleStudent}, member, "member does not match")
+				return nil, eduboard.Course{ID: "1", Members: append(members, member)}, member.UserID != "4"
+			}
+			publisher := mock.EventPublisher{PublishFn: func(event eduboard.Event) {
+				assert.Equal(t, eduboard.EventMemberAdded, event.Type, "event type does not match")
+				assert.Equal(t, []string{v.user}, event.UserIDs, "added members do not match")
+			}}
+			service := New(&mockCourseRepo, &publisher, nil)
+
+			err, course := service.JoinCourse(v.course, v.user)
+			assert.Equal(t, v.invokeAdd, mockCourseRepo.AddMemberFnInvoked, "AddMember was not invoked as expected")
+			assert.Equal(t, v.added, publisher.PublishFnInvoked, "event was not published as expected")
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			_, ok := course.RoleOf(v.user)
+			assert.True(t, ok, "user is not a member")
 		})
 	}
 }
@@ -0,0 +1,202 @@
+package enrollmentService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type EnrollmentService struct {
+	r  eduboard.EnrollmentRepository
+	cf eduboard.CourseOneFinder
+	// notifications is informed about new and rejected requests. It may be nil.
+	notifications eduboard.NotificationCreator
+}
+
+func New(repository eduboard.EnrollmentRepository, courseFinder eduboard.CourseOneFinder, notifications eduboard.NotificationCreator) *EnrollmentService {
+	return &EnrollmentService{
+		r:             repository,
+		cf:            courseFinder,
+		notifications: notifications,
+	}
+}
+
+// RequestEnrollment asks to join a course as a student. Requesting again while a request is pending returns it,
+// requesting again after a decision replaces the old request.
+func (eS *EnrollmentService) RequestEnrollment(courseID string, userID string, message string, cj eduboard.CourseJoiner) (error, eduboard.EnrollmentRequest) {
+	message = strings.TrimSpace(message)
+	if utf8.RuneCountInString(message) > eduboard.MaxEnrollmentMessageLength {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "message is longer than %d characters", eduboard.MaxEnrollmentMessageLength), eduboard.EnrollmentRequest{}
+	}
+
+	err, course := eS.cf.FindOneByID(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", courseID), eduboard.EnrollmentRequest{}
+	}
+	if _, ok := course.RoleOf(userID); ok {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "user %s is already a member of course %s", userID, courseID), eduboard.EnrollmentRequest{}
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not enroll in course %s", courseID), eduboard.EnrollmentRequest{}
+	}
+
+	now := time.Now()
+	request := eduboard.EnrollmentRequest{
+		CourseID:  course.ID,
+		UserID:    userID,
+		Status:    eduboard.EnrollmentPending,
+		Message:   message,
+		CreatedAt: now,
+	}
+
+	switch course.EnrollmentPolicy() {
+	case eduboard.EnrollmentOpen:
+		if err, _ = cj.JoinCourse(courseID, userID); err != nil {
+			return errors.Wrapf(err, "error enrolling user %s in course %s", userID, courseID), eduboard.EnrollmentRequest{}
+		}
+		request.Status = eduboard.EnrollmentApproved
+		request.DecidedAt = &now
+		return nil, request
+	case eduboard.EnrollmentInviteOnly:
+		return errors.Wrapf(eduboard.ErrForbidden, "course %s can only be joined on invitation", courseID), eduboard.EnrollmentRequest{}
+	}
+
+	if err, existing := eS.r.Find(courseID, userID); err == nil {
+		if existing.Status == eduboard.EnrollmentPending {
+			return nil, existing
+		}
+		if err = eS.r.Delete(existing.ID.Hex()); err != nil {
+			return errors.Wrapf(err, "error replacing enrollment request %s", existing.ID.Hex()), eduboard.EnrollmentRequest{}
+		}
+	}
+
+	if err = eS.r.Insert(&request); err != nil {
+		return errors.Wrapf(err, "error storing enrollment request for course %s", courseID), eduboard.EnrollmentRequest{}
+	}
+
+	eS.notify(course.StaffIDs(), eduboard.Notification{
+		Type:        eduboard.NotificationEnrollmentRequested,
+		CourseID:    course.ID,
+		CourseTitle: course.Title,
+		ActorID:     userID,
+	})
+	return nil, request
+}
+
+// GetEnrollment returns the request of userID to join a course.
+func (eS *EnrollmentService) GetEnrollment(courseID string, userID string) (error, eduboard.EnrollmentRequest) {
+	err, request := eS.r.Find(courseID, userID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding enrollment request of user %s for course %s", userID, courseID), eduboard.EnrollmentRequest{}
+	}
+	return nil, request
+}
+
+// CancelEnrollment withdraws the pending request of userID. Decided requests can not be cancelled.
+func (eS *EnrollmentService) CancelEnrollment(courseID string, userID string) error {
+	if err := eS.r.DeletePending(courseID, userID); err != nil {
+		return errors.Wrapf(err, "error cancelling enrollment request of user %s for course %s", userID, courseID)
+	}
+	return nil
+}
+
+// GetPendingEnrollments returns the pending requests of a course, oldest first. Only the staff may see them.
+func (eS *EnrollmentService) GetPendingEnrollments(courseID string, userID string) (error, []eduboard.EnrollmentRequest) {
+	if err, _ := access.Staff(eS.cf, courseID, userID); err != nil {
+		return err, []eduboard.EnrollmentRequest{}
+	}
+
+	err, requests := eS.r.FindByCourse(courseID, eduboard.EnrollmentPending)
+	if err != nil {
+		return errors.Wrapf(err, "error finding enrollment requests of course %s", courseID), []eduboard.EnrollmentRequest{}
+	}
+	return nil, requests
+}
+
+// ApproveEnrollment adds the requesting student to the course. Permissions are checked by cj.AddMembers.
+func (eS *EnrollmentService) ApproveEnrollment(courseID string, requestID string, userID string, cj eduboard.CourseJoiner) (error, eduboard.EnrollmentRequest) {
+	err, request := eS.pending(courseID, requestID)
+	if err != nil {
+		return err, eduboard.EnrollmentRequest{}
+	}
+
+	// The student is added before the request is decided. AddMembers skips existing members, so if deciding
+	// fails the request stays pending and approving it again completes it.
+	member := eduboard.Member{UserID: request.UserID, Role: eduboard.RoleStudent}
+	if err, _ = cj.AddMembers(courseID, userID, []eduboard.Member{member}); err != nil {
+		return errors.Wrapf(err, "error approving enrollment request %s", requestID), eduboard.EnrollmentRequest{}
+	}
+
+	return eS.decide(request, eduboard.EnrollmentApproved, userID)
+}
+
+// RejectEnrollment rejects a pending request. Only the staff may reject requests.
+func (eS *EnrollmentService) RejectEnrollment(courseID string, requestID string, userID string) (error, eduboard.EnrollmentRequest) {
+	err, course := access.Staff(eS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.EnrollmentRequest{}
+	}
+
+	err, request := eS.pending(courseID, requestID)
+	if err != nil {
+		return err, eduboard.EnrollmentRequest{}
+	}
+
+	err, request = eS.decide(request, eduboard.EnrollmentRejected, userID)
+	if err != nil {
+		return err, eduboard.EnrollmentRequest{}
+	}
+
+	eS.notify([]string{request.UserID}, eduboard.Notification{
+		Type:        eduboard.NotificationEnrollmentRejected,
+		CourseID:    course.ID,
+		CourseTitle: course.Title,
+		ActorID:     userID,
+	})
+	return nil, request
+}
+
+// DeleteByCourse deletes all enrollment requests of a course.
+func (eS *EnrollmentService) DeleteByCourse(courseID string) error {
+	if err := eS.r.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting enrollment requests of course %s", courseID)
+	}
+	return nil
+}
+
+// pending returns a pending request of a course.
+func (eS *EnrollmentService) pending(courseID string, requestID string) (error, eduboard.EnrollmentRequest) {
+	err, request := eS.r.FindOneByID(requestID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding enrollment request %s", requestID), eduboard.EnrollmentRequest{}
+	}
+	if request.CourseID.Hex() != courseID {
+		return errors.Wrapf(eduboard.ErrNotFound, "enrollment request %s does not belong to course %s", requestID, courseID), eduboard.EnrollmentRequest{}
+	}
+	if request.Status != eduboard.EnrollmentPending {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "enrollment request %s is already %s", requestID, request.Status), eduboard.EnrollmentRequest{}
+	}
+	return nil, request
+}
+
+func (eS *EnrollmentService) decide(request eduboard.EnrollmentRequest, status eduboard.EnrollmentStatus, userID string) (error, eduboard.EnrollmentRequest) {
+	now := time.Now()
+	if err := eS.r.Decide(request.ID.Hex(), status, userID, now); err != nil {
+		return errors.Wrapf(err, "error deciding enrollment request %s", request.ID.Hex()), eduboard.EnrollmentRequest{}
+	}
+
+	request.Status = status
+	request.DecidedBy = userID
+	request.DecidedAt = &now
+	return nil, request
+}
+
+func (eS *EnrollmentService) notify(userIDs []string, notification eduboard.Notification) {
+	if eS.notifications == nil {
+		return
+	}
+	eS.notifications.CreateNotifications(userIDs, notification)
+}
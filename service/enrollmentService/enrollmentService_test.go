@@ -0,0 +1,304 @@
+package enrollmentService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	openID       = "5b23bbdc2bfa844c41a9f134"
+	approvalID   = "5b23bbdc2bfa844c41a9f135"
+	inviteOnlyID = "5b23bbdc2bfa844c41a9f136"
+	archivedID   = "5b23bbdc2bfa844c41a9f137"
+	legacyID     = "5b23bbdc2bfa844c41a9f138"
+	pendingID    = "5b23bbdc2bfa844c41a9f140"
+	rejectedID   = "5b23bbdc2bfa844c41a9f141"
+)
+
+var members = []eduboard.Member{
+	{UserID: "owner", Role: eduboard.RoleOwner},
+	{UserID: "teacher", Role: eduboard.RoleTeacher},
+	{UserID: "student", Role: eduboard.RoleStudent},
+}
+
+// newFinder returns a finder for one course of each enrollment policy, an archived course and a course without policy.
+func newFinder() *mock.CourseRepository {
+	courses := map[string]eduboard.Course{
+		openID:       {Enrollment: eduboard.EnrollmentOpen},
+		approvalID:   {Enrollment: eduboard.EnrollmentApproval},
+		inviteOnlyID: {Enrollment: eduboard.EnrollmentInviteOnly},
+		archivedID:   {Enrollment: eduboard.EnrollmentApproval, Archived: true},
+		legacyID:     {},
+	}
+
+	cr := &mock.CourseRepository{}
+	cr.FindFn = func(id string) (error, eduboard.Course) {
+		if c, ok := courses[id]; ok {
+			c.ID = bson.ObjectIdHex(id)
+			c.Title = "Algorithms"
+			c.Members = members
+			return nil, c
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
+	return cr
+}
+
+// newRepository returns a repository holding a pending request of "pending" and a rejected one of "rejected",
+// both for the course requiring approval.
+func newRepository() *mock.EnrollmentRepository {
+	requests := map[string]eduboard.EnrollmentRequest{
+		pendingID:  {ID: bson.ObjectIdHex(pendingID), CourseID: bson.ObjectIdHex(approvalID), UserID: "pending", Status: eduboard.EnrollmentPending},
+		rejectedID: {ID: bson.ObjectIdHex(rejectedID), CourseID: bson.ObjectIdHex(approvalID), UserID: "rejected", Status: eduboard.EnrollmentRejected},
+	}
+
+	r := &mock.EnrollmentRepository{}
+	r.InsertFn = func(request *eduboard.EnrollmentRequest) error { return nil }
+	r.FindOneByIDFn = func(id string) (error, eduboard.EnrollmentRequest) {
+		if request, ok := requests[id]; ok {
+			return nil, request
+		}
+		return errors.New("not found"), eduboard.EnrollmentRequest{}
+	}
+	r.FindFn = func(courseID string, userID string) (error, eduboard.EnrollmentRequest) {
+		for _, request := range requests {
+			if request.CourseID.Hex() == courseID && request.UserID == userID {
+				return nil, request
+			}
+		}
+		return errors.New("not found"), eduboard.EnrollmentRequest{}
+	}
+	r.DecideFn = func(id string, status eduboard.EnrollmentStatus, userID string, now time.Time) error { return nil }
+	r.DeleteFn = func(id string) error { return nil }
+	return r
+}
+
+func TestNew(t *testing.T) {
+	r := newRepository()
+	cf := newFinder()
+	s := New(r, cf, nil)
+	assert.Equal(t, r, s.r, "repository does not match")
+	assert.Equal(t, cf, s.cf, "course finder does not match")
+}
+
+func TestEnrollmentService_RequestEnrollment(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		course   string
+		user     string
+		message  string
+		err      error
+		notFound bool
+		status   eduboard.EnrollmentStatus
+		joined   bool
+		stored   bool
+	}{
+		{"open", openID, "new", "", nil, false, eduboard.EnrollmentApproved, true, false},
+		{"approval", approvalID, "new", " Please ", nil, false, eduboard.EnrollmentPending, false, true},
+		{"already pending", approvalID, "pending", "", nil, false, eduboard.EnrollmentPending, false, false},
+		{"again after rejection", approvalID, "rejected", "", nil, false, eduboard.EnrollmentPending, false, true},
+		{"invite-only", inviteOnlyID, "new", "", eduboard.ErrForbidden, false, "", false, false},
+		{"without policy", legacyID, "new", "", eduboard.ErrForbidden, false, "", false, false},
+		{"already member", approvalID, "student", "", eduboard.ErrInvalidInput, false, "", false, false},
+		{"archived", archivedID, "new", "", eduboard.ErrArchived, false, "", false, false},
+		{"message too long", approvalID, "new", strings.Repeat("a", eduboard.MaxEnrollmentMessageLength+1), eduboard.ErrInvalidInput, false, "", false, false},
+		{"unknown course", pendingID, "new", "", nil, true, "", false, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			cj := &mock.CourseService{JoinCourseFn: func(course string, user string) (error, eduboard.Course) {
+				return nil, eduboard.Course{}
+			}}
+			var notified []string
+			n := &mock.NotificationService{CreateNotificationsFn: func(userIDs []string, notification eduboard.Notification) {
+				notified = userIDs
+				assert.Equal(t, eduboard.NotificationEnrollmentRequested, notification.Type, "notification type does not match")
+				assert.Equal(t, v.user, notification.ActorID, "actor does not match")
+			}}
+
+			err, request := New(r, newFinder(), n).RequestEnrollment(v.course, v.user, v.message, cj)
+			assert.Equal(t, v.joined, cj.JoinCourseFnInvoked, "JoinCourse was not invoked as expected")
+			assert.Equal(t, v.stored, r.InsertFnInvoked, "Insert was not invoked as expected")
+			assert.Equal(t, v.name == "again after rejection", r.DeleteFnInvoked, "Delete was not invoked as expected")
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.status, request.Status, "status does not match")
+			assert.Equal(t, v.user, request.UserID, "user does not match")
+			if v.stored {
+				assert.Equal(t, strings.TrimSpace(v.message), request.Message, "message does not match")
+				assert.Equal(t, []string{"owner", "teacher"}, notified, "staff was not notified")
+			}
+		})
+	}
+}
+
+func TestEnrollmentService_GetPendingEnrollments(t *testing.T) {
+	var testCases = []struct {
+		name string
+		user string
+		err  error
+	}{
+		{"teacher", "teacher", nil},
+		{"student", "student", eduboard.ErrForbidden},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			r.FindByCourseFn = func(courseID string, status eduboard.EnrollmentStatus) (error, []eduboard.EnrollmentRequest) {
+				assert.Equal(t, eduboard.EnrollmentPending, status, "status does not match")
+				return nil, []eduboard.EnrollmentRequest{{UserID: "pending"}}
+			}
+
+			err, requests := New(r, newFinder(), nil).GetPendingEnrollments(approvalID, v.user)
+			assert.Equal(t, v.err == nil, r.FindByCourseFnInvoked, "FindByCourse was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Len(t, requests, 1, "unexpected number of requests")
+		})
+	}
+}
+
+func TestEnrollmentService_ApproveEnrollment(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		course   string
+		request  string
+		user     string
+		err      error
+		notFound bool
+		added    bool
+	}{
+		{"success", approvalID, pendingID, "teacher", nil, false, true},
+		{"not staff", approvalID, pendingID, "student", eduboard.ErrForbidden, false, true},
+		{"already decided", approvalID, rejectedID, "teacher", eduboard.ErrInvalidInput, false, false},
+		{"other course", openID, pendingID, "teacher", eduboard.ErrNotFound, true, false},
+		{"unknown request", approvalID, approvalID, "teacher", nil, true, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			cj := &mock.CourseService{AddMembersFn: func(course string, user string, m []eduboard.Member) (error, eduboard.Course) {
+				assert.Equal(t, []eduboard.Member{{UserID: "pending", Role: eduboard.RoleStudent}}, m, "members do not match")
+				if user != "teacher" {
+					return errors.Wrap(eduboard.ErrForbidden, "not staff"), eduboard.Course{}
+				}
+				return nil, eduboard.Course{}
+			}}
+
+			err, request := New(r, newFinder(), nil).ApproveEnrollment(v.course, v.request, v.user, cj)
+			assert.Equal(t, v.added, cj.AddMembersFnInvoked, "AddMembers was not invoked as expected")
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				assert.False(t, r.DecideFnInvoked, "Decide was invoked")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.True(t, r.DecideFnInvoked, "Decide was not invoked")
+			assert.Equal(t, eduboard.EnrollmentApproved, request.Status, "status does not match")
+			assert.Equal(t, v.user, request.DecidedBy, "decider does not match")
+			assert.NotNil(t, request.DecidedAt, "decision time not set")
+		})
+	}
+}
+
+func TestEnrollmentService_RejectEnrollment(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		request string
+		user    string
+		err     error
+	}{
+		{"success", pendingID, "owner", nil},
+		{"not staff", pendingID, "student", eduboard.ErrForbidden},
+		{"already decided", rejectedID, "owner", eduboard.ErrInvalidInput},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			n := &mock.NotificationService{CreateNotificationsFn: func(userIDs []string, notification eduboard.Notification) {
+				assert.Equal(t, []string{"pending"}, userIDs, "student was not notified")
+				assert.Equal(t, eduboard.NotificationEnrollmentRejected, notification.Type, "notification type does not match")
+			}}
+
+			err, request := New(r, newFinder(), n).RejectEnrollment(approvalID, v.request, v.user)
+			assert.Equal(t, v.err == nil, r.DecideFnInvoked, "Decide was not invoked as expected")
+			assert.Equal(t, v.err == nil, n.CreateNotificationsFnInvoked, "CreateNotifications was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, eduboard.EnrollmentRejected, request.Status, "status does not match")
+		})
+	}
+}
+
+func TestEnrollmentService_CancelEnrollment(t *testing.T) {
+	r := newRepository()
+	r.DeletePendingFn = func(courseID string, userID string) error {
+		assert.Equal(t, approvalID, courseID, "course does not match")
+		if userID != "pending" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+	s := New(r, newFinder(), nil)
+
+	assert.Nil(t, s.CancelEnrollment(approvalID, "pending"), "returned error when it shouldn't")
+	assert.Error(t, s.CancelEnrollment(approvalID, "rejected"), "did not return error when expected")
+}
+
+func TestEnrollmentService_ApproveEnrollment_Retry(t *testing.T) {
+	r := newRepository()
+	r.DecideFn = func(id string, status eduboard.EnrollmentStatus, userID string, now time.Time) error {
+		return errors.New("error updating request")
+	}
+	cj := &mock.CourseService{AddMembersFn: func(course string, user string, m []eduboard.Member) (error, eduboard.Course) {
+		return nil, eduboard.Course{}
+	}}
+	s := New(r, newFinder(), nil)
+
+	err, _ := s.ApproveEnrollment(approvalID, pendingID, "teacher", cj)
+	assert.Error(t, err, "did not return error when expected")
+
+	// The request is still pending, so approving it again adds the student once more, which has no effect.
+	r.DecideFn = func(id string, status eduboard.EnrollmentStatus, userID string, now time.Time) error { return nil }
+	cj.AddMembersFnInvoked = false
+	err, request := s.ApproveEnrollment(approvalID, pendingID, "teacher", cj)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.True(t, cj.AddMembersFnInvoked, "AddMembers was not invoked")
+	assert.Equal(t, eduboard.EnrollmentApproved, request.Status, "status does not match")
+}
+
+func TestEnrollmentService_DeleteByCourse(t *testing.T) {
+	r := newRepository()
+	r.DeleteByCourseFn = func(courseID string) error {
+		if courseID != approvalID {
+			return errors.New("error deleting requests")
+		}
+		return nil
+	}
+	s := New(r, newFinder(), nil)
+
+	assert.Nil(t, s.DeleteByCourse(approvalID), "returned error when it shouldn't")
+	assert.Error(t, s.DeleteByCourse(openID), "did not return error when expected")
+}
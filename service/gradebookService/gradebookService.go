@@ -0,0 +1,342 @@
+package gradebookService
+
+import (
+	"encoding/csv"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxCategories is the largest number of grade categories of a course.
+const MaxCategories = 20
+
+type GradebookService struct {
+	gr  eduboard.GradeRepository
+	gcr eduboard.GradeCategoryRepository
+	ar  eduboard.AssignmentRepository
+	cf  eduboard.CourseOneFinder
+}
+
+func New(grades eduboard.GradeRepository, categories eduboard.GradeCategoryRepository, assignments eduboard.AssignmentRepository, courseFinder eduboard.CourseOneFinder) *GradebookService {
+	return &GradebookService{
+		gr:  grades,
+		gcr: categories,
+		ar:  assignments,
+		cf:  courseFinder,
+	}
+}
+
+// GetCategories returns the grade categories of a course. Only members may see them.
+func (gS *GradebookService) GetCategories(courseID string, userID string) (error, []eduboard.GradeCategory) {
+	if err, _ := access.Member(gS.cf, courseID, userID); err != nil {
+		return err, []eduboard.GradeCategory{}
+	}
+
+	err, categories := gS.gcr.Find(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding grade categories of course %s", courseID), []eduboard.GradeCategory{}
+	}
+	return nil, categories
+}
+
+// SetCategories replaces the grade categories of a course. Names must be unique and weights positive.
+func (gS *GradebookService) SetCategories(courseID string, userID string, categories []eduboard.GradeCategory) (error, []eduboard.GradeCategory) {
+	if err, _ := access.Manager(gS.cf, courseID, userID); err != nil {
+		return err, []eduboard.GradeCategory{}
+	}
+	if len(categories) > MaxCategories {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "more than %d grade categories", MaxCategories), []eduboard.GradeCategory{}
+	}
+
+	names := map[string]bool{}
+	result := make([]eduboard.GradeCategory, len(categories))
+	for k, v := range categories {
+		v.Name = strings.TrimSpace(v.Name)
+		if v.Name == "" {
+			return errors.Wrap(eduboard.ErrInvalidInput, "grade category has no name"), []eduboard.GradeCategory{}
+		}
+		if names[v.Name] {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "grade category %s is not unique", v.Name), []eduboard.GradeCategory{}
+		}
+		if v.Weight <= 0 {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "grade category %s has no positive weight", v.Name), []eduboard.GradeCategory{}
+		}
+		names[v.Name] = true
+		result[k] = v
+	}
+
+	if err := gS.gcr.Set(courseID, result); err != nil {
+		return errors.Wrapf(err, "error storing grade categories of course %s", courseID), []eduboard.GradeCategory{}
+	}
+	return nil, result
+}
+
+// SetGrade records the score and feedback of a student for a graded assignment, replacing an earlier grade.
+func (gS *GradebookService) SetGrade(courseID string, assignmentID string, studentID string, userID string, points float64, feedback string) (error, eduboard.Grade) {
+	err, course := access.Manager(gS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Grade{}
+	}
+	if role, _ := course.RoleOf(studentID); role != eduboard.RoleStudent {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "user %s is no student of course %s", studentID, courseID), eduboard.Grade{}
+	}
+
+	err, assignment := access.Assignment(gS.ar, courseID, assignmentID)
+	if err != nil {
+		return err, eduboard.Grade{}
+	}
+	if !assignment.IsGraded() {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "assignment %s is not graded", assignmentID), eduboard.Grade{}
+	}
+	if points < 0 || points > assignment.MaxPoints {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "points must be between 0 and %v", assignment.MaxPoints), eduboard.Grade{}
+	}
+
+	feedback = strings.TrimSpace(feedback)
+	if utf8.RuneCountInString(feedback) > eduboard.MaxFeedbackLength {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "feedback is longer than %d characters", eduboard.MaxFeedbackLength), eduboard.Grade{}
+	}
+
+	grade := eduboard.Grade{
+		CourseID:     course.ID,
+		AssignmentID: assignment.ID,
+		UserID:       studentID,
+		Points:       points,
+		Feedback:     feedback,
+		GradedBy:     userID,
+		GradedAt:     time.Now(),
+	}
+	if err = gS.gr.Upsert(&grade); err != nil {
+		return errors.Wrapf(err, "error storing grade of user %s for assignment %s", studentID, assignmentID), eduboard.Grade{}
+	}
+	return nil, grade
+}
+
+func (gS *GradebookService) DeleteGrade(courseID string, assignmentID string, studentID string, userID string) error {
+	if err, _ := access.Manager(gS.cf, courseID, userID); err != nil {
+		return err
+	}
+	if err, _ := access.Assignment(gS.ar, courseID, assignmentID); err != nil {
+		return err
+	}
+
+	if err := gS.gr.Delete(assignmentID, studentID); err != nil {
+		return errors.Wrapf(err, "error deleting grade of user %s for assignment %s", studentID, assignmentID)
+	}
+	return nil
+}
+
+// DeleteByAssignment deletes all grades given for an assignment.
+func (gS *GradebookService) DeleteByAssignment(assignmentID string) error {
+	if err := gS.gr.DeleteByAssignment(assignmentID); err != nil {
+		return errors.Wrapf(err, "error deleting grades of assignment %s", assignmentID)
+	}
+	return nil
+}
+
+// DeleteByCourse deletes all grades and the grade categories of a course.
+func (gS *GradebookService) DeleteByCourse(courseID string) error {
+	if err := gS.gr.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting grades of course %s", courseID)
+	}
+	if err := gS.gcr.Delete(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting grade categories of course %s", courseID)
+	}
+	return nil
+}
+
+// GetGradebook returns a row for every student of the course to the staff, and only the own row to students.
+func (gS *GradebookService) GetGradebook(courseID string, userID string) (error, eduboard.Gradebook) {
+	err, course := access.Member(gS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Gradebook{}
+	}
+
+	if !course.IsStaff(userID) {
+		err, grades := gS.gr.FindByUser(courseID, userID)
+		if err != nil {
+			return errors.Wrapf(err, "error finding grades of user %s in course %s", userID, courseID), eduboard.Gradebook{}
+		}
+		return gS.gradebook(course, []string{userID}, grades)
+	}
+
+	err, grades := gS.gr.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding grades of course %s", courseID), eduboard.Gradebook{}
+	}
+	return gS.gradebook(course, course.StudentIDs(), grades)
+}
+
+// ExportGradebook writes a header line followed by a line per student with their name, the points of every graded
+// assignment, the category scores and the total. Ungraded cells are empty.
+func (gS *GradebookService) ExportGradebook(courseID string, userID string, uf eduboard.UserFinder, w io.Writer) error {
+	err, course := access.Staff(gS.cf, courseID, userID)
+	if err != nil {
+		return err
+	}
+
+	err, gradebook := gS.GetGradebook(courseID, userID)
+	if err != nil {
+		return err
+	}
+
+	err, users := uf.FindMembers(course.StudentIDs())
+	if err != nil {
+		return errors.Wrapf(err, "error finding students of course %s", courseID)
+	}
+	names := make(map[string]eduboard.User, len(users))
+	for _, v := range users {
+		names[v.ID.Hex()] = v
+	}
+
+	header := []string{"User ID", "Name", "Surname", "Email"}
+	for _, v := range gradebook.Assignments {
+		header = append(header, cell(v.Title))
+	}
+	for _, v := range gradebook.Categories {
+		header = append(header, cell(v.Name))
+	}
+	header = append(header, "Total")
+
+	cw := csv.NewWriter(w)
+	if err = cw.Write(header); err != nil {
+		return errors.Wrap(err, "error writing gradebook")
+	}
+	for _, row := range gradebook.Rows {
+		user := names[row.UserID]
+		points := make(map[string]float64, len(row.Grades))
+		for _, v := range row.Grades {
+			points[v.AssignmentID.Hex()] = v.Points
+		}
+
+		line := []string{row.UserID, cell(user.Name), cell(user.Surname), cell(user.Email)}
+		for _, v := range gradebook.Assignments {
+			p, ok := points[v.ID.Hex()]
+			if !ok {
+				line = append(line, "")
+				continue
+			}
+			line = append(line, strconv.FormatFloat(p, 'f', -1, 64))
+		}
+		for _, v := range gradebook.Categories {
+			line = append(line, formatPercentage(row.Categories[v.Name]))
+		}
+		line = append(line, formatPercentage(row.Total))
+
+		if err = cw.Write(line); err != nil {
+			return errors.Wrap(err, "error writing gradebook")
+		}
+	}
+
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "error writing gradebook")
+}
+
+// gradebook computes the rows of the given students from their grades.
+func (gS *GradebookService) gradebook(course eduboard.Course, students []string, grades []eduboard.Grade) (error, eduboard.Gradebook) {
+	courseID := course.ID.Hex()
+	err, categories := gS.gcr.Find(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding grade categories of course %s", courseID), eduboard.Gradebook{}
+	}
+	err, all := gS.ar.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding assignments of course %s", courseID), eduboard.Gradebook{}
+	}
+
+	assignments := []eduboard.Assignment{}
+	for _, v := range all {
+		if v.IsGraded() {
+			assignments = append(assignments, v)
+		}
+	}
+
+	byUser := map[string][]eduboard.Grade{}
+	for _, v := range grades {
+		byUser[v.UserID] = append(byUser[v.UserID], v)
+	}
+
+	rows := make([]eduboard.GradebookRow, len(students))
+	for k, v := range students {
+		rows[k] = row(v, assignments, categories, byUser[v])
+	}
+
+	return nil, eduboard.Gradebook{
+		CourseID:    course.ID,
+		Categories:  categories,
+		Assignments: assignments,
+		Rows:        rows,
+	}
+}
+
+// row computes the scores of a student. Without categories the total is the share of points over all graded work.
+// With categories the total is the weighted mean of the category scores; categories without graded work are left out,
+// assignments outside of the categories do not count. Grades of deleted or ungraded assignments are ignored.
+func row(userID string, assignments []eduboard.Assignment, categories []eduboard.GradeCategory, grades []eduboard.Grade) eduboard.GradebookRow {
+	byAssignment := make(map[string]eduboard.Grade, len(grades))
+	for _, v := range grades {
+		byAssignment[v.AssignmentID.Hex()] = v
+	}
+
+	points := map[string]float64{}
+	max := map[string]float64{}
+	var allPoints, allMax float64
+	result := eduboard.GradebookRow{UserID: userID, Grades: []eduboard.Grade{}, Categories: map[string]*float64{}}
+	for _, v := range assignments {
+		grade, ok := byAssignment[v.ID.Hex()]
+		if !ok {
+			continue
+		}
+		result.Grades = append(result.Grades, grade)
+		points[v.Category] += grade.Points
+		max[v.Category] += v.MaxPoints
+		allPoints += grade.Points
+		allMax += v.MaxPoints
+	}
+
+	if len(categories) == 0 {
+		result.Total = percentage(allPoints, allMax)
+		return result
+	}
+
+	var sum, weights float64
+	for _, v := range categories {
+		score := percentage(points[v.Name], max[v.Name])
+		result.Categories[v.Name] = score
+		if score != nil {
+			sum += *score * v.Weight
+			weights += v.Weight
+		}
+	}
+	result.Total = percentage(sum, weights*100)
+	return result
+}
+
+// percentage returns points as a share of max in percent, or nil if max is zero.
+func percentage(points float64, max float64) *float64 {
+	if max == 0 {
+		return nil
+	}
+	p := points / max * 100
+	return &p
+}
+
+// cell escapes text that spreadsheet applications would evaluate as a formula.
+func cell(text string) string {
+	if text != "" && strings.ContainsAny(text[:1], "=+-@\t\r") {
+		return "'" + text
+	}
+	return text
+}
+
+func formatPercentage(p *float64) string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*p, 'f', 2, 64)
+}
@@ -0,0 +1,284 @@
+package gradebookService
+
+import (
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"testing"
+)
+
+const (
+	courseID     = "5b23bbdc2bfa844c41a9f134"
+	archivedID   = "5b23bbdc2bfa844c41a9f135"
+	homeworkID   = "5b23bbdc2bfa844c41a9f140"
+	examID       = "5b23bbdc2bfa844c41a9f141"
+	ungradedID   = "5b23bbdc2bfa844c41a9f142"
+	elsewhereID  = "5b23bbdc2bfa844c41a9f143"
+	studentID    = "5b1d24e72c5b292fe0d6ee56"
+	classmateID  = "5b1d24e72c5b292fe0d6ee57"
+	teacherID    = "5b1d24e72c5b292fe0d6ee55"
+	otherCourse  = "5b23bbdc2bfa844c41a9f136"
+	unknownGrade = "5b23bbdc2bfa844c41a9f144"
+)
+
+var members = []eduboard.Member{
+	{UserID: teacherID, Role: eduboard.RoleTeacher},
+	{UserID: studentID, Role: eduboard.RoleStudent},
+	{UserID: classmateID, Role: eduboard.RoleStudent},
+}
+
+var assignments = []eduboard.Assignment{
+	{ID: bson.ObjectIdHex(homeworkID), CourseID: bson.ObjectIdHex(courseID), Title: "Sorting", MaxPoints: 10, Category: "Homework"},
+	{ID: bson.ObjectIdHex(examID), CourseID: bson.ObjectIdHex(courseID), Title: "=Exam", MaxPoints: 50, Category: "Exams"},
+	{ID: bson.ObjectIdHex(ungradedID), CourseID: bson.ObjectIdHex(courseID), Title: "Reading"},
+	{ID: bson.ObjectIdHex(elsewhereID), CourseID: bson.ObjectIdHex(otherCourse), Title: "Other", MaxPoints: 10},
+}
+
+var grades = []eduboard.Grade{
+	{AssignmentID: bson.ObjectIdHex(homeworkID), UserID: studentID, Points: 5},
+	{AssignmentID: bson.ObjectIdHex(examID), UserID: studentID, Points: 45},
+	{AssignmentID: bson.ObjectIdHex(unknownGrade), UserID: studentID, Points: 100},
+	{AssignmentID: bson.ObjectIdHex(homeworkID), UserID: classmateID, Points: 10},
+}
+
+func newAssignments() *mock.AssignmentRepository {
+	ar := &mock.AssignmentRepository{}
+	ar.FindOneByIDFn = func(id string) (error, eduboard.Assignment) {
+		for _, v := range assignments {
+			if v.ID.Hex() == id {
+				return nil, v
+			}
+		}
+		return errors.New("not found"), eduboard.Assignment{}
+	}
+	ar.FindByCourseFn = func(id string) (error, []eduboard.Assignment) {
+		return nil, assignments[:3]
+	}
+	return ar
+}
+
+func newGrades() *mock.GradeRepository {
+	gr := &mock.GradeRepository{}
+	gr.UpsertFn = func(grade *eduboard.Grade) error { return nil }
+	gr.DeleteFn = func(assignmentID string, userID string) error { return nil }
+	gr.FindByCourseFn = func(id string) (error, []eduboard.Grade) { return nil, grades }
+	gr.FindByUserFn = func(id string, userID string) (error, []eduboard.Grade) {
+		result := []eduboard.Grade{}
+		for _, v := range grades {
+			if v.UserID == userID {
+				result = append(result, v)
+			}
+		}
+		return nil, result
+	}
+	return gr
+}
+
+func newCategories(categories ...eduboard.GradeCategory) *mock.GradeCategoryRepository {
+	gcr := &mock.GradeCategoryRepository{}
+	gcr.FindFn = func(id string) (error, []eduboard.GradeCategory) { return nil, categories }
+	gcr.SetFn = func(id string, categories []eduboard.GradeCategory) error { return nil }
+	return gcr
+}
+
+func TestNew(t *testing.T) {
+	gr := newGrades()
+	gcr := newCategories()
+	ar := newAssignments()
+	cf := mock.CourseFinder(members, archivedID, courseID)
+	s := New(gr, gcr, ar, cf)
+	assert.Equal(t, gr, s.gr, "grade repository does not match")
+	assert.Equal(t, gcr, s.gcr, "category repository does not match")
+	assert.Equal(t, ar, s.ar, "assignment repository does not match")
+	assert.Equal(t, cf, s.cf, "course finder does not match")
+}
+
+func TestGradebookService_SetCategories(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		course     string
+		user       string
+		categories []eduboard.GradeCategory
+		err        error
+	}{
+		{"success", courseID, teacherID, []eduboard.GradeCategory{{Name: " Homework ", Weight: 1}, {Name: "Exams", Weight: 3}}, nil},
+		{"none", courseID, teacherID, []eduboard.GradeCategory{}, nil},
+		{"student", courseID, studentID, []eduboard.GradeCategory{{Name: "Homework", Weight: 1}}, eduboard.ErrForbidden},
+		{"archived", archivedID, teacherID, []eduboard.GradeCategory{{Name: "Homework", Weight: 1}}, eduboard.ErrArchived},
+		{"no name", courseID, teacherID, []eduboard.GradeCategory{{Name: " ", Weight: 1}}, eduboard.ErrInvalidInput},
+		{"duplicate", courseID, teacherID, []eduboard.GradeCategory{{Name: "Homework", Weight: 1}, {Name: "Homework ", Weight: 2}}, eduboard.ErrInvalidInput},
+		{"zero weight", courseID, teacherID, []eduboard.GradeCategory{{Name: "Homework", Weight: 0}}, eduboard.ErrInvalidInput},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			gcr := newCategories()
+
+			err, categories := New(newGrades(), gcr, newAssignments(), mock.CourseFinder(members, archivedID, courseID)).SetCategories(v.course, v.user, v.categories)
+			assert.Equal(t, v.err == nil, gcr.SetFnInvoked, "Set was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Len(t, categories, len(v.categories), "unexpected number of categories")
+			if len(categories) > 0 {
+				assert.Equal(t, "Homework", categories[0].Name, "name was not trimmed")
+			}
+		})
+	}
+}
+
+func TestGradebookService_SetGrade(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		course     string
+		assignment string
+		student    string
+		user       string
+		points     float64
+		feedback   string
+		err        error
+		notFound   bool
+	}{
+		{"success", courseID, homeworkID, studentID, teacherID, 7.5, " Well done ", nil, false},
+		{"full points", courseID, examID, studentID, teacherID, 50, "", nil, false},
+		{"student", courseID, homeworkID, classmateID, studentID, 7, "", eduboard.ErrForbidden, false},
+		{"archived", archivedID, homeworkID, studentID, teacherID, 7, "", eduboard.ErrArchived, false},
+		{"no student", courseID, homeworkID, teacherID, teacherID, 7, "", eduboard.ErrInvalidInput, false},
+		{"ungraded", courseID, ungradedID, studentID, teacherID, 7, "", eduboard.ErrInvalidInput, false},
+		{"negative", courseID, homeworkID, studentID, teacherID, -1, "", eduboard.ErrInvalidInput, false},
+		{"too many", courseID, homeworkID, studentID, teacherID, 11, "", eduboard.ErrInvalidInput, false},
+		{"feedback too long", courseID, homeworkID, studentID, teacherID, 7, strings.Repeat("a", eduboard.MaxFeedbackLength+1), eduboard.ErrInvalidInput, false},
+		{"other course", courseID, elsewhereID, studentID, teacherID, 7, "", nil, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			gr := newGrades()
+
+			err, grade := New(gr, newCategories(), newAssignments(), mock.CourseFinder(members, archivedID, courseID)).SetGrade(v.course, v.assignment, v.student, v.user, v.points, v.feedback)
+			assert.Equal(t, v.err == nil && !v.notFound, gr.UpsertFnInvoked, "Upsert was not invoked as expected")
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.points, grade.Points, "points do not match")
+			assert.Equal(t, strings.TrimSpace(v.feedback), grade.Feedback, "feedback does not match")
+			assert.Equal(t, v.student, grade.UserID, "student does not match")
+			assert.Equal(t, v.user, grade.GradedBy, "grader does not match")
+		})
+	}
+}
+
+func TestGradebookService_DeleteGrade(t *testing.T) {
+	gr := newGrades()
+	s := New(gr, newCategories(), newAssignments(), mock.CourseFinder(members, archivedID, courseID))
+
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(s.DeleteGrade(courseID, homeworkID, studentID, studentID)), "error does not match")
+	assert.False(t, gr.DeleteFnInvoked, "Delete was invoked")
+	assert.Nil(t, s.DeleteGrade(courseID, homeworkID, studentID, teacherID), "returned error when it shouldn't")
+	assert.True(t, gr.DeleteFnInvoked, "Delete was not invoked")
+}
+
+func TestGradebookService_DeleteByAssignment(t *testing.T) {
+	gr := newGrades()
+	gr.DeleteByAssignmentFn = func(assignmentID string) error {
+		if assignmentID != homeworkID {
+			return errors.New("error deleting grades")
+		}
+		return nil
+	}
+	s := New(gr, newCategories(), newAssignments(), mock.CourseFinder(members, archivedID, courseID))
+
+	assert.Nil(t, s.DeleteByAssignment(homeworkID), "returned error when it shouldn't")
+	assert.Error(t, s.DeleteByAssignment(courseID), "did not return error when expected")
+}
+
+func TestGradebookService_DeleteByCourse(t *testing.T) {
+	gr := newGrades()
+	gr.DeleteByCourseFn = func(id string) error {
+		if id != courseID {
+			return errors.New("error deleting grades")
+		}
+		return nil
+	}
+	gcr := newCategories()
+	gcr.DeleteFn = func(id string) error { return nil }
+	s := New(gr, gcr, newAssignments(), mock.CourseFinder(members, archivedID, courseID))
+
+	assert.Nil(t, s.DeleteByCourse(courseID), "returned error when it shouldn't")
+	assert.True(t, gcr.DeleteFnInvoked, "Delete was not invoked")
+
+	gcr.DeleteFnInvoked = false
+	assert.Error(t, s.DeleteByCourse(archivedID), "did not return error when expected")
+	assert.False(t, gcr.DeleteFnInvoked, "Delete was invoked")
+}
+
+func TestGradebookService_GetGradebook(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		user       string
+		categories []eduboard.GradeCategory
+		rows       []string
+		totals     []float64
+	}{
+		// 50 of 60 points and 10 of 10 points.
+		{"staff without categories", teacherID, nil, []string{studentID, classmateID}, []float64{50.0 / 60 * 100, 100}},
+		// (50% * 1 + 90% * 3) / 4 and only homework graded.
+		{"staff with categories", teacherID, []eduboard.GradeCategory{{Name: "Homework", Weight: 1}, {Name: "Exams", Weight: 3}}, []string{studentID, classmateID}, []float64{80, 100}},
+		{"student", studentID, nil, []string{studentID}, []float64{50.0 / 60 * 100}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			gr := newGrades()
+
+			err, gradebook := New(gr, newCategories(v.categories...), newAssignments(), mock.CourseFinder(members, archivedID, courseID)).GetGradebook(courseID, v.user)
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.user == teacherID, gr.FindByCourseFnInvoked, "FindByCourse was not invoked as expected")
+			assert.Len(t, gradebook.Assignments, 2, "ungraded assignment was included")
+			assert.Len(t, gradebook.Rows, len(v.rows), "unexpected number of rows")
+			for k, row := range gradebook.Rows {
+				assert.Equal(t, v.rows[k], row.UserID, "user does not match")
+				if assert.NotNil(t, row.Total, "total not computed") {
+					assert.InDelta(t, v.totals[k], *row.Total, 0.001, "total does not match")
+				}
+			}
+			assert.Len(t, gradebook.Rows[0].Grades, 2, "grade of unknown assignment was included")
+			if len(v.categories) > 0 {
+				assert.Nil(t, gradebook.Rows[1].Categories["Exams"], "score of ungraded category was computed")
+			}
+		})
+	}
+
+	err, _ := New(newGrades(), newCategories(), newAssignments(), mock.CourseFinder(members, archivedID, courseID)).GetGradebook(courseID, "stranger")
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(err), "error does not match")
+}
+
+func TestGradebookService_ExportGradebook(t *testing.T) {
+	uf := &mock.UserRepository{FindMembersFn: func(ids []string) (error, []eduboard.User) {
+		assert.Equal(t, []string{studentID, classmateID}, ids, "students do not match")
+		return nil, []eduboard.User{
+			{ID: bson.ObjectIdHex(studentID), Name: "Ada", Surname: "Lovelace", Email: "ada@example.com"},
+			{ID: bson.ObjectIdHex(classmateID), Name: "+Alan", Surname: "Turing", Email: "alan@example.com"},
+		}
+	}}
+	s := New(newGrades(), newCategories(eduboard.GradeCategory{Name: "Homework", Weight: 1}), newAssignments(), mock.CourseFinder(members, archivedID, courseID))
+
+	var buf bytes.Buffer
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(s.ExportGradebook(courseID, studentID, uf, &buf)), "error does not match")
+	assert.Empty(t, buf.String(), "gradebook was written")
+
+	assert.Nil(t, s.ExportGradebook(courseID, teacherID, uf, &buf), "returned error when it shouldn't")
+	expected := "User ID,Name,Surname,Email,Sorting,'=Exam,Homework,Total\n" +
+		studentID + ",Ada,Lovelace,ada@example.com,5,45,50.00,50.00\n" +
+		classmateID + ",'+Alan,Turing,alan@example.com,10,,100.00,100.00\n"
+	assert.Equal(t, expected, buf.String(), "csv does not match")
+}
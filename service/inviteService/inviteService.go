@@ -0,0 +1,251 @@
+package inviteService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/auth"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const (
+	// InviteTimeout is the time an email invite stays valid.
+	InviteTimeout = 14 * 24 * time.Hour
+	// codeLength is the number of characters of an invite code.
+	codeLength = 8
+)
+
+type InviteService struct {
+	r  eduboard.InviteRepository
+	cf eduboard.CourseOneFinder
+	ur eduboard.UserRepository
+	n  eduboard.Notifier
+	a  Authenticator
+}
+
+type Authenticator interface {
+	SessionID() string
+}
+
+func New(repository eduboard.InviteRepository, courseFinder eduboard.CourseOneFinder, userRepository eduboard.UserRepository, notifier eduboard.Notifier) *InviteService {
+	return &InviteService{
+		r:  repository,
+		cf: courseFinder,
+		ur: userRepository,
+		n:  notifier,
+		a:  &auth.Authenticator{},
+	}
+}
+
+// CreateInviteCode creates a new invite code for a course. Only the staff of the course may create codes.
+func (iS *InviteService) CreateInviteCode(courseID string, userID string, expiresAt *time.Time, maxUses int) (error, eduboard.InviteCode) {
+	now := time.Now()
+	if maxUses < 0 {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "negative maximum uses %d", maxUses), eduboard.InviteCode{}
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return errors.Wrap(eduboard.ErrInvalidInput, "expiry is in the past"), eduboard.InviteCode{}
+	}
+
+	err, course := access.Manager(iS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.InviteCode{}
+	}
+
+	code, err := auth.NewCode(codeLength)
+	if err != nil {
+		return errors.Wrap(err, "error generating invite code"), eduboard.InviteCode{}
+	}
+
+	invite := eduboard.InviteCode{
+		CourseID:  course.ID,
+		Code:      code,
+		CreatedBy: userID,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+		MaxUses:   maxUses,
+	}
+	if err = iS.r.InsertCode(&invite); err != nil {
+		return errors.Wrapf(err, "error storing invite code for course %s", courseID), eduboard.InviteCode{}
+	}
+	return nil, invite
+}
+
+// GetInviteCodes returns all codes of a course including revoked and expired ones. Only the staff may see them.
+func (iS *InviteService) GetInviteCodes(courseID string, userID string) (error, []eduboard.InviteCode) {
+	if err, _ := access.Staff(iS.cf, courseID, userID); err != nil {
+		return err, []eduboard.InviteCode{}
+	}
+
+	err, codes := iS.r.FindCodes(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding invite codes of course %s", courseID), []eduboard.InviteCode{}
+	}
+	return nil, codes
+}
+
+// RevokeInviteCode stops a code from being used. Members who already joined with it stay in the course.
+func (iS *InviteService) RevokeInviteCode(courseID string, codeID string, userID string) error {
+	if err, _ := access.Staff(iS.cf, courseID, userID); err != nil {
+		return err
+	}
+
+	if err := iS.r.RevokeCode(codeID, courseID); err != nil {
+		return errors.Wrapf(err, "error revoking invite code %s", codeID)
+	}
+	return nil
+}
+
+// Join adds userID as a student to the course of an invite code. Joining a course one is already a member of
+// does not count as a use of the code.
+func (iS *InviteService) Join(code string, userID string, cj eduboard.CourseJoiner) (error, eduboard.Course) {
+	err, invite := iS.r.FindCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return errors.Wrapf(err, "error finding invite code %s", code), eduboard.Course{}
+	}
+
+	now := time.Now()
+	if !invite.Valid(now) {
+		return errors.Wrapf(eduboard.ErrExpired, "invite code %s can not be used anymore", code), eduboard.Course{}
+	}
+
+	err, course := iS.cf.FindOneByID(invite.CourseID.Hex())
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", invite.CourseID.Hex()), eduboard.Course{}
+	}
+	if _, ok := course.RoleOf(userID); ok {
+		return nil, course
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not join course %s", course.ID.Hex()), eduboard.Course{}
+	}
+
+	// The code may have been used up since it was found.
+	if err = iS.r.UseCode(invite, now); err != nil {
+		return errors.Wrapf(eduboard.ErrExpired, "invite code %s can not be used anymore: %v", code, err), eduboard.Course{}
+	}
+
+	err, joined := cj.JoinCourse(course.ID.Hex(), userID)
+	if err != nil {
+		if rErr := iS.r.ReleaseCode(invite.ID.Hex()); rErr != nil {
+			return errors.Wrapf(err, "error joining course %s, the use of code %s was not released: %v", course.ID.Hex(), code, rErr), eduboard.Course{}
+		}
+		return errors.Wrapf(err, "error joining course %s", course.ID.Hex()), eduboard.Course{}
+	}
+	return nil, joined
+}
+
+// InviteEmail invites email to a course. Users who already have an account are added to the course right away
+// and the returned invite has UserID set. Otherwise an invite is sent that is redeemed when registering.
+func (iS *InviteService) InviteEmail(courseID string, userID string, email string, cj eduboard.CourseJoiner) (error, eduboard.EmailInvite) {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "invalid email %q", email), eduboard.EmailInvite{}
+	}
+	email = address.Address
+
+	err, course := access.Manager(iS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.EmailInvite{}
+	}
+
+	now := time.Now()
+	invite := eduboard.EmailInvite{
+		CourseID:  course.ID,
+		Email:     email,
+		InvitedBy: userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(InviteTimeout),
+	}
+
+	if err, user := iS.ur.FindByEmail(email); err == nil {
+		member := eduboard.Member{UserID: user.ID.Hex(), Role: eduboard.RoleStudent}
+		if err, _ = cj.AddMembers(courseID, userID, []eduboard.Member{member}); err != nil {
+			return errors.Wrapf(err, "error adding user %s to course %s", member.UserID, courseID), eduboard.EmailInvite{}
+		}
+		invite.UserID = member.UserID
+		return nil, invite
+	}
+
+	token := iS.a.SessionID()
+	invite.TokenHash = auth.HashToken(token)
+	if err = iS.r.InsertEmailInvite(&invite); err != nil {
+		return errors.Wrapf(err, "error storing invite for course %s", courseID), eduboard.EmailInvite{}
+	}
+
+	if err = iS.n.NotifyInvite(email, course, token, invite.ExpiresAt); err != nil {
+		return errors.Wrapf(err, "error sending invite for course %s", courseID), eduboard.EmailInvite{}
+	}
+	return nil, invite
+}
+
+// GetEmailInvites returns the pending invites of a course. Only the staff may see them.
+func (iS *InviteService) GetEmailInvites(courseID string, userID string) (error, []eduboard.EmailInvite) {
+	if err, _ := access.Staff(iS.cf, courseID, userID); err != nil {
+		return err, []eduboard.EmailInvite{}
+	}
+
+	err, invites := iS.r.FindEmailInvites(courseID, time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "error finding invites of course %s", courseID), []eduboard.EmailInvite{}
+	}
+	return nil, invites
+}
+
+// RedeemEmailInvite adds userID to the course of the invite sent with token. Every invite can only be redeemed once.
+// The token only proves that the user could read the invite, not that they own the email they registered with,
+// so unverified users join in JoinInvitedCourses and get an empty course for now.
+func (iS *InviteService) RedeemEmailInvite(token string, userID string, cj eduboard.CourseJoiner) (error, eduboard.Course) {
+	err, invite := iS.r.RedeemEmailInvite(auth.HashToken(token), userID, time.Now())
+	if err != nil {
+		return errors.Wrapf(eduboard.ErrExpired, "invalid invite token: %v", err), eduboard.Course{}
+	}
+
+	err, user := iS.ur.Find(userID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding user %s", userID), eduboard.Course{}
+	}
+	if !user.Verified {
+		return nil, eduboard.Course{}
+	}
+	return iS.joinInvited(invite, cj)
+}
+
+// JoinInvitedCourses adds userID to the courses of all invites they redeemed. It keeps going if a course can
+// not be joined and returns the last error.
+func (iS *InviteService) JoinInvitedCourses(userID string, cj eduboard.CourseJoiner) error {
+	err, invites := iS.r.FindRedeemedEmailInvites(userID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding invites redeemed by user %s", userID)
+	}
+
+	var last error
+	for _, invite := range invites {
+		if err, _ = iS.joinInvited(invite, cj); err != nil {
+			last = err
+		}
+	}
+	return last
+}
+
+// DeleteByCourse deletes all invite codes and email invites of a course.
+func (iS *InviteService) DeleteByCourse(courseID string) error {
+	if err := iS.r.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting invites of course %s", courseID)
+	}
+	return nil
+}
+
+// joinInvited adds the user who redeemed invite to its course. The invite is not needed afterwards.
+func (iS *InviteService) joinInvited(invite eduboard.EmailInvite, cj eduboard.CourseJoiner) (error, eduboard.Course) {
+	err, course := cj.JoinCourse(invite.CourseID.Hex(), invite.UserID)
+	if err != nil {
+		return errors.Wrapf(err, "error joining course %s", invite.CourseID.Hex()), eduboard.Course{}
+	}
+	if err = iS.r.DeleteEmailInvite(invite.ID.Hex()); err != nil {
+		return errors.Wrapf(err, "error deleting invite %s", invite.ID.Hex()), eduboard.Course{}
+	}
+	return nil, course
+}
@@ -0,0 +1,375 @@
+package inviteService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/auth"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	courseID   = "5b23bbdc2bfa844c41a9f134"
+	archivedID = "5b23bbdc2bfa844c41a9f135"
+	codeID     = "5b23bbdc2bfa844c41a9f140"
+	userID     = "5b23bbdc2bfa844c41a9f150"
+)
+
+var members = []eduboard.Member{
+	{UserID: "teacher", Role: eduboard.RoleTeacher},
+	{UserID: "student", Role: eduboard.RoleStudent},
+}
+
+// newService returns a service for a course and an archived course, an existing user existing@mail.com and
+// the users new and verified.
+func newService(r *mock.InviteRepository, n *mock.Notifier) *InviteService {
+	cr := &mock.CourseRepository{}
+	cr.FindFn = func(id string) (error, eduboard.Course) {
+		switch id {
+		case courseID:
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Title: "Algorithms", Members: members}
+		case archivedID:
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members, Archived: true}
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
+
+	ur := &mock.UserRepository{}
+	ur.FindByEmailFn = func(email string) (error, eduboard.User) {
+		if email == "existing@mail.com" {
+			return nil, eduboard.User{ID: bson.ObjectIdHex(userID), Email: email}
+		}
+		return errors.New("not found"), eduboard.User{}
+	}
+	ur.FindFn = func(id string) (error, eduboard.User) {
+		switch id {
+		case "new":
+			return nil, eduboard.User{}
+		case "verified":
+			return nil, eduboard.User{Verified: true}
+		}
+		return errors.New("not found"), eduboard.User{}
+	}
+
+	s := New(r, cr, ur, n)
+	s.a = &mock.AuthenticatorMock{SessionIDFn: func() string { return "token" }}
+	return s
+}
+
+func TestNew(t *testing.T) {
+	r := &mock.InviteRepository{}
+	n := &mock.Notifier{}
+	s := New(r, &mock.CourseRepository{}, &mock.UserRepository{}, n)
+	assert.Equal(t, r, s.r, "repository does not match")
+	assert.Equal(t, n, s.n, "notifier does not match")
+}
+
+func TestInviteService_CreateInviteCode(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	var testCases = []struct {
+		name      string
+		course    string
+		user      string
+		expiresAt *time.Time
+		maxUses   int
+		err       error
+		notFound  bool
+	}{
+		{"success", courseID, "teacher", nil, 0, nil, false},
+		{"limited", courseID, "teacher", &future, 10, nil, false},
+		{"student", courseID, "student", nil, 0, eduboard.ErrForbidden, false},
+		{"archived", archivedID, "teacher", nil, 0, eduboard.ErrArchived, false},
+		{"expired", courseID, "teacher", &past, 0, eduboard.ErrInvalidInput, false},
+		{"negative uses", courseID, "teacher", nil, -1, eduboard.ErrInvalidInput, false},
+		{"unknown course", codeID, "teacher", nil, 0, nil, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := &mock.InviteRepository{InsertCodeFn: func(code *eduboard.InviteCode) error { return nil }}
+
+			err, code := newService(r, nil).CreateInviteCode(v.course, v.user, v.expiresAt, v.maxUses)
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				assert.False(t, r.InsertCodeFnInvoked, "InsertCode was invoked")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.True(t, r.InsertCodeFnInvoked, "InsertCode was not invoked")
+			assert.Len(t, code.Code, codeLength, "code length does not match")
+			assert.Empty(t, strings.Trim(code.Code, auth.CodeAlphabet), "code contains invalid characters")
+			assert.Equal(t, v.maxUses, code.MaxUses, "maximum uses do not match")
+			assert.Equal(t, v.expiresAt, code.ExpiresAt, "expiry does not match")
+			assert.Equal(t, courseID, code.CourseID.Hex(), "course does not match")
+		})
+	}
+}
+
+func TestInviteService_RevokeInviteCode(t *testing.T) {
+	var testCases = []struct {
+		name string
+		user string
+		err  error
+	}{
+		{"success", "teacher", nil},
+		{"student", "student", eduboard.ErrForbidden},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := &mock.InviteRepository{RevokeCodeFn: func(id string, course string) error {
+				assert.Equal(t, codeID, id, "code does not match")
+				assert.Equal(t, courseID, course, "course does not match")
+				return nil
+			}}
+
+			err := newService(r, nil).RevokeInviteCode(courseID, codeID, v.user)
+			assert.Equal(t, v.err == nil, r.RevokeCodeFnInvoked, "RevokeCode was not invoked as expected")
+			assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+		})
+	}
+}
+
+func TestInviteService_Join(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	codes := map[string]eduboard.InviteCode{
+		"VALID234":  {CourseID: bson.ObjectIdHex(courseID), MaxUses: 2, Uses: 1},
+		"EXPIRED2":  {CourseID: bson.ObjectIdHex(courseID), ExpiresAt: &past},
+		"REVOKED2":  {CourseID: bson.ObjectIdHex(courseID), Revoked: true},
+		"USEDUP23":  {CourseID: bson.ObjectIdHex(courseID), MaxUses: 2, Uses: 2},
+		"ARCHIVED":  {CourseID: bson.ObjectIdHex(archivedID)},
+		"RACELOST":  {CourseID: bson.ObjectIdHex(courseID), MaxUses: 1},
+		"NOCOURSE2": {CourseID: bson.ObjectIdHex(codeID)},
+	}
+
+	var testCases = []struct {
+		name     string
+		code     string
+		user     string
+		err      error
+		notFound bool
+		joined   bool
+	}{
+		{"success", "VALID234", "new", nil, false, true},
+		{"lower case", " valid234 ", "new", nil, false, true},
+		{"already member", "VALID234", "student", nil, false, false},
+		{"expired", "EXPIRED2", "new", eduboard.ErrExpired, false, false},
+		{"revoked", "REVOKED2", "new", eduboard.ErrExpired, false, false},
+		{"used up", "USEDUP23", "new", eduboard.ErrExpired, false, false},
+		{"used up concurrently", "RACELOST", "new", eduboard.ErrExpired, false, false},
+		{"archived", "ARCHIVED", "new", eduboard.ErrArchived, false, false},
+		{"unknown code", "UNKNOWN2", "new", nil, true, false},
+		{"unknown course", "NOCOURSE2", "new", nil, true, false},
+		{"joining fails", "VALID234", "broken", nil, true, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := &mock.InviteRepository{}
+			r.FindCodeFn = func(code string) (error, eduboard.InviteCode) {
+				if c, ok := codes[code]; ok {
+					return nil, c
+				}
+				return errors.New("not found"), eduboard.InviteCode{}
+			}
+			r.UseCodeFn = func(code eduboard.InviteCode, now time.Time) error {
+				if code.MaxUses == 1 {
+					return errors.New("not found")
+				}
+				return nil
+			}
+			r.ReleaseCodeFn = func(id string) error { return nil }
+			cj := &mock.CourseService{JoinCourseFn: func(course string, user string) (error, eduboard.Course) {
+				assert.Equal(t, courseID, course, "course does not match")
+				assert.Equal(t, v.user, user, "user does not match")
+				if user == "broken" {
+					return errors.New("error updating course"), eduboard.Course{}
+				}
+				return nil, eduboard.Course{ID: bson.ObjectIdHex(course)}
+			}}
+
+			err, course := newService(r, nil).Join(v.code, v.user, cj)
+			assert.Equal(t, v.joined, cj.JoinCourseFnInvoked, "JoinCourse was not invoked as expected")
+			assert.Equal(t, v.user == "broken", r.ReleaseCodeFnInvoked, "ReleaseCode was not invoked as expected")
+			if v.err != nil || v.notFound {
+				assert.Error(t, err, "did not return error when expected")
+				assert.Equal(t, v.err != nil, errors.Cause(err) == v.err, "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.joined, r.UseCodeFnInvoked, "UseCode was not invoked as expected")
+			assert.Equal(t, courseID, course.ID.Hex(), "course does not match")
+		})
+	}
+}
+
+func TestInviteService_InviteEmail(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		course  string
+		user    string
+		email   string
+		err     error
+		added   bool
+		invited bool
+	}{
+		{"invite", courseID, "teacher", " new@mail.com ", nil, false, true},
+		{"display name", courseID, "teacher", "New <new@mail.com>", nil, false, true},
+		{"existing user", courseID, "teacher", "existing@mail.com", nil, true, false},
+		{"invalid email", courseID, "teacher", "new", eduboard.ErrInvalidInput, false, false},
+		{"missing domain", courseID, "teacher", "new@", eduboard.ErrInvalidInput, false, false},
+		{"student", courseID, "student", "new@mail.com", eduboard.ErrForbidden, false, false},
+		{"archived", archivedID, "teacher", "new@mail.com", eduboard.ErrArchived, false, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			var stored eduboard.EmailInvite
+			r := &mock.InviteRepository{InsertEmailInviteFn: func(invite *eduboard.EmailInvite) error {
+				stored = *invite
+				return nil
+			}}
+			n := &mock.Notifier{NotifyInviteFn: func(email string, course eduboard.Course, token string, expires time.Time) error {
+				assert.Equal(t, "new@mail.com", email, "recipient does not match")
+				assert.Equal(t, "Algorithms", course.Title, "course does not match")
+				assert.Equal(t, "token", token, "token does not match")
+				return nil
+			}}
+			cj := &mock.CourseService{AddMembersFn: func(course string, user string, m []eduboard.Member) (error, eduboard.Course) {
+				assert.Equal(t, "teacher", user, "acting user does not match")
+				assert.Equal(t, []eduboard.Member{{UserID: userID, Role: eduboard.RoleStudent}}, m, "members do not match")
+				return nil, eduboard.Course{}
+			}}
+
+			err, invite := newService(r, n).InviteEmail(v.course, v.user, v.email, cj)
+			assert.Equal(t, v.added, cj.AddMembersFnInvoked, "AddMembers was not invoked as expected")
+			assert.Equal(t, v.invited, r.InsertEmailInviteFnInvoked, "InsertEmailInvite was not invoked as expected")
+			assert.Equal(t, v.invited, n.NotifyInviteFnInvoked, "NotifyInvite was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			if v.added {
+				assert.Equal(t, "existing@mail.com", invite.Email, "email does not match")
+				assert.Equal(t, userID, invite.UserID, "invite is not marked as redeemed")
+				return
+			}
+			assert.Equal(t, "new@mail.com", invite.Email, "email does not match")
+			assert.Equal(t, auth.HashToken("token"), stored.TokenHash, "token hash does not match")
+			assert.True(t, invite.ExpiresAt.After(time.Now().Add(InviteTimeout-time.Minute)), "expiry does not match")
+		})
+	}
+}
+
+func TestInviteService_RedeemEmailInvite(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		token  string
+		user   string
+		err    error
+		joined bool
+	}{
+		{"verified", "token", "verified", nil, true},
+		{"unverified", "token", "new", nil, false},
+		{"invalid token", "other", "verified", eduboard.ErrExpired, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := &mock.InviteRepository{RedeemEmailInviteFn: func(tokenHash string, user string, now time.Time) (error, eduboard.EmailInvite) {
+				assert.Equal(t, v.user, user, "user does not match")
+				if tokenHash != auth.HashToken("token") {
+					return errors.New("not found"), eduboard.EmailInvite{}
+				}
+				return nil, eduboard.EmailInvite{ID: bson.ObjectIdHex(codeID), CourseID: bson.ObjectIdHex(courseID), UserID: user}
+			}}
+			r.DeleteEmailInviteFn = func(id string) error {
+				assert.Equal(t, codeID, id, "invite does not match")
+				return nil
+			}
+			cj := &mock.CourseService{JoinCourseFn: func(course string, user string) (error, eduboard.Course) {
+				assert.Equal(t, courseID, course, "course does not match")
+				assert.Equal(t, v.user, user, "user does not match")
+				return nil, eduboard.Course{ID: bson.ObjectIdHex(course)}
+			}}
+
+			err, course := newService(r, nil).RedeemEmailInvite(v.token, v.user, cj)
+			assert.Equal(t, v.joined, cj.JoinCourseFnInvoked, "JoinCourse was not invoked as expected")
+			assert.Equal(t, v.joined, r.DeleteEmailInviteFnInvoked, "DeleteEmailInvite was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			if v.joined {
+				assert.Equal(t, courseID, course.ID.Hex(), "course does not match")
+				return
+			}
+			assert.Empty(t, course.ID, "returned a course before the user joined")
+		})
+	}
+}
+
+func TestInviteService_JoinInvitedCourses(t *testing.T) {
+	invites := []eduboard.EmailInvite{
+		{ID: bson.NewObjectId(), CourseID: bson.ObjectIdHex(archivedID), UserID: "new"},
+		{ID: bson.NewObjectId(), CourseID: bson.ObjectIdHex(courseID), UserID: "new"},
+	}
+	r := &mock.InviteRepository{}
+	r.FindRedeemedEmailInvitesFn = func(user string) (error, []eduboard.EmailInvite) {
+		assert.Equal(t, "new", user, "user does not match")
+		return nil, invites
+	}
+	deleted := []bson.ObjectId{}
+	r.DeleteEmailInviteFn = func(id string) error {
+		deleted = append(deleted, bson.ObjectIdHex(id))
+		return nil
+	}
+	joined := []string{}
+	cj := &mock.CourseService{JoinCourseFn: func(course string, user string) (error, eduboard.Course) {
+		if course == archivedID {
+			return eduboard.ErrArchived, eduboard.Course{}
+		}
+		joined = append(joined, course)
+		return nil, eduboard.Course{ID: bson.ObjectIdHex(course)}
+	}}
+
+	err := newService(r, nil).JoinInvitedCourses("new", cj)
+	assert.Equal(t, eduboard.ErrArchived, errors.Cause(err), "error does not match")
+	assert.Equal(t, []string{courseID}, joined, "did not keep joining after an error")
+	assert.Equal(t, []bson.ObjectId{invites[1].ID}, deleted, "deleted the wrong invites")
+}
+
+func TestInviteService_DeleteByCourse(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		course string
+		error  bool
+	}{
+		{"success", courseID, false},
+		{"error", codeID, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := &mock.InviteRepository{DeleteByCourseFn: func(course string) error {
+				if course != courseID {
+					return errors.New("error deleting invites")
+				}
+				return nil
+			}}
+
+			err := newService(r, nil).DeleteByCourse(v.course)
+			assert.True(t, r.DeleteByCourseFnInvoked, "DeleteByCourse was not invoked")
+			assert.Equal(t, v.error, err != nil, "error does not match")
+		})
+	}
+}
@@ -0,0 +1,750 @@
+package materialService
+
+import (
+	"archive/zip"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type MaterialService struct {
+	fr eduboard.MaterialFolderRepository
+	r  eduboard.MaterialRepository
+	ur eduboard.UploadRepository
+	b  eduboard.BlobStore
+	cf eduboard.CourseOneFinder
+	// names is held while names and version numbers are checked and stored, such that two changes can not take the
+	// same name or version at once and nothing is stored into a folder while it is deleted. The unique indexes of the
+	// repositories also hold across processes, but only catch exact matches within folders or within materials.
+	names sync.Mutex
+}
+
+func New(folderRepository eduboard.MaterialFolderRepository, repository eduboard.MaterialRepository, uploadRepository eduboard.UploadRepository, store eduboard.BlobStore, courseFinder eduboard.CourseOneFinder) *MaterialService {
+	return &MaterialService{
+		fr: folderRepository,
+		r:  repository,
+		ur: uploadRepository,
+		b:  store,
+		cf: courseFinder,
+	}
+}
+
+// GetListing returns the folders and materials in a folder along with the folders above it.
+// Every member may read the library.
+func (mS *MaterialService) GetListing(courseID string, folderID string, userID string) (error, eduboard.MaterialListing) {
+	if err, _ := access.Member(mS.cf, courseID, userID); err != nil {
+		return err, eduboard.MaterialListing{}
+	}
+
+	listing := eduboard.MaterialListing{Path: []eduboard.MaterialFolder{}, Folders: []eduboard.MaterialFolder{}, Materials: []eduboard.Material{}}
+	var id bson.ObjectId
+	if folderID != "" {
+		err, folder := mS.folder(courseID, folderID)
+		if err != nil {
+			return err, eduboard.MaterialListing{}
+		}
+		listing.Folder = &folder
+		id = folder.ID
+	}
+
+	err, lib := mS.library(courseID)
+	if err != nil {
+		return err, eduboard.MaterialListing{}
+	}
+
+	if listing.Folder != nil {
+		ancestors := lib.ancestors(id)
+		for k := len(ancestors) - 1; k > 0; k-- {
+			listing.Path = append(listing.Path, lib.folders[ancestors[k]])
+		}
+	}
+	for _, f := range lib.sortedFolders() {
+		if f.ParentID == id {
+			listing.Folders = append(listing.Folders, f)
+		}
+	}
+	for _, m := range lib.materials {
+		if m.FolderID == id {
+			listing.Materials = append(listing.Materials, m)
+		}
+	}
+	return nil, listing
+}
+
+func (mS *MaterialService) GetFolder(courseID string, folderID string, userID string) (error, eduboard.MaterialFolder) {
+	if err, _ := access.Member(mS.cf, courseID, userID); err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+	return mS.folder(courseID, folderID)
+}
+
+// CreateFolder adds a folder to the library. Names must be unique within their folder.
+func (mS *MaterialService) CreateFolder(courseID string, userID string, folder eduboard.MaterialFolder) (error, eduboard.MaterialFolder) {
+	name, err := validName(folder.Name)
+	if err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+
+	err, course := access.Manager(mS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+	mS.names.Lock()
+	defer mS.names.Unlock()
+	err, lib := mS.library(courseID)
+	if err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+
+	if folder.ParentID != "" {
+		if _, ok := lib.folders[folder.ParentID]; !ok {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "course %s has no folder %s", courseID, folder.ParentID.Hex()), eduboard.MaterialFolder{}
+		}
+		if len(lib.ancestors(folder.ParentID)) >= eduboard.MaxMaterialFolderDepth {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "folders can not be nested deeper than %d", eduboard.MaxMaterialFolderDepth), eduboard.MaterialFolder{}
+		}
+	}
+	if err = lib.checkName(folder.ParentID, name, ""); err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+
+	folder = eduboard.MaterialFolder{
+		ID:        bson.NewObjectId(),
+		CourseID:  course.ID,
+		ParentID:  folder.ParentID,
+		Name:      name,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+	err = mS.fr.Insert(folder)
+	if err == eduboard.ErrDuplicate {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "there already is a folder called %s", name), eduboard.MaterialFolder{}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error storing folder of course %s", courseID), eduboard.MaterialFolder{}
+	}
+	return nil, folder
+}
+
+// UpdateFolder renames a folder or moves it along with its content. Folders can not be moved into themselves.
+func (mS *MaterialService) UpdateFolder(courseID string, folderID string, userID string, update eduboard.MaterialFolderUpdate) (error, eduboard.MaterialFolder) {
+	if err, _ := access.Manager(mS.cf, courseID, userID); err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+	mS.names.Lock()
+	defer mS.names.Unlock()
+	err, folder := mS.folder(courseID, folderID)
+	if err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+	err, lib := mS.library(courseID)
+	if err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+
+	name := folder.Name
+	if update.Name != nil {
+		if name, err = validName(*update.Name); err != nil {
+			return err, eduboard.MaterialFolder{}
+		}
+	}
+
+	parentID := folder.ParentID
+	if update.ParentID != nil {
+		if parentID, err = lib.folderID(courseID, *update.ParentID); err != nil {
+			return err, eduboard.MaterialFolder{}
+		}
+	}
+	if parentID != "" && parentID != folder.ParentID {
+		subtree := lib.subtree(folder.ID)
+		if _, ok := subtree[parentID]; ok {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "folder %s can not be moved into itself", folderID), eduboard.MaterialFolder{}
+		}
+		if len(lib.ancestors(parentID))+lib.height(folder.ID) > eduboard.MaxMaterialFolderDepth {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "folders can not be nested deeper than %d", eduboard.MaxMaterialFolderDepth), eduboard.MaterialFolder{}
+		}
+	}
+
+	if name == folder.Name && parentID == folder.ParentID {
+		return nil, folder
+	}
+	if err = lib.checkName(parentID, name, folder.ID); err != nil {
+		return err, eduboard.MaterialFolder{}
+	}
+
+	change := bson.M{"$set": bson.M{"name": name, "parentID": parentID}}
+	if parentID == "" {
+		change = bson.M{"$set": bson.M{"name": name}, "$unset": bson.M{"parentID": ""}}
+	}
+	err, folder = mS.fr.Update(folderID, change)
+	if err == eduboard.ErrDuplicate {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "there already is a folder called %s", name), eduboard.MaterialFolder{}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error updating folder %s", folderID), eduboard.MaterialFolder{}
+	}
+	return nil, folder
+}
+
+// DeleteFolder deletes a folder along with all folders and materials in it.
+func (mS *MaterialService) DeleteFolder(courseID string, folderID string, userID string) error {
+	if err, _ := access.Manager(mS.cf, courseID, userID); err != nil {
+		return err
+	}
+	mS.names.Lock()
+	defer mS.names.Unlock()
+	err, folder := mS.folder(courseID, folderID)
+	if err != nil {
+		return err
+	}
+	err, lib := mS.library(courseID)
+	if err != nil {
+		return err
+	}
+
+	ids := []bson.ObjectId{}
+	for id := range lib.subtree(folder.ID) {
+		ids = append(ids, id)
+	}
+
+	// The materials are only found through the folders they are in, so they have to go before the folders.
+	if err = mS.r.DeleteByFolders(ids); err != nil {
+		return errors.Wrapf(err, "error deleting materials in folder %s", folderID)
+	}
+	if err = mS.fr.Delete(ids); err != nil {
+		return errors.Wrapf(err, "error deleting folder %s", folderID)
+	}
+	return nil
+}
+
+func (mS *MaterialService) GetMaterial(courseID string, materialID string, userID string) (error, eduboard.Material) {
+	if err, _ := access.Member(mS.cf, courseID, userID); err != nil {
+		return err, eduboard.Material{}
+	}
+	return mS.material(courseID, materialID)
+}
+
+// CreateMaterial adds a material to the library, named after the uploaded file unless a name is given.
+// Uploads must belong to the course.
+func (mS *MaterialService) CreateMaterial(courseID string, userID string, material eduboard.Material, uploadID string) (error, eduboard.Material) {
+	description, err := validDescription(material.Description)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+
+	err, course := access.Manager(mS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+	err, version := mS.version(courseID, uploadID, userID, "")
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+
+	if material.Name == "" {
+		material.Name = version.Filename
+	}
+	name, err := validName(material.Name)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+
+	mS.names.Lock()
+	defer mS.names.Unlock()
+	err, lib := mS.library(courseID)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+	if material.FolderID != "" {
+		if _, ok := lib.folders[material.FolderID]; !ok {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "course %s has no folder %s", courseID, material.FolderID.Hex()), eduboard.Material{}
+		}
+	}
+	if err = lib.checkName(material.FolderID, name, ""); err != nil {
+		return err, eduboard.Material{}
+	}
+
+	now := time.Now()
+	version.Version = 1
+	material = eduboard.Material{
+		ID:          bson.NewObjectId(),
+		CourseID:    course.ID,
+		FolderID:    material.FolderID,
+		Name:        name,
+		Description: description,
+		Versions:    []eduboard.MaterialVersion{version},
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = mS.r.Insert(material)
+	if err == eduboard.ErrDuplicate {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "there already is a material called %s", name), eduboard.Material{}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error storing material of course %s", courseID), eduboard.Material{}
+	}
+	return nil, material
+}
+
+// UpdateMaterial changes the name or description of a material, or moves it to another folder.
+func (mS *MaterialService) UpdateMaterial(courseID string, materialID string, userID string, update eduboard.MaterialUpdate) (error, eduboard.Material) {
+	if err, _ := access.Manager(mS.cf, courseID, userID); err != nil {
+		return err, eduboard.Material{}
+	}
+	mS.names.Lock()
+	defer mS.names.Unlock()
+	err, material := mS.material(courseID, materialID)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+	err, lib := mS.library(courseID)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+
+	set := bson.M{}
+	change := bson.M{}
+	name := material.Name
+	if update.Name != nil {
+		if name, err = validName(*update.Name); err != nil {
+			return err, eduboard.Material{}
+		}
+		set["name"] = name
+	}
+	if update.Description != nil {
+		if set["description"], err = validDescription(*update.Description); err != nil {
+			return err, eduboard.Material{}
+		}
+	}
+
+	folderID := material.FolderID
+	if update.FolderID != nil {
+		if folderID, err = lib.folderID(courseID, *update.FolderID); err != nil {
+			return err, eduboard.Material{}
+		}
+		if folderID == "" {
+			change["$unset"] = bson.M{"folderID": ""}
+		} else {
+			set["folderID"] = folderID
+		}
+	}
+
+	if len(set) == 0 && len(change) == 0 {
+		return nil, material
+	}
+	if name != material.Name || folderID != material.FolderID {
+		if err = lib.checkName(folderID, name, material.ID); err != nil {
+			return err, eduboard.Material{}
+		}
+	}
+
+	set["updatedAt"] = time.Now()
+	change["$set"] = set
+	err, material = mS.r.Update(materialID, change)
+	if err == eduboard.ErrDuplicate {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "there already is a material called %s", name), eduboard.Material{}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error updating material %s", materialID), eduboard.Material{}
+	}
+	return nil, material
+}
+
+// AddVersion makes an upload the latest version of a material. Previous versions are kept.
+func (mS *MaterialService) AddVersion(courseID string, materialID string, userID string, uploadID string, comment string) (error, eduboard.Material) {
+	comment, err := validDescription(comment)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+
+	if err, _ := access.Manager(mS.cf, courseID, userID); err != nil {
+		return err, eduboard.Material{}
+	}
+	err, version := mS.version(courseID, uploadID, userID, comment)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+
+	// The material is read under the lock, such that its latest version is the one the new version follows.
+	mS.names.Lock()
+	defer mS.names.Unlock()
+	err, material := mS.material(courseID, materialID)
+	if err != nil {
+		return err, eduboard.Material{}
+	}
+	version.Version = material.Latest().Version + 1
+	err, material = mS.r.AddVersion(materialID, version)
+	if err != nil {
+		return errors.Wrapf(err, "error adding version to material %s", materialID), eduboard.Material{}
+	}
+	return nil, material
+}
+
+func (mS *MaterialService) DeleteMaterial(courseID string, materialID string, userID string) error {
+	if err, _ := access.Manager(mS.cf, courseID, userID); err != nil {
+		return err
+	}
+	if err, _ := mS.material(courseID, materialID); err != nil {
+		return err
+	}
+
+	if err := mS.r.Delete(materialID); err != nil {
+		return errors.Wrapf(err, "error deleting material %s", materialID)
+	}
+	return nil
+}
+
+// OpenMaterial returns a version of a material and its content, the latest one if version is 0.
+func (mS *MaterialService) OpenMaterial(courseID string, materialID string, userID string, version int) (error, eduboard.MaterialVersion, io.ReadCloser) {
+	if err, _ := access.Member(mS.cf, courseID, userID); err != nil {
+		return err, eduboard.MaterialVersion{}, nil
+	}
+	err, material := mS.material(courseID, materialID)
+	if err != nil {
+		return err, eduboard.MaterialVersion{}, nil
+	}
+
+	v := material.Latest()
+	if version != 0 {
+		if version < 0 || version > len(material.Versions) {
+			return errors.Wrapf(eduboard.ErrNotFound, "material %s has no version %d", materialID, version), eduboard.MaterialVersion{}, nil
+		}
+		v = material.Versions[version-1]
+	}
+
+	content, err := mS.b.Get(eduboard.Upload{ID: v.UploadID}.Key())
+	if err != nil {
+		return errors.Wrapf(err, "error reading version %d of material %s", v.Version, materialID), eduboard.MaterialVersion{}, nil
+	}
+	return nil, v, content
+}
+
+// ExportFolder writes the latest versions of all materials in a folder and its subfolders to w as a zip archive.
+// Paths in the archive are relative to the folder. The archive is built in a temporary file first, such that nothing
+// is written if the folder can not be exported.
+func (mS *MaterialService) ExportFolder(courseID string, folderID string, userID string, w io.Writer) error {
+	if err, _ := access.Member(mS.cf, courseID, userID); err != nil {
+		return err
+	}
+	var root bson.ObjectId
+	if folderID != "" {
+		err, folder := mS.folder(courseID, folderID)
+		if err != nil {
+			return err
+		}
+		root = folder.ID
+	}
+	err, lib := mS.library(courseID)
+	if err != nil {
+		return err
+	}
+
+	subtree := lib.subtree(root)
+	materials := []eduboard.Material{}
+	var size int64
+	for _, m := range lib.materials {
+		if _, ok := subtree[m.FolderID]; ok {
+			materials = append(materials, m)
+			size += m.Latest().Size
+		}
+	}
+	if size > eduboard.MaxMaterialExportSize {
+		return errors.Wrapf(eduboard.ErrTooLarge, "materials of folder %s have %d bytes", folderID, size)
+	}
+
+	tmp, err := ioutil.TempFile("", "materials")
+	if err != nil {
+		return errors.Wrap(err, "error creating archive")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	// Names are unique within a folder, but materials get the extension of their file added. Materials are renamed
+	// if their entry clashes with another one anyway.
+	entries := map[string]bool{}
+	for _, f := range lib.sortedFolders() {
+		if _, ok := subtree[f.ID]; !ok || f.ID == root {
+			continue
+		}
+		name := lib.path(f.ID, root)
+		entries[strings.ToLower(name)] = true
+		if _, err = zw.Create(name + "/"); err != nil {
+			return errors.Wrapf(err, "error writing folder %s", f.ID.Hex())
+		}
+	}
+	for _, m := range materials {
+		if err = mS.export(zw, m, unique(entries, path.Join(lib.path(m.FolderID, root), filename(m)))); err != nil {
+			return err
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return errors.Wrap(err, "error writing archive")
+	}
+
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "error reading archive")
+	}
+	_, err = io.Copy(w, tmp)
+	return err
+}
+
+// DeleteByCourse deletes all folders and materials of a course. Their files are deleted along with the uploads.
+func (mS *MaterialService) DeleteByCourse(courseID string) error {
+	if err := mS.r.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting materials of course %s", courseID)
+	}
+	if err := mS.fr.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting material folders of course %s", courseID)
+	}
+	return nil
+}
+
+// export writes the latest version of a material to zw.
+func (mS *MaterialService) export(zw *zip.Writer, material eduboard.Material, name string) error {
+	v := material.Latest()
+	content, err := mS.b.Get(eduboard.Upload{ID: v.UploadID}.Key())
+	if err != nil {
+		return errors.Wrapf(err, "error reading material %s", material.ID.Hex())
+	}
+	defer content.Close()
+
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: v.UploadedAt})
+	if err != nil {
+		return errors.Wrapf(err, "error writing material %s", material.ID.Hex())
+	}
+	if _, err = io.Copy(f, content); err != nil {
+		return errors.Wrapf(err, "error writing material %s", material.ID.Hex())
+	}
+	return nil
+}
+
+// filename returns the name of a material with the extension of its latest file, unless the name has one already.
+func filename(material eduboard.Material) string {
+	if path.Ext(material.Name) != "" {
+		return material.Name
+	}
+	return material.Name + path.Ext(material.Latest().Filename)
+}
+
+// unique returns name, or name with a number added if an entry of that name was taken already. Entries are compared
+// case-insensitively, like names in the library.
+func unique(taken map[string]bool, name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	result := name
+	for n := 2; taken[strings.ToLower(result)]; n++ {
+		result = base + " (" + strconv.Itoa(n) + ")" + ext
+	}
+	taken[strings.ToLower(result)] = true
+	return result
+}
+
+// version returns a new version made from an upload of a course.
+func (mS *MaterialService) version(courseID string, uploadID string, userID string, comment string) (error, eduboard.MaterialVersion) {
+	err, upload := mS.ur.Find(uploadID)
+	if err != nil {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "upload %s does not exist", uploadID), eduboard.MaterialVersion{}
+	}
+	if upload.CourseID != courseID {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "upload %s does not belong to course %s", uploadID, courseID), eduboard.MaterialVersion{}
+	}
+	if upload.Private {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "upload %s is private", uploadID), eduboard.MaterialVersion{}
+	}
+
+	return nil, eduboard.MaterialVersion{
+		UploadID:    upload.ID,
+		Filename:    upload.Filename,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		Comment:     comment,
+		UploadedBy:  userID,
+		UploadedAt:  time.Now(),
+	}
+}
+
+// library holds all folders and materials of a course.
+type library struct {
+	folders   map[bson.ObjectId]eduboard.MaterialFolder
+	materials []eduboard.Material
+}
+
+func (mS *MaterialService) library(courseID string) (error, library) {
+	err, folders := mS.fr.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding folders of course %s", courseID), library{}
+	}
+	err, materials := mS.r.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding materials of course %s", courseID), library{}
+	}
+
+	lib := library{folders: map[bson.ObjectId]eduboard.MaterialFolder{}, materials: materials}
+	for _, f := range folders {
+		lib.folders[f.ID] = f
+	}
+	sort.SliceStable(lib.materials, func(i, j int) bool {
+		return strings.ToLower(lib.materials[i].Name) < strings.ToLower(lib.materials[j].Name)
+	})
+	return nil, lib
+}
+
+// sortedFolders returns all folders sorted by name.
+func (l library) sortedFolders() []eduboard.MaterialFolder {
+	folders := make([]eduboard.MaterialFolder, 0, len(l.folders))
+	for _, f := range l.folders {
+		folders = append(folders, f)
+	}
+	sort.Slice(folders, func(i, j int) bool {
+		a, b := strings.ToLower(folders[i].Name), strings.ToLower(folders[j].Name)
+		if a == b {
+			return folders[i].ID < folders[j].ID
+		}
+		return a < b
+	})
+	return folders
+}
+
+// ancestors returns the ID of a folder followed by the IDs of the folders above it, innermost first.
+func (l library) ancestors(id bson.ObjectId) []bson.ObjectId {
+	result := []bson.ObjectId{}
+	// Stored folders never form a cycle, the limit only guards against corrupted data.
+	for id != "" && len(result) <= len(l.folders) {
+		result = append(result, id)
+		id = l.folders[id].ParentID
+	}
+	return result
+}
+
+// subtree returns the IDs of a folder and all folders in it. The subtree of the empty ID is the whole library.
+func (l library) subtree(id bson.ObjectId) map[bson.ObjectId]bool {
+	result := map[bson.ObjectId]bool{id: true}
+	for fid := range l.folders {
+		for _, a := range l.ancestors(fid) {
+			if a == id {
+				result[fid] = true
+				break
+			}
+		}
+	}
+	if id == "" {
+		for fid := range l.folders {
+			result[fid] = true
+		}
+	}
+	return result
+}
+
+// height returns the number of nested folders from a folder down to its deepest subfolder, including itself.
+func (l library) height(id bson.ObjectId) int {
+	depth := len(l.ancestors(id))
+	height := 1
+	for fid := range l.subtree(id) {
+		if h := len(l.ancestors(fid)) - depth + 1; h > height {
+			height = h
+		}
+	}
+	return height
+}
+
+// path returns the path of a folder relative to root, joining the names of the folders in between.
+func (l library) path(id bson.ObjectId, root bson.ObjectId) string {
+	names := []string{}
+	for _, a := range l.ancestors(id) {
+		if a == root {
+			break
+		}
+		names = append([]string{l.folders[a].Name}, names...)
+	}
+	return path.Join(names...)
+}
+
+// folderID returns the ID of a folder of the library, or the empty ID if id is empty.
+func (l library) folderID(courseID string, id string) (bson.ObjectId, error) {
+	if id == "" {
+		return "", nil
+	}
+	if !bson.IsObjectIdHex(id) {
+		return "", errors.Wrapf(eduboard.ErrInvalidInput, "invalid folder id %s", id)
+	}
+	if _, ok := l.folders[bson.ObjectIdHex(id)]; !ok {
+		return "", errors.Wrapf(eduboard.ErrInvalidInput, "course %s has no folder %s", courseID, id)
+	}
+	return bson.ObjectIdHex(id), nil
+}
+
+// checkName returns an error if a folder or material other than except in the folder parentID is called name.
+// Names are compared case-insensitively, so that archives can be extracted on every file system.
+func (l library) checkName(parentID bson.ObjectId, name string, except bson.ObjectId) error {
+	for _, f := range l.folders {
+		if f.ParentID == parentID && f.ID != except && strings.EqualFold(f.Name, name) {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "there already is a folder called %s", name)
+		}
+	}
+	for _, m := range l.materials {
+		if m.FolderID == parentID && m.ID != except && strings.EqualFold(m.Name, name) {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "there already is a material called %s", name)
+		}
+	}
+	return nil
+}
+
+// folder returns a folder of a course.
+func (mS *MaterialService) folder(courseID string, folderID string) (error, eduboard.MaterialFolder) {
+	err, folder := mS.fr.FindOneByID(folderID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding folder %s", folderID), eduboard.MaterialFolder{}
+	}
+	if folder.CourseID.Hex() != courseID {
+		return errors.Wrapf(eduboard.ErrNotFound, "folder %s does not belong to course %s", folderID, courseID), eduboard.MaterialFolder{}
+	}
+	return nil, folder
+}
+
+// material returns a material of a course.
+func (mS *MaterialService) material(courseID string, materialID string) (error, eduboard.Material) {
+	err, material := mS.r.FindOneByID(materialID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding material %s", materialID), eduboard.Material{}
+	}
+	if material.CourseID.Hex() != courseID {
+		return errors.Wrapf(eduboard.ErrNotFound, "material %s does not belong to course %s", materialID, courseID), eduboard.Material{}
+	}
+	return nil, material
+}
+
+// validName returns the trimmed name of a folder or material. Names must be usable as file names.
+func validName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." {
+		return "", errors.Wrapf(eduboard.ErrInvalidInput, "invalid name %q", name)
+	}
+	if strings.ContainsAny(name, "/\\") {
+		return "", errors.Wrapf(eduboard.ErrInvalidInput, "name %q contains a slash", name)
+	}
+	if utf8.RuneCountInString(name) > eduboard.MaxMaterialNameLength {
+		return "", errors.Wrapf(eduboard.ErrInvalidInput, "name is longer than %d characters", eduboard.MaxMaterialNameLength)
+	}
+	return name, nil
+}
+
+func validDescription(description string) (string, error) {
+	description = strings.TrimSpace(description)
+	if utf8.RuneCountInString(description) > eduboard.MaxMaterialDescriptionLength {
+		return "", errors.Wrapf(eduboard.ErrInvalidInput, "description is longer than %d characters", eduboard.MaxMaterialDescriptionLength)
+	}
+	return description, nil
+}
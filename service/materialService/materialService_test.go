@@ -0,0 +1,625 @@
+package materialService
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	courseID    = "5b23bbdc2bfa844c41a9f134"
+	otherID     = "5b23bbdc2bfa844c41a9f135"
+	archivedID  = "5b23bbdc2bfa844c41a9f136"
+	slidesID    = "5b23bbdc2bfa844c41a9f140"
+	weekID      = "5b23bbdc2bfa844c41a9f141"
+	emptyID     = "5b23bbdc2bfa844c41a9f142"
+	elsewhereID = "5b23bbdc2bfa844c41a9f143"
+	syllabusID  = "5b23bbdc2bfa844c41a9f150"
+	introID     = "5b23bbdc2bfa844c41a9f151"
+	strangerID  = "5b23bbdc2bfa844c41a9f152"
+	uploadV1    = "5b23bbdc2bfa844c41a9f160"
+	uploadV2    = "5b23bbdc2bfa844c41a9f161"
+	uploadPDF   = "5b23bbdc2bfa844c41a9f162"
+)
+
+var members = []eduboard.Member{
+	{UserID: "owner", Role: eduboard.RoleOwner},
+	{UserID: "teacher", Role: eduboard.RoleTeacher},
+	{UserID: "student", Role: eduboard.RoleStudent},
+}
+
+// newFolders returns a repository holding the folders "Slides", "Slides/Week 1" and "Empty" of the course
+// and one folder of another course.
+func newFolders() *mock.MaterialFolderRepository {
+	folders := []eduboard.MaterialFolder{
+		{ID: bson.ObjectIdHex(slidesID), CourseID: bson.ObjectIdHex(courseID), Name: "Slides"},
+		{ID: bson.ObjectIdHex(weekID), CourseID: bson.ObjectIdHex(courseID), ParentID: bson.ObjectIdHex(slidesID), Name: "Week 1"},
+		{ID: bson.ObjectIdHex(emptyID), CourseID: bson.ObjectIdHex(courseID), Name: "Empty"},
+		{ID: bson.ObjectIdHex(elsewhereID), CourseID: bson.ObjectIdHex(otherID), Name: "Slides"},
+	}
+
+	fr := &mock.MaterialFolderRepository{}
+	fr.InsertFn = func(folder eduboard.MaterialFolder) error { return nil }
+	fr.FindOneByIDFn = func(id string) (error, eduboard.MaterialFolder) {
+		for _, f := range folders {
+			if f.ID.Hex() == id {
+				return nil, f
+			}
+		}
+		return errors.New("not found"), eduboard.MaterialFolder{}
+	}
+	fr.FindByCourseFn = func(course string) (error, []eduboard.MaterialFolder) {
+		result := []eduboard.MaterialFolder{}
+		for _, f := range folders {
+			if f.CourseID.Hex() == course {
+				result = append(result, f)
+			}
+		}
+		return nil, result
+	}
+	fr.UpdateFn = func(id string, update bson.M) (error, eduboard.MaterialFolder) {
+		return nil, eduboard.MaterialFolder{ID: bson.ObjectIdHex(id)}
+	}
+	fr.DeleteFn = func(ids []bson.ObjectId) error { return nil }
+	return fr
+}
+
+// newMaterials returns a repository holding "syllabus.pdf" at the top of the library of the course,
+// "Intro" with two versions in "Slides/Week 1" and one material of another course.
+func newMaterials() *mock.MaterialRepository {
+	modified := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	materials := []eduboard.Material{
+		{ID: bson.ObjectIdHex(syllabusID), CourseID: bson.ObjectIdHex(courseID), Name: "syllabus.pdf", Versions: []eduboard.MaterialVersion{
+			{Version: 1, UploadID: bson.ObjectIdHex(uploadPDF), Filename: "syllabus.pdf", UploadedAt: modified},
+		}},
+		{ID: bson.ObjectIdHex(introID), CourseID: bson.ObjectIdHex(courseID), FolderID: bson.ObjectIdHex(weekID), Name: "Intro", Versions: []eduboard.MaterialVersion{
+			{Version: 1, UploadID: bson.ObjectIdHex(uploadV1), Filename: "intro.ppt", UploadedAt: modified},
+			{Version: 2, UploadID: bson.ObjectIdHex(uploadV2), Filename: "intro.pdf", UploadedAt: modified},
+		}},
+		{ID: bson.ObjectIdHex(strangerID), CourseID: bson.ObjectIdHex(otherID), Name: "syllabus.pdf", Versions: []eduboard.MaterialVersion{
+			{Version: 1, UploadID: bson.ObjectIdHex(uploadPDF), Filename: "syllabus.pdf"},
+		}},
+	}
+
+	mr := &mock.MaterialRepository{}
+	mr.InsertFn = func(material eduboard.Material) error { return nil }
+	mr.FindOneByIDFn = func(id string) (error, eduboard.Material) {
+		for _, m := range materials {
+			if m.ID.Hex() == id {
+				return nil, m
+			}
+		}
+		return errors.New("not found"), eduboard.Material{}
+	}
+	mr.FindByCourseFn = func(course string) (error, []eduboard.Material) {
+		result := []eduboard.Material{}
+		for _, m := range materials {
+			if m.CourseID.Hex() == course {
+				result = append(result, m)
+			}
+		}
+		return nil, result
+	}
+	mr.UpdateFn = func(id string, update bson.M) (error, eduboard.Material) {
+		return nil, eduboard.Material{ID: bson.ObjectIdHex(id)}
+	}
+	mr.AddVersionFn = func(id string, version eduboard.MaterialVersion) (error, eduboard.Material) {
+		return nil, eduboard.Material{ID: bson.ObjectIdHex(id), Versions: []eduboard.MaterialVersion{version}}
+	}
+	mr.DeleteFn = func(id string) error { return nil }
+	mr.DeleteByFoldersFn = func(folderIDs []bson.ObjectId) error { return nil }
+	return mr
+}
+
+// newUploads returns a repository holding an upload to the course, a private one of a student and one to another course.
+func newUploads() *mock.UploadRepository {
+	uploads := map[string]eduboard.Upload{
+		"notes":     {ID: bson.ObjectIdHex(uploadV1), CourseID: courseID, Filename: "notes.pdf", ContentType: "application/pdf", Size: 42},
+		"elsewhere": {ID: bson.ObjectIdHex(uploadV2), CourseID: otherID, Filename: "notes.pdf"},
+		"homework":  {ID: bson.ObjectIdHex(uploadV2), CourseID: courseID, Filename: "homework.pdf", Private: true},
+	}
+
+	ur := &mock.UploadRepository{}
+	ur.FindFn = func(id string) (error, eduboard.Upload) {
+		if u, ok := uploads[id]; ok {
+			return nil, u
+		}
+		return errors.New("not found"), eduboard.Upload{}
+	}
+	return ur
+}
+
+// newStore returns a store whose content of every key is the key itself.
+func newStore() *mock.BlobStore {
+	b := &mock.BlobStore{}
+	b.GetFn = func(key string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(key)), nil
+	}
+	return b
+}
+
+func newService() *MaterialService {
+	return New(newFolders(), newMaterials(), newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID))
+}
+
+func TestNew(t *testing.T) {
+	fr := newFolders()
+	mr := newMaterials()
+	ur := newUploads()
+	b := newStore()
+	cf := mock.CourseFinder(members, archivedID, courseID, otherID)
+	s := New(fr, mr, ur, b, cf)
+	assert.Equal(t, fr, s.fr, "folder repository does not match")
+	assert.Equal(t, mr, s.r, "material repository does not match")
+	assert.Equal(t, ur, s.ur, "upload repository does not match")
+	assert.Equal(t, b, s.b, "blob store does not match")
+	assert.Equal(t, cf, s.cf, "course finder does not match")
+}
+
+func TestMaterialService_GetListing(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		folder    string
+		user      string
+		err       error
+		path      []string
+		folders   []string
+		materials []string
+	}{
+		{"top", "", "student", nil, []string{}, []string{"Empty", "Slides"}, []string{"syllabus.pdf"}},
+		{"nested", weekID, "student", nil, []string{"Slides"}, []string{}, []string{"Intro"}},
+		{"parent", slidesID, "teacher", nil, []string{}, []string{"Week 1"}, []string{}},
+		{"no member", "", "stranger", eduboard.ErrForbidden, nil, nil, nil},
+	}
+
+	names := func(folders []eduboard.MaterialFolder) []string {
+		result := []string{}
+		for _, f := range folders {
+			result = append(result, f.Name)
+		}
+		return result
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err, listing := newService().GetListing(courseID, v.folder, v.user)
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.folder != "", listing.Folder != nil, "folder does not match")
+			assert.Equal(t, v.path, names(listing.Path), "path does not match")
+			assert.Equal(t, v.folders, names(listing.Folders), "folders do not match")
+			materials := []string{}
+			for _, m := range listing.Materials {
+				materials = append(materials, m.Name)
+			}
+			assert.Equal(t, v.materials, materials, "materials do not match")
+		})
+	}
+
+	err, _ := newService().GetListing(courseID, elsewhereID, "student")
+	assert.NotNil(t, err, "listed a folder of another course")
+}
+
+func TestMaterialService_CreateFolder(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		course string
+		user   string
+		folder eduboard.MaterialFolder
+		err    error
+	}{
+		{"top", courseID, "teacher", eduboard.MaterialFolder{Name: " Exercises "}, nil},
+		{"nested", courseID, "owner", eduboard.MaterialFolder{Name: "Week 2", ParentID: bson.ObjectIdHex(slidesID)}, nil},
+		{"student", courseID, "student", eduboard.MaterialFolder{Name: "Exercises"}, eduboard.ErrForbidden},
+		{"archived", archivedID, "teacher", eduboard.MaterialFolder{Name: "Exercises"}, eduboard.ErrArchived},
+		{"duplicate", courseID, "teacher", eduboard.MaterialFolder{Name: "slides"}, eduboard.ErrInvalidInput},
+		{"duplicate material", courseID, "teacher", eduboard.MaterialFolder{Name: "Syllabus.pdf"}, eduboard.ErrInvalidInput},
+		{"no name", courseID, "teacher", eduboard.MaterialFolder{Name: " "}, eduboard.ErrInvalidInput},
+		{"slash", courseID, "teacher", eduboard.MaterialFolder{Name: "a/b"}, eduboard.ErrInvalidInput},
+		{"dots", courseID, "teacher", eduboard.MaterialFolder{Name: ".."}, eduboard.ErrInvalidInput},
+		{"too long", courseID, "teacher", eduboard.MaterialFolder{Name: strings.Repeat("a", eduboard.MaxMaterialNameLength+1)}, eduboard.ErrInvalidInput},
+		{"foreign parent", courseID, "teacher", eduboard.MaterialFolder{Name: "Week 2", ParentID: bson.ObjectIdHex(elsewhereID)}, eduboard.ErrInvalidInput},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			fr := newFolders()
+			err, folder := New(fr, newMaterials(), newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID)).CreateFolder(v.course, v.user, v.folder)
+			assert.Equal(t, v.err == nil, fr.InsertFnInvoked, "Insert was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.True(t, folder.ID.Valid(), "folder has no ID")
+			assert.Equal(t, strings.TrimSpace(v.folder.Name), folder.Name, "name does not match")
+			assert.Equal(t, v.folder.ParentID, folder.ParentID, "parent does not match")
+			assert.Equal(t, v.user, folder.CreatedBy, "creator does not match")
+		})
+	}
+}
+
+func TestMaterialService_CreateFolder_Duplicate(t *testing.T) {
+	fr := newFolders()
+	fr.InsertFn = func(folder eduboard.MaterialFolder) error { return eduboard.ErrDuplicate }
+	mr := newMaterials()
+	mr.InsertFn = func(material eduboard.Material) error { return eduboard.ErrDuplicate }
+	s := New(fr, mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID))
+
+	err, _ := s.CreateFolder(courseID, "teacher", eduboard.MaterialFolder{Name: "Exercises"})
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "folder stored concurrently is not reported as invalid input")
+	err, _ = s.CreateMaterial(courseID, "teacher", eduboard.Material{}, "notes")
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "material stored concurrently is not reported as invalid input")
+}
+
+func TestMaterialService_CreateFolder_Depth(t *testing.T) {
+	folders := []eduboard.MaterialFolder{}
+	var parent bson.ObjectId
+	for k := 0; k < eduboard.MaxMaterialFolderDepth; k++ {
+		f := eduboard.MaterialFolder{ID: bson.NewObjectId(), CourseID: bson.ObjectIdHex(courseID), ParentID: parent, Name: "Level"}
+		folders = append(folders, f)
+		parent = f.ID
+	}
+	fr := newFolders()
+	fr.FindByCourseFn = func(courseID string) (error, []eduboard.MaterialFolder) {
+		return nil, folders
+	}
+	s := New(fr, newMaterials(), newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID))
+
+	err, _ := s.CreateFolder(courseID, "teacher", eduboard.MaterialFolder{Name: "Deeper", ParentID: folders[len(folders)-2].ID})
+	assert.Nil(t, err, "could not create a folder at the deepest level")
+	err, _ = s.CreateFolder(courseID, "teacher", eduboard.MaterialFolder{Name: "Deeper", ParentID: parent})
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "created a folder below the deepest level")
+}
+
+func TestMaterialService_UpdateFolder(t *testing.T) {
+	str := func(s string) *string { return &s }
+	var testCases = []struct {
+		name   string
+		folder string
+		user   string
+		update eduboard.MaterialFolderUpdate
+		err    error
+		change bson.M
+	}{
+		{"rename", slidesID, "teacher", eduboard.MaterialFolderUpdate{Name: str("Lectures")}, nil, bson.M{"$set": bson.M{"name": "Lectures"}, "$unset": bson.M{"parentID": ""}}},
+		{"move", emptyID, "teacher", eduboard.MaterialFolderUpdate{ParentID: str(weekID)}, nil, bson.M{"$set": bson.M{"name": "Empty", "parentID": bson.ObjectIdHex(weekID)}}},
+		{"move to top", weekID, "teacher", eduboard.MaterialFolderUpdate{ParentID: str("")}, nil, bson.M{"$set": bson.M{"name": "Week 1"}, "$unset": bson.M{"parentID": ""}}},
+		{"unchanged", slidesID, "teacher", eduboard.MaterialFolderUpdate{Name: str("Slides")}, nil, nil},
+		{"into itself", slidesID, "teacher", eduboard.MaterialFolderUpdate{ParentID: str(slidesID)}, eduboard.ErrInvalidInput, nil},
+		{"into subfolder", slidesID, "teacher", eduboard.MaterialFolderUpdate{ParentID: str(weekID)}, eduboard.ErrInvalidInput, nil},
+		{"duplicate", emptyID, "teacher", eduboard.MaterialFolderUpdate{Name: str("SLIDES")}, eduboard.ErrInvalidInput, nil},
+		{"foreign parent", emptyID, "teacher", eduboard.MaterialFolderUpdate{ParentID: str(elsewhereID)}, eduboard.ErrInvalidInput, nil},
+		{"invalid parent", emptyID, "teacher", eduboard.MaterialFolderUpdate{ParentID: str("week")}, eduboard.ErrInvalidInput, nil},
+		{"student", slidesID, "student", eduboard.MaterialFolderUpdate{Name: str("Lectures")}, eduboard.ErrForbidden, nil},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			fr := newFolders()
+			fr.UpdateFn = func(id string, update bson.M) (error, eduboard.MaterialFolder) {
+				assert.Equal(t, v.folder, id, "folder does not match")
+				assert.Equal(t, v.change, update, "update does not match")
+				return nil, eduboard.MaterialFolder{ID: bson.ObjectIdHex(id)}
+			}
+
+			err, _ := New(fr, newMaterials(), newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID)).UpdateFolder(courseID, v.folder, v.user, v.update)
+			assert.Equal(t, v.change != nil, fr.UpdateFnInvoked, "Update was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+		})
+	}
+
+	err, _ := newService().UpdateFolder(courseID, elsewhereID, "teacher", eduboard.MaterialFolderUpdate{Name: str("Lectures")})
+	assert.NotNil(t, err, "updated a folder of another course")
+}
+
+func TestMaterialService_DeleteFolder(t *testing.T) {
+	fr := newFolders()
+	mr := newMaterials()
+	var deleted []string
+	fr.DeleteFn = func(ids []bson.ObjectId) error {
+		for _, id := range ids {
+			deleted = append(deleted, id.Hex())
+		}
+		return nil
+	}
+	mr.DeleteByFoldersFn = func(folderIDs []bson.ObjectId) error {
+		assert.Len(t, folderIDs, 2, "materials of unexpected folders were deleted")
+		return nil
+	}
+	s := New(fr, mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID))
+
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(s.DeleteFolder(courseID, slidesID, "student")), "student deleted a folder")
+	assert.False(t, fr.DeleteFnInvoked, "Delete was invoked for a student")
+
+	assert.Nil(t, s.DeleteFolder(courseID, slidesID, "teacher"), "returned error when it shouldn't")
+	assert.True(t, mr.DeleteByFoldersFnInvoked, "DeleteByFolders was not invoked")
+	sort.Strings(deleted)
+	assert.Equal(t, []string{slidesID, weekID}, deleted, "deleted folders do not match")
+}
+
+func TestMaterialService_CreateMaterial(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		course   string
+		user     string
+		material eduboard.Material
+		upload   string
+		err      error
+		expected string
+	}{
+		{"upload name", courseID, "teacher", eduboard.Material{}, "notes", nil, "notes.pdf"},
+		{"own name", courseID, "teacher", eduboard.Material{Name: "Notes", FolderID: bson.ObjectIdHex(weekID), Description: " Read first "}, "notes", nil, "Notes"},
+		{"student", courseID, "student", eduboard.Material{}, "notes", eduboard.ErrForbidden, ""},
+		{"archived", archivedID, "teacher", eduboard.Material{}, "notes", eduboard.ErrArchived, ""},
+		{"foreign upload", courseID, "teacher", eduboard.Material{}, "elsewhere", eduboard.ErrInvalidInput, ""},
+		{"missing upload", courseID, "teacher", eduboard.Material{}, "missing", eduboard.ErrInvalidInput, ""},
+		{"duplicate", courseID, "teacher", eduboard.Material{Name: "SYLLABUS.PDF"}, "notes", eduboard.ErrInvalidInput, ""},
+		{"foreign folder", courseID, "teacher", eduboard.Material{FolderID: bson.ObjectIdHex(elsewhereID)}, "notes", eduboard.ErrInvalidInput, ""},
+		{"long description", courseID, "teacher", eduboard.Material{Description: strings.Repeat("a", eduboard.MaxMaterialDescriptionLength+1)}, "notes", eduboard.ErrInvalidInput, ""},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mr := newMaterials()
+			err, material := New(newFolders(), mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID)).CreateMaterial(v.course, v.user, v.material, v.upload)
+			assert.Equal(t, v.err == nil, mr.InsertFnInvoked, "Insert was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.expected, material.Name, "name does not match")
+			assert.Equal(t, strings.TrimSpace(v.material.Description), material.Description, "description does not match")
+			assert.Equal(t, v.material.FolderID, material.FolderID, "folder does not match")
+			assert.Len(t, material.Versions, 1, "unexpected number of versions")
+			version := material.Latest()
+			assert.Equal(t, 1, version.Version, "version does not match")
+			assert.Equal(t, bson.ObjectIdHex(uploadV1), version.UploadID, "upload does not match")
+			assert.Equal(t, "notes.pdf", version.Filename, "filename does not match")
+			assert.Equal(t, int64(42), version.Size, "size does not match")
+			assert.Equal(t, v.user, version.UploadedBy, "uploader does not match")
+		})
+	}
+}
+
+func TestMaterialService_UpdateMaterial(t *testing.T) {
+	str := func(s string) *string { return &s }
+	var testCases = []struct {
+		name     string
+		material string
+		user     string
+		update   eduboard.MaterialUpdate
+		err      error
+		invoked  bool
+	}{
+		{"rename", introID, "teacher", eduboard.MaterialUpdate{Name: str("Introduction")}, nil, true},
+		{"move", syllabusID, "teacher", eduboard.MaterialUpdate{FolderID: str(slidesID)}, nil, true},
+		{"unchanged", introID, "teacher", eduboard.MaterialUpdate{}, nil, false},
+		{"duplicate", introID, "teacher", eduboard.MaterialUpdate{FolderID: str(""), Name: str("syllabus.PDF")}, eduboard.ErrInvalidInput, false},
+		{"foreign folder", introID, "teacher", eduboard.MaterialUpdate{FolderID: str(elsewhereID)}, eduboard.ErrInvalidInput, false},
+		{"student", introID, "student", eduboard.MaterialUpdate{Name: str("Introduction")}, eduboard.ErrForbidden, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			mr := newMaterials()
+			err, _ := New(newFolders(), mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID)).UpdateMaterial(courseID, v.material, v.user, v.update)
+			assert.Equal(t, v.invoked, mr.UpdateFnInvoked, "Update was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+		})
+	}
+
+	err, _ := newService().UpdateMaterial(courseID, strangerID, "teacher", eduboard.MaterialUpdate{Name: str("Introduction")})
+	assert.NotNil(t, err, "updated a material of another course")
+}
+
+func TestMaterialService_AddVersion(t *testing.T) {
+	mr := newMaterials()
+	s := New(newFolders(), mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID))
+
+	err, _ := s.AddVersion(courseID, introID, "student", "notes", "")
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(err), "student added a version")
+	err, _ = s.AddVersion(courseID, introID, "teacher", "elsewhere", "")
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "added an upload of another course")
+	err, _ = s.AddVersion(courseID, introID, "teacher", "homework", "")
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "added a private upload")
+	assert.False(t, mr.AddVersionFnInvoked, "AddVersion was invoked for an invalid version")
+
+	err, material := s.AddVersion(courseID, introID, "teacher", "notes", " Fixed typos ")
+	assert.Nil(t, err, "returned error when it shouldn't")
+	version := material.Latest()
+	assert.Equal(t, 3, version.Version, "version does not match")
+	assert.Equal(t, "Fixed typos", version.Comment, "comment does not match")
+	assert.Equal(t, "notes.pdf", version.Filename, "filename does not match")
+}
+
+func TestMaterialService_DeleteMaterial(t *testing.T) {
+	mr := newMaterials()
+	s := New(newFolders(), mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID))
+
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(s.DeleteMaterial(courseID, introID, "student")), "student deleted a material")
+	assert.NotNil(t, s.DeleteMaterial(courseID, strangerID, "teacher"), "deleted a material of another course")
+	assert.False(t, mr.DeleteFnInvoked, "Delete was invoked for an invalid deletion")
+
+	assert.Nil(t, s.DeleteMaterial(courseID, introID, "teacher"), "returned error when it shouldn't")
+	assert.True(t, mr.DeleteFnInvoked, "Delete was not invoked")
+}
+
+func TestMaterialService_OpenMaterial(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		user    string
+		version int
+		err     bool
+		content string
+	}{
+		{"latest", "student", 0, false, uploadV2},
+		{"first", "student", 1, false, uploadV1},
+		{"missing", "student", 3, true, ""},
+		{"negative", "student", -1, true, ""},
+		{"no member", "stranger", 0, true, ""},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err, version, content := newService().OpenMaterial(courseID, introID, v.user, v.version)
+			if v.err {
+				assert.NotNil(t, err, "returned no error when it should")
+				if v.name == "missing" {
+					assert.Equal(t, eduboard.ErrNotFound, errors.Cause(err), "missing version is not reported as not found")
+				}
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			defer content.Close()
+			assert.Equal(t, v.content, version.UploadID.Hex(), "version does not match")
+			data, _ := ioutil.ReadAll(content)
+			assert.Equal(t, v.content, string(data), "content does not match")
+		})
+	}
+}
+
+func TestMaterialService_ExportFolder(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		folder string
+		user   string
+		err    bool
+		files  map[string]string
+	}{
+		{"library", "", "student", false, map[string]string{
+			"Empty/":                  "",
+			"Slides/":                 "",
+			"Slides/Week 1/":          "",
+			"Slides/Week 1/Intro.pdf": uploadV2,
+			"syllabus.pdf":            uploadPDF,
+		}},
+		{"folder", slidesID, "student", false, map[string]string{
+			"Week 1/":          "",
+			"Week 1/Intro.pdf": uploadV2,
+		}},
+		{"empty", emptyID, "student", false, map[string]string{}},
+		{"foreign folder", elsewhereID, "student", true, nil},
+		{"no member", "", "stranger", true, nil},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := newService().ExportFolder(courseID, v.folder, v.user, buf)
+			if v.err {
+				assert.NotNil(t, err, "returned no error when it should")
+				if v.name == "foreign folder" {
+					assert.Equal(t, eduboard.ErrNotFound, errors.Cause(err), "foreign folder is not reported as not found")
+				}
+				assert.Zero(t, buf.Len(), "wrote an archive when it shouldn't")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+
+			zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			assert.Nil(t, err, "archive can not be read")
+			files := map[string]string{}
+			for _, f := range zr.File {
+				r, err := f.Open()
+				assert.Nil(t, err, "file %s can not be opened", f.Name)
+				data, _ := ioutil.ReadAll(r)
+				r.Close()
+				files[f.Name] = string(data)
+				if !strings.HasSuffix(f.Name, "/") {
+					assert.Equal(t, 2018, f.Modified.Year(), "modification time of %s does not match", f.Name)
+				}
+			}
+			assert.Equal(t, v.files, files, "files do not match")
+		})
+	}
+}
+
+func TestMaterialService_ExportFolder_Clash(t *testing.T) {
+	modified := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	mr := newMaterials()
+	mr.FindByCourseFn = func(course string) (error, []eduboard.Material) {
+		return nil, []eduboard.Material{
+			{ID: bson.ObjectIdHex(syllabusID), FolderID: bson.ObjectIdHex(emptyID), Name: "notes.pdf", Versions: []eduboard.MaterialVersion{{UploadID: bson.ObjectIdHex(uploadPDF), Filename: "notes.pdf", UploadedAt: modified}}},
+			{ID: bson.ObjectIdHex(introID), FolderID: bson.ObjectIdHex(emptyID), Name: "Notes", Versions: []eduboard.MaterialVersion{{UploadID: bson.ObjectIdHex(uploadV1), Filename: "notes.pdf", UploadedAt: modified}}},
+		}
+	}
+	buf := &bytes.Buffer{}
+	err := New(newFolders(), mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID)).ExportFolder(courseID, emptyID, "student", buf)
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if assert.Nil(t, err, "archive can not be read") {
+		names := []string{}
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, []string{"Notes.pdf", "notes (2).pdf"}, names, "entries do not match")
+	}
+}
+
+func TestMaterialService_ExportFolder_Failure(t *testing.T) {
+	mr := newMaterials()
+	mr.FindByCourseFn = func(course string) (error, []eduboard.Material) {
+		return nil, []eduboard.Material{{ID: bson.ObjectIdHex(syllabusID), Name: "video.mp4", Versions: []eduboard.MaterialVersion{
+			{UploadID: bson.ObjectIdHex(uploadPDF), Size: eduboard.MaxMaterialExportSize + 1},
+		}}}
+	}
+	buf := &bytes.Buffer{}
+	err := New(newFolders(), mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID)).ExportFolder(courseID, "", "student", buf)
+	assert.Equal(t, eduboard.ErrTooLarge, errors.Cause(err), "error does not match")
+	assert.Zero(t, buf.Len(), "wrote an archive when it shouldn't")
+
+	b := newStore()
+	b.GetFn = func(key string) (io.ReadCloser, error) {
+		if key == uploadV2 {
+			return nil, errors.New("blob is gone")
+		}
+		return ioutil.NopCloser(strings.NewReader(key)), nil
+	}
+	err = New(newFolders(), newMaterials(), newUploads(), b, mock.CourseFinder(members, archivedID, courseID, otherID)).ExportFolder(courseID, "", "student", buf)
+	assert.NotNil(t, err, "returned no error when it should")
+	assert.Zero(t, buf.Len(), "wrote a broken archive")
+}
+
+func TestMaterialService_DeleteByCourse(t *testing.T) {
+	fr := newFolders()
+	fr.DeleteByCourseFn = func(id string) error { return nil }
+	mr := newMaterials()
+	mr.DeleteByCourseFn = func(id string) error {
+		if id != courseID {
+			return errors.New("error deleting materials")
+		}
+		return nil
+	}
+	s := New(fr, mr, newUploads(), newStore(), mock.CourseFinder(members, archivedID, courseID, otherID))
+
+	assert.Nil(t, s.DeleteByCourse(courseID), "returned error when it shouldn't")
+	assert.True(t, fr.DeleteByCourseFnInvoked, "folders were not deleted")
+
+	fr.DeleteByCourseFnInvoked = false
+	assert.Error(t, s.DeleteByCourse(otherID), "did not return error when expected")
+	assert.False(t, fr.DeleteByCourseFnInvoked, "folders were deleted before the materials")
+}
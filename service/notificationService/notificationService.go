@@ -0,0 +1,116 @@
+package notificationService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"log"
+	"time"
+)
+
+type NotificationService struct {
+	r      eduboard.NotificationRepository
+	Logger *log.Logger
+}
+
+var (
+	_ eduboard.NotificationService = (*NotificationService)(nil)
+	_ eduboard.EntryPublishedHook  = (*NotificationService)(nil)
+)
+
+func New(repository eduboard.NotificationRepository, logger *log.Logger) *NotificationService {
+	return &NotificationService{
+		r:      repository,
+		Logger: logger,
+	}
+}
+
+// CreateNotifications stores a copy of notification for every user in userIDs, except for the user who caused it.
+func (nS *NotificationService) CreateNotifications(userIDs []string, notification eduboard.Notification) {
+	notification.Read = false
+	notification.CreatedAt = time.Now()
+
+	seen := map[string]bool{notification.ActorID: true}
+	notifications := []eduboard.Notification{}
+	for _, id := range userIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		n := notification
+		n.ID = ""
+		n.UserID = id
+		notifications = append(notifications, n)
+	}
+	if len(notifications) == 0 {
+		return
+	}
+
+	if err := nS.r.Insert(notifications); err != nil {
+		nS.Logger.Printf("error storing %s notifications of course %s: %v", notification.Type, notification.CourseID.Hex(), err)
+	}
+}
+
+// EntryPublished notifies the students of a course about a published entry. Staff members are left out,
+// as they published the entry themselves.
+func (nS *NotificationService) EntryPublished(course eduboard.Course, entry eduboard.CourseEntry) {
+	students := []string{}
+	for _, m := range course.Members {
+		if !m.Role.IsStaff() {
+			students = append(students, m.UserID)
+		}
+	}
+	nS.CreateNotifications(students, eduboard.Notification{
+		Type:        eduboard.NotificationEntryPublished,
+		CourseID:    course.ID,
+		CourseTitle: course.Title,
+		EntryID:     entry.ID,
+	})
+}
+
+// GetNotifications returns the latest notifications of a user, newest first. A limit of 0 selects the default limit.
+func (nS *NotificationService) GetNotifications(userID string, unread bool, limit int) (error, []eduboard.Notification) {
+	if limit < 0 || limit > eduboard.MaxNotificationLimit {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "limit must be between 0 and %d", eduboard.MaxNotificationLimit), []eduboard.Notification{}
+	}
+	if limit == 0 {
+		limit = eduboard.DefaultNotificationLimit
+	}
+
+	err, notifications := nS.r.FindByUser(userID, unread, limit)
+	if err != nil {
+		return errors.Wrapf(err, "error finding notifications of user %s", userID), []eduboard.Notification{}
+	}
+	return nil, notifications
+}
+
+// MarkRead marks a notification as read. Users can only mark their own notifications.
+func (nS *NotificationService) MarkRead(id string, userID string) error {
+	if err := nS.r.MarkRead(id, userID); err != nil {
+		return errors.Wrapf(err, "error marking notification %s of user %s as read", id, userID)
+	}
+	return nil
+}
+
+func (nS *NotificationService) MarkAllRead(userID string) error {
+	if err := nS.r.MarkAllRead(userID); err != nil {
+		return errors.Wrapf(err, "error marking notifications of user %s as read", userID)
+	}
+	return nil
+}
+
+func (nS *NotificationService) CountUnread(userID string) (error, int) {
+	err, count := nS.r.CountUnread(userID)
+	if err != nil {
+		return errors.Wrapf(err, "error counting notifications of user %s", userID), 0
+	}
+	return nil, count
+}
+
+// DeleteByCourse deletes all notifications about a course.
+func (nS *NotificationService) DeleteByCourse(courseID string) error {
+	if err := nS.r.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting notifications of course %s", courseID)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package notificationService
+
+import (
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	r := &mock.NotificationRepository{}
+	s := New(r, nil)
+	assert.Equal(t, r, s.r, "repository does not match")
+}
+
+func TestNotificationService_CreateNotifications(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		userIDs  []string
+		actorID  string
+		error    bool
+		expected []string
+	}{
+		{"success", []string{"1", "2"}, "", false, []string{"1", "2"}},
+		{"skips actor", []string{"1", "2", "3"}, "2", false, []string{"1", "3"}},
+		{"skips duplicates", []string{"1", "1", "", "2"}, "", false, []string{"1", "2"}},
+		{"only actor", []string{"1"}, "1", false, []string{}},
+		{"repository error", []string{"1"}, "", true, []string{"1"}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			stored := []string{}
+			r := &mock.NotificationRepository{}
+			r.InsertFn = func(notifications []eduboard.Notification) error {
+				for _, n := range notifications {
+					assert.Equal(t, eduboard.NotificationMemberAdded, n.Type, "type does not match")
+					assert.False(t, n.CreatedAt.IsZero(), "creation time not set")
+					assert.False(t, n.Read, "notification is read")
+					stored = append(stored, n.UserID)
+				}
+				if v.error {
+					return errors.New("error")
+				}
+				return nil
+			}
+			logs := &bytes.Buffer{}
+			s := New(r, log.New(logs, "", 0))
+
+			s.CreateNotifications(v.userIDs, eduboard.Notification{Type: eduboard.NotificationMemberAdded, ActorID: v.actorID, Read: true})
+			assert.Equal(t, len(v.expected) > 0, r.InsertFnInvoked, "Insert was not invoked as expected")
+			assert.Equal(t, v.expected, stored, "recipients do not match")
+			assert.Equal(t, v.error, logs.Len() > 0, "error was not logged as expected")
+		})
+	}
+}
+
+func TestNotificationService_EntryPublished(t *testing.T) {
+	var stored []eduboard.Notification
+	r := &mock.NotificationRepository{}
+	r.InsertFn = func(notifications []eduboard.Notification) error {
+		stored = notifications
+		return nil
+	}
+	s := New(r, nil)
+
+	course := eduboard.Course{ID: "1", Title: "Algebra", Members: []eduboard.Member{
+		{UserID: "1", Role: eduboard.RoleOwner},
+		{UserID: "2", Role: eduboard.RoleTeacher},
+		{UserID: "3", Role: eduboard.RoleStudent},
+	}}
+	s.EntryPublished(course, eduboard.CourseEntry{ID: "2"})
+
+	if assert.Len(t, stored, 1, "unexpected number of notifications") {
+		assert.Equal(t, "3", stored[0].UserID, "recipient does not match")
+		assert.Equal(t, eduboard.NotificationEntryPublished, stored[0].Type, "type does not match")
+		assert.Equal(t, "Algebra", stored[0].CourseTitle, "course title does not match")
+		assert.Equal(t, course.ID, stored[0].CourseID, "course does not match")
+	}
+}
+
+func TestNotificationService_GetNotifications(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		limit   int
+		invalid bool
+		error   bool
+		limited int
+	}{
+		{"default limit", 0, false, false, eduboard.DefaultNotificationLimit},
+		{"limit", 5, false, false, 5},
+		{"negative limit", -1, true, true, 0},
+		{"limit too large", eduboard.MaxNotificationLimit + 1, true, true, 0},
+		{"repository error", 13, false, true, 13},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := &mock.NotificationRepository{}
+			r.FindByUserFn = func(userID string, unread bool, limit int) (error, []eduboard.Notification) {
+				assert.Equal(t, v.limited, limit, "limit does not match")
+				assert.True(t, unread, "unread filter not passed")
+				if limit == 13 {
+					return errors.New("error"), []eduboard.Notification{}
+				}
+				return nil, []eduboard.Notification{{UserID: userID}}
+			}
+			s := New(r, nil)
+
+			err, notifications := s.GetNotifications("1", true, v.limit)
+			assert.Equal(t, !v.invalid, r.FindByUserFnInvoked, "FindByUser was not invoked as expected")
+			if v.error {
+				assert.Error(t, err, "error is nil")
+				assert.Equal(t, v.invalid, errors.Cause(err) == eduboard.ErrInvalidInput, "invalid input error unexpected")
+				assert.Empty(t, notifications, "notifications returned on error")
+				return
+			}
+			assert.Nil(t, err, "error not nil")
+			assert.Len(t, notifications, 1, "notifications do not match")
+		})
+	}
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	r := &mock.NotificationRepository{}
+	r.MarkReadFn = func(id string, userID string) error {
+		if id == "1" && userID == "1" {
+			return nil
+		}
+		return errors.New("not found")
+	}
+	r.MarkAllReadFn = func(userID string) error {
+		if userID == "1" {
+			return nil
+		}
+		return errors.New("error")
+	}
+	r.CountUnreadFn = func(userID string) (error, int) {
+		if userID == "1" {
+			return nil, 3
+		}
+		return errors.New("error"), 0
+	}
+	s := New(r, nil)
+
+	assert.Nil(t, s.MarkRead("1", "1"), "error not nil")
+	assert.Error(t, s.MarkRead("1", "2"), "marked notification of another user")
+	assert.Nil(t, s.MarkAllRead("1"), "error not nil")
+	assert.Error(t, s.MarkAllRead("2"), "error is nil")
+
+	err, count := s.CountUnread("1")
+	assert.Nil(t, err, "error not nil")
+	assert.Equal(t, 3, count, "count does not match")
+	err, _ = s.CountUnread("2")
+	assert.Error(t, err, "error is nil")
+}
+
+func TestNotificationService_DeleteByCourse(t *testing.T) {
+	r := &mock.NotificationRepository{}
+	r.DeleteByCourseFn = func(courseID string) error {
+		if courseID != "course" {
+			return errors.New("invalid id")
+		}
+		return nil
+	}
+	s := New(r, nil)
+
+	assert.Nil(t, s.DeleteByCourse("course"), "should not cause error")
+	assert.True(t, r.DeleteByCourseFnInvoked, "DeleteByCourse was not invoked")
+	assert.NotNil(t, s.DeleteByCourse("other"), "did not fail")
+}
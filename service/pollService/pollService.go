@@ -0,0 +1,442 @@
+package pollService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type PollService struct {
+	r  eduboard.PollResponseRepository
+	er eduboard.CourseEntryRepository
+	cf eduboard.CourseOneFinder
+	// events is informed about all changes of polls. It may be nil.
+	events eduboard.EventPublisher
+}
+
+func New(repository eduboard.PollResponseRepository, entryRepository eduboard.CourseEntryRepository, courseFinder eduboard.CourseOneFinder, events eduboard.EventPublisher) *PollService {
+	return &PollService{
+		r:      repository,
+		er:     entryRepository,
+		cf:     courseFinder,
+		events: events,
+	}
+}
+
+// SetPoll attaches a poll to an entry. A poll can only be replaced as long as nobody responded to it.
+func (pS *PollService) SetPoll(courseID string, entryID string, userID string, poll eduboard.Poll) (error, eduboard.Poll) {
+	poll, err := validPoll(poll)
+	if err != nil {
+		return err, eduboard.Poll{}
+	}
+
+	err, course := access.Manager(pS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Poll{}
+	}
+	err, entry := pS.entry(course, entryID)
+	if err != nil {
+		return err, eduboard.Poll{}
+	}
+
+	if entry.Poll != nil {
+		err, n := pS.r.CountByEntry(entryID)
+		if err != nil {
+			return errors.Wrapf(err, "error counting responses to poll of entry %s", entryID), eduboard.Poll{}
+		}
+		if n > 0 {
+			return errors.Wrapf(eduboard.ErrInvalidInput, "poll of entry %s already has responses", entryID), eduboard.Poll{}
+		}
+	}
+
+	poll.Closed = false
+	poll.CreatedAt = time.Now()
+	// A member may have started responding since the responses were counted.
+	err = pS.er.UpdateIf(entryID, bson.M{"poll.answered": bson.M{"$ne": true}}, bson.M{"$set": bson.M{"poll": poll}})
+	if err == eduboard.ErrNotFound {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "poll of entry %s already has responses", entryID), eduboard.Poll{}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error storing poll of entry %s", entryID), eduboard.Poll{}
+	}
+
+	pS.publish(course, entry)
+	return nil, poll
+}
+
+// DeletePoll removes the poll of an entry along with all responses to it.
+func (pS *PollService) DeletePoll(courseID string, entryID string, userID string) error {
+	err, course := access.Manager(pS.cf, courseID, userID)
+	if err != nil {
+		return err
+	}
+	err, entry := pS.entry(course, entryID)
+	if err != nil {
+		return err
+	}
+	if entry.Poll == nil {
+		return errors.Wrapf(eduboard.ErrNotFound, "entry %s has no poll", entryID)
+	}
+
+	// Once the poll is gone DeletePoll fails for the entry, so responses left by a failed deletion could never be
+	// removed. The responses have to go first.
+	if err = pS.r.DeleteByEntry(entryID); err != nil {
+		return errors.Wrapf(err, "error deleting responses to poll of entry %s", entryID)
+	}
+	if err = pS.er.Update(entryID, bson.M{"$unset": bson.M{"poll": ""}}); err != nil {
+		return errors.Wrapf(err, "error deleting poll of entry %s", entryID)
+	}
+
+	pS.publish(course, entry)
+	return nil
+}
+
+// ClosePoll stops a poll from taking further responses. Results of closed polls are visible to all members.
+func (pS *PollService) ClosePoll(courseID string, entryID string, userID string) (error, eduboard.Poll) {
+	err, course := access.Manager(pS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Poll{}
+	}
+	err, entry := pS.entry(course, entryID)
+	if err != nil {
+		return err, eduboard.Poll{}
+	}
+	if entry.Poll == nil {
+		return errors.Wrapf(eduboard.ErrNotFound, "entry %s has no poll", entryID), eduboard.Poll{}
+	}
+	if entry.Poll.Closed {
+		return nil, *entry.Poll
+	}
+
+	if err = pS.er.Update(entryID, bson.M{"$set": bson.M{"poll.closed": true}}); err != nil {
+		return errors.Wrapf(err, "error closing poll of entry %s", entryID), eduboard.Poll{}
+	}
+
+	pS.publish(course, entry)
+	entry.Poll.Closed = true
+	return nil, *entry.Poll
+}
+
+// Respond stores the options a member chose for every question of a poll. Every member may respond once.
+// Responses to quizzes are graded right away.
+func (pS *PollService) Respond(courseID string, entryID string, userID string, answers [][]int) (error, eduboard.PollResponse) {
+	err, course, entry := pS.visible(courseID, entryID, userID)
+	if err != nil {
+		return err, eduboard.PollResponse{}
+	}
+	if course.Archived {
+		return errors.Wrapf(eduboard.ErrArchived, "can not respond to polls of course %s", courseID), eduboard.PollResponse{}
+	}
+	if !entry.Published {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "entry %s is not published yet", entryID), eduboard.PollResponse{}
+	}
+	if entry.Poll.Closed {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "poll of entry %s is closed", entryID), eduboard.PollResponse{}
+	}
+
+	poll := *entry.Poll
+	if len(answers) != len(poll.Questions) {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "poll has %d questions, got %d answers", len(poll.Questions), len(answers)), eduboard.PollResponse{}
+	}
+	for k, q := range poll.Questions {
+		if answers[k], err = validChoice(q, answers[k]); err != nil {
+			return errors.Wrapf(err, "invalid answer to question %d", k), eduboard.PollResponse{}
+		}
+	}
+
+	if err, _ := pS.r.Find(entryID, userID); err == nil {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "user %s already responded to poll of entry %s", userID, entryID), eduboard.PollResponse{}
+	}
+
+	response := eduboard.PollResponse{
+		ID:        bson.NewObjectId(),
+		CourseID:  course.ID,
+		EntryID:   entry.ID,
+		UserID:    userID,
+		Answers:   answers,
+		CreatedAt: time.Now(),
+	}
+	if poll.Kind == eduboard.PollKindQuiz {
+		score := 0
+		response.Correct = make([]bool, len(poll.Questions))
+		for k, q := range poll.Questions {
+			response.Correct[k] = equal(answers[k], q.Correct)
+			if response.Correct[k] {
+				score++
+			}
+		}
+		response.Score = &score
+	}
+
+	// The poll is marked as answered first, such that it is not replaced while the response is stored. Polls that
+	// were replaced or closed in the meantime do not match.
+	err = pS.er.UpdateIf(entryID, bson.M{"poll.createdAt": poll.CreatedAt, "poll.closed": false}, bson.M{"$set": bson.M{"poll.answered": true}})
+	if err == eduboard.ErrNotFound {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "poll of entry %s was changed or closed", entryID), eduboard.PollResponse{}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error marking poll of entry %s as answered", entryID), eduboard.PollResponse{}
+	}
+
+	err = pS.r.Insert(response)
+	if err == eduboard.ErrDuplicate {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "user %s already responded to poll of entry %s", userID, entryID), eduboard.PollResponse{}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error storing response to poll of entry %s", entryID), eduboard.PollResponse{}
+	}
+
+	pS.publish(course, entry)
+	return nil, response
+}
+
+// GetResponse returns the response of a member to a poll.
+func (pS *PollService) GetResponse(courseID string, entryID string, userID string) (error, eduboard.PollResponse) {
+	if err, _, _ := pS.visible(courseID, entryID, userID); err != nil {
+		return err, eduboard.PollResponse{}
+	}
+
+	err, response := pS.r.Find(entryID, userID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding response of user %s to poll of entry %s", userID, entryID), eduboard.PollResponse{}
+	}
+	return nil, response
+}
+
+// GetResults aggregates all responses to a poll. Staff may always see the results, other members once they
+// responded or the poll is closed.
+func (pS *PollService) GetResults(courseID string, entryID string, userID string) (error, eduboard.PollResults) {
+	err, course, entry := pS.visible(courseID, entryID, userID)
+	if err != nil {
+		return err, eduboard.PollResults{}
+	}
+
+	staff := course.IsStaff(userID)
+	poll := *entry.Poll
+	if !staff && !poll.Closed {
+		if err, _ := pS.r.Find(entryID, userID); err != nil {
+			return errors.Wrapf(eduboard.ErrForbidden, "user %s has not responded to poll of entry %s", userID, entryID), eduboard.PollResults{}
+		}
+	}
+
+	err, responses := pS.r.FindByEntry(entryID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding responses to poll of entry %s", entryID), eduboard.PollResults{}
+	}
+	return nil, results(entry.ID, poll, responses, staff)
+}
+
+// results counts the chosen options of all responses. Voters and scores are only listed for staff.
+func results(entryID bson.ObjectId, poll eduboard.Poll, responses []eduboard.PollResponse, staff bool) eduboard.PollResults {
+	quiz := poll.Kind == eduboard.PollKindQuiz
+	voters := staff && !quiz && !poll.Anonymous
+
+	res := eduboard.PollResults{
+		EntryID:   entryID,
+		Kind:      poll.Kind,
+		Closed:    poll.Closed,
+		Responses: len(responses),
+		Questions: make([]eduboard.PollQuestionResult, len(poll.Questions)),
+	}
+	for k, q := range poll.Questions {
+		res.Questions[k] = eduboard.PollQuestionResult{Counts: make([]int, len(q.Options)), Correct: q.Correct}
+		if voters {
+			res.Questions[k].Voters = make([][]string, len(q.Options))
+			for o := range q.Options {
+				res.Questions[k].Voters[o] = []string{}
+			}
+		}
+	}
+
+	total := 0
+	for _, r := range responses {
+		for k, choice := range r.Answers {
+			if k >= len(res.Questions) {
+				break
+			}
+			for _, o := range choice {
+				if o < 0 || o >= len(res.Questions[k].Counts) {
+					continue
+				}
+				res.Questions[k].Counts[o]++
+				if voters {
+					res.Questions[k].Voters[o] = append(res.Questions[k].Voters[o], r.UserID)
+				}
+			}
+		}
+
+		if quiz && r.Score != nil {
+			total += *r.Score
+			if staff {
+				res.Scores = append(res.Scores, eduboard.PollScore{UserID: r.UserID, Score: *r.Score})
+			}
+		}
+	}
+
+	if quiz {
+		res.MaxScore = len(poll.Questions)
+		if len(responses) > 0 {
+			mean := float64(total) / float64(len(responses))
+			res.MeanScore = &mean
+		}
+	}
+	return res
+}
+
+// validPoll returns the poll with trimmed texts and sorted correct answers, or an error wrapping
+// eduboard.ErrInvalidInput if it can not be used.
+func validPoll(poll eduboard.Poll) (eduboard.Poll, error) {
+	quiz := poll.Kind == eduboard.PollKindQuiz
+	if !quiz && poll.Kind != eduboard.PollKindPoll {
+		return eduboard.Poll{}, errors.Wrapf(eduboard.ErrInvalidInput, "unknown poll kind %q", poll.Kind)
+	}
+	if quiz && poll.Anonymous {
+		return eduboard.Poll{}, errors.Wrap(eduboard.ErrInvalidInput, "quizzes can not be anonymous")
+	}
+	if len(poll.Questions) == 0 || len(poll.Questions) > eduboard.MaxPollQuestions {
+		return eduboard.Poll{}, errors.Wrapf(eduboard.ErrInvalidInput, "a poll needs between 1 and %d questions", eduboard.MaxPollQuestions)
+	}
+
+	questions := make([]eduboard.PollQuestion, len(poll.Questions))
+	for k, q := range poll.Questions {
+		text, err := validText(q.Text)
+		if err != nil {
+			return eduboard.Poll{}, errors.Wrapf(err, "invalid question %d", k)
+		}
+		if len(q.Options) < 2 || len(q.Options) > eduboard.MaxPollOptions {
+			return eduboard.Poll{}, errors.Wrapf(eduboard.ErrInvalidInput, "question %d needs between 2 and %d options", k, eduboard.MaxPollOptions)
+		}
+		options := make([]string, len(q.Options))
+		for o, option := range q.Options {
+			if options[o], err = validText(option); err != nil {
+				return eduboard.Poll{}, errors.Wrapf(err, "invalid option %d of question %d", o, k)
+			}
+		}
+
+		question := eduboard.PollQuestion{Text: text, Options: options, Multiple: q.Multiple}
+		if quiz {
+			if question.Correct, err = validChoice(question, q.Correct); err != nil {
+				return eduboard.Poll{}, errors.Wrapf(err, "invalid correct answer of question %d", k)
+			}
+		} else if len(q.Correct) > 0 {
+			return eduboard.Poll{}, errors.Wrapf(eduboard.ErrInvalidInput, "question %d of a poll has correct answers", k)
+		}
+		questions[k] = question
+	}
+
+	poll.Questions = questions
+	return poll, nil
+}
+
+// validChoice returns the sorted indices of the options chosen for a question. At least one option must be chosen,
+// and only one unless the question allows multiple.
+func validChoice(q eduboard.PollQuestion, choice []int) ([]int, error) {
+	if len(choice) == 0 {
+		return nil, errors.Wrap(eduboard.ErrInvalidInput, "no option chosen")
+	}
+	if !q.Multiple && len(choice) > 1 {
+		return nil, errors.Wrap(eduboard.ErrInvalidInput, "only one option may be chosen")
+	}
+
+	sorted := append([]int{}, choice...)
+	sort.Ints(sorted)
+	for k, o := range sorted {
+		if o < 0 || o >= len(q.Options) {
+			return nil, errors.Wrapf(eduboard.ErrInvalidInput, "there is no option %d", o)
+		}
+		if k > 0 && sorted[k-1] == o {
+			return nil, errors.Wrapf(eduboard.ErrInvalidInput, "option %d chosen twice", o)
+		}
+	}
+	return sorted, nil
+}
+
+func validText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.Wrap(eduboard.ErrInvalidInput, "text must not be empty")
+	}
+	if utf8.RuneCountInString(text) > eduboard.MaxPollTextLength {
+		return "", errors.Wrapf(eduboard.ErrInvalidInput, "text is longer than %d characters", eduboard.MaxPollTextLength)
+	}
+	return text, nil
+}
+
+// equal reports whether two sorted choices are the same.
+func equal(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteByCourse deletes all responses to polls of a course.
+func (pS *PollService) DeleteByCourse(courseID string) error {
+	if err := pS.r.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting poll responses of course %s", courseID)
+	}
+	return nil
+}
+
+// publish reports a change of the poll of entry to all members who can see the entry.
+func (pS *PollService) publish(course eduboard.Course, entry eduboard.CourseEntry) {
+	if pS.events == nil {
+		return
+	}
+
+	recipients := course.StaffIDs()
+	if entry.Published {
+		recipients = course.MemberIDs()
+	}
+	pS.events.Publish(eduboard.Event{
+		Type:       eduboard.EventPollUpdated,
+		CourseID:   course.ID,
+		EntryID:    entry.ID,
+		Time:       time.Now(),
+		Recipients: recipients,
+	})
+}
+
+// entry returns an entry of course.
+func (pS *PollService) entry(course eduboard.Course, entryID string) (error, eduboard.CourseEntry) {
+	err, entry := pS.er.FindOneByID(entryID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding entry %s", entryID), eduboard.CourseEntry{}
+	}
+	if entry.CourseID != course.ID {
+		return errors.Wrapf(eduboard.ErrNotFound, "course %s has no entry %s", course.ID.Hex(), entryID), eduboard.CourseEntry{}
+	}
+	return nil, entry
+}
+
+// visible returns an entry with a poll along with its course if userID may see it.
+// Members see published entries, staff also sees drafts.
+func (pS *PollService) visible(courseID string, entryID string, userID string) (error, eduboard.Course, eduboard.CourseEntry) {
+	err, course := access.Member(pS.cf, courseID, userID)
+	if err != nil {
+		return err, eduboard.Course{}, eduboard.CourseEntry{}
+	}
+
+	err, entry := pS.entry(course, entryID)
+	if err != nil {
+		return err, eduboard.Course{}, eduboard.CourseEntry{}
+	}
+	// Drafts are hidden from students as if they did not exist.
+	if !entry.Published && !course.IsStaff(userID) {
+		return errors.Wrapf(eduboard.ErrNotFound, "course %s has no entry %s", courseID, entryID), eduboard.Course{}, eduboard.CourseEntry{}
+	}
+	if entry.Poll == nil {
+		return errors.Wrapf(eduboard.ErrNotFound, "entry %s has no poll", entryID), eduboard.Course{}, eduboard.CourseEntry{}
+	}
+	return nil, course, entry
+}
@@ -0,0 +1,425 @@
+package pollService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+)
+
+const (
+	courseID     = "5b23bbdc2bfa844c41a9f134"
+	archivedID   = "5b23bbdc2bfa844c41a9f135"
+	pollID       = "5b23bbdc2bfa844c41a9f150"
+	quizID       = "5b23bbdc2bfa844c41a9f151"
+	anonymousID  = "5b23bbdc2bfa844c41a9f152"
+	draftID      = "5b23bbdc2bfa844c41a9f153"
+	closedID     = "5b23bbdc2bfa844c41a9f154"
+	plainID      = "5b23bbdc2bfa844c41a9f155"
+	archivedPoll = "5b23bbdc2bfa844c41a9f156"
+)
+
+var members = []eduboard.Member{
+	{UserID: "teacher", Role: eduboard.RoleTeacher},
+	{UserID: "student", Role: eduboard.RoleStudent},
+	{UserID: "classmate", Role: eduboard.RoleStudent},
+}
+
+var (
+	choice = eduboard.Poll{Kind: eduboard.PollKindPoll, Questions: []eduboard.PollQuestion{
+		{Text: "Where should we meet?", Options: []string{"Lab", "Library", "Online"}},
+	}}
+	quiz = eduboard.Poll{Kind: eduboard.PollKindQuiz, Questions: []eduboard.PollQuestion{
+		{Text: "1 + 1", Options: []string{"1", "2"}, Correct: []int{1}},
+		{Text: "Primes", Options: []string{"2", "3", "4"}, Multiple: true, Correct: []int{0, 1}},
+	}}
+)
+
+func newEntryRepository() *mock.CourseEntryRepository {
+	anonymous := choice
+	anonymous.Anonymous = true
+	closed := choice
+	closed.Closed = true
+
+	course := bson.ObjectIdHex(courseID)
+	entries := []eduboard.CourseEntry{
+		{ID: bson.ObjectIdHex(pollID), CourseID: course, Published: true, Poll: &choice},
+		{ID: bson.ObjectIdHex(quizID), CourseID: course, Published: true, Poll: &quiz},
+		{ID: bson.ObjectIdHex(anonymousID), CourseID: course, Published: true, Poll: &anonymous},
+		{ID: bson.ObjectIdHex(draftID), CourseID: course, Poll: &choice},
+		{ID: bson.ObjectIdHex(closedID), CourseID: course, Published: true, Poll: &closed},
+		{ID: bson.ObjectIdHex(plainID), CourseID: course, Published: true},
+		{ID: bson.ObjectIdHex(archivedPoll), CourseID: bson.ObjectIdHex(archivedID), Published: true, Poll: &choice},
+	}
+
+	er := &mock.CourseEntryRepository{}
+	er.FindOneFn = func(id string) (error, eduboard.CourseEntry) {
+		for _, v := range entries {
+			if v.ID.Hex() == id {
+				// Copy the poll, such that tests can not change the fixtures.
+				if v.Poll != nil {
+					poll := *v.Poll
+					v.Poll = &poll
+				}
+				return nil, v
+			}
+		}
+		return errors.New("not found"), eduboard.CourseEntry{}
+	}
+	er.UpdateFn = func(id string, update bson.M) error { return nil }
+	er.UpdateIfFn = func(id string, query bson.M, update bson.M) error { return nil }
+	return er
+}
+
+// newRepository returns a repository in which "student" responded to the polls and "classmate" to the quiz.
+func newRepository() *mock.PollResponseRepository {
+	two, one := 2, 1
+	responses := []eduboard.PollResponse{
+		{EntryID: bson.ObjectIdHex(pollID), UserID: "student", Answers: [][]int{{1}}},
+		{EntryID: bson.ObjectIdHex(anonymousID), UserID: "student", Answers: [][]int{{2}}},
+		{EntryID: bson.ObjectIdHex(quizID), UserID: "student", Answers: [][]int{{1}, {0, 1}}, Correct: []bool{true, true}, Score: &two},
+		{EntryID: bson.ObjectIdHex(quizID), UserID: "classmate", Answers: [][]int{{1}, {0}}, Correct: []bool{true, false}, Score: &one},
+	}
+
+	r := &mock.PollResponseRepository{}
+	r.InsertFn = func(response eduboard.PollResponse) error { return nil }
+	r.FindFn = func(entryID string, userID string) (error, eduboard.PollResponse) {
+		for _, v := range responses {
+			if v.EntryID.Hex() == entryID && v.UserID == userID {
+				return nil, v
+			}
+		}
+		return errors.New("not found"), eduboard.PollResponse{}
+	}
+	r.FindByEntryFn = func(entryID string) (error, []eduboard.PollResponse) {
+		result := []eduboard.PollResponse{}
+		for _, v := range responses {
+			if v.EntryID.Hex() == entryID {
+				result = append(result, v)
+			}
+		}
+		return nil, result
+	}
+	r.CountByEntryFn = func(entryID string) (error, int) {
+		_, result := r.FindByEntryFn(entryID)
+		return nil, len(result)
+	}
+	r.DeleteByEntryFn = func(entryID string) error { return nil }
+	return r
+}
+
+func TestNew(t *testing.T) {
+	r := newRepository()
+	er := newEntryRepository()
+	cf := mock.CourseFinder(members, archivedID, courseID)
+	events := &mock.EventPublisher{}
+	s := New(r, er, cf, events)
+	assert.Equal(t, r, s.r, "repository does not match")
+	assert.Equal(t, er, s.er, "entry repository does not match")
+	assert.Equal(t, cf, s.cf, "course finder does not match")
+	assert.Equal(t, events, s.events, "event publisher does not match")
+}
+
+func TestPollService_SetPoll(t *testing.T) {
+	question := func(q eduboard.PollQuestion) eduboard.Poll {
+		return eduboard.Poll{Kind: eduboard.PollKindQuiz, Questions: []eduboard.PollQuestion{q}}
+	}
+
+	var testCases = []struct {
+		name   string
+		course string
+		entry  string
+		user   string
+		poll   eduboard.Poll
+		err    error
+		stored bool
+	}{
+		{"poll", courseID, plainID, "teacher", choice, nil, true},
+		{"quiz", courseID, plainID, "teacher", quiz, nil, true},
+		{"replace without responses", courseID, closedID, "teacher", quiz, nil, true},
+		{"replace with responses", courseID, pollID, "teacher", quiz, eduboard.ErrInvalidInput, false},
+		{"student", courseID, plainID, "student", choice, eduboard.ErrForbidden, false},
+		{"archived", archivedID, archivedPoll, "teacher", choice, eduboard.ErrArchived, false},
+		{"other course", archivedID, plainID, "teacher", choice, nil, false},
+		{"unknown kind", courseID, plainID, "teacher", eduboard.Poll{Kind: "survey", Questions: choice.Questions}, eduboard.ErrInvalidInput, false},
+		{"anonymous quiz", courseID, plainID, "teacher", eduboard.Poll{Kind: eduboard.PollKindQuiz, Anonymous: true, Questions: quiz.Questions}, eduboard.ErrInvalidInput, false},
+		{"no questions", courseID, plainID, "teacher", eduboard.Poll{Kind: eduboard.PollKindPoll}, eduboard.ErrInvalidInput, false},
+		{"one option", courseID, plainID, "teacher", question(eduboard.PollQuestion{Text: "?", Options: []string{"yes"}, Correct: []int{0}}), eduboard.ErrInvalidInput, false},
+		{"empty option", courseID, plainID, "teacher", question(eduboard.PollQuestion{Text: "?", Options: []string{"yes", " "}, Correct: []int{0}}), eduboard.ErrInvalidInput, false},
+		{"empty question", courseID, plainID, "teacher", question(eduboard.PollQuestion{Text: "", Options: []string{"yes", "no"}, Correct: []int{0}}), eduboard.ErrInvalidInput, false},
+		{"quiz without answer", courseID, plainID, "teacher", question(eduboard.PollQuestion{Text: "?", Options: []string{"yes", "no"}}), eduboard.ErrInvalidInput, false},
+		{"answer out of range", courseID, plainID, "teacher", question(eduboard.PollQuestion{Text: "?", Options: []string{"yes", "no"}, Correct: []int{2}}), eduboard.ErrInvalidInput, false},
+		{"several answers to single choice", courseID, plainID, "teacher", question(eduboard.PollQuestion{Text: "?", Options: []string{"yes", "no"}, Correct: []int{0, 1}}), eduboard.ErrInvalidInput, false},
+		{"poll with answers", courseID, plainID, "teacher", eduboard.Poll{Kind: eduboard.PollKindPoll, Questions: quiz.Questions}, eduboard.ErrInvalidInput, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			er := newEntryRepository()
+			var stored bson.M
+			er.UpdateIfFn = func(id string, query bson.M, update bson.M) error {
+				stored = update
+				return nil
+			}
+			s := New(newRepository(), er, mock.CourseFinder(members, archivedID, courseID), nil)
+
+			err, poll := s.SetPoll(v.course, v.entry, v.user, v.poll)
+			assert.Equal(t, v.stored, er.UpdateIfFnInvoked, "UpdateIf was not invoked as expected")
+			if !v.stored {
+				assert.NotNil(t, err, "error is nil")
+				if v.err != nil {
+					assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				}
+				return
+			}
+			assert.Nil(t, err, "error not nil")
+			assert.False(t, poll.Closed, "poll is closed")
+			assert.False(t, poll.CreatedAt.IsZero(), "creation time is not set")
+			assert.Equal(t, bson.M{"$set": bson.M{"poll": poll}}, stored, "update does not match")
+		})
+	}
+}
+
+func TestPollService_SetPoll_Answered(t *testing.T) {
+	er := newEntryRepository()
+	er.UpdateIfFn = func(id string, query bson.M, update bson.M) error {
+		assert.Equal(t, bson.M{"poll.answered": bson.M{"$ne": true}}, query, "condition does not match")
+		return eduboard.ErrNotFound
+	}
+	s := New(newRepository(), er, mock.CourseFinder(members, archivedID, courseID), nil)
+
+	err, _ := s.SetPoll(courseID, closedID, "teacher", quiz)
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "error does not match")
+}
+
+func TestPollService_SetPoll_Normalizes(t *testing.T) {
+	s := New(newRepository(), newEntryRepository(), mock.CourseFinder(members, archivedID, courseID), nil)
+
+	poll := eduboard.Poll{Kind: eduboard.PollKindQuiz, Questions: []eduboard.PollQuestion{
+		{Text: " Primes ", Options: []string{" 2", "3 ", "4"}, Multiple: true, Correct: []int{1, 0}},
+	}}
+	err, stored := s.SetPoll(courseID, plainID, "teacher", poll)
+	assert.Nil(t, err, "error not nil")
+	assert.Equal(t, "Primes", stored.Questions[0].Text, "question was not trimmed")
+	assert.Equal(t, []string{"2", "3", "4"}, stored.Questions[0].Options, "options were not trimmed")
+	assert.Equal(t, []int{0, 1}, stored.Questions[0].Correct, "answers were not sorted")
+}
+
+func TestPollService_DeletePoll(t *testing.T) {
+	var testCases = []struct {
+		name  string
+		entry string
+		user  string
+		err   bool
+	}{
+		{"success", pollID, "teacher", false},
+		{"student", pollID, "student", true},
+		{"no poll", plainID, "teacher", true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			er := newEntryRepository()
+			s := New(r, er, mock.CourseFinder(members, archivedID, courseID), nil)
+
+			err := s.DeletePoll(courseID, v.entry, v.user)
+			if v.err {
+				assert.NotNil(t, err, "error is nil")
+				assert.False(t, r.DeleteByEntryFnInvoked, "responses were deleted")
+				assert.False(t, er.UpdateFnInvoked, "poll was deleted")
+				return
+			}
+			assert.Nil(t, err, "error not nil")
+			assert.True(t, r.DeleteByEntryFnInvoked, "responses were not deleted")
+			assert.True(t, er.UpdateFnInvoked, "poll was not deleted")
+		})
+	}
+}
+
+func TestPollService_ClosePoll(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		entry   string
+		user    string
+		err     bool
+		updated bool
+	}{
+		{"success", pollID, "teacher", false, true},
+		{"already closed", closedID, "teacher", false, false},
+		{"student", pollID, "student", true, false},
+		{"no poll", plainID, "teacher", true, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			er := newEntryRepository()
+			s := New(newRepository(), er, mock.CourseFinder(members, archivedID, courseID), nil)
+
+			err, poll := s.ClosePoll(courseID, v.entry, v.user)
+			assert.Equal(t, v.updated, er.UpdateFnInvoked, "Update was not invoked as expected")
+			if v.err {
+				assert.NotNil(t, err, "error is nil")
+				return
+			}
+			assert.Nil(t, err, "error not nil")
+			assert.True(t, poll.Closed, "poll is not closed")
+		})
+	}
+}
+
+func TestPollService_Respond(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		course  string
+		entry   string
+		user    string
+		answers [][]int
+		err     error
+		stored  bool
+		score   int
+	}{
+		{"poll", courseID, pollID, "classmate", [][]int{{2}}, nil, true, 0},
+		{"staff", courseID, pollID, "teacher", [][]int{{0}}, nil, true, 0},
+		{"quiz right", courseID, quizID, "teacher", [][]int{{1}, {1, 0}}, nil, true, 2},
+		{"quiz partly right", courseID, quizID, "teacher", [][]int{{1}, {1}}, nil, true, 1},
+		{"twice", courseID, pollID, "student", [][]int{{0}}, eduboard.ErrInvalidInput, false, 0},
+		{"closed", courseID, closedID, "student", [][]int{{0}}, eduboard.ErrInvalidInput, false, 0},
+		{"draft", courseID, draftID, "teacher", [][]int{{0}}, eduboard.ErrInvalidInput, false, 0},
+		{"hidden draft", courseID, draftID, "student", [][]int{{0}}, eduboard.ErrNotFound, false, 0},
+		{"archived", archivedID, archivedPoll, "student", [][]int{{0}}, eduboard.ErrArchived, false, 0},
+		{"no member", courseID, pollID, "stranger", [][]int{{0}}, eduboard.ErrForbidden, false, 0},
+		{"no poll", courseID, plainID, "student", [][]int{{0}}, eduboard.ErrNotFound, false, 0},
+		{"missing answer", courseID, quizID, "teacher", [][]int{{1}}, eduboard.ErrInvalidInput, false, 0},
+		{"no option", courseID, pollID, "classmate", [][]int{{}}, eduboard.ErrInvalidInput, false, 0},
+		{"several options", courseID, pollID, "classmate", [][]int{{0, 1}}, eduboard.ErrInvalidInput, false, 0},
+		{"same option twice", courseID, quizID, "teacher", [][]int{{1}, {0, 0}}, eduboard.ErrInvalidInput, false, 0},
+		{"unknown option", courseID, pollID, "classmate", [][]int{{3}}, eduboard.ErrInvalidInput, false, 0},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			var stored eduboard.PollResponse
+			r.InsertFn = func(response eduboard.PollResponse) error {
+				stored = response
+				return nil
+			}
+			events := []eduboard.Event{}
+			publisher := &mock.EventPublisher{PublishFn: func(event eduboard.Event) { events = append(events, event) }}
+			s := New(r, newEntryRepository(), mock.CourseFinder(members, archivedID, courseID), publisher)
+
+			err, response := s.Respond(v.course, v.entry, v.user, v.answers)
+			assert.Equal(t, v.stored, r.InsertFnInvoked, "Insert was not invoked as expected")
+			if !v.stored {
+				assert.NotNil(t, err, "error is nil")
+				if v.err != nil {
+					assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				}
+				assert.Empty(t, events, "event was published")
+				return
+			}
+			assert.Nil(t, err, "error not nil")
+			assert.Equal(t, stored, response, "stored response was not returned")
+			assert.Equal(t, v.user, response.UserID, "user does not match")
+			if v.entry == quizID {
+				assert.Equal(t, v.score, *response.Score, "score does not match")
+				assert.Len(t, response.Correct, 2, "questions were not graded")
+			} else {
+				assert.Nil(t, response.Score, "poll was graded")
+			}
+
+			if assert.Len(t, events, 1, "unexpected number of events") {
+				assert.Equal(t, eduboard.EventPollUpdated, events[0].Type, "event type does not match")
+				assert.Equal(t, v.entry, events[0].EntryID.Hex(), "entry does not match")
+				assert.Len(t, events[0].Recipients, len(members), "not all members were informed")
+			}
+		})
+	}
+}
+
+func TestPollService_Respond_Concurrent(t *testing.T) {
+	er := newEntryRepository()
+	er.UpdateIfFn = func(id string, query bson.M, update bson.M) error {
+		assert.Equal(t, bson.M{"poll.createdAt": choice.CreatedAt, "poll.closed": false}, query, "condition does not match")
+		assert.Equal(t, bson.M{"$set": bson.M{"poll.answered": true}}, update, "update does not match")
+		return eduboard.ErrNotFound
+	}
+	r := newRepository()
+	s := New(r, er, mock.CourseFinder(members, archivedID, courseID), nil)
+
+	err, _ := s.Respond(courseID, pollID, "classmate", [][]int{{0}})
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "replaced poll is not reported as invalid input")
+	assert.False(t, r.InsertFnInvoked, "Insert was invoked")
+
+	r.InsertFn = func(response eduboard.PollResponse) error { return eduboard.ErrDuplicate }
+	s = New(r, newEntryRepository(), mock.CourseFinder(members, archivedID, courseID), nil)
+	err, _ = s.Respond(courseID, pollID, "classmate", [][]int{{0}})
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "duplicate response is not reported as invalid input")
+}
+
+func TestPollService_GetResults(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		entry  string
+		user   string
+		err    error
+		counts [][]int
+		voters bool
+		scores int
+	}{
+		{"staff", pollID, "teacher", nil, [][]int{{0, 1, 0}}, true, 0},
+		{"responded", pollID, "student", nil, [][]int{{0, 1, 0}}, false, 0},
+		{"not responded", pollID, "classmate", eduboard.ErrForbidden, nil, false, 0},
+		{"closed", closedID, "classmate", nil, [][]int{{0, 0, 0}}, false, 0},
+		{"anonymous", anonymousID, "teacher", nil, [][]int{{0, 0, 1}}, false, 0},
+		{"quiz staff", quizID, "teacher", nil, [][]int{{0, 2}, {2, 1, 0}}, false, 2},
+		{"quiz student", quizID, "classmate", nil, [][]int{{0, 2}, {2, 1, 0}}, false, 0},
+		{"no member", pollID, "stranger", eduboard.ErrForbidden, nil, false, 0},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			s := New(newRepository(), newEntryRepository(), mock.CourseFinder(members, archivedID, courseID), nil)
+
+			err, results := s.GetResults(courseID, v.entry, v.user)
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "error not nil")
+
+			counts := make([][]int, len(results.Questions))
+			for k, q := range results.Questions {
+				counts[k] = q.Counts
+				assert.Equal(t, v.voters, q.Voters != nil, "voters were not listed as expected")
+			}
+			assert.Equal(t, v.counts, counts, "counts do not match")
+			assert.Len(t, results.Scores, v.scores, "scores were not listed as expected")
+			if v.voters {
+				assert.Equal(t, []string{"student"}, results.Questions[0].Voters[1], "voters do not match")
+			}
+			if v.entry == quizID {
+				assert.Equal(t, 2, results.MaxScore, "maximum score does not match")
+				assert.Equal(t, 1.5, *results.MeanScore, "mean score does not match")
+				assert.Equal(t, []int{0, 1}, results.Questions[1].Correct, "correct answers are missing")
+			}
+		})
+	}
+}
+
+func TestPollService_DeleteByCourse(t *testing.T) {
+	r := newRepository()
+	r.DeleteByCourseFn = func(id string) error {
+		if id != courseID {
+			return errors.New("error deleting responses")
+		}
+		return nil
+	}
+	s := New(r, newEntryRepository(), mock.CourseFinder(members, archivedID, courseID), nil)
+
+	assert.Nil(t, s.DeleteByCourse(courseID), "returned error when it shouldn't")
+	assert.Error(t, s.DeleteByCourse(archivedID), "did not return error when expected")
+}
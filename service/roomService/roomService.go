@@ -0,0 +1,166 @@
+package roomService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"time"
+)
+
+type RoomService struct {
+	r  eduboard.RoomRepository
+	cf eduboard.CourseManyFinder
+}
+
+func New(repository eduboard.RoomRepository, courseFinder eduboard.CourseManyFinder) *RoomService {
+	return &RoomService{
+		r:  repository,
+		cf: courseFinder,
+	}
+}
+
+// CreateRoom registers a new room. Room names are unique, as schedules refer to rooms by name.
+// Only users who teach a course may register rooms, they may then change and delete them.
+func (rS *RoomService) CreateRoom(room *eduboard.Room, userID string) (error, eduboard.Room) {
+	if err := rS.staff(userID); err != nil {
+		return err, eduboard.Room{}
+	}
+
+	room.Name = strings.TrimSpace(room.Name)
+	if err := rS.validName(room.Name, ""); err != nil {
+		return err, eduboard.Room{}
+	}
+	if room.Capacity < 0 {
+		return errors.Wrap(eduboard.ErrInvalidInput, "negative capacity"), eduboard.Room{}
+	}
+
+	room.ID = ""
+	room.CreatedBy = userID
+	room.CreatedAt = time.Now()
+	if err := rS.r.Insert(room); err != nil {
+		return errors.Wrapf(err, "error storing room %s", room.Name), eduboard.Room{}
+	}
+	return nil, *room
+}
+
+func (rS *RoomService) GetRooms() (error, []eduboard.Room) {
+	err, rooms := rS.r.FindAll()
+	if err != nil {
+		return errors.Wrap(err, "error finding rooms"), []eduboard.Room{}
+	}
+	return nil, rooms
+}
+
+func (rS *RoomService) GetRoom(id string) (error, eduboard.Room) {
+	err, room := rS.r.FindOneByID(id)
+	if err != nil {
+		return errors.Wrapf(err, "error finding room %s", id), eduboard.Room{}
+	}
+	return nil, room
+}
+
+// UpdateRoom changes the details of a room. Rooms used by a schedule can not be renamed, as schedules refer to them by name.
+func (rS *RoomService) UpdateRoom(id string, userID string, update eduboard.RoomUpdate) (error, eduboard.Room) {
+	err, room := rS.managed(id, userID)
+	if err != nil {
+		return err, eduboard.Room{}
+	}
+
+	set := bson.M{}
+	if update.Name != nil && strings.TrimSpace(*update.Name) != room.Name {
+		name := strings.TrimSpace(*update.Name)
+		if err = rS.validName(name, id); err != nil {
+			return err, eduboard.Room{}
+		}
+		if err = rS.unused(room); err != nil {
+			return err, eduboard.Room{}
+		}
+		set["name"] = name
+	}
+	if update.Building != nil {
+		set["building"] = *update.Building
+	}
+	if update.Capacity != nil {
+		if *update.Capacity < 0 {
+			return errors.Wrap(eduboard.ErrInvalidInput, "negative capacity"), eduboard.Room{}
+		}
+		set["capacity"] = *update.Capacity
+	}
+	if len(set) == 0 {
+		return nil, room
+	}
+
+	err, room = rS.r.Update(id, bson.M{"$set": set})
+	if err != nil {
+		return errors.Wrapf(err, "error updating room %s", id), eduboard.Room{}
+	}
+	return nil, room
+}
+
+// DeleteRoom deletes a room that is not used by any schedule.
+func (rS *RoomService) DeleteRoom(id string, userID string) error {
+	err, room := rS.managed(id, userID)
+	if err != nil {
+		return err
+	}
+	if err = rS.unused(room); err != nil {
+		return err
+	}
+
+	if err = rS.r.Delete(id); err != nil {
+		return errors.Wrapf(err, "error deleting room %s", id)
+	}
+	return nil
+}
+
+// staff fails with eduboard.ErrForbidden unless userID is staff of at least one course.
+func (rS *RoomService) staff(userID string) error {
+	roles := []eduboard.Role{eduboard.RoleOwner, eduboard.RoleTeacher}
+	err, courses := rS.cf.FindMany(bson.M{"members": bson.M{"$elemMatch": bson.M{"userID": userID, "role": bson.M{"$in": roles}}}})
+	if err != nil {
+		return errors.Wrapf(err, "error finding courses of user %s", userID)
+	}
+	if len(courses) == 0 {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s does not teach any course", userID)
+	}
+	return nil
+}
+
+// managed returns a room if userID registered it. Rooms registered before creators were recorded can not be changed.
+func (rS *RoomService) managed(id string, userID string) (error, eduboard.Room) {
+	err, room := rS.r.FindOneByID(id)
+	if err != nil {
+		return errors.Wrapf(eduboard.ErrNotFound, "error finding room %s: %v", id, err), eduboard.Room{}
+	}
+	if room.CreatedBy != userID {
+		return errors.Wrapf(eduboard.ErrForbidden, "user %s did not register room %s", userID, id), eduboard.Room{}
+	}
+	return nil, room
+}
+
+// validName checks that name is not empty and not taken by a room other than the one with the given id.
+func (rS *RoomService) validName(name string, id string) error {
+	if name == "" {
+		return errors.Wrap(eduboard.ErrInvalidInput, "room name must not be empty")
+	}
+	if err, other := rS.r.FindByName(name); err == nil && other.ID.Hex() != id {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "room %s already exists", name)
+	}
+	return nil
+}
+
+// unused fails with eduboard.ErrInvalidInput if a schedule of any course, including archived ones, takes place in room.
+func (rS *RoomService) unused(room eduboard.Room) error {
+	err, courses := rS.cf.FindMany(bson.M{"$or": []bson.M{
+		{"schedules.room": room.Name},
+		{"schedules.exceptions.room": room.Name},
+	}})
+	if err != nil {
+		return errors.Wrapf(err, "error finding courses in room %s", room.Name)
+	}
+	if len(courses) > 0 {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "room %s is used by course %s", room.Name, courses[0].ID.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package roomService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"testing"
+)
+
+const roomID = "5b23bbdc2bfa844c41a9f150"
+
+func newRepository() *mock.RoomRepository {
+	room := eduboard.Room{ID: bson.ObjectIdHex(roomID), Name: "EN 154", CreatedBy: "teacher"}
+	return &mock.RoomRepository{
+		InsertFn: func(room *eduboard.Room) error {
+			if room.Name == "failing" {
+				return errors.New("error storing room")
+			}
+			room.ID = bson.NewObjectId()
+			return nil
+		},
+		FindAllFn: func() (error, []eduboard.Room) {
+			return nil, []eduboard.Room{room}
+		},
+		FindOneByIDFn: func(id string) (error, eduboard.Room) {
+			if id == roomID {
+				return nil, room
+			}
+			return errors.New("not found"), eduboard.Room{}
+		},
+		FindByNameFn: func(name string) (error, eduboard.Room) {
+			if name == room.Name {
+				return nil, room
+			}
+			return errors.New("not found"), eduboard.Room{}
+		},
+		UpdateFn: func(id string, update bson.M) (error, eduboard.Room) {
+			set := update["$set"].(bson.M)
+			if name, ok := set["name"]; ok {
+				room.Name = name.(string)
+			}
+			if capacity, ok := set["capacity"]; ok {
+				room.Capacity = capacity.(int)
+			}
+			return nil, room
+		},
+		DeleteFn: func(id string) error {
+			return nil
+		},
+	}
+}
+
+// newCourseFinder returns a course finder where "teacher" teaches a course that meets in the room busy.
+func newCourseFinder(busy string) *mock.CourseRepository {
+	course := eduboard.Course{ID: bson.NewObjectId()}
+	return &mock.CourseRepository{
+		FindManyFn: func(query bson.M) (error, []eduboard.Course) {
+			if members, ok := query["members"]; ok {
+				if members.(bson.M)["$elemMatch"].(bson.M)["userID"] == "teacher" {
+					return nil, []eduboard.Course{course}
+				}
+				return nil, []eduboard.Course{}
+			}
+			if query["$or"].([]bson.M)[0]["schedules.room"] == busy {
+				return nil, []eduboard.Course{course}
+			}
+			return nil, []eduboard.Course{}
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	r := newRepository()
+	cf := newCourseFinder("")
+	s := New(r, cf)
+	assert.Equal(t, r, s.r, "repository does not match")
+	assert.Equal(t, cf, s.cf, "course finder does not match")
+}
+
+func TestRoomService_CreateRoom(t *testing.T) {
+	var testCases = []struct {
+		name  string
+		room  eduboard.Room
+		user  string
+		err   error
+		error bool
+	}{
+		{"success", eduboard.Room{Name: " MA 001 ", Capacity: 120}, "teacher", nil, false},
+		{"student", eduboard.Room{Name: "MA 001"}, "student", eduboard.ErrForbidden, true},
+		{"empty name", eduboard.Room{Name: "  "}, "teacher", eduboard.ErrInvalidInput, true},
+		{"negative capacity", eduboard.Room{Name: "MA 002", Capacity: -1}, "teacher", eduboard.ErrInvalidInput, true},
+		{"existing", eduboard.Room{Name: "EN 154"}, "teacher", eduboard.ErrInvalidInput, true},
+		{"failing", eduboard.Room{Name: "failing"}, "teacher", nil, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			s := New(r, newCourseFinder(""))
+			err, room := s.CreateRoom(&v.room, v.user)
+			if v.error {
+				assert.NotNil(t, err, "did not fail")
+				if v.err != nil {
+					assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+					assert.False(t, r.InsertFnInvoked, "Insert was invoked")
+				}
+				return
+			}
+			assert.Nil(t, err, "caused error creating room")
+			assert.Equal(t, "MA 001", room.Name, "name was not trimmed")
+			assert.Equal(t, v.user, room.CreatedBy, "creator does not match")
+			assert.NotEmpty(t, room.ID, "room has no ID")
+			assert.False(t, room.CreatedAt.IsZero(), "room has no creation time")
+		})
+	}
+}
+
+func TestRoomService_GetRooms(t *testing.T) {
+	err, rooms := New(newRepository(), newCourseFinder("")).GetRooms()
+	assert.Nil(t, err, "caused error getting rooms")
+	assert.Len(t, rooms, 1, "unexpected number of rooms")
+}
+
+func TestRoomService_GetRoom(t *testing.T) {
+	s := New(newRepository(), newCourseFinder(""))
+
+	err, room := s.GetRoom(roomID)
+	assert.Nil(t, err, "caused error getting room")
+	assert.Equal(t, "EN 154", room.Name, "room does not match")
+
+	err, _ = s.GetRoom("unknown")
+	assert.NotNil(t, err, "did not fail")
+}
+
+func TestRoomService_UpdateRoom(t *testing.T) {
+	name := func(s string) *string { return &s }
+	capacity := func(c int) *int { return &c }
+
+	var testCases = []struct {
+		name   string
+		id     string
+		user   string
+		busy   string
+		update eduboard.RoomUpdate
+		err    error
+		stored bool
+	}{
+		{"rename", roomID, "teacher", "", eduboard.RoomUpdate{Name: name(" EN 155 ")}, nil, true},
+		{"capacity", roomID, "teacher", "EN 154", eduboard.RoomUpdate{Capacity: capacity(60)}, nil, true},
+		{"same name", roomID, "teacher", "EN 154", eduboard.RoomUpdate{Name: name("EN 154")}, nil, false},
+		{"rename used room", roomID, "teacher", "EN 154", eduboard.RoomUpdate{Name: name("EN 155")}, eduboard.ErrInvalidInput, false},
+		{"empty name", roomID, "teacher", "", eduboard.RoomUpdate{Name: name(" ")}, eduboard.ErrInvalidInput, false},
+		{"negative capacity", roomID, "teacher", "", eduboard.RoomUpdate{Capacity: capacity(-1)}, eduboard.ErrInvalidInput, false},
+		{"student", roomID, "student", "", eduboard.RoomUpdate{Capacity: capacity(60)}, eduboard.ErrForbidden, false},
+		{"other teacher", roomID, "colleague", "", eduboard.RoomUpdate{Capacity: capacity(60)}, eduboard.ErrForbidden, false},
+		{"unknown", "unknown", "teacher", "", eduboard.RoomUpdate{Capacity: capacity(60)}, eduboard.ErrNotFound, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			err, room := New(r, newCourseFinder(v.busy)).UpdateRoom(v.id, v.user, v.update)
+			assert.Equal(t, v.stored, r.UpdateFnInvoked, "Update was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "caused error updating room")
+			if v.update.Name != nil {
+				assert.Equal(t, strings.TrimSpace(*v.update.Name), room.Name, "name does not match")
+			}
+		})
+	}
+}
+
+func TestRoomService_DeleteRoom(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		id      string
+		user    string
+		busy    string
+		err     error
+		deleted bool
+	}{
+		{"success", roomID, "teacher", "", nil, true},
+		{"used", roomID, "teacher", "EN 154", eduboard.ErrInvalidInput, false},
+		{"student", roomID, "student", "", eduboard.ErrForbidden, false},
+		{"other teacher", roomID, "colleague", "", eduboard.ErrForbidden, false},
+		{"unknown", "unknown", "teacher", "", eduboard.ErrNotFound, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			err := New(r, newCourseFinder(v.busy)).DeleteRoom(v.id, v.user)
+			assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+			assert.Equal(t, v.deleted, r.DeleteFnInvoked, "Delete was not invoked as expected")
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package scheduleService
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/ical"
+	"github.com/pkg/errors"
+	"sort"
+	"time"
+)
+
+// openTermYears is how far time zones are described for schedules without a term end.
+const openTermYears = 5
+
+// ExportCalendar returns an iCalendar document with one recurring event per schedule of the given courses.
+// Cancelled meetings are excluded from their series, moved ones are overridden by an event of their own.
+func (sS ScheduleService) ExportCalendar(name string, courses []eduboard.Course) (error, []byte) {
+	type event struct {
+		course eduboard.Course
+		uid    string
+		r      recurrence
+	}
+
+	now := time.Now()
+	events := []event{}
+	zones := map[string]*time.Location{}
+	from := map[string]time.Time{}
+	to := map[string]time.Time{}
+	for _, c := range courses {
+		for k, s := range c.Schedules {
+			// Schedules stored before they were validated may not be expandable and are left out.
+			r, err := newRecurrence(s)
+			if err != nil {
+				continue
+			}
+
+			uid := fmt.Sprintf("%s-%d@eduboard", c.ID.Hex(), k)
+			if s.ID != "" {
+				uid = s.ID.Hex() + "@eduboard"
+			}
+			events = append(events, event{c, uid, r})
+
+			if r.loc == time.UTC {
+				continue
+			}
+			zone := r.loc.String()
+			end := r.last.AddDate(0, 0, 1)
+			if r.last.IsZero() {
+				end = latest(r.first, now).AddDate(openTermYears, 0, 0)
+			}
+			if _, ok := zones[zone]; !ok || r.first.Before(from[zone]) {
+				from[zone] = r.first
+			}
+			if _, ok := zones[zone]; !ok || end.After(to[zone]) {
+				to[zone] = end
+			}
+			zones[zone] = r.loc
+		}
+	}
+
+	b := &bytes.Buffer{}
+	w := ical.NewWriter(b)
+	w.Begin("VCALENDAR")
+	w.Property("VERSION", "2.0")
+	w.Property("PRODID", "-//eduboard//eduboard//EN")
+	w.Property("CALSCALE", "GREGORIAN")
+	w.Text("X-WR-CALNAME", name)
+
+	names := []string{}
+	for zone := range zones {
+		names = append(names, zone)
+	}
+	sort.Strings(names)
+	for _, zone := range names {
+		w.Timezone(zones[zone], from[zone], to[zone])
+	}
+
+	stamp := now.UTC()
+	for _, e := range events {
+		writeEvent(w, e.course, e.uid, e.r, stamp)
+	}
+
+	w.End("VCALENDAR")
+	if err := w.Err(); err != nil {
+		return errors.Wrap(err, "error writing calendar"), []byte{}
+	}
+	return nil, b.Bytes()
+}
+
+// writeEvent writes the series of a schedule followed by the overrides of its moved meetings.
+func writeEvent(w *ical.Writer, course eduboard.Course, uid string, r recurrence, stamp time.Time) {
+	s := r.schedule
+	summary := course.Title
+	if s.Title != "" {
+		summary += ": " + s.Title
+	}
+
+	exceptions := make([]eduboard.ScheduleException, len(s.Exceptions))
+	copy(exceptions, s.Exceptions)
+	sort.Slice(exceptions, func(i, j int) bool {
+		return exceptions[i].Date < exceptions[j].Date
+	})
+
+	first := r.regular(r.first)
+	w.Begin("VEVENT")
+	w.Text("UID", uid)
+	w.DateTime("DTSTAMP", stamp)
+	w.DateTime("DTSTART", first.Start)
+	if s.Duration > 0 {
+		w.DateTime("DTEND", first.End)
+	}
+	w.Property("RRULE", rrule(r))
+	w.Text("SUMMARY", summary)
+	if s.Room != "" {
+		w.Text("LOCATION", s.Room)
+	}
+	for _, e := range exceptions {
+		if e.Cancelled {
+			w.DateTime("EXDATE", regularStart(r, e.Date))
+		}
+	}
+	w.End("VEVENT")
+
+	for _, e := range exceptions {
+		if e.Cancelled {
+			continue
+		}
+
+		o := r.exception(e)
+		w.Begin("VEVENT")
+		w.Text("UID", uid)
+		w.DateTime("DTSTAMP", stamp)
+		w.DateTime("RECURRENCE-ID", regularStart(r, e.Date))
+		w.DateTime("DTSTART", o.Start)
+		if o.End.After(o.Start) {
+			w.DateTime("DTEND", o.End)
+		}
+		w.Text("SUMMARY", summary)
+		if o.Room != "" {
+			w.Text("LOCATION", o.Room)
+		}
+		w.End("VEVENT")
+	}
+}
+
+// rrule returns the recurrence rule of a schedule. UNTIL is the end of the last day of the term, given in UTC
+// as required for series with a time zone.
+func rrule(r recurrence) string {
+	rule := fmt.Sprintf("FREQ=WEEKLY;INTERVAL=%d;BYDAY=%s", r.step/7, weekdays[r.schedule.Day])
+	if !r.last.IsZero() {
+		rule += ";UNTIL=" + r.last.AddDate(0, 0, 1).Add(-time.Second).UTC().Format("20060102T150405Z")
+	}
+	return rule
+}
+
+// regularStart returns the regular start of the meeting on date.
+func regularStart(r recurrence, date string) time.Time {
+	day, _ := time.ParseInLocation(eduboard.DateFormat, date, r.loc)
+	return r.regular(day).Start
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+var weekdays = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
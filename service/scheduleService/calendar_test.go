@@ -0,0 +1,102 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleService_ExportCalendar(t *testing.T) {
+	moved := time.Date(2018, 10, 10, 14, 0, 0, 0, time.UTC)
+	course := eduboard.Course{ID: bson.ObjectIdHex(courseID), Title: "Algebra", Schedules: []eduboard.Schedule{
+		{ID: bson.ObjectIdHex(scheduleID), Day: time.Monday, Start: start, Duration: 90 * time.Minute, Room: "A 1.01", Title: "Lecture",
+			TermEnd: "2019-01-31", Interval: 2,
+			Exceptions: []eduboard.ScheduleException{
+				{Date: "2018-10-29", Start: &moved, Room: "B 2.02"},
+				{Date: "2018-10-15", Cancelled: true},
+			}},
+		{Day: time.Friday, Start: start},
+	}}
+
+	s := New(newRepository(), newRoomRepository(), nil)
+	err, calendar := s.ExportCalendar("My courses", []eduboard.Course{course})
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	lines := strings.Split(strings.TrimSuffix(string(calendar), "\r\n"), "\r\n")
+	assert.Equal(t, "BEGIN:VCALENDAR", lines[0], "calendar does not begin with VCALENDAR")
+	assert.Equal(t, "END:VCALENDAR", lines[len(lines)-1], "calendar does not end with VCALENDAR")
+	assert.Contains(t, lines, "X-WR-CALNAME:My courses", "calendar name is missing")
+	assert.NotContains(t, lines, "BEGIN:VTIMEZONE", "UTC needs no time zone")
+
+	series := eventsOf(lines)
+	assert.Len(t, series, 3, "unexpected number of events")
+	assert.Equal(t, []string{
+		"UID:" + scheduleID + "@eduboard",
+		"DTSTART:20181001T100000Z",
+		"DTEND:20181001T113000Z",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO;UNTIL=20190131T235959Z",
+		"SUMMARY:Algebra: Lecture",
+		"LOCATION:A 1.01",
+		"EXDATE:20181015T100000Z",
+	}, series[0], "series does not match")
+	assert.Equal(t, []string{
+		"UID:" + scheduleID + "@eduboard",
+		"RECURRENCE-ID:20181029T100000Z",
+		"DTSTART:20181010T140000Z",
+		"DTEND:20181010T153000Z",
+		"SUMMARY:Algebra: Lecture",
+		"LOCATION:B 2.02",
+	}, series[1], "moved meeting does not match")
+	assert.Equal(t, []string{
+		"UID:" + courseID + "-1@eduboard",
+		"DTSTART:20181005T100000Z",
+		"RRULE:FREQ=WEEKLY;INTERVAL=1;BYDAY=FR",
+		"SUMMARY:Algebra",
+	}, series[2], "open series does not match")
+
+	course.Schedules[1].Day = 9
+	err, calendar = s.ExportCalendar("My courses", []eduboard.Course{course})
+	assert.Nil(t, err, "failed on invalid schedule")
+	lines = strings.Split(strings.TrimSuffix(string(calendar), "\r\n"), "\r\n")
+	assert.Len(t, eventsOf(lines), 2, "invalid schedule was exported")
+}
+
+func TestScheduleService_ExportCalendar_TimeZone(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	course := eduboard.Course{ID: bson.ObjectIdHex(courseID), Title: "Algebra", Schedules: []eduboard.Schedule{
+		{ID: bson.ObjectIdHex(scheduleID), Day: time.Monday, Start: start, TimeZone: "Europe/Berlin",
+			TermStart: "2018-10-01", TermEnd: "2019-01-31"},
+	}}
+
+	err, calendar := New(newRepository(), newRoomRepository(), nil).ExportCalendar("Algebra", []eduboard.Course{course})
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	lines := strings.Split(string(calendar), "\r\n")
+	assert.Contains(t, lines, "TZID:Europe/Berlin", "time zone is missing")
+	assert.Contains(t, lines, "DTSTART;TZID=Europe/Berlin:20181001T120000", "series does not start in local time")
+	assert.Contains(t, lines, "RRULE:FREQ=WEEKLY;INTERVAL=1;BYDAY=MO;UNTIL=20190131T225959Z", "series does not end in UTC")
+}
+
+// eventsOf returns the properties of all events except DTSTAMP.
+func eventsOf(lines []string) [][]string {
+	events := [][]string{}
+	var event []string
+	for _, l := range lines {
+		switch {
+		case l == "BEGIN:VEVENT":
+			event = []string{}
+		case l == "END:VEVENT":
+			events = append(events, event)
+			event = nil
+		case event != nil && !strings.HasPrefix(l, "DTSTAMP:"):
+			event = append(event, l)
+		}
+	}
+	return events
+}
@@ -0,0 +1,176 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"sort"
+	"time"
+)
+
+// recurrence is a validated schedule with its time zone and term resolved.
+type recurrence struct {
+	schedule eduboard.Schedule
+	loc      *time.Location
+	// first and last are the midnights of the first meeting day and of the end of the term. last is zero for open terms.
+	first time.Time
+	last  time.Time
+	// step is the number of days between two meetings.
+	step int
+}
+
+func newRecurrence(s eduboard.Schedule) (recurrence, error) {
+	if s.Day < time.Sunday || s.Day > time.Saturday {
+		return recurrence{}, errors.Wrapf(eduboard.ErrInvalidInput, "invalid day %d", s.Day)
+	}
+	if s.Start.IsZero() {
+		return recurrence{}, errors.Wrap(eduboard.ErrInvalidInput, "missing start")
+	}
+	if s.Duration < 0 || s.Interval < 0 {
+		return recurrence{}, errors.Wrap(eduboard.ErrInvalidInput, "negative duration or interval")
+	}
+
+	r := recurrence{schedule: s, loc: time.UTC, step: 7}
+	if s.Interval > 1 {
+		r.step = 7 * s.Interval
+	}
+	if s.TimeZone != "" {
+		loc, err := time.LoadLocation(s.TimeZone)
+		if err != nil {
+			return recurrence{}, errors.Wrapf(eduboard.ErrInvalidInput, "unknown time zone %s", s.TimeZone)
+		}
+		r.loc = loc
+	}
+
+	r.first = midnight(s.Start.In(r.loc))
+	if s.TermStart != "" {
+		day, err := time.ParseInLocation(eduboard.DateFormat, s.TermStart, r.loc)
+		if err != nil {
+			return recurrence{}, errors.Wrapf(eduboard.ErrInvalidInput, "invalid term start %s", s.TermStart)
+		}
+		r.first = day
+	}
+	r.first = r.first.AddDate(0, 0, (int(s.Day)-int(r.first.Weekday())+7)%7)
+
+	if s.TermEnd != "" {
+		day, err := time.ParseInLocation(eduboard.DateFormat, s.TermEnd, r.loc)
+		if err != nil {
+			return recurrence{}, errors.Wrapf(eduboard.ErrInvalidInput, "invalid term end %s", s.TermEnd)
+		}
+		if day.Before(r.first) {
+			return recurrence{}, errors.Wrapf(eduboard.ErrInvalidInput, "term ends before the first meeting on %s", r.first.Format(eduboard.DateFormat))
+		}
+		r.last = day
+	}
+
+	dates := map[string]bool{}
+	for _, e := range s.Exceptions {
+		if !r.meets(e.Date) {
+			return recurrence{}, errors.Wrapf(eduboard.ErrInvalidInput, "no meeting on %s", e.Date)
+		}
+		if dates[e.Date] {
+			return recurrence{}, errors.Wrapf(eduboard.ErrInvalidInput, "several exceptions on %s", e.Date)
+		}
+		dates[e.Date] = true
+		if e.Duration < 0 {
+			return recurrence{}, errors.Wrap(eduboard.ErrInvalidInput, "negative duration")
+		}
+	}
+	return r, nil
+}
+
+// meets reports whether there is a regular meeting on date.
+func (r recurrence) meets(date string) bool {
+	day, err := time.ParseInLocation(eduboard.DateFormat, date, r.loc)
+	if err != nil || day.Before(r.first) || (!r.last.IsZero() && day.After(r.last)) {
+		return false
+	}
+	return days(r.first, day)%r.step == 0
+}
+
+// occurrences returns all meetings that overlap the range from to, sorted by start.
+func (r recurrence) occurrences(from time.Time, to time.Time) []eduboard.Occurrence {
+	exceptions := map[string]eduboard.ScheduleException{}
+	for _, e := range r.schedule.Exceptions {
+		exceptions[e.Date] = e
+	}
+
+	result := []eduboard.Occurrence{}
+	add := func(o eduboard.Occurrence) {
+		if o.Start.Before(to) && o.End.After(from) {
+			result = append(result, o)
+		}
+	}
+
+	// Meetings starting before from may last into the range.
+	day := midnight(from.Add(-r.schedule.Duration).In(r.loc))
+	if day.Before(r.first) {
+		day = r.first
+	} else {
+		day = r.first.AddDate(0, 0, (days(r.first, day)+r.step-1)/r.step*r.step)
+	}
+
+	for ; day.Before(to) && (r.last.IsZero() || !day.After(r.last)); day = day.AddDate(0, 0, r.step) {
+		if _, ok := exceptions[day.Format(eduboard.DateFormat)]; !ok {
+			add(r.regular(day))
+		}
+	}
+
+	// Moved meetings are looked at separately, as they may be moved into the range from far away.
+	for _, e := range exceptions {
+		add(r.exception(e))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// exception returns the meeting on the date of e, cancelled or moved as e says.
+func (r recurrence) exception(e eduboard.ScheduleException) eduboard.Occurrence {
+	day, _ := time.ParseInLocation(eduboard.DateFormat, e.Date, r.loc)
+	o := r.regular(day)
+	if e.Cancelled {
+		o.Cancelled = true
+		return o
+	}
+
+	o.Moved = true
+	if e.Start != nil {
+		o.Start = e.Start.In(r.loc)
+	}
+	duration := r.schedule.Duration
+	if e.Duration > 0 {
+		duration = e.Duration
+	}
+	o.End = o.Start.Add(duration)
+	if e.Room != "" {
+		o.Room = e.Room
+	}
+	return o
+}
+
+// regular returns the meeting on day as scheduled.
+func (r recurrence) regular(day time.Time) eduboard.Occurrence {
+	clock := r.schedule.Start.In(r.loc)
+	start := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, r.loc)
+	return eduboard.Occurrence{
+		ScheduleID: r.schedule.ID,
+		Date:       day.Format(eduboard.DateFormat),
+		Start:      start,
+		End:        start.Add(r.schedule.Duration),
+		Title:      r.schedule.Title,
+		Room:       r.schedule.Room,
+	}
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// days returns the number of calendar days from a to b. Days are counted in UTC, where every day has 24 hours.
+func days(a time.Time, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
@@ -0,0 +1,135 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func dates(occurrences []eduboard.Occurrence) []string {
+	result := []string{}
+	for _, o := range occurrences {
+		result = append(result, o.Date)
+	}
+	return result
+}
+
+func TestNewRecurrence(t *testing.T) {
+	start := time.Date(2018, 10, 15, 10, 0, 0, 0, time.UTC)
+
+	var testCases = []struct {
+		name     string
+		schedule eduboard.Schedule
+		valid    bool
+	}{
+		{"weekly", eduboard.Schedule{Day: time.Monday, Start: start}, true},
+		{"term", eduboard.Schedule{Day: time.Monday, Start: start, TermStart: "2018-10-01", TermEnd: "2019-02-01"}, true},
+		{"time zone", eduboard.Schedule{Day: time.Monday, Start: start, TimeZone: "Europe/Berlin"}, true},
+		{"exception", eduboard.Schedule{Day: time.Monday, Start: start, Exceptions: []eduboard.ScheduleException{{Date: "2018-10-22", Cancelled: true}}}, true},
+		{"bad day", eduboard.Schedule{Day: 7, Start: start}, false},
+		{"missing start", eduboard.Schedule{Day: time.Monday}, false},
+		{"negative interval", eduboard.Schedule{Day: time.Monday, Start: start, Interval: -1}, false},
+		{"negative duration", eduboard.Schedule{Day: time.Monday, Start: start, Duration: -time.Hour}, false},
+		{"unknown time zone", eduboard.Schedule{Day: time.Monday, Start: start, TimeZone: "Mars/Olympus"}, false},
+		{"bad term start", eduboard.Schedule{Day: time.Monday, Start: start, TermStart: "01.10.2018"}, false},
+		{"term ends early", eduboard.Schedule{Day: time.Monday, Start: start, TermStart: "2018-10-02", TermEnd: "2018-10-07"}, false},
+		{"exception off schedule", eduboard.Schedule{Day: time.Monday, Start: start, Exceptions: []eduboard.ScheduleException{{Date: "2018-10-23"}}}, false},
+		{"duplicate exception", eduboard.Schedule{Day: time.Monday, Start: start, Exceptions: []eduboard.ScheduleException{{Date: "2018-10-22"}, {Date: "2018-10-22"}}}, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := newRecurrence(v.schedule)
+			if v.valid {
+				assert.Nil(t, err, "returned error when it shouldn't")
+				return
+			}
+			assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "error does not match")
+		})
+	}
+}
+
+func TestRecurrence_occurrences(t *testing.T) {
+	start := time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC)
+	moved := time.Date(2018, 12, 3, 14, 0, 0, 0, time.UTC)
+	from := time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	var testCases = []struct {
+		name     string
+		schedule eduboard.Schedule
+		from     time.Time
+		to       time.Time
+		expected []string
+	}{
+		{"weekly", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour},
+			from, from.AddDate(0, 0, 21), []string{"2018-10-01", "2018-10-08", "2018-10-15"}},
+		{"starts on weekday after start", eduboard.Schedule{Day: time.Wednesday, Start: start, Duration: time.Hour},
+			from, from.AddDate(0, 0, 14), []string{"2018-10-03", "2018-10-10"}},
+		{"biweekly", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour, Interval: 2},
+			from, from.AddDate(0, 0, 42), []string{"2018-10-01", "2018-10-15", "2018-10-29"}},
+		{"biweekly from the middle", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour, Interval: 2},
+			from.AddDate(0, 0, 7), from.AddDate(0, 0, 42), []string{"2018-10-15", "2018-10-29"}},
+		{"term", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour, TermStart: "2018-10-10", TermEnd: "2018-10-29"},
+			from, from.AddDate(0, 1, 0), []string{"2018-10-15", "2018-10-22", "2018-10-29"}},
+		{"ongoing meeting", eduboard.Schedule{Day: time.Monday, Start: start, Duration: 2 * time.Hour},
+			start.Add(time.Hour), start.AddDate(0, 0, 1), []string{"2018-10-01"}},
+		{"before first meeting", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour},
+			from.AddDate(0, 0, -14), from, []string{}},
+		{"cancelled", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour,
+			Exceptions: []eduboard.ScheduleException{{Date: "2018-10-08", Cancelled: true}}},
+			from, from.AddDate(0, 0, 14), []string{"2018-10-01", "2018-10-08"}},
+		{"moved into range", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour,
+			Exceptions: []eduboard.ScheduleException{{Date: "2018-12-03", Start: &moved}}},
+			moved.AddDate(0, 0, -1), moved.AddDate(0, 0, 1), []string{"2018-12-03"}},
+		{"moved out of range", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour,
+			Exceptions: []eduboard.ScheduleException{{Date: "2018-10-08", Start: &moved}}},
+			from, from.AddDate(0, 0, 14), []string{"2018-10-01"}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r, err := newRecurrence(v.schedule)
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.expected, dates(r.occurrences(v.from, v.to)), "occurrences do not match")
+		})
+	}
+}
+
+func TestRecurrence_occurrences_Exceptions(t *testing.T) {
+	start := time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC)
+	moved := time.Date(2018, 10, 10, 12, 0, 0, 0, time.UTC)
+	r, err := newRecurrence(eduboard.Schedule{ID: "1", Day: time.Monday, Start: start, Duration: time.Hour, Room: "EN 154", Title: "Lecture",
+		Exceptions: []eduboard.ScheduleException{
+			{Date: "2018-10-08", Start: &moved, Duration: 2 * time.Hour, Room: "MA 001"},
+			{Date: "2018-10-15", Cancelled: true},
+		}})
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	occurrences := r.occurrences(start, start.AddDate(0, 0, 21))
+	assert.Equal(t, []eduboard.Occurrence{
+		{ScheduleID: "1", Date: "2018-10-01", Start: start, End: start.Add(time.Hour), Title: "Lecture", Room: "EN 154"},
+		{ScheduleID: "1", Date: "2018-10-08", Start: moved, End: moved.Add(2 * time.Hour), Title: "Lecture", Room: "MA 001", Moved: true},
+		{ScheduleID: "1", Date: "2018-10-15", Start: start.AddDate(0, 0, 14), End: start.AddDate(0, 0, 14).Add(time.Hour), Title: "Lecture", Room: "EN 154", Cancelled: true},
+	}, occurrences, "occurrences do not match")
+}
+
+func TestRecurrence_occurrences_DaylightSavingTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	// Summer time ends on 2018-10-28, the meeting stays at 10:00 local time.
+	start := time.Date(2018, 10, 22, 10, 0, 0, 0, berlin)
+	r, err := newRecurrence(eduboard.Schedule{Day: time.Monday, Start: start.UTC(), Duration: time.Hour, TimeZone: "Europe/Berlin"})
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	occurrences := r.occurrences(start, start.AddDate(0, 0, 14))
+	assert.Len(t, occurrences, 2, "unexpected number of occurrences")
+	for _, o := range occurrences {
+		assert.Equal(t, 10, o.Start.In(berlin).Hour(), "meeting did not stay at local time")
+	}
+	assert.Equal(t, 169*time.Hour, occurrences[1].Start.Sub(occurrences[0].Start), "week did not have an extra hour")
+}
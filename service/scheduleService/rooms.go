@@ -0,0 +1,232 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"sort"
+	"time"
+)
+
+// conflictHorizon limits how far ahead schedules are checked for room conflicts, as open terms never end.
+const conflictHorizon = eduboard.MaxOccurrenceRange
+
+// CheckSchedules checks schedules that are about to replace all schedules of course and calls store to replace them.
+// Afterwards the data of meetings that no longer take place is deleted.
+// It fails with eduboard.ErrInvalidInput for invalid schedules or unknown rooms and with a
+// *eduboard.RoomConflictError if they clash with each other or with other courses.
+func (sS ScheduleService) CheckSchedules(course eduboard.Course, schedules []eduboard.Schedule, store func() error) error {
+	for _, s := range schedules {
+		if _, err := newRecurrence(s); err != nil {
+			return err
+		}
+	}
+
+	sS.bookings.Lock()
+	defer sS.bookings.Unlock()
+	if err := sS.checkRooms(course, nil, schedules); err != nil {
+		return err
+	}
+	if err := store(); err != nil {
+		return err
+	}
+	return sS.deleteCancelled(course.ID.Hex())
+}
+
+// CheckRestore checks that the schedules of an archived course do not clash with active courses, which may have
+// booked its rooms in the meantime, and calls store to restore it. Schedules stored before they were validated have
+// no meetings and are left out.
+func (sS ScheduleService) CheckRestore(course eduboard.Course, store func() error) error {
+	schedules := []eduboard.Schedule{}
+	for _, s := range course.Schedules {
+		if _, err := newRecurrence(s); err == nil {
+			schedules = append(schedules, s)
+		}
+	}
+
+	sS.bookings.Lock()
+	defer sS.bookings.Unlock()
+	if err := sS.checkRooms(course, nil, schedules); err != nil {
+		return err
+	}
+	return store()
+}
+
+// GetRoomOccupancy returns all meetings of active courses that take place in a room between from and to, sorted by start.
+func (sS ScheduleService) GetRoomOccupancy(roomID string, from time.Time, to time.Time) (error, []eduboard.Occurrence) {
+	if !to.After(from) || to.Sub(from) > eduboard.MaxOccurrenceRange {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "invalid range %s to %s", from, to), []eduboard.Occurrence{}
+	}
+
+	err, room := sS.RR.FindOneByID(roomID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding room %s", roomID), []eduboard.Occurrence{}
+	}
+
+	err, courses := sS.CR.FindMany(inRooms([]string{room.Name}))
+	if err != nil {
+		return errors.Wrapf(err, "error finding courses in room %s", room.Name), []eduboard.Occurrence{}
+	}
+
+	occurrences := []eduboard.Occurrence{}
+	for _, c := range courses {
+		for _, s := range c.Schedules {
+			r, err := newRecurrence(s)
+			if err != nil {
+				continue
+			}
+			for _, o := range meetings(r, from, to) {
+				if o.Room == room.Name {
+					o.CourseID = c.ID
+					occurrences = append(occurrences, o)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return nil, occurrences
+}
+
+// checkRooms checks that the rooms of changed schedules are registered and not in use at the time of their meetings,
+// neither by the other schedules of course nor by other active courses. Past meetings are not checked.
+// Rooms the course already uses need not be registered, such that schedules from before the registry can still be edited.
+func (sS ScheduleService) checkRooms(course eduboard.Course, others []eduboard.Schedule, changed []eduboard.Schedule) error {
+	used := map[string]bool{}
+	for _, s := range course.Schedules {
+		for _, name := range roomsOf(s) {
+			used[name] = true
+		}
+	}
+
+	rooms := []string{}
+	seen := map[string]bool{}
+	for _, s := range changed {
+		for _, name := range roomsOf(s) {
+			if seen[name] {
+				continue
+			}
+			if !used[name] {
+				if err, _ := sS.RR.FindByName(name); err != nil {
+					return errors.Wrapf(eduboard.ErrInvalidInput, "unknown room %s", name)
+				}
+			}
+			seen[name] = true
+			rooms = append(rooms, name)
+		}
+	}
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	query := inRooms(rooms)
+	query["_id"] = bson.M{"$ne": course.ID}
+	err, courses := sS.CR.FindMany(query)
+	if err != nil {
+		return errors.Wrap(err, "error finding courses in the same rooms")
+	}
+
+	now := time.Now()
+	conflicts := []eduboard.RoomConflict{}
+	for k, s := range changed {
+		r, err := newRecurrence(s)
+		if err != nil {
+			return err
+		}
+		from := latest(now, r.first)
+		to := from.Add(conflictHorizon)
+		mine := meetings(r, from, to)
+
+		check := func(c eduboard.Course, other eduboard.Schedule) {
+			ro, err := newRecurrence(other)
+			if err != nil {
+				// Invalid schedules stored before they were validated have no meetings.
+				return
+			}
+			if o, ok := clash(mine, meetings(ro, from, to)); ok {
+				conflicts = append(conflicts, eduboard.RoomConflict{
+					Room:        o.Room,
+					CourseID:    c.ID,
+					CourseTitle: c.Title,
+					ScheduleID:  other.ID,
+					Start:       o.Start,
+					End:         o.End,
+				})
+			}
+		}
+
+		for _, other := range others {
+			check(course, other)
+		}
+		for _, other := range changed[:k] {
+			check(course, other)
+		}
+		for _, c := range courses {
+			for _, other := range c.Schedules {
+				check(c, other)
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &eduboard.RoomConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// roomsOf returns the rooms a schedule takes place in, including those of moved meetings.
+func roomsOf(s eduboard.Schedule) []string {
+	names := []string{}
+	if s.Room != "" {
+		names = append(names, s.Room)
+	}
+	for _, e := range s.Exceptions {
+		if !e.Cancelled && e.Room != "" {
+			names = append(names, e.Room)
+		}
+	}
+	return names
+}
+
+// inRooms returns a query for active courses with meetings in any of rooms, including moved ones.
+func inRooms(rooms []string) bson.M {
+	return bson.M{
+		"archived": bson.M{"$ne": true},
+		"$or": []bson.M{
+			{"schedules.room": bson.M{"$in": rooms}},
+			{"schedules.exceptions.room": bson.M{"$in": rooms}},
+		},
+	}
+}
+
+// meetings returns the meetings of r between from and to that take place in a room.
+func meetings(r recurrence, from time.Time, to time.Time) []eduboard.Occurrence {
+	result := []eduboard.Occurrence{}
+	for _, o := range r.occurrences(from, to) {
+		if !o.Cancelled && o.Room != "" {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// clash returns the first meeting of b that takes place in the same room at the same time as a meeting of a.
+// Both must be sorted by start. Meetings without a duration are ignored, as their end is unknown.
+func clash(a []eduboard.Occurrence, b []eduboard.Occurrence) (eduboard.Occurrence, bool) {
+	for _, x := range a {
+		if !x.End.After(x.Start) {
+			continue
+		}
+		for _, y := range b {
+			if !y.Start.Before(x.End) {
+				break
+			}
+			if y.End.After(x.Start) && y.End.After(y.Start) && y.Room == x.Room {
+				return y, true
+			}
+		}
+	}
+	return eduboard.Occurrence{}, false
+}
@@ -0,0 +1,222 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+var otherID = "5b23bbdc2bfa844c41a9f136"
+
+// nextMonday returns the date of a Monday in the near future, which is within the range checked for conflicts.
+func nextMonday() string {
+	day := midnight(time.Now().UTC()).AddDate(0, 0, 7)
+	return day.AddDate(0, 0, (int(time.Monday)-int(day.Weekday())+7)%7).Format(eduboard.DateFormat)
+}
+
+func TestScheduleService_AddSchedule_Rooms(t *testing.T) {
+	// The other course meets biweekly on Mondays from 10:30 to 12:00 in EN 154 and on Tuesdays at 10:00 in MA 001.
+	other := eduboard.Course{ID: bson.ObjectIdHex(otherID), Title: "Analysis", Schedules: []eduboard.Schedule{
+		{ID: "a", Day: time.Monday, Start: start.Add(30 * time.Minute), Duration: 90 * time.Minute, Room: "EN 154", Interval: 2},
+		{ID: "b", Day: time.Tuesday, Start: start.AddDate(0, 0, 1), Duration: time.Hour, Room: "MA 001"},
+	}}
+
+	var testCases = []struct {
+		name     string
+		schedule eduboard.Schedule
+		invalid  bool
+		conflict bool
+	}{
+		{"free room", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour, Room: "MA 001"}, false, false},
+		{"unknown room", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour, Room: "Attic"}, true, false},
+		{"overlap", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour, Room: "EN 154"}, false, true},
+		{"back to back", eduboard.Schedule{Day: time.Monday, Start: start, Duration: 30 * time.Minute, Room: "EN 154"}, false, false},
+		{"other weeks", eduboard.Schedule{Day: time.Monday, Start: start.AddDate(0, 0, 7), Duration: time.Hour, Room: "EN 154", Interval: 2}, false, false},
+		{"same weeks", eduboard.Schedule{Day: time.Monday, Start: start.AddDate(0, 0, 14), Duration: time.Hour, Room: "EN 154", Interval: 4}, false, true},
+		{"no duration", eduboard.Schedule{Day: time.Monday, Start: start.Add(time.Hour), Room: "EN 154"}, false, false},
+		{"term over", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour, Room: "EN 154", TermEnd: "2018-12-31"}, false, false},
+		{"moved into", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour, Room: "MA 001",
+			Exceptions: []eduboard.ScheduleException{{Date: nextMonday(), Room: "EN 154"}}}, false, false},
+		{"moved onto", eduboard.Schedule{Day: time.Monday, Start: start, Duration: time.Hour,
+			Exceptions: []eduboard.ScheduleException{{Date: nextMonday(), Start: timeOn(nextMonday(), 1, 10), Room: "MA 001"}}}, false, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			var query bson.M
+			r.FindManyFn = func(q bson.M) (error, []eduboard.Course) {
+				query = q
+				return nil, []eduboard.Course{other}
+			}
+
+			err, _ := New(r, newRoomRepository(), nil).AddSchedule(courseID, "teacher", v.schedule)
+			if v.invalid {
+				assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "error does not match")
+				assert.False(t, r.UpdateFnInvoked, "Update was invoked")
+				return
+			}
+			if !v.conflict {
+				assert.Nil(t, err, "returned error when it shouldn't")
+				assert.True(t, r.UpdateFnInvoked, "Update was not invoked")
+				return
+			}
+
+			conflict, ok := errors.Cause(err).(*eduboard.RoomConflictError)
+			assert.True(t, ok, "did not return room conflict")
+			assert.False(t, r.UpdateFnInvoked, "Update was invoked")
+			assert.Equal(t, bson.M{"$ne": bson.ObjectIdHex(courseID)}, query["_id"], "query does not exclude the course itself")
+			if assert.Len(t, conflict.Conflicts, 1, "unexpected number of conflicts") {
+				assert.Equal(t, other.ID, conflict.Conflicts[0].CourseID, "clashing course does not match")
+				assert.Equal(t, "Analysis", conflict.Conflicts[0].CourseTitle, "clashing course title does not match")
+			}
+		})
+	}
+}
+
+// timeOn returns the time of day h on the weekday offset days after the Monday date.
+func timeOn(date string, offset int, h int) *time.Time {
+	day, _ := time.Parse(eduboard.DateFormat, date)
+	t := day.AddDate(0, 0, offset).Add(time.Duration(h) * time.Hour)
+	return &t
+}
+
+func TestScheduleService_SetException_Rooms(t *testing.T) {
+	r := newRepository()
+	course := eduboard.Course{ID: bson.ObjectIdHex(courseID), Title: "Algebra", Members: members, Schedules: []eduboard.Schedule{
+		{ID: bson.ObjectIdHex(scheduleID), Day: time.Monday, Start: start, Duration: time.Hour, Room: "MA 001"},
+		{ID: "b", Day: time.Tuesday, Start: start.AddDate(0, 0, 1), Duration: time.Hour, Room: "EN 154"},
+	}}
+	r.FindFn = func(id string) (error, eduboard.Course) {
+		return nil, course
+	}
+
+	// Moving a meeting onto another schedule of the same course clashes as well.
+	err, _ := New(r, newRoomRepository(), nil).SetException(courseID, scheduleID, "teacher",
+		eduboard.ScheduleException{Date: nextMonday(), Start: timeOn(nextMonday(), 1, 10), Room: "EN 154"})
+	conflict, ok := errors.Cause(err).(*eduboard.RoomConflictError)
+	if assert.True(t, ok, "did not return room conflict") {
+		assert.Equal(t, course.ID, conflict.Conflicts[0].CourseID, "clashing course does not match")
+		assert.Equal(t, bson.ObjectId("b"), conflict.Conflicts[0].ScheduleID, "clashing schedule does not match")
+	}
+	assert.False(t, r.UpdateFnInvoked, "Update was invoked")
+
+	// Cancelled meetings free their room.
+	err, _ = New(r, newRoomRepository(), nil).SetException(courseID, scheduleID, "teacher",
+		eduboard.ScheduleException{Date: nextMonday(), Cancelled: true})
+	assert.Nil(t, err, "returned error when it shouldn't")
+}
+
+func TestScheduleService_CheckSchedules(t *testing.T) {
+	s := New(newRepository(), newRoomRepository(), nil)
+	course := eduboard.Course{ID: bson.ObjectIdHex(courseID)}
+
+	stored := false
+	store := func() error {
+		stored = true
+		return nil
+	}
+
+	err := s.CheckSchedules(course, []eduboard.Schedule{
+		{ID: "a", Day: time.Monday, Start: start, Duration: time.Hour, Room: "EN 154"},
+		{ID: "b", Day: time.Monday, Start: start, Duration: time.Hour, Room: "MA 001"},
+	}, store)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.True(t, stored, "schedules were not stored")
+
+	err = s.CheckSchedules(course, []eduboard.Schedule{
+		{ID: "a", Day: time.Monday, Start: start, Duration: time.Hour, Room: "EN 154"},
+		{ID: "b", Day: time.Monday, Start: start.Add(30 * time.Minute), Duration: time.Hour, Room: "EN 154"},
+	}, store)
+	conflict, ok := err.(*eduboard.RoomConflictError)
+	if assert.True(t, ok, "did not return room conflict") {
+		assert.Equal(t, bson.ObjectId("a"), conflict.Conflicts[0].ScheduleID, "clashing schedule does not match")
+	}
+
+	err = s.CheckSchedules(course, []eduboard.Schedule{{Day: 9}}, store)
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "error does not match")
+
+	// Rooms from before the registry stay usable for the course that already meets there.
+	stored = false
+	legacy := eduboard.Course{ID: bson.ObjectIdHex(courseID), Schedules: []eduboard.Schedule{{ID: "a", Day: time.Monday, Start: start, Room: "Attic"}}}
+	err = s.CheckSchedules(legacy, []eduboard.Schedule{{ID: "a", Day: time.Tuesday, Start: start.AddDate(0, 0, 1), Room: "Attic"}}, store)
+	assert.Nil(t, err, "rejected a room the course already uses")
+	assert.True(t, stored, "schedules were not stored")
+	err = s.CheckSchedules(legacy, []eduboard.Schedule{{ID: "a", Day: time.Monday, Start: start, Room: "Cellar"}}, store)
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "accepted an unknown room")
+}
+
+func TestScheduleService_CheckRestore(t *testing.T) {
+	// Another course booked EN 154 on Mondays while the course was archived.
+	other := eduboard.Course{ID: bson.ObjectIdHex(otherID), Title: "Analysis", Schedules: []eduboard.Schedule{
+		{ID: "c", Day: time.Monday, Start: start, Duration: time.Hour, Room: "EN 154"},
+	}}
+	r := newRepository()
+	r.FindManyFn = func(query bson.M) (error, []eduboard.Course) {
+		return nil, []eduboard.Course{other}
+	}
+	s := New(r, newRoomRepository(), nil)
+
+	stored := false
+	store := func() error {
+		stored = true
+		return nil
+	}
+
+	free := eduboard.Course{ID: bson.ObjectIdHex(courseID), Archived: true, Schedules: []eduboard.Schedule{
+		{ID: "a", Day: time.Tuesday, Start: start.AddDate(0, 0, 1), Duration: time.Hour, Room: "EN 154"},
+		// Invalid schedules stored before they were validated do not keep the course from being restored.
+		{ID: "b", Day: 9, Room: "EN 154"},
+	}}
+	assert.Nil(t, s.CheckRestore(free, store), "returned error when it shouldn't")
+	assert.True(t, stored, "course was not restored")
+
+	stored = false
+	booked := eduboard.Course{ID: bson.ObjectIdHex(courseID), Archived: true, Schedules: []eduboard.Schedule{
+		{ID: "a", Day: time.Monday, Start: start, Duration: time.Hour, Room: "EN 154"},
+	}}
+	err := s.CheckRestore(booked, store)
+	conflict, ok := err.(*eduboard.RoomConflictError)
+	if assert.True(t, ok, "did not return room conflict") {
+		assert.Equal(t, bson.ObjectId("c"), conflict.Conflicts[0].ScheduleID, "clashing schedule does not match")
+	}
+	assert.False(t, stored, "course was restored despite a conflict")
+}
+
+func TestScheduleService_GetRoomOccupancy(t *testing.T) {
+	moved := time.Date(2018, 10, 2, 8, 0, 0, 0, time.UTC)
+	courses := []eduboard.Course{
+		{ID: bson.ObjectIdHex(courseID), Schedules: []eduboard.Schedule{
+			{ID: "a", Day: time.Monday, Start: start, Duration: time.Hour, Room: "EN 154",
+				Exceptions: []eduboard.ScheduleException{{Date: "2018-10-08", Cancelled: true}}},
+			{ID: "b", Day: time.Wednesday, Start: start, Duration: time.Hour, Room: "MA 001"},
+		}},
+		{ID: bson.ObjectIdHex(otherID), Schedules: []eduboard.Schedule{
+			{ID: "c", Day: time.Friday, Start: start, Duration: time.Hour, Room: "MA 001",
+				Exceptions: []eduboard.ScheduleException{{Date: "2018-10-05", Start: &moved, Room: "EN 154"}}},
+		}},
+	}
+	r := newRepository()
+	r.FindManyFn = func(query bson.M) (error, []eduboard.Course) {
+		return nil, courses
+	}
+	s := New(r, newRoomRepository(), nil)
+
+	err, occurrences := s.GetRoomOccupancy(roomID, start.AddDate(0, 0, -1), start.AddDate(0, 0, 13))
+	assert.Nil(t, err, "returned error when it shouldn't")
+	if assert.Len(t, occurrences, 2, "unexpected number of occurrences") {
+		assert.Equal(t, "2018-10-01", occurrences[0].Date, "first occurrence does not match")
+		assert.Equal(t, bson.ObjectIdHex(courseID), occurrences[0].CourseID, "course of first occurrence does not match")
+		assert.Equal(t, moved, occurrences[1].Start, "moved occurrence does not match")
+		assert.Equal(t, bson.ObjectIdHex(otherID), occurrences[1].CourseID, "course of moved occurrence does not match")
+	}
+
+	err, _ = s.GetRoomOccupancy("unknown", start, start.AddDate(0, 0, 7))
+	assert.NotNil(t, err, "did not fail on unknown room")
+
+	err, _ = s.GetRoomOccupancy(roomID, start, start.AddDate(2, 0, 0))
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "error does not match")
+}
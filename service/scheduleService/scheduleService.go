@@ -0,0 +1,271 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"sort"
+	"sync"
+	"time"
+)
+
+type ScheduleService struct {
+	CR eduboard.CourseRepository
+	RR eduboard.RoomRepository
+	// Notifications is informed about schedule changes. It may be nil.
+	Notifications eduboard.NotificationCreator
+	// bookings is held while schedules are checked for room conflicts and stored, such that two changes can not
+	// take the same room at once. It is shared by all copies of the service, but not across processes.
+	bookings *sync.Mutex
+	// deleters are called whenever meetings of a course may have been cancelled.
+	deleters []eduboard.MeetingDataDeleter
+}
+
+func New(courseRepository eduboard.CourseRepository, roomRepository eduboard.RoomRepository, notifications eduboard.NotificationCreator, deleters ...eduboard.MeetingDataDeleter) ScheduleService {
+	return ScheduleService{
+		CR:            courseRepository,
+		RR:            roomRepository,
+		Notifications: notifications,
+		bookings:      &sync.Mutex{},
+		deleters:      deleters,
+	}
+}
+
+func (sS ScheduleService) GetSchedules(courseID string) (error, []eduboard.Schedule) {
+	err, course := sS.CR.FindOneByID(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", courseID), []eduboard.Schedule{}
+	}
+	if course.Schedules == nil {
+		return nil, []eduboard.Schedule{}
+	}
+	return nil, course.Schedules
+}
+
+// AddSchedule adds a new schedule to a course. Only staff may manage schedules.
+func (sS ScheduleService) AddSchedule(courseID string, userID string, schedule eduboard.Schedule) (error, eduboard.Schedule) {
+	sS.bookings.Lock()
+	defer sS.bookings.Unlock()
+
+	err, course := access.Manager(sS.CR, courseID, userID)
+	if err != nil {
+		return err, eduboard.Schedule{}
+	}
+
+	schedule.ID = bson.NewObjectId()
+	if _, err = newRecurrence(schedule); err != nil {
+		return err, eduboard.Schedule{}
+	}
+	if err = sS.checkRooms(course, course.Schedules, []eduboard.Schedule{schedule}); err != nil {
+		return err, eduboard.Schedule{}
+	}
+
+	if err, _ = sS.CR.Update(courseID, bson.M{"$push": bson.M{"schedules": schedule}}); err != nil {
+		return errors.Wrapf(err, "error adding schedule to course %s", courseID), eduboard.Schedule{}
+	}
+	sS.notify(course, userID, schedule.ID)
+	return nil, schedule
+}
+
+// UpdateSchedule replaces a schedule of a course, including its exceptions.
+func (sS ScheduleService) UpdateSchedule(courseID string, scheduleID string, userID string, schedule eduboard.Schedule) (error, eduboard.Schedule) {
+	return sS.change(courseID, scheduleID, userID, func(s *eduboard.Schedule) error {
+		schedule.ID = s.ID
+		*s = schedule
+		return nil
+	})
+}
+
+func (sS ScheduleService) DeleteSchedule(courseID string, scheduleID string, userID string) error {
+	sS.bookings.Lock()
+	defer sS.bookings.Unlock()
+
+	err, course := access.Manager(sS.CR, courseID, userID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := find(course.Schedules, scheduleID); !ok {
+		return errors.Errorf("course %s has no schedule %s", courseID, scheduleID)
+	}
+
+	if err, _ = sS.CR.Update(courseID, bson.M{"$pull": bson.M{"schedules": bson.M{"id": bson.ObjectIdHex(scheduleID)}}}); err != nil {
+		return errors.Wrapf(err, "error deleting schedule %s", scheduleID)
+	}
+	sS.notify(course, userID, bson.ObjectIdHex(scheduleID))
+	return sS.deleteCancelled(courseID)
+}
+
+// SetException cancels or moves the meeting of a schedule on exception.Date, replacing an earlier exception of that date.
+func (sS ScheduleService) SetException(courseID string, scheduleID string, userID string, exception eduboard.ScheduleException) (error, eduboard.Schedule) {
+	return sS.change(courseID, scheduleID, userID, func(s *eduboard.Schedule) error {
+		exceptions := []eduboard.ScheduleException{exception}
+		for _, e := range s.Exceptions {
+			if e.Date != exception.Date {
+				exceptions = append(exceptions, e)
+			}
+		}
+		sort.Slice(exceptions, func(i, j int) bool {
+			return exceptions[i].Date < exceptions[j].Date
+		})
+		s.Exceptions = exceptions
+		return nil
+	})
+}
+
+// DeleteException restores the regular meeting of a schedule on date.
+func (sS ScheduleService) DeleteException(courseID string, scheduleID string, userID string, date string) (error, eduboard.Schedule) {
+	return sS.change(courseID, scheduleID, userID, func(s *eduboard.Schedule) error {
+		exceptions := []eduboard.ScheduleException{}
+		for _, e := range s.Exceptions {
+			if e.Date != date {
+				exceptions = append(exceptions, e)
+			}
+		}
+		if len(exceptions) == len(s.Exceptions) {
+			return errors.Errorf("schedule %s has no exception on %s", s.ID.Hex(), date)
+		}
+		s.Exceptions = exceptions
+		return nil
+	})
+}
+
+// GetOccurrences returns all meetings of a course that overlap the range from to, sorted by start.
+func (sS ScheduleService) GetOccurrences(courseID string, from time.Time, to time.Time) (error, []eduboard.Occurrence) {
+	if !to.After(from) || to.Sub(from) > eduboard.MaxOccurrenceRange {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "invalid range %s to %s", from, to), []eduboard.Occurrence{}
+	}
+
+	err, course := sS.CR.FindOneByID(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", courseID), []eduboard.Occurrence{}
+	}
+
+	occurrences := []eduboard.Occurrence{}
+	for _, s := range course.Schedules {
+		// Schedules stored before they were validated may not be expandable and are left out.
+		r, err := newRecurrence(s)
+		if err != nil {
+			continue
+		}
+		for _, o := range r.occurrences(from, to) {
+			o.CourseID = course.ID
+			occurrences = append(occurrences, o)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return nil, occurrences
+}
+
+// GetOccurrence returns the meeting of a schedule with the regular date date, cancelled or moved if an exception says so.
+func (sS ScheduleService) GetOccurrence(courseID string, scheduleID string, date string) (error, eduboard.Occurrence) {
+	err, course := sS.CR.FindOneByID(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding course %s", courseID), eduboard.Occurrence{}
+	}
+	k, ok := find(course.Schedules, scheduleID)
+	if !ok {
+		return errors.Wrapf(eduboard.ErrNotFound, "schedule %s does not belong to course %s", scheduleID, courseID), eduboard.Occurrence{}
+	}
+
+	r, err := newRecurrence(course.Schedules[k])
+	if err != nil {
+		return errors.Wrapf(err, "invalid schedule %s", scheduleID), eduboard.Occurrence{}
+	}
+	if !r.meets(date) {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "schedule %s has no meeting on %s", scheduleID, date), eduboard.Occurrence{}
+	}
+
+	var o eduboard.Occurrence
+	for _, e := range r.schedule.Exceptions {
+		if e.Date == date {
+			o = r.exception(e)
+		}
+	}
+	if o.Date == "" {
+		day, _ := time.ParseInLocation(eduboard.DateFormat, date, r.loc)
+		o = r.regular(day)
+	}
+	o.CourseID = course.ID
+	return nil, o
+}
+
+// change applies fn to a schedule of a course and stores the result if it is valid.
+func (sS ScheduleService) change(courseID string, scheduleID string, userID string, fn func(s *eduboard.Schedule) error) (error, eduboard.Schedule) {
+	sS.bookings.Lock()
+	defer sS.bookings.Unlock()
+
+	err, course := access.Manager(sS.CR, courseID, userID)
+	if err != nil {
+		return err, eduboard.Schedule{}
+	}
+
+	k, ok := find(course.Schedules, scheduleID)
+	if !ok {
+		return errors.Errorf("course %s has no schedule %s", courseID, scheduleID), eduboard.Schedule{}
+	}
+
+	schedule := course.Schedules[k]
+	if err = fn(&schedule); err != nil {
+		return err, eduboard.Schedule{}
+	}
+	if _, err = newRecurrence(schedule); err != nil {
+		return err, eduboard.Schedule{}
+	}
+
+	others := make([]eduboard.Schedule, 0, len(course.Schedules)-1)
+	others = append(others, course.Schedules[:k]...)
+	others = append(others, course.Schedules[k+1:]...)
+	if err = sS.checkRooms(course, others, []eduboard.Schedule{schedule}); err != nil {
+		return err, eduboard.Schedule{}
+	}
+
+	course.Schedules[k] = schedule
+	if err, _ = sS.CR.Update(courseID, bson.M{"$set": bson.M{"schedules": course.Schedules}}); err != nil {
+		return errors.Wrapf(err, "error updating schedule %s", scheduleID), eduboard.Schedule{}
+	}
+	sS.notify(course, userID, schedule.ID)
+	if err = sS.deleteCancelled(courseID); err != nil {
+		return err, eduboard.Schedule{}
+	}
+	return nil, schedule
+}
+
+// deleteCancelled deletes the data of meetings of a course that no longer take place. All meetings of the course are
+// checked, so a failed deletion is completed by the next change of its schedules.
+func (sS ScheduleService) deleteCancelled(courseID string) error {
+	for _, v := range sS.deleters {
+		if err := v.DeleteCancelled(courseID, sS); err != nil {
+			return errors.Wrapf(err, "error deleting data of cancelled meetings of course %s", courseID)
+		}
+	}
+	return nil
+}
+
+// notify tells the members of course that userID changed one of its schedules.
+func (sS ScheduleService) notify(course eduboard.Course, userID string, scheduleID bson.ObjectId) {
+	if sS.Notifications == nil {
+		return
+	}
+	sS.Notifications.CreateNotifications(course.MemberIDs(), eduboard.Notification{
+		Type:        eduboard.NotificationScheduleChanged,
+		CourseID:    course.ID,
+		CourseTitle: course.Title,
+		ScheduleID:  scheduleID,
+		ActorID:     userID,
+	})
+}
+
+// find returns the index of the schedule with the given ID.
+func find(schedules []eduboard.Schedule, scheduleID string) (int, bool) {
+	for k, s := range schedules {
+		if s.ID.Hex() == scheduleID {
+			return k, true
+		}
+	}
+	return 0, false
+}
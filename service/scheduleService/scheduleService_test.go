@@ -0,0 +1,337 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+var (
+	courseID   = "5b23bbdc2bfa844c41a9f134"
+	archivedID = "5b23bbdc2bfa844c41a9f135"
+	scheduleID = "5b23bbdc2bfa844c41a9f140"
+	roomID     = "5b23bbdc2bfa844c41a9f150"
+	start      = time.Date(2018, 10, 1, 10, 0, 0, 0, time.UTC)
+	members    = []eduboard.Member{{UserID: "teacher", Role: eduboard.RoleTeacher}, {UserID: "student", Role: eduboard.RoleStudent}}
+)
+
+// newRepository returns a course repository holding a course with one weekly schedule and an archived course.
+func newRepository() *mock.CourseRepository {
+	schedule := eduboard.Schedule{ID: bson.ObjectIdHex(scheduleID), Day: time.Monday, Start: start, Duration: time.Hour,
+		Exceptions: []eduboard.ScheduleException{{Date: "2018-10-15", Cancelled: true}}}
+
+	r := &mock.CourseRepository{}
+	r.FindFn = func(id string) (error, eduboard.Course) {
+		switch id {
+		case courseID:
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members, Schedules: []eduboard.Schedule{schedule}}
+		case archivedID:
+			return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Members: members, Archived: true}
+		}
+		return errors.New("not found"), eduboard.Course{}
+	}
+	r.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) {
+		return nil, eduboard.Course{}
+	}
+	r.FindManyFn = func(query bson.M) (error, []eduboard.Course) {
+		return nil, []eduboard.Course{}
+	}
+	return r
+}
+
+// newRoomRepository returns a room repository holding the rooms EN 154 and MA 001.
+func newRoomRepository() *mock.RoomRepository {
+	rooms := map[string]eduboard.Room{
+		roomID:                     {ID: bson.ObjectIdHex(roomID), Name: "EN 154"},
+		"5b23bbdc2bfa844c41a9f151": {ID: bson.ObjectIdHex("5b23bbdc2bfa844c41a9f151"), Name: "MA 001"},
+	}
+	return &mock.RoomRepository{
+		FindOneByIDFn: func(id string) (error, eduboard.Room) {
+			if room, ok := rooms[id]; ok {
+				return nil, room
+			}
+			return errors.New("not found"), eduboard.Room{}
+		},
+		FindByNameFn: func(name string) (error, eduboard.Room) {
+			for _, room := range rooms {
+				if room.Name == name {
+					return nil, room
+				}
+			}
+			return errors.New("not found"), eduboard.Room{}
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	r := mock.CourseRepository{}
+	rr := mock.RoomRepository{}
+	s := New(&r, &rr, nil)
+	assert.Equal(t, &r, s.CR, "course repository does not match")
+	assert.Equal(t, &rr, s.RR, "room repository does not match")
+}
+
+func TestScheduleService_GetSchedules(t *testing.T) {
+	s := New(newRepository(), newRoomRepository(), nil)
+
+	err, schedules := s.GetSchedules(courseID)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Len(t, schedules, 1, "unexpected number of schedules")
+
+	err, schedules = s.GetSchedules(archivedID)
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Equal(t, []eduboard.Schedule{}, schedules, "schedules should be empty")
+
+	err, _ = s.GetSchedules("")
+	assert.Error(t, err, "did not return error when expected")
+}
+
+func TestScheduleService_AddSchedule(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		course   string
+		user     string
+		schedule eduboard.Schedule
+		err      error
+		invoked  bool
+	}{
+		{"success", courseID, "teacher", eduboard.Schedule{Day: time.Tuesday, Start: start, Interval: 2}, nil, true},
+		{"invalid", courseID, "teacher", eduboard.Schedule{Day: time.Tuesday}, eduboard.ErrInvalidInput, false},
+		{"student", courseID, "student", eduboard.Schedule{Day: time.Tuesday, Start: start}, eduboard.ErrForbidden, false},
+		{"archived", archivedID, "teacher", eduboard.Schedule{Day: time.Tuesday, Start: start}, eduboard.ErrArchived, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			var change bson.M
+			r.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) {
+				change = update
+				return nil, eduboard.Course{}
+			}
+
+			err, schedule := New(r, newRoomRepository(), nil).AddSchedule(v.course, v.user, v.schedule)
+			assert.Equal(t, v.invoked, r.UpdateFnInvoked, "Update was not invoked as expected")
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.True(t, schedule.ID.Valid(), "schedule did not get an ID")
+			assert.Equal(t, bson.M{"$push": bson.M{"schedules": schedule}}, change, "update does not match")
+		})
+	}
+}
+
+func TestScheduleService_UpdateSchedule(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		schedule string
+		user     string
+		update   eduboard.Schedule
+		error    bool
+		invoked  bool
+	}{
+		{"success", scheduleID, "teacher", eduboard.Schedule{Day: time.Monday, Start: start, Room: "EN 154"}, false, true},
+		{"exception does not fit", scheduleID, "teacher", eduboard.Schedule{Day: time.Monday, Start: start, Interval: 2,
+			Exceptions: []eduboard.ScheduleException{{Date: "2018-10-08"}}}, true, false},
+		{"unknown schedule", "5b23bbdc2bfa844c41a9f141", "teacher", eduboard.Schedule{Day: time.Monday, Start: start}, true, false},
+		{"student", scheduleID, "student", eduboard.Schedule{Day: time.Monday, Start: start}, true, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			r := newRepository()
+			var change bson.M
+			r.UpdateFn = func(id string, update bson.M) (error, eduboard.Course) {
+				change = update
+				return nil, eduboard.Course{}
+			}
+
+			err, schedule := New(r, newRoomRepository(), nil).UpdateSchedule(courseID, v.schedule, v.user, v.update)
+			assert.Equal(t, v.invoked, r.UpdateFnInvoked, "Update was not invoked as expected")
+			if v.error {
+				assert.Error(t, err, "did not return error when expected")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, scheduleID, schedule.ID.Hex(), "schedule ID changed")
+			assert.Equal(t, "EN 154", schedule.Room, "schedule was not updated")
+			assert.Equal(t, bson.M{"$set": bson.M{"schedules": []eduboard.Schedule{schedule}}}, change, "update does not match")
+		})
+	}
+}
+
+func TestScheduleService_DeleteSchedule(t *testing.T) {
+	r := newRepository()
+	s := New(r, newRoomRepository(), nil)
+
+	err := s.DeleteSchedule(courseID, "5b23bbdc2bfa844c41a9f141", "teacher")
+	assert.Error(t, err, "did not return error when expected")
+	assert.False(t, r.UpdateFnInvoked, "Update was invoked")
+
+	err = s.DeleteSchedule(courseID, scheduleID, "student")
+	assert.Equal(t, eduboard.ErrForbidden, errors.Cause(err), "error does not match")
+
+	err = s.DeleteSchedule(courseID, scheduleID, "teacher")
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.True(t, r.UpdateFnInvoked, "Update was not invoked")
+}
+
+func TestScheduleService_Notifications(t *testing.T) {
+	var notifications []eduboard.Notification
+	n := &mock.NotificationService{}
+	n.CreateNotificationsFn = func(userIDs []string, notification eduboard.Notification) {
+		assert.Equal(t, []string{"teacher", "student"}, userIDs, "recipients do not match")
+		notifications = append(notifications, notification)
+	}
+	s := New(newRepository(), newRoomRepository(), n)
+
+	err, _ := s.UpdateSchedule(courseID, scheduleID, "student", eduboard.Schedule{Day: time.Monday, Start: start})
+	assert.Error(t, err, "did not return error when expected")
+	assert.False(t, n.CreateNotificationsFnInvoked, "notified about failed change")
+
+	err, _ = s.SetException(courseID, scheduleID, "teacher", eduboard.ScheduleException{Date: "2018-10-08", Cancelled: true})
+	assert.Nil(t, err, "returned error when it shouldn't")
+	err = s.DeleteSchedule(courseID, scheduleID, "teacher")
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	assert.Len(t, notifications, 2, "unexpected number of notifications")
+	for _, v := range notifications {
+		assert.Equal(t, eduboard.NotificationScheduleChanged, v.Type, "type does not match")
+		assert.Equal(t, courseID, v.CourseID.Hex(), "course does not match")
+		assert.Equal(t, scheduleID, v.ScheduleID.Hex(), "schedule does not match")
+		assert.Equal(t, "teacher", v.ActorID, "actor does not match")
+	}
+}
+
+func TestScheduleService_DeleteCancelled(t *testing.T) {
+	d := &mock.AttendanceService{}
+	d.DeleteCancelledFn = func(id string, of eduboard.OccurrenceFinder) error {
+		assert.Equal(t, courseID, id, "course does not match")
+		return nil
+	}
+	s := New(newRepository(), newRoomRepository(), nil, d)
+
+	err, _ := s.SetException(courseID, scheduleID, "student", eduboard.ScheduleException{Date: "2018-10-08", Cancelled: true})
+	assert.Error(t, err, "did not return error when expected")
+	assert.False(t, d.DeleteCancelledFnInvoked, "DeleteCancelled was invoked")
+
+	err, _ = s.SetException(courseID, scheduleID, "teacher", eduboard.ScheduleException{Date: "2018-10-08", Cancelled: true})
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.True(t, d.DeleteCancelledFnInvoked, "DeleteCancelled was not invoked")
+
+	d.DeleteCancelledFnInvoked = false
+	assert.Nil(t, s.DeleteSchedule(courseID, scheduleID, "teacher"), "returned error when it shouldn't")
+	assert.True(t, d.DeleteCancelledFnInvoked, "DeleteCancelled was not invoked")
+
+	d.DeleteCancelledFn = func(id string, of eduboard.OccurrenceFinder) error { return errors.New("error deleting sessions") }
+	err, _ = s.SetException(courseID, scheduleID, "teacher", eduboard.ScheduleException{Date: "2018-10-08", Cancelled: true})
+	assert.Error(t, err, "did not return error when expected")
+}
+
+func TestScheduleService_SetException(t *testing.T) {
+	moved := start.AddDate(0, 0, 8)
+
+	var testCases = []struct {
+		name      string
+		exception eduboard.ScheduleException
+		error     bool
+		expected  []eduboard.ScheduleException
+	}{
+		{"cancel", eduboard.ScheduleException{Date: "2018-10-08", Cancelled: true}, false,
+			[]eduboard.ScheduleException{{Date: "2018-10-08", Cancelled: true}, {Date: "2018-10-15", Cancelled: true}}},
+		{"replace", eduboard.ScheduleException{Date: "2018-10-15", Start: &moved}, false,
+			[]eduboard.ScheduleException{{Date: "2018-10-15", Start: &moved}}},
+		{"no meeting", eduboard.ScheduleException{Date: "2018-10-16", Cancelled: true}, true, nil},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err, schedule := New(newRepository(), newRoomRepository(), nil).SetException(courseID, scheduleID, "teacher", v.exception)
+			if v.error {
+				assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "error does not match")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.expected, schedule.Exceptions, "exceptions do not match")
+		})
+	}
+}
+
+func TestScheduleService_DeleteException(t *testing.T) {
+	err, schedule := New(newRepository(), newRoomRepository(), nil).DeleteException(courseID, scheduleID, "teacher", "2018-10-15")
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Empty(t, schedule.Exceptions, "exception was not deleted")
+
+	err, _ = New(newRepository(), newRoomRepository(), nil).DeleteException(courseID, scheduleID, "teacher", "2018-10-08")
+	assert.Error(t, err, "did not return error when expected")
+}
+
+func TestScheduleService_GetOccurrences(t *testing.T) {
+	s := New(newRepository(), newRoomRepository(), nil)
+
+	err, occurrences := s.GetOccurrences(courseID, start, start.AddDate(0, 0, 21))
+	assert.Nil(t, err, "returned error when it shouldn't")
+	assert.Equal(t, []string{"2018-10-01", "2018-10-08", "2018-10-15"}, dates(occurrences), "occurrences do not match")
+	assert.Equal(t, courseID, occurrences[0].CourseID.Hex(), "course ID missing")
+	assert.True(t, occurrences[2].Cancelled, "cancelled meeting not marked")
+
+	err, _ = s.GetOccurrences(courseID, start, start)
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "empty range should be invalid")
+
+	err, _ = s.GetOccurrences(courseID, start, start.AddDate(2, 0, 0))
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "long range should be invalid")
+
+	err, _ = s.GetOccurrences("", start, start.AddDate(0, 0, 7))
+	assert.Error(t, err, "did not return error when expected")
+
+	legacy := newRepository()
+	legacy.FindFn = func(id string) (error, eduboard.Course) {
+		return nil, eduboard.Course{ID: bson.ObjectIdHex(id), Schedules: []eduboard.Schedule{{Day: 9, Start: start}, {Day: time.Monday, Start: start, Duration: time.Hour}}}
+	}
+	err, occurrences = New(legacy, newRoomRepository(), nil).GetOccurrences(courseID, start, start.AddDate(0, 0, 7))
+	assert.Nil(t, err, "failed on invalid schedule")
+	assert.Equal(t, []string{"2018-10-01"}, dates(occurrences), "occurrences do not match")
+}
+
+func TestScheduleService_GetOccurrence(t *testing.T) {
+	s := New(newRepository(), newRoomRepository(), nil)
+
+	var testCases = []struct {
+		name      string
+		schedule  string
+		date      string
+		err       bool
+		cancelled bool
+	}{
+		{"regular", scheduleID, "2018-10-08", false, false},
+		{"cancelled", scheduleID, "2018-10-15", false, true},
+		{"no meeting", scheduleID, "2018-10-09", true, false},
+		{"before first meeting", scheduleID, "2018-09-24", true, false},
+		{"unknown schedule", roomID, "2018-10-08", true, false},
+	}
+
+	err, _ := s.GetOccurrence(courseID, roomID, "2018-10-08")
+	assert.Equal(t, eduboard.ErrNotFound, errors.Cause(err), "unknown schedule is not reported as not found")
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			err, occurrence := s.GetOccurrence(courseID, v.schedule, v.date)
+			if v.err {
+				assert.Error(t, err, "did not return error when expected")
+				return
+			}
+			assert.Nil(t, err, "returned error when it shouldn't")
+			assert.Equal(t, v.date, occurrence.Date, "date does not match")
+			assert.Equal(t, courseID, occurrence.CourseID.Hex(), "course ID missing")
+			assert.Equal(t, v.cancelled, occurrence.Cancelled, "cancelled flag does not match")
+			assert.Equal(t, 10, occurrence.Start.Hour(), "start does not match")
+		})
+	}
+}
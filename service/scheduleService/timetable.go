@@ -0,0 +1,69 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"sort"
+	"time"
+)
+
+// GetTimetable returns all meetings of active courses between from and to, sorted by start. Meetings that take place
+// at the same time are marked as overlapping, unless they are cancelled or have no duration.
+func (sS ScheduleService) GetTimetable(courses []eduboard.Course, from time.Time, to time.Time) (error, []eduboard.TimetableEntry) {
+	if !to.After(from) || to.Sub(from) > eduboard.MaxOccurrenceRange {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "invalid range %s to %s", from, to), []eduboard.TimetableEntry{}
+	}
+
+	entries := []eduboard.TimetableEntry{}
+	for _, c := range courses {
+		if c.Archived {
+			continue
+		}
+		for _, s := range c.Schedules {
+			// Schedules stored before they were validated may not be expandable and are left out.
+			r, err := newRecurrence(s)
+			if err != nil {
+				continue
+			}
+			for _, o := range r.occurrences(from, to) {
+				o.CourseID = c.ID
+				entries = append(entries, eduboard.TimetableEntry{Occurrence: o, CourseTitle: c.Title})
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+
+	for i := range entries {
+		if !takesPlace(entries[i].Occurrence) {
+			continue
+		}
+		for j := i + 1; j < len(entries) && entries[j].Start.Before(entries[i].End); j++ {
+			if !takesPlace(entries[j].Occurrence) {
+				continue
+			}
+			overlap(&entries[i], entries[j].CourseID)
+			overlap(&entries[j], entries[i].CourseID)
+		}
+	}
+	return nil, entries
+}
+
+// takesPlace reports whether a meeting can overlap others.
+func takesPlace(o eduboard.Occurrence) bool {
+	return !o.Cancelled && o.End.After(o.Start)
+}
+
+// overlap marks e as overlapping with a meeting of the given course.
+func overlap(e *eduboard.TimetableEntry, courseID bson.ObjectId) {
+	e.Overlapping = true
+	for _, id := range e.OverlapsWith {
+		if id == courseID {
+			return
+		}
+	}
+	e.OverlapsWith = append(e.OverlapsWith, courseID)
+}
@@ -0,0 +1,55 @@
+package scheduleService
+
+import (
+	"github.com/eduboard/backend"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+func TestScheduleService_GetTimetable(t *testing.T) {
+	algebra := eduboard.Course{ID: bson.ObjectIdHex(courseID), Title: "Algebra", Schedules: []eduboard.Schedule{
+		{ID: "a", Day: time.Monday, Start: start, Duration: 90 * time.Minute,
+			Exceptions: []eduboard.ScheduleException{{Date: "2018-10-08", Cancelled: true}}},
+	}}
+	analysis := eduboard.Course{ID: bson.ObjectIdHex(otherID), Title: "Analysis", Schedules: []eduboard.Schedule{
+		{ID: "b", Day: time.Monday, Start: start.Add(time.Hour), Duration: time.Hour},
+		{ID: "c", Day: time.Wednesday, Start: start, Duration: time.Hour},
+		{ID: "legacy", Day: 9, Start: start, Duration: time.Hour},
+	}}
+	archived := eduboard.Course{ID: bson.ObjectIdHex(archivedID), Archived: true, Schedules: []eduboard.Schedule{
+		{ID: "d", Day: time.Wednesday, Start: start, Duration: time.Hour},
+	}}
+
+	s := New(newRepository(), newRoomRepository(), nil)
+	err, entries := s.GetTimetable([]eduboard.Course{archived, analysis, algebra}, start, start.AddDate(0, 0, 14))
+	assert.Nil(t, err, "returned error when it shouldn't")
+
+	type entry struct {
+		date        string
+		schedule    bson.ObjectId
+		overlapping bool
+	}
+	expected := []entry{
+		{"2018-10-01", "a", true},
+		{"2018-10-01", "b", true},
+		{"2018-10-03", "c", false},
+		{"2018-10-08", "a", false},
+		{"2018-10-08", "b", false},
+		{"2018-10-10", "c", false},
+	}
+	actual := make([]entry, len(entries))
+	for k, v := range entries {
+		actual[k] = entry{v.Date, v.ScheduleID, v.Overlapping}
+	}
+	assert.Equal(t, expected, actual, "timetable does not match")
+	assert.Equal(t, []bson.ObjectId{analysis.ID}, entries[0].OverlapsWith, "overlapping courses do not match")
+	assert.Equal(t, []bson.ObjectId{algebra.ID}, entries[1].OverlapsWith, "overlapping courses do not match")
+	assert.Equal(t, "Algebra", entries[0].CourseTitle, "course title does not match")
+	assert.True(t, entries[3].Cancelled, "cancelled meeting is not marked")
+
+	err, _ = s.GetTimetable([]eduboard.Course{algebra}, start, start)
+	assert.Equal(t, eduboard.ErrInvalidInput, errors.Cause(err), "error does not match")
+}
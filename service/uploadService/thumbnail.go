@@ -0,0 +1,34 @@
+package uploadService
+
+import (
+	"golang.org/x/image/draw"
+	"image"
+)
+
+// ThumbnailSize is the maximum width and height of a thumbnail in pixels.
+const ThumbnailSize = 256
+
+// thumbnail scales img down to fit into a size x size square, keeping its aspect ratio.
+// Smaller images are only copied.
+func thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,38 @@
+package uploadService
+
+import (
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnail(t *testing.T) {
+	t.Parallel()
+	var testCases = []struct {
+		name   string
+		width  int
+		height int
+		bounds image.Rectangle
+	}{
+		{"landscape", 1000, 500, image.Rect(0, 0, 256, 128)},
+		{"portrait", 300, 600, image.Rect(0, 0, 128, 256)},
+		{"small", 20, 10, image.Rect(0, 0, 20, 10)},
+		{"thin", 3000, 2, image.Rect(0, 0, 256, 1)},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, v.width, v.height))
+			for y := 0; y < v.height; y++ {
+				for x := 0; x < v.width; x++ {
+					img.SetRGBA(x, y, color.RGBA{200, 100, 50, 255})
+				}
+			}
+
+			thumb := thumbnail(img, ThumbnailSize)
+			assert.Equal(t, v.bounds, thumb.Bounds(), "bounds do not match")
+			assert.Equal(t, color.RGBA{200, 100, 50, 255}, thumb.At(0, 0), "color was not kept")
+		})
+	}
+}
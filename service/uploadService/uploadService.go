@@ -0,0 +1,182 @@
+package uploadService
+
+import (
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/service/access"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// MaxImagePixels is the maximum number of pixels of an uploaded image. Larger images are rejected before they
+// are decoded, as a small file can expand to a huge image.
+const MaxImagePixels = 40 * 1000 * 1000
+
+// allowedTypes are the content types that may be uploaded. Images get a thumbnail.
+var allowedTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"application/pdf": true,
+}
+
+type UploadService struct {
+	r eduboard.UploadRepository
+	b eduboard.BlobStore
+}
+
+func New(repository eduboard.UploadRepository, store eduboard.BlobStore) *UploadService {
+	return &UploadService{
+		r: repository,
+		b: store,
+	}
+}
+
+// StoreUpload stores content along with the metadata in upload. The content type is detected from content,
+// the one sent by the client is ignored. Only members of a course may upload files to it, uploads of students
+// are private.
+func (uS *UploadService) StoreUpload(upload *eduboard.Upload, content io.Reader, cf eduboard.CourseOneFinder) (error, eduboard.Upload) {
+	upload.Private = false
+	if upload.CourseID != "" {
+		err, course := access.Member(cf, upload.CourseID, upload.OwnerID)
+		if err != nil {
+			return err, eduboard.Upload{}
+		}
+		upload.Private = !course.IsStaff(upload.OwnerID)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(content, eduboard.MaxUploadSize+1))
+	if err != nil {
+		return errors.Wrap(err, "error reading upload"), eduboard.Upload{}
+	}
+	if len(data) > eduboard.MaxUploadSize {
+		return eduboard.ErrTooLarge, eduboard.Upload{}
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return errors.Wrapf(eduboard.ErrUnsupportedMediaType, "content type %s", contentType), eduboard.Upload{}
+	}
+
+	upload.ContentType = contentType
+	upload.Size = int64(len(data))
+	upload.CreatedAt = time.Now()
+	upload.ThumbnailType = ""
+
+	var thumb []byte
+	if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		if config.Width*config.Height > MaxImagePixels {
+			return errors.Wrapf(eduboard.ErrTooLarge, "image of %dx%d pixels", config.Width, config.Height), eduboard.Upload{}
+		}
+		if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+			thumb, upload.ThumbnailType, err = encodeThumbnail(img, contentType)
+			if err != nil {
+				return errors.Wrap(err, "error creating thumbnail"), eduboard.Upload{}
+			}
+		}
+	}
+
+	// The content is stored first, such that no upload refers to missing content.
+	if upload.ID == "" {
+		upload.ID = bson.NewObjectId()
+	}
+	if err = uS.b.Put(upload.Key(), bytes.NewReader(data)); err != nil {
+		return errors.Wrapf(err, "error storing content of upload %s", upload.ID.Hex()), eduboard.Upload{}
+	}
+	if thumb != nil {
+		if err = uS.b.Put(upload.ThumbnailKey(), bytes.NewReader(thumb)); err != nil {
+			uS.deleteContent(*upload)
+			return errors.Wrapf(err, "error storing thumbnail of upload %s", upload.ID.Hex()), eduboard.Upload{}
+		}
+	}
+
+	if err = uS.r.Insert(upload); err != nil {
+		uS.deleteContent(*upload)
+		return errors.Wrap(err, "error storing upload"), eduboard.Upload{}
+	}
+	return nil, *upload
+}
+
+// OpenUpload returns the upload with the given id and its content, or its thumbnail if requested and available.
+// The caller has to close the content.
+func (uS *UploadService) OpenUpload(id string, userID string, thumbnail bool, cf eduboard.CourseOneFinder) (error, eduboard.Upload, io.ReadCloser) {
+	err, upload := uS.r.Find(id)
+	if err != nil {
+		return errors.Wrapf(err, "error finding upload %s", id), eduboard.Upload{}, nil
+	}
+
+	if upload.CourseID != "" {
+		err, course := access.Member(cf, upload.CourseID, userID)
+		if err != nil {
+			return err, eduboard.Upload{}, nil
+		}
+		if upload.Private && upload.OwnerID != userID && !course.IsStaff(userID) {
+			return errors.Wrapf(eduboard.ErrForbidden, "upload %s is private", id), eduboard.Upload{}, nil
+		}
+	}
+
+	key := upload.Key()
+	if thumbnail && upload.ThumbnailType != "" {
+		key = upload.ThumbnailKey()
+		upload.ContentType = upload.ThumbnailType
+	}
+
+	content, err := uS.b.Get(key)
+	if err != nil {
+		return errors.Wrapf(err, "error reading content of upload %s", id), eduboard.Upload{}, nil
+	}
+	return nil, upload, content
+}
+
+// DeleteByCourse deletes all uploads of a course along with their content.
+func (uS *UploadService) DeleteByCourse(courseID string) error {
+	err, uploads := uS.r.FindByCourse(courseID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding uploads of course %s", courseID)
+	}
+
+	// The uploads are the only reference to their content, so the content has to go first.
+	for _, upload := range uploads {
+		if err = uS.b.Delete(upload.Key()); err != nil {
+			return errors.Wrapf(err, "error deleting content of upload %s", upload.ID.Hex())
+		}
+		if upload.ThumbnailType != "" {
+			if err = uS.b.Delete(upload.ThumbnailKey()); err != nil {
+				return errors.Wrapf(err, "error deleting thumbnail of upload %s", upload.ID.Hex())
+			}
+		}
+	}
+	if err = uS.r.DeleteByCourse(courseID); err != nil {
+		return errors.Wrapf(err, "error deleting uploads of course %s", courseID)
+	}
+	return nil
+}
+
+// deleteContent removes the stored content and thumbnail of an upload that could not be stored completely.
+// Failures only leave unreferenced content behind.
+func (uS *UploadService) deleteContent(upload eduboard.Upload) {
+	uS.b.Delete(upload.Key())
+	if upload.ThumbnailType != "" {
+		uS.b.Delete(upload.ThumbnailKey())
+	}
+}
+
+// encodeThumbnail encodes a thumbnail of img as JPEG for photos and as PNG otherwise to keep transparency.
+func encodeThumbnail(img image.Image, contentType string) ([]byte, string, error) {
+	b := &bytes.Buffer{}
+	t := thumbnail(img, ThumbnailSize)
+	if contentType == "image/jpeg" {
+		err := jpeg.Encode(b, t, &jpeg.Options{Quality: 85})
+		return b.Bytes(), "image/jpeg", err
+	}
+	err := png.Encode(b, t)
+	return b.Bytes(), "image/png", err
+}
@@ -0,0 +1,236 @@
+package uploadService
+
+import (
+	"bytes"
+	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const courseID = "5b23c8d5382d33000150681e"
+
+func newCourseRepository() *mock.CourseRepository {
+	members := []eduboard.Member{
+		{UserID: "teacher", Role: eduboard.RoleTeacher},
+		{UserID: "student", Role: eduboard.RoleStudent},
+		{UserID: "other", Role: eduboard.RoleStudent},
+	}
+	return &mock.CourseRepository{
+		FindFn: func(id string) (error, eduboard.Course) {
+			if id == courseID {
+				return nil, eduboard.Course{ID: bson.ObjectIdHex(courseID), Members: members}
+			}
+			return errors.New("not found"), eduboard.Course{}
+		},
+	}
+}
+
+func pngImage(t *testing.T, w, h int) []byte {
+	b := &bytes.Buffer{}
+	if err := png.Encode(b, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatalf("error running test: %v", err)
+	}
+	return b.Bytes()
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	r := mock.UploadRepository{}
+	b := mock.BlobStore{}
+	s := New(&r, &b)
+	assert.Equal(t, &r, s.r, "repository does not match")
+	assert.Equal(t, &b, s.b, "blob store does not match")
+}
+
+func TestUploadService_StoreUpload(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n%âãÏÓ\n")
+	var testCases = []struct {
+		name        string
+		upload      eduboard.Upload
+		content     []byte
+		err         error
+		contentType string
+		thumbnail   bool
+		private     bool
+	}{
+		{"image", eduboard.Upload{OwnerID: "teacher"}, pngImage(t, 600, 300), nil, "image/png", true, false},
+		{"course image", eduboard.Upload{OwnerID: "student", CourseID: courseID}, pngImage(t, 10, 10), nil, "image/png", true, true},
+		{"pdf", eduboard.Upload{OwnerID: "teacher", CourseID: courseID}, pdf, nil, "application/pdf", false, false},
+		{"private flag is ignored", eduboard.Upload{OwnerID: "teacher", CourseID: courseID, Private: true}, pdf, nil, "application/pdf", false, false},
+		{"no member", eduboard.Upload{OwnerID: "stranger", CourseID: courseID}, pdf, eduboard.ErrForbidden, "", false, false},
+		{"unsupported", eduboard.Upload{OwnerID: "teacher"}, []byte("<html><script></script></html>"), eduboard.ErrUnsupportedMediaType, "", false, false},
+		{"too large", eduboard.Upload{OwnerID: "teacher"}, make([]byte, eduboard.MaxUploadSize+1), eduboard.ErrTooLarge, "", false, false},
+		{"too many pixels", eduboard.Upload{OwnerID: "teacher"}, []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00"), eduboard.ErrTooLarge, "", false, false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			stored := map[string][]byte{}
+			r := &mock.UploadRepository{InsertFn: func(upload *eduboard.Upload) error {
+				return nil
+			}}
+			b := &mock.BlobStore{PutFn: func(key string, r io.Reader) error {
+				stored[key], _ = ioutil.ReadAll(r)
+				return nil
+			}}
+			s := New(r, b)
+
+			err, upload := s.StoreUpload(&v.upload, bytes.NewReader(v.content), newCourseRepository())
+			if v.err != nil {
+				assert.Equal(t, v.err, errors.Cause(err), "error does not match")
+				assert.False(t, r.InsertFnInvoked, "Insert was invoked")
+				assert.False(t, b.PutFnInvoked, "Put was invoked")
+				return
+			}
+
+			assert.Nil(t, err, "should not cause error")
+			assert.Equal(t, v.contentType, upload.ContentType, "content type does not match")
+			assert.Equal(t, int64(len(v.content)), upload.Size, "size does not match")
+			assert.Equal(t, v.content, stored[upload.Key()], "content was not stored")
+			assert.Equal(t, v.private, upload.Private, "private flag does not match")
+
+			thumb, ok := stored[upload.ThumbnailKey()]
+			assert.Equal(t, v.thumbnail, ok, "thumbnail was not stored as expected")
+			assert.Equal(t, v.thumbnail, upload.ThumbnailURL() != "", "thumbnail url does not match")
+			if v.thumbnail {
+				img, _, err := image.Decode(bytes.NewReader(thumb))
+				assert.Nil(t, err, "thumbnail can not be decoded")
+				assert.True(t, img.Bounds().Dx() <= ThumbnailSize && img.Bounds().Dy() <= ThumbnailSize, "thumbnail is too large")
+			}
+		})
+	}
+}
+
+func TestUploadService_StoreUpload_InsertFails(t *testing.T) {
+	stored := map[string]bool{}
+	r := &mock.UploadRepository{InsertFn: func(upload *eduboard.Upload) error {
+		assert.True(t, stored[upload.Key()], "content was not stored before the upload")
+		return errors.New("database down")
+	}}
+	b := &mock.BlobStore{
+		PutFn: func(key string, r io.Reader) error {
+			stored[key] = true
+			return nil
+		},
+		DeleteFn: func(key string) error {
+			delete(stored, key)
+			return nil
+		},
+	}
+	s := New(r, b)
+
+	err, _ := s.StoreUpload(&eduboard.Upload{OwnerID: "teacher"}, bytes.NewReader(pngImage(t, 600, 300)), newCourseRepository())
+	assert.NotNil(t, err, "did not fail")
+	assert.True(t, r.InsertFnInvoked, "Insert was not invoked")
+	assert.Empty(t, stored, "content was not removed")
+}
+
+func TestUploadService_OpenUpload(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		id        string
+		userID    string
+		thumbnail bool
+		served    string
+		error     bool
+	}{
+		{"not found", "unknown", "student", false, "", true},
+		{"public", "public", "stranger", false, "content", false},
+		{"member", "course", "student", false, "content", false},
+		{"no member", "course", "stranger", false, "", true},
+		{"own private", "private", "student", false, "content", false},
+		{"private of staff", "private", "teacher", false, "content", false},
+		{"private of other student", "private", "other", false, "", true},
+		{"thumbnail", "public", "student", true, "thumbnail", false},
+		{"no thumbnail", "course", "student", true, "content", false},
+	}
+
+	r := &mock.UploadRepository{FindFn: func(id string) (error, eduboard.Upload) {
+		switch id {
+		case "public":
+			return nil, eduboard.Upload{ID: "public", ContentType: "image/png", ThumbnailType: "image/png"}
+		case "course":
+			return nil, eduboard.Upload{ID: "course", CourseID: courseID, ContentType: "application/pdf"}
+		case "private":
+			return nil, eduboard.Upload{ID: "private", OwnerID: "student", CourseID: courseID, ContentType: "application/pdf", Private: true}
+		}
+		return errors.New("not found"), eduboard.Upload{}
+	}}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			var key string
+			b := &mock.BlobStore{GetFn: func(k string) (io.ReadCloser, error) {
+				key = k
+				return ioutil.NopCloser(strings.NewReader("content")), nil
+			}}
+			s := New(r, b)
+
+			err, _, content := s.OpenUpload(v.id, v.userID, v.thumbnail, newCourseRepository())
+			if v.error {
+				assert.NotNil(t, err, "did not fail")
+				assert.False(t, b.GetFnInvoked, "Get was invoked")
+				return
+			}
+			assert.Nil(t, err, "should not cause error")
+			upload := eduboard.Upload{ID: bson.ObjectId(v.id)}
+			expected := map[string]string{"content": upload.Key(), "thumbnail": upload.ThumbnailKey()}
+			assert.Equal(t, expected[v.served], key, "wrong blob was opened")
+			content.Close()
+		})
+	}
+}
+
+func TestUploadService_DeleteByCourse(t *testing.T) {
+	uploads := []eduboard.Upload{
+		{ID: bson.ObjectIdHex("5b23bbdc2bfa844c41a9f140"), CourseID: courseID, ThumbnailType: "image/png"},
+		{ID: bson.ObjectIdHex("5b23bbdc2bfa844c41a9f141"), CourseID: courseID},
+	}
+
+	var testCases = []struct {
+		name      string
+		blobError bool
+		deleted   []string
+	}{
+		{"success", false, []string{"5b23bbdc2bfa844c41a9f140", "5b23bbdc2bfa844c41a9f140.thumbnail", "5b23bbdc2bfa844c41a9f141"}},
+		{"blob error", true, []string{}},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			deleted := []string{}
+			r := &mock.UploadRepository{
+				FindByCourseFn: func(id string) (error, []eduboard.Upload) {
+					assert.Equal(t, courseID, id, "course does not match")
+					return nil, uploads
+				},
+				DeleteByCourseFn: func(id string) error { return nil },
+			}
+			b := &mock.BlobStore{DeleteFn: func(key string) error {
+				if v.blobError {
+					return errors.New("disk failure")
+				}
+				deleted = append(deleted, key)
+				return nil
+			}}
+			s := New(r, b)
+
+			err := s.DeleteByCourse(courseID)
+			assert.Equal(t, v.deleted, deleted, "content was not deleted as expected")
+			assert.Equal(t, !v.blobError, r.DeleteByCourseFnInvoked, "DeleteByCourse was not invoked as expected")
+			if v.blobError {
+				assert.NotNil(t, err, "did not fail")
+				return
+			}
+			assert.Nil(t, err, "should not cause error")
+		})
+	}
+}
@@ -5,12 +5,33 @@ import (
 	"github.com/eduboard/backend/auth"
 	"github.com/pkg/errors"
 	"gopkg.in/mgo.v2/bson"
+	"log"
+	"net/mail"
+	"net/url"
 	"time"
 )
 
+const (
+	// IdleTimeout is the time after which an unused session expires.
+	IdleTimeout = 24 * time.Hour
+	// AbsoluteTimeout is the maximum lifetime of a session, regardless of its use.
+	AbsoluteTimeout = 30 * 24 * time.Hour
+	// renewInterval limits how often the idle timeout of a session is extended.
+	renewInterval = time.Minute
+	// ResetTimeout is the time a password reset token stays valid.
+	ResetTimeout = time.Hour
+	// VerificationTimeout is the time an email verification token stays valid.
+	VerificationTimeout = 48 * time.Hour
+)
+
 type UserService struct {
-	r eduboard.UserRepository
-	a Authenticator
+	r  eduboard.UserRepository
+	s  eduboard.SessionRepository
+	pr eduboard.PasswordResetRepository
+	vr eduboard.VerificationRepository
+	n  eduboard.Notifier
+	a  Authenticator
+	l  *log.Logger
 }
 
 type Authenticator interface {
@@ -19,10 +40,15 @@ type Authenticator interface {
 	SessionID() string
 }
 
-func New(userRepository eduboard.UserRepository) *UserService {
+func New(userRepository eduboard.UserRepository, sessionRepository eduboard.SessionRepository, resetRepository eduboard.PasswordResetRepository, verificationRepository eduboard.VerificationRepository, notifier eduboard.Notifier, logger *log.Logger) *UserService {
 	return &UserService{
-		r: userRepository,
-		a: &auth.Authenticator{},
+		r:  userRepository,
+		s:  sessionRepository,
+		pr: resetRepository,
+		vr: verificationRepository,
+		n:  notifier,
+		a:  &auth.Authenticator{},
+		l:  logger,
 	}
 }
 
@@ -31,6 +57,10 @@ func (uS *UserService) GetAllUsers() ([]eduboard.User, error) {
 }
 
 func (uS *UserService) CreateUser(user *eduboard.User, password string) (error, eduboard.User) {
+	if _, err := mail.ParseAddress(user.Email); err != nil {
+		return errors.Wrapf(eduboard.ErrInvalidInput, "invalid email %q", user.Email), eduboard.User{}
+	}
+
 	err, _ := uS.r.FindByEmail(user.Email)
 	if err == nil {
 		return errors.New("email already exists"), eduboard.User{}
@@ -46,13 +76,18 @@ func (uS *UserService) CreateUser(user *eduboard.User, password string) (error,
 	}
 
 	user.PasswordHash = hashedPassword
-	user.SessionID = uS.a.SessionID()
-	user.SessionExpires = time.Time{}.Add(24 * time.Hour)
+	user.CreatedAt = time.Now()
+	user.Verified = false
 
 	err = uS.r.Store(user)
 	if err != nil {
 		return errors.Wrap(err, "error storing user"), eduboard.User{}
 	}
+
+	// The account exists at this point, the user can request another verification mail.
+	if err = uS.sendVerification(*user); err != nil {
+		uS.l.Printf("error sending verification to user %s: %v", user.ID.Hex(), err)
+	}
 	return nil, *user
 }
 
@@ -60,14 +95,15 @@ func (uS *UserService) GetUser(id string) (err error, user eduboard.User) {
 	return uS.r.Find(id)
 }
 
-func (uS *UserService) GetMyCourses(id string, cBMF eduboard.CourseManyFinder, cEMF eduboard.CourseEntryManyFinder) (err error, user []eduboard.Course) {
+// GetMyCourses returns the courses of the user with the given id, including the entries viewerID may see.
+func (uS *UserService) GetMyCourses(id string, viewerID string, cBMF eduboard.CourseManyFinder, cEMF eduboard.CourseEntryManyFinder) (err error, user []eduboard.Course) {
 
 	if !uS.r.IsIDValid(id) {
 		return errors.New("invalid id"), []eduboard.Course{}
 	}
 
 	result := []eduboard.Course{}
-	err, result = cBMF.FindMany(bson.M{"members": id})
+	err, result = cBMF.FindMany(bson.M{"members.userID": id})
 	if err != nil {
 		return errors.Wrap(err, "error finding courses from member"), []eduboard.Course{}
 	}
@@ -76,58 +112,270 @@ func (uS *UserService) GetMyCourses(id string, cBMF eduboard.CourseManyFinder, c
 		if len(course.EntryIDs) > 0 {
 			err, e := cEMF.FindMany(bson.M{"courseID": course.ID})
 			if err != nil {
-				return errors.Wrapf(err, "error finding courseEntries from %d", course.ID), []eduboard.Course{}
+				return errors.Wrapf(err, "error finding courseEntries from %s", course.ID.Hex()), []eduboard.Course{}
 			}
-			result[k].Entries = e
+			course.Entries = e
+			result[k].Entries = course.VisibleEntries(viewerID)
 		}
 	}
 
 	return nil, result
 }
 
-func (uS *UserService) Login(email string, password string) (error, eduboard.User) {
+func (uS *UserService) UpdatePicture(id string, picture url.URL) error {
+	if err := uS.r.SetPicture(id, picture); err != nil {
+		return errors.Wrapf(err, "error updating picture of user %s", id)
+	}
+	return nil
+}
+
+func (uS *UserService) Login(email string, password string, userAgent string) (error, eduboard.User, eduboard.Session) {
 	err, user := uS.r.FindByEmail(email)
 	if err != nil {
-		return errors.Wrap(err, "error finding user by email"), eduboard.User{}
+		return errors.Wrap(err, "error finding user by email"), eduboard.User{}, eduboard.Session{}
 	}
 
 	ok, err := uS.a.CompareHash(user.PasswordHash, password)
 	if err != nil {
-		return errors.Wrap(err, "error comparing hash"), eduboard.User{}
+		return errors.Wrap(err, "error comparing hash"), eduboard.User{}, eduboard.Session{}
 	}
 	if !ok {
-		return errors.New("invalid password"), eduboard.User{}
+		return errors.New("invalid password"), eduboard.User{}, eduboard.Session{}
 	}
 
-	user.SessionID = uS.a.SessionID()
-	err, user = uS.r.UpdateSessionID(user)
+	err, session := uS.CreateSession(user.ID.Hex(), userAgent)
 	if err != nil {
-		return errors.Wrap(err, "error updating sessionID"), eduboard.User{}
+		return err, eduboard.User{}, eduboard.Session{}
 	}
 
-	return nil, user
+	return nil, user, session
+}
+
+func (uS *UserService) CreateSession(userID string, userAgent string) (error, eduboard.Session) {
+	now := time.Now()
+	token := uS.a.SessionID()
+	session := eduboard.Session{
+		TokenHash:    auth.HashToken(token),
+		UserID:       userID,
+		UserAgent:    userAgent,
+		CreatedAt:    now,
+		LastSeen:     now,
+		ExpiresAt:    now.Add(IdleTimeout),
+		MaxExpiresAt: now.Add(AbsoluteTimeout),
+	}
+
+	if err := uS.s.Insert(&session); err != nil {
+		return errors.Wrap(err, "error storing session"), eduboard.Session{}
+	}
+	session.Token = token
+	return nil, session
+}
+
+func (uS *UserService) Logout(token string) error {
+	err, session := uS.s.FindByToken(auth.HashToken(token))
+	if err != nil {
+		return errors.Wrap(err, "error finding session")
+	}
+
+	if err = uS.s.Delete(session.ID.Hex()); err != nil {
+		return errors.Wrap(err, "error deleting session")
+	}
+	return nil
+}
+
+// CheckAuthentication returns the session belonging to token. Sessions in use are renewed,
+// such that they expire IdleTimeout after their last use but never later than their absolute expiry.
+func (uS *UserService) CheckAuthentication(token string) (err error, session eduboard.Session) {
+	err, session = uS.s.FindByToken(auth.HashToken(token))
+	if err != nil {
+		return errors.Wrap(err, "error finding session"), eduboard.Session{}
+	}
+
+	now := time.Now()
+	if session.IsExpired(now) {
+		uS.s.Delete(session.ID.Hex())
+		return errors.New("session expired"), eduboard.Session{}
+	}
+
+	if now.Sub(session.LastSeen) < renewInterval {
+		return nil, session
+	}
+
+	expiresAt := now.Add(IdleTimeout)
+	if expiresAt.After(session.MaxExpiresAt) {
+		expiresAt = session.MaxExpiresAt
+	}
+	if err = uS.s.Touch(session.ID.Hex(), now, expiresAt); err != nil {
+		return errors.Wrap(err, "error renewing session"), eduboard.Session{}
+	}
+
+	session.LastSeen = now
+	session.ExpiresAt = expiresAt
+	return nil, session
+}
+
+func (uS *UserService) GetSessions(userID string) (error, []eduboard.Session) {
+	err, sessions := uS.s.FindByUser(userID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding sessions of user %s", userID), []eduboard.Session{}
+	}
+
+	active := []eduboard.Session{}
+	now := time.Now()
+	for _, v := range sessions {
+		if !v.IsExpired(now) {
+			active = append(active, v)
+		}
+	}
+	return nil, active
+}
+
+func (uS *UserService) RevokeSession(userID string, sessionID string) error {
+	err, sessions := uS.s.FindByUser(userID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding sessions of user %s", userID)
+	}
+
+	for _, v := range sessions {
+		if v.ID.Hex() == sessionID {
+			return uS.s.Delete(sessionID)
+		}
+	}
+	return errors.Errorf("session %s does not belong to user %s", sessionID, userID)
 }
 
-func (uS *UserService) Logout(sessionID string) error {
-	err, user := uS.r.FindBySessionID(sessionID)
+// RequestPasswordReset sends a password reset token to the user with the given email.
+// Unknown emails are ignored silently to not reveal which emails are registered.
+func (uS *UserService) RequestPasswordReset(email string) error {
+	err, user := uS.r.FindByEmail(email)
 	if err != nil {
-		return errors.Wrap(err, "error finding by sessionID")
+		return nil
+	}
+
+	now := time.Now()
+	token := uS.a.SessionID()
+	reset := eduboard.PasswordResetToken{
+		UserID:    user.ID.Hex(),
+		TokenHash: auth.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ResetTimeout),
 	}
 
-	user.SessionID = ""
-	uS.r.UpdateSessionID(user)
+	if err = uS.pr.Insert(&reset); err != nil {
+		return errors.Wrap(err, "error storing password reset token")
+	}
 
+	if err = uS.n.NotifyPasswordReset(user, token, reset.ExpiresAt); err != nil {
+		return errors.Wrap(err, "error sending password reset token")
+	}
 	return nil
 }
 
-func (uS *UserService) CheckAuthentication(sessionID string) (err error, id string) {
-	err, user := uS.r.FindBySessionID(sessionID)
+// ResetPassword sets a new password for the owner of token and ends all of their sessions.
+// The token is only used up if the password was changed, all other tokens of the owner stop working.
+func (uS *UserService) ResetPassword(token string, password string) error {
+	if len(password) < 8 {
+		return errors.New("password too short")
+	}
+
+	hashedPassword, err := uS.a.Hash(password)
 	if err != nil {
-		return errors.Wrap(err, "error finding by sessionID"), ""
+		return errors.Wrap(err, "error hashing password")
 	}
-	if user.SessionExpires.After(time.Now()) {
-		return errors.New("session expired"), ""
+
+	err, reset := uS.pr.Consume(auth.HashToken(token), time.Now())
+	if err != nil {
+		return errors.Wrap(err, "invalid password reset token")
 	}
 
-	return nil, user.ID.Hex()
+	if err = uS.r.UpdatePassword(reset.UserID, hashedPassword); err != nil {
+		if releaseErr := uS.pr.Release(reset.ID.Hex()); releaseErr != nil {
+			return errors.Wrapf(err, "error updating password of user %s, token was not released: %v", reset.UserID, releaseErr)
+		}
+		return errors.Wrapf(err, "error updating password of user %s", reset.UserID)
+	}
+
+	// Sessions are ended first, a failure to delete the remaining tokens must not leave old sessions alive.
+	if err = uS.s.DeleteByUser(reset.UserID); err != nil {
+		return errors.Wrapf(err, "error ending sessions of user %s", reset.UserID)
+	}
+	if err = uS.pr.DeleteByUser(reset.UserID); err != nil {
+		return errors.Wrapf(err, "error deleting password reset tokens of user %s", reset.UserID)
+	}
+	return nil
+}
+
+// RequestVerification sends a new verification token to the user, e.g. if the first one got lost.
+func (uS *UserService) RequestVerification(userID string) error {
+	err, user := uS.r.Find(userID)
+	if err != nil {
+		return errors.Wrapf(err, "error finding user %s", userID)
+	}
+	if user.Verified {
+		return errors.Errorf("user %s is already verified", userID)
+	}
+	return uS.sendVerification(user)
+}
+
+// VerifyEmail marks the owner of token as verified and returns their ID.
+func (uS *UserService) VerifyEmail(token string) (error, string) {
+	err, verification := uS.vr.Consume(auth.HashToken(token), time.Now())
+	if err != nil {
+		return errors.Wrap(err, "invalid verification token"), ""
+	}
+
+	if err = uS.r.SetVerified(verification.UserID); err != nil {
+		return errors.Wrapf(err, "error verifying user %s", verification.UserID), ""
+	}
+	return nil, verification.UserID
+}
+
+func (uS *UserService) sendVerification(user eduboard.User) error {
+	now := time.Now()
+	token := uS.a.SessionID()
+	verification := eduboard.VerificationToken{
+		UserID:    user.ID.Hex(),
+		TokenHash: auth.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(VerificationTimeout),
+	}
+
+	if err := uS.vr.Insert(&verification); err != nil {
+		return errors.Wrap(err, "error storing verification token")
+	}
+
+	if err := uS.n.NotifyVerification(user, token, verification.ExpiresAt); err != nil {
+		return errors.Wrap(err, "error sending verification token")
+	}
+	return nil
+}
+
+// CreateCalendarToken issues a new calendar token for the user. Only its hash is stored,
+// so a previously issued token stops working.
+func (uS *UserService) CreateCalendarToken(userID string) (error, string) {
+	token := uS.a.SessionID()
+	if err := uS.r.SetCalendarToken(userID, auth.HashToken(token)); err != nil {
+		return errors.Wrapf(err, "error storing calendar token of user %s", userID), ""
+	}
+	return nil, token
+}
+
+// RevokeCalendarToken removes the calendar token of the user.
+func (uS *UserService) RevokeCalendarToken(userID string) error {
+	if err := uS.r.SetCalendarToken(userID, ""); err != nil {
+		return errors.Wrapf(err, "error revoking calendar token of user %s", userID)
+	}
+	return nil
+}
+
+// FindByCalendarToken returns the owner of a calendar token.
+func (uS *UserService) FindByCalendarToken(token string) (error, eduboard.User) {
+	if token == "" {
+		return errors.New("invalid calendar token"), eduboard.User{}
+	}
+	err, user := uS.r.FindByCalendarToken(auth.HashToken(token))
+	if err != nil {
+		return errors.Wrap(err, "invalid calendar token"), eduboard.User{}
+	}
+	return nil, user
 }
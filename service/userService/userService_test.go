@@ -4,17 +4,25 @@ import (
 	"errors"
 	"fmt"
 	"github.com/eduboard/backend"
+	"github.com/eduboard/backend/auth"
 	"github.com/eduboard/backend/mock"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/mgo.v2/bson"
+	"io/ioutil"
+	"log"
+	"net/url"
 	"testing"
+	"time"
 )
 
 var r = mock.UserRepository{
 	FindFnInvoked: false,
 	FindFn: func(id string) (error, eduboard.User) {
-		if id == "0" {
+		switch id {
+		case "0":
 			return nil, eduboard.User{ID: "0"}
+		case "verified":
+			return nil, eduboard.User{ID: "1", Verified: true}
 		}
 		return errors.New("not found"), eduboard.User{}
 	},
@@ -29,13 +37,6 @@ var r = mock.UserRepository{
 		}
 		return errors.New("not found"), eduboard.User{}
 	},
-	FindBySessionIDFnInvoked: false,
-	FindBySessionIDFn: func(sessionID string) (error, eduboard.User) {
-		if sessionID == "sessionID-0-0-0" {
-			return nil, eduboard.User{ID: "1"}
-		}
-		return errors.New("not found"), eduboard.User{}
-	},
 	StoreFnInvoked: false,
 	StoreFn: func(user *eduboard.User) error {
 		if user.Email == "fail@mail.com" {
@@ -43,9 +44,35 @@ var r = mock.UserRepository{
 		}
 		return nil
 	},
-	UpdateSessionIDFnInvoked: false,
-	UpdateSessionIDFn: func(user eduboard.User) (error, eduboard.User) {
-		return nil, user
+	UpdatePasswordFnInvoked: false,
+	UpdatePasswordFn: func(id string, passwordHash string) error {
+		if id == "broken" {
+			return errors.New("error updating password")
+		}
+		return nil
+	},
+	SetVerifiedFnInvoked: false,
+	SetVerifiedFn: func(id string) error {
+		return nil
+	},
+	SetPictureFnInvoked: false,
+	SetPictureFn: func(id string, picture url.URL) error {
+		if id != "0" {
+			return errors.New("not found")
+		}
+		return nil
+	},
+	SetCalendarTokenFn: func(id string, tokenHash string) error {
+		if id != "0" {
+			return errors.New("not found")
+		}
+		return nil
+	},
+	FindByCalendarTokenFn: func(tokenHash string) (error, eduboard.User) {
+		if tokenHash == auth.HashToken("sessionID-0-0-0") {
+			return nil, eduboard.User{ID: "0"}
+		}
+		return errors.New("not found"), eduboard.User{}
 	},
 }
 var a = mock.AuthenticatorMock{
@@ -69,13 +96,110 @@ var a = mock.AuthenticatorMock{
 	},
 }
 
-var us = &UserService{&r, &a}
+var s = mock.SessionRepository{
+	InsertFn: func(session *eduboard.Session) error {
+		if session.UserID == "failing" {
+			return errors.New("error storing session")
+		}
+		return nil
+	},
+	FindByTokenFn: func(tokenHash string) (error, eduboard.Session) {
+		now := time.Now()
+		switch tokenHash {
+		case auth.HashToken("sessionID-0-0-0"):
+			return nil, eduboard.Session{ID: "1", UserID: "1", LastSeen: now, ExpiresAt: now.Add(time.Hour), MaxExpiresAt: now.Add(time.Hour)}
+		case auth.HashToken("idle"):
+			return nil, eduboard.Session{ID: "2", UserID: "1", LastSeen: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour), MaxExpiresAt: now.Add(AbsoluteTimeout)}
+		case auth.HashToken("almost over"):
+			return nil, eduboard.Session{ID: "3", UserID: "1", LastSeen: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour), MaxExpiresAt: now.Add(2 * time.Hour)}
+		case auth.HashToken("expired"):
+			return nil, eduboard.Session{ID: "4", UserID: "1", LastSeen: now.Add(-2 * IdleTimeout), ExpiresAt: now.Add(-IdleTimeout), MaxExpiresAt: now.Add(time.Hour)}
+		case auth.HashToken("max expired"):
+			return nil, eduboard.Session{ID: "5", UserID: "1", LastSeen: now, ExpiresAt: now.Add(time.Hour), MaxExpiresAt: now.Add(-time.Second)}
+		}
+		return errors.New("not found"), eduboard.Session{}
+	},
+	FindByUserFn: func(userID string) (error, []eduboard.Session) {
+		if userID != "1" {
+			return nil, []eduboard.Session{}
+		}
+		now := time.Now()
+		return nil, []eduboard.Session{
+			{ID: "1", UserID: "1", ExpiresAt: now.Add(time.Hour), MaxExpiresAt: now.Add(time.Hour)},
+			{ID: "2", UserID: "1", ExpiresAt: now.Add(-time.Hour), MaxExpiresAt: now.Add(time.Hour)},
+		}
+	},
+	TouchFn: func(id string, lastSeen time.Time, expiresAt time.Time) error {
+		return nil
+	},
+	DeleteFn: func(id string) error {
+		return nil
+	},
+	DeleteByUserFn: func(userID string) error {
+		return nil
+	},
+}
+
+var pr = mock.PasswordResetRepository{
+	InsertFn: func(token *eduboard.PasswordResetToken) error {
+		return nil
+	},
+	ConsumeFn: func(tokenHash string, now time.Time) (error, eduboard.PasswordResetToken) {
+		switch tokenHash {
+		case auth.HashToken("sessionID-0-0-0"):
+			return nil, eduboard.PasswordResetToken{ID: "1", UserID: "0"}
+		case auth.HashToken("broken"):
+			return nil, eduboard.PasswordResetToken{ID: "2", UserID: "broken"}
+		case auth.HashToken("cleanup"):
+			return nil, eduboard.PasswordResetToken{ID: "3", UserID: "cleanup"}
+		}
+		return errors.New("not found"), eduboard.PasswordResetToken{}
+	},
+	ReleaseFn: func(id string) error {
+		return nil
+	},
+	DeleteByUserFn: func(userID string) error {
+		if userID == "cleanup" {
+			return errors.New("error deleting tokens")
+		}
+		return nil
+	},
+}
+
+var vr = mock.VerificationRepository{
+	InsertFn: func(token *eduboard.VerificationToken) error {
+		return nil
+	},
+	ConsumeFn: func(tokenHash string, now time.Time) (error, eduboard.VerificationToken) {
+		if tokenHash == auth.HashToken("sessionID-0-0-0") {
+			return nil, eduboard.VerificationToken{UserID: "0"}
+		}
+		return errors.New("not found"), eduboard.VerificationToken{}
+	},
+}
+
+var n = mock.Notifier{
+	NotifyPasswordResetFn: func(user eduboard.User, token string, expires time.Time) error {
+		return nil
+	},
+	NotifyVerificationFn: func(user eduboard.User, token string, expires time.Time) error {
+		return nil
+	},
+}
+
+var us = &UserService{r: &r, s: &s, pr: &pr, vr: &vr, n: &n, a: &a, l: log.New(ioutil.Discard, "", 0)}
 
 func TestNew(t *testing.T) {
 	t.Parallel()
-	u := New(&r)
+	logger := log.New(ioutil.Discard, "", 0)
+	u := New(&r, &s, &pr, &vr, &n, logger)
 	assert.Equal(t, &r, u.r, "repository does not match")
+	assert.Equal(t, &s, u.s, "session repository does not match")
+	assert.Equal(t, &pr, u.pr, "password reset repository does not match")
+	assert.Equal(t, &vr, u.vr, "verification repository does not match")
+	assert.Equal(t, &n, u.n, "notifier does not match")
 	assert.NotNil(t, u.a, "no authenticator")
+	assert.Equal(t, logger, u.l, "logger does not match")
 }
 
 func TestUserService_CreateUser(t *testing.T) {
@@ -85,6 +209,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		password string
 		error    bool
 	}{
+		{"invalid email", "new.mail.com", "longpassword", true},
 		{"user exists", "existing@mail.com", "password", true},
 		{"password too short", "new@mail.com", "pass", true},
 		{"error hashing password", "new@mail.com", "longpasswordbuthashfailed", true},
@@ -95,9 +220,9 @@ func TestUserService_CreateUser(t *testing.T) {
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
 			defer func() { r.FindByEmailFnInvoked = false }()
-			defer func() { a.SessionIDFnInvoked = false }()
 			defer func() { r.StoreFnInvoked = false }()
 			defer func() { a.HashFnInvoked = false }()
+			defer func() { n.NotifyVerificationFnInvoked = false }()
 
 			err, user := us.CreateUser(&eduboard.User{Email: v.email}, v.password)
 			if v.error {
@@ -113,13 +238,27 @@ func TestUserService_CreateUser(t *testing.T) {
 			assert.True(t, r.FindByEmailFnInvoked, "FindByEmail was not invoked")
 			assert.True(t, a.HashFnInvoked, "Hash was not invoked")
 			assert.Equal(t, v.password, user.PasswordHash, "did not hash password")
-			assert.True(t, a.SessionIDFnInvoked, "SessionID was not invoked")
-			assert.Equal(t, "sessionID-0-0-0", user.SessionID, "sessionID was not set")
 			assert.True(t, r.StoreFnInvoked, "Store was not invoked")
+			assert.False(t, user.Verified, "new user is verified")
+			assert.True(t, n.NotifyVerificationFnInvoked, "verification was not sent")
 		})
 	}
 }
 
+func TestUserService_CreateUser_NotificationFails(t *testing.T) {
+	defer func() { r.StoreFnInvoked = false }()
+	defer func() { n.NotifyVerificationFnInvoked = false }()
+	defer func(fn func(eduboard.User, string, time.Time) error) { n.NotifyVerificationFn = fn }(n.NotifyVerificationFn)
+	n.NotifyVerificationFn = func(user eduboard.User, token string, expires time.Time) error {
+		return errors.New("mail server down")
+	}
+
+	err, user := us.CreateUser(&eduboard.User{Email: "new@mail.com"}, "longpassword")
+	assert.Nil(t, err, "failed although the user was stored")
+	assert.True(t, r.StoreFnInvoked, "Store was not invoked")
+	assert.Equal(t, "new@mail.com", user.Email, "did not return the user")
+}
+
 func TestUserService_GetUser(t *testing.T) {
 	var testCases = []struct {
 		name  string
@@ -171,6 +310,17 @@ func TestUserService_GetAllUsers(t *testing.T) {
 	}
 }
 
+func TestUserService_UpdatePicture(t *testing.T) {
+	picture, _ := url.Parse("/api/v1/uploads/5b23bbdc2bfa844c41a9f140")
+
+	err := us.UpdatePicture("0", *picture)
+	assert.Nil(t, err, "should not cause error")
+	assert.True(t, r.SetPictureFnInvoked, "SetPicture was not invoked")
+
+	err = us.UpdatePicture("1", *picture)
+	assert.NotNil(t, err, "did not fail for unknown user")
+}
+
 func TestUserService_Login(t *testing.T) {
 	var testCases = []struct {
 		name     string
@@ -188,21 +338,54 @@ func TestUserService_Login(t *testing.T) {
 			defer func() { r.FindByEmailFnInvoked = false }()
 			defer func() { a.CompareHashFnInvoked = false }()
 			defer func() { a.SessionIDFnInvoked = false }()
-			defer func() { r.UpdateSessionIDFnInvoked = false }()
+			defer func() { s.InsertFnInvoked = false }()
 
-			err, user := us.Login(v.email, v.password)
+			err, user, session := us.Login(v.email, v.password, "agent")
 			if v.error {
 				assert.NotNil(t, err, "did not fail to log in user")
 				assert.Equal(t, eduboard.User{}, user, "did not return empty user")
-				assert.False(t, r.UpdateSessionIDFnInvoked, "UpdateSessionID invoked")
+				assert.Equal(t, eduboard.Session{}, session, "did not return empty session")
+				assert.False(t, s.InsertFnInvoked, "Insert invoked")
 				return
 			}
 			assert.Nil(t, err, "should not fail to login userService")
 			assert.True(t, r.FindByEmailFnInvoked, "FindByEmail not invoked")
 			assert.True(t, a.CompareHashFnInvoked, "CompareHash not invoked")
 			assert.True(t, a.SessionIDFnInvoked, "SessionID not invoked")
-			assert.True(t, r.UpdateSessionIDFnInvoked, "UpdateSessionID not invoked")
-			assert.Equal(t, "sessionID-0-0-0", user.SessionID, "did not update sessionID")
+			assert.True(t, s.InsertFnInvoked, "Insert not invoked")
+			assert.Equal(t, "sessionID-0-0-0", session.Token, "did not create session")
+			assert.Equal(t, user.ID.Hex(), session.UserID, "session does not belong to user")
+		})
+	}
+}
+
+func TestUserService_CreateSession(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		userID string
+		error  bool
+	}{
+		{"success", "1", false},
+		{"error storing", "failing", true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			defer func() { s.InsertFnInvoked = false }()
+
+			err, session := us.CreateSession(v.userID, "agent")
+			assert.True(t, s.InsertFnInvoked, "Insert not invoked")
+			if v.error {
+				assert.NotNil(t, err, "did not fail")
+				assert.Equal(t, eduboard.Session{}, session, "did not return empty session")
+				return
+			}
+			assert.Nil(t, err, "caused error creating session")
+			assert.Equal(t, "agent", session.UserAgent, "user agent was not stored")
+			assert.Equal(t, "sessionID-0-0-0", session.Token, "token was not returned")
+			assert.Equal(t, auth.HashToken(session.Token), session.TokenHash, "stored hash does not match token")
+			assert.Equal(t, IdleTimeout, session.ExpiresAt.Sub(session.LastSeen), "idle timeout not applied")
+			assert.Equal(t, AbsoluteTimeout, session.MaxExpiresAt.Sub(session.CreatedAt), "absolute timeout not applied")
 		})
 	}
 }
@@ -219,19 +402,19 @@ func TestUserService_Logout(t *testing.T) {
 
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
-			defer func() { r.FindBySessionIDFnInvoked = false }()
-			defer func() { r.UpdateSessionIDFnInvoked = false }()
+			defer func() { s.FindByTokenFnInvoked = false }()
+			defer func() { s.DeleteFnInvoked = false }()
 
 			err := us.Logout(v.sessionID)
 			if v.error {
 				assert.NotNil(t, err, "did not fail")
-				assert.True(t, r.FindBySessionIDFnInvoked, "FindBySessionID was not invoked")
-				assert.False(t, r.UpdateSessionIDFnInvoked, "UpdateSessionIDFn was invoked")
+				assert.True(t, s.FindByTokenFnInvoked, "FindByToken was not invoked")
+				assert.False(t, s.DeleteFnInvoked, "Delete was invoked")
 				return
 			}
 			assert.Nil(t, err, "caused error logging out user")
-			assert.True(t, r.FindBySessionIDFnInvoked, "FindBySessionID was not invoked")
-			assert.True(t, r.UpdateSessionIDFnInvoked, "UpdateSessionID was not invoked")
+			assert.True(t, s.FindByTokenFnInvoked, "FindByToken was not invoked")
+			assert.True(t, s.DeleteFnInvoked, "Delete was not invoked")
 		})
 	}
 }
@@ -244,8 +427,8 @@ func TestUserService_GetMyCourses(t *testing.T) {
 
 	service := UserService{r: &mockUserRepo}
 
-	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []string{"1"}}
-	courseWithEntries := eduboard.Course{ID: "2", Title: "Course 2", Members: []string{"2"}, EntryIDs: []bson.ObjectId{"1", "2"}}
+	course1 := eduboard.Course{ID: "1", Title: "Course 1", Members: []eduboard.Member{{UserID: "1", Role: eduboard.RoleOwner}}}
+	courseWithEntries := eduboard.Course{ID: "2", Title: "Course 2", Members: []eduboard.Member{{UserID: "2", Role: eduboard.RoleOwner}}, EntryIDs: []bson.ObjectId{"1", "2"}}
 	brokenCourseWithEntries := eduboard.Course{ID: "4", Title: "Course 4", EntryIDs: []bson.ObjectId{"1"}}
 	courseEntry1 := eduboard.CourseEntry{ID: "1", CourseID: "2", Message: "First Entry", Published: true}
 	courseEntry2 := eduboard.CourseEntry{ID: "2", CourseID: "2", Message: "Second Entry", Published: true}
@@ -277,7 +460,7 @@ func TestUserService_GetMyCourses(t *testing.T) {
 	}
 
 	mockCourseRepo.FindManyFn = func(query bson.M) (error, []eduboard.Course) {
-		switch query["members"] {
+		switch query["members.userID"] {
 		case "1":
 			return nil, []eduboard.Course{course1}
 		case "2":
@@ -301,7 +484,7 @@ func TestUserService_GetMyCourses(t *testing.T) {
 			mockUserRepo.IsIDValidFnInvoked = false
 			mockCourseRepo.FindManyFnInvoked = false
 
-			err, courses := service.GetMyCourses(v.input, &mockCourseRepo, &mockCourseEntryRepo)
+			err, courses := service.GetMyCourses(v.input, v.input, &mockCourseRepo, &mockCourseEntryRepo)
 			assert.True(t, mockUserRepo.IsIDValidFnInvoked, "user repository call was not invoked")
 
 			assert.Equal(t, v.expected, courses, "courses do not equal expected value")
@@ -320,25 +503,285 @@ func TestUserService_CheckAuthentication(t *testing.T) {
 		name      string
 		sessionID string
 		error     bool
+		renewed   bool
+		capped    bool
 	}{
-		{"unknown session", "someOtherSession", true},
-		{"user exists", "sessionID-0-0-0", false},
+		{"unknown session", "someOtherSession", true, false, false},
+		{"user exists", "sessionID-0-0-0", false, false, false},
+		{"renewed", "idle", false, true, false},
+		{"renewed up to absolute timeout", "almost over", false, true, true},
+		{"idle timeout", "expired", true, false, false},
+		{"absolute timeout", "max expired", true, false, false},
 	}
 
 	for _, v := range testCases {
 		t.Run(v.name, func(t *testing.T) {
-			defer func() { r.FindBySessionIDFnInvoked = false }()
+			defer func() { s.FindByTokenFnInvoked = false }()
+			defer func() { s.TouchFnInvoked = false }()
 
-			err, id := us.CheckAuthentication(v.sessionID)
+			err, session := us.CheckAuthentication(v.sessionID)
+			assert.True(t, s.FindByTokenFnInvoked, "FindByToken was not invoked")
+			assert.Equal(t, v.renewed, s.TouchFnInvoked, "Touch was not invoked as expected")
 			if v.error {
 				assert.NotNil(t, err, "did not fail")
-				assert.Equal(t, "", id, "should not contain id")
-				assert.True(t, r.FindBySessionIDFnInvoked, "UpdateSessionIDFn was invoked")
+				assert.Equal(t, "", session.UserID, "should not contain id")
 				return
 			}
-			assert.Nil(t, err, "caused error logging out user")
-			assert.Equal(t, "31", id, "should contain id") // 31 is the Hex representation of 1.
-			assert.True(t, r.FindBySessionIDFnInvoked, "FindBySessionID was not invoked")
+			assert.Nil(t, err, "caused error checking session")
+			assert.Equal(t, "1", session.UserID, "should contain id")
+			if v.renewed && !v.capped {
+				assert.WithinDuration(t, time.Now().Add(IdleTimeout), session.ExpiresAt, time.Second, "session was not renewed")
+			}
+			if v.capped {
+				assert.Equal(t, session.MaxExpiresAt, session.ExpiresAt, "session was renewed beyond absolute timeout")
+			}
+		})
+	}
+}
+
+func TestUserService_GetSessions(t *testing.T) {
+	defer func() { s.FindByUserFnInvoked = false }()
+
+	err, sessions := us.GetSessions("1")
+	assert.Nil(t, err, "caused error getting sessions")
+	assert.True(t, s.FindByUserFnInvoked, "FindByUser was not invoked")
+	assert.Len(t, sessions, 1, "expired sessions were not filtered")
+}
+
+func TestUserService_RevokeSession(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		userID    string
+		sessionID string
+		error     bool
+	}{
+		{"success", "1", "31", false},
+		{"foreign session", "2", "31", true},
+		{"unknown session", "1", "33", true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			defer func() { s.DeleteFnInvoked = false }()
+
+			err := us.RevokeSession(v.userID, v.sessionID)
+			if v.error {
+				assert.NotNil(t, err, "did not fail")
+				assert.False(t, s.DeleteFnInvoked, "Delete was invoked")
+				return
+			}
+			assert.Nil(t, err, "caused error revoking session")
+			assert.True(t, s.DeleteFnInvoked, "Delete was not invoked")
+		})
+	}
+}
+
+func TestUserService_RequestPasswordReset(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		email  string
+		notify bool
+	}{
+		{"unknown email", "new@mail.com", false},
+		{"user exists", "existing@mail.com", true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			defer func() { pr.InsertFnInvoked = false }()
+			defer func() { n.NotifyPasswordResetFnInvoked = false }()
+
+			var stored eduboard.PasswordResetToken
+			pr.InsertFn = func(token *eduboard.PasswordResetToken) error {
+				stored = *token
+				return nil
+			}
+			var sent string
+			n.NotifyPasswordResetFn = func(user eduboard.User, token string, expires time.Time) error {
+				sent = token
+				return nil
+			}
+
+			err := us.RequestPasswordReset(v.email)
+			assert.Nil(t, err, "caused error requesting password reset")
+			assert.Equal(t, v.notify, pr.InsertFnInvoked, "Insert was not invoked as expected")
+			assert.Equal(t, v.notify, n.NotifyPasswordResetFnInvoked, "NotifyPasswordReset was not invoked as expected")
+			if v.notify {
+				assert.NotEqual(t, sent, stored.TokenHash, "token was stored in plain text")
+				assert.Equal(t, auth.HashToken(sent), stored.TokenHash, "stored hash does not match token")
+				assert.Equal(t, ResetTimeout, stored.ExpiresAt.Sub(stored.CreatedAt), "token does not expire")
+			}
+		})
+	}
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		token    string
+		password string
+		error    bool
+		consumed bool
+		released bool
+		ended    bool
+	}{
+		{"password too short", "sessionID-0-0-0", "pass", true, false, false, false},
+		{"invalid token", "someOtherToken", "longpassword", true, true, false, false},
+		{"hashing failed", "sessionID-0-0-0", "longpasswordbuthashfailed", true, false, false, false},
+		{"update failed", "broken", "longpassword", true, true, true, false},
+		{"token cleanup failed", "cleanup", "longpassword", true, true, false, true},
+		{"success", "sessionID-0-0-0", "longpassword", false, true, false, true},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			defer func() { r.UpdatePasswordFnInvoked = false }()
+			defer func() { s.DeleteByUserFnInvoked = false }()
+			defer func() { pr.ConsumeFnInvoked = false }()
+			defer func() { pr.ReleaseFnInvoked = false }()
+			defer func() { pr.DeleteByUserFnInvoked = false }()
+
+			err := us.ResetPassword(v.token, v.password)
+			assert.Equal(t, v.consumed, pr.ConsumeFnInvoked, "Consume was not invoked as expected")
+			assert.Equal(t, v.released, pr.ReleaseFnInvoked, "Release was not invoked as expected")
+			assert.Equal(t, v.ended, s.DeleteByUserFnInvoked, "sessions were not ended as expected")
+			if v.error {
+				assert.NotNil(t, err, "did not fail")
+				return
+			}
+			assert.Nil(t, err, "caused error resetting password")
+			assert.True(t, r.UpdatePasswordFnInvoked, "UpdatePassword was not invoked")
+			assert.True(t, pr.DeleteByUserFnInvoked, "other reset tokens were not invalidated")
+		})
+	}
+}
+
+func TestUserService_RequestVerification(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		userID string
+		error  bool
+	}{
+		{"unknown user", "unknown", true},
+		{"already verified", "verified", true},
+		{"success", "0", false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			defer func() { vr.InsertFnInvoked = false }()
+			defer func() { n.NotifyVerificationFnInvoked = false }()
+
+			var stored eduboard.VerificationToken
+			vr.InsertFn = func(token *eduboard.VerificationToken) error {
+				stored = *token
+				return nil
+			}
+			var sent string
+			n.NotifyVerificationFn = func(user eduboard.User, token string, expires time.Time) error {
+				sent = token
+				return nil
+			}
+
+			err := us.RequestVerification(v.userID)
+			if v.error {
+				assert.NotNil(t, err, "did not fail")
+				assert.False(t, n.NotifyVerificationFnInvoked, "NotifyVerification was invoked")
+				return
+			}
+			assert.Nil(t, err, "caused error requesting verification")
+			assert.True(t, vr.InsertFnInvoked, "Insert was not invoked")
+			assert.Equal(t, auth.HashToken(sent), stored.TokenHash, "stored hash does not match token")
+			assert.Equal(t, bson.ObjectId("0").Hex(), stored.UserID, "token does not belong to user")
+			assert.Equal(t, VerificationTimeout, stored.ExpiresAt.Sub(stored.CreatedAt), "token does not expire")
+		})
+	}
+}
+
+func TestUserService_VerifyEmail(t *testing.T) {
+	var testCases = []struct {
+		name  string
+		token string
+		error bool
+	}{
+		{"invalid token", "someOtherToken", true},
+		{"success", "sessionID-0-0-0", false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			defer func() { r.SetVerifiedFnInvoked = false }()
+
+			err, userID := us.VerifyEmail(v.token)
+			if v.error {
+				assert.NotNil(t, err, "did not fail")
+				assert.False(t, r.SetVerifiedFnInvoked, "SetVerified was invoked")
+				return
+			}
+			assert.Nil(t, err, "caused error verifying email")
+			assert.True(t, r.SetVerifiedFnInvoked, "SetVerified was not invoked")
+			assert.Equal(t, "0", userID, "returned the wrong user")
+		})
+	}
+}
+
+func TestUserService_CreateCalendarToken(t *testing.T) {
+	var stored string
+	r.SetCalendarTokenFn = func(id string, tokenHash string) error {
+		if id != "0" {
+			return errors.New("not found")
+		}
+		stored = tokenHash
+		return nil
+	}
+
+	err, token := us.CreateCalendarToken("0")
+	assert.Nil(t, err, "caused error creating calendar token")
+	assert.Equal(t, "sessionID-0-0-0", token, "token does not match")
+	assert.Equal(t, auth.HashToken(token), stored, "stored hash does not match token")
+
+	err, token = us.CreateCalendarToken("unknown")
+	assert.NotNil(t, err, "did not fail")
+	assert.Empty(t, token, "returned token on failure")
+}
+
+func TestUserService_RevokeCalendarToken(t *testing.T) {
+	stored := "hash"
+	r.SetCalendarTokenFn = func(id string, tokenHash string) error {
+		if id != "0" {
+			return errors.New("not found")
+		}
+		stored = tokenHash
+		return nil
+	}
+
+	assert.Nil(t, us.RevokeCalendarToken("0"), "caused error revoking calendar token")
+	assert.Empty(t, stored, "token hash was not removed")
+	assert.NotNil(t, us.RevokeCalendarToken("unknown"), "did not fail")
+}
+
+func TestUserService_FindByCalendarToken(t *testing.T) {
+	var testCases = []struct {
+		name  string
+		token string
+		error bool
+	}{
+		{"empty token", "", true},
+		{"invalid token", "someOtherToken", true},
+		{"success", "sessionID-0-0-0", false},
+	}
+
+	for _, v := range testCases {
+		t.Run(v.name, func(t *testing.T) {
+			defer func() { r.FindByCalendarTokenFnInvoked = false }()
+
+			err, user := us.FindByCalendarToken(v.token)
+			if v.error {
+				assert.NotNil(t, err, "did not fail")
+				return
+			}
+			assert.Nil(t, err, "caused error finding calendar token")
+			assert.Equal(t, bson.ObjectId("0"), user.ID, "user does not match")
 		})
 	}
 }
@@ -0,0 +1,35 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// Session is a login of a user on a single device. A session ends when it has not been used for the idle timeout
+// or when it reaches MaxExpiresAt, whichever comes first. Only the hash of its token is stored, Token is only set
+// on the session returned at login.
+type Session struct {
+	ID           bson.ObjectId `json:"id" bson:"_id"`
+	Token        string        `json:"-" bson:"-"`
+	TokenHash    string        `json:"-" bson:"tokenHash"`
+	UserID       string        `json:"userID" bson:"userID"`
+	UserAgent    string        `json:"userAgent" bson:"userAgent"`
+	CreatedAt    time.Time     `json:"createdAt" bson:"createdAt"`
+	LastSeen     time.Time     `json:"lastSeen" bson:"lastSeen"`
+	ExpiresAt    time.Time     `json:"expiresAt" bson:"expiresAt"`
+	MaxExpiresAt time.Time     `json:"maxExpiresAt" bson:"maxExpiresAt"`
+}
+
+// IsExpired reports whether the session has ended at the time now.
+func (s Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt) || !now.Before(s.MaxExpiresAt)
+}
+
+type SessionRepository interface {
+	Insert(session *Session) error
+	FindByToken(tokenHash string) (error, Session)
+	FindByUser(userID string) (error, []Session)
+	Touch(id string, lastSeen time.Time, expiresAt time.Time) error
+	Delete(id string) error
+	DeleteByUser(userID string) error
+}
@@ -0,0 +1,77 @@
+package eduboard
+
+import (
+	"errors"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"time"
+)
+
+// MaxUploadSize is the maximum size of a single uploaded file in bytes.
+const MaxUploadSize = 10 << 20
+
+var (
+	// ErrUnsupportedMediaType is returned if the content of an upload is not of an allowed type.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrTooLarge is returned if an upload exceeds MaxUploadSize or an export exceeds its limit.
+	ErrTooLarge = errors.New("too large")
+)
+
+// Upload describes a file stored in a BlobStore. Uploads belonging to a course can only be read by its members,
+// private ones only by their owner and the staff. All other uploads, like profile pictures, can be read by every
+// authenticated user.
+type Upload struct {
+	ID            bson.ObjectId `json:"id" bson:"_id"`
+	OwnerID       string        `json:"ownerID" bson:"ownerID"`
+	CourseID      string        `json:"courseID,omitempty" bson:"courseID,omitempty"`
+	Filename      string        `json:"filename" bson:"filename"`
+	ContentType   string        `json:"contentType" bson:"contentType"`
+	Size          int64         `json:"size" bson:"size"`
+	ThumbnailType string        `json:"thumbnailType,omitempty" bson:"thumbnailType,omitempty"`
+	CreatedAt     time.Time     `json:"createdAt" bson:"createdAt"`
+	// Private is set for uploads of students, which are made for their submissions.
+	Private bool `json:"private" bson:"private,omitempty"`
+}
+
+// Key is the key of the file content in a BlobStore.
+func (u Upload) Key() string {
+	return u.ID.Hex()
+}
+
+// ThumbnailKey is the key of the thumbnail in a BlobStore. Only images have a thumbnail.
+func (u Upload) ThumbnailKey() string {
+	return u.ID.Hex() + ".thumbnail"
+}
+
+// URL is the path the upload is served at.
+func (u Upload) URL() string {
+	return "/api/v1/uploads/" + u.ID.Hex()
+}
+
+// ThumbnailURL is the path the thumbnail is served at, or empty if there is no thumbnail.
+func (u Upload) ThumbnailURL() string {
+	if u.ThumbnailType == "" {
+		return ""
+	}
+	return u.URL() + "/thumbnail"
+}
+
+type UploadRepository interface {
+	Insert(upload *Upload) error
+	Find(id string) (error, Upload)
+	FindByCourse(courseID string) (error, []Upload)
+	DeleteByCourse(courseID string) error
+}
+
+// BlobStore stores file contents by key.
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the blob with the given key. Deleting a missing blob is no error.
+	Delete(key string) error
+}
+
+type UploadService interface {
+	StoreUpload(upload *Upload, content io.Reader, cf CourseOneFinder) (error, Upload)
+	OpenUpload(id string, userID string, thumbnail bool, cf CourseOneFinder) (error, Upload, io.ReadCloser)
+}
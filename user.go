@@ -7,43 +7,81 @@ import (
 )
 
 type User struct {
-	ID             bson.ObjectId `json:"id" bson:"_id"`
-	Name           string        `json:"name" bson:"name"`
-	Surname        string        `json:"surname" bson:"surname"`
-	Email          string        `json:"email" bson:"email"`
-	PasswordHash   string        `json:"password" bson:"password"`
-	SessionID      string        `json:"sessionID" bson:"sessionID"`
-	SessionExpires time.Time     `json:"sessionExpires" bson:"sessionExpires"`
-	Courses        []string      `json:"courses" bson:"courses"`
-	CreatedAt      time.Time     `json:"createdAt" bson:"createdAt"`
-	Picture        url.URL       `json:"profilePicture" bson:"profilePicture"`
+	ID           bson.ObjectId `json:"id" bson:"_id"`
+	Name         string        `json:"name" bson:"name"`
+	Surname      string        `json:"surname" bson:"surname"`
+	Email        string        `json:"email" bson:"email"`
+	PasswordHash string        `json:"password" bson:"password"`
+	Courses      []string      `json:"courses" bson:"courses"`
+	CreatedAt    time.Time     `json:"createdAt" bson:"createdAt"`
+	Picture      url.URL       `json:"profilePicture" bson:"profilePicture"`
+	Verified     bool          `json:"verified" bson:"verified"`
+	// CalendarTokenHash is the hash of the token granting access to the user's calendar feeds.
+	CalendarTokenHash string `json:"-" bson:"calendarToken,omitempty"`
 }
 
 type UserFinder interface {
 	FindMembers(members []string) (error, []User)
 }
 
+type UserCourseRemover interface {
+	// RemoveCourse removes the course with the given ID from the courses of all users.
+	RemoveCourse(courseID string) error
+}
+
 type UserRepository interface {
 	Store(user *User) error
 	Find(id string) (error, User)
 	FindMany(query bson.M) ([]User, error)
 	FindByEmail(email string) (error, User)
-	FindBySessionID(sessionID string) (error, User)
 	IsIDValid(id string) bool
-	UpdateSessionID(user User) (error, User)
+	UpdatePassword(id string, passwordHash string) error
+	SetVerified(id string) error
+	SetPicture(id string, picture url.URL) error
+	// SetCalendarToken stores the calendar token hash of a user. An empty hash removes it.
+	SetCalendarToken(id string, tokenHash string) error
+	FindByCalendarToken(tokenHash string) (error, User)
 	UserFinder
+	UserCourseRemover
 }
 
 type UserService interface {
 	CreateUser(u *User, password string) (error, User)
 	GetUser(id string) (error, User)
 	GetAllUsers() ([]User, error)
-	GetMyCourses(id string, cS CourseManyFinder, cEMF CourseEntryManyFinder) (error, []Course)
+	GetMyCourses(id string, viewerID string, cS CourseManyFinder, cEMF CourseEntryManyFinder) (error, []Course)
+	UpdatePicture(id string, picture url.URL) error
 	UserAuthenticationProvider
+	PasswordResetter
+	EmailVerifier
+	CalendarTokenProvider
 }
 
 type UserAuthenticationProvider interface {
-	Login(email string, password string) (error, User)
-	Logout(sessionID string) error
-	CheckAuthentication(sessionID string) (err error, userID string)
+	Login(email string, password string, userAgent string) (error, User, Session)
+	Logout(token string) error
+	CreateSession(userID string, userAgent string) (error, Session)
+	CheckAuthentication(token string) (err error, session Session)
+	GetSessions(userID string) (error, []Session)
+	RevokeSession(userID string, sessionID string) error
+}
+
+type PasswordResetter interface {
+	RequestPasswordReset(email string) error
+	ResetPassword(token string, password string) error
+}
+
+type EmailVerifier interface {
+	RequestVerification(userID string) error
+	// VerifyEmail marks the owner of token as verified and returns their ID.
+	VerifyEmail(token string) (error, string)
+}
+
+// CalendarTokenProvider manages the secret tokens that give calendar clients access to a user's feeds
+// without a session.
+type CalendarTokenProvider interface {
+	// CreateCalendarToken returns a new calendar token for the user, replacing any previous one.
+	CreateCalendarToken(userID string) (error, string)
+	RevokeCalendarToken(userID string) error
+	FindByCalendarToken(token string) (error, User)
 }
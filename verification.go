@@ -0,0 +1,23 @@
+package eduboard
+
+import (
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+// VerificationToken confirms that a user owns the email they registered with.
+// Like PasswordResetToken only the hash of the token is stored.
+type VerificationToken struct {
+	ID        bson.ObjectId `json:"id" bson:"_id"`
+	UserID    string        `json:"userID" bson:"userID"`
+	TokenHash string        `json:"-" bson:"tokenHash"`
+	CreatedAt time.Time     `json:"createdAt" bson:"createdAt"`
+	ExpiresAt time.Time     `json:"expiresAt" bson:"expiresAt"`
+	Used      bool          `json:"used" bson:"used"`
+}
+
+type VerificationRepository interface {
+	Insert(token *VerificationToken) error
+	// Consume marks the unused and unexpired token with the given hash as used and returns it.
+	Consume(tokenHash string, now time.Time) (error, VerificationToken)
+}